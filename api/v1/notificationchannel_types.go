@@ -0,0 +1,149 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Notification channel types.
+const (
+	ChannelTypeCloudEvents = "CloudEvents"
+	ChannelTypeSMTP        = "SMTP"
+	ChannelTypePagerDuty   = "PagerDuty"
+	ChannelTypeGrafana     = "Grafana"
+)
+
+// CloudEventsChannel posts CloudEvents to an HTTP sink.
+type CloudEventsChannel struct {
+	// SinkURL is the HTTP endpoint receiving the events.
+	// +kubebuilder:validation:MinLength=1
+	SinkURL string `json:"sinkURL"`
+}
+
+// SMTPChannel mails events through an SMTP server.
+type SMTPChannel struct {
+	// Addr is the host:port of the SMTP server.
+	// +kubebuilder:validation:MinLength=1
+	Addr string `json:"addr"`
+	// From is the sender address.
+	// +kubebuilder:validation:MinLength=1
+	From string `json:"from"`
+	// To lists the recipient addresses.
+	// +kubebuilder:validation:MinItems=1
+	To []string `json:"to"`
+	// Username enables PLAIN authentication against the server.
+	// +optional
+	Username string `json:"username,omitempty"`
+	// PasswordSecretRef selects the key of a Secret in the channel's namespace
+	// holding the password for Username.
+	// +optional
+	PasswordSecretRef *corev1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+	// Digest sends one mail per profile per day summarizing its events instead
+	// of one mail per event.
+	// +optional
+	Digest bool `json:"digest,omitempty"`
+}
+
+// PagerDutyChannel pages through the PagerDuty Events API v2 when a profile's
+// actions fail repeatedly.
+type PagerDutyChannel struct {
+	// RoutingKeySecretRef selects the key of a Secret in the channel's namespace
+	// holding the integration key of the PagerDuty service.
+	RoutingKeySecretRef corev1.SecretKeySelector `json:"routingKeySecretRef"`
+	// FailureThreshold is the number of consecutive failed actions that
+	// triggers an incident. Defaults to 3.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+}
+
+// GrafanaChannel annotates Grafana dashboards with every applied or failed
+// action.
+type GrafanaChannel struct {
+	// URL is the base URL of Grafana, e.g. https://grafana.example.com.
+	// +kubebuilder:validation:MinLength=1
+	URL string `json:"url"`
+	// APITokenSecretRef selects the key of a Secret in the channel's namespace
+	// holding a service account token allowed to create annotations.
+	APITokenSecretRef corev1.SecretKeySelector `json:"apiTokenSecretRef"`
+	// DashboardUID restricts annotations to one dashboard. When empty they
+	// are organization wide, and shown by dashboards querying their tags.
+	// +optional
+	DashboardUID string `json:"dashboardUID,omitempty"`
+	// Tags are added to the k20s, action, namespace and profile tags of every
+	// annotation.
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+}
+
+// NotificationChannelSpec defines the desired state of NotificationChannel.
+// Exactly the section matching Type must be set.
+// +kubebuilder:validation:XValidation:rule="self.type != 'CloudEvents' || has(self.cloudEvents)",message="cloudEvents is required for type CloudEvents"
+// +kubebuilder:validation:XValidation:rule="self.type != 'SMTP' || has(self.smtp)",message="smtp is required for type SMTP"
+// +kubebuilder:validation:XValidation:rule="self.type != 'PagerDuty' || has(self.pagerDuty)",message="pagerDuty is required for type PagerDuty"
+// +kubebuilder:validation:XValidation:rule="self.type != 'Grafana' || has(self.grafana)",message="grafana is required for type Grafana"
+type NotificationChannelSpec struct {
+	// +kubebuilder:validation:Enum=CloudEvents;SMTP;PagerDuty;Grafana
+	Type string `json:"type"`
+
+	// Events restricts the event kinds delivered to this channel. All kinds but
+	// report are delivered when empty; weekly reports are only delivered to
+	// the channels listing them.
+	// +optional
+	Events []NotificationEventKind `json:"events,omitempty"`
+
+	// +optional
+	CloudEvents *CloudEventsChannel `json:"cloudEvents,omitempty"`
+	// +optional
+	SMTP *SMTPChannel `json:"smtp,omitempty"`
+	// +optional
+	PagerDuty *PagerDutyChannel `json:"pagerDuty,omitempty"`
+	// +optional
+	Grafana *GrafanaChannel `json:"grafana,omitempty"`
+}
+
+// NotificationEventKind is a kind of event published by the controller.
+// +kubebuilder:validation:Enum=action;action_failed;recommendation;report
+type NotificationEventKind string
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// NotificationChannel is a reusable notification destination referenced by
+// ResourceOptimizerProfiles in the same namespace.
+type NotificationChannel struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec NotificationChannelSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NotificationChannelList contains a list of NotificationChannel
+type NotificationChannelList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NotificationChannel `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NotificationChannel{}, &NotificationChannelList{})
+}
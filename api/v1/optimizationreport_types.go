@@ -0,0 +1,120 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OptimizationReportSpec is the period an OptimizationReport covers.
+type OptimizationReportSpec struct {
+	// PeriodStart is when the covered week starts, on a Monday at 00:00 UTC.
+	PeriodStart metav1.Time `json:"periodStart"`
+	// PeriodEnd is when the covered week ends, exclusive.
+	PeriodEnd metav1.Time `json:"periodEnd"`
+}
+
+// ActionCount is the number of times an action was applied to a target.
+type ActionCount struct {
+	Action string `json:"action"`
+	Count  int32  `json:"count"`
+}
+
+// OverProvisionedWorkload is a workload whose CPU requests exceed what its
+// profile recommends.
+type OverProvisionedWorkload struct {
+	// Kind is Deployment or StatefulSet.
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Profile is the name of the profile selecting the workload.
+	Profile string `json:"profile"`
+	// CPURequest is the CPU request of the workload's first container with
+	// one.
+	CPURequest resource.Quantity `json:"cpuRequest"`
+	// RecommendedCPURequest is the CPU request the profile recommends for it.
+	RecommendedCPURequest resource.Quantity `json:"recommendedCPURequest"`
+	// Excess is the CPU requested beyond the recommendation, across all
+	// replicas of the workload.
+	Excess resource.Quantity `json:"excess"`
+}
+
+// OptimizationReportStatus summarizes the optimization of a namespace over the
+// report's period.
+type OptimizationReportStatus struct {
+	// GeneratedAt is when the report was generated.
+	// +optional
+	GeneratedAt metav1.Time `json:"generatedAt,omitempty"`
+	// Actions counts the actions applied to the namespace's workloads.
+	// +optional
+	Actions []ActionCount `json:"actions,omitempty"`
+	// FailedActions is the number of actions that could not be applied.
+	// +optional
+	FailedActions int32 `json:"failedActions,omitempty"`
+	// ReplicasRemoved is the number of replicas removed by scale-downs, net of
+	// the replicas added by scale-ups. It is negative when more were added.
+	// +optional
+	ReplicasRemoved int32 `json:"replicasRemoved,omitempty"`
+	// CPURequestsReleased is the CPU removed from the requests of pod
+	// templates by resize-downs, net of resize-ups, per replica.
+	// +optional
+	CPURequestsReleased resource.Quantity `json:"cpuRequestsReleased,omitempty"`
+	// TopOverProvisioned are the workloads requesting the most CPU beyond
+	// their recommendation at the end of the period.
+	// +optional
+	TopOverProvisioned []OverProvisionedWorkload `json:"topOverProvisioned,omitempty"`
+	// Markdown is the report rendered as Markdown.
+	// +optional
+	Markdown string `json:"markdown,omitempty"`
+	// HTML is the report rendered as an HTML fragment.
+	// +optional
+	HTML string `json:"html,omitempty"`
+	// ArchivedAt is when the report was uploaded to object storage, if the
+	// controller archives reports.
+	// +optional
+	ArchivedAt *metav1.Time `json:"archivedAt,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Start",type=date,JSONPath=`.spec.periodStart`
+// +kubebuilder:printcolumn:name="Replicas Removed",type=integer,JSONPath=`.status.replicasRemoved`
+// +kubebuilder:printcolumn:name="CPU Released",type=string,JSONPath=`.status.cpuRequestsReleased`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// OptimizationReport is the weekly summary of the optimization of the
+// profiles in its namespace. Reports are generated by the controller.
+type OptimizationReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OptimizationReportSpec   `json:"spec,omitempty"`
+	Status OptimizationReportStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OptimizationReportList contains a list of OptimizationReport
+type OptimizationReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OptimizationReport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OptimizationReport{}, &OptimizationReportList{})
+}
@@ -17,8 +17,11 @@ limitations under the License.
 package v1
 
 import (
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -41,9 +44,40 @@ type ResourceOptimizerProfileSpec struct {
 
 	CPUThresholds ThresholdSpec `json:"cpuThresholds"`
 
+	// MetricQuery replaces the built-in query of the CPU utilization the
+	// thresholds are compared with by a PromQL query returning a utilization
+	// in percent per pod. It is a Go template with the placeholders
+	// {{.Namespace}}, the namespace of the profile, {{.Selector}}, its
+	// matchLabels as matchers on the pod labels of kube-state-metrics such as
+	// label_app="web", and {{.PodRegex}}, a regex matching the names of its
+	// pods, which are substituted whenever the query is run.
+	// +optional
+	MetricQuery string `json:"metricQuery,omitempty"`
+
+	// RecordingRule makes the controller maintain a PrometheusRule recording
+	// the CPU utilization of the profile's pods, and query the recorded series
+	// instead of computing the utilization from the raw container metrics on
+	// every reconcile. It does not apply to a MetricQuery, and requires the
+	// Prometheus Operator.
+	// +optional
+	RecordingRule bool `json:"recordingRule,omitempty"`
+
 	// +kubebuilder:validation:Enum=Scale;Resize;Recommend
 	OptimizationPolicy string `json:"optimizationPolicy"`
 
+	// Direction restricts the actions of the profile to one direction. DownOnly
+	// only scales or resizes down, to reclaim over-provisioned resources without
+	// ever increasing spend. UpOnly only scales or resizes up. Defaults to Both.
+	// +optional
+	// +kubebuilder:validation:Enum=Both;DownOnly;UpOnly
+	Direction string `json:"direction,omitempty"`
+
+	// BusinessHours defers scale-downs and resize-downs to outside the given
+	// weekly hours, so that capacity is only reduced when traffic is low.
+	// Unset uses the controller's --business-hours, if any.
+	// +optional
+	BusinessHours *BusinessHours `json:"businessHours,omitempty"`
+
 	// CooldownPeriod is the duration the controller will wait before taking another scaling action.
 	// Defaults to 5 minutes if not specified.
 	// +optional
@@ -57,6 +91,456 @@ type ResourceOptimizerProfileSpec struct {
 	// MaxCPU is the maximum CPU request that can be set by the Resize policy.
 	// +optional
 	MaxCPU *resource.Quantity `json:"maxCPU,omitempty"`
+
+	// MaxResizePercent caps how much a single action of the Resize policy
+	// changes a container's CPU request, in percent of its current request,
+	// whatever the recommendation calls for. It keeps a spike, such as the CPU
+	// burst of a starting pod, from resizing a container in one jump. Unset
+	// leaves the change uncapped.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxResizePercent *int32 `json:"maxResizePercent,omitempty"`
+
+	// RecommendationSource selects where the Resize policy takes new CPU
+	// requests from. K20s derives them from the observed utilization. VPA uses
+	// the target recommended by a VerticalPodAutoscaler in Off mode for the
+	// workload, falling back to K20s while there is none. Percentile sizes them
+	// to a percentile of the usage over a window, see PercentileRecommendation.
+	// Defaults to K20s.
+	// +optional
+	// +kubebuilder:validation:Enum=K20s;VPA;Percentile
+	RecommendationSource string `json:"recommendationSource,omitempty"`
+
+	// PercentileRecommendation configures the Percentile recommendation source.
+	// +optional
+	PercentileRecommendation *PercentileRecommendation `json:"percentileRecommendation,omitempty"`
+
+	// UsageHistory has the Resize policy size CPU requests from a decaying
+	// histogram of each target's CPU usage, like the VerticalPodAutoscaler,
+	// instead of from the latest utilization alone.
+	// +optional
+	UsageHistory *UsageHistory `json:"usageHistory,omitempty"`
+
+	// InitialCPURequest is the CPU request estimated for targets that have no
+	// metric history yet when no other workload in the namespace runs the same
+	// image.
+	// +optional
+	InitialCPURequest *resource.Quantity `json:"initialCPURequest,omitempty"`
+
+	// ActionMode controls how the Scale and Resize policies apply an action.
+	// Patch changes the target workloads. Annotate only writes the recommended
+	// replicas or CPU requests into well-known annotations on them, for other
+	// tooling or people to apply. Admission annotates resizes the same way and
+	// has the pod webhook set the recommended requests on pods as they are
+	// created, without a rollout; scaling is patched as usual. Defaults to Patch.
+	// +optional
+	// +kubebuilder:validation:Enum=Patch;Annotate;Admission
+	ActionMode string `json:"actionMode,omitempty"`
+
+	// RestartPolicy controls how pods pick up requests changed by the Resize
+	// policy. Rollout relies on the rollout the workload controller starts for
+	// the changed pod template. Restart also restarts the pods like kubectl
+	// rollout restart, and waits for a workload's previous rollout to finish
+	// before changing it again. Defaults to Rollout.
+	// +optional
+	// +kubebuilder:validation:Enum=Rollout;Restart
+	RestartPolicy string `json:"restartPolicy,omitempty"`
+
+	// Notifications lists the NotificationChannels, in the profile's namespace,
+	// that receive this profile's actions and recommendations.
+	// +optional
+	Notifications []NotificationTarget `json:"notifications,omitempty"`
+
+	// HoldOnAlerts holds back scale-down and resize-down actions while an alert
+	// carrying all of these labels, e.g. team=payments and severity=critical, is
+	// firing in Alertmanager.
+	// +optional
+	HoldOnAlerts map[string]string `json:"holdOnAlerts,omitempty"`
+
+	// AlertTriggers lists Prometheus alerts that trigger a ScaleUp, regardless
+	// of CPU utilization, while any of them is firing. They apply to the Scale
+	// and Recommend policies.
+	// +optional
+	AlertTriggers []AlertTrigger `json:"alertTriggers,omitempty"`
+
+	// RequestsPerSecond has the Scale and Recommend policies also scale on the
+	// HTTP requests per second per replica, measured by a service mesh or an
+	// ingress controller. Traffic above max scales up whatever the CPU
+	// utilization, and traffic at or above min prevents scaling down.
+	// +optional
+	RequestsPerSecond *RequestsPerSecond `json:"requestsPerSecond,omitempty"`
+
+	// LatencyObjective has the Scale and Recommend policies also scale on the
+	// latency of the requests to the targets, measured by a service mesh.
+	// Latency above the objective scales up whatever the CPU utilization.
+	// +optional
+	LatencyObjective *LatencyObjective `json:"latencyObjective,omitempty"`
+
+	// QueueTriggers have the Scale and Recommend policies also scale consumers
+	// of message queues on their backlog, and optionally scale them to zero
+	// while their queues stay empty.
+	// +optional
+	QueueTriggers []QueueTrigger `json:"queueTriggers,omitempty"`
+
+	// ScaleStep sets how many replicas the Scale policy adds or removes per
+	// action. Defaults to 1 in both directions.
+	// +optional
+	ScaleStep *ScaleStep `json:"scaleStep,omitempty"`
+
+	// MaxReplicaChange caps the replicas a single action of the Scale policy
+	// adds to or removes from a target, whatever its scaleStep, queue triggers
+	// or alert triggers call for. It limits the damage of wrong metrics. Unset
+	// leaves the change uncapped.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicaChange *int32 `json:"maxReplicaChange,omitempty"`
+
+	// MinReplicas is the fewest replicas a ScaleDown of the Scale policy
+	// leaves a target with. Targets already below it are not scaled down.
+	// ScaleToZero still scales targets to zero. Defaults to 1.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// ManagePodDisruptionBudgets has the controller maintain a
+	// PodDisruptionBudget for each target, keeping minReplicas pods available
+	// during voluntary disruptions. Targets already covered by a
+	// PodDisruptionBudget of their own are left alone.
+	// +optional
+	ManagePodDisruptionBudgets bool `json:"managePodDisruptionBudgets,omitempty"`
+
+	// Behavior configures the scaling of the Scale policy like the behavior of a
+	// HorizontalPodAutoscaler: scaleUp and scaleDown each set a stabilization
+	// window the action must keep being called for before it is taken, and
+	// policies limiting how many replicas may be added or removed per period.
+	// Setting it applies the HorizontalPodAutoscaler's default stabilization
+	// windows, 0 seconds for scaleUp and 300 for scaleDown. The tolerance field
+	// is not used.
+	// +optional
+	Behavior *autoscalingv2.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty"`
+
+	// StatefulSets controls how the Scale policy scales StatefulSets down.
+	// +optional
+	StatefulSets *StatefulSetScaling `json:"statefulSets,omitempty"`
+
+	// TopologySpread keeps the replicas of the targets balanced across
+	// topology domains when the Scale policy scales them down.
+	// +optional
+	TopologySpread *TopologySpread `json:"topologySpread,omitempty"`
+
+	// Priorities configures how scale-downs treat the PriorityClasses of the
+	// targets' pods. Targets are always scaled down lowest priority first, and
+	// targets with a priority of 1000000 or more are not scaled down unless
+	// this opts in.
+	// +optional
+	Priorities *PriorityScaling `json:"priorities,omitempty"`
+
+	// VolumeExpansion watches the PersistentVolumeClaims mounted by the pods of
+	// the profile's targets and recommends, or performs, their expansion as
+	// their volumes fill up.
+	// +optional
+	VolumeExpansion *VolumeExpansion `json:"volumeExpansion,omitempty"`
+
+	// ActionPolicy lists rules an action must pass, on each target, before the
+	// Scale or Resize policy applies it. Each rule is a CEL expression that must
+	// evaluate to true for the action to be taken.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ActionPolicy []ActionRule `json:"actionPolicy,omitempty"`
+
+	// MinConfidence is the confidence score, from 0 to 100, the observed
+	// utilization must have before the Scale or Resize policy acts on it. Unset
+	// acts regardless of confidence.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	MinConfidence *int32 `json:"minConfidence,omitempty"`
+
+	// QueryTimeout is how long each Prometheus query of the profile may take
+	// before it is cancelled and the reconcile fails. Unset uses the
+	// controller's --query-timeout.
+	// +optional
+	QueryTimeout *metav1.Duration `json:"queryTimeout,omitempty"`
+
+	// PartialResponsePolicy decides what happens when a Prometheus query of
+	// the profile returns warnings, such as the partial responses of Thanos
+	// when some of its stores did not answer. Fail fails the query like an
+	// unreachable Prometheus, Warn logs the warnings and uses the result, and
+	// Skip takes no action on a partial utilization until a later reconcile
+	// gets a complete one. Unset uses the controller's
+	// --partial-response-policy.
+	// +optional
+	// +kubebuilder:validation:Enum=Fail;Warn;Skip
+	PartialResponsePolicy string `json:"partialResponsePolicy,omitempty"`
+
+	// RecommendationTTL is how long the recommendations of the Recommend
+	// policy stay in the status. Recommendations older than that, because no
+	// reconcile could refresh them, are cleared. Unset keeps them until the
+	// next recommendation replaces them.
+	// +optional
+	RecommendationTTL *metav1.Duration `json:"recommendationTTL,omitempty"`
+}
+
+// StatefulSetScaling controls how the Scale policy scales StatefulSets down.
+type StatefulSetScaling struct {
+	// ScaleDown is Ordered to scale a StatefulSet down only once all of its
+	// replicas are ready, by one replica at a time unless its
+	// podManagementPolicy is Parallel, and never below the partition of a
+	// staged rolling update. Disabled never scales StatefulSets down.
+	// Defaults to Ordered.
+	// +optional
+	// +kubebuilder:validation:Enum=Ordered;Disabled
+	ScaleDown string `json:"scaleDown,omitempty"`
+}
+
+// TopologySpread keeps the replicas of a profile's targets balanced across
+// topology domains when they are scaled down.
+type TopologySpread struct {
+	// TopologyKey is the node label whose most populated domains the
+	// replicas removed from Deployments are taken from. Defaults to
+	// topology.kubernetes.io/zone.
+	// +optional
+	TopologyKey string `json:"topologyKey,omitempty"`
+}
+
+// PriorityScaling orders the scale-downs of a profile's targets by the
+// priority of their PriorityClass.
+type PriorityScaling struct {
+	// ProtectedPriority is the PriorityClass value from which targets are
+	// protected from scale-downs. Defaults to 1000000.
+	// +optional
+	ProtectedPriority *int32 `json:"protectedPriority,omitempty"`
+	// ScaleDownProtected allows scale-downs of protected targets, once every
+	// target of a lower priority is at its fewest replicas.
+	// +optional
+	ScaleDownProtected bool `json:"scaleDownProtected,omitempty"`
+}
+
+// VolumeExpansion configures the expansion of the PersistentVolumeClaims of a
+// profile's targets, from the volume stats reported by the kubelets.
+type VolumeExpansion struct {
+	// ThresholdPercent is the share of a volume's capacity in use from which
+	// its claim is expanded. Defaults to 80.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=99
+	ThresholdPercent *int32 `json:"thresholdPercent,omitempty"`
+	// IncreasePercent is how much an expansion grows a claim, in percent of
+	// its capacity. Sizes are rounded up to whole gibibytes. Defaults to 50.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	IncreasePercent *int32 `json:"increasePercent,omitempty"`
+	// MaxSize caps the size claims are expanded to.
+	// +optional
+	MaxSize *resource.Quantity `json:"maxSize,omitempty"`
+	// Expand has the Scale and Resize policies expand claims whose
+	// StorageClass allows volume expansion. Otherwise expansions are only
+	// recommended in the status.
+	// +optional
+	Expand bool `json:"expand,omitempty"`
+}
+
+// VolumeRecommendation is the expansion of a claim whose volume is nearing
+// capacity.
+type VolumeRecommendation struct {
+	// PersistentVolumeClaim is the name of the claim.
+	PersistentVolumeClaim string `json:"persistentVolumeClaim"`
+	// UsedPercent is the share of the volume's capacity in use.
+	UsedPercent int32 `json:"usedPercent"`
+	// Capacity is the current capacity of the volume.
+	Capacity resource.Quantity `json:"capacity"`
+	// RecommendedSize is the size the claim should be expanded to. It is
+	// unset when the claim is already at the maxSize.
+	// +optional
+	RecommendedSize *resource.Quantity `json:"recommendedSize,omitempty"`
+	// Expanded is true once the controller requested the expansion.
+	// +optional
+	Expanded bool `json:"expanded,omitempty"`
+	// Message explains why the claim was not expanded.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// AlertTrigger selects firing Prometheus alerts.
+type AlertTrigger struct {
+	// AlertName is the name of the alerting rule.
+	// +kubebuilder:validation:MinLength=1
+	AlertName string `json:"alertName"`
+	// MatchLabels restricts the trigger to alerts carrying all of these labels.
+	// +optional
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+}
+
+// RequestsPerSecond are thresholds on the HTTP requests per second per replica
+// of a profile's targets.
+type RequestsPerSecond struct {
+	// Source of the request metrics: Istio reads istio_requests_total, NGINX
+	// reads nginx_ingress_controller_requests of ingress-nginx.
+	// +kubebuilder:validation:Enum=Istio;NGINX
+	Source string `json:"source"`
+	// Service is the name of the Service receiving the requests. It is required
+	// for NGINX. With Istio, requests to the profile's targets are counted when
+	// it is empty.
+	// +optional
+	Service string `json:"service,omitempty"`
+	// Min is the requests per second per replica below which the profile may
+	// scale down.
+	// +kubebuilder:validation:Minimum=0
+	Min int32 `json:"min"`
+	// Max is the requests per second per replica above which the profile
+	// scales up.
+	// +kubebuilder:validation:Minimum=1
+	Max int32 `json:"max"`
+}
+
+// LatencyObjective is a latency percentile the requests to a profile's targets
+// should stay below.
+type LatencyObjective struct {
+	// Source of the latency histograms: Istio reads
+	// istio_request_duration_milliseconds, Linkerd reads response_latency_ms of
+	// the Linkerd proxies.
+	// +kubebuilder:validation:Enum=Istio;Linkerd
+	Source string `json:"source"`
+	// Service is the name of the Service receiving the requests, for the Istio
+	// source. Requests to the profile's targets are measured when it is empty.
+	// +optional
+	Service string `json:"service,omitempty"`
+	// Percentile of the latency the objective applies to. Defaults to 95.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=99
+	// +optional
+	Percentile *int32 `json:"percentile,omitempty"`
+	// Threshold is the latency the percentile should stay below, e.g. 300ms.
+	Threshold metav1.Duration `json:"threshold"`
+}
+
+// QueueTrigger scales the consumers of a Kafka consumer group or a RabbitMQ
+// queue on the messages waiting for them.
+type QueueTrigger struct {
+	// Type of the queue: Kafka reads kafka_consumergroup_lag of kafka_exporter,
+	// RabbitMQ reads rabbitmq_queue_messages_ready.
+	// +kubebuilder:validation:Enum=Kafka;RabbitMQ
+	Type string `json:"type"`
+	// ConsumerGroup is the Kafka consumer group of the targets. Required for
+	// Kafka.
+	// +optional
+	ConsumerGroup string `json:"consumerGroup,omitempty"`
+	// Topic restricts the lag to a Kafka topic. All topics of the consumer group
+	// count when it is empty.
+	// +optional
+	Topic string `json:"topic,omitempty"`
+	// Queue is the RabbitMQ queue the targets consume. Required for RabbitMQ.
+	// +optional
+	Queue string `json:"queue,omitempty"`
+	// MessagesPerReplica is the backlog each replica is expected to handle.
+	// A larger backlog scales up whatever the CPU utilization.
+	// +kubebuilder:validation:Minimum=1
+	MessagesPerReplica int32 `json:"messagesPerReplica"`
+	// ScaleToZeroAfter scales the targets to zero replicas once the backlog has
+	// been empty for this long, and back to one replica when messages arrive.
+	// Targets are only scaled to zero when every queue trigger of the profile
+	// sets it and is idle.
+	// +optional
+	ScaleToZeroAfter *metav1.Duration `json:"scaleToZeroAfter,omitempty"`
+}
+
+// BusinessHours is a weekly window during which scale-downs and resize-downs
+// are deferred. A window whose end is not after its start runs past midnight.
+type BusinessHours struct {
+	// Disabled turns off the deferral for the profile, including the
+	// controller's default business hours.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+	// Days the window opens on. Defaults to Monday to Friday.
+	// +optional
+	// +kubebuilder:validation:items:Enum=Monday;Tuesday;Wednesday;Thursday;Friday;Saturday;Sunday
+	Days []string `json:"days,omitempty"`
+	// Start is the time of day the window opens, as HH:MM. Defaults to 09:00.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start,omitempty"`
+	// End is the time of day the window closes, as HH:MM. Defaults to 18:00.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end,omitempty"`
+	// TimeZone is the IANA time zone of Start and End, e.g. Europe/Berlin.
+	// Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// ScaleStep is the replica change of a ScaleUp and a ScaleDown, each either a
+// number of replicas or a percentage of the current replicas, e.g. 50%.
+// Percentages are rounded up, so every action changes at least one replica.
+type ScaleStep struct {
+	// Up is the number or percentage of replicas a ScaleUp adds. Defaults to 1.
+	// +optional
+	Up *intstr.IntOrString `json:"up,omitempty"`
+	// Down is the number or percentage of replicas a ScaleDown removes.
+	// Defaults to 1.
+	// +optional
+	Down *intstr.IntOrString `json:"down,omitempty"`
+}
+
+// PercentileRecommendation sizes CPU requests to a percentile of the CPU usage
+// of a target's pods over a window, read from Prometheus, plus headroom.
+type PercentileRecommendation struct {
+	// Window is how far back usage is considered. Defaults to 7 days.
+	// +optional
+	// +kubebuilder:validation:Type=string
+	Window *metav1.Duration `json:"window,omitempty"`
+	// Percentile of the usage in the window requests are sized to. Defaults to 95.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Percentile *int32 `json:"percentile,omitempty"`
+	// HeadroomPercent is added on top of the percentile, in percent of it.
+	// Defaults to 15.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	HeadroomPercent *int32 `json:"headroomPercent,omitempty"`
+}
+
+// UsageHistory configures the usage histogram CPU requests are sized from.
+// Every observed usage sample is added to the histogram of its target with a
+// weight that halves every HalfLife, so recent usage counts most while past
+// peaks are still remembered. Requests are sized so that the usage at
+// Percentile sits in the middle of the CPU thresholds.
+type UsageHistory struct {
+	// HalfLife is how long it takes the weight of a usage sample to halve.
+	// Defaults to 24 hours.
+	// +optional
+	// +kubebuilder:validation:Type=string
+	HalfLife *metav1.Duration `json:"halfLife,omitempty"`
+	// Percentile of the usage distribution requests are sized to. Defaults to 90.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Percentile *int32 `json:"percentile,omitempty"`
+}
+
+// ActionRule is a CEL expression gating actions. It is evaluated with the
+// variables action (ScaleUp, ScaleDown, ResizeUp or ResizeDown), value (the
+// observed CPU utilization in percent), now (the current time), profile (name,
+// namespace, labels and policy) and target (kind, name, labels and
+// annotations).
+type ActionRule struct {
+	// Name identifies the rule in logs and status.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Expression must evaluate to true for the action to be taken, e.g.
+	// !(action == "ScaleDown" && now.getDayOfWeek("Europe/Berlin") == 5).
+	// +kubebuilder:validation:MinLength=1
+	Expression string `json:"expression"`
+	// Message explains why an action was denied.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// NotificationTarget references a NotificationChannel.
+type NotificationTarget struct {
+	ChannelRef corev1.LocalObjectReference `json:"channelRef"`
 }
 
 // ActionDetail records the details of the last action taken by the controller.
@@ -65,14 +549,244 @@ type ActionDetail struct {
 	Timestamp metav1.Time `json:"timestamp"`
 	// +optional
 	Details string `json:"details,omitempty"`
+	// Targets are the results of the action on each target it was applied to.
+	// +optional
+	Targets []TargetResult `json:"targets,omitempty"`
+}
+
+// Results of an action on a target.
+const (
+	TargetResultSucceeded = "Succeeded"
+	TargetResultFailed    = "Failed"
+)
+
+// TargetResult is the result of an action on a single target.
+type TargetResult struct {
+	// Kind is Deployment or StatefulSet.
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Field is the changed field, e.g. spec.replicas.
+	Field string `json:"field"`
+	// From and To are the values of the field before and after the action.
+	// +optional
+	From string `json:"from,omitempty"`
+	// +optional
+	To string `json:"to,omitempty"`
+	// Result is Succeeded or Failed.
+	// +kubebuilder:validation:Enum=Succeeded;Failed
+	Result string `json:"result"`
+	// Message is why the action failed on the target.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// TargetDiff is what an action held back by the Recommend policy would change
+// on a target.
+type TargetDiff struct {
+	// Kind is Deployment or StatefulSet.
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Diff lists the changes, e.g. "replicas 3→5, cpu 500m→750m".
+	Diff string `json:"diff"`
+}
+
+// TargetFailure tracks the actions that failed in a row on a target.
+type TargetFailure struct {
+	// Kind is Deployment or StatefulSet.
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Failures is the number of consecutive actions that failed on the target.
+	Failures int32 `json:"failures"`
+	// LastFailureTime is when the last action failed on the target.
+	LastFailureTime metav1.Time `json:"lastFailureTime"`
+	// RetryAfter is when the next action on the target is attempted.
+	RetryAfter metav1.Time `json:"retryAfter"`
+	// Degraded is true once the failures exhausted the controller's retry
+	// budget.
+	// +optional
+	Degraded bool `json:"degraded,omitempty"`
+	// Message is why the last action failed.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ActionSuspension records that a profile's actions are suspended after they
+// kept failing.
+type ActionSuspension struct {
+	// Since is when the actions were suspended.
+	Since metav1.Time `json:"since"`
+	// ObservedGeneration is the generation of the profile's spec the actions
+	// were suspended at. Editing the spec resumes them.
+	ObservedGeneration int64 `json:"observedGeneration"`
+	// Message is why the last action failed.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// InitialEstimate is the CPU request estimated for a target without metric
+// history, and how it was arrived at.
+type InitialEstimate struct {
+	// Kind is Deployment or StatefulSet.
+	Kind string `json:"kind"`
+	// Name is the name of the target.
+	Name string `json:"name"`
+	// Container is the container the estimate is for.
+	Container string `json:"container"`
+	// CPURequest is the estimated CPU request. It is unset when nothing to
+	// estimate from was found.
+	// +optional
+	CPURequest *resource.Quantity `json:"cpuRequest,omitempty"`
+	// Rationale explains where the estimate comes from.
+	Rationale string `json:"rationale"`
+}
+
+// Confidence describes how far the CPU utilization a profile acts on can be
+// trusted, from the utilization history of its targets over a window.
+type Confidence struct {
+	// Score is the confidence from 0 to 100. It grows with the number of
+	// samples and the length of the history, and shrinks with their variation.
+	Score int32 `json:"score"`
+	// Level is Low, Medium or High.
+	Level string `json:"level"`
+	// Samples is the number of utilization samples in the window.
+	Samples int32 `json:"samples"`
+	// HistoryLength is how far back the samples go.
+	HistoryLength metav1.Duration `json:"historyLength"`
+	// VariationPercent is the standard deviation of the samples in percent of
+	// their mean.
+	VariationPercent int32 `json:"variationPercent"`
 }
 
+// Condition types reported in ResourceOptimizerProfileStatus.Conditions.
+const (
+	// ConditionDegraded is True while the controller is unable to query metrics
+	// for the profile or to apply its actions.
+	ConditionDegraded = "Degraded"
+	// ConditionActionsHeld is True while scale-down and resize-down actions are
+	// held back because alerts matching spec.holdOnAlerts are firing.
+	ConditionActionsHeld = "ActionsHeld"
+	// ConditionInvalidSamples is True while the utilization query returned
+	// NaN or infinite samples, typically for pods without CPU requests, which
+	// are left out of the observed utilization.
+	ConditionInvalidSamples = "InvalidSamples"
+)
+
+// Annotations written on target workloads by profiles with the Annotate and
+// Admission action modes.
+const (
+	// RecommendedReplicasAnnotation holds the replicas recommended by the Scale
+	// policy.
+	RecommendedReplicasAnnotation = "optimizer.k20s.opscale.ir/recommended-replicas"
+	// RecommendedCPURequestAnnotation holds the CPU request recommended by the
+	// Resize policy, as container=quantity.
+	RecommendedCPURequestAnnotation = "optimizer.k20s.opscale.ir/recommended-cpu-request"
+	// RecommendedByAnnotation names the profile that made the recommendation.
+	RecommendedByAnnotation = "optimizer.k20s.opscale.ir/recommended-by"
+	// RecommendedAtAnnotation is the RFC 3339 time of the recommendation.
+	RecommendedAtAnnotation = "optimizer.k20s.opscale.ir/recommended-at"
+)
+
+// Markers the controller writes on every workload it changes, so that other
+// tools can find them, e.g. with kubectl get deploy -l k20s.opscale.ir/managed=true.
+const (
+	// ManagedLabel is "true" on workloads the controller changed.
+	ManagedLabel = "k20s.opscale.ir/managed"
+	// ManagedByProfileAnnotation names the profile that last changed the
+	// workload.
+	ManagedByProfileAnnotation = "k20s.opscale.ir/profile"
+	// LastActionAtAnnotation is the RFC 3339 time the workload was last
+	// changed.
+	LastActionAtAnnotation = "k20s.opscale.ir/last-action-at"
+	// LastActionPolicyAnnotation is the policy, Scale or Resize, of the
+	// profile that last changed the workload.
+	LastActionPolicyAnnotation = "k20s.opscale.ir/last-action-policy"
+)
+
+// Annotations the controller writes on every workload it patches, in any
+// action mode, to trace the change back to the decision that made it.
+const (
+	// ProfileGenerationAnnotation is the generation of the profile's spec
+	// that decided the last patch.
+	ProfileGenerationAnnotation = "k20s.opscale.ir/profile-generation"
+	// ControllerVersionAnnotation is the version of the controller that made
+	// the last patch.
+	ControllerVersionAnnotation = "k20s.opscale.ir/controller-version"
+	// ReconcileIDAnnotation is the ID of the reconcile that made the last
+	// patch, which the controller's log lines carry as reconcileID.
+	ReconcileIDAnnotation = "k20s.opscale.ir/reconcile-id"
+	// DecisionKeyAnnotation identifies the decision the last patch applied.
+	// A retried reconcile that makes the same decision leaves the workload
+	// alone.
+	DecisionKeyAnnotation = "k20s.opscale.ir/decision-key"
+)
+
+// PausedHPABehaviorAnnotation holds, as JSON, the behavior a
+// HorizontalPodAutoscaler had before k20s import hpa --pause-hpas disabled its
+// scaling in favor of an imported profile. Restoring the behavior resumes it.
+const PausedHPABehaviorAnnotation = "optimizer.k20s.opscale.ir/paused-behavior"
+
+// PausedVPAUpdateModeAnnotation holds the update mode a VerticalPodAutoscaler
+// had before k20s import vpa --pause-vpas switched it to Off in favor of an
+// imported profile. Restoring the mode resumes it.
+const PausedVPAUpdateModeAnnotation = "optimizer.k20s.opscale.ir/paused-update-mode"
+
+// AllowProtectedNamespaceAnnotation set to "true" on a profile lets the
+// webhook admit it in a protected namespace, such as kube-system or the
+// controller's own namespace.
+const AllowProtectedNamespaceAnnotation = "optimizer.k20s.opscale.ir/allow-protected-namespace"
+
+// ResumeActionsAnnotation set to "true" on a profile resumes its actions
+// after they were suspended for failing repeatedly. The controller removes it
+// once it resumed them.
+const ResumeActionsAnnotation = "optimizer.k20s.opscale.ir/resume-actions"
+
+// RuntimeAnnotation on a target workload selects the sizing preset of its
+// language runtime for the Resize policy: go, jvm or nodejs.
+const RuntimeAnnotation = "optimizer.k20s.opscale.ir/runtime"
+
 // ResourceOptimizerProfileStatus defines the observed state of ResourceOptimizerProfile.
 type ResourceOptimizerProfileStatus struct {
 	ObservedMetrics map[string]string `json:"observedMetrics,omitempty"`
 	// +optional
 	LastAction      *ActionDetail `json:"lastAction,omitempty"`
 	Recommendations []string      `json:"recommendations,omitempty"`
+	// RecommendedAt is when the Recommendations and Diff were made.
+	// +optional
+	RecommendedAt *metav1.Time `json:"recommendedAt,omitempty"`
+	// Diff is what the action recommended by the Recommend policy would change
+	// on each target: the replicas a Scale profile would scale it to and the
+	// CPU request a Resize profile would set.
+	// +optional
+	Diff []TargetDiff `json:"diff,omitempty"`
+	// InitialEstimates are the CPU requests estimated for the targets while
+	// there is no metric history for them yet.
+	// +optional
+	InitialEstimates []InitialEstimate `json:"initialEstimates,omitempty"`
+	// Confidence is the confidence in the utilization last observed.
+	// +optional
+	Confidence *Confidence `json:"confidence,omitempty"`
+	// FailingTargets are the targets whose last actions failed. They are
+	// retried with a backoff, and dropped once an action succeeds on them.
+	// +optional
+	FailingTargets []TargetFailure `json:"failingTargets,omitempty"`
+	// ActionFailures is the number of consecutive actions of the profile that
+	// failed. A successful action resets it.
+	// +optional
+	ActionFailures int32 `json:"actionFailures,omitempty"`
+	// ActionsSuspended is set once ActionFailures reached the controller's
+	// action failure budget. The profile's metrics are still observed, but no
+	// action is applied until its spec is edited or it is annotated with
+	// optimizer.k20s.opscale.ir/resume-actions=true.
+	// +optional
+	ActionsSuspended *ActionSuspension `json:"actionsSuspended,omitempty"`
+	// VolumeRecommendations lists the claims of the targets whose volumes are
+	// nearing capacity, with the expansion recommended for them.
+	// +optional
+	VolumeRecommendations []VolumeRecommendation `json:"volumeRecommendations,omitempty"`
+	// RecordingRule is the name of the PrometheusRule maintained for the
+	// profile's recordingRule.
+	// +optional
+	RecordingRule string `json:"recordingRule,omitempty"`
 	// +listType=map
 	// +listMapKey=type
 	// +optional
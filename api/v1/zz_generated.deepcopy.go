@@ -21,22 +21,553 @@ limitations under the License.
 package v1
 
 import (
+	"k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActionCount) DeepCopyInto(out *ActionCount) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActionCount.
+func (in *ActionCount) DeepCopy() *ActionCount {
+	if in == nil {
+		return nil
+	}
+	out := new(ActionCount)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ActionDetail) DeepCopyInto(out *ActionDetail) {
 	*out = *in
-	in.Timestamp.DeepCopyInto(&out.Timestamp)
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]TargetResult, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActionDetail.
+func (in *ActionDetail) DeepCopy() *ActionDetail {
+	if in == nil {
+		return nil
+	}
+	out := new(ActionDetail)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActionRule) DeepCopyInto(out *ActionRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActionRule.
+func (in *ActionRule) DeepCopy() *ActionRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ActionRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActionSuspension) DeepCopyInto(out *ActionSuspension) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActionSuspension.
+func (in *ActionSuspension) DeepCopy() *ActionSuspension {
+	if in == nil {
+		return nil
+	}
+	out := new(ActionSuspension)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertTrigger) DeepCopyInto(out *AlertTrigger) {
+	*out = *in
+	if in.MatchLabels != nil {
+		in, out := &in.MatchLabels, &out.MatchLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertTrigger.
+func (in *AlertTrigger) DeepCopy() *AlertTrigger {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertTrigger)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BusinessHours) DeepCopyInto(out *BusinessHours) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BusinessHours.
+func (in *BusinessHours) DeepCopy() *BusinessHours {
+	if in == nil {
+		return nil
+	}
+	out := new(BusinessHours)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudEventsChannel) DeepCopyInto(out *CloudEventsChannel) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudEventsChannel.
+func (in *CloudEventsChannel) DeepCopy() *CloudEventsChannel {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudEventsChannel)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Confidence) DeepCopyInto(out *Confidence) {
+	*out = *in
+	out.HistoryLength = in.HistoryLength
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Confidence.
+func (in *Confidence) DeepCopy() *Confidence {
+	if in == nil {
+		return nil
+	}
+	out := new(Confidence)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaChannel) DeepCopyInto(out *GrafanaChannel) {
+	*out = *in
+	in.APITokenSecretRef.DeepCopyInto(&out.APITokenSecretRef)
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaChannel.
+func (in *GrafanaChannel) DeepCopy() *GrafanaChannel {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaChannel)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InitialEstimate) DeepCopyInto(out *InitialEstimate) {
+	*out = *in
+	if in.CPURequest != nil {
+		in, out := &in.CPURequest, &out.CPURequest
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InitialEstimate.
+func (in *InitialEstimate) DeepCopy() *InitialEstimate {
+	if in == nil {
+		return nil
+	}
+	out := new(InitialEstimate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LatencyObjective) DeepCopyInto(out *LatencyObjective) {
+	*out = *in
+	if in.Percentile != nil {
+		in, out := &in.Percentile, &out.Percentile
+		*out = new(int32)
+		**out = **in
+	}
+	out.Threshold = in.Threshold
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LatencyObjective.
+func (in *LatencyObjective) DeepCopy() *LatencyObjective {
+	if in == nil {
+		return nil
+	}
+	out := new(LatencyObjective)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationChannel) DeepCopyInto(out *NotificationChannel) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationChannel.
+func (in *NotificationChannel) DeepCopy() *NotificationChannel {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationChannel)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NotificationChannel) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationChannelList) DeepCopyInto(out *NotificationChannelList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NotificationChannel, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationChannelList.
+func (in *NotificationChannelList) DeepCopy() *NotificationChannelList {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationChannelList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NotificationChannelList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationChannelSpec) DeepCopyInto(out *NotificationChannelSpec) {
+	*out = *in
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]NotificationEventKind, len(*in))
+		copy(*out, *in)
+	}
+	if in.CloudEvents != nil {
+		in, out := &in.CloudEvents, &out.CloudEvents
+		*out = new(CloudEventsChannel)
+		**out = **in
+	}
+	if in.SMTP != nil {
+		in, out := &in.SMTP, &out.SMTP
+		*out = new(SMTPChannel)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PagerDuty != nil {
+		in, out := &in.PagerDuty, &out.PagerDuty
+		*out = new(PagerDutyChannel)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Grafana != nil {
+		in, out := &in.Grafana, &out.Grafana
+		*out = new(GrafanaChannel)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationChannelSpec.
+func (in *NotificationChannelSpec) DeepCopy() *NotificationChannelSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationChannelSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationTarget) DeepCopyInto(out *NotificationTarget) {
+	*out = *in
+	out.ChannelRef = in.ChannelRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationTarget.
+func (in *NotificationTarget) DeepCopy() *NotificationTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OptimizationReport) DeepCopyInto(out *OptimizationReport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OptimizationReport.
+func (in *OptimizationReport) DeepCopy() *OptimizationReport {
+	if in == nil {
+		return nil
+	}
+	out := new(OptimizationReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OptimizationReport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OptimizationReportList) DeepCopyInto(out *OptimizationReportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OptimizationReport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OptimizationReportList.
+func (in *OptimizationReportList) DeepCopy() *OptimizationReportList {
+	if in == nil {
+		return nil
+	}
+	out := new(OptimizationReportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OptimizationReportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OptimizationReportSpec) DeepCopyInto(out *OptimizationReportSpec) {
+	*out = *in
+	in.PeriodStart.DeepCopyInto(&out.PeriodStart)
+	in.PeriodEnd.DeepCopyInto(&out.PeriodEnd)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OptimizationReportSpec.
+func (in *OptimizationReportSpec) DeepCopy() *OptimizationReportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OptimizationReportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OptimizationReportStatus) DeepCopyInto(out *OptimizationReportStatus) {
+	*out = *in
+	in.GeneratedAt.DeepCopyInto(&out.GeneratedAt)
+	if in.Actions != nil {
+		in, out := &in.Actions, &out.Actions
+		*out = make([]ActionCount, len(*in))
+		copy(*out, *in)
+	}
+	out.CPURequestsReleased = in.CPURequestsReleased.DeepCopy()
+	if in.TopOverProvisioned != nil {
+		in, out := &in.TopOverProvisioned, &out.TopOverProvisioned
+		*out = make([]OverProvisionedWorkload, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ArchivedAt != nil {
+		in, out := &in.ArchivedAt, &out.ArchivedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OptimizationReportStatus.
+func (in *OptimizationReportStatus) DeepCopy() *OptimizationReportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OptimizationReportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OverProvisionedWorkload) DeepCopyInto(out *OverProvisionedWorkload) {
+	*out = *in
+	out.CPURequest = in.CPURequest.DeepCopy()
+	out.RecommendedCPURequest = in.RecommendedCPURequest.DeepCopy()
+	out.Excess = in.Excess.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OverProvisionedWorkload.
+func (in *OverProvisionedWorkload) DeepCopy() *OverProvisionedWorkload {
+	if in == nil {
+		return nil
+	}
+	out := new(OverProvisionedWorkload)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyChannel) DeepCopyInto(out *PagerDutyChannel) {
+	*out = *in
+	in.RoutingKeySecretRef.DeepCopyInto(&out.RoutingKeySecretRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyChannel.
+func (in *PagerDutyChannel) DeepCopy() *PagerDutyChannel {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyChannel)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PercentileRecommendation) DeepCopyInto(out *PercentileRecommendation) {
+	*out = *in
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Percentile != nil {
+		in, out := &in.Percentile, &out.Percentile
+		*out = new(int32)
+		**out = **in
+	}
+	if in.HeadroomPercent != nil {
+		in, out := &in.HeadroomPercent, &out.HeadroomPercent
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PercentileRecommendation.
+func (in *PercentileRecommendation) DeepCopy() *PercentileRecommendation {
+	if in == nil {
+		return nil
+	}
+	out := new(PercentileRecommendation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PriorityScaling) DeepCopyInto(out *PriorityScaling) {
+	*out = *in
+	if in.ProtectedPriority != nil {
+		in, out := &in.ProtectedPriority, &out.ProtectedPriority
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActionDetail.
-func (in *ActionDetail) DeepCopy() *ActionDetail {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PriorityScaling.
+func (in *PriorityScaling) DeepCopy() *PriorityScaling {
 	if in == nil {
 		return nil
 	}
-	out := new(ActionDetail)
+	out := new(PriorityScaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueueTrigger) DeepCopyInto(out *QueueTrigger) {
+	*out = *in
+	if in.ScaleToZeroAfter != nil {
+		in, out := &in.ScaleToZeroAfter, &out.ScaleToZeroAfter
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueueTrigger.
+func (in *QueueTrigger) DeepCopy() *QueueTrigger {
+	if in == nil {
+		return nil
+	}
+	out := new(QueueTrigger)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequestsPerSecond) DeepCopyInto(out *RequestsPerSecond) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequestsPerSecond.
+func (in *RequestsPerSecond) DeepCopy() *RequestsPerSecond {
+	if in == nil {
+		return nil
+	}
+	out := new(RequestsPerSecond)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -105,6 +636,11 @@ func (in *ResourceOptimizerProfileSpec) DeepCopyInto(out *ResourceOptimizerProfi
 	*out = *in
 	in.Selector.DeepCopyInto(&out.Selector)
 	out.CPUThresholds = in.CPUThresholds
+	if in.BusinessHours != nil {
+		in, out := &in.BusinessHours, &out.BusinessHours
+		*out = new(BusinessHours)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.CooldownPeriod != nil {
 		in, out := &in.CooldownPeriod, &out.CooldownPeriod
 		*out = new(metav1.Duration)
@@ -120,6 +656,122 @@ func (in *ResourceOptimizerProfileSpec) DeepCopyInto(out *ResourceOptimizerProfi
 		x := (*in).DeepCopy()
 		*out = &x
 	}
+	if in.MaxResizePercent != nil {
+		in, out := &in.MaxResizePercent, &out.MaxResizePercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PercentileRecommendation != nil {
+		in, out := &in.PercentileRecommendation, &out.PercentileRecommendation
+		*out = new(PercentileRecommendation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UsageHistory != nil {
+		in, out := &in.UsageHistory, &out.UsageHistory
+		*out = new(UsageHistory)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InitialCPURequest != nil {
+		in, out := &in.InitialCPURequest, &out.InitialCPURequest
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = make([]NotificationTarget, len(*in))
+		copy(*out, *in)
+	}
+	if in.HoldOnAlerts != nil {
+		in, out := &in.HoldOnAlerts, &out.HoldOnAlerts
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AlertTriggers != nil {
+		in, out := &in.AlertTriggers, &out.AlertTriggers
+		*out = make([]AlertTrigger, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RequestsPerSecond != nil {
+		in, out := &in.RequestsPerSecond, &out.RequestsPerSecond
+		*out = new(RequestsPerSecond)
+		**out = **in
+	}
+	if in.LatencyObjective != nil {
+		in, out := &in.LatencyObjective, &out.LatencyObjective
+		*out = new(LatencyObjective)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.QueueTriggers != nil {
+		in, out := &in.QueueTriggers, &out.QueueTriggers
+		*out = make([]QueueTrigger, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ScaleStep != nil {
+		in, out := &in.ScaleStep, &out.ScaleStep
+		*out = new(ScaleStep)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxReplicaChange != nil {
+		in, out := &in.MaxReplicaChange, &out.MaxReplicaChange
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Behavior != nil {
+		in, out := &in.Behavior, &out.Behavior
+		*out = new(v2.HorizontalPodAutoscalerBehavior)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StatefulSets != nil {
+		in, out := &in.StatefulSets, &out.StatefulSets
+		*out = new(StatefulSetScaling)
+		**out = **in
+	}
+	if in.TopologySpread != nil {
+		in, out := &in.TopologySpread, &out.TopologySpread
+		*out = new(TopologySpread)
+		**out = **in
+	}
+	if in.Priorities != nil {
+		in, out := &in.Priorities, &out.Priorities
+		*out = new(PriorityScaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeExpansion != nil {
+		in, out := &in.VolumeExpansion, &out.VolumeExpansion
+		*out = new(VolumeExpansion)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ActionPolicy != nil {
+		in, out := &in.ActionPolicy, &out.ActionPolicy
+		*out = make([]ActionRule, len(*in))
+		copy(*out, *in)
+	}
+	if in.MinConfidence != nil {
+		in, out := &in.MinConfidence, &out.MinConfidence
+		*out = new(int32)
+		**out = **in
+	}
+	if in.QueryTimeout != nil {
+		in, out := &in.QueryTimeout, &out.QueryTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RecommendationTTL != nil {
+		in, out := &in.RecommendationTTL, &out.RecommendationTTL
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceOptimizerProfileSpec.
@@ -152,6 +804,46 @@ func (in *ResourceOptimizerProfileStatus) DeepCopyInto(out *ResourceOptimizerPro
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.RecommendedAt != nil {
+		in, out := &in.RecommendedAt, &out.RecommendedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Diff != nil {
+		in, out := &in.Diff, &out.Diff
+		*out = make([]TargetDiff, len(*in))
+		copy(*out, *in)
+	}
+	if in.InitialEstimates != nil {
+		in, out := &in.InitialEstimates, &out.InitialEstimates
+		*out = make([]InitialEstimate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Confidence != nil {
+		in, out := &in.Confidence, &out.Confidence
+		*out = new(Confidence)
+		**out = **in
+	}
+	if in.FailingTargets != nil {
+		in, out := &in.FailingTargets, &out.FailingTargets
+		*out = make([]TargetFailure, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ActionsSuspended != nil {
+		in, out := &in.ActionsSuspended, &out.ActionsSuspended
+		*out = new(ActionSuspension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeRecommendations != nil {
+		in, out := &in.VolumeRecommendations, &out.VolumeRecommendations
+		*out = make([]VolumeRecommendation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -171,6 +863,118 @@ func (in *ResourceOptimizerProfileStatus) DeepCopy() *ResourceOptimizerProfileSt
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SMTPChannel) DeepCopyInto(out *SMTPChannel) {
+	*out = *in
+	if in.To != nil {
+		in, out := &in.To, &out.To
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PasswordSecretRef != nil {
+		in, out := &in.PasswordSecretRef, &out.PasswordSecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SMTPChannel.
+func (in *SMTPChannel) DeepCopy() *SMTPChannel {
+	if in == nil {
+		return nil
+	}
+	out := new(SMTPChannel)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaleStep) DeepCopyInto(out *ScaleStep) {
+	*out = *in
+	if in.Up != nil {
+		in, out := &in.Up, &out.Up
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.Down != nil {
+		in, out := &in.Down, &out.Down
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleStep.
+func (in *ScaleStep) DeepCopy() *ScaleStep {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaleStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetScaling) DeepCopyInto(out *StatefulSetScaling) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetScaling.
+func (in *StatefulSetScaling) DeepCopy() *StatefulSetScaling {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetScaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetDiff) DeepCopyInto(out *TargetDiff) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetDiff.
+func (in *TargetDiff) DeepCopy() *TargetDiff {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetDiff)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetFailure) DeepCopyInto(out *TargetFailure) {
+	*out = *in
+	in.LastFailureTime.DeepCopyInto(&out.LastFailureTime)
+	in.RetryAfter.DeepCopyInto(&out.RetryAfter)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetFailure.
+func (in *TargetFailure) DeepCopy() *TargetFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetResult) DeepCopyInto(out *TargetResult) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetResult.
+func (in *TargetResult) DeepCopy() *TargetResult {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ThresholdSpec) DeepCopyInto(out *ThresholdSpec) {
 	*out = *in
@@ -185,3 +989,94 @@ func (in *ThresholdSpec) DeepCopy() *ThresholdSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologySpread) DeepCopyInto(out *TopologySpread) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologySpread.
+func (in *TopologySpread) DeepCopy() *TopologySpread {
+	if in == nil {
+		return nil
+	}
+	out := new(TopologySpread)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UsageHistory) DeepCopyInto(out *UsageHistory) {
+	*out = *in
+	if in.HalfLife != nil {
+		in, out := &in.HalfLife, &out.HalfLife
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Percentile != nil {
+		in, out := &in.Percentile, &out.Percentile
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UsageHistory.
+func (in *UsageHistory) DeepCopy() *UsageHistory {
+	if in == nil {
+		return nil
+	}
+	out := new(UsageHistory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeExpansion) DeepCopyInto(out *VolumeExpansion) {
+	*out = *in
+	if in.ThresholdPercent != nil {
+		in, out := &in.ThresholdPercent, &out.ThresholdPercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.IncreasePercent != nil {
+		in, out := &in.IncreasePercent, &out.IncreasePercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeExpansion.
+func (in *VolumeExpansion) DeepCopy() *VolumeExpansion {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeExpansion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeRecommendation) DeepCopyInto(out *VolumeRecommendation) {
+	*out = *in
+	out.Capacity = in.Capacity.DeepCopy()
+	if in.RecommendedSize != nil {
+		in, out := &in.RecommendedSize, &out.RecommendedSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeRecommendation.
+func (in *VolumeRecommendation) DeepCopy() *VolumeRecommendation {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeRecommendation)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -17,32 +17,62 @@ limitations under the License.
 package main
 
 import (
-	"bytes"
+	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"flag"
-	"html/template"
+	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/archive"
+	"github.com/OpScaleHub/K20s/internal/audit"
+	"github.com/OpScaleHub/K20s/internal/cli"
 	"github.com/OpScaleHub/K20s/internal/controller"
+	"github.com/OpScaleHub/K20s/internal/dashboard"
+	"github.com/OpScaleHub/K20s/internal/export"
+	"github.com/OpScaleHub/K20s/internal/grpcapi"
+	"github.com/OpScaleHub/K20s/internal/monitoring"
+	"github.com/OpScaleHub/K20s/internal/notify"
+	"github.com/OpScaleHub/K20s/internal/preflight"
+	"github.com/OpScaleHub/K20s/internal/pricing"
+	"github.com/OpScaleHub/K20s/internal/report"
+	webhookv1 "github.com/OpScaleHub/K20s/internal/webhook/v1"
 	// +kubebuilder:scaffold:imports
 )
 
+// metricsServicePortName is the name of the metrics port in
+// config/default/metrics_service.yaml.
+const metricsServicePortName = "http-metrics-status"
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
@@ -56,32 +86,320 @@ func init() {
 }
 
 func main() {
-	var metricsAddr string
-	var enableLeaderElection bool
-	var probeAddr string
-	var secureMetrics bool
-	var enableHTTP2 bool
-	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
-	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
-	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
+	if err := newRootCommand().ExecuteContext(ctrl.SetupSignalHandler()); err != nil {
+		os.Exit(1)
+	}
+}
+
+// newRootCommand returns the command line of the binary. Without a subcommand
+// it runs the controller manager, so deployments that only pass flags keep
+// working.
+func newRootCommand() *cobra.Command {
+	o := &managerOptions{}
+	root := &cobra.Command{
+		Use:   "k20s",
+		Short: "K20s scales and resizes workloads based on their CPU utilization",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			runManager(cmd.Context(), o)
+			return nil
+		},
+	}
+	run := &cobra.Command{
+		Use:   "run",
+		Short: "Run the controller manager (the default)",
+		Args:  cobra.NoArgs,
+		RunE:  root.RunE,
+	}
+	flags := o.flagSet()
+	root.Flags().AddFlagSet(flags)
+	run.Flags().AddFlagSet(flags)
+	root.AddCommand(
+		run,
+		cli.NewScanCommand(),
+		cli.NewSimulateCommand(),
+		cli.NewLintCommand(),
+		cli.NewImportCommand(),
+		cli.NewVersionCommand(),
+	)
+	return root
+}
+
+// managerOptions holds the flags of the controller manager.
+type managerOptions struct {
+	metricsAddr                    string
+	enableLeaderElection           bool
+	probeAddr                      string
+	secureMetrics                  bool
+	enableHTTP2                    bool
+	enableWebhooks                 bool
+	denyOverlappingProfiles        bool
+	protectedNamespaces            []string
+	skipStartupChecks              bool
+	namespaces                     controller.NamespaceFilter
+	clusterAutoscalerAware         bool
+	checkCapacity                  bool
+	clusterAutoscalerStatus        string
+	compactAfterResizeDown         bool
+	compactor                      controller.Compactor
+	alertmanagerURL                string
+	opaURL                         string
+	disableDownwardActions         bool
+	protectedWorkloads             []string
+	targetRetryBudget              int
+	actionFailureBudget            int
+	queryTimeout                   time.Duration
+	partialResponsePolicy          string
+	metricsCacheTTL                time.Duration
+	requeueJitter                  float64
+	businessHours                  string
+	businessDays                   []string
+	businessHoursTimeZone          string
+	exportRecommendations          bool
+	weeklyReports                  bool
+	archiveURL                     string
+	archiveSecret                  string
+	archiveInterval                time.Duration
+	maxMetricProfiles              int
+	maxMetricTargets               int
+	prometheusUnreachableThreshold time.Duration
+	prometheusTLS                  controller.PrometheusTLSConfig
+	createPrometheusRule           bool
+	createServiceMonitor           bool
+	metricsServiceName             string
+	monitoringLabels               string
+	auditLogPath                   string
+	actionHistorySize              int
+	cpuHistorySize                 int
+	serviceIdentityKey             string
+	grpcAddr                       string
+	grpcCertPath                   string
+	grpcCertName                   string
+	grpcCertKey                    string
+	grpcInsecure                   bool
+	grpcClientCAFile               string
+	grpcAllowUnauthenticated       bool
+	dashboardAddr                  string
+	dashboardCertPath              string
+	dashboardCertName              string
+	dashboardCertKey               string
+	dashboardAuth                  bool
+	dashboardAuthOpts              dashboard.AuthOptions
+	cloudEventsSinkURL             string
+	smtpConfig                     notify.SMTPConfig
+	smtpTo                         string
+	enablePagerDuty                bool
+	pagerDutyFailureThreshold      int
+	pricingConfigMap               string
+	pricingRegion                  string
+	grafanaConfig                  notify.GrafanaConfig
+	grafanaTags                    string
+	zapOpts                        zap.Options
+}
+
+// flagSet returns the flags of the controller manager bound to o.
+func (o *managerOptions) flagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("manager", pflag.ContinueOnError)
+	fs.StringVar(&o.metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
+	fs.StringVar(&o.probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	fs.BoolVar(&o.enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
-	flag.BoolVar(&secureMetrics, "metrics-secure", false,
-		"If set the metrics endpoint is served securely")
-	flag.BoolVar(&enableHTTP2, "enable-http2", false,
-		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
-	opts := zap.Options{
+	fs.BoolVar(&o.secureMetrics, "metrics-secure", false,
+		"If set the metrics endpoint is served securely and only to callers authorized to get its paths")
+	fs.BoolVar(&o.enableHTTP2, "enable-http2", false,
+		"If set, HTTP/2 will be enabled for the metrics, webhook and dashboard servers")
+	fs.BoolVar(&o.enableWebhooks, "enable-webhooks", false,
+		"If set, the validating webhook for ResourceOptimizerProfiles and the mutating webhook for Pods are served. "+
+			"Requires a serving certificate in the webhook server's certificate directory.")
+	fs.BoolVar(&o.denyOverlappingProfiles, "deny-overlapping-profiles", false,
+		"If set, the webhook rejects profiles whose selector overlaps another profile in the namespace, "+
+			"instead of admitting them with a warning")
+	fs.StringSliceVar(&o.protectedNamespaces, "protected-namespaces", webhookv1.DefaultProtectedNamespaces,
+		"Comma-separated namespaces the webhook only admits profiles in when they are annotated with "+
+			optimizerv1.AllowProtectedNamespaceAnnotation+"=true. The controller's own namespace is always protected.")
+	fs.BoolVar(&o.skipStartupChecks, "skip-startup-checks", false,
+		"If set, the controller starts without checking that the ResourceOptimizerProfile CRD is installed "+
+			"and that it may list and patch Deployments and StatefulSets")
+	fs.StringSliceVar(&o.namespaces.Deny, "namespace-denylist", controller.DefaultNamespaceDenylist,
+		"Comma-separated namespaces whose workloads are never changed. Profiles there are rejected by the webhook "+
+			"and marked Degraded.")
+	fs.StringSliceVar(&o.namespaces.Allow, "namespace-allowlist", nil,
+		"If set, comma-separated namespaces outside of which workloads are never changed")
+	fs.BoolVar(&o.clusterAutoscalerAware, "cluster-autoscaler-aware", false,
+		"If set, scale-ups are deferred while a profile's pods are unschedulable or the Cluster Autoscaler is adding nodes")
+	fs.StringVar(&o.clusterAutoscalerStatus, "cluster-autoscaler-status", controller.DefaultClusterAutoscalerStatus.String(),
+		"The namespace/name of the ConfigMap the Cluster Autoscaler writes its status to, used by --cluster-autoscaler-aware")
+	fs.BoolVar(&o.checkCapacity, "check-capacity", false,
+		"If set, scale-ups are limited to the replicas that fit, by CPU requests, on the nodes a target's pods may be "+
+			"scheduled on given their nodeSelector, node affinity and tolerations")
+	fs.BoolVar(&o.compactAfterResizeDown, "compact-after-resize-down", false,
+		"If set, after a resize down the pods of the resized targets are evicted from lightly requested nodes, "+
+			"respecting PodDisruptionBudgets, so the scheduler packs them onto fewer nodes")
+	fs.Float64Var(&o.compactor.UtilizationThreshold, "compaction-utilization-threshold", controller.DefaultCompactionUtilizationThreshold,
+		"The percentage of a node's allocatable CPU requested below which --compact-after-resize-down evicts its pods")
+	fs.IntVar(&o.compactor.MaxEvictions, "compaction-max-evictions", controller.DefaultCompactionMaxEvictions,
+		"The maximum number of pods --compact-after-resize-down evicts after one resize down")
+	fs.StringVar(&o.alertmanagerURL, "alertmanager-url", "",
+		"The URL of the Alertmanager consulted for profiles with spec.holdOnAlerts, e.g. http://alertmanager-operated:9093. "+
+			"If empty, holdOnAlerts is ignored.")
+	fs.StringVar(&o.opaURL, "opa-url", "",
+		"The URL of an Open Policy Agent decision that must approve every action before it is applied, "+
+			"e.g. http://opa:8181/v1/data/k20s/allow. If empty, actions need no approval.")
+	fs.BoolVar(&o.disableDownwardActions, "disable-downward-actions", false,
+		"If set, no profile scales or resizes anything down, while scale-ups and resize-ups still go ahead. "+
+			"Use it to freeze resource reductions, e.g. during incidents or peak seasons.")
+	fs.StringArrayVar(&o.protectedWorkloads, "protected-workloads", nil,
+		"A label selector, e.g. app.kubernetes.io/part-of=observability, of Deployments and StatefulSets that are never "+
+			"scaled, resized or annotated, whatever the profiles selecting them say. May be repeated.")
+	fs.DurationVar(&o.queryTimeout, "query-timeout", controller.DefaultQueryTimeout,
+		"How long each Prometheus query of a reconcile may take before it is cancelled, "+
+			"unless the profile sets spec.queryTimeout. Use 0 to let queries run as long as Prometheus takes.")
+	fs.StringVar(&o.partialResponsePolicy, "partial-response-policy", controller.PartialResponseWarn,
+		"What happens when a Prometheus query returns warnings, such as the partial responses of Thanos, "+
+			"unless the profile sets spec.partialResponsePolicy: Fail fails the query, Warn logs the warnings and uses the result, "+
+			"and Skip takes no action until a later reconcile gets a complete result.")
+	fs.DurationVar(&o.metricsCacheTTL, "metrics-cache-ttl", 0,
+		"How long the result of a Prometheus query is reused by later reconciles running the same query, "+
+			"so bursts of watch-driven reconciles of a profile query Prometheus once. Use 0 to disable the cache.")
+	fs.Float64Var(&o.requeueJitter, "requeue-jitter", controller.DefaultRequeueJitter,
+		"The fraction of the 5 minute reconcile interval added at random to each requeue, so profiles created at the same "+
+			"moment spread their Prometheus queries and API requests out. Use 0 to requeue every profile exactly 5 minutes later.")
+	fs.StringVar(&o.businessHours, "business-hours", "",
+		"If set, as HH:MM-HH:MM, e.g. 09:00-18:00, scale-downs and resize-downs are deferred to outside these hours "+
+			"on --business-days. Profiles can override it with spec.businessHours.")
+	fs.StringSliceVar(&o.businessDays, "business-days", controller.DefaultBusinessDays,
+		"Comma-separated days, e.g. Monday,Tuesday, on which --business-hours apply")
+	fs.StringVar(&o.businessHoursTimeZone, "business-hours-time-zone", "UTC",
+		"The IANA time zone of --business-hours, e.g. Europe/Berlin")
+	fs.IntVar(&o.targetRetryBudget, "target-retry-budget", controller.DefaultTargetRetryBudget,
+		"The number of consecutive failed actions after which a target is marked Degraded in its profile's status. "+
+			"Failing targets are retried with an exponential backoff while the profile's other targets are still managed.")
+	fs.IntVar(&o.actionFailureBudget, "action-failure-budget", controller.DefaultActionFailureBudget,
+		"The number of consecutive failed actions after which a profile is marked Degraded and its actions are suspended. "+
+			"Its metrics are still observed, and its actions resume once its spec is edited or it is annotated with "+
+			"optimizer.k20s.opscale.ir/resume-actions=true.")
+	fs.IntVar(&o.maxMetricProfiles, "metrics-max-profiles", controller.DefaultMaxMetricProfiles,
+		"Maximum number of profiles exported with their own namespace/profile metric labels. "+
+			"Additional profiles are aggregated under the \"_other\" label value. Set to 0 to disable the limit.")
+	fs.IntVar(&o.maxMetricTargets, "metrics-max-targets", controller.DefaultMaxMetricTargets,
+		"Maximum number of targets per profile exported by the per-target CPU gauges, in kind and name order. "+
+			"Set to 0 to disable the limit.")
+	fs.DurationVar(&o.prometheusUnreachableThreshold, "prometheus-unreachable-threshold", 5*time.Minute,
+		"The readiness check fails until Prometheus answers a query and once every query has failed for longer than this. "+
+			"Use 0 to disable the check.")
+	o.prometheusTLS.BindFlags(fs)
+	fs.BoolVar(&o.createPrometheusRule, "create-prometheus-rule", false,
+		"If set, the controller maintains a PrometheusRule with alerts on its own health "+
+			"when the Prometheus Operator CRDs are installed")
+	fs.BoolVar(&o.createServiceMonitor, "create-service-monitor", false,
+		"If set, the controller maintains a ServiceMonitor for its metrics Service "+
+			"when the Prometheus Operator CRDs are installed")
+	fs.StringVar(&o.metricsServiceName, "metrics-service-name", "k20s-controller-manager-metrics-service",
+		"The name of the Service exposing the metrics endpoint, used by --create-service-monitor")
+	fs.StringVar(&o.monitoringLabels, "monitoring-labels", "",
+		"Comma-separated key=value labels added to Prometheus Operator objects created by the controller, "+
+			"including the recording rules of profiles, e.g. release=prometheus to match the operator's rule selector")
+	fs.BoolVar(&o.exportRecommendations, "export-recommendations", false,
+		"If set, the current recommendations of the profiles in each namespace are published as YAML and JSON "+
+			"in a ConfigMap named "+export.ConfigMapName)
+	fs.BoolVar(&o.weeklyReports, "weekly-reports", false,
+		"If set, an OptimizationReport summarizing the past week is generated every Monday in each namespace "+
+			"with profiles, and delivered to the namespace's notification channels listing report events")
+	fs.StringVar(&o.archiveURL, "archive-url", "",
+		"If set, actions and recommendations are uploaded as CSV, and reports as JSON, Markdown and HTML, to this "+
+			"object storage location: s3://bucket/prefix?region=..., gs://bucket/prefix or azblob://account/container/prefix")
+	fs.StringVar(&o.archiveSecret, "archive-secret", "",
+		"The namespace/name of the Secret holding the credentials of --archive-url")
+	fs.DurationVar(&o.archiveInterval, "archive-interval", archive.DefaultInterval,
+		"How often exports are uploaded to --archive-url")
+	fs.StringVar(&o.auditLogPath, "audit-log-path", "",
+		"If set, every patch applied to a workload is appended as a JSON line to this file. "+
+			"Use \"-\" to write the audit trail to stdout.")
+	fs.IntVar(&o.actionHistorySize, "action-history-size", audit.DefaultHistorySize,
+		"The number of recent workload patches kept in memory and served by /api/v1/actions")
+	fs.StringVar(&o.serviceIdentityKey, "service-identity-key", dashboard.DefaultServiceKey,
+		"The label, or annotation, naming the service of a workload for /api/v1/services")
+	fs.IntVar(&o.cpuHistorySize, "cpu-history-size", dashboard.DefaultCPUHistorySize,
+		"The number of CPU samples per profile kept in memory and drawn as sparklines on the status page")
+	fs.StringVar(&o.dashboardAddr, "dashboard-bind-address", "",
+		"The address the status pages and API bind to, e.g. :8082. "+
+			"If empty they are served by the metrics server and share its TLS and authentication settings.")
+	fs.StringVar(&o.dashboardCertPath, "dashboard-cert-path", "",
+		"The directory that contains the dashboard server certificate. "+
+			"If empty the server started by --dashboard-bind-address serves plain HTTP.")
+	fs.StringVar(&o.dashboardCertName, "dashboard-cert-name", "tls.crt", "The name of the dashboard server certificate file.")
+	fs.StringVar(&o.dashboardCertKey, "dashboard-cert-key", "tls.key", "The name of the dashboard server key file.")
+	fs.BoolVar(&o.dashboardAuth, "dashboard-auth", false,
+		"If set, the status page, the HTTP API and the gRPC API require a bearer token accepted by the Kubernetes API server "+
+			"(verified with a TokenReview) or, with --dashboard-oidc-issuer-url, an ID token of that issuer")
+	fs.BoolVar(&o.dashboardAuthOpts.Authorize, "dashboard-authorize", false,
+		"If set with --dashboard-auth, users must be allowed to get or list resourceoptimizerprofiles "+
+			"in the namespace they read, as checked with a SubjectAccessReview")
+	fs.StringVar(&o.dashboardAuthOpts.OIDCIssuerURL, "dashboard-oidc-issuer-url", "",
+		"The HTTPS URL of an OpenID Connect issuer whose ID tokens are accepted by the status page and API")
+	fs.StringVar(&o.dashboardAuthOpts.OIDCClientID, "dashboard-oidc-client-id", "",
+		"The client ID the OIDC ID tokens must be issued for")
+	fs.StringVar(&o.dashboardAuthOpts.OIDCUsernameClaim, "dashboard-oidc-username-claim", "sub",
+		"The OIDC claim used as the user name")
+	fs.StringVar(&o.dashboardAuthOpts.OIDCGroupsClaim, "dashboard-oidc-groups-claim", "",
+		"The OIDC claim holding the user's groups")
+	fs.StringVar(&o.grpcAddr, "grpc-bind-address", "0",
+		"The address the gRPC API binds to, e.g. :9090. Use 0 to disable the gRPC API.")
+	fs.StringVar(&o.grpcCertPath, "grpc-cert-path", "",
+		"The directory that contains the gRPC server certificate. Required unless --grpc-insecure is set.")
+	fs.StringVar(&o.grpcCertName, "grpc-cert-name", "tls.crt", "The name of the gRPC server certificate file.")
+	fs.StringVar(&o.grpcCertKey, "grpc-cert-key", "tls.key", "The name of the gRPC server key file.")
+	fs.BoolVar(&o.grpcInsecure, "grpc-insecure", false,
+		"If set, the gRPC API is served without TLS. Only use this behind a mesh or for local development.")
+	fs.StringVar(&o.grpcClientCAFile, "grpc-client-ca-file", "",
+		"A PEM bundle of the CAs that sign the client certificates the gRPC API requires.")
+	fs.BoolVar(&o.grpcAllowUnauthenticated, "grpc-allow-unauthenticated", false,
+		"If set, the gRPC API answers clients without a certificate. Anyone who can reach its port can read every profile.")
+	fs.StringVar(&o.cloudEventsSinkURL, "cloudevents-sink-url", "",
+		"If set, a CloudEvent is posted to this HTTP endpoint for every applied action and recommendation, "+
+			"e.g. a Knative Eventing broker or an Argo Events webhook source")
+	fs.StringVar(&o.smtpConfig.Addr, "smtp-addr", "",
+		"If set, notifications are mailed through the SMTP server at this host:port. "+
+			"The password for --smtp-username is read from the SMTP_PASSWORD environment variable.")
+	fs.StringVar(&o.smtpConfig.From, "smtp-from", "", "The sender address of notification mails")
+	fs.StringVar(&o.smtpTo, "smtp-to", "", "Comma-separated recipient addresses of notification mails")
+	fs.StringVar(&o.smtpConfig.Username, "smtp-username", "", "If set, the SMTP server is authenticated against with this username")
+	fs.BoolVar(&o.smtpConfig.Digest, "smtp-digest", false,
+		"If set, one mail per profile per day summarizes its actions and recommendations instead of one mail per event")
+	fs.BoolVar(&o.enablePagerDuty, "enable-pagerduty", false,
+		"If set, a PagerDuty incident is triggered when a profile's actions fail repeatedly. "+
+			"The integration key is read from the PAGERDUTY_ROUTING_KEY environment variable.")
+	fs.IntVar(&o.pagerDutyFailureThreshold, "pagerduty-failure-threshold", notify.DefaultPagerDutyFailureThreshold,
+		"The number of consecutive failed actions of a profile that triggers a PagerDuty incident")
+	fs.StringVar(&o.pricingConfigMap, "pricing-configmap", "",
+		"If set, the namespace/name of a ConfigMap listing the hourly price of one CPU core per region, "+
+			"used to estimate what the recommendations would save")
+	fs.StringVar(&o.pricingRegion, "pricing-region", pricing.DefaultRegion,
+		"The region whose price in --pricing-configmap applies to the cluster")
+	fs.StringVar(&o.grafanaConfig.URL, "grafana-url", "",
+		"If set, an annotation is created in the Grafana at this URL for every applied or failed action. "+
+			"The service account token is read from the GRAFANA_API_TOKEN environment variable.")
+	fs.StringVar(&o.grafanaConfig.DashboardUID, "grafana-dashboard-uid", "",
+		"If set, Grafana annotations are restricted to this dashboard instead of being organization wide")
+	fs.StringVar(&o.grafanaTags, "grafana-annotation-tags", "", "Comma-separated tags added to every Grafana annotation")
+	o.zapOpts = zap.Options{
 		Development: true,
 	}
-	opts.BindFlags(flag.CommandLine)
-	flag.Parse()
+	// flag.CommandLine also holds --kubeconfig, registered by controller-runtime.
+	o.zapOpts.BindFlags(flag.CommandLine)
+	fs.AddGoFlagSet(flag.CommandLine)
+	return fs
+}
 
-	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+// runManager runs the controller manager until ctx is cancelled.
+func runManager(ctx context.Context, o *managerOptions) {
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&o.zapOpts)))
 
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More info: https://github.com/kubernetes/kubernetes/issues/115413
 	var tlsOpts []func(*tls.Config)
-	if !enableHTTP2 {
+	if !o.enableHTTP2 {
 		tlsOpts = append(tlsOpts, func(c *tls.Config) {
 			c.NextProtos = []string{"http/1.1"}
 		})
@@ -92,22 +410,88 @@ func main() {
 		TLSOpts: tlsOpts,
 	})
 
-	// Create the status page handler. We will inject the client later to break a dependency cycle.
-	statusHandler := &StatusPageHandler{}
-
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme: scheme,
-		Metrics: server.Options{
-			BindAddress:   metricsAddr,
-			SecureServing: secureMetrics,
-			TLSOpts:       tlsOpts,
-			ExtraHandlers: map[string]http.Handler{
-				"/status": statusHandler,
-			},
-		},
+	// Create the status page and API handlers. We will inject the client later to break a dependency cycle.
+	actionHistory := audit.NewHistory(o.actionHistorySize)
+	liveEvents := dashboard.NewHub()
+	cpuHistory := dashboard.NewCPUHistory(o.cpuHistorySize, liveEvents)
+	prometheusHealth := &controller.PrometheusHealth{}
+	statusHandler := &dashboard.StatusPage{Actions: actionHistory, History: cpuHistory, Prometheus: prometheusHealth}
+	actionsHandler := &dashboard.ActionsPage{Actions: actionHistory}
+	apiHandler := dashboard.NewAPIHandler(nil, actionHistory, liveEvents)
+	apiHandler.ServiceKey = o.serviceIdentityKey
+
+	restConfig := ctrl.GetConfigOrDie()
+
+	if !o.skipStartupChecks {
+		clientset, err := kubernetes.NewForConfig(restConfig)
+		if err != nil {
+			setupLog.Error(err, "unable to create client for startup checks")
+			os.Exit(1)
+		}
+		if err := preflight.Check(ctx, clientset.Discovery(), clientset.AuthorizationV1().SelfSubjectAccessReviews()); err != nil {
+			setupLog.Error(err, "startup checks failed, see --skip-startup-checks")
+			os.Exit(1)
+		}
+	}
+
+	var statusPage, actionsPage, api http.Handler = statusHandler, actionsHandler, apiHandler
+	var dashboardAuth *dashboard.Auth
+	if o.dashboardAuth {
+		httpClient, err := rest.HTTPClientFor(restConfig)
+		if err != nil {
+			setupLog.Error(err, "unable to create HTTP client for dashboard authentication")
+			os.Exit(1)
+		}
+		dashboardAuth, err = dashboard.NewAuth(ctx, restConfig, httpClient, o.dashboardAuthOpts)
+		if err != nil {
+			setupLog.Error(err, "unable to set up dashboard authentication")
+			os.Exit(1)
+		}
+		statusPage, actionsPage, api = dashboardAuth.Wrap(statusHandler), dashboardAuth.Wrap(actionsHandler), dashboardAuth.Wrap(apiHandler)
+		setupLog.Info("dashboard authentication enabled", "authorize", o.dashboardAuthOpts.Authorize,
+			"oidcIssuer", o.dashboardAuthOpts.OIDCIssuerURL)
+	} else if o.dashboardAuthOpts.Authorize || o.dashboardAuthOpts.OIDCIssuerURL != "" {
+		setupLog.Error(errors.New("--dashboard-auth is required"), "invalid dashboard configuration")
+		os.Exit(1)
+	}
+	dashboardHandlers := map[string]http.Handler{
+		dashboard.StatusPath:       statusPage,
+		dashboard.StatusPath + "/": statusPage,
+		dashboard.ActionsPath:      actionsPage,
+		dashboard.APIPrefix:        api,
+		dashboard.DocsPath:         api,
+		dashboard.VersionPath:      dashboard.VersionHandler{},
+		dashboard.GrafanaPath:      dashboard.GrafanaHandler{},
+	}
+	var metricsExtraHandlers map[string]http.Handler
+	if o.dashboardAddr == "" {
+		metricsExtraHandlers = dashboardHandlers
+	}
+	if o.dashboardCertPath != "" && o.dashboardAddr == "" {
+		setupLog.Error(errors.New("--dashboard-cert-path requires --dashboard-bind-address"), "invalid dashboard configuration")
+		os.Exit(1)
+	}
+
+	metricsServerOptions := server.Options{
+		BindAddress:   o.metricsAddr,
+		SecureServing: o.secureMetrics,
+		TLSOpts:       tlsOpts,
+		ExtraHandlers: metricsExtraHandlers,
+	}
+	if o.secureMetrics {
+		// Only callers allowed to get the path, as checked with a TokenReview and
+		// a SubjectAccessReview, may read the metrics and the handlers served
+		// next to them. The metrics-reader and dashboard-reader ClusterRoles in
+		// config/rbac grant that access.
+		metricsServerOptions.FilterProvider = filters.WithAuthenticationAndAuthorization
+	}
+
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
+		Scheme:                 scheme,
+		Metrics:                metricsServerOptions,
 		WebhookServer:          webs,
-		HealthProbeBindAddress: probeAddr,
-		LeaderElection:         enableLeaderElection,
+		HealthProbeBindAddress: o.probeAddr,
+		LeaderElection:         o.enableLeaderElection,
 		LeaderElectionID:       "f8c0d2a.k20s.opscale.ir",
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
@@ -128,104 +512,423 @@ func main() {
 
 	// Inject the client into the status handler now that the manager is created.
 	statusHandler.Client = mgr.GetClient()
-	setupLog.Info("status page handler registered", "path", "/status")
+	apiHandler.Client = mgr.GetClient()
+	setupLog.Info("status page handler registered", "path", dashboard.StatusPath)
+	setupLog.Info("API handler registered", "path", dashboard.APIPrefix)
+
+	if o.dashboardAddr != "" {
+		dashboardServer := &dashboard.Server{Addr: o.dashboardAddr, Handlers: dashboardHandlers}
+		if o.dashboardCertPath != "" {
+			certWatcher, err := certwatcher.New(
+				filepath.Join(o.dashboardCertPath, o.dashboardCertName),
+				filepath.Join(o.dashboardCertPath, o.dashboardCertKey),
+			)
+			if err != nil {
+				setupLog.Error(err, "unable to initialize dashboard certificate watcher")
+				os.Exit(1)
+			}
+			if err := mgr.Add(certWatcher); err != nil {
+				setupLog.Error(err, "unable to add dashboard certificate watcher to manager")
+				os.Exit(1)
+			}
+			dashboardServer.TLSConfig = &tls.Config{
+				MinVersion:     tls.VersionTLS12,
+				GetCertificate: certWatcher.GetCertificate,
+			}
+			for _, opt := range tlsOpts {
+				opt(dashboardServer.TLSConfig)
+			}
+		}
+		if err := mgr.Add(dashboardServer); err != nil {
+			setupLog.Error(err, "unable to set up dashboard server")
+			os.Exit(1)
+		}
+	}
+
+	if err := liveEvents.WatchProfiles(ctx, mgr.GetCache()); err != nil {
+		setupLog.Error(err, "unable to watch profiles for live updates")
+		os.Exit(1)
+	}
+
+	auditRecorder := audit.Multi{actionHistory, liveEvents}
+	if o.auditLogPath != "" {
+		auditFile, err := audit.OpenFile(o.auditLogPath)
+		if err != nil {
+			setupLog.Error(err, "unable to open audit log", "path", o.auditLogPath)
+			os.Exit(1)
+		}
+		auditRecorder = append(auditRecorder, audit.NewJSONLinesRecorder(auditFile))
+		setupLog.Info("audit log enabled", "path", o.auditLogPath)
+	}
+	reportCollector := report.NewCollector()
+	if o.weeklyReports {
+		auditRecorder = append(auditRecorder, reportCollector)
+	}
+	var archiver *archive.Archiver
+	if o.archiveURL != "" {
+		location, err := archive.ParseURL(o.archiveURL)
+		if err != nil {
+			setupLog.Error(err, "invalid --archive-url")
+			os.Exit(1)
+		}
+		namespace, name, ok := strings.Cut(o.archiveSecret, "/")
+		if !ok || namespace == "" || name == "" {
+			setupLog.Error(fmt.Errorf("%q is not namespace/name", o.archiveSecret), "invalid --archive-secret")
+			os.Exit(1)
+		}
+		archiver = &archive.Archiver{
+			Client:            mgr.GetClient(),
+			SecretReader:      mgr.GetAPIReader(),
+			Location:          location,
+			CredentialsSecret: types.NamespacedName{Namespace: namespace, Name: name},
+			Actions:           &archive.ActionLog{},
+			Interval:          o.archiveInterval,
+		}
+		auditRecorder = append(auditRecorder, archiver.Actions)
+		setupLog.Info("archiving to object storage", "url", o.archiveURL)
+	}
+
+	var notifiers notify.Multi
+	if o.cloudEventsSinkURL != "" {
+		notifiers = append(notifiers, notify.NewCloudEventsNotifier(o.cloudEventsSinkURL))
+		setupLog.Info("CloudEvents notifications enabled", "sink", o.cloudEventsSinkURL)
+	}
+	if o.smtpConfig.Addr != "" {
+		if o.smtpConfig.From == "" || o.smtpTo == "" {
+			setupLog.Error(errors.New("--smtp-from and --smtp-to are required"), "invalid SMTP configuration")
+			os.Exit(1)
+		}
+		o.smtpConfig.To = strings.Split(o.smtpTo, ",")
+		o.smtpConfig.Password = os.Getenv("SMTP_PASSWORD")
+		smtpNotifier := notify.NewSMTPNotifier(o.smtpConfig)
+		if err := mgr.Add(smtpNotifier); err != nil {
+			setupLog.Error(err, "unable to set up SMTP notifier")
+			os.Exit(1)
+		}
+		notifiers = append(notifiers, smtpNotifier)
+		setupLog.Info("SMTP notifications enabled", "server", o.smtpConfig.Addr, "digest", o.smtpConfig.Digest)
+	}
+	if o.enablePagerDuty {
+		routingKey := os.Getenv("PAGERDUTY_ROUTING_KEY")
+		if routingKey == "" {
+			setupLog.Error(errors.New("PAGERDUTY_ROUTING_KEY is not set"), "invalid PagerDuty configuration")
+			os.Exit(1)
+		}
+		notifiers = append(notifiers, notify.NewPagerDutyNotifier(routingKey, o.pagerDutyFailureThreshold))
+		setupLog.Info("PagerDuty notifications enabled", "failureThreshold", o.pagerDutyFailureThreshold)
+	}
+	if o.grafanaConfig.URL != "" {
+		o.grafanaConfig.APIToken = os.Getenv("GRAFANA_API_TOKEN")
+		if o.grafanaConfig.APIToken == "" {
+			setupLog.Error(errors.New("GRAFANA_API_TOKEN is not set"), "invalid Grafana configuration")
+			os.Exit(1)
+		}
+		if o.grafanaTags != "" {
+			o.grafanaConfig.Tags = strings.Split(o.grafanaTags, ",")
+		}
+		notifiers = append(notifiers, notify.NewGrafanaNotifier(o.grafanaConfig))
+		setupLog.Info("Grafana annotations enabled", "url", o.grafanaConfig.URL, "dashboardUID", o.grafanaConfig.DashboardUID)
+	}
+	var notifier notify.Notifier
+	if len(notifiers) > 0 {
+		notifier = notifiers
+	}
+
+	channels := notify.NewChannelResolver(mgr.GetClient(), mgr.GetAPIReader())
+	if err := mgr.Add(channels); err != nil {
+		setupLog.Error(err, "unable to set up notification channels")
+		os.Exit(1)
+	}
+	if err := channels.WatchChannels(ctx, mgr.GetCache()); err != nil {
+		setupLog.Error(err, "unable to watch notification channels")
+		os.Exit(1)
+	}
+
+	var pricer pricing.Provider
+	if o.pricingConfigMap != "" {
+		namespace, name, ok := strings.Cut(o.pricingConfigMap, "/")
+		if !ok || namespace == "" || name == "" {
+			setupLog.Error(fmt.Errorf("%q is not namespace/name", o.pricingConfigMap), "invalid --pricing-configmap")
+			os.Exit(1)
+		}
+		pricer = pricing.NewConfigMapProvider(mgr.GetAPIReader(), types.NamespacedName{Namespace: namespace, Name: name})
+		setupLog.Info("savings estimates enabled", "configMap", o.pricingConfigMap, "region", o.pricingRegion)
+	}
+
+	var autoscaler *controller.ClusterAutoscalerGate
+	if o.clusterAutoscalerAware {
+		namespace, name, ok := strings.Cut(o.clusterAutoscalerStatus, "/")
+		if !ok || namespace == "" || name == "" {
+			setupLog.Error(fmt.Errorf("%q is not namespace/name", o.clusterAutoscalerStatus), "invalid --cluster-autoscaler-status")
+			os.Exit(1)
+		}
+		autoscaler = &controller.ClusterAutoscalerGate{
+			Reader: mgr.GetAPIReader(),
+			Status: types.NamespacedName{Namespace: namespace, Name: name},
+		}
+		setupLog.Info("Deferring scale-ups while the cluster waits for capacity", "clusterAutoscalerStatus", autoscaler.Status)
+	}
+
+	var compactor *controller.Compactor
+	if o.compactAfterResizeDown {
+		compactor = &o.compactor
+		setupLog.Info("Compacting pods after resize down", "utilizationThreshold", compactor.UtilizationThreshold,
+			"maxEvictions", compactor.MaxEvictions)
+	}
+
+	var alerts controller.AlertSource
+	if o.alertmanagerURL != "" {
+		alerts = controller.NewAlertmanagerClient(o.alertmanagerURL)
+		setupLog.Info("Holding actions while alerts are firing", "alertmanagerURL", o.alertmanagerURL)
+	}
+
+	var approver controller.ActionApprover
+	if o.opaURL != "" {
+		approver = controller.NewOPAClient(o.opaURL)
+		setupLog.Info("Asking OPA to approve actions", "opaURL", o.opaURL)
+	}
+	if o.disableDownwardActions {
+		setupLog.Info("Downward actions are disabled, no profile scales or resizes down")
+	}
+
+	protectedWorkloads, err := controller.ParseWorkloadSelectors(o.protectedWorkloads)
+	if err != nil {
+		setupLog.Error(err, "invalid --protected-workloads")
+		os.Exit(1)
+	}
+	if len(protectedWorkloads) > 0 {
+		setupLog.Info("Never changing protected workloads", "selectors", o.protectedWorkloads)
+	}
+
+	var businessHours *optimizerv1.BusinessHours
+	if o.businessHours != "" {
+		start, end, ok := strings.Cut(o.businessHours, "-")
+		businessHours = &optimizerv1.BusinessHours{Days: o.businessDays, Start: start, End: end, TimeZone: o.businessHoursTimeZone}
+		err := controller.CheckBusinessHours(businessHours)
+		if !ok || start == "" || end == "" {
+			err = errors.New("want HH:MM-HH:MM")
+		}
+		if err != nil {
+			setupLog.Error(err, "invalid --business-hours", "businessHours", o.businessHours)
+			os.Exit(1)
+		}
+		setupLog.Info("Deferring downward actions during business hours", "hours", o.businessHours,
+			"days", o.businessDays, "timeZone", o.businessHoursTimeZone)
+	}
+
+	switch o.partialResponsePolicy {
+	case controller.PartialResponseFail, controller.PartialResponseWarn, controller.PartialResponseSkip:
+	default:
+		setupLog.Error(fmt.Errorf("%q is not Fail, Warn or Skip", o.partialResponsePolicy), "invalid --partial-response-policy")
+		os.Exit(1)
+	}
+
+	recordingRuleLabels, err := labels.ConvertSelectorToLabelsMap(o.monitoringLabels)
+	if err != nil {
+		setupLog.Error(err, "invalid --monitoring-labels")
+		os.Exit(1)
+	}
 
 	if err = (&controller.ResourceOptimizerProfileReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:                 mgr.GetClient(),
+		Scheme:                 mgr.GetScheme(),
+		MaxMetricProfiles:      o.maxMetricProfiles,
+		MaxMetricTargets:       o.maxMetricTargets,
+		Audit:                  auditRecorder,
+		Notifier:               notifier,
+		Channels:               channels,
+		CPUHistory:             cpuHistory,
+		PrometheusHealth:       prometheusHealth,
+		PrometheusTLS:          o.prometheusTLS,
+		Namespaces:             o.namespaces,
+		Pricing:                pricer,
+		PricingRegion:          o.pricingRegion,
+		Autoscaler:             autoscaler,
+		CheckCapacity:          o.checkCapacity,
+		Compactor:              compactor,
+		Alerts:                 alerts,
+		Approver:               approver,
+		DisableDownwardActions: o.disableDownwardActions,
+		ProtectedWorkloads:     protectedWorkloads,
+		TargetRetryBudget:      o.targetRetryBudget,
+		ActionFailureBudget:    o.actionFailureBudget,
+		QueryTimeout:           o.queryTimeout,
+		PartialResponsePolicy:  o.partialResponsePolicy,
+		MetricsCacheTTL:        o.metricsCacheTTL,
+		RequeueJitter:          o.requeueJitter,
+		BusinessHours:          businessHours,
+		RecordingRuleLabels:    recordingRuleLabels,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ResourceOptimizerProfile")
 		os.Exit(1)
 	}
+	if o.enableWebhooks {
+		protected := o.protectedNamespaces
+		if ns := os.Getenv("POD_NAMESPACE"); ns != "" {
+			protected = append(slices.Clip(protected), ns)
+		}
+		if err := webhookv1.SetupResourceOptimizerProfileWebhookWithManager(mgr, o.namespaces, protected, o.denyOverlappingProfiles); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ResourceOptimizerProfile")
+			os.Exit(1)
+		}
+		if err := webhookv1.SetupPodWebhookWithManager(mgr, o.namespaces, protected); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Pod")
+			os.Exit(1)
+		}
+	}
 
 	// +kubebuilder:scaffold:builder
 
+	if o.grpcAddr != "0" {
+		grpcServer := &grpcapi.Server{
+			Addr:    o.grpcAddr,
+			Service: &grpcapi.Service{Client: mgr.GetClient()},
+		}
+		switch {
+		case o.grpcCertPath != "":
+			certWatcher, err := certwatcher.New(
+				filepath.Join(o.grpcCertPath, o.grpcCertName),
+				filepath.Join(o.grpcCertPath, o.grpcCertKey),
+			)
+			if err != nil {
+				setupLog.Error(err, "unable to initialize gRPC certificate watcher")
+				os.Exit(1)
+			}
+			if err := mgr.Add(certWatcher); err != nil {
+				setupLog.Error(err, "unable to add gRPC certificate watcher to manager")
+				os.Exit(1)
+			}
+			grpcServer.TLSConfig = &tls.Config{
+				MinVersion:     tls.VersionTLS12,
+				GetCertificate: certWatcher.GetCertificate,
+			}
+		case !o.grpcInsecure:
+			setupLog.Error(errors.New("--grpc-cert-path is required unless --grpc-insecure is set"), "invalid gRPC configuration")
+			os.Exit(1)
+		}
+		if dashboardAuth != nil {
+			grpcServer.Auth = dashboardAuth
+		}
+		switch {
+		case o.grpcClientCAFile != "":
+			if grpcServer.TLSConfig == nil {
+				setupLog.Error(errors.New("--grpc-client-ca-file requires --grpc-cert-path"), "invalid gRPC configuration")
+				os.Exit(1)
+			}
+			pem, err := os.ReadFile(o.grpcClientCAFile)
+			if err != nil {
+				setupLog.Error(err, "unable to read gRPC client CA")
+				os.Exit(1)
+			}
+			grpcServer.ClientCAs = x509.NewCertPool()
+			if !grpcServer.ClientCAs.AppendCertsFromPEM(pem) {
+				setupLog.Error(errors.New("no PEM certificates found"), "invalid --grpc-client-ca-file", "path", o.grpcClientCAFile)
+				os.Exit(1)
+			}
+		case grpcServer.Auth == nil && !o.grpcAllowUnauthenticated:
+			setupLog.Error(errors.New("--grpc-client-ca-file or --dashboard-auth is required unless --grpc-allow-unauthenticated is set"),
+				"invalid gRPC configuration")
+			os.Exit(1)
+		}
+		if err := mgr.Add(grpcServer); err != nil {
+			setupLog.Error(err, "unable to set up gRPC API")
+			os.Exit(1)
+		}
+	}
+
+	if o.createPrometheusRule || o.createServiceMonitor {
+		monitoringOpts, err := monitoringOptions(o.monitoringLabels)
+		if err != nil {
+			setupLog.Error(err, "invalid monitoring configuration")
+			os.Exit(1)
+		}
+		if o.createPrometheusRule {
+			if err := mgr.Add(monitoring.NewPrometheusRuleInstaller(mgr.GetClient(), mgr.GetAPIReader(), monitoringOpts)); err != nil {
+				setupLog.Error(err, "unable to set up PrometheusRule installer")
+				os.Exit(1)
+			}
+		}
+		if o.createServiceMonitor {
+			endpoint := monitoring.MetricsEndpoint{
+				ServiceName: o.metricsServiceName,
+				PortName:    metricsServicePortName,
+				Secure:      o.secureMetrics,
+			}
+			if err := mgr.Add(monitoring.NewServiceMonitorInstaller(mgr.GetClient(), mgr.GetAPIReader(), monitoringOpts, endpoint)); err != nil {
+				setupLog.Error(err, "unable to set up ServiceMonitor installer")
+				os.Exit(1)
+			}
+		}
+	}
+
+	if o.exportRecommendations {
+		exporter := &export.ConfigMapExporter{Client: mgr.GetClient(), Reader: mgr.GetAPIReader(), Interval: export.DefaultInterval}
+		if err := mgr.Add(exporter); err != nil {
+			setupLog.Error(err, "unable to set up recommendation exporter")
+			os.Exit(1)
+		}
+	}
+
+	if o.weeklyReports {
+		generator := &report.Generator{Client: mgr.GetClient(), Collector: reportCollector, Notifier: channels.ReportNotifier}
+		if err := mgr.Add(generator); err != nil {
+			setupLog.Error(err, "unable to set up weekly reports")
+			os.Exit(1)
+		}
+	}
+
+	if archiver != nil {
+		if err := mgr.Add(archiver); err != nil {
+			setupLog.Error(err, "unable to set up archiving")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
 	}
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
+	// Ready means the controller can reach Prometheus, so rollouts with a wrong
+	// PROMETHEUS_URL stop at the first new replica.
+	if o.prometheusUnreachableThreshold > 0 {
+		promAPI, err := controller.NewPrometheusAPI(controller.PrometheusURLFromEnv(), o.prometheusTLS)
+		if err != nil {
+			setupLog.Error(err, "unable to create Prometheus client for the ready check")
+			os.Exit(1)
+		}
+		if err := mgr.AddReadyzCheck("prometheus", prometheusHealth.ReadyzCheck(promAPI, o.prometheusUnreachableThreshold)); err != nil {
+			setupLog.Error(err, "unable to set up Prometheus ready check")
+			os.Exit(1)
+		}
+	} else if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
 
-// StatusPageHandler serves a simple HTML page with the status of all ResourceOptimizerProfiles.
-type StatusPageHandler struct {
-	Client client.Client
-}
-
-const statusPageTemplate = `
-<!DOCTYPE html>
-<html>
-<head>
-    <title>K20s Controller Status</title>
-    <style>
-        body { font-family: sans-serif; margin: 2em; }
-        table { border-collapse: collapse; width: 100%; }
-        th, td { border: 1px solid #ddd; padding: 8px; text-align: left; }
-        th { background-color: #f2f2f2; }
-		h1 { color: #333; }
-    </style>
-</head>
-<body>
-    <h1>K20s Controller Status</h1>
-    <h2>Resource Optimizer Profiles</h2>
-    <table>
-        <tr>
-            <th>Namespace</th>
-            <th>Name</th>
-            <th>Policy</th>
-            <th>Last Action</th>
-            <th>Observed CPU</th>
-            <th>Recommendation</th>
-        </tr>
-        {{range .Items}}
-        <tr>
-            <td>{{.Namespace}}</td>
-            <td>{{.Name}}</td>
-            <td>{{.Spec.OptimizationPolicy}}</td>
-            <td>{{if .Status.LastAction}}{{.Status.LastAction.Type}} @ {{.Status.LastAction.Timestamp.Format "2006-01-02 15:04:05"}}{{else}}None{{end}}</td>
-            <td>{{if .Status.ObservedMetrics}}{{.Status.ObservedMetrics.cpu_usage}}%{{else}}N/A{{end}}</td>
-            <td>{{if .Status.Recommendations}}{{range .Status.Recommendations}}{{.}}{{end}}{{else}}None{{end}}</td>
-        </tr>
-        {{end}}
-    </table>
-</body>
-</html>
-`
-
-func (h *StatusPageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	logger := ctrl.Log.WithName("status-page-handler")
-
-	var profiles optimizerv1.ResourceOptimizerProfileList
-	if err := h.Client.List(ctx, &profiles); err != nil {
-		logger.Error(err, "failed to list ResourceOptimizerProfiles")
-		http.Error(w, "Failed to list resources", http.StatusInternalServerError)
-		return
-	}
-
-	tmpl, err := template.New("status").Parse(statusPageTemplate)
-	if err != nil {
-		logger.Error(err, "failed to parse HTML template")
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		return
+// monitoringOptions builds the options for Prometheus Operator objects created by
+// the controller. The controller's namespace and pod name are read from the
+// POD_NAMESPACE and POD_NAME environment variables set through the downward API.
+func monitoringOptions(extraLabels string) (monitoring.Options, error) {
+	opts := monitoring.Options{
+		Namespace: os.Getenv("POD_NAMESPACE"),
+		PodName:   os.Getenv("POD_NAME"),
 	}
-
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, profiles); err != nil {
-		logger.Error(err, "failed to execute HTML template")
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		return
+	if opts.Namespace == "" {
+		return opts, errors.New("POD_NAMESPACE must be set to create monitoring objects")
 	}
-
-	w.Header().Set("Content-Type", "text/html")
-	w.Write(buf.Bytes())
+	if extraLabels != "" {
+		parsed, err := labels.ConvertSelectorToLabelsMap(extraLabels)
+		if err != nil {
+			return opts, fmt.Errorf("invalid --monitoring-labels: %w", err)
+		}
+		opts.Labels = parsed
+	}
+	return opts, nil
 }
@@ -0,0 +1,298 @@
+package archive
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/audit"
+	"github.com/OpScaleHub/K20s/internal/controller"
+)
+
+// +kubebuilder:rbac:groups=optimizer.k20s.opscale.ir,resources=optimizationreports,verbs=get;list;watch
+// +kubebuilder:rbac:groups=optimizer.k20s.opscale.ir,resources=optimizationreports/status,verbs=get;update;patch
+
+const (
+	// DefaultInterval is how often exports are uploaded.
+	DefaultInterval = time.Hour
+	// DefaultMaxPendingActions is the number of actions held while uploads
+	// fail. The oldest are dropped beyond it.
+	DefaultMaxPendingActions = 10000
+)
+
+var actionColumns = []string{"time", "namespace", "profile", "profileGeneration", "action", "targetKind", "targetName",
+	"field", "before", "after", "metricValue", "result", "error"}
+
+var recommendationColumns = []string{"time", "namespace", "profile", "policy", "cpuUtilization", "targetKind", "targetName",
+	"replicas", "cpuRequest", "recommendedCPURequest"}
+
+// ActionLog is an audit.Recorder holding the actions applied since the last
+// upload.
+type ActionLog struct {
+	// MaxPending is the number of actions held. Defaults to
+	// DefaultMaxPendingActions.
+	MaxPending int
+
+	mu      sync.Mutex
+	pending []audit.Record
+	dropped int
+}
+
+var _ audit.Recorder = &ActionLog{}
+
+// Record implements audit.Recorder.
+func (l *ActionLog) Record(_ context.Context, record audit.Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pending = append(l.pending, record)
+	if excess := len(l.pending) - cmp.Or(l.MaxPending, DefaultMaxPendingActions); excess > 0 {
+		l.pending = l.pending[excess:]
+		l.dropped += excess
+	}
+	return nil
+}
+
+// take returns and clears the pending actions, and how many were dropped.
+func (l *ActionLog) take() ([]audit.Record, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	pending, dropped := l.pending, l.dropped
+	l.pending, l.dropped = nil, 0
+	return pending, dropped
+}
+
+// restore puts back actions that could not be uploaded, ahead of the ones
+// recorded since.
+func (l *ActionLog) restore(records []audit.Record) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pending = append(records, l.pending...)
+	if excess := len(l.pending) - cmp.Or(l.MaxPending, DefaultMaxPendingActions); excess > 0 {
+		l.pending = l.pending[excess:]
+		l.dropped += excess
+	}
+}
+
+// Archiver uploads, on every interval, the actions applied since the last
+// upload and the current recommendations as CSV, and the OptimizationReports
+// not uploaded yet as JSON, Markdown and HTML.
+//
+// Objects are keyed under the location's prefix:
+//
+//	actions/<date>/<time>.csv
+//	recommendations/<date>/<time>.csv
+//	reports/<namespace>/<name>.json, .md and .html
+type Archiver struct {
+	// Client reads profiles, their targets and reports, and marks reports
+	// archived.
+	Client client.Client
+	// SecretReader reads the credentials. An uncached reader avoids watching
+	// every Secret in the cluster.
+	SecretReader client.Reader
+	// Location is where objects are uploaded.
+	Location Location
+	// CredentialsSecret holds the credentials, read before every upload so
+	// they can be rotated.
+	CredentialsSecret types.NamespacedName
+	// Actions holds the actions to upload.
+	Actions *ActionLog
+	// Interval is how often exports are uploaded.
+	Interval time.Duration
+	// HTTPClient sends the uploads. Defaults to a client timing out after
+	// DefaultUploadTimeout.
+	HTTPClient *http.Client
+}
+
+var _ manager.LeaderElectionRunnable = &Archiver{}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Only the
+// leader applies actions, so only its log holds them.
+func (a *Archiver) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable. Every upload is bounded by the interval,
+// so a hung endpoint cannot hold back the next one. Pending actions are
+// uploaded once more on shutdown.
+func (a *Archiver) Start(ctx context.Context) error {
+	interval := cmp.Or(a.Interval, DefaultInterval)
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		ctx, cancel := context.WithTimeout(ctx, interval)
+		defer cancel()
+		if err := a.Archive(ctx, time.Now()); err != nil {
+			log.FromContext(ctx).Error(err, "unable to archive exports")
+		}
+	}, interval)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	store, err := a.store(shutdownCtx)
+	if err == nil {
+		err = a.archiveActions(shutdownCtx, store, time.Now())
+	}
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to archive pending actions on shutdown")
+	}
+	return nil
+}
+
+// Archive uploads everything once.
+func (a *Archiver) Archive(ctx context.Context, now time.Time) error {
+	store, err := a.store(ctx)
+	if err != nil {
+		return err
+	}
+	return errors.Join(
+		a.archiveActions(ctx, store, now),
+		a.archiveRecommendations(ctx, store, now),
+		a.archiveReports(ctx, store, now),
+	)
+}
+
+func (a *Archiver) store(ctx context.Context) (Store, error) {
+	var secret corev1.Secret
+	if err := a.SecretReader.Get(ctx, a.CredentialsSecret, &secret); err != nil {
+		return nil, fmt.Errorf("reading archive credentials: %w", err)
+	}
+	store, err := NewStore(a.Location, secret.Data, a.HTTPClient)
+	if err != nil {
+		return nil, fmt.Errorf("archive credentials %s: %w", a.CredentialsSecret, err)
+	}
+	return store, nil
+}
+
+// archiveActions uploads the pending actions, keeping them for the next
+// attempt if the upload fails.
+func (a *Archiver) archiveActions(ctx context.Context, store Store, now time.Time) error {
+	if a.Actions == nil {
+		return nil
+	}
+	records, dropped := a.Actions.take()
+	if dropped > 0 {
+		log.FromContext(ctx).Info("Dropped actions that could not be archived in time", "dropped", dropped)
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	rows := make([][]string, 0, len(records))
+	for _, r := range records {
+		rows = append(rows, []string{r.Time.UTC().Format(time.RFC3339), r.ProfileNamespace, r.ProfileName,
+			strconv.FormatInt(r.ProfileGeneration, 10), r.Action, r.TargetKind, r.TargetName, r.Field, r.Before, r.After,
+			strconv.FormatFloat(r.MetricValue, 'f', 2, 64), r.Result, r.Error})
+	}
+	if err := store.Put(ctx, timestampedKey("actions", now, ".csv"), encodeCSV(actionColumns, rows), "text/csv"); err != nil {
+		a.Actions.restore(records)
+		return fmt.Errorf("archiving actions: %w", err)
+	}
+	return nil
+}
+
+// archiveRecommendations uploads the CPU requests currently recommended for
+// the targets of every evaluated profile.
+func (a *Archiver) archiveRecommendations(ctx context.Context, store Store, now time.Time) error {
+	var profiles optimizerv1.ResourceOptimizerProfileList
+	if err := a.Client.List(ctx, &profiles); err != nil {
+		return fmt.Errorf("listing profiles: %w", err)
+	}
+	var rows [][]string
+	for i := range profiles.Items {
+		profile := &profiles.Items[i]
+		value, err := strconv.ParseFloat(profile.Status.ObservedMetrics["cpu_usage"], 64)
+		if err != nil {
+			// Not evaluated yet.
+			continue
+		}
+		sim, err := controller.Simulate(ctx, a.Client, profile, value)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "unable to recommend target requests", "profile", client.ObjectKeyFromObject(profile))
+			continue
+		}
+		for _, target := range sim.Targets {
+			rows = append(rows, []string{now.UTC().Format(time.RFC3339), profile.Namespace, profile.Name,
+				profile.Spec.OptimizationPolicy, strconv.FormatFloat(value, 'f', 2, 64), target.Kind, target.Name,
+				strconv.Itoa(int(target.Replicas)), target.CurrentCPURequest.String(), target.CPURequest.String()})
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	if err := store.Put(ctx, timestampedKey("recommendations", now, ".csv"), encodeCSV(recommendationColumns, rows), "text/csv"); err != nil {
+		return fmt.Errorf("archiving recommendations: %w", err)
+	}
+	return nil
+}
+
+// archiveReports uploads the generated reports not uploaded yet and marks
+// them archived.
+func (a *Archiver) archiveReports(ctx context.Context, store Store, now time.Time) error {
+	var reports optimizerv1.OptimizationReportList
+	if err := a.Client.List(ctx, &reports); err != nil {
+		return fmt.Errorf("listing reports: %w", err)
+	}
+	var errs []error
+	for i := range reports.Items {
+		report := &reports.Items[i]
+		if report.Status.ArchivedAt != nil || report.Status.GeneratedAt.IsZero() {
+			continue
+		}
+		if err := a.archiveReport(ctx, store, report); err != nil {
+			errs = append(errs, fmt.Errorf("archiving report %s: %w", client.ObjectKeyFromObject(report), err))
+			continue
+		}
+		report.Status.ArchivedAt = &metav1.Time{Time: now}
+		if err := a.Client.Status().Update(ctx, report); err != nil {
+			errs = append(errs, fmt.Errorf("marking report %s archived: %w", client.ObjectKeyFromObject(report), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (a *Archiver) archiveReport(ctx context.Context, store Store, report *optimizerv1.OptimizationReport) error {
+	exported := report.DeepCopy()
+	exported.APIVersion, exported.Kind = optimizerv1.GroupVersion.String(), "OptimizationReport"
+	exported.ManagedFields = nil
+	data, err := json.MarshalIndent(exported, "", "  ")
+	if err != nil {
+		return err
+	}
+	key := "reports/" + report.Namespace + "/" + report.Name
+	if err := store.Put(ctx, key+".json", append(data, '\n'), "application/json"); err != nil {
+		return err
+	}
+	if err := store.Put(ctx, key+".md", []byte(report.Status.Markdown), "text/markdown; charset=utf-8"); err != nil {
+		return err
+	}
+	return store.Put(ctx, key+".html", []byte(report.Status.HTML), "text/html; charset=utf-8")
+}
+
+// timestampedKey returns a key grouping uploads by day.
+func timestampedKey(kind string, now time.Time, ext string) string {
+	now = now.UTC()
+	return kind + "/" + now.Format("2006-01-02") + "/" + now.Format("20060102T150405Z") + ext
+}
+
+func encodeCSV(header []string, rows [][]string) []byte {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	// Writing to a buffer cannot fail.
+	_ = w.Write(header)
+	_ = w.WriteAll(rows)
+	return buf.Bytes()
+}
@@ -0,0 +1,143 @@
+package archive
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/audit"
+)
+
+var _ = Describe("Archiver", func() {
+	labels := map[string]string{"app": "web"}
+	now := time.Date(2025, time.January, 13, 1, 0, 0, 0, time.UTC)
+
+	var (
+		uploads  map[string]string
+		failing  bool
+		server   *httptest.Server
+		c        client.Client
+		archiver *Archiver
+	)
+
+	BeforeEach(func() {
+		uploads, failing = map[string]string{}, false
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if failing {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			data, _ := io.ReadAll(r.Body)
+			uploads[strings.TrimPrefix(r.URL.Path, "/backups/k20s/")] = string(data)
+		}))
+
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(optimizerv1.AddToScheme(scheme)).To(Succeed())
+		c = fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&optimizerv1.OptimizationReport{}).WithObjects(
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "archive", Namespace: "k20s-system"},
+				Data:       map[string][]byte{AccessKeyIDKey: []byte("id"), SecretAccessKeyKey: []byte("secret")},
+			},
+			&optimizerv1.ResourceOptimizerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+				Spec: optimizerv1.ResourceOptimizerProfileSpec{
+					Selector:           metav1.LabelSelector{MatchLabels: labels},
+					CPUThresholds:      optimizerv1.ThresholdSpec{Min: 20, Max: 80},
+					OptimizationPolicy: "Recommend",
+				},
+				Status: optimizerv1.ResourceOptimizerProfileStatus{ObservedMetrics: map[string]string{"cpu_usage": "10.00"}},
+			},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", Labels: labels},
+				Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Name:      "app",
+					Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}},
+				}}}}},
+			},
+			&optimizerv1.OptimizationReport{
+				ObjectMeta: metav1.ObjectMeta{Name: "weekly-2025-w02", Namespace: "team-a"},
+				Status: optimizerv1.OptimizationReportStatus{
+					GeneratedAt: metav1.NewTime(now),
+					Markdown:    "# Weekly optimization report for team-a\n",
+					HTML:        "<h1>Weekly optimization report for team-a</h1>\n",
+				},
+			},
+		).Build()
+		archiver = &Archiver{
+			Client:            c,
+			SecretReader:      c,
+			Location:          Location{Provider: ProviderS3, Bucket: "backups", Prefix: "k20s/", Region: "us-east-1", Endpoint: server.URL},
+			CredentialsSecret: types.NamespacedName{Namespace: "k20s-system", Name: "archive"},
+			Actions:           &ActionLog{},
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should upload actions, recommendations and new reports", func() {
+		Expect(archiver.Actions.Record(context.Background(), audit.Record{Time: now, ProfileNamespace: "team-a", ProfileName: "web",
+			Action: "ScaleDown", TargetKind: "Deployment", TargetName: "web", Field: "spec.replicas", Before: "3", After: "2",
+			Result: audit.ResultSucceeded})).To(Succeed())
+
+		Expect(archiver.Archive(context.Background(), now)).To(Succeed())
+		Expect(uploads).To(HaveKeyWithValue("actions/2025-01-13/20250113T010000Z.csv",
+			"time,namespace,profile,profileGeneration,action,targetKind,targetName,field,before,after,metricValue,result,error\n"+
+				"2025-01-13T01:00:00Z,team-a,web,0,ScaleDown,Deployment,web,spec.replicas,3,2,0.00,Succeeded,\n"))
+		Expect(uploads).To(HaveKeyWithValue("recommendations/2025-01-13/20250113T010000Z.csv",
+			ContainSubstring("\n2025-01-13T01:00:00Z,team-a,web,Recommend,10.00,Deployment,web,1,1,")))
+		Expect(uploads).To(HaveKeyWithValue("reports/team-a/weekly-2025-w02.json", ContainSubstring(`"kind": "OptimizationReport"`)))
+		Expect(uploads).To(HaveKeyWithValue("reports/team-a/weekly-2025-w02.md", "# Weekly optimization report for team-a\n"))
+		Expect(uploads).To(HaveKey("reports/team-a/weekly-2025-w02.html"))
+
+		var report optimizerv1.OptimizationReport
+		Expect(c.Get(context.Background(), client.ObjectKey{Namespace: "team-a", Name: "weekly-2025-w02"}, &report)).To(Succeed())
+		Expect(report.Status.ArchivedAt).NotTo(BeNil())
+
+		By("not uploading the report again")
+		uploads = map[string]string{}
+		Expect(archiver.Archive(context.Background(), now.Add(time.Hour))).To(Succeed())
+		Expect(uploads).To(ConsistOf(ContainSubstring("team-a,web,Recommend")))
+	})
+
+	It("should keep actions until they could be uploaded", func() {
+		record := audit.Record{Time: now, ProfileNamespace: "team-a", ProfileName: "web", Action: "ScaleDown", Result: audit.ResultSucceeded}
+		Expect(archiver.Actions.Record(context.Background(), record)).To(Succeed())
+		failing = true
+		Expect(archiver.Archive(context.Background(), now)).To(MatchError(ContainSubstring("archiving actions")))
+
+		failing = false
+		Expect(archiver.Actions.Record(context.Background(), record)).To(Succeed())
+		Expect(archiver.Archive(context.Background(), now.Add(time.Hour))).To(Succeed())
+		Expect(strings.Count(uploads["actions/2025-01-13/20250113T020000Z.csv"], "ScaleDown")).To(Equal(2))
+	})
+
+	It("should drop the oldest actions beyond the limit", func() {
+		log := &ActionLog{MaxPending: 2}
+		for _, action := range []string{"ScaleUp", "ScaleDown", "ResizeUp"} {
+			Expect(log.Record(context.Background(), audit.Record{Action: action})).To(Succeed())
+		}
+		records, dropped := log.take()
+		Expect(dropped).To(Equal(1))
+		Expect(records).To(HaveLen(2))
+		Expect(records[0].Action).To(Equal("ScaleDown"))
+	})
+})
@@ -0,0 +1,167 @@
+// Package archive uploads reports and CSV exports of the controller's
+// activity to object storage, so the history of optimizations outlives the
+// controller and the cluster.
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Providers of object storage.
+const (
+	ProviderS3    = "s3"
+	ProviderGCS   = "gs"
+	ProviderAzure = "azblob"
+)
+
+// Keys of the credentials Secret.
+const (
+	// AccessKeyIDKey and SecretAccessKeyKey hold an S3 access key, or a GCS
+	// HMAC key.
+	AccessKeyIDKey     = "accessKeyID"
+	SecretAccessKeyKey = "secretAccessKey"
+	// SessionTokenKey optionally holds the token of temporary S3 credentials.
+	SessionTokenKey = "sessionToken"
+	// AccountKeyKey holds the shared key of an Azure storage account.
+	AccountKeyKey = "accountKey"
+)
+
+// DefaultUploadTimeout bounds every upload of a Store created without an HTTP
+// client, so that an unresponsive endpoint cannot stall the archiver.
+const DefaultUploadTimeout = time.Minute
+
+// Location is where objects are archived.
+type Location struct {
+	Provider string
+	// Bucket is the S3 or GCS bucket, or the Azure container.
+	Bucket string
+	// Account is the Azure storage account.
+	Account string
+	// Prefix is prepended to the key of every object.
+	Prefix string
+	// Region is the S3 region.
+	Region string
+	// Endpoint overrides the provider's endpoint, e.g. for MinIO or Azurite.
+	// Buckets are then addressed in the path.
+	Endpoint string
+}
+
+// ParseURL parses a location given as s3://bucket/prefix?region=eu-west-1,
+// gs://bucket/prefix or azblob://account/container/prefix. S3 and Azure
+// locations take an endpoint parameter to use compatible services.
+func ParseURL(raw string) (Location, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return Location{}, err
+	}
+	loc := Location{Provider: u.Scheme, Bucket: u.Host, Endpoint: u.Query().Get("endpoint")}
+	path := strings.Trim(u.Path, "/")
+	switch u.Scheme {
+	case ProviderS3:
+		loc.Region = u.Query().Get("region")
+		if loc.Region == "" {
+			loc.Region = "us-east-1"
+		}
+	case ProviderGCS:
+		if loc.Endpoint != "" {
+			return Location{}, errors.New("gs locations do not take an endpoint")
+		}
+	case ProviderAzure:
+		loc.Account = u.Host
+		loc.Bucket, path, _ = strings.Cut(path, "/")
+	default:
+		return Location{}, fmt.Errorf("unsupported scheme %q, use s3, gs or azblob", u.Scheme)
+	}
+	if loc.Bucket == "" {
+		return Location{}, errors.New("no bucket or container")
+	}
+	if loc.Endpoint != "" {
+		if _, err := url.ParseRequestURI(loc.Endpoint); err != nil {
+			return Location{}, fmt.Errorf("invalid endpoint: %w", err)
+		}
+	}
+	if path != "" {
+		loc.Prefix = path + "/"
+	}
+	return loc, nil
+}
+
+// Store writes objects.
+type Store interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+}
+
+// NewStore returns a Store writing to loc with the credentials held in the
+// data of a Secret. Without a client, uploads time out after
+// DefaultUploadTimeout.
+func NewStore(loc Location, credentials map[string][]byte, client *http.Client) (Store, error) {
+	if client == nil {
+		client = &http.Client{Timeout: DefaultUploadTimeout}
+	}
+	value := func(key string) (string, error) {
+		v := strings.TrimSpace(string(credentials[key]))
+		if v == "" {
+			return "", fmt.Errorf("credentials have no %s", key)
+		}
+		return v, nil
+	}
+	switch loc.Provider {
+	case ProviderS3, ProviderGCS:
+		accessKeyID, err := value(AccessKeyIDKey)
+		if err != nil {
+			return nil, err
+		}
+		secretAccessKey, err := value(SecretAccessKeyKey)
+		if err != nil {
+			return nil, err
+		}
+		return &s3Store{
+			location:        loc,
+			accessKeyID:     accessKeyID,
+			secretAccessKey: secretAccessKey,
+			sessionToken:    strings.TrimSpace(string(credentials[SessionTokenKey])),
+			client:          client,
+		}, nil
+	case ProviderAzure:
+		accountKey, err := value(AccountKeyKey)
+		if err != nil {
+			return nil, err
+		}
+		return newAzureStore(loc, accountKey, client)
+	}
+	return nil, fmt.Errorf("unsupported provider %q", loc.Provider)
+}
+
+// put sends a prepared upload and fails on any status but 2xx.
+func put(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("uploading %s: %s", req.URL.Redacted(), resp.Status)
+	}
+	return nil
+}
+
+// escapePath percent-encodes every byte of a key but unreserved characters
+// and slashes, as the S3 and Azure signatures expect.
+func escapePath(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-_.~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,147 @@
+// Package audit records every mutation the controller applies to workloads as
+// an append-only trail for compliance reviews.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Results of an audited mutation.
+const (
+	ResultSucceeded = "Succeeded"
+	ResultFailed    = "Failed"
+)
+
+// Record describes a single patch applied, or attempted, by the controller.
+type Record struct {
+	Time              time.Time `json:"time"`
+	ProfileNamespace  string    `json:"profileNamespace"`
+	ProfileName       string    `json:"profileName"`
+	ProfileGeneration int64     `json:"profileGeneration"`
+	Action            string    `json:"action"`
+	TargetKind        string    `json:"targetKind"`
+	TargetName        string    `json:"targetName"`
+	// Field is the path of the mutated field, e.g. spec.replicas.
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+	// MetricValue is the observed metric value that triggered the action.
+	MetricValue float64 `json:"metricValue"`
+	Result      string  `json:"result"`
+	Error       string  `json:"error,omitempty"`
+}
+
+// Recorder persists audit records. Implementations must be safe for concurrent
+// use and should not block reconciles for long.
+type Recorder interface {
+	Record(ctx context.Context, record Record) error
+}
+
+// Discard is a Recorder that drops every record.
+var Discard Recorder = discard{}
+
+type discard struct{}
+
+func (discard) Record(context.Context, Record) error { return nil }
+
+// JSONLinesRecorder writes each record as a single JSON document per line.
+type JSONLinesRecorder struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONLinesRecorder returns a recorder writing JSON lines to w.
+func NewJSONLinesRecorder(w io.Writer) *JSONLinesRecorder {
+	return &JSONLinesRecorder{enc: json.NewEncoder(w)}
+}
+
+// Record implements Recorder.
+func (r *JSONLinesRecorder) Record(_ context.Context, record Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.enc.Encode(record)
+}
+
+// OpenFile opens the audit trail at path for appending, creating it if needed.
+// A path of "-" writes to stdout.
+func OpenFile(path string) (io.WriteCloser, error) {
+	if path == "-" {
+		return nopCloser{os.Stdout}, nil
+	}
+	return os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// Multi records to several recorders. Every recorder is attempted and their
+// errors are joined.
+type Multi []Recorder
+
+// Record implements Recorder.
+func (m Multi) Record(ctx context.Context, record Record) error {
+	var errs []error
+	for _, r := range m {
+		if err := r.Record(ctx, record); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// DefaultHistorySize is the default number of records kept by a History.
+const DefaultHistorySize = 200
+
+// History keeps the most recent records in memory so they can be served by the
+// controller's HTTP API. It forgets everything on restart; use a
+// JSONLinesRecorder for a durable trail.
+type History struct {
+	mu      sync.Mutex
+	records []Record
+	next    int
+	full    bool
+}
+
+// NewHistory returns a History holding up to size records.
+func NewHistory(size int) *History {
+	if size < 1 {
+		size = 1
+	}
+	return &History{records: make([]Record, size)}
+}
+
+// Record implements Recorder.
+func (h *History) Record(_ context.Context, record Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records[h.next] = record
+	h.next = (h.next + 1) % len(h.records)
+	if h.next == 0 {
+		h.full = true
+	}
+	return nil
+}
+
+// Records returns the retained records, newest first.
+func (h *History) Records() []Record {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n := h.next
+	if h.full {
+		n = len(h.records)
+	}
+	out := make([]Record, 0, n)
+	for i := 1; i <= n; i++ {
+		out = append(out, h.records[(h.next-i+len(h.records))%len(h.records)])
+	}
+	return out
+}
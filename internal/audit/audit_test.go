@@ -0,0 +1,48 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("History", func() {
+	It("should return records newest first", func() {
+		history := NewHistory(5)
+		Expect(history.Records()).To(BeEmpty())
+		for _, action := range []string{"a", "b"} {
+			Expect(history.Record(context.Background(), Record{Action: action})).To(Succeed())
+		}
+		records := history.Records()
+		Expect(records).To(HaveLen(2))
+		Expect(records[0].Action).To(Equal("b"))
+		Expect(records[1].Action).To(Equal("a"))
+	})
+
+	It("should keep only the most recent records", func() {
+		history := NewHistory(2)
+		for _, action := range []string{"a", "b", "c"} {
+			Expect(history.Record(context.Background(), Record{Action: action})).To(Succeed())
+		}
+		records := history.Records()
+		Expect(records).To(HaveLen(2))
+		Expect(records[0].Action).To(Equal("c"))
+		Expect(records[1].Action).To(Equal("b"))
+	})
+})
+
+var _ = Describe("Multi", func() {
+	It("should record to every recorder", func() {
+		var buf bytes.Buffer
+		history := NewHistory(1)
+		Expect(Multi{history, NewJSONLinesRecorder(&buf)}.Record(context.Background(), Record{Action: "ScaleUp"})).To(Succeed())
+
+		Expect(history.Records()).To(HaveLen(1))
+		var written Record
+		Expect(json.Unmarshal(buf.Bytes(), &written)).To(Succeed())
+		Expect(written.Action).To(Equal("ScaleUp"))
+	})
+})
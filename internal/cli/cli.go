@@ -0,0 +1,62 @@
+// Package cli implements the one-shot commands of the controller binary. They
+// run against a cluster or local files, print a report and exit instead of
+// starting the controller manager.
+package cli
+
+import (
+	"github.com/go-logr/logr"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/controller"
+)
+
+// prepare runs once a command's flags are parsed. From then on errors are
+// not followed by the usage, and the controller's helpers, which log every
+// step of a reconcile, are silenced so they do not drown the report.
+func prepare(cmd *cobra.Command, _ []string) {
+	cmd.SilenceUsage = true
+	ctrl.SetLogger(logr.Discard())
+}
+
+// restConfig loads the kubeconfig at path, or finds one like the controller
+// does when path is empty.
+func restConfig(path string) (*rest.Config, error) {
+	if path != "" {
+		return clientcmd.BuildConfigFromFlags("", path)
+	}
+	return ctrl.GetConfig()
+}
+
+// connect returns clients for the cluster and the Prometheus the controller
+// would query.
+func connect(kubeconfig, prometheusURL string, prometheusTLS controller.PrometheusTLSConfig) (client.Client, controller.PrometheusClient, error) {
+	config, err := restConfig(kubeconfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	c, err := newClient(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	promAPI, err := controller.NewPrometheusAPI(prometheusURL, prometheusTLS)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, promAPI, nil
+}
+
+// newClient returns a client for the cluster that knows the optimizer types.
+func newClient(config *rest.Config) (client.Client, error) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(optimizerv1.AddToScheme(scheme))
+	return client.New(config, client.Options{Scheme: scheme})
+}
@@ -0,0 +1,588 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// defaultHPAUtilization is the CPU utilization a HorizontalPodAutoscaler
+// without metrics targets.
+const defaultHPAUtilization = 80
+
+// errImportIncomplete is returned once the profiles are printed if any
+// autoscaler could not be imported.
+var errImportIncomplete = errors.New("some autoscalers could not be imported")
+
+// Imported is the profile generated for an autoscaler.
+type Imported struct {
+	// Source is the kind, namespace and name of the autoscaler.
+	Source string
+	// Profile is nil when the autoscaler could not be imported.
+	Profile *optimizerv1.ResourceOptimizerProfile
+	// Warnings are the settings of the autoscaler the profile does not carry
+	// over.
+	Warnings []string
+	// Error is why the autoscaler could not be imported.
+	Error string
+}
+
+// importOptions are the flags shared by the import commands.
+type importOptions struct {
+	kubeconfig string
+	namespace  string
+	apply      bool
+}
+
+func (o *importOptions) bindFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.kubeconfig, "kubeconfig", "", "Path to a kubeconfig. Defaults to $KUBECONFIG, the in-cluster config or ~/.kube/config.")
+	cmd.Flags().StringVar(&o.namespace, "namespace", "", "Only import autoscalers in this namespace. Defaults to all namespaces.")
+	cmd.Flags().BoolVar(&o.apply, "apply", false, "Create the profiles in the cluster instead of only printing them.")
+}
+
+// finish creates the imported profiles with --apply, pausing the autoscaler
+// of every profile created when pause is set, and prints them.
+func (o *importOptions) finish(cmd *cobra.Command, c client.Client, imported []Imported, pause bool, pauseAutoscaler func(i int) error) error {
+	if o.apply {
+		for i := range imported {
+			if err := applyImported(cmd.Context(), c, &imported[i]); err != nil {
+				return err
+			}
+			if pause && imported[i].Error == "" {
+				if err := pauseAutoscaler(i); err != nil {
+					return fmt.Errorf("pausing %s: %w", imported[i].Source, err)
+				}
+			}
+		}
+	}
+	return writeImported(cmd.OutOrStdout(), imported)
+}
+
+// NewImportCommand returns the import command.
+func NewImportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Generate profiles from existing autoscalers",
+		Long: "Generates the profiles equivalent to existing autoscalers and prints them as YAML, ready for " +
+			"kubectl apply, or creates them with --apply.",
+		Args: cobra.NoArgs,
+	}
+	cmd.AddCommand(newImportHPACommand(), newImportVPACommand())
+	return cmd
+}
+
+func newImportHPACommand() *cobra.Command {
+	var o importOptions
+	var pause bool
+	cmd := &cobra.Command{
+		Use:   "hpa [name...]",
+		Short: "Generate Scale profiles from HorizontalPodAutoscalers",
+		Long: "Generates a Scale profile for each HorizontalPodAutoscaler, or the named ones, selecting the workload " +
+			"it scales by its labels. With --pause-hpas the HorizontalPodAutoscalers of the created profiles stop " +
+			"scaling, so the two do not fight over the replicas.",
+		PreRun: prepare,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if pause && !o.apply {
+				return errors.New("--pause-hpas requires --apply")
+			}
+			config, err := restConfig(o.kubeconfig)
+			if err != nil {
+				return err
+			}
+			c, err := newClient(config)
+			if err != nil {
+				return err
+			}
+
+			var list autoscalingv2.HorizontalPodAutoscalerList
+			if err := c.List(cmd.Context(), &list, client.InNamespace(o.namespace)); err != nil {
+				return fmt.Errorf("listing horizontalpodautoscalers: %w", err)
+			}
+			var hpas []autoscalingv2.HorizontalPodAutoscaler
+			for _, hpa := range list.Items {
+				if len(args) == 0 || slices.Contains(args, hpa.Name) {
+					hpas = append(hpas, hpa)
+				}
+			}
+
+			imported, err := ImportHPAs(cmd.Context(), c, hpas)
+			if err != nil {
+				return err
+			}
+			return o.finish(cmd, c, imported, pause, func(i int) error {
+				return pauseHPA(cmd.Context(), c, &hpas[i])
+			})
+		},
+	}
+	o.bindFlags(cmd)
+	cmd.Flags().BoolVar(&pause, "pause-hpas", false, "Disable the scaling of every HorizontalPodAutoscaler whose profile was created. "+
+		"Its behavior is kept in the "+optimizerv1.PausedHPABehaviorAnnotation+" annotation.")
+	return cmd
+}
+
+// ImportHPAs generates the profile of each HorizontalPodAutoscaler, in the
+// same order, from the workloads in the cluster they scale.
+func ImportHPAs(ctx context.Context, c client.Reader, hpas []autoscalingv2.HorizontalPodAutoscaler) ([]Imported, error) {
+	imported := make([]Imported, 0, len(hpas))
+	for i := range hpas {
+		hpa := &hpas[i]
+		result := Imported{Source: fmt.Sprintf("HorizontalPodAutoscaler %s/%s", hpa.Namespace, hpa.Name)}
+		ref := hpa.Spec.ScaleTargetRef
+		workload, err := importTarget(ctx, c, hpa.Namespace, ref.Kind, ref.Name)
+		if err != nil {
+			if !errors.Is(err, errNoImportTarget) {
+				return nil, err
+			}
+			result.Error = err.Error()
+			imported = append(imported, result)
+			continue
+		}
+		targetLabels := workload.GetLabels()
+		result.Profile, result.Warnings = ProfileFromHPA(hpa, targetLabels)
+		others, err := otherMatches(ctx, c, hpa.Namespace, targetLabels, ref.Kind+"/"+ref.Name)
+		if err != nil {
+			return nil, err
+		}
+		if len(others) > 0 {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("selector %s also matches %s",
+				labels.Set(targetLabels), strings.Join(others, ", ")))
+		}
+		imported = append(imported, result)
+	}
+	return imported, nil
+}
+
+// ProfileFromHPA returns the Scale profile equivalent to a
+// HorizontalPodAutoscaler of a workload with the given labels, and the
+// settings it does not carry over.
+//
+// The CPU thresholds are placed around the utilization the autoscaler
+// targets: the profile scales up above it, past the autoscaler's 10%
+// tolerance, and scales down below half of it, so that removing a replica does
+// not immediately call for adding it back.
+func ProfileFromHPA(hpa *autoscalingv2.HorizontalPodAutoscaler, targetLabels map[string]string) (*optimizerv1.ResourceOptimizerProfile, []string) {
+	var warnings []string
+	utilization := int32(defaultHPAUtilization)
+	for _, metric := range hpa.Spec.Metrics {
+		if metric.Type == autoscalingv2.ResourceMetricSourceType && metric.Resource != nil &&
+			metric.Resource.Name == corev1.ResourceCPU && metric.Resource.Target.Type == autoscalingv2.UtilizationMetricType &&
+			metric.Resource.Target.AverageUtilization != nil {
+			utilization = *metric.Resource.Target.AverageUtilization
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("metric %s is not imported; profiles scale on CPU utilization", hpaMetricName(metric)))
+	}
+	if utilization > 100 {
+		warnings = append(warnings, fmt.Sprintf("target CPU utilization %d%% is capped at 100%%", utilization))
+	}
+
+	profile := &optimizerv1.ResourceOptimizerProfile{
+		TypeMeta:   metav1.TypeMeta{APIVersion: optimizerv1.GroupVersion.String(), Kind: "ResourceOptimizerProfile"},
+		ObjectMeta: metav1.ObjectMeta{Name: hpa.Name, Namespace: hpa.Namespace},
+		Spec: optimizerv1.ResourceOptimizerProfileSpec{
+			Selector: metav1.LabelSelector{MatchLabels: targetLabels},
+			CPUThresholds: optimizerv1.ThresholdSpec{
+				Min: max(utilization/2, 1),
+				Max: min(int32(math.Ceil(float64(utilization)*1.1)), 100),
+			},
+			OptimizationPolicy: "Scale",
+			Behavior:           hpaBehavior(hpa),
+		},
+	}
+	if hpa.Spec.MinReplicas != nil && *hpa.Spec.MinReplicas > 1 {
+		profile.Spec.MinReplicas = hpa.Spec.MinReplicas
+	}
+	warnings = append(warnings, fmt.Sprintf("maxReplicas %d is not imported; profiles do not cap the replicas", hpa.Spec.MaxReplicas))
+	return profile, warnings
+}
+
+// hpaBehavior returns the behavior of a HorizontalPodAutoscaler, from before it
+// was paused if it was.
+func hpaBehavior(hpa *autoscalingv2.HorizontalPodAutoscaler) *autoscalingv2.HorizontalPodAutoscalerBehavior {
+	if paused, ok := hpa.Annotations[optimizerv1.PausedHPABehaviorAnnotation]; ok {
+		var behavior *autoscalingv2.HorizontalPodAutoscalerBehavior
+		if err := json.Unmarshal([]byte(paused), &behavior); err == nil {
+			return behavior
+		}
+	}
+	return hpa.Spec.Behavior.DeepCopy()
+}
+
+// hpaMetricName describes a metric of a HorizontalPodAutoscaler.
+func hpaMetricName(metric autoscalingv2.MetricSpec) string {
+	switch {
+	case metric.Resource != nil:
+		return fmt.Sprintf("%s %s", metric.Type, metric.Resource.Name)
+	case metric.ContainerResource != nil:
+		return fmt.Sprintf("%s %s of container %s", metric.Type, metric.ContainerResource.Name, metric.ContainerResource.Container)
+	case metric.Pods != nil:
+		return fmt.Sprintf("%s %s", metric.Type, metric.Pods.Metric.Name)
+	case metric.Object != nil:
+		return fmt.Sprintf("%s %s", metric.Type, metric.Object.Metric.Name)
+	case metric.External != nil:
+		return fmt.Sprintf("%s %s", metric.Type, metric.External.Metric.Name)
+	}
+	return string(metric.Type)
+}
+
+func newImportVPACommand() *cobra.Command {
+	var o importOptions
+	var pause bool
+	cmd := &cobra.Command{
+		Use:   "vpa [name...]",
+		Short: "Generate Resize profiles from VerticalPodAutoscalers",
+		Long: "Generates a Resize profile for each VerticalPodAutoscaler, or the named ones, selecting the workload " +
+			"it resizes by its labels and taking its CPU requests from the VerticalPodAutoscaler's recommendations. " +
+			"With --pause-vpas the VerticalPodAutoscalers of the created profiles are switched to Off mode, so they " +
+			"keep recommending without applying their recommendations themselves.",
+		PreRun: prepare,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if pause && !o.apply {
+				return errors.New("--pause-vpas requires --apply")
+			}
+			config, err := restConfig(o.kubeconfig)
+			if err != nil {
+				return err
+			}
+			c, err := newClient(config)
+			if err != nil {
+				return err
+			}
+
+			list := &unstructured.UnstructuredList{}
+			list.SetGroupVersionKind(verticalPodAutoscalerListGVK)
+			if err := c.List(cmd.Context(), list, client.InNamespace(o.namespace)); err != nil {
+				return fmt.Errorf("listing verticalpodautoscalers: %w", err)
+			}
+			var vpas []unstructured.Unstructured
+			for _, vpa := range list.Items {
+				if len(args) == 0 || slices.Contains(args, vpa.GetName()) {
+					vpas = append(vpas, vpa)
+				}
+			}
+
+			imported, err := ImportVPAs(cmd.Context(), c, vpas)
+			if err != nil {
+				return err
+			}
+			return o.finish(cmd, c, imported, pause, func(i int) error {
+				return pauseVPA(cmd.Context(), c, &vpas[i])
+			})
+		},
+	}
+	o.bindFlags(cmd)
+	cmd.Flags().BoolVar(&pause, "pause-vpas", false, "Switch every VerticalPodAutoscaler whose profile was created to Off mode. "+
+		"Its previous mode is kept in the "+optimizerv1.PausedVPAUpdateModeAnnotation+" annotation.")
+	return cmd
+}
+
+// verticalPodAutoscalerListGVK lists VerticalPodAutoscalers, whose types are
+// not vendored.
+var verticalPodAutoscalerListGVK = schema.GroupVersionKind{Group: "autoscaling.k8s.io", Version: "v1", Kind: "VerticalPodAutoscalerList"}
+
+// importedResizeThresholds are the CPU thresholds of the profiles imported
+// from VerticalPodAutoscalers, which have none. They only decide when a target
+// is resized; its new CPU request comes from the VerticalPodAutoscaler.
+var importedResizeThresholds = optimizerv1.ThresholdSpec{Min: 40, Max: 80}
+
+// ImportVPAs generates the profile of each VerticalPodAutoscaler, in the same
+// order, from the workloads in the cluster they resize.
+func ImportVPAs(ctx context.Context, c client.Reader, vpas []unstructured.Unstructured) ([]Imported, error) {
+	imported := make([]Imported, 0, len(vpas))
+	for i := range vpas {
+		vpa := &vpas[i]
+		result := Imported{Source: fmt.Sprintf("VerticalPodAutoscaler %s/%s", vpa.GetNamespace(), vpa.GetName())}
+		kind, _, _ := unstructured.NestedString(vpa.Object, "spec", "targetRef", "kind")
+		name, _, _ := unstructured.NestedString(vpa.Object, "spec", "targetRef", "name")
+		workload, err := importTarget(ctx, c, vpa.GetNamespace(), kind, name)
+		if err != nil {
+			if !errors.Is(err, errNoImportTarget) {
+				return nil, err
+			}
+			result.Error = err.Error()
+			imported = append(imported, result)
+			continue
+		}
+		result.Profile, result.Warnings = ProfileFromVPA(vpa, workload)
+		others, err := otherMatches(ctx, c, vpa.GetNamespace(), workload.GetLabels(), kind+"/"+name)
+		if err != nil {
+			return nil, err
+		}
+		if len(others) > 0 {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("selector %s also matches %s",
+				labels.Set(workload.GetLabels()), strings.Join(others, ", ")))
+		}
+		imported = append(imported, result)
+	}
+	return imported, nil
+}
+
+// ProfileFromVPA returns the Resize profile equivalent to a
+// VerticalPodAutoscaler of a workload, and the settings it does not carry over.
+//
+// The profile takes its CPU requests from the VerticalPodAutoscaler, which
+// only recommends them once it is in Off mode, and bounds them by the
+// minAllowed and maxAllowed CPU of the container the profile resizes, the
+// first with a CPU request. The Off mode maps to the Recommend policy and the
+// Initial mode, which only sets requests on new pods, to the Admission action
+// mode.
+func ProfileFromVPA(vpa *unstructured.Unstructured, workload client.Object) (*optimizerv1.ResourceOptimizerProfile, []string) {
+	var warnings []string
+	profile := &optimizerv1.ResourceOptimizerProfile{
+		TypeMeta:   metav1.TypeMeta{APIVersion: optimizerv1.GroupVersion.String(), Kind: "ResourceOptimizerProfile"},
+		ObjectMeta: metav1.ObjectMeta{Name: vpa.GetName(), Namespace: vpa.GetNamespace()},
+		Spec: optimizerv1.ResourceOptimizerProfileSpec{
+			Selector:             metav1.LabelSelector{MatchLabels: workload.GetLabels()},
+			CPUThresholds:        importedResizeThresholds,
+			OptimizationPolicy:   "Resize",
+			RecommendationSource: "VPA",
+		},
+	}
+
+	mode, _, _ := unstructured.NestedString(vpa.Object, "spec", "updatePolicy", "updateMode")
+	if paused, ok := vpa.GetAnnotations()[optimizerv1.PausedVPAUpdateModeAnnotation]; ok {
+		mode = paused
+	}
+	switch mode {
+	case "Off":
+		profile.Spec.OptimizationPolicy = "Recommend"
+	case "Initial":
+		profile.Spec.ActionMode = "Admission"
+	}
+
+	container := ""
+	if template := podTemplate(workload); template != nil {
+		for _, c := range template.Spec.Containers {
+			if _, ok := c.Resources.Requests[corev1.ResourceCPU]; ok {
+				container = c.Name
+				break
+			}
+		}
+	}
+	if container == "" {
+		warnings = append(warnings, "the workload has no container with a CPU request; profiles only resize containers with one")
+	}
+
+	policies, _, _ := unstructured.NestedSlice(vpa.Object, "spec", "resourcePolicy", "containerPolicies")
+	var applied map[string]interface{}
+	for _, p := range policies {
+		policy, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(policy, "containerName")
+		switch {
+		case name == container && container != "":
+			applied = policy
+		case name == "*":
+			if applied == nil {
+				applied = policy
+			}
+		default:
+			warnings = append(warnings, fmt.Sprintf("the policy of container %s is not imported; profiles resize the first container with a CPU request", name))
+		}
+	}
+	resources := []string{"cpu", "memory"}
+	if applied != nil {
+		if cpu, ok, _ := unstructured.NestedString(applied, "minAllowed", "cpu"); ok {
+			if quantity, err := resource.ParseQuantity(cpu); err == nil {
+				profile.Spec.MinCPU = &quantity
+			}
+		}
+		if cpu, ok, _ := unstructured.NestedString(applied, "maxAllowed", "cpu"); ok {
+			if quantity, err := resource.ParseQuantity(cpu); err == nil {
+				profile.Spec.MaxCPU = &quantity
+			}
+		}
+		if controlled, ok, _ := unstructured.NestedStringSlice(applied, "controlledResources"); ok {
+			resources = controlled
+		}
+		if containerMode, _, _ := unstructured.NestedString(applied, "mode"); containerMode == "Off" {
+			warnings = append(warnings, "the container policy is Off, but the profile resizes the container")
+		}
+	}
+	if slices.Contains(resources, "memory") {
+		warnings = append(warnings, "memory is not imported; profiles only resize CPU requests")
+	}
+	if !slices.Contains(resources, "cpu") {
+		warnings = append(warnings, "the VerticalPodAutoscaler does not control CPU, but the profile resizes it")
+	}
+	return profile, warnings
+}
+
+// podTemplate returns the pod template of a Deployment or StatefulSet.
+func podTemplate(workload client.Object) *corev1.PodTemplateSpec {
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		return &w.Spec.Template
+	case *appsv1.StatefulSet:
+		return &w.Spec.Template
+	}
+	return nil
+}
+
+// errNoImportTarget marks autoscalers whose workload cannot be selected by a
+// profile.
+var errNoImportTarget = errors.New("no importable workload")
+
+// importTarget returns the Deployment or StatefulSet an autoscaler manages,
+// provided it has labels for the imported profile to select it by.
+func importTarget(ctx context.Context, c client.Reader, namespace, kind, name string) (client.Object, error) {
+	var workload client.Object
+	switch kind {
+	case "Deployment":
+		workload = &appsv1.Deployment{}
+	case "StatefulSet":
+		workload = &appsv1.StatefulSet{}
+	default:
+		return nil, fmt.Errorf("%w: profiles scale Deployments and StatefulSets, not %s %s", errNoImportTarget, kind, name)
+	}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, workload); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %s %s not found", errNoImportTarget, kind, name)
+		}
+		return nil, fmt.Errorf("getting %s %s: %w", kind, name, err)
+	}
+	if len(workload.GetLabels()) == 0 {
+		return nil, fmt.Errorf("%w: %s %s has no labels to select it by", errNoImportTarget, kind, name)
+	}
+	return workload, nil
+}
+
+// otherMatches lists the workloads other than target that a profile
+// selecting targetLabels would also scale.
+func otherMatches(ctx context.Context, c client.Reader, namespace string, targetLabels map[string]string, target string) ([]string, error) {
+	opts := []client.ListOption{client.InNamespace(namespace), client.MatchingLabels(targetLabels)}
+	var deployments appsv1.DeploymentList
+	if err := c.List(ctx, &deployments, opts...); err != nil {
+		return nil, fmt.Errorf("listing deployments: %w", err)
+	}
+	var statefulSets appsv1.StatefulSetList
+	if err := c.List(ctx, &statefulSets, opts...); err != nil {
+		return nil, fmt.Errorf("listing statefulsets: %w", err)
+	}
+	var others []string
+	for _, d := range deployments.Items {
+		if name := "Deployment/" + d.Name; name != target {
+			others = append(others, name)
+		}
+	}
+	for _, s := range statefulSets.Items {
+		if name := "StatefulSet/" + s.Name; name != target {
+			others = append(others, name)
+		}
+	}
+	return others, nil
+}
+
+// applyImported creates an imported profile. A profile of the same name that
+// already exists is left as it is.
+func applyImported(ctx context.Context, c client.Client, imported *Imported) error {
+	if imported.Profile == nil {
+		return nil
+	}
+	err := c.Create(ctx, imported.Profile.DeepCopy())
+	if apierrors.IsAlreadyExists(err) {
+		imported.Warnings = append(imported.Warnings, "a profile of the same name already exists and was left unchanged")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("creating the profile of %s: %w", imported.Source, err)
+	}
+	return nil
+}
+
+// pauseHPA disables both scaling directions of a HorizontalPodAutoscaler,
+// keeping its behavior in an annotation so that it can be restored.
+func pauseHPA(ctx context.Context, c client.Client, hpa *autoscalingv2.HorizontalPodAutoscaler) error {
+	if _, paused := hpa.Annotations[optimizerv1.PausedHPABehaviorAnnotation]; paused {
+		return nil
+	}
+	behavior, err := json.Marshal(hpa.Spec.Behavior)
+	if err != nil {
+		return err
+	}
+	patch := client.MergeFrom(hpa.DeepCopy())
+	if hpa.Annotations == nil {
+		hpa.Annotations = map[string]string{}
+	}
+	hpa.Annotations[optimizerv1.PausedHPABehaviorAnnotation] = string(behavior)
+	disabled := autoscalingv2.DisabledPolicySelect
+	hpa.Spec.Behavior = &autoscalingv2.HorizontalPodAutoscalerBehavior{
+		ScaleUp:   &autoscalingv2.HPAScalingRules{SelectPolicy: &disabled},
+		ScaleDown: &autoscalingv2.HPAScalingRules{SelectPolicy: &disabled},
+	}
+	return c.Patch(ctx, hpa, patch)
+}
+
+// pauseVPA switches a VerticalPodAutoscaler to Off mode, keeping its mode in
+// an annotation so that it can be restored. In Off mode it still recommends
+// the requests the imported profile applies.
+func pauseVPA(ctx context.Context, c client.Client, vpa *unstructured.Unstructured) error {
+	mode, _, _ := unstructured.NestedString(vpa.Object, "spec", "updatePolicy", "updateMode")
+	if _, paused := vpa.GetAnnotations()[optimizerv1.PausedVPAUpdateModeAnnotation]; paused || mode == "Off" {
+		return nil
+	}
+	if mode == "" {
+		mode = "Auto"
+	}
+	patch := client.MergeFrom(vpa.DeepCopy())
+	annotations := vpa.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[optimizerv1.PausedVPAUpdateModeAnnotation] = mode
+	vpa.SetAnnotations(annotations)
+	if err := unstructured.SetNestedField(vpa.Object, "Off", "spec", "updatePolicy", "updateMode"); err != nil {
+		return err
+	}
+	return c.Patch(ctx, vpa, patch)
+}
+
+// writeImported prints the imported profiles as YAML documents, preceded by
+// their warnings as comments. Autoscalers that could not be imported are
+// listed as comments too.
+func writeImported(w io.Writer, imported []Imported) error {
+	failed := false
+	for _, result := range imported {
+		if result.Error != "" {
+			failed = true
+			_, _ = fmt.Fprintf(w, "# %s was not imported: %s\n", result.Source, result.Error)
+			continue
+		}
+		out, err := yaml.Marshal(result.Profile)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(w, "---\n# Imported from %s.\n", result.Source)
+		for _, warning := range result.Warnings {
+			_, _ = fmt.Fprintf(w, "# Warning: %s.\n", warning)
+		}
+		_, _ = w.Write(out)
+	}
+	if failed {
+		return errImportIncomplete
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Import", func() {
+	labels := map[string]string{"app": "web"}
+	var hpa *autoscalingv2.HorizontalPodAutoscaler
+
+	BeforeEach(func() {
+		hpa = &autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "web"},
+				MinReplicas:    ptr.To[int32](2),
+				MaxReplicas:    10,
+				Metrics: []autoscalingv2.MetricSpec{{
+					Type: autoscalingv2.ResourceMetricSourceType,
+					Resource: &autoscalingv2.ResourceMetricSource{
+						Name:   corev1.ResourceCPU,
+						Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: ptr.To[int32](60)},
+					},
+				}},
+				Behavior: &autoscalingv2.HorizontalPodAutoscalerBehavior{
+					ScaleDown: &autoscalingv2.HPAScalingRules{StabilizationWindowSeconds: ptr.To[int32](600)},
+				},
+			},
+		}
+	})
+
+	newClient := func() *fake.ClientBuilder {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(autoscalingv2.AddToScheme(scheme)).To(Succeed())
+		Expect(optimizerv1.AddToScheme(scheme)).To(Succeed())
+		return fake.NewClientBuilder().WithScheme(scheme)
+	}
+
+	It("should place the CPU thresholds around the target utilization", func() {
+		profile, warnings := ProfileFromHPA(hpa, labels)
+		Expect(profile.Name).To(Equal("web"))
+		Expect(profile.Namespace).To(Equal("team-a"))
+		Expect(profile.Spec.OptimizationPolicy).To(Equal("Scale"))
+		Expect(profile.Spec.Selector.MatchLabels).To(Equal(labels))
+		Expect(profile.Spec.CPUThresholds).To(Equal(optimizerv1.ThresholdSpec{Min: 30, Max: 66}))
+		Expect(profile.Spec.MinReplicas).To(Equal(ptr.To[int32](2)))
+		Expect(profile.Spec.Behavior).To(Equal(hpa.Spec.Behavior))
+		Expect(warnings).To(ConsistOf("maxReplicas 10 is not imported; profiles do not cap the replicas"))
+	})
+
+	It("should warn about metrics other than CPU utilization", func() {
+		hpa.Spec.Metrics = []autoscalingv2.MetricSpec{{
+			Type: autoscalingv2.PodsMetricSourceType,
+			Pods: &autoscalingv2.PodsMetricSource{Metric: autoscalingv2.MetricIdentifier{Name: "requests_per_second"}},
+		}}
+		profile, warnings := ProfileFromHPA(hpa, labels)
+		Expect(profile.Spec.CPUThresholds).To(Equal(optimizerv1.ThresholdSpec{Min: 40, Max: 88}))
+		Expect(warnings).To(ContainElement("metric Pods requests_per_second is not imported; profiles scale on CPU utilization"))
+	})
+
+	It("should skip autoscalers of workloads that cannot be selected", func() {
+		other := hpa.DeepCopy()
+		other.Name = "jobs"
+		other.Spec.ScaleTargetRef = autoscalingv2.CrossVersionObjectReference{Kind: "ReplicaSet", Name: "jobs"}
+		c := newClient().WithObjects(
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", Labels: labels}},
+			&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "cache", Namespace: "team-a", Labels: labels}},
+		).Build()
+
+		imported, err := ImportHPAs(context.Background(), c, []autoscalingv2.HorizontalPodAutoscaler{*hpa, *other})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(imported).To(HaveLen(2))
+		Expect(imported[0].Profile).NotTo(BeNil())
+		Expect(imported[0].Warnings).To(ContainElement("selector app=web also matches StatefulSet/cache"))
+		Expect(imported[1].Profile).To(BeNil())
+		Expect(imported[1].Error).To(ContainSubstring("not ReplicaSet jobs"))
+
+		var out bytes.Buffer
+		Expect(writeImported(&out, imported)).To(MatchError(errImportIncomplete))
+		Expect(out.String()).To(ContainSubstring("# Imported from HorizontalPodAutoscaler team-a/web.\n"))
+		Expect(out.String()).To(ContainSubstring("kind: ResourceOptimizerProfile\n"))
+		Expect(out.String()).To(ContainSubstring("# HorizontalPodAutoscaler team-a/jobs was not imported: "))
+	})
+
+	It("should pause an autoscaler and import its behavior from before", func() {
+		c := newClient().WithObjects(hpa).Build()
+		Expect(pauseHPA(context.Background(), c, hpa)).To(Succeed())
+
+		var paused autoscalingv2.HorizontalPodAutoscaler
+		Expect(c.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "web"}, &paused)).To(Succeed())
+		Expect(*paused.Spec.Behavior.ScaleUp.SelectPolicy).To(Equal(autoscalingv2.DisabledPolicySelect))
+		Expect(*paused.Spec.Behavior.ScaleDown.SelectPolicy).To(Equal(autoscalingv2.DisabledPolicySelect))
+		Expect(paused.Annotations).To(HaveKey(optimizerv1.PausedHPABehaviorAnnotation))
+
+		profile, _ := ProfileFromHPA(&paused, labels)
+		Expect(profile.Spec.Behavior.ScaleDown.StabilizationWindowSeconds).To(Equal(ptr.To[int32](600)))
+		Expect(profile.Spec.Behavior.ScaleUp).To(BeNil())
+	})
+
+	Context("from VerticalPodAutoscalers", func() {
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", Labels: labels},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "proxy"},
+				{Name: "app", Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}}},
+			}}}},
+		}
+		vpa := func(mode string, policies ...interface{}) *unstructured.Unstructured {
+			u := &unstructured.Unstructured{Object: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"targetRef":      map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "name": "web"},
+					"updatePolicy":   map[string]interface{}{"updateMode": mode},
+					"resourcePolicy": map[string]interface{}{"containerPolicies": policies},
+				},
+			}}
+			u.SetAPIVersion("autoscaling.k8s.io/v1")
+			u.SetKind("VerticalPodAutoscaler")
+			u.SetNamespace("team-a")
+			u.SetName("web")
+			return u
+		}
+
+		It("should bound the CPU requests by the policy of the resized container", func() {
+			profile, warnings := ProfileFromVPA(vpa("Auto",
+				map[string]interface{}{"containerName": "*", "minAllowed": map[string]interface{}{"cpu": "50m"}},
+				map[string]interface{}{"containerName": "app", "minAllowed": map[string]interface{}{"cpu": "100m"},
+					"maxAllowed": map[string]interface{}{"cpu": "2", "memory": "1Gi"}, "controlledResources": []interface{}{"cpu"}},
+			), deployment)
+			Expect(profile.Spec.OptimizationPolicy).To(Equal("Resize"))
+			Expect(profile.Spec.RecommendationSource).To(Equal("VPA"))
+			Expect(profile.Spec.ActionMode).To(BeEmpty())
+			Expect(profile.Spec.Selector.MatchLabels).To(Equal(labels))
+			Expect(profile.Spec.MinCPU.String()).To(Equal("100m"))
+			Expect(profile.Spec.MaxCPU.String()).To(Equal("2"))
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("should map the update mode and warn about what is not imported", func() {
+			profile, warnings := ProfileFromVPA(vpa("Initial",
+				map[string]interface{}{"containerName": "proxy", "maxAllowed": map[string]interface{}{"cpu": "1"}},
+			), deployment)
+			Expect(profile.Spec.ActionMode).To(Equal("Admission"))
+			Expect(profile.Spec.MaxCPU).To(BeNil())
+			Expect(warnings).To(ConsistOf(
+				"the policy of container proxy is not imported; profiles resize the first container with a CPU request",
+				"memory is not imported; profiles only resize CPU requests",
+			))
+
+			profile, _ = ProfileFromVPA(vpa("Off"), deployment)
+			Expect(profile.Spec.OptimizationPolicy).To(Equal("Recommend"))
+		})
+
+		It("should switch a paused autoscaler to Off and import its previous mode", func() {
+			auto := vpa("Auto")
+			c := newClient().WithObjects(deployment, auto).Build()
+			imported, err := ImportVPAs(context.Background(), c, []unstructured.Unstructured{*auto})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(imported).To(HaveLen(1))
+			Expect(imported[0].Profile).NotTo(BeNil())
+			Expect(pauseVPA(context.Background(), c, auto)).To(Succeed())
+
+			paused := &unstructured.Unstructured{}
+			paused.SetGroupVersionKind(auto.GroupVersionKind())
+			Expect(c.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "web"}, paused)).To(Succeed())
+			mode, _, _ := unstructured.NestedString(paused.Object, "spec", "updatePolicy", "updateMode")
+			Expect(mode).To(Equal("Off"))
+			Expect(paused.GetAnnotations()).To(HaveKeyWithValue(optimizerv1.PausedVPAUpdateModeAnnotation, "Auto"))
+
+			profile, _ := ProfileFromVPA(paused, deployment)
+			Expect(profile.Spec.OptimizationPolicy).To(Equal("Resize"))
+		})
+	})
+})
@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	webhookv1 "github.com/OpScaleHub/K20s/internal/webhook/v1"
+)
+
+// errLintFailed is returned once the findings are printed if any profile
+// would be rejected.
+var errLintFailed = errors.New("some profiles are invalid")
+
+// Finding is a problem lint found in a profile.
+type Finding struct {
+	Source    string
+	Namespace string
+	Name      string
+	// Error is true for problems the admission webhook rejects. Warnings are
+	// admitted.
+	Error   bool
+	Message string
+}
+
+func (f Finding) String() string {
+	severity := "warning"
+	if f.Error {
+		severity = "error"
+	}
+	return fmt.Sprintf("%s: %s/%s: %s: %s", f.Source, f.Namespace, f.Name, severity, f.Message)
+}
+
+// NewLintCommand returns the lint command.
+func NewLintCommand() *cobra.Command {
+	var kubeconfig, namespace string
+	var checkCluster, strict bool
+	cmd := &cobra.Command{
+		Use:   "lint file.yaml...",
+		Short: "Check profiles with the admission webhook's rules",
+		Long: "Runs the checks of the admission webhook on the profiles in the files, without a cluster unless " +
+			"--check-cluster is set.",
+		Args:   cobra.MinimumNArgs(1),
+		PreRun: prepare,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var c client.Reader
+			if checkCluster {
+				config, err := restConfig(kubeconfig)
+				if err != nil {
+					return err
+				}
+				if c, err = newClient(config); err != nil {
+					return err
+				}
+			}
+
+			var findings []Finding
+			for _, path := range args {
+				profiles, err := readProfiles(path, namespace)
+				if err != nil {
+					return err
+				}
+				for i := range profiles {
+					found, err := Lint(cmd.Context(), c, path, &profiles[i])
+					if err != nil {
+						return err
+					}
+					findings = append(findings, found...)
+				}
+			}
+
+			failed := false
+			for _, f := range findings {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), f)
+				if f.Error || strict {
+					failed = true
+				}
+			}
+			if failed {
+				return errLintFailed
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to a kubeconfig. Defaults to $KUBECONFIG, the in-cluster config or ~/.kube/config.")
+	cmd.Flags().StringVar(&namespace, "namespace", "", "The namespace of profiles in the files without one. Defaults to \"default\".")
+	cmd.Flags().BoolVar(&checkCluster, "check-cluster", false, "Also look up the workloads each profile selects and warn when there are none or when an HPA manages them.")
+	cmd.Flags().BoolVar(&strict, "warnings-as-errors", false, "Fail on warnings too.")
+	return cmd
+}
+
+// Lint runs the webhook's checks on profile. When c is set it also checks the
+// workloads the profile selects in the cluster.
+func Lint(ctx context.Context, c client.Reader, source string, profile *optimizerv1.ResourceOptimizerProfile) ([]Finding, error) {
+	var findings []Finding
+	add := func(isError bool, message string) {
+		findings = append(findings, Finding{
+			Source:    source,
+			Namespace: profile.Namespace,
+			Name:      profile.Name,
+			Error:     isError,
+			Message:   message,
+		})
+	}
+
+	warnings, err := webhookv1.ValidateProfile(profile)
+	if err != nil {
+		var status apierrors.APIStatus
+		if errors.As(err, &status) && status.Status().Details != nil && len(status.Status().Details.Causes) > 0 {
+			for _, cause := range status.Status().Details.Causes {
+				add(true, cause.Field+": "+cause.Message)
+			}
+		} else {
+			add(true, err.Error())
+		}
+	}
+	for _, warning := range warnings {
+		add(false, warning)
+	}
+	if c == nil || err != nil {
+		return findings, nil
+	}
+
+	messages, err := lintTargets(ctx, c, profile)
+	if err != nil {
+		return nil, fmt.Errorf("%s/%s: %w", profile.Namespace, profile.Name, err)
+	}
+	for _, message := range messages {
+		add(false, message)
+	}
+	return findings, nil
+}
+
+// lintTargets warns about profiles that select no workloads and about
+// workloads that are also scaled by a HorizontalPodAutoscaler, which would
+// fight the Scale policy over the replica count. HorizontalPodAutoscalers
+// paused by import hpa no longer scale.
+func lintTargets(ctx context.Context, c client.Reader, profile *optimizerv1.ResourceOptimizerProfile) ([]string, error) {
+	opts := []client.ListOption{
+		client.InNamespace(profile.Namespace),
+		client.MatchingLabels(profile.Spec.Selector.MatchLabels),
+	}
+	var deployments appsv1.DeploymentList
+	if err := c.List(ctx, &deployments, opts...); err != nil {
+		return nil, fmt.Errorf("listing deployments: %w", err)
+	}
+	var statefulSets appsv1.StatefulSetList
+	if err := c.List(ctx, &statefulSets, opts...); err != nil {
+		return nil, fmt.Errorf("listing statefulsets: %w", err)
+	}
+	if len(deployments.Items) == 0 && len(statefulSets.Items) == 0 {
+		return []string{fmt.Sprintf("selector %s matches no Deployments or StatefulSets", labels.Set(profile.Spec.Selector.MatchLabels))}, nil
+	}
+
+	targets := map[string]bool{}
+	for _, d := range deployments.Items {
+		targets["Deployment/"+d.Name] = true
+	}
+	for _, s := range statefulSets.Items {
+		targets["StatefulSet/"+s.Name] = true
+	}
+	var hpas autoscalingv2.HorizontalPodAutoscalerList
+	if err := c.List(ctx, &hpas, client.InNamespace(profile.Namespace)); err != nil {
+		return nil, fmt.Errorf("listing horizontalpodautoscalers: %w", err)
+	}
+	var messages []string
+	for _, hpa := range hpas.Items {
+		if _, paused := hpa.Annotations[optimizerv1.PausedHPABehaviorAnnotation]; paused {
+			continue
+		}
+		ref := hpa.Spec.ScaleTargetRef
+		if target := ref.Kind + "/" + ref.Name; targets[target] {
+			messages = append(messages, fmt.Sprintf("selector matches %s, which is also scaled by HorizontalPodAutoscaler %s", target, hpa.Name))
+		}
+	}
+	return messages, nil
+}
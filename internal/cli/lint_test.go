@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Lint", func() {
+	labels := map[string]string{"app": "web"}
+	var profile *optimizerv1.ResourceOptimizerProfile
+
+	BeforeEach(func() {
+		profile = &optimizerv1.ResourceOptimizerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+			Spec: optimizerv1.ResourceOptimizerProfileSpec{
+				Selector:           metav1.LabelSelector{MatchLabels: labels},
+				CPUThresholds:      optimizerv1.ThresholdSpec{Min: 20, Max: 80},
+				OptimizationPolicy: "Scale",
+			},
+		}
+	})
+
+	newClient := func() *fake.ClientBuilder {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(autoscalingv2.AddToScheme(scheme)).To(Succeed())
+		return fake.NewClientBuilder().WithScheme(scheme)
+	}
+
+	It("should report nothing for a valid profile", func() {
+		findings, err := Lint(context.Background(), nil, "web.yaml", profile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(findings).To(BeEmpty())
+	})
+
+	It("should report every field the webhook rejects", func() {
+		profile.Spec.CPUThresholds = optimizerv1.ThresholdSpec{Min: 90, Max: 80}
+		profile.Spec.OptimizationPolicy = "Shrink"
+		findings, err := Lint(context.Background(), nil, "web.yaml", profile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(findings).To(HaveLen(2))
+		Expect(findings[0].Error).To(BeTrue())
+		Expect(findings[0].String()).To(HavePrefix("web.yaml: team-a/web: error: spec.cpuThresholds: "))
+		Expect(findings[1].String()).To(ContainSubstring("spec.optimizationPolicy: "))
+	})
+
+	It("should warn when the selector matches nothing in the cluster", func() {
+		findings, err := Lint(context.Background(), newClient().Build(), "web.yaml", profile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(findings).To(HaveLen(1))
+		Expect(findings[0].Error).To(BeFalse())
+		Expect(findings[0].Message).To(Equal("selector app=web matches no Deployments or StatefulSets"))
+	})
+
+	It("should warn when a target is also scaled by an HPA", func() {
+		c := newClient().WithObjects(
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", Labels: labels}},
+			&autoscalingv2.HorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Name: "web-hpa", Namespace: "team-a"},
+				Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "web"},
+				},
+			},
+		).Build()
+		findings, err := Lint(context.Background(), c, "web.yaml", profile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(findings).To(HaveLen(1))
+		Expect(findings[0].Message).To(Equal("selector matches Deployment/web, which is also scaled by HorizontalPodAutoscaler web-hpa"))
+	})
+})
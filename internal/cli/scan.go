@@ -0,0 +1,206 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/controller"
+)
+
+// ScanResult is the evaluation of a single profile by scan.
+type ScanResult struct {
+	Namespace      string   `json:"namespace"`
+	Name           string   `json:"name"`
+	Policy         string   `json:"policy"`
+	CPUUtilization float64  `json:"cpuUtilization"`
+	Action         string   `json:"action"`
+	WouldExecute   bool     `json:"wouldExecute"`
+	SkipReason     string   `json:"skipReason,omitempty"`
+	Targets        []Target `json:"targets,omitempty"`
+	// Error is set when the profile could not be evaluated.
+	Error string `json:"error,omitempty"`
+}
+
+// Target is the CPU request recommended for a profile's target.
+type Target struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	CPURequest string `json:"cpuRequest"`
+}
+
+// errProfilesFailed is returned once the report is printed if any profile
+// could not be evaluated.
+var errProfilesFailed = errors.New("some profiles could not be evaluated")
+
+// NewScanCommand returns the scan command.
+func NewScanCommand() *cobra.Command {
+	var kubeconfig, prometheusURL, namespace, output string
+	var prometheusTLS controller.PrometheusTLSConfig
+	var files []string
+	cmd := &cobra.Command{
+		Use:   "scan",
+		Short: "Print the actions and CPU requests the controller would choose now",
+		Long: "Evaluates profiles once against the cluster and Prometheus and prints the actions and CPU requests " +
+			"the controller would choose. Nothing is changed.",
+		Args:   cobra.NoArgs,
+		PreRun: prepare,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if output != "text" && output != "json" {
+				return fmt.Errorf("unknown output format %q", output)
+			}
+
+			c, promAPI, err := connect(kubeconfig, prometheusURL, prometheusTLS)
+			if err != nil {
+				return err
+			}
+
+			var profiles []optimizerv1.ResourceOptimizerProfile
+			if len(files) > 0 {
+				for _, path := range files {
+					read, err := readProfiles(path, namespace)
+					if err != nil {
+						return err
+					}
+					profiles = append(profiles, read...)
+				}
+			} else {
+				var list optimizerv1.ResourceOptimizerProfileList
+				if err := c.List(cmd.Context(), &list, client.InNamespace(namespace)); err != nil {
+					return fmt.Errorf("listing profiles: %w", err)
+				}
+				profiles = list.Items
+			}
+
+			results := Scan(cmd.Context(), c, promAPI, profiles)
+			if output == "json" {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(results); err != nil {
+					return err
+				}
+			} else if err := writeScanReport(cmd.OutOrStdout(), results); err != nil {
+				return err
+			}
+			for _, result := range results {
+				if result.Error != "" {
+					return errProfilesFailed
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to a kubeconfig. Defaults to $KUBECONFIG, the in-cluster config or ~/.kube/config.")
+	cmd.Flags().StringVar(&prometheusURL, "prometheus-url", controller.PrometheusURLFromEnv(),
+		"The Prometheus to query, or a comma-separated list of the replicas of a highly available Prometheus.")
+	prometheusTLS.BindFlags(cmd.Flags())
+	cmd.Flags().StringVar(&namespace, "namespace", "", "Only scan profiles in this namespace. Profiles read from files without a namespace are placed here, or in \"default\".")
+	cmd.Flags().StringVar(&output, "output", "text", "Report format: text or json.")
+	cmd.Flags().StringArrayVarP(&files, "filename", "f", nil, "Evaluate the profiles in this YAML file instead of the ones in the cluster. May be repeated.")
+	return cmd
+}
+
+// Scan evaluates every profile once, like a reconcile would, without changing
+// the profiles or their targets.
+func Scan(ctx context.Context, c client.Reader, promAPI controller.PrometheusClient, profiles []optimizerv1.ResourceOptimizerProfile) []ScanResult {
+	results := make([]ScanResult, 0, len(profiles))
+	for i := range profiles {
+		profile := &profiles[i]
+		result := ScanResult{Namespace: profile.Namespace, Name: profile.Name, Policy: profile.Spec.OptimizationPolicy}
+		cpu, err := controller.QueryCPU(ctx, c, promAPI, profile)
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+		sim, err := controller.Simulate(ctx, c, profile, cpu)
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+		result.CPUUtilization = cpu
+		result.Action = sim.Action
+		result.WouldExecute = sim.Executed
+		result.SkipReason = sim.SkipReason
+		for _, target := range sim.Targets {
+			result.Targets = append(result.Targets, Target{Kind: target.Kind, Name: target.Name, CPURequest: target.CPURequest.String()})
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func writeScanReport(w io.Writer, results []ScanResult) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAMESPACE\tNAME\tPOLICY\tCPU\tACTION\tRECOMMENDED CPU REQUESTS")
+	for _, r := range results {
+		if r.Error != "" {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t-\terror: %s\t-\n", r.Namespace, r.Name, r.Policy, r.Error)
+			continue
+		}
+		action := r.Action
+		if r.SkipReason != "" {
+			action += " (" + r.SkipReason + ")"
+		}
+		var targets []string
+		for _, t := range r.Targets {
+			targets = append(targets, fmt.Sprintf("%s/%s=%s", t.Kind, t.Name, t.CPURequest))
+		}
+		if len(targets) == 0 {
+			targets = []string{"-"}
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%.2f%%\t%s\t%s\n", r.Namespace, r.Name, r.Policy, r.CPUUtilization, action,
+			strings.Join(targets, ", "))
+	}
+	return tw.Flush()
+}
+
+// readProfiles decodes the ResourceOptimizerProfiles in a YAML or JSON file,
+// which may hold several documents.
+func readProfiles(path, namespace string) ([]optimizerv1.ResourceOptimizerProfile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return decodeProfiles(f, path, namespace)
+}
+
+func decodeProfiles(r io.Reader, source, namespace string) ([]optimizerv1.ResourceOptimizerProfile, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+	var profiles []optimizerv1.ResourceOptimizerProfile
+	dec := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		var profile optimizerv1.ResourceOptimizerProfile
+		if err := dec.Decode(&profile); err != nil {
+			if errors.Is(err, io.EOF) {
+				return profiles, nil
+			}
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+		if profile.Kind == "" && profile.Name == "" {
+			// An empty document, e.g. after a trailing "---".
+			continue
+		}
+		if profile.Kind != "ResourceOptimizerProfile" {
+			return nil, fmt.Errorf("%s: %s %q is not a ResourceOptimizerProfile", source, profile.Kind, profile.Name)
+		}
+		if profile.Namespace == "" {
+			profile.Namespace = namespace
+		}
+		profiles = append(profiles, profile)
+	}
+}
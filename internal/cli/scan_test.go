@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// fakePrometheus answers every query with the same CPU utilization, or error.
+type fakePrometheus struct {
+	value float64
+	err   error
+}
+
+func (f fakePrometheus) Query(context.Context, string, time.Time, ...prometheusv1.Option) (model.Value, prometheusv1.Warnings, error) {
+	if f.err != nil {
+		return nil, nil, f.err
+	}
+	return model.Vector{{Metric: model.Metric{"pod": "web-0"}, Value: model.SampleValue(f.value)}}, nil, nil
+}
+
+func (f fakePrometheus) QueryRange(_ context.Context, _ string, r prometheusv1.Range, _ ...prometheusv1.Option) (model.Value, prometheusv1.Warnings, error) {
+	if f.err != nil {
+		return nil, nil, f.err
+	}
+	series := &model.SampleStream{Metric: model.Metric{"pod": "web-0"}}
+	for ts := r.Start; !ts.After(r.End); ts = ts.Add(r.Step) {
+		series.Values = append(series.Values, model.SamplePair{Timestamp: model.TimeFromUnixNano(ts.UnixNano()), Value: model.SampleValue(f.value)})
+	}
+	return model.Matrix{series}, nil, nil
+}
+
+var _ = Describe("Scan", func() {
+	labels := map[string]string{"app": "web"}
+	profile := optimizerv1.ResourceOptimizerProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+		Spec: optimizerv1.ResourceOptimizerProfileSpec{
+			Selector:           metav1.LabelSelector{MatchLabels: labels},
+			CPUThresholds:      optimizerv1.ThresholdSpec{Min: 20, Max: 80},
+			OptimizationPolicy: "Resize",
+		},
+	}
+
+	newClient := func() *fake.ClientBuilder {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(optimizerv1.AddToScheme(scheme)).To(Succeed())
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "team-a", Labels: labels}},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", Labels: labels},
+				Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Name: "app",
+					Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("100m"),
+					}},
+				}}}}},
+			},
+		)
+	}
+
+	It("should report the action and requests the controller would choose", func() {
+		results := Scan(context.Background(), newClient().Build(), fakePrometheus{value: 95},
+			[]optimizerv1.ResourceOptimizerProfile{profile})
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].Error).To(BeEmpty())
+		Expect(results[0].CPUUtilization).To(Equal(95.0))
+		Expect(results[0].Action).To(Equal("ResizeUp"))
+		Expect(results[0].WouldExecute).To(BeTrue())
+		Expect(results[0].Targets).To(HaveLen(1))
+		Expect(results[0].Targets[0].Kind).To(Equal("Deployment"))
+
+		var out bytes.Buffer
+		Expect(writeScanReport(&out, results)).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("team-a     web   Resize  95.00%  ResizeUp  Deployment/web="))
+	})
+
+	It("should report profiles that cannot be evaluated", func() {
+		results := Scan(context.Background(), newClient().Build(), fakePrometheus{err: errors.New("connection refused")},
+			[]optimizerv1.ResourceOptimizerProfile{profile})
+		Expect(results[0].Error).To(ContainSubstring("connection refused"))
+
+		var out bytes.Buffer
+		Expect(writeScanReport(&out, results)).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("error: connection refused"))
+	})
+
+	It("should read hypothetical profiles from YAML", func() {
+		profiles, err := decodeProfiles(strings.NewReader(`
+apiVersion: optimizer.k20s.opscale.ir/v1
+kind: ResourceOptimizerProfile
+metadata:
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+  cpuThresholds:
+    min: 20
+    max: 80
+  optimizationPolicy: Recommend
+---
+apiVersion: optimizer.k20s.opscale.ir/v1
+kind: ResourceOptimizerProfile
+metadata:
+  name: api
+  namespace: team-b
+spec:
+  selector:
+    matchLabels:
+      app: api
+  cpuThresholds:
+    min: 10
+    max: 90
+  optimizationPolicy: Scale
+---
+`), "profiles.yaml", "team-a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(profiles).To(HaveLen(2))
+		Expect(profiles[0].Namespace).To(Equal("team-a"))
+		Expect(profiles[0].Spec.OptimizationPolicy).To(Equal("Recommend"))
+		Expect(profiles[1].Namespace).To(Equal("team-b"))
+
+		_, err = decodeProfiles(strings.NewReader("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: x\n"), "cm.yaml", "")
+		Expect(err).To(MatchError(ContainSubstring("is not a ResourceOptimizerProfile")))
+	})
+
+	It("should reject bad flags", func() {
+		for _, args := range [][]string{{"--output", "xml"}, {"--unknown"}, {"extra"}} {
+			cmd := NewScanCommand()
+			cmd.SetArgs(args)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			Expect(cmd.Execute()).NotTo(Succeed(), "args %v", args)
+		}
+	})
+})
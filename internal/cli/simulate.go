@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/controller"
+)
+
+// defaultSimulationStep matches the interval at which profiles are requeued.
+const defaultSimulationStep = 5 * time.Minute
+
+// SimulationResult is the replay of a single profile by simulate.
+type SimulationResult struct {
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Policy    string    `json:"policy"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	// Samples is the number of evaluations replayed.
+	Samples  int          `json:"samples"`
+	Steps    []ReplayStep `json:"steps"`
+	Executed int          `json:"executed"`
+	Skipped  int          `json:"skipped"`
+	// Error is set when the history could not be read.
+	Error string `json:"error,omitempty"`
+}
+
+// ReplayStep is an evaluation that called for an action.
+type ReplayStep struct {
+	Time           time.Time `json:"time"`
+	CPUUtilization float64   `json:"cpuUtilization"`
+	Action         string    `json:"action"`
+	Executed       bool      `json:"executed"`
+	SkipReason     string    `json:"skipReason,omitempty"`
+}
+
+// NewSimulateCommand returns the simulate command.
+func NewSimulateCommand() *cobra.Command {
+	var kubeconfig, prometheusURL, namespace, output, profilePath, from, to string
+	var prometheusTLS controller.PrometheusTLSConfig
+	var step time.Duration
+	cmd := &cobra.Command{
+		Use:   "simulate --profile file.yaml",
+		Short: "Replay recorded CPU utilization through the controller's decisions",
+		Long: "Replays the CPU utilization recorded by Prometheus through the controller's decisions and prints " +
+			"the actions each profile would have taken. Nothing is changed.",
+		Args:   cobra.NoArgs,
+		PreRun: prepare,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if output != "text" && output != "json" {
+				return fmt.Errorf("unknown output format %q", output)
+			}
+			if step <= 0 {
+				return errors.New("--step must be positive")
+			}
+			now := time.Now()
+			start, err := parseTime(from, now)
+			if err != nil {
+				return fmt.Errorf("--from: %w", err)
+			}
+			end, err := parseTime(to, now)
+			if err != nil {
+				return fmt.Errorf("--to: %w", err)
+			}
+			if !start.Before(end) {
+				return errors.New("--from must be before --to")
+			}
+
+			profiles, err := readProfiles(profilePath, namespace)
+			if err != nil {
+				return err
+			}
+			c, promAPI, err := connect(kubeconfig, prometheusURL, prometheusTLS)
+			if err != nil {
+				return err
+			}
+
+			results := Simulate(cmd.Context(), c, promAPI, profiles, prometheusv1.Range{Start: start, End: end, Step: step})
+			if output == "json" {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(results); err != nil {
+					return err
+				}
+			} else if err := writeSimulationReport(cmd.OutOrStdout(), results); err != nil {
+				return err
+			}
+			for _, result := range results {
+				if result.Error != "" {
+					return errProfilesFailed
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to a kubeconfig. Defaults to $KUBECONFIG, the in-cluster config or ~/.kube/config.")
+	cmd.Flags().StringVar(&prometheusURL, "prometheus-url", controller.PrometheusURLFromEnv(),
+		"The Prometheus to query, or a comma-separated list of the replicas of a highly available Prometheus.")
+	prometheusTLS.BindFlags(cmd.Flags())
+	cmd.Flags().StringVar(&namespace, "namespace", "", "The namespace of profiles in the file without one. Defaults to \"default\".")
+	cmd.Flags().StringVar(&output, "output", "text", "Report format: text or json.")
+	cmd.Flags().StringVar(&profilePath, "profile", "", "The YAML file with the profiles to simulate.")
+	cmd.Flags().StringVar(&from, "from", "-7d", "Start of the replayed history: an RFC 3339 time, or a duration before now like -7d or -12h.")
+	cmd.Flags().StringVar(&to, "to", "now", "End of the replayed history, in the same formats as --from.")
+	cmd.Flags().DurationVar(&step, "step", defaultSimulationStep, "Time between replayed evaluations.")
+	_ = cmd.MarkFlagRequired("profile")
+	return cmd
+}
+
+// Simulate replays the CPU utilization of every profile's targets over r
+// through the controller's decisions.
+func Simulate(ctx context.Context, c client.Reader, promAPI controller.PrometheusRangeClient, profiles []optimizerv1.ResourceOptimizerProfile, r prometheusv1.Range) []SimulationResult {
+	results := make([]SimulationResult, 0, len(profiles))
+	for i := range profiles {
+		profile := &profiles[i]
+		result := SimulationResult{
+			Namespace: profile.Namespace,
+			Name:      profile.Name,
+			Policy:    profile.Spec.OptimizationPolicy,
+			From:      r.Start,
+			To:        r.End,
+			Steps:     []ReplayStep{},
+		}
+		samples, err := controller.QueryCPUHistory(ctx, c, promAPI, profile, r)
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+		result.Samples = len(samples)
+		for _, step := range controller.Replay(profile, samples) {
+			result.Steps = append(result.Steps, ReplayStep(step))
+			if step.Executed {
+				result.Executed++
+			} else {
+				result.Skipped++
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func writeSimulationReport(w io.Writer, results []SimulationResult) error {
+	for i, r := range results {
+		if i > 0 {
+			_, _ = fmt.Fprintln(w)
+		}
+		_, _ = fmt.Fprintf(w, "%s/%s (%s) from %s to %s\n", r.Namespace, r.Name, r.Policy,
+			r.From.Format(time.DateTime), r.To.Format(time.DateTime))
+		if r.Error != "" {
+			_, _ = fmt.Fprintf(w, "error: %s\n", r.Error)
+			continue
+		}
+		if len(r.Steps) > 0 {
+			tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+			_, _ = fmt.Fprintln(tw, "TIME\tCPU\tACTION\tRESULT")
+			for _, step := range r.Steps {
+				result := "executed"
+				if !step.Executed {
+					result = "skipped (" + step.SkipReason + ")"
+				}
+				_, _ = fmt.Fprintf(tw, "%s\t%.2f%%\t%s\t%s\n", step.Time.Format(time.DateTime), step.CPUUtilization, step.Action, result)
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+		}
+		_, _ = fmt.Fprintf(w, "%d evaluations, %d actions executed, %d skipped\n", r.Samples, r.Executed, r.Skipped)
+	}
+	return nil
+}
+
+// parseTime parses an RFC 3339 time, "now", or a negative duration relative to
+// now. Durations accept the units of time.ParseDuration and d for days.
+func parseTime(value string, now time.Time) (time.Time, error) {
+	if value == "now" {
+		return now, nil
+	}
+	if rest, ok := strings.CutPrefix(value, "-"); ok {
+		if days, ok := strings.CutSuffix(rest, "d"); ok {
+			n, err := strconv.Atoi(days)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid duration %q", value)
+			}
+			return now.AddDate(0, 0, -n), nil
+		}
+		d, err := time.ParseDuration(rest)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return now.Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
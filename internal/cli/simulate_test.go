@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Simulate", func() {
+	labels := map[string]string{"app": "web"}
+	profile := optimizerv1.ResourceOptimizerProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+		Spec: optimizerv1.ResourceOptimizerProfileSpec{
+			Selector:           metav1.LabelSelector{MatchLabels: labels},
+			CPUThresholds:      optimizerv1.ThresholdSpec{Min: 20, Max: 80},
+			OptimizationPolicy: "Scale",
+			CooldownPeriod:     &metav1.Duration{Duration: 15 * time.Minute},
+		},
+	}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	window := prometheusv1.Range{Start: start, End: start.Add(time.Hour), Step: 5 * time.Minute}
+
+	newClient := func() *fake.ClientBuilder {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		return fake.NewClientBuilder().WithScheme(scheme)
+	}
+
+	It("should report the actions the history would have triggered", func() {
+		c := newClient().WithObjects(&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", Labels: labels},
+		}).Build()
+		results := Simulate(context.Background(), c, fakePrometheus{value: 90}, []optimizerv1.ResourceOptimizerProfile{profile}, window)
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].Error).To(BeEmpty())
+		Expect(results[0].Samples).To(Equal(13))
+		// Every third sample is outside the cooldown of the previous action.
+		Expect(results[0].Executed).To(Equal(5))
+		Expect(results[0].Skipped).To(Equal(8))
+
+		var out bytes.Buffer
+		Expect(writeSimulationReport(&out, results)).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("team-a/web (Scale) from 2025-01-01 00:00:00 to 2025-01-01 01:00:00"))
+		Expect(out.String()).To(ContainSubstring("2025-01-01 00:05:00  90.00%  ScaleUp  skipped (cooldown)"))
+		Expect(out.String()).To(ContainSubstring("13 evaluations, 5 actions executed, 8 skipped"))
+	})
+
+	It("should report profiles without targets", func() {
+		results := Simulate(context.Background(), newClient().Build(), fakePrometheus{value: 90},
+			[]optimizerv1.ResourceOptimizerProfile{profile}, window)
+		Expect(results[0].Error).To(ContainSubstring("matches no Deployment or StatefulSet"))
+	})
+
+	It("should parse absolute and relative times", func() {
+		now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+		Expect(parseTime("now", now)).To(Equal(now))
+		Expect(parseTime("-7d", now)).To(Equal(time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)))
+		Expect(parseTime("-90m", now)).To(Equal(now.Add(-90 * time.Minute)))
+		Expect(parseTime("2025-03-01T00:00:00Z", now)).To(Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)))
+		_, err := parseTime("-xd", now)
+		Expect(err).To(HaveOccurred())
+	})
+})
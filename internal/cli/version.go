@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/OpScaleHub/K20s/internal/version"
+)
+
+// NewVersionCommand returns the version command.
+func NewVersionCommand() *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print the version of the binary and the API versions it supports",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			info := version.Get()
+			switch output {
+			case "text":
+				w := cmd.OutOrStdout()
+				_, _ = fmt.Fprintf(w, "Version:      %s\n", info.Version)
+				_, _ = fmt.Fprintf(w, "Git commit:   %s\n", info.GitCommit)
+				_, _ = fmt.Fprintf(w, "Build date:   %s\n", info.BuildDate)
+				_, _ = fmt.Fprintf(w, "Go version:   %s\n", info.GoVersion)
+				_, _ = fmt.Fprintf(w, "API versions: %s\n", strings.Join(info.APIVersions, ", "))
+				return nil
+			case "json":
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(info)
+			default:
+				return fmt.Errorf("unknown output format %q", output)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&output, "output", "text", "Output format: text or json.")
+	return cmd
+}
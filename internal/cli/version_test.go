@@ -0,0 +1,27 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/OpScaleHub/K20s/internal/version"
+)
+
+var _ = Describe("Version", func() {
+	It("should print the build and the supported API versions", func() {
+		var out bytes.Buffer
+		cmd := NewVersionCommand()
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"--output", "json"})
+		Expect(cmd.Execute()).To(Succeed())
+
+		var info version.Info
+		Expect(json.Unmarshal(out.Bytes(), &info)).To(Succeed())
+		Expect(info.Version).To(Equal("dev"))
+		Expect(info.GitCommit).NotTo(BeEmpty())
+		Expect(info.APIVersions).To(ConsistOf("optimizer.k20s.opscale.ir/v1"))
+	})
+})
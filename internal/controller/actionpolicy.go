@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// actionPolicyCostLimit bounds the cost of evaluating a single rule, so that an
+// expensive expression cannot stall reconciles.
+const actionPolicyCostLimit = 100000
+
+// actionPolicyEnv declares the variables action policy rules are evaluated
+// against.
+var actionPolicyEnv = sync.OnceValues(func() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("action", cel.StringType),
+		cel.Variable("value", cel.DoubleType),
+		cel.Variable("now", cel.TimestampType),
+		cel.Variable("profile", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("target", cel.MapType(cel.StringType, cel.DynType)),
+		cel.OptionalTypes(),
+		ext.Strings(),
+	)
+})
+
+// CompileActionRule compiles the CEL expression of an action policy rule,
+// which must evaluate to a bool.
+func CompileActionRule(expression string) (cel.Program, error) {
+	env, err := actionPolicyEnv()
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("expression must evaluate to a bool, not %s", ast.OutputType())
+	}
+	return env.Program(ast, cel.CostLimit(actionPolicyCostLimit))
+}
+
+// maxActionPrograms bounds the number of compiled rules kept in actionPrograms.
+const maxActionPrograms = 1024
+
+// actionPrograms caches compiled action policy rules by expression, since the
+// rules are evaluated for every target of every action. It is cleared when it
+// fills up, so that edited rules do not accumulate.
+var actionPrograms = struct {
+	sync.Mutex
+	programs map[string]cel.Program
+}{programs: map[string]cel.Program{}}
+
+// actionProgram returns the compiled program of an action policy rule.
+func actionProgram(expression string) (cel.Program, error) {
+	actionPrograms.Lock()
+	defer actionPrograms.Unlock()
+	if program, ok := actionPrograms.programs[expression]; ok {
+		return program, nil
+	}
+	program, err := CompileActionRule(expression)
+	if err != nil {
+		return nil, err
+	}
+	if len(actionPrograms.programs) >= maxActionPrograms {
+		clear(actionPrograms.programs)
+	}
+	actionPrograms.programs[expression] = program
+	return program, nil
+}
+
+// actionDenied evaluates the profile's action policy for an action on a single
+// target and returns why the action is denied, or "" if every rule allows it.
+// A rule that cannot be compiled or evaluated denies the action, since a
+// broken rule must not let through what it was written to prevent.
+func actionDenied(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, action string, observedValue float64, now time.Time, kind string, target metav1.Object) string {
+	if len(profile.Spec.ActionPolicy) == 0 {
+		return ""
+	}
+	vars := actionInput(profile, action, observedValue, now, kind, target)
+	for _, rule := range profile.Spec.ActionPolicy {
+		program, err := actionProgram(rule.Expression)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "invalid action policy rule", "rule", rule.Name)
+			return fmt.Sprintf("rule %s is invalid: %v", rule.Name, err)
+		}
+		out, _, err := program.Eval(vars)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "unable to evaluate action policy rule", "rule", rule.Name)
+			return fmt.Sprintf("rule %s failed: %v", rule.Name, err)
+		}
+		if allowed, ok := out.Value().(bool); !ok || !allowed {
+			if rule.Message != "" {
+				return fmt.Sprintf("rule %s: %s", rule.Name, rule.Message)
+			}
+			return fmt.Sprintf("rule %s", rule.Name)
+		}
+	}
+	return ""
+}
+
+// actionInput describes a planned action on a target, for the action policy
+// and the Approver.
+func actionInput(profile *optimizerv1.ResourceOptimizerProfile, action string, observedValue float64, now time.Time, kind string, target metav1.Object) map[string]any {
+	return map[string]any{
+		"action": action,
+		"value":  observedValue,
+		"now":    now,
+		"profile": map[string]any{
+			"name":      profile.Name,
+			"namespace": profile.Namespace,
+			"labels":    profile.Labels,
+			"policy":    profile.Spec.OptimizationPolicy,
+		},
+		"target": map[string]any{
+			"kind":        kind,
+			"name":        target.GetName(),
+			"labels":      target.GetLabels(),
+			"annotations": target.GetAnnotations(),
+		},
+	}
+}
+
+// actionAllowed reports whether the profile's action policy and the Approver
+// allow the action on a target. Denied actions are logged and counted as
+// skipped.
+func (r *ResourceOptimizerProfileReconciler) actionAllowed(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, action string, observedValue float64, kind string, target metav1.Object) bool {
+	now := time.Now()
+	reason := actionDenied(ctx, profile, action, observedValue, now, kind, target)
+	if reason == "" && r.Approver != nil {
+		reason = approvalDenied(ctx, r.Approver, actionInput(profile, action, observedValue, now, kind, target))
+	}
+	if reason == "" {
+		return true
+	}
+	log.FromContext(ctx).Info("Action denied by policy", "action", action, "kind", kind, "name", target.GetName(), "reason", reason)
+	r.recordSkippedAction(profile, action, SkipReasonPolicyDenied)
+	return false
+}
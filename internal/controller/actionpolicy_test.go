@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/common/model"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Action policy", func() {
+	// A Friday.
+	friday := time.Date(2025, time.October, 17, 12, 0, 0, 0, time.UTC)
+	payments := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "checkout", Labels: map[string]string{"team": "payments"}}}
+	search := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "search"}}
+
+	var profile *optimizerv1.ResourceOptimizerProfile
+
+	BeforeEach(func() {
+		profile = &optimizerv1.ResourceOptimizerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+			Spec: optimizerv1.ResourceOptimizerProfileSpec{
+				OptimizationPolicy: "Scale",
+				ActionPolicy: []optimizerv1.ActionRule{{
+					Name:       "no-friday-scale-down",
+					Expression: `!(action == "ScaleDown" && target.labels.?team.orValue("") == "payments" && now.getDayOfWeek() == 5)`,
+					Message:    "payments is never scaled down on Fridays",
+				}},
+			},
+		}
+	})
+	denied := func(action string, now time.Time, target *appsv1.Deployment) string {
+		return actionDenied(context.Background(), profile, action, 10, now, "Deployment", target)
+	}
+
+	It("should deny the actions a rule rejects", func() {
+		Expect(denied(ScaleDownAction, friday, payments)).To(Equal("rule no-friday-scale-down: payments is never scaled down on Fridays"))
+		Expect(denied(ScaleUpAction, friday, payments)).To(BeEmpty())
+		Expect(denied(ScaleDownAction, friday, search)).To(BeEmpty())
+		Expect(denied(ScaleDownAction, friday.AddDate(0, 0, 1), payments)).To(BeEmpty())
+	})
+
+	It("should evaluate every rule", func() {
+		profile.Spec.ActionPolicy = append(profile.Spec.ActionPolicy, optimizerv1.ActionRule{
+			Name:       "busy",
+			Expression: `value < 90.0 || action.startsWith("Scale")`,
+		})
+		Expect(denied(ResizeUpAction, friday, search)).To(BeEmpty())
+		Expect(actionDenied(context.Background(), profile, ResizeUpAction, 95, friday, "Deployment", search)).To(Equal("rule busy"))
+	})
+
+	It("should deny actions when a rule fails", func() {
+		profile.Spec.ActionPolicy[0].Expression = `target.labels["team"] == "payments"`
+		Expect(denied(ScaleUpAction, friday, search)).To(ContainSubstring("rule no-friday-scale-down failed"))
+
+		profile.Spec.ActionPolicy[0].Expression = `action`
+		Expect(denied(ScaleUpAction, friday, search)).To(ContainSubstring("must evaluate to a bool"))
+	})
+
+	It("should compile each rule once", func() {
+		first, err := actionProgram(`action == "ScaleUp"`)
+		Expect(err).NotTo(HaveOccurred())
+		second, err := actionProgram(`action == "ScaleUp"`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second).To(BeIdenticalTo(first))
+	})
+
+	It("should not record an action every target was denied", func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(optimizerv1.AddToScheme(scheme)).To(Succeed())
+		web := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", Labels: map[string]string{"app": "web"}},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](2)},
+		}
+		profile.Spec.Selector = metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+		profile.Spec.CPUThresholds = optimizerv1.ThresholdSpec{Min: 30, Max: 70}
+		profile.Spec.ActionPolicy = []optimizerv1.ActionRule{{Name: "freeze", Expression: "false"}}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&optimizerv1.ResourceOptimizerProfile{}).
+			WithObjects(profile, web, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "team-a", Labels: map[string]string{"app": "web"}}}).Build()
+		reconciler := &ResourceOptimizerProfileReconciler{
+			Client:        c,
+			Scheme:        scheme,
+			PrometheusAPI: &mockPrometheusAPI{result: model.Vector{{Value: 90}}},
+		}
+
+		_, err := reconciler.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "team-a", Name: "web"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "web"}, web)).To(Succeed())
+		Expect(web.Spec.Replicas).To(HaveValue(Equal(int32(2))))
+		Expect(c.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "web"}, profile)).To(Succeed())
+		Expect(profile.Status.LastAction).To(BeNil())
+	})
+})
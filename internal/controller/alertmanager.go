@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// AlertHoldRecheck is how often a profile whose actions are held back by
+// firing alerts is evaluated again.
+const AlertHoldRecheck = time.Minute
+
+// AlertSource looks up firing alerts.
+type AlertSource interface {
+	// FiringAlerts returns the names of the active alerts carrying all of the
+	// given labels.
+	FiringAlerts(ctx context.Context, matchLabels map[string]string) ([]string, error)
+}
+
+// AlertmanagerClient reads alerts from the Alertmanager API v2. Silenced and
+// inhibited alerts are not considered firing.
+type AlertmanagerClient struct {
+	// URL is the base URL of Alertmanager, e.g. http://alertmanager:9093.
+	URL string
+	// Client is the HTTP client used to query Alertmanager.
+	Client *http.Client
+}
+
+// NewAlertmanagerClient returns a client for the Alertmanager at rawURL.
+func NewAlertmanagerClient(rawURL string) *AlertmanagerClient {
+	return &AlertmanagerClient{URL: strings.TrimSuffix(rawURL, "/"), Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// FiringAlerts implements AlertSource.
+func (a *AlertmanagerClient) FiringAlerts(ctx context.Context, matchLabels map[string]string) ([]string, error) {
+	query := url.Values{
+		"active":    {"true"},
+		"silenced":  {"false"},
+		"inhibited": {"false"},
+	}
+	for _, name := range slices.Sorted(maps.Keys(matchLabels)) {
+		query.Add("filter", name+"="+strconv.Quote(matchLabels[name]))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.URL+"/api/v2/alerts?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("alertmanager responded with %s", resp.Status)
+	}
+
+	var alerts []struct {
+		Labels map[string]string `json:"labels"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&alerts); err != nil {
+		return nil, fmt.Errorf("decoding alerts: %w", err)
+	}
+	var names []string
+	for _, alert := range alerts {
+		if name := alert.Labels["alertname"]; !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// holdForAlerts reports whether the action must be held back because alerts
+// matching the profile's holdOnAlerts are firing, and records the answer in the
+// ActionsHeld condition. Only scale-down and resize-down actions of the Scale
+// and Resize policies are held. If
+// Alertmanager cannot be queried the action is held as well, since removing
+// capacity during an unknown incident is the riskier choice.
+func (r *ResourceOptimizerProfileReconciler) holdForAlerts(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, action string) bool {
+	if len(profile.Spec.HoldOnAlerts) == 0 || r.Alerts == nil || profile.Spec.OptimizationPolicy == "Recommend" {
+		meta.RemoveStatusCondition(&profile.Status.Conditions, optimizerv1.ConditionActionsHeld)
+		return false
+	}
+
+	condition := metav1.Condition{
+		Type:               optimizerv1.ConditionActionsHeld,
+		Status:             metav1.ConditionFalse,
+		Reason:             "NotHeld",
+		Message:            "No action is held back by alerts matching holdOnAlerts",
+		ObservedGeneration: profile.Generation,
+	}
+	held := false
+	if action == ScaleDownAction || action == ResizeDownAction || action == ScaleToZeroAction {
+		alerts, err := r.Alerts.FiringAlerts(ctx, profile.Spec.HoldOnAlerts)
+		switch {
+		case err != nil:
+			log.FromContext(ctx).Error(err, "unable to query Alertmanager, holding action", "action", action)
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = "AlertmanagerUnavailable"
+			condition.Message = fmt.Sprintf("Holding %s: alerts could not be checked: %v", action, err)
+			held = true
+		case len(alerts) > 0:
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = "AlertsFiring"
+			condition.Message = fmt.Sprintf("Holding %s while alerts are firing: %s", action, strings.Join(alerts, ", "))
+			held = true
+		}
+	}
+	meta.SetStatusCondition(&profile.Status.Conditions, condition)
+	return held
+}
@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// fakeAlerts answers every lookup with the same alerts, or error.
+type fakeAlerts struct {
+	names []string
+	err   error
+}
+
+func (f fakeAlerts) FiringAlerts(context.Context, map[string]string) ([]string, error) {
+	return f.names, f.err
+}
+
+var _ = Describe("Alertmanager holds", func() {
+	It("should query active, unsilenced alerts by label", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/api/v2/alerts"))
+			Expect(r.URL.Query()["filter"]).To(Equal([]string{`severity="critical"`, `team="payments"`}))
+			Expect(r.URL.Query().Get("silenced")).To(Equal("false"))
+			_, _ = w.Write([]byte(`[
+				{"labels": {"alertname": "HighLatency", "team": "payments"}},
+				{"labels": {"alertname": "ErrorBudgetBurn", "team": "payments"}},
+				{"labels": {"alertname": "HighLatency", "team": "payments", "pod": "web-1"}}
+			]`))
+		}))
+		defer server.Close()
+
+		alerts, err := NewAlertmanagerClient(server.URL+"/").FiringAlerts(context.Background(),
+			map[string]string{"team": "payments", "severity": "critical"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(alerts).To(Equal([]string{"ErrorBudgetBurn", "HighLatency"}))
+	})
+
+	It("should report Alertmanager errors", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		_, err := NewAlertmanagerClient(server.URL).FiringAlerts(context.Background(), map[string]string{"team": "payments"})
+		Expect(err).To(MatchError(ContainSubstring("503")))
+	})
+
+	Context("when deciding whether to hold an action", func() {
+		var profile *optimizerv1.ResourceOptimizerProfile
+
+		BeforeEach(func() {
+			profile = &optimizerv1.ResourceOptimizerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+				Spec: optimizerv1.ResourceOptimizerProfileSpec{
+					OptimizationPolicy: "Scale",
+					HoldOnAlerts:       map[string]string{"team": "payments"},
+				},
+			}
+		})
+		held := func(alerts AlertSource, action string) (bool, *metav1.Condition) {
+			r := &ResourceOptimizerProfileReconciler{Alerts: alerts}
+			hold := r.holdForAlerts(context.Background(), profile, action)
+			return hold, meta.FindStatusCondition(profile.Status.Conditions, optimizerv1.ConditionActionsHeld)
+		}
+
+		It("should hold scale-downs while matching alerts fire", func() {
+			hold, condition := held(fakeAlerts{names: []string{"HighLatency"}}, ScaleDownAction)
+			Expect(hold).To(BeTrue())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal("AlertsFiring"))
+			Expect(condition.Message).To(Equal("Holding ScaleDown while alerts are firing: HighLatency"))
+
+			hold, condition = held(fakeAlerts{}, ScaleDownAction)
+			Expect(hold).To(BeFalse())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		})
+
+		It("should not hold scale-ups", func() {
+			hold, condition := held(fakeAlerts{names: []string{"HighLatency"}}, ScaleUpAction)
+			Expect(hold).To(BeFalse())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		})
+
+		It("should hold when Alertmanager cannot be queried", func() {
+			hold, condition := held(fakeAlerts{err: errors.New("connection refused")}, ResizeDownAction)
+			Expect(hold).To(BeTrue())
+			Expect(condition.Reason).To(Equal("AlertmanagerUnavailable"))
+		})
+
+		It("should drop the condition when holds are not configured", func() {
+			held(fakeAlerts{names: []string{"HighLatency"}}, ScaleDownAction)
+			profile.Spec.HoldOnAlerts = nil
+			hold, condition := held(fakeAlerts{names: []string{"HighLatency"}}, ScaleDownAction)
+			Expect(hold).To(BeFalse())
+			Expect(condition).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/common/model"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// alertTriggerPromQL selects the series of the ALERTS metric that Prometheus
+// exports for the firing alerts listed by triggers.
+func alertTriggerPromQL(triggers []optimizerv1.AlertTrigger) string {
+	selectors := make([]string, 0, len(triggers))
+	for _, trigger := range triggers {
+		matchers := []string{`alertstate="firing"`, "alertname=" + strconv.Quote(trigger.AlertName)}
+		for _, name := range slices.Sorted(maps.Keys(trigger.MatchLabels)) {
+			matchers = append(matchers, name+"="+strconv.Quote(trigger.MatchLabels[name]))
+		}
+		selectors = append(selectors, "ALERTS{"+strings.Join(matchers, ", ")+"}")
+	}
+	return strings.Join(selectors, " or ")
+}
+
+// firingAlertTriggers returns the names of the profile's alert triggers that are
+// currently firing.
+func firingAlertTriggers(ctx context.Context, promAPI PrometheusClient, profile *optimizerv1.ResourceOptimizerProfile) ([]string, error) {
+	if len(profile.Spec.AlertTriggers) == 0 {
+		return nil, nil
+	}
+	result, err := executePromQL(ctx, promAPI, alertTriggerPromQL(profile.Spec.AlertTriggers))
+	if err != nil {
+		return nil, err
+	}
+	vector, ok := result.(model.Vector)
+	if !ok {
+		return nil, fmt.Errorf("alert trigger query returned %s, not a vector", result.Type())
+	}
+	var names []string
+	for _, sample := range vector {
+		if name := string(sample.Metric[model.AlertNameLabel]); !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
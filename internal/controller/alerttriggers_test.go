@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/common/model"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Alert triggers", func() {
+	triggers := []optimizerv1.AlertTrigger{
+		{AlertName: "HighLatency", MatchLabels: map[string]string{"team": "payments", "service": "checkout"}},
+		{AlertName: "QueueBacklog"},
+	}
+
+	It("should select the firing ALERTS series of every trigger", func() {
+		Expect(alertTriggerPromQL(triggers)).To(Equal(
+			`ALERTS{alertstate="firing", alertname="HighLatency", service="checkout", team="payments"} or ` +
+				`ALERTS{alertstate="firing", alertname="QueueBacklog"}`))
+	})
+
+	It("should return the names of the firing triggers", func() {
+		profile := &optimizerv1.ResourceOptimizerProfile{Spec: optimizerv1.ResourceOptimizerProfileSpec{AlertTriggers: triggers}}
+		promAPI := &mockPrometheusAPI{result: model.Vector{
+			{Metric: model.Metric{model.AlertNameLabel: "QueueBacklog", "queue": "orders"}, Value: 1},
+			{Metric: model.Metric{model.AlertNameLabel: "HighLatency", "pod": "web-0"}, Value: 1},
+			{Metric: model.Metric{model.AlertNameLabel: "HighLatency", "pod": "web-1"}, Value: 1},
+		}}
+		alerts, err := firingAlertTriggers(context.Background(), promAPI, profile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(alerts).To(Equal([]string{"HighLatency", "QueueBacklog"}))
+
+		alerts, err = firingAlertTriggers(context.Background(), promAPI, &optimizerv1.ResourceOptimizerProfile{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(alerts).To(BeEmpty())
+	})
+})
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	crcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/version"
+)
+
+// Values of spec.actionMode.
+const (
+	// ActionModePatch applies actions to the target workloads.
+	ActionModePatch = "Patch"
+	// ActionModeAnnotate records actions as annotations on the target workloads.
+	ActionModeAnnotate = "Annotate"
+	// ActionModeAdmission records resizes as annotations that the pod webhook
+	// applies to new pods.
+	ActionModeAdmission = "Admission"
+)
+
+// annotates reports whether the profile records its actions as annotations
+// instead of applying them. Admission profiles do so only for resizes.
+func annotates(profile *optimizerv1.ResourceOptimizerProfile) bool {
+	switch profile.Spec.ActionMode {
+	case ActionModeAnnotate:
+		return true
+	case ActionModeAdmission:
+		return profile.Spec.OptimizationPolicy == "Resize"
+	}
+	return false
+}
+
+// ParseRecommendedCPURequest splits the value of the recommended CPU request
+// annotation into the container name and the request.
+func ParseRecommendedCPURequest(value string) (string, resource.Quantity, error) {
+	container, request, ok := strings.Cut(value, "=")
+	if !ok || container == "" {
+		return "", resource.Quantity{}, fmt.Errorf("%q is not container=quantity", value)
+	}
+	quantity, err := resource.ParseQuantity(request)
+	if err != nil {
+		return "", resource.Quantity{}, fmt.Errorf("%q is not container=quantity: %w", value, err)
+	}
+	return container, quantity, nil
+}
+
+// currentCPURequest is the CPU request a workload's container runs with: the
+// one the webhook injects into its pods for Admission profiles, or else the
+// one in its pod template.
+func currentCPURequest(profile *optimizerv1.ResourceOptimizerProfile, annotations map[string]string, container corev1.Container) resource.Quantity {
+	if profile.Spec.ActionMode == ActionModeAdmission {
+		name, request, err := ParseRecommendedCPURequest(annotations[optimizerv1.RecommendedCPURequestAnnotation])
+		if err == nil && name == container.Name {
+			return request
+		}
+	}
+	return *container.Resources.Requests.Cpu()
+}
+
+// annotateRecommendation writes a recommended value into the annotation key of
+// a workload, along with the profile and time it was made at. It returns the
+// audited field and the value the annotation had before.
+func annotateRecommendation(obj *metav1.ObjectMeta, profile *optimizerv1.ResourceOptimizerProfile, key, value string, now time.Time) (string, string) {
+	if obj.Annotations == nil {
+		obj.Annotations = map[string]string{}
+	}
+	before := obj.Annotations[key]
+	obj.Annotations[key] = value
+	obj.Annotations[optimizerv1.RecommendedByAnnotation] = profile.Name
+	obj.Annotations[optimizerv1.RecommendedAtAnnotation] = now.UTC().Format(time.RFC3339)
+	return fmt.Sprintf("metadata.annotations[%s]", key), before
+}
+
+// markManaged labels a workload the profile changes as managed by the
+// controller, and records the profile, its policy and the time of the change.
+func markManaged(obj *metav1.ObjectMeta, profile *optimizerv1.ResourceOptimizerProfile, now time.Time) {
+	if obj.Labels == nil {
+		obj.Labels = map[string]string{}
+	}
+	obj.Labels[optimizerv1.ManagedLabel] = "true"
+	if obj.Annotations == nil {
+		obj.Annotations = map[string]string{}
+	}
+	obj.Annotations[optimizerv1.ManagedByProfileAnnotation] = profile.Name
+	obj.Annotations[optimizerv1.LastActionAtAnnotation] = now.UTC().Format(time.RFC3339)
+	obj.Annotations[optimizerv1.LastActionPolicyAnnotation] = profile.Spec.OptimizationPolicy
+}
+
+// stampDecision records on a workload about to be patched which decision,
+// generation of the profile, controller version and reconcile made the change.
+// The reconcile ID is left out outside of a reconcile, e.g. in tests.
+func stampDecision(ctx context.Context, obj *metav1.ObjectMeta, profile *optimizerv1.ResourceOptimizerProfile, key string) {
+	if obj.Annotations == nil {
+		obj.Annotations = map[string]string{}
+	}
+	obj.Annotations[optimizerv1.DecisionKeyAnnotation] = key
+	obj.Annotations[optimizerv1.ProfileGenerationAnnotation] = strconv.FormatInt(profile.Generation, 10)
+	obj.Annotations[optimizerv1.ControllerVersionAnnotation] = version.Get().Version
+	if id := crcontroller.ReconcileIDFromContext(ctx); id != "" {
+		obj.Annotations[optimizerv1.ReconcileIDAnnotation] = string(id)
+	} else {
+		delete(obj.Annotations, optimizerv1.ReconcileIDAnnotation)
+	}
+}
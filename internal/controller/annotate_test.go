@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/version"
+)
+
+var _ = Describe("Annotate action mode", func() {
+	labels := map[string]string{"app": "web"}
+	key := types.NamespacedName{Namespace: "team-a", Name: "web"}
+
+	var (
+		reconciler *ResourceOptimizerProfileReconciler
+		profile    *optimizerv1.ResourceOptimizerProfile
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		reconciler = &ResourceOptimizerProfileReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace, Labels: labels},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: ptr.To[int32](2),
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Name:      "app",
+					Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}},
+				}}}},
+			},
+		}).Build()}
+		profile = &optimizerv1.ResourceOptimizerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-profile", Namespace: key.Namespace},
+			Spec: optimizerv1.ResourceOptimizerProfileSpec{
+				Selector:      metav1.LabelSelector{MatchLabels: labels},
+				CPUThresholds: optimizerv1.ThresholdSpec{Min: 20, Max: 80},
+				ActionMode:    ActionModeAnnotate,
+			},
+		}
+	})
+
+	get := func() *appsv1.Deployment {
+		var deployment appsv1.Deployment
+		Expect(reconciler.Get(context.Background(), key, &deployment)).To(Succeed())
+		return &deployment
+	}
+
+	It("should record recommended replicas without scaling", func() {
+		Expect(reconciler.executeScaleAction(context.Background(), profile, ScaleUpAction, 95)).Error().NotTo(HaveOccurred())
+		deployment := get()
+		Expect(*deployment.Spec.Replicas).To(Equal(int32(2)))
+		Expect(deployment.Annotations).To(HaveKeyWithValue(optimizerv1.RecommendedReplicasAnnotation, "3"))
+		Expect(deployment.Annotations).To(HaveKeyWithValue(optimizerv1.RecommendedByAnnotation, "web-profile"))
+		Expect(deployment.Annotations).To(HaveKey(optimizerv1.RecommendedAtAnnotation))
+	})
+
+	It("should count annotations apart from the applied actions", func() {
+		scaleUps := scaleUpActions.WithLabelValues(key.Namespace, "web-profile", "Deployment")
+		annotations := annotatedRecommendations.WithLabelValues(key.Namespace, "web-profile", ScaleUpAction, "Deployment")
+		scaledBefore, annotatedBefore := testutil.ToFloat64(scaleUps), testutil.ToFloat64(annotations)
+
+		Expect(reconciler.executeScaleAction(context.Background(), profile, ScaleUpAction, 95)).Error().NotTo(HaveOccurred())
+		Expect(testutil.ToFloat64(scaleUps)).To(Equal(scaledBefore))
+		Expect(testutil.ToFloat64(annotations)).To(Equal(annotatedBefore + 1))
+	})
+
+	It("should record recommended CPU requests without resizing", func() {
+		Expect(reconciler.executeResizeAction(context.Background(), profile, ResizeUpAction, 100)).Error().NotTo(HaveOccurred())
+		deployment := get()
+		Expect(deployment.Spec.Template.Spec.Containers[0].Resources.Requests.Cpu().String()).To(Equal("100m"))
+		// 100% observed against a 50% target, plus the 25% buffer.
+		Expect(deployment.Annotations).To(HaveKeyWithValue(optimizerv1.RecommendedCPURequestAnnotation, "app=250m"))
+	})
+	It("should recommend from the injected request in the Admission mode", func() {
+		profile.Spec.ActionMode = ActionModeAdmission
+		profile.Spec.OptimizationPolicy = "Resize"
+		Expect(reconciler.executeResizeAction(context.Background(), profile, ResizeUpAction, 100)).Error().NotTo(HaveOccurred())
+		Expect(get().Annotations).To(HaveKeyWithValue(optimizerv1.RecommendedCPURequestAnnotation, "app=250m"))
+
+		// The pods now run with 250m, so the next resize starts from there.
+		profile.Status.LastAction = &optimizerv1.ActionDetail{Type: ResizeUpAction, Timestamp: metav1.Now()}
+		Expect(reconciler.executeResizeAction(context.Background(), profile, ResizeUpAction, 100)).Error().NotTo(HaveOccurred())
+		deployment := get()
+		Expect(deployment.Spec.Template.Spec.Containers[0].Resources.Requests.Cpu().String()).To(Equal("100m"))
+		Expect(deployment.Annotations).To(HaveKeyWithValue(optimizerv1.RecommendedCPURequestAnnotation, "app=625m"))
+	})
+
+	It("should mark the workloads it changes in the Patch mode", func() {
+		Expect(reconciler.executeScaleAction(context.Background(), profile, ScaleUpAction, 95)).Error().NotTo(HaveOccurred())
+		Expect(get().Labels).NotTo(HaveKey(optimizerv1.ManagedLabel))
+
+		profile.Spec.ActionMode = ActionModePatch
+		profile.Generation++
+		results, err := reconciler.executeScaleAction(context.Background(), profile, ScaleUpAction, 95)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(Equal([]optimizerv1.TargetResult{{Kind: "Deployment", Name: "web", Field: "spec.replicas", From: "2", To: "3",
+			Result: optimizerv1.TargetResultSucceeded}}))
+		deployment := get()
+		Expect(*deployment.Spec.Replicas).To(Equal(int32(3)))
+		Expect(deployment.Labels).To(HaveKeyWithValue(optimizerv1.ManagedLabel, "true"))
+		Expect(deployment.Annotations).To(HaveKeyWithValue(optimizerv1.ManagedByProfileAnnotation, "web-profile"))
+		Expect(deployment.Annotations).To(HaveKey(optimizerv1.LastActionAtAnnotation))
+	})
+
+	It("should stamp the decision on every patched workload", func() {
+		profile.Generation = 4
+		Expect(reconciler.executeScaleAction(context.Background(), profile, ScaleUpAction, 95)).Error().NotTo(HaveOccurred())
+		Expect(get().Annotations).To(HaveKeyWithValue(optimizerv1.ProfileGenerationAnnotation, "4"))
+		Expect(get().Annotations).To(HaveKeyWithValue(optimizerv1.ControllerVersionAnnotation, version.Get().Version))
+		Expect(get().Annotations).NotTo(HaveKey(optimizerv1.ReconcileIDAnnotation))
+	})
+
+	It("should keep patching the other targets when one fails", func() {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		reconciler.Client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: key.Namespace, Labels: labels},
+				Spec: appsv1.DeploymentSpec{Replicas: ptr.To[int32](2)}},
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace, Labels: labels},
+				Spec: appsv1.DeploymentSpec{Replicas: ptr.To[int32](2)}},
+		).WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				if obj.GetName() == "api" {
+					return errors.New("admission webhook denied the request")
+				}
+				return c.Patch(ctx, obj, patch, opts...)
+			},
+		}).Build()
+
+		profile.Spec.ActionMode = ActionModePatch
+		profile.Generation++
+		results, err := reconciler.executeScaleAction(context.Background(), profile, ScaleUpAction, 95)
+		Expect(err).To(MatchError(ContainSubstring("patching Deployment api")))
+		Expect(results).To(HaveLen(2))
+		Expect(results[0].Result).To(Equal(optimizerv1.TargetResultFailed))
+		Expect(results[0].Message).To(Equal("admission webhook denied the request"))
+		Expect(results[1].Result).To(Equal(optimizerv1.TargetResultSucceeded))
+		Expect(*get().Spec.Replicas).To(Equal(int32(3)))
+	})
+
+	It("should parse recommended CPU requests", func() {
+		container, request, err := ParseRecommendedCPURequest("app=250m")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(container).To(Equal("app"))
+		Expect(request.String()).To(Equal("250m"))
+
+		_, _, err = ParseRecommendedCPURequest("250m")
+		Expect(err).To(HaveOccurred())
+	})
+})
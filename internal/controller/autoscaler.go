@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get
+
+// DefaultClusterAutoscalerStatus is where the Cluster Autoscaler publishes its
+// status unless configured otherwise.
+var DefaultClusterAutoscalerStatus = types.NamespacedName{Namespace: "kube-system", Name: "cluster-autoscaler-status"}
+
+// ClusterAutoscalerDeferral is how long a scale-up is held back while the
+// cluster is waiting for capacity.
+const ClusterAutoscalerDeferral = time.Minute
+
+// legacyScaleUpInProgress matches the cluster-wide scale-up line of the text
+// status written by Cluster Autoscaler before 1.30.
+var legacyScaleUpInProgress = regexp.MustCompile(`(?m)^\s*ScaleUp:\s+InProgress\b`)
+
+// ClusterAutoscalerGate holds back scale-ups while the profile's pods are
+// waiting for capacity or the Cluster Autoscaler is adding nodes. Replicas added
+// then would only join the queue of Pending pods and make the autoscaler add
+// even more nodes.
+type ClusterAutoscalerGate struct {
+	// Reader reads the Cluster Autoscaler status ConfigMap. Use an uncached
+	// reader so the controller doesn't watch every ConfigMap in the cluster.
+	Reader client.Reader
+	// Status locates the Cluster Autoscaler status ConfigMap.
+	Status types.NamespacedName
+}
+
+// Check returns why a scale-up of the profile's workloads should wait, or an
+// empty string when it may go ahead. Pods are listed with c. A missing status
+// ConfigMap means the Cluster Autoscaler is not installed.
+func (g *ClusterAutoscalerGate) Check(ctx context.Context, c client.Reader, profile *optimizerv1.ResourceOptimizerProfile) (string, error) {
+	var pods corev1.PodList
+	selector := labels.Set(profile.Spec.Selector.MatchLabels).AsSelector()
+	if err := c.List(ctx, &pods, &client.ListOptions{LabelSelector: selector, Namespace: profile.Namespace}); err != nil {
+		return "", err
+	}
+	pending := 0
+	for i := range pods.Items {
+		if unschedulable(&pods.Items[i]) {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Sprintf("%d pod(s) of the profile are pending for lack of capacity", pending), nil
+	}
+
+	var status corev1.ConfigMap
+	if err := g.Reader.Get(ctx, g.Status, &status); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("reading Cluster Autoscaler status %s: %w", g.Status, err)
+	}
+	if scaleUpInProgress(status.Data["status"]) {
+		return "the Cluster Autoscaler is scaling up the cluster", nil
+	}
+	return "", nil
+}
+
+// unschedulable reports whether the scheduler found no node for a pod.
+func unschedulable(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodPending {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse &&
+			condition.Reason == corev1.PodReasonUnschedulable {
+			return true
+		}
+	}
+	return false
+}
+
+// scaleUpInProgress parses the status written by the Cluster Autoscaler, in
+// either the YAML format of 1.30 and later or the older text format.
+func scaleUpInProgress(status string) bool {
+	var parsed struct {
+		ClusterWide struct {
+			ScaleUp struct {
+				Status string `json:"status"`
+			} `json:"scaleUp"`
+		} `json:"clusterWide"`
+	}
+	if err := yaml.Unmarshal([]byte(status), &parsed); err == nil && parsed.ClusterWide.ScaleUp.Status != "" {
+		return parsed.ClusterWide.ScaleUp.Status == "InProgress"
+	}
+	return legacyScaleUpInProgress.MatchString(status)
+}
@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("ClusterAutoscalerGate", func() {
+	labels := map[string]string{"app": "web"}
+	profile := &optimizerv1.ResourceOptimizerProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+		Spec:       optimizerv1.ResourceOptimizerProfileSpec{Selector: metav1.LabelSelector{MatchLabels: labels}},
+	}
+
+	newClient := func(objects ...client.Object) client.Client {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	}
+	pod := func(name string, phase corev1.PodPhase, conditions ...corev1.PodCondition) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-a", Labels: labels},
+			Status:     corev1.PodStatus{Phase: phase, Conditions: conditions},
+		}
+	}
+	status := func(data string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: DefaultClusterAutoscalerStatus.Name, Namespace: DefaultClusterAutoscalerStatus.Namespace},
+			Data:       map[string]string{"status": data},
+		}
+	}
+	check := func(c client.Client) string {
+		gate := &ClusterAutoscalerGate{Reader: c, Status: DefaultClusterAutoscalerStatus}
+		reason, err := gate.Check(context.Background(), c, profile)
+		Expect(err).NotTo(HaveOccurred())
+		return reason
+	}
+
+	It("should allow scale-ups without the Cluster Autoscaler", func() {
+		Expect(check(newClient(pod("web-0", corev1.PodRunning)))).To(BeEmpty())
+	})
+
+	It("should defer scale-ups while the profile's pods are unschedulable", func() {
+		c := newClient(
+			pod("web-0", corev1.PodRunning),
+			pod("web-1", corev1.PodPending, corev1.PodCondition{
+				Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable,
+			}),
+			// Scheduled pods still pulling their image are not waiting for capacity.
+			pod("web-2", corev1.PodPending, corev1.PodCondition{Type: corev1.PodScheduled, Status: corev1.ConditionTrue}),
+		)
+		Expect(check(c)).To(Equal("1 pod(s) of the profile are pending for lack of capacity"))
+	})
+
+	It("should defer scale-ups while the Cluster Autoscaler is scaling up", func() {
+		Expect(check(newClient(status(`
+time: 2025-01-01 00:00:00 +0000 UTC
+clusterWide:
+  health:
+    status: Healthy
+  scaleUp:
+    status: InProgress
+`)))).To(Equal("the Cluster Autoscaler is scaling up the cluster"))
+		Expect(check(newClient(status(`
+clusterWide:
+  scaleUp:
+    status: NoActivity
+`)))).To(BeEmpty())
+	})
+
+	It("should read the status format of older Cluster Autoscalers", func() {
+		Expect(check(newClient(status(`Cluster-autoscaler status at 2025-01-01 00:00:00 +0000 UTC:
+Cluster-wide:
+  Health:      Healthy (ready=3 unready=0 notStarted=0 longNotStarted=0 registered=3 longUnregistered=0)
+  ScaleUp:     InProgress (ready=3 registered=4)
+  ScaleDown:   NoCandidates (candidates=0)
+`)))).To(Equal("the Cluster Autoscaler is scaling up the cluster"))
+		Expect(check(newClient(status(`Cluster-autoscaler status at 2025-01-01 00:00:00 +0000 UTC:
+Cluster-wide:
+  ScaleUp:     NoActivity (ready=3 registered=3)
+`)))).To(BeEmpty())
+	})
+})
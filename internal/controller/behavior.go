@@ -0,0 +1,279 @@
+package controller
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// Stabilization windows used by profiles with a behavior that does not set
+// them, as in the HorizontalPodAutoscaler.
+const (
+	DefaultScaleUpStabilizationWindow   = 0
+	DefaultScaleDownStabilizationWindow = 5 * time.Minute
+)
+
+// maxScalingPolicyPeriod is the longest periodSeconds a scaling policy may
+// have.
+const maxScalingPolicyPeriod = 30 * time.Minute
+
+// workloadKey identifies a target across namespaces.
+type workloadKey struct {
+	namespace string
+	targetRef
+}
+
+// scaleEvent is a replica change applied to a target.
+type scaleEvent struct {
+	time   time.Time
+	change int32
+}
+
+// pendingScale is the scale action a profile has called for since a time.
+type pendingScale struct {
+	action string
+	since  time.Time
+}
+
+// scaleHistory remembers what the behavior of Scale profiles is evaluated
+// against: the replica changes applied to each target and the action each
+// profile has been calling for. It is kept in memory only, so a restarted
+// controller starts stabilization windows and rate limit periods afresh. The
+// zero value is ready to use.
+type scaleHistory struct {
+	mu      sync.Mutex
+	events  map[workloadKey][]scaleEvent
+	pending map[types.NamespacedName]pendingScale
+}
+
+// scalingRules returns the behavior rules of the profile for an action, or nil.
+func scalingRules(profile *optimizerv1.ResourceOptimizerProfile, action string) *autoscalingv2.HPAScalingRules {
+	behavior := profile.Spec.Behavior
+	switch {
+	case behavior == nil:
+		return nil
+	case action == ScaleUpAction:
+		return behavior.ScaleUp
+	case action == ScaleDownAction:
+		return behavior.ScaleDown
+	}
+	return nil
+}
+
+// stabilizationWindow returns how long the profile must keep calling for an
+// action before it is taken.
+func stabilizationWindow(profile *optimizerv1.ResourceOptimizerProfile, action string) time.Duration {
+	if profile.Spec.Behavior == nil {
+		return 0
+	}
+	if rules := scalingRules(profile, action); rules != nil && rules.StabilizationWindowSeconds != nil {
+		return time.Duration(*rules.StabilizationWindowSeconds) * time.Second
+	}
+	if action == ScaleDownAction {
+		return DefaultScaleDownStabilizationWindow
+	}
+	return DefaultScaleUpStabilizationWindow
+}
+
+// stabilizing records the action a profile calls for at now and returns how
+// long it must keep calling for it before the stabilization window of its
+// behavior has passed. It is zero when the action may be taken.
+func (h *scaleHistory) stabilizing(profile *optimizerv1.ResourceOptimizerProfile, action string, now time.Time) time.Duration {
+	key := types.NamespacedName{Namespace: profile.Namespace, Name: profile.Name}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.pending == nil {
+		h.pending = map[types.NamespacedName]pendingScale{}
+	}
+	pending, ok := h.pending[key]
+	if !ok || pending.action != action {
+		pending = pendingScale{action: action, since: now}
+		h.pending[key] = pending
+	}
+	if action == DoNothing {
+		return 0
+	}
+	return max(stabilizationWindow(profile, action)-now.Sub(pending.since), 0)
+}
+
+// record remembers a replica change applied to a target. Changes older than
+// the longest period a policy may have are dropped.
+func (h *scaleHistory) record(target workloadKey, change int32, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.events == nil {
+		h.events = map[workloadKey][]scaleEvent{}
+	}
+	events := slices.DeleteFunc(h.events[target], func(event scaleEvent) bool {
+		return now.Sub(event.time) > maxScalingPolicyPeriod
+	})
+	h.events[target] = append(events, scaleEvent{time: now, change: change})
+}
+
+// prune drops the replica changes of the targets in a namespace that keep
+// does not contain.
+func (h *scaleHistory) prune(namespace string, keep map[targetRef]bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for key := range h.events {
+		if key.namespace == namespace && !keep[key.targetRef] {
+			delete(h.events, key)
+		}
+	}
+}
+
+// forget drops the action a deleted profile has been calling for.
+func (h *scaleHistory) forget(profile types.NamespacedName) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.pending, profile)
+}
+
+// changesSince returns the replicas added and removed on a target since a time.
+func (h *scaleHistory) changesSince(target workloadKey, since time.Time) (added, removed int32) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, event := range h.events[target] {
+		if !event.time.After(since) {
+			continue
+		}
+		if event.change > 0 {
+			added += event.change
+		} else {
+			removed -= event.change
+		}
+	}
+	return added, removed
+}
+
+// limitReplicas bounds the replicas desired for a target by the policies of the
+// profile's behavior for the action, following the HorizontalPodAutoscaler:
+// each policy allows changing the replicas the target had at the start of its
+// period by a number of pods or a percentage, and selectPolicy picks the policy
+// allowing the largest (Max, the default) or smallest (Min) change, or forbids
+// changes (Disabled).
+func (h *scaleHistory) limitReplicas(profile *optimizerv1.ResourceOptimizerProfile, action string, target workloadKey, current, desired int32, now time.Time) int32 {
+	rules := scalingRules(profile, action)
+	if rules == nil {
+		return desired
+	}
+	selectPolicy := autoscalingv2.MaxChangePolicySelect
+	if rules.SelectPolicy != nil {
+		selectPolicy = *rules.SelectPolicy
+	}
+	if selectPolicy == autoscalingv2.DisabledPolicySelect {
+		return current
+	}
+	if len(rules.Policies) == 0 {
+		return desired
+	}
+
+	up := action == ScaleUpAction
+	// The largest change is the highest limit for scale-ups and the lowest for
+	// scale-downs.
+	highest := (selectPolicy == autoscalingv2.MaxChangePolicySelect) == up
+	var limit int32
+	for i, policy := range rules.Policies {
+		added, removed := h.changesSince(target, now.Add(-time.Duration(policy.PeriodSeconds)*time.Second))
+		periodStart := current - added + removed
+		var proposed int32
+		switch {
+		case up && policy.Type == autoscalingv2.PodsScalingPolicy:
+			proposed = periodStart + policy.Value
+		case up:
+			proposed = int32(math.Ceil(float64(periodStart) * (1 + float64(policy.Value)/100)))
+		case policy.Type == autoscalingv2.PodsScalingPolicy:
+			proposed = periodStart - policy.Value
+		default:
+			proposed = int32(float64(periodStart) * (1 - float64(policy.Value)/100))
+		}
+		if i == 0 || (highest && proposed > limit) || (!highest && proposed < limit) {
+			limit = proposed
+		}
+	}
+	if up {
+		return max(min(desired, limit), current)
+	}
+	return min(max(desired, limit), current)
+}
+
+// behaviorReplicas applies the profile's behavior policies to the replicas
+// desired for a target. It returns false, after counting the action as
+// skipped, when the policies allow no change at all.
+func (r *ResourceOptimizerProfileReconciler) behaviorReplicas(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, action string, target targetRef, current, desired int32) (int32, bool) {
+	limited := r.scaleHistory.limitReplicas(profile, action, workloadKey{profile.Namespace, target}, current, desired, time.Now())
+	if limited == desired {
+		return desired, true
+	}
+	log.FromContext(ctx).Info("Scaling limited by the behavior policies", "kind", target.Kind, "name", target.Name,
+		"replicas", current, "desired", desired, "allowed", limited)
+	if limited == current {
+		r.recordSkippedAction(profile, action, SkipReasonRateLimited)
+		return current, false
+	}
+	return limited, true
+}
+
+// cappedReplicas limits the replica change from current to desired to the
+// profile's maxReplicaChange.
+func cappedReplicas(profile *optimizerv1.ResourceOptimizerProfile, current, desired int32) int32 {
+	if profile.Spec.MaxReplicaChange == nil {
+		return desired
+	}
+	limit := *profile.Spec.MaxReplicaChange
+	return min(max(desired, current-limit), current+limit)
+}
+
+// recordScale remembers a replica change applied to a target for the
+// behavior policies.
+func (r *ResourceOptimizerProfileReconciler) recordScale(profile *optimizerv1.ResourceOptimizerProfile, target targetRef, current, replicas int32) {
+	if profile.Spec.Behavior != nil && replicas != current {
+		r.scaleHistory.record(workloadKey{profile.Namespace, target}, replicas-current, time.Now())
+	}
+}
+
+// steppedReplicas returns the replicas a target with current replicas is scaled
+// to by the profile's scaleStep for a ScaleUp or ScaleDown. Percentages are
+// taken of the current replicas and rounded up, every step changes at least one
+// replica, and targets are never scaled below the profile's minReplicas but by
+// a ScaleToZero.
+func steppedReplicas(profile *optimizerv1.ResourceOptimizerProfile, action string, current int32) int32 {
+	if action == ScaleToZeroAction {
+		return 0
+	}
+	var step *intstr.IntOrString
+	if profile.Spec.ScaleStep != nil {
+		step = profile.Spec.ScaleStep.Down
+		if action == ScaleUpAction {
+			step = profile.Spec.ScaleStep.Up
+		}
+	}
+	change := 1
+	if step != nil {
+		// The webhook rejects steps that are neither integers nor percentages.
+		if scaled, err := intstr.GetScaledValueFromIntOrPercent(step, int(current), true); err == nil {
+			change = max(scaled, 1)
+		}
+	}
+	if action == ScaleUpAction {
+		return current + int32(change)
+	}
+	return max(current-int32(change), min(minReplicas(profile), current))
+}
+
+// minReplicas returns the fewest replicas a ScaleDown leaves a target with.
+func minReplicas(profile *optimizerv1.ResourceOptimizerProfile) int32 {
+	if profile.Spec.MinReplicas != nil {
+		return *profile.Spec.MinReplicas
+	}
+	return 1
+}
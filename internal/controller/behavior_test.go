@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Scaling behavior", func() {
+	now := time.Date(2025, time.October, 17, 12, 0, 0, 0, time.UTC)
+	web := workloadKey{"team-a", targetRef{Kind: "Deployment", Name: "web"}}
+
+	var (
+		profile *optimizerv1.ResourceOptimizerProfile
+		history *scaleHistory
+	)
+
+	BeforeEach(func() {
+		profile = &optimizerv1.ResourceOptimizerProfile{Spec: optimizerv1.ResourceOptimizerProfileSpec{
+			OptimizationPolicy: "Scale",
+			Behavior:           &autoscalingv2.HorizontalPodAutoscalerBehavior{},
+		}}
+		history = &scaleHistory{}
+	})
+
+	It("should wait for the stabilization window", func() {
+		Expect(history.stabilizing(profile, ScaleUpAction, now)).To(BeZero())
+
+		Expect(history.stabilizing(profile, ScaleDownAction, now)).To(Equal(5 * time.Minute))
+		Expect(history.stabilizing(profile, ScaleDownAction, now.Add(4*time.Minute))).To(Equal(time.Minute))
+		Expect(history.stabilizing(profile, ScaleDownAction, now.Add(5*time.Minute))).To(BeZero())
+
+		// Any other recommendation restarts the window.
+		Expect(history.stabilizing(profile, DoNothing, now.Add(6*time.Minute))).To(BeZero())
+		Expect(history.stabilizing(profile, ScaleDownAction, now.Add(7*time.Minute))).To(Equal(5 * time.Minute))
+
+		profile.Spec.Behavior = nil
+		Expect(history.stabilizing(profile, ScaleDownAction, now.Add(8*time.Minute))).To(BeZero())
+	})
+
+	It("should limit scale-ups by the policy allowing the largest change", func() {
+		profile.Spec.Behavior.ScaleUp = &autoscalingv2.HPAScalingRules{Policies: []autoscalingv2.HPAScalingPolicy{
+			{Type: autoscalingv2.PodsScalingPolicy, Value: 2, PeriodSeconds: 60},
+			{Type: autoscalingv2.PercentScalingPolicy, Value: 50, PeriodSeconds: 60},
+		}}
+		Expect(history.limitReplicas(profile, ScaleUpAction, web, 10, 20, now)).To(Equal(int32(15)))
+		Expect(history.limitReplicas(profile, ScaleUpAction, web, 2, 20, now)).To(Equal(int32(4)))
+
+		profile.Spec.Behavior.ScaleUp.SelectPolicy = ptr.To(autoscalingv2.MinChangePolicySelect)
+		Expect(history.limitReplicas(profile, ScaleUpAction, web, 10, 20, now)).To(Equal(int32(12)))
+	})
+
+	It("should count the changes made within each policy's period", func() {
+		profile.Spec.Behavior.ScaleDown = &autoscalingv2.HPAScalingRules{Policies: []autoscalingv2.HPAScalingPolicy{
+			{Type: autoscalingv2.PodsScalingPolicy, Value: 1, PeriodSeconds: 300},
+		}}
+		Expect(history.limitReplicas(profile, ScaleDownAction, web, 5, 4, now)).To(Equal(int32(4)))
+		history.record(web, -1, now)
+		Expect(history.limitReplicas(profile, ScaleDownAction, web, 4, 3, now.Add(time.Minute))).To(Equal(int32(4)))
+		Expect(history.limitReplicas(profile, ScaleDownAction, web, 4, 3, now.Add(5*time.Minute))).To(Equal(int32(3)))
+	})
+
+	It("should not scale when the direction is disabled", func() {
+		profile.Spec.Behavior.ScaleDown = &autoscalingv2.HPAScalingRules{SelectPolicy: ptr.To(autoscalingv2.DisabledPolicySelect)}
+		Expect(history.limitReplicas(profile, ScaleDownAction, web, 5, 4, now)).To(Equal(int32(5)))
+		Expect(history.limitReplicas(profile, ScaleUpAction, web, 5, 6, now)).To(Equal(int32(6)))
+	})
+
+	It("should step replicas by a number or a percentage", func() {
+		Expect(steppedReplicas(profile, ScaleUpAction, 4)).To(Equal(int32(5)))
+		Expect(steppedReplicas(profile, ScaleDownAction, 1)).To(Equal(int32(1)))
+
+		up, down := intstr.FromString("50%"), intstr.FromString("25%")
+		profile.Spec.ScaleStep = &optimizerv1.ScaleStep{Up: &up, Down: &down}
+		Expect(steppedReplicas(profile, ScaleUpAction, 20)).To(Equal(int32(30)))
+		Expect(steppedReplicas(profile, ScaleUpAction, 3)).To(Equal(int32(5)))
+		Expect(steppedReplicas(profile, ScaleDownAction, 20)).To(Equal(int32(15)))
+		// Percentages are rounded up to at least one replica.
+		Expect(steppedReplicas(profile, ScaleDownAction, 2)).To(Equal(int32(1)))
+
+		down = intstr.FromInt32(5)
+		Expect(steppedReplicas(profile, ScaleDownAction, 4)).To(Equal(int32(1)))
+
+		// Targets are not scaled below minReplicas, nor down while below it.
+		profile.Spec.MinReplicas = ptr.To[int32](2)
+		Expect(steppedReplicas(profile, ScaleDownAction, 4)).To(Equal(int32(2)))
+		Expect(steppedReplicas(profile, ScaleDownAction, 1)).To(Equal(int32(1)))
+		Expect(steppedReplicas(profile, ScaleToZeroAction, 4)).To(Equal(int32(0)))
+	})
+
+	It("should cap the replica change of an action", func() {
+		Expect(cappedReplicas(profile, 20, 30)).To(Equal(int32(30)))
+
+		profile.Spec.MaxReplicaChange = ptr.To[int32](3)
+		Expect(cappedReplicas(profile, 20, 30)).To(Equal(int32(23)))
+		Expect(cappedReplicas(profile, 20, 15)).To(Equal(int32(17)))
+		Expect(cappedReplicas(profile, 20, 21)).To(Equal(int32(21)))
+		// Scaling to zero takes several actions.
+		Expect(cappedReplicas(profile, 5, steppedReplicas(profile, ScaleToZeroAction, 5))).To(Equal(int32(2)))
+	})
+})
@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// Defaults of business hours that leave fields unset.
+const (
+	DefaultBusinessHoursStart = "09:00"
+	DefaultBusinessHoursEnd   = "18:00"
+)
+
+// DefaultBusinessDays are the days business hours open on when none are set.
+var DefaultBusinessDays = []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"}
+
+// businessWindow is a parsed BusinessHours.
+type businessWindow struct {
+	days       []time.Weekday
+	start, end time.Duration
+	location   *time.Location
+}
+
+// parseBusinessHours parses and defaults business hours.
+func parseBusinessHours(hours *optimizerv1.BusinessHours) (*businessWindow, error) {
+	w := &businessWindow{location: time.UTC}
+	days := hours.Days
+	if len(days) == 0 {
+		days = DefaultBusinessDays
+	}
+	for _, day := range days {
+		weekday, ok := parseWeekday(day)
+		if !ok {
+			return nil, fmt.Errorf("unknown day %q", day)
+		}
+		w.days = append(w.days, weekday)
+	}
+	var err error
+	if w.start, err = parseClock(hours.Start, DefaultBusinessHoursStart); err != nil {
+		return nil, fmt.Errorf("invalid start: %w", err)
+	}
+	if w.end, err = parseClock(hours.End, DefaultBusinessHoursEnd); err != nil {
+		return nil, fmt.Errorf("invalid end: %w", err)
+	}
+	if w.start == w.end {
+		return nil, fmt.Errorf("start and end are both %s", hours.Start)
+	}
+	if hours.TimeZone != "" {
+		if w.location, err = time.LoadLocation(hours.TimeZone); err != nil {
+			return nil, fmt.Errorf("invalid time zone: %w", err)
+		}
+	}
+	return w, nil
+}
+
+// parseWeekday parses the English name of a day of the week.
+func parseWeekday(day string) (time.Weekday, bool) {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if weekday.String() == day {
+			return weekday, true
+		}
+	}
+	return 0, false
+}
+
+// parseClock parses a time of day as HH:MM, or def if it is empty.
+func parseClock(clock, def string) (time.Duration, error) {
+	if clock == "" {
+		clock = def
+	}
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// CheckBusinessHours returns an error if business hours cannot be parsed.
+func CheckBusinessHours(hours *optimizerv1.BusinessHours) error {
+	_, err := parseBusinessHours(hours)
+	return err
+}
+
+// open returns whether the window is open at now and, if so, when it closes.
+// Windows run past midnight when their end is before their start, so the
+// window opened the day before is checked too.
+func (w *businessWindow) open(now time.Time) (bool, time.Time) {
+	now = now.In(w.location)
+	for _, offset := range []int{-1, 0} {
+		// time.Date, unlike adding durations, keeps the times of day across
+		// daylight saving time changes.
+		at := func(d time.Duration, days int) time.Time {
+			return time.Date(now.Year(), now.Month(), now.Day()+offset+days, 0, int(d/time.Minute), 0, 0, w.location)
+		}
+		if !slices.Contains(w.days, at(0, 0).Weekday()) {
+			continue
+		}
+		opens, closes := at(w.start, 0), at(w.end, 0)
+		if w.end < w.start {
+			closes = at(w.end, 1)
+		}
+		if !now.Before(opens) && now.Before(closes) {
+			return true, closes
+		}
+	}
+	return false, time.Time{}
+}
+
+// businessHours returns the business hours that apply to the profile: its
+// own, unless disabled, or else the controller's.
+func (r *ResourceOptimizerProfileReconciler) businessHours(profile *optimizerv1.ResourceOptimizerProfile) *optimizerv1.BusinessHours {
+	if hours := profile.Spec.BusinessHours; hours != nil {
+		if hours.Disabled {
+			return nil
+		}
+		return hours
+	}
+	return r.BusinessHours
+}
+
+// deferredUntil returns when the business hours of the profile close if a
+// scale-down or resize-down is called for while they are open, or the zero
+// time otherwise.
+func (r *ResourceOptimizerProfileReconciler) deferredUntil(profile *optimizerv1.ResourceOptimizerProfile, action string, now time.Time) (time.Time, error) {
+	hours := r.businessHours(profile)
+	if hours == nil || (action != ScaleDownAction && action != ResizeDownAction && action != ScaleToZeroAction) {
+		return time.Time{}, nil
+	}
+	w, err := parseBusinessHours(hours)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid business hours: %w", err)
+	}
+	if open, closes := w.open(now); open {
+		return closes, nil
+	}
+	return time.Time{}, nil
+}
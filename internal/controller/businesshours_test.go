@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Business hours", func() {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	// Thursday.
+	day := func(hour, minute int) time.Time { return time.Date(2025, time.October, 16, hour, minute, 0, 0, berlin) }
+
+	It("should defer downward actions while business hours are open", func() {
+		reconciler := &ResourceOptimizerProfileReconciler{BusinessHours: &optimizerv1.BusinessHours{TimeZone: "Europe/Berlin"}}
+		profile := &optimizerv1.ResourceOptimizerProfile{}
+
+		until, err := reconciler.deferredUntil(profile, ScaleDownAction, day(10, 30))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(until).To(BeTemporally("==", day(18, 0)))
+		Expect(reconciler.deferredUntil(profile, ScaleUpAction, day(10, 30))).To(BeZero())
+		Expect(reconciler.deferredUntil(profile, ResizeDownAction, day(18, 0))).To(BeZero())
+		Expect(reconciler.deferredUntil(profile, ScaleDownAction, day(8, 59))).To(BeZero())
+		// Saturday.
+		Expect(reconciler.deferredUntil(profile, ScaleDownAction, day(10, 30).AddDate(0, 0, 2))).To(BeZero())
+
+		profile.Spec.BusinessHours = &optimizerv1.BusinessHours{Disabled: true}
+		Expect(reconciler.deferredUntil(profile, ScaleDownAction, day(10, 30))).To(BeZero())
+	})
+
+	It("should run windows past midnight", func() {
+		w, err := parseBusinessHours(&optimizerv1.BusinessHours{Days: []string{"Thursday"}, Start: "22:00", End: "06:00", TimeZone: "Europe/Berlin"})
+		Expect(err).NotTo(HaveOccurred())
+		open, closes := w.open(day(23, 0))
+		Expect(open).To(BeTrue())
+		Expect(closes).To(BeTemporally("==", day(6, 0).AddDate(0, 0, 1)))
+		open, _ = w.open(day(5, 0).AddDate(0, 0, 1))
+		Expect(open).To(BeTrue())
+		// The window opened on Wednesday does not apply.
+		open, _ = w.open(day(5, 0))
+		Expect(open).To(BeFalse())
+	})
+
+	It("should reject invalid business hours", func() {
+		Expect(CheckBusinessHours(&optimizerv1.BusinessHours{Days: []string{"Funday"}})).To(MatchError(ContainSubstring(`unknown day "Funday"`)))
+		Expect(CheckBusinessHours(&optimizerv1.BusinessHours{Start: "9am"})).To(MatchError(ContainSubstring("invalid start")))
+		Expect(CheckBusinessHours(&optimizerv1.BusinessHours{})).To(Succeed())
+	})
+})
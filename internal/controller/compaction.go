@@ -0,0 +1,247 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods/eviction,verbs=create
+
+const (
+	// DefaultCompactionUtilizationThreshold is the percentage of a node's
+	// allocatable CPU requested below which its pods are moved elsewhere.
+	DefaultCompactionUtilizationThreshold = 50
+	// DefaultCompactionMaxEvictions caps the pods evicted after one resize-down.
+	DefaultCompactionMaxEvictions = 5
+)
+
+// Compactor evicts pods from lightly requested nodes after their requests were
+// lowered, so the scheduler packs them onto fewer nodes and the capacity freed
+// by the resize can be reclaimed. Evictions go through the Eviction API and are
+// refused while they would violate a PodDisruptionBudget.
+type Compactor struct {
+	// UtilizationThreshold is the percentage of a node's allocatable CPU
+	// requested below which the node's pods are evicted.
+	UtilizationThreshold float64
+	// MaxEvictions caps the pods evicted per call.
+	MaxEvictions int
+}
+
+// nodeUsage is the CPU, in millicores, allocatable on and requested from a node.
+type nodeUsage struct {
+	node        *corev1.Node
+	name        string
+	allocatable int64
+	requested   int64
+}
+
+func (n nodeUsage) utilization() float64 {
+	if n.allocatable == 0 {
+		return 100
+	}
+	return float64(n.requested) / float64(n.allocatable) * 100
+}
+
+// workloadPods matches the pods of one Deployment or StatefulSet.
+type workloadPods struct {
+	target   targetRef
+	selector labels.Selector
+}
+
+// owns reports whether pod belongs to the workload: it is selected by the
+// workload and controlled by it, or for a Deployment by one of its
+// ReplicaSets.
+func (w workloadPods) owns(pod *corev1.Pod) bool {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil || !w.selector.Matches(labels.Set(pod.Labels)) {
+		return false
+	}
+	switch w.target.Kind {
+	case "Deployment":
+		return owner.Kind == "ReplicaSet" && strings.HasPrefix(owner.Name, w.target.Name+"-")
+	case "StatefulSet":
+		return owner.Kind == "StatefulSet" && owner.Name == w.target.Name
+	}
+	return false
+}
+
+// targetPods looks up the selectors of the targets in namespace. Targets that
+// no longer exist are left out.
+func targetPods(ctx context.Context, cl client.Client, namespace string, targets []targetRef) ([]workloadPods, error) {
+	var workloads []workloadPods
+	for _, target := range targets {
+		var selector *metav1.LabelSelector
+		key := types.NamespacedName{Namespace: namespace, Name: target.Name}
+		var err error
+		switch target.Kind {
+		case "Deployment":
+			var deployment appsv1.Deployment
+			err = cl.Get(ctx, key, &deployment)
+			selector = deployment.Spec.Selector
+		case "StatefulSet":
+			var statefulSet appsv1.StatefulSet
+			err = cl.Get(ctx, key, &statefulSet)
+			selector = statefulSet.Spec.Selector
+		default:
+			continue
+		}
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil || parsed.Empty() {
+			// An empty selector would match every pod of the namespace.
+			continue
+		}
+		workloads = append(workloads, workloadPods{target: target, selector: parsed})
+	}
+	return workloads, nil
+}
+
+// Compact evicts the pods of the given targets in namespace that run on nodes
+// below the utilization threshold, as long as one of the other nodes the pods
+// may be scheduled on has room for their requests. Pods of other workloads are
+// never evicted. It returns the number of pods evicted.
+func (c *Compactor) Compact(ctx context.Context, cl client.Client, namespace string, targets []targetRef) (int, error) {
+	logger := log.FromContext(ctx)
+
+	workloads, err := targetPods(ctx, cl, namespace, targets)
+	if err != nil || len(workloads) == 0 {
+		return 0, err
+	}
+	ownedByTarget := func(pod *corev1.Pod) bool {
+		return slices.ContainsFunc(workloads, func(w workloadPods) bool { return w.owns(pod) })
+	}
+
+	var nodes corev1.NodeList
+	if err := cl.List(ctx, &nodes); err != nil {
+		return 0, err
+	}
+	var pods corev1.PodList
+	if err := cl.List(ctx, &pods); err != nil {
+		return 0, err
+	}
+
+	usage := map[string]*nodeUsage{}
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if node.Spec.Unschedulable {
+			continue
+		}
+		usage[node.Name] = &nodeUsage{node: node, name: node.Name, allocatable: node.Status.Allocatable.Cpu().MilliValue()}
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if node, ok := usage[pod.Spec.NodeName]; ok && !podTerminated(pod) {
+			node.requested += podCPURequest(pod)
+		}
+	}
+
+	var sources, destinations []*nodeUsage
+	for _, node := range usage {
+		if node.utilization() < c.UtilizationThreshold {
+			sources = append(sources, node)
+		} else {
+			destinations = append(destinations, node)
+		}
+	}
+	// Empty the least requested nodes first: they are the likeliest to be freed.
+	sort.Slice(sources, func(i, j int) bool {
+		if sources[i].utilization() != sources[j].utilization() {
+			return sources[i].utilization() < sources[j].utilization()
+		}
+		return sources[i].name < sources[j].name
+	})
+
+	evicted := 0
+	for _, node := range sources {
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			if pod.Spec.NodeName != node.name || pod.Namespace != namespace || !evictable(pod) || !ownedByTarget(pod) {
+				continue
+			}
+			if evicted >= c.MaxEvictions {
+				return evicted, nil
+			}
+			request := podCPURequest(pod)
+			destination := roomiestNode(destinations, pod, request)
+			if destination == nil {
+				continue
+			}
+			err := cl.SubResource("eviction").Create(ctx, pod, &policyv1.Eviction{
+				ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+			})
+			switch {
+			case apierrors.IsTooManyRequests(err):
+				logger.Info("Eviction refused by a PodDisruptionBudget", "pod", pod.Name, "node", node.name)
+				continue
+			case apierrors.IsNotFound(err):
+				continue
+			case err != nil:
+				return evicted, fmt.Errorf("evicting pod %s/%s: %w", pod.Namespace, pod.Name, err)
+			}
+			logger.Info("Evicted pod to compact nodes", "pod", pod.Name, "node", node.name,
+				"nodeUtilization", fmt.Sprintf("%.2f%%", node.utilization()))
+			destination.requested += request
+			evicted++
+		}
+	}
+	return evicted, nil
+}
+
+// roomiestNode returns the node with the most CPU left among those pod may be
+// scheduled on and that have room for its request, or nil if there is none.
+func roomiestNode(nodes []*nodeUsage, pod *corev1.Pod, request int64) *nodeUsage {
+	var roomiest *nodeUsage
+	for _, node := range nodes {
+		free := node.allocatable - node.requested
+		if free < request || !schedulableOn(&pod.Spec, node.node) {
+			continue
+		}
+		if roomiest == nil || free > roomiest.allocatable-roomiest.requested ||
+			free == roomiest.allocatable-roomiest.requested && node.name < roomiest.name {
+			roomiest = node
+		}
+	}
+	return roomiest
+}
+
+// evictable reports whether a running pod is recreated elsewhere by its
+// controller when evicted. DaemonSet pods would come back on the same node.
+func evictable(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+		return false
+	}
+	owner := metav1.GetControllerOf(pod)
+	return owner != nil && owner.Kind != "DaemonSet" && owner.Kind != "Node"
+}
+
+func podTerminated(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
+
+// podCPURequest is the CPU, in millicores, requested by a pod's containers.
+func podCPURequest(pod *corev1.Pod) int64 {
+	var total int64
+	for _, container := range pod.Spec.Containers {
+		total += container.Resources.Requests.Cpu().MilliValue()
+	}
+	return total
+}
@@ -0,0 +1,228 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/common/model"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Compactor", func() {
+	node := func(name, cpu string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     corev1.NodeStatus{Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}},
+		}
+	}
+	web := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+		Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+	}
+	targets := []targetRef{{Kind: "Deployment", Name: "web"}}
+	// pod returns a pod of the web Deployment, or of another controller of
+	// ownerKind.
+	pod := func(namespace, name, nodeName, cpu, ownerKind string) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{"app": "web"}},
+			Spec: corev1.PodSpec{NodeName: nodeName, Containers: []corev1.Container{{
+				Name:      "app",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}},
+			}}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+		if ownerKind != "" {
+			owner := name
+			if ownerKind == "ReplicaSet" {
+				owner = "web-5d8f9c"
+			}
+			p.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: ownerKind, Name: owner, UID: "uid", Controller: ptr.To(true)}}
+		}
+		return p
+	}
+	newClient := func(funcs interceptor.Funcs, objects ...client.Object) client.Client {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(objects, web.DeepCopy())...).WithInterceptorFuncs(funcs).Build()
+	}
+	remaining := func(c client.Client) []string {
+		var pods corev1.PodList
+		Expect(c.List(context.Background(), &pods)).To(Succeed())
+		var names []string
+		for _, p := range pods.Items {
+			names = append(names, p.Name)
+		}
+		return names
+	}
+
+	It("should evict the targets' pods from lightly requested nodes", func() {
+		c := newClient(interceptor.Funcs{},
+			node("busy", "4"), node("idle", "4"),
+			pod("team-a", "busy-0", "busy", "3", "ReplicaSet"),
+			pod("team-a", "idle-0", "idle", "500m", "ReplicaSet"),
+			pod("team-a", "idle-ds", "idle", "100m", "DaemonSet"),
+			pod("team-a", "idle-bare", "idle", "100m", ""),
+			pod("team-b", "idle-other", "idle", "100m", "ReplicaSet"),
+		)
+		compactor := &Compactor{UtilizationThreshold: 50, MaxEvictions: 5}
+		evicted, err := compactor.Compact(context.Background(), c, "team-a", targets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(evicted).To(Equal(1))
+		Expect(remaining(c)).To(ConsistOf("busy-0", "idle-ds", "idle-bare", "idle-other"))
+	})
+
+	It("should never evict the pods of workloads that were not resized", func() {
+		db := pod("team-a", "idle-db", "idle", "500m", "ReplicaSet")
+		db.Labels = map[string]string{"app": "db"}
+		db.OwnerReferences[0].Name = "db-7c6b5"
+		c := newClient(interceptor.Funcs{},
+			node("busy", "4"), node("idle", "4"),
+			pod("team-a", "busy-0", "busy", "3", "ReplicaSet"),
+			db,
+			pod("team-a", "idle-0", "idle", "500m", "ReplicaSet"),
+		)
+		// db was protected or denied, so only web was resized.
+		evicted, err := (&Compactor{UtilizationThreshold: 50, MaxEvictions: 5}).Compact(context.Background(), c, "team-a", targets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(evicted).To(Equal(1))
+		Expect(remaining(c)).To(ConsistOf("busy-0", "idle-db"))
+
+		c = newClient(interceptor.Funcs{},
+			node("busy", "4"), node("idle", "4"),
+			pod("team-a", "busy-0", "busy", "3", "ReplicaSet"),
+			pod("team-a", "idle-0", "idle", "500m", "ReplicaSet"),
+		)
+		evicted, err = (&Compactor{UtilizationThreshold: 50, MaxEvictions: 5}).Compact(context.Background(), c, "team-a", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(evicted).To(BeZero())
+	})
+
+	It("should not compact after a resize down that changed no target", func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(optimizerv1.AddToScheme(scheme)).To(Succeed())
+		protected := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", Labels: map[string]string{"app": "web", "tier": "critical"}},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+					}}}},
+				},
+			},
+		}
+		evictions := 0
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&optimizerv1.ResourceOptimizerProfile{}).WithObjects(
+			&optimizerv1.ResourceOptimizerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+				Spec: optimizerv1.ResourceOptimizerProfileSpec{
+					Selector:           metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+					OptimizationPolicy: "Resize",
+					CPUThresholds:      optimizerv1.ThresholdSpec{Min: 30, Max: 70},
+				},
+			},
+			protected,
+			node("busy", "4"), node("idle", "4"),
+			pod("team-a", "busy-0", "busy", "3", "ReplicaSet"),
+			pod("team-a", "idle-0", "idle", "500m", "ReplicaSet"),
+		).WithInterceptorFuncs(interceptor.Funcs{
+			SubResourceCreate: func(ctx context.Context, c client.Client, subResource string, obj client.Object, sub client.Object, opts ...client.SubResourceCreateOption) error {
+				evictions++
+				return c.SubResource(subResource).Create(ctx, obj, sub, opts...)
+			},
+		}).Build()
+		selectors, err := ParseWorkloadSelectors([]string{"tier=critical"})
+		Expect(err).NotTo(HaveOccurred())
+		reconciler := &ResourceOptimizerProfileReconciler{
+			Client:             c,
+			Scheme:             scheme,
+			PrometheusAPI:      &mockPrometheusAPI{result: model.Vector{{Value: 10}}},
+			Compactor:          &Compactor{UtilizationThreshold: 50, MaxEvictions: 5},
+			ProtectedWorkloads: selectors,
+		}
+
+		_, err = reconciler.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "team-a", Name: "web"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(evictions).To(BeZero())
+		Expect(remaining(c)).To(ConsistOf("busy-0", "idle-0"))
+	})
+
+	It("should not evict pods the other nodes have no room for", func() {
+		c := newClient(interceptor.Funcs{},
+			node("busy", "4"), node("idle", "4"),
+			pod("team-a", "busy-0", "busy", "3800m", "ReplicaSet"),
+			pod("team-a", "idle-0", "idle", "500m", "ReplicaSet"),
+		)
+		evicted, err := (&Compactor{UtilizationThreshold: 50, MaxEvictions: 5}).Compact(context.Background(), c, "team-a", targets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(evicted).To(BeZero())
+	})
+
+	It("should not evict pods to nodes they cannot be scheduled on", func() {
+		tainted := node("busy", "4")
+		tainted.Spec.Taints = []corev1.Taint{{Key: "dedicated", Value: "batch", Effect: corev1.TaintEffectNoSchedule}}
+		c := newClient(interceptor.Funcs{},
+			tainted, node("idle", "4"),
+			pod("team-a", "busy-0", "busy", "3", "ReplicaSet"),
+			pod("team-a", "idle-0", "idle", "500m", "ReplicaSet"),
+		)
+		evicted, err := (&Compactor{UtilizationThreshold: 50, MaxEvictions: 5}).Compact(context.Background(), c, "team-a", targets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(evicted).To(BeZero())
+	})
+
+	It("should skip pods protected by a PodDisruptionBudget and stop at the eviction limit", func() {
+		c := newClient(interceptor.Funcs{
+			SubResourceCreate: func(ctx context.Context, c client.Client, subResource string, obj client.Object, sub client.Object, opts ...client.SubResourceCreateOption) error {
+				if obj.GetName() == "idle-0" {
+					return apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 10)
+				}
+				return c.SubResource(subResource).Create(ctx, obj, sub, opts...)
+			},
+		},
+			node("busy", "8"), node("idle", "4"),
+			pod("team-a", "idle-0", "idle", "100m", "ReplicaSet"),
+			pod("team-a", "idle-1", "idle", "100m", "ReplicaSet"),
+			pod("team-a", "idle-2", "idle", "100m", "ReplicaSet"),
+			pod("team-a", "busy-0", "busy", "5", "ReplicaSet"),
+		)
+		evicted, err := (&Compactor{UtilizationThreshold: 50, MaxEvictions: 1}).Compact(context.Background(), c, "team-a", targets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(evicted).To(Equal(1))
+		Expect(remaining(c)).To(ContainElements("idle-0", "busy-0"))
+		Expect(remaining(c)).To(HaveLen(3))
+	})
+
+	It("should report other eviction failures", func() {
+		c := newClient(interceptor.Funcs{
+			SubResourceCreate: func(context.Context, client.Client, string, client.Object, client.Object, ...client.SubResourceCreateOption) error {
+				return apierrors.NewForbidden(schema.GroupResource{Resource: "pods/eviction"}, "idle-0", nil)
+			},
+		},
+			node("busy", "4"), node("idle", "4"),
+			pod("team-a", "busy-0", "busy", "3", "ReplicaSet"),
+			pod("team-a", "idle-0", "idle", "100m", "ReplicaSet"),
+		)
+		_, err := (&Compactor{UtilizationThreshold: 50, MaxEvictions: 5}).Compact(context.Background(), c, "team-a", targets)
+		Expect(err).To(MatchError(ContainSubstring("evicting pod team-a/idle-0")))
+	})
+})
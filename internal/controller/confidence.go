@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"context"
+	"math"
+	"time"
+
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// ConfidenceWindow is the utilization history the confidence of a profile is
+// computed from, sampled every ConfidenceStep.
+const (
+	ConfidenceWindow = 24 * time.Hour
+	ConfidenceStep   = 5 * time.Minute
+)
+
+// Confidence levels, by score.
+const (
+	ConfidenceLow    = "Low"
+	ConfidenceMedium = "Medium"
+	ConfidenceHigh   = "High"
+)
+
+// Lowest scores of the Medium and High confidence levels.
+const (
+	mediumConfidenceScore = 50
+	highConfidenceScore   = 75
+)
+
+// confidenceOf scores utilization samples taken every step over a window. The
+// score is the share of the window covered by samples, counting both how many
+// there are and how far back they go, divided by one plus their coefficient
+// of variation: a full window of steady utilization scores close to 100, while
+// a few hours of history or erratic utilization score low.
+func confidenceOf(samples []CPUSample, window, step time.Duration) optimizerv1.Confidence {
+	confidence := optimizerv1.Confidence{Level: ConfidenceLow, Samples: int32(len(samples))}
+	if len(samples) == 0 {
+		return confidence
+	}
+	length := samples[len(samples)-1].Time.Sub(samples[0].Time)
+	confidence.HistoryLength = metav1.Duration{Duration: length}
+
+	var sum float64
+	for _, sample := range samples {
+		sum += sample.Value
+	}
+	mean := sum / float64(len(samples))
+	var squares float64
+	for _, sample := range samples {
+		squares += (sample.Value - mean) * (sample.Value - mean)
+	}
+	variation := 0.0
+	if mean > 0 {
+		variation = math.Sqrt(squares/float64(len(samples))) / mean
+	}
+	confidence.VariationPercent = int32(math.Round(variation * 100))
+
+	expected := float64(window/step) + 1
+	coverage := (min(float64(len(samples))/expected, 1) + min(float64(length)/float64(window), 1)) / 2
+	confidence.Score = int32(math.Round(100 * coverage / (1 + variation)))
+	switch {
+	case confidence.Score >= highConfidenceScore:
+		confidence.Level = ConfidenceHigh
+	case confidence.Score >= mediumConfidenceScore:
+		confidence.Level = ConfidenceMedium
+	}
+	return confidence
+}
+
+// updateConfidence computes the confidence of the profile from the
+// utilization history of its targets and sets it in the status. It is left
+// unset when the history cannot be queried.
+func (r *ResourceOptimizerProfileReconciler) updateConfidence(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile) {
+	profile.Status.Confidence = nil
+	now := time.Now()
+	samples, err := QueryCPUHistory(ctx, r.Client, r.PrometheusAPI, profile,
+		prometheusv1.Range{Start: now.Add(-ConfidenceWindow), End: now, Step: ConfidenceStep})
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to query the utilization history for the confidence")
+		return
+	}
+	confidence := confidenceOf(samples, ConfidenceWindow, ConfidenceStep)
+	profile.Status.Confidence = &confidence
+}
+
+// confidentEnough reports whether the profile's confidence reaches its
+// minConfidence. An unknown confidence never does.
+func confidentEnough(profile *optimizerv1.ResourceOptimizerProfile) bool {
+	if profile.Spec.MinConfidence == nil {
+		return true
+	}
+	return profile.Status.Confidence != nil && profile.Status.Confidence.Score >= *profile.Spec.MinConfidence
+}
@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Confidence", func() {
+	start := time.Date(2025, time.October, 17, 0, 0, 0, 0, time.UTC)
+	samples := func(d time.Duration, values ...float64) []CPUSample {
+		var out []CPUSample
+		for t := time.Duration(0); t <= d; t += ConfidenceStep {
+			out = append(out, CPUSample{Time: start.Add(t), Value: values[int(t/ConfidenceStep)%len(values)]})
+		}
+		return out
+	}
+
+	It("should be high for a full window of steady utilization", func() {
+		confidence := confidenceOf(samples(ConfidenceWindow, 50), ConfidenceWindow, ConfidenceStep)
+		Expect(confidence.Score).To(Equal(int32(100)))
+		Expect(confidence.Level).To(Equal(ConfidenceHigh))
+		Expect(confidence.Samples).To(Equal(int32(289)))
+		Expect(confidence.HistoryLength.Duration).To(Equal(ConfidenceWindow))
+		Expect(confidence.VariationPercent).To(BeZero())
+	})
+
+	It("should drop with short history and erratic utilization", func() {
+		confidence := confidenceOf(samples(12*time.Hour, 50), ConfidenceWindow, ConfidenceStep)
+		Expect(confidence.Score).To(Equal(int32(50)))
+		Expect(confidence.Level).To(Equal(ConfidenceMedium))
+
+		confidence = confidenceOf(samples(ConfidenceWindow, 20, 80), ConfidenceWindow, ConfidenceStep)
+		Expect(confidence.VariationPercent).To(Equal(int32(60)))
+		Expect(confidence.Score).To(Equal(int32(62)))
+		Expect(confidence.Level).To(Equal(ConfidenceMedium))
+
+		confidence = confidenceOf(nil, ConfidenceWindow, ConfidenceStep)
+		Expect(confidence.Score).To(BeZero())
+		Expect(confidence.Level).To(Equal(ConfidenceLow))
+	})
+
+	It("should hold actions below the minimum confidence", func() {
+		profile := &optimizerv1.ResourceOptimizerProfile{}
+		Expect(confidentEnough(profile)).To(BeTrue())
+
+		profile.Spec.MinConfidence = ptr.To[int32](60)
+		Expect(confidentEnough(profile)).To(BeFalse())
+		profile.Status.Confidence = &optimizerv1.Confidence{Score: 59}
+		Expect(confidentEnough(profile)).To(BeFalse())
+		profile.Status.Confidence.Score = 60
+		Expect(confidentEnough(profile)).To(BeTrue())
+	})
+})
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// dryRunDiff returns what the action a Recommend profile holds back would
+// change on each target: the replicas a Scale profile would scale it to, and
+// the CPU request a Resize profile would set. Targets neither would change are
+// left out.
+func (r *ResourceOptimizerProfileReconciler) dryRunDiff(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, action string, observedValue float64) ([]optimizerv1.TargetDiff, error) {
+	listOpts := &client.ListOptions{LabelSelector: labels.Set(profile.Spec.Selector.MatchLabels).AsSelector(), Namespace: profile.Namespace}
+	var deployments appsv1.DeploymentList
+	if err := r.List(ctx, &deployments, listOpts); err != nil {
+		return nil, err
+	}
+	var statefulSets appsv1.StatefulSetList
+	if err := r.List(ctx, &statefulSets, listOpts); err != nil {
+		return nil, err
+	}
+	replicas := map[targetRef]int32{}
+	for _, deployment := range deployments.Items {
+		replicas[targetRef{Kind: "Deployment", Name: deployment.Name}] = ptr.Deref(deployment.Spec.Replicas, 1)
+	}
+	for _, ss := range statefulSets.Items {
+		replicas[targetRef{Kind: "StatefulSet", Name: ss.Name}] = ptr.Deref(ss.Spec.Replicas, 1)
+	}
+
+	recommendations, err := recommendCPURequests(ctx, r.Client, r.PrometheusAPI, profile, &r.usageHistory, observedValue)
+	if err != nil {
+		return nil, err
+	}
+	current, err := currentTargets(ctx, r.Client, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	var diff []optimizerv1.TargetDiff
+	for target, from := range replicas {
+		var changes []string
+		if action != DoNothing {
+			if to := cappedReplicas(profile, from, steppedReplicas(profile, action, from)); to != from {
+				changes = append(changes, fmt.Sprintf("replicas %d→%d", from, to))
+			}
+		}
+		if request, ok := recommendations[target]; ok {
+			if before := current[target].CurrentCPURequest; before.Cmp(*request) != 0 {
+				changes = append(changes, fmt.Sprintf("cpu %s→%s", before.String(), request.String()))
+			}
+		}
+		if len(changes) > 0 {
+			diff = append(diff, optimizerv1.TargetDiff{Kind: target.Kind, Name: target.Name, Diff: strings.Join(changes, ", ")})
+		}
+	}
+	slices.SortFunc(diff, func(a, b optimizerv1.TargetDiff) int {
+		return cmp.Or(cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Name, b.Name))
+	})
+	return diff, nil
+}
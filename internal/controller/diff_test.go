@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Dry-run diff", func() {
+	labels := map[string]string{"app": "web"}
+
+	It("should list the replicas and CPU requests a held back action would change", func() {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		reconciler := &ResourceOptimizerProfileReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", Labels: labels},
+				Spec: appsv1.DeploymentSpec{Replicas: ptr.To[int32](3), Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Name:      "app",
+					Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")}},
+				}}}}},
+			},
+			&appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "cache", Namespace: "team-a", Labels: labels},
+				Spec:       appsv1.StatefulSetSpec{Replicas: ptr.To[int32](1)},
+			},
+		).Build()}
+		up := intstr.FromInt32(2)
+		profile := &optimizerv1.ResourceOptimizerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+			Spec: optimizerv1.ResourceOptimizerProfileSpec{
+				Selector:           metav1.LabelSelector{MatchLabels: labels},
+				CPUThresholds:      optimizerv1.ThresholdSpec{Min: 20, Max: 80},
+				OptimizationPolicy: "Recommend",
+				ScaleStep:          &optimizerv1.ScaleStep{Up: &up},
+				MaxCPU:             ptr.To(resource.MustParse("750m")),
+			},
+		}
+
+		diff, err := reconciler.dryRunDiff(context.Background(), profile, ScaleUpAction, 90)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(diff).To(Equal([]optimizerv1.TargetDiff{
+			{Kind: "Deployment", Name: "web", Diff: "replicas 3→5, cpu 500m→750m"},
+			{Kind: "StatefulSet", Name: "cache", Diff: "replicas 1→3"},
+		}))
+	})
+})
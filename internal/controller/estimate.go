@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// workloadTemplate is the part of a Deployment or StatefulSet the initial
+// estimates are made from.
+type workloadTemplate struct {
+	ref        targetRef
+	containers []corev1.Container
+}
+
+// estimateInitialCPURequests estimates a CPU request for every target of a
+// profile without metric history. A target's container is estimated at the
+// median CPU request of the same container image in the other workloads of the
+// namespace, or else at the profile's initialCPURequest. Estimates are clamped
+// to minCPU and maxCPU like resizes.
+func estimateInitialCPURequests(ctx context.Context, c client.Reader, profile *optimizerv1.ResourceOptimizerProfile) ([]optimizerv1.InitialEstimate, error) {
+	all, err := listWorkloadTemplates(ctx, c, &client.ListOptions{Namespace: profile.Namespace})
+	if err != nil {
+		return nil, err
+	}
+	targets, err := listWorkloadTemplates(ctx, c, &client.ListOptions{
+		Namespace:     profile.Namespace,
+		LabelSelector: labels.Set(profile.Spec.Selector.MatchLabels).AsSelector(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var estimates []optimizerv1.InitialEstimate
+	for _, target := range targets {
+		container, ok := resizedContainer(target.containers)
+		if !ok {
+			continue
+		}
+		estimate := optimizerv1.InitialEstimate{Kind: target.ref.Kind, Name: target.ref.Name, Container: container.Name}
+		repository := imageRepository(container.Image)
+
+		var requests []resource.Quantity
+		var similar []string
+		for _, peer := range all {
+			if slices.ContainsFunc(targets, func(t workloadTemplate) bool { return t.ref == peer.ref }) {
+				continue
+			}
+			for _, pc := range peer.containers {
+				request, ok := pc.Resources.Requests[corev1.ResourceCPU]
+				if ok && imageRepository(pc.Image) == repository {
+					requests = append(requests, request)
+					similar = append(similar, peer.ref.Kind+"/"+peer.ref.Name)
+					break
+				}
+			}
+		}
+
+		switch {
+		case len(requests) > 0:
+			slices.SortFunc(requests, func(a, b resource.Quantity) int { return a.Cmp(b) })
+			estimate.CPURequest = clampCPURequest(profile, requests[len(requests)/2])
+			estimate.Rationale = fmt.Sprintf("median CPU request of %d workloads running %s: %s",
+				len(requests), repository, strings.Join(similar, ", "))
+		case profile.Spec.InitialCPURequest != nil:
+			estimate.CPURequest = clampCPURequest(profile, *profile.Spec.InitialCPURequest)
+			estimate.Rationale = fmt.Sprintf("spec.initialCPURequest, no other workload runs %s", repository)
+		default:
+			estimate.Rationale = fmt.Sprintf("no other workload runs %s and spec.initialCPURequest is not set", repository)
+		}
+		estimates = append(estimates, estimate)
+	}
+	return estimates, nil
+}
+
+// listWorkloadTemplates lists the Deployments and StatefulSets matching opts.
+func listWorkloadTemplates(ctx context.Context, c client.Reader, opts *client.ListOptions) ([]workloadTemplate, error) {
+	var deployments appsv1.DeploymentList
+	if err := c.List(ctx, &deployments, opts); err != nil {
+		return nil, err
+	}
+	var statefulSets appsv1.StatefulSetList
+	if err := c.List(ctx, &statefulSets, opts); err != nil {
+		return nil, err
+	}
+	var workloads []workloadTemplate
+	for _, deployment := range deployments.Items {
+		workloads = append(workloads, workloadTemplate{
+			ref:        targetRef{Kind: "Deployment", Name: deployment.Name},
+			containers: deployment.Spec.Template.Spec.Containers,
+		})
+	}
+	for _, ss := range statefulSets.Items {
+		workloads = append(workloads, workloadTemplate{
+			ref:        targetRef{Kind: "StatefulSet", Name: ss.Name},
+			containers: ss.Spec.Template.Spec.Containers,
+		})
+	}
+	return workloads, nil
+}
+
+// resizedContainer returns the container the Resize policy operates on: the
+// first one with a CPU request, or else the first one.
+func resizedContainer(containers []corev1.Container) (corev1.Container, bool) {
+	if len(containers) == 0 {
+		return corev1.Container{}, false
+	}
+	for _, container := range containers {
+		if _, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
+			return container, true
+		}
+	}
+	return containers[0], true
+}
+
+// imageRepository strips the tag and digest from an image reference, so that
+// different versions of an image count as the same image.
+func imageRepository(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
+// clampCPURequest bounds a CPU request by the profile's minCPU and maxCPU.
+func clampCPURequest(profile *optimizerv1.ResourceOptimizerProfile, request resource.Quantity) *resource.Quantity {
+	if profile.Spec.MinCPU != nil && request.Cmp(*profile.Spec.MinCPU) < 0 {
+		request = profile.Spec.MinCPU.DeepCopy()
+	}
+	if profile.Spec.MaxCPU != nil && request.Cmp(*profile.Spec.MaxCPU) > 0 {
+		request = profile.Spec.MaxCPU.DeepCopy()
+	}
+	return &request
+}
+
+// cappedCPURequest limits the change from the current CPU request to the
+// desired one to the profile's maxResizePercent of the current request. Every
+// capped change is at least a millicore, and containers without a current
+// request are not capped.
+func cappedCPURequest(profile *optimizerv1.ResourceOptimizerProfile, current, desired resource.Quantity) *resource.Quantity {
+	if profile.Spec.MaxResizePercent == nil || current.Sign() <= 0 {
+		return &desired
+	}
+	limit := max(current.MilliValue()*int64(*profile.Spec.MaxResizePercent)/100, 1)
+	milli := min(max(desired.MilliValue(), current.MilliValue()-limit), current.MilliValue()+limit)
+	if milli == desired.MilliValue() {
+		return &desired
+	}
+	return resource.NewMilliQuantity(max(milli, 1), resource.DecimalSI)
+}
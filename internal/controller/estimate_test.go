@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Initial estimates", func() {
+	deployment := func(name, app, image, cpu string) *appsv1.Deployment {
+		container := corev1.Container{Name: "app", Image: image}
+		if cpu != "" {
+			container.Resources.Requests = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}
+		}
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-a", Labels: map[string]string{"app": app}},
+			Spec:       appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{container}}}},
+		}
+	}
+	newClient := func(objects ...client.Object) client.Client {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	}
+
+	var profile *optimizerv1.ResourceOptimizerProfile
+
+	BeforeEach(func() {
+		profile = &optimizerv1.ResourceOptimizerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+			Spec: optimizerv1.ResourceOptimizerProfileSpec{
+				Selector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			},
+		}
+	})
+
+	It("should estimate from workloads running the same image", func() {
+		c := newClient(
+			deployment("web", "web", "registry.example.com/web:2.0", ""),
+			deployment("web-eu", "web-eu", "registry.example.com/web:1.9", "300m"),
+			deployment("web-us", "web-us", "registry.example.com/web@sha256:abc", "500m"),
+			deployment("web-ap", "web-ap", "registry.example.com/web:1.8", "200m"),
+			deployment("api", "api", "registry.example.com/api:1.0", "2"),
+		)
+		estimates, err := estimateInitialCPURequests(context.Background(), c, profile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(estimates).To(HaveLen(1))
+		Expect(estimates[0].Kind).To(Equal("Deployment"))
+		Expect(estimates[0].Container).To(Equal("app"))
+		Expect(estimates[0].CPURequest.String()).To(Equal("300m"))
+		Expect(estimates[0].Rationale).To(Equal("median CPU request of 3 workloads running registry.example.com/web: " +
+			"Deployment/web-ap, Deployment/web-eu, Deployment/web-us"))
+	})
+
+	It("should fall back to the profile's initial CPU request within the CPU bounds", func() {
+		c := newClient(deployment("web", "web", "registry.example.com/web:2.0", "100m"))
+		estimates, err := estimateInitialCPURequests(context.Background(), c, profile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(estimates[0].CPURequest).To(BeNil())
+		Expect(estimates[0].Rationale).To(ContainSubstring("spec.initialCPURequest is not set"))
+
+		profile.Spec.InitialCPURequest = ptr.To(resource.MustParse("50m"))
+		profile.Spec.MinCPU = ptr.To(resource.MustParse("100m"))
+		estimates, err = estimateInitialCPURequests(context.Background(), c, profile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(estimates[0].CPURequest.String()).To(Equal("100m"))
+		Expect(estimates[0].Rationale).To(Equal("spec.initialCPURequest, no other workload runs registry.example.com/web"))
+	})
+
+	It("should ignore the image tag and digest", func() {
+		Expect(imageRepository("nginx")).To(Equal("nginx"))
+		Expect(imageRepository("nginx:1.27")).To(Equal("nginx"))
+		Expect(imageRepository("localhost:5000/nginx:1.27")).To(Equal("localhost:5000/nginx"))
+		Expect(imageRepository("localhost:5000/nginx@sha256:abc")).To(Equal("localhost:5000/nginx"))
+	})
+})
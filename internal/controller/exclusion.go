@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// otherDimensionChanged reports whether a profile with another policy changed
+// a target within this profile's cooldown period: its CPU requests for a
+// Scale profile, or its replicas for a Resize profile. Only one dimension of a
+// target changes per cooldown window, so that the effect of every change can
+// be attributed to it. Actions held back are logged and counted as skipped.
+func (r *ResourceOptimizerProfileReconciler) otherDimensionChanged(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, action, kind string, target metav1.Object, now time.Time) bool {
+	annotations := target.GetAnnotations()
+	policy := annotations[optimizerv1.LastActionPolicyAnnotation]
+	if policy == "" || policy == profile.Spec.OptimizationPolicy {
+		return false
+	}
+	at, err := time.Parse(time.RFC3339, annotations[optimizerv1.LastActionAtAnnotation])
+	if err != nil {
+		return false
+	}
+	remaining := cooldownPeriod(profile) - now.Sub(at)
+	if remaining <= 0 {
+		return false
+	}
+	log.FromContext(ctx).Info("Skipping target changed by another policy within the cooldown period", "action", action, "kind", kind,
+		"name", target.GetName(), "policy", policy, "profile", annotations[optimizerv1.ManagedByProfileAnnotation], "remaining", remaining.String())
+	r.recordSkippedAction(profile, action, SkipReasonOtherDimension)
+	return true
+}
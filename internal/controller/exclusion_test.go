@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Mutual exclusion of scale and resize", func() {
+	It("should not scale targets resized within the cooldown period", func() {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		now := time.Now()
+		deployment := func(name string, resizedAt time.Time) *appsv1.Deployment {
+			return &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-a", Labels: map[string]string{"app": "web"}, Annotations: map[string]string{
+					optimizerv1.ManagedByProfileAnnotation: "web-resize",
+					optimizerv1.LastActionPolicyAnnotation: "Resize",
+					optimizerv1.LastActionAtAnnotation:     resizedAt.UTC().Format(time.RFC3339),
+				}},
+				Spec: appsv1.DeploymentSpec{Replicas: ptr.To[int32](2)},
+			}
+		}
+		reconciler := &ResourceOptimizerProfileReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			deployment("web", now.Add(-time.Minute)),
+			deployment("api", now.Add(-time.Hour)),
+		).Build()}
+		profile := &optimizerv1.ResourceOptimizerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-scale", Namespace: "team-a"},
+			Spec: optimizerv1.ResourceOptimizerProfileSpec{
+				Selector:           metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				CPUThresholds:      optimizerv1.ThresholdSpec{Min: 20, Max: 80},
+				OptimizationPolicy: "Scale",
+				CooldownPeriod:     &metav1.Duration{Duration: 10 * time.Minute},
+			},
+		}
+
+		results, err := reconciler.executeScaleAction(context.Background(), profile, ScaleUpAction, 90)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].Name).To(Equal("api"))
+
+		var api appsv1.Deployment
+		Expect(reconciler.Get(context.Background(), client.ObjectKey{Namespace: "team-a", Name: "api"}, &api)).To(Succeed())
+		Expect(api.Annotations).To(HaveKeyWithValue(optimizerv1.LastActionPolicyAnnotation, "Scale"))
+	})
+})
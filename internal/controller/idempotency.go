@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// decisionKey identifies the decision to apply an action: the profile, the
+// generation of its spec, its last recorded action and the action, which is
+// the side of the thresholds the observed value fell on. A reconcile retried
+// because its status update failed makes the same decision again and gets the
+// same key, even though the re-queried utilization has moved, while the next
+// action after a recorded one gets a new key. Neither the observed value nor
+// the new replicas or request are part of it: both change between attempts,
+// the latter because the first attempt already patched the target.
+func decisionKey(profile *optimizerv1.ResourceOptimizerProfile, action string) string {
+	var lastAction string
+	if last := profile.Status.LastAction; last != nil {
+		lastAction = last.Type + "@" + last.Timestamp.UTC().Format(time.RFC3339Nano)
+	}
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s/%d/%s/%s", profile.UID, profile.Generation, lastAction, action))
+	return hex.EncodeToString(sum[:8])
+}
+
+// alreadyApplied reports whether a target was already patched for the decision
+// identified by key, in which case patching it again would apply the action
+// twice. Such targets are logged and counted as skipped.
+func (r *ResourceOptimizerProfileReconciler) alreadyApplied(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, action, kind string, target metav1.Object, key string) bool {
+	if target.GetAnnotations()[optimizerv1.DecisionKeyAnnotation] != key {
+		return false
+	}
+	log.FromContext(ctx).Info("Action was already applied to the target", "action", action, "kind", kind, "name", target.GetName(), "decisionKey", key)
+	r.recordSkippedAction(profile, action, SkipReasonDuplicate)
+	return true
+}
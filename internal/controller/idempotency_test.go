@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Idempotent actions", func() {
+	labels := map[string]string{"app": "web"}
+	var profile *optimizerv1.ResourceOptimizerProfile
+
+	BeforeEach(func() {
+		profile = &optimizerv1.ResourceOptimizerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", UID: "uid", Generation: 3},
+			Spec: optimizerv1.ResourceOptimizerProfileSpec{
+				Selector:           metav1.LabelSelector{MatchLabels: labels},
+				CPUThresholds:      optimizerv1.ThresholdSpec{Min: 20, Max: 80},
+				OptimizationPolicy: "Scale",
+				ActionMode:         ActionModePatch,
+			},
+		}
+	})
+
+	It("should key a decision by generation, last action and action", func() {
+		key := decisionKey(profile, ScaleUpAction)
+		Expect(decisionKey(profile, ScaleDownAction)).NotTo(Equal(key))
+
+		updated := profile.DeepCopy()
+		updated.Generation++
+		Expect(decisionKey(updated, ScaleUpAction)).NotTo(Equal(key))
+		updated = profile.DeepCopy()
+		updated.Status.LastAction = &optimizerv1.ActionDetail{Type: ScaleUpAction, Timestamp: metav1.Now()}
+		Expect(decisionKey(updated, ScaleUpAction)).NotTo(Equal(key))
+	})
+
+	It("should not patch a target twice for the same decision", func() {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		patched := map[string]int{}
+		reconciler := &ResourceOptimizerProfileReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "team-a", Labels: labels},
+				Spec: appsv1.DeploymentSpec{Replicas: ptr.To[int32](2)}},
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", Labels: labels},
+				Spec: appsv1.DeploymentSpec{Replicas: ptr.To[int32](2)}},
+		).WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				patched[obj.GetName()]++
+				if obj.GetName() == "api" && patched["api"] == 1 {
+					return errors.New("conflict")
+				}
+				return c.Patch(ctx, obj, patch, opts...)
+			},
+		}).Build()}
+
+		// The status recording the first attempt is lost, so the retry makes
+		// the same decision, on a utilization that has moved meanwhile.
+		_, err := reconciler.executeScaleAction(context.Background(), profile, ScaleUpAction, 95)
+		Expect(err).To(HaveOccurred())
+		Expect(reconciler.executeScaleAction(context.Background(), profile, ScaleUpAction, 97.3)).Error().NotTo(HaveOccurred())
+		Expect(patched).To(Equal(map[string]int{"api": 2, "web": 1}))
+
+		for _, name := range []string{"api", "web"} {
+			var deployment appsv1.Deployment
+			Expect(reconciler.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: name}, &deployment)).To(Succeed())
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(3)), name)
+			Expect(deployment.Annotations).To(HaveKeyWithValue(optimizerv1.DecisionKeyAnnotation, decisionKey(profile, ScaleUpAction)))
+		}
+	})
+})
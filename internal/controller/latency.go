@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/prometheus/common/model"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// Sources of the latency histograms of a profile's latencyObjective.
+const (
+	LatencySourceIstio   = "Istio"
+	LatencySourceLinkerd = "Linkerd"
+)
+
+// DefaultLatencyPercentile is the percentile of a latencyObjective without one.
+const DefaultLatencyPercentile = 95
+
+// latencyScaleDownMargin is the share of the latency objective above which
+// scale-downs are held back, as removing a replica would threaten the
+// objective.
+const latencyScaleDownMargin = 0.8
+
+// latencyPromQL calculates the latency percentile in milliseconds, over the
+// last 5 minutes, of the requests to the profile's targets. Istio requests are
+// matched by workload or Service, Linkerd requests by the pods of the targets.
+func latencyPromQL(namespace string, objective *optimizerv1.LatencyObjective, workloads []string, podNameRegex string) string {
+	quantile := float64(latencyPercentile(objective)) / 100
+	if objective.Source == LatencySourceLinkerd {
+		return fmt.Sprintf(`histogram_quantile(%g, sum(rate(response_latency_ms_bucket{direction="inbound", namespace="%s", pod=~"%s"}[5m])) by (le))`,
+			quantile, namespace, podNameRegex)
+	}
+	return fmt.Sprintf(`histogram_quantile(%g, sum(rate(istio_request_duration_milliseconds_bucket{%s}[5m])) by (le))`,
+		quantile, istioDestination(namespace, objective.Service, workloads))
+}
+
+// latencyPercentile returns the percentile the objective applies to.
+func latencyPercentile(objective *optimizerv1.LatencyObjective) int32 {
+	if objective.Percentile == nil {
+		return DefaultLatencyPercentile
+	}
+	return *objective.Percentile
+}
+
+// observedLatency returns the latency percentile, in milliseconds, of the
+// requests to the profile's targets. It returns false when the profile has no
+// latencyObjective, no targets, or the targets received no requests.
+func observedLatency(ctx context.Context, c client.Reader, promAPI PrometheusClient, profile *optimizerv1.ResourceOptimizerProfile) (float64, bool, error) {
+	objective := profile.Spec.LatencyObjective
+	if objective == nil {
+		return 0, false, nil
+	}
+	workloads, _, err := listTargetReplicas(ctx, c, profile)
+	if err != nil || len(workloads) == 0 {
+		return 0, false, err
+	}
+	podNameRegex, err := targetPodNameRegex(ctx, c, profile)
+	if err != nil {
+		return 0, false, err
+	}
+
+	result, err := executePromQL(ctx, promAPI, latencyPromQL(profile.Namespace, objective, workloads, podNameRegex))
+	if err != nil {
+		return 0, false, err
+	}
+	vector, ok := result.(model.Vector)
+	if !ok {
+		return 0, false, fmt.Errorf("latency query returned %s, not a vector", result.Type())
+	}
+	// Without requests, the histograms are empty and the quantile is NaN.
+	if len(vector) == 0 || math.IsNaN(float64(vector[0].Value)) {
+		return 0, false, nil
+	}
+	return float64(vector[0].Value), true, nil
+}
+
+// latencyAction combines the action the CPU thresholds call for with the
+// profile's latencyObjective: latency above the objective scales up, and
+// latency close to it holds back a scale-down.
+func latencyAction(profile *optimizerv1.ResourceOptimizerProfile, latencyMillis float64, action string) string {
+	threshold := float64(profile.Spec.LatencyObjective.Threshold.Milliseconds())
+	switch {
+	case latencyMillis > threshold && directionAllows(profile, ScaleUpAction):
+		return ScaleUpAction
+	case action == ScaleDownAction && latencyMillis > latencyScaleDownMargin*threshold:
+		return DoNothing
+	}
+	return action
+}
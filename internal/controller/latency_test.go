@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+	"math"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/common/model"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Latency objective", func() {
+	It("should take the percentile of the mesh's latency histograms", func() {
+		istio := &optimizerv1.LatencyObjective{Source: LatencySourceIstio}
+		Expect(latencyPromQL("shop", istio, []string{"web"}, "web-[a-z0-9]+-[a-z0-9]+")).To(Equal(
+			`histogram_quantile(0.95, sum(rate(istio_request_duration_milliseconds_bucket{reporter="destination", destination_workload_namespace="shop", destination_workload=~"web"}[5m])) by (le))`))
+
+		linkerd := &optimizerv1.LatencyObjective{Source: LatencySourceLinkerd, Percentile: ptr.To[int32](99)}
+		Expect(latencyPromQL("shop", linkerd, []string{"web"}, "web-[a-z0-9]+-[a-z0-9]+")).To(Equal(
+			`histogram_quantile(0.99, sum(rate(response_latency_ms_bucket{direction="inbound", namespace="shop", pod=~"web-[a-z0-9]+-[a-z0-9]+"}[5m])) by (le))`))
+	})
+
+	It("should ignore targets without requests", func() {
+		labels := map[string]string{"app": "web"}
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", Labels: labels}},
+		).Build()
+		profile := &optimizerv1.ResourceOptimizerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Spec: optimizerv1.ResourceOptimizerProfileSpec{
+				Selector:         metav1.LabelSelector{MatchLabels: labels},
+				LatencyObjective: &optimizerv1.LatencyObjective{Source: LatencySourceIstio, Threshold: metav1.Duration{Duration: 300 * time.Millisecond}},
+			},
+		}
+
+		latency, ok, err := observedLatency(context.Background(), c, &mockPrometheusAPI{result: model.Vector{{Value: 420}}}, profile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(latency).To(Equal(420.0))
+
+		_, ok, err = observedLatency(context.Background(), c, &mockPrometheusAPI{result: model.Vector{{Value: model.SampleValue(math.NaN())}}}, profile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should scale up when the objective is missed and hold scale-downs close to it", func() {
+		profile := &optimizerv1.ResourceOptimizerProfile{Spec: optimizerv1.ResourceOptimizerProfileSpec{
+			LatencyObjective: &optimizerv1.LatencyObjective{Source: LatencySourceIstio, Threshold: metav1.Duration{Duration: 300 * time.Millisecond}},
+		}}
+		Expect(latencyAction(profile, 350, DoNothing)).To(Equal(ScaleUpAction))
+		Expect(latencyAction(profile, 350, ScaleDownAction)).To(Equal(ScaleUpAction))
+		Expect(latencyAction(profile, 250, ScaleDownAction)).To(Equal(DoNothing))
+		Expect(latencyAction(profile, 100, ScaleDownAction)).To(Equal(ScaleDownAction))
+
+		profile.Spec.Direction = DirectionDownOnly
+		Expect(latencyAction(profile, 350, DoNothing)).To(Equal(DoNothing))
+	})
+})
@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// MetricQueryData holds the placeholders of a profile's metricQuery.
+type MetricQueryData struct {
+	// Namespace is the namespace of the profile.
+	Namespace string
+	// Selector is the profile's matchLabels as PromQL matchers on the pod
+	// labels exported by kube-state-metrics, e.g. label_app="web".
+	Selector string
+	// PodRegex matches the names of the profile's pods.
+	PodRegex string
+}
+
+// invalidLabelChars are the characters kube-state-metrics replaces with an
+// underscore in the names of the labels it exports.
+var invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// ParseMetricQuery parses a metricQuery and renders it once with sample
+// placeholders, so that unknown placeholders are reported before the query is
+// run.
+func ParseMetricQuery(query string) (*template.Template, error) {
+	tmpl, err := template.New("metricQuery").Option("missingkey=error").Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	if err := tmpl.Execute(io.Discard, MetricQueryData{Namespace: "default", Selector: `label_app="web"`, PodRegex: "web-.*"}); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// metricQueryData returns the placeholders of the profile's metricQuery for
+// pods whose names match podNameRegex.
+func metricQueryData(profile *optimizerv1.ResourceOptimizerProfile, podNameRegex string) MetricQueryData {
+	keys := make([]string, 0, len(profile.Spec.Selector.MatchLabels))
+	for key := range profile.Spec.Selector.MatchLabels {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	matchers := make([]string, 0, len(keys))
+	for _, key := range keys {
+		matchers = append(matchers, "label_"+invalidLabelChars.ReplaceAllString(key, "_")+"="+strconv.Quote(profile.Spec.Selector.MatchLabels[key]))
+	}
+	return MetricQueryData{Namespace: profile.Namespace, Selector: strings.Join(matchers, ", "), PodRegex: podNameRegex}
+}
+
+// utilizationPromQL returns the query for the utilization of the profile's
+// pods whose names match podNameRegex: its metricQuery with the placeholders
+// substituted, or the built-in CPU query.
+func utilizationPromQL(profile *optimizerv1.ResourceOptimizerProfile, podNameRegex string) (string, error) {
+	if profile.Spec.MetricQuery == "" {
+		return cpuPromQL(profile.Namespace, podNameRegex), nil
+	}
+	tmpl, err := ParseMetricQuery(profile.Spec.MetricQuery)
+	if err != nil {
+		return "", fmt.Errorf("invalid metricQuery: %w", err)
+	}
+	var query strings.Builder
+	if err := tmpl.Execute(&query, metricQueryData(profile, podNameRegex)); err != nil {
+		return "", fmt.Errorf("invalid metricQuery: %w", err)
+	}
+	return query.String(), nil
+}
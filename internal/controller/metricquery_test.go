@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Metric query templates", func() {
+	labels := map[string]string{"app": "web", "app.kubernetes.io/part-of": "shop"}
+	var profile *optimizerv1.ResourceOptimizerProfile
+
+	BeforeEach(func() {
+		profile = &optimizerv1.ResourceOptimizerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+			Spec: optimizerv1.ResourceOptimizerProfileSpec{
+				Selector:      metav1.LabelSelector{MatchLabels: labels},
+				CPUThresholds: optimizerv1.ThresholdSpec{Min: 20, Max: 80},
+			},
+		}
+	})
+
+	It("should substitute the placeholders when the query is built", func() {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "team-a", Labels: labels}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-2", Namespace: "team-a", Labels: labels}},
+		).Build()
+		profile.Spec.MetricQuery = `sum by (pod) (rate(http_requests_total{namespace="{{.Namespace}}", pod=~"{{.PodRegex}}"}[5m]))` +
+			` * on (pod) group_left kube_pod_labels{ {{- .Selector -}} }`
+
+		query, err := buildPromQL(context.Background(), c, profile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(query).To(Equal(`sum by (pod) (rate(http_requests_total{namespace="team-a", pod=~"web-1|web-2"}[5m]))` +
+			` * on (pod) group_left kube_pod_labels{label_app="web", label_app_kubernetes_io_part_of="shop"}`))
+	})
+
+	It("should reject unknown placeholders", func() {
+		_, err := ParseMetricQuery(`up{namespace="{{.Namespace}}", pod=~"{{.Pods}}"}`)
+		Expect(err).To(MatchError(ContainSubstring("can't evaluate field Pods")))
+
+		profile.Spec.MetricQuery = `up{namespace="{{.Namespace"}`
+		_, err = utilizationPromQL(profile, "web-1")
+		Expect(err).To(MatchError(ContainSubstring("invalid metricQuery")))
+	})
+})
@@ -0,0 +1,411 @@
+package controller
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/version"
+)
+
+const (
+	// overflowLabelValue replaces the namespace and profile labels once the
+	// number of distinct profiles exceeds the configured limit.
+	overflowLabelValue = "_other"
+
+	// DefaultMaxMetricProfiles is the default number of distinct profiles that
+	// are exported with their own label values.
+	DefaultMaxMetricProfiles = 500
+
+	// DefaultMaxMetricTargets is the default number of targets per profile
+	// exported by the per-target gauges.
+	DefaultMaxMetricTargets = 50
+)
+
+// Reasons recorded when a planned action is not applied.
+const (
+	// SkipReasonCooldown is used while the profile's cooldown period is active.
+	SkipReasonCooldown = "cooldown"
+	// SkipReasonDryRun is used for profiles with the Recommend policy, which
+	// compute actions without applying them.
+	SkipReasonDryRun = "dry_run"
+	// SkipReasonPendingCapacity is used for scale-ups held back while the
+	// profile's pods are unschedulable or the Cluster Autoscaler is adding nodes.
+	SkipReasonPendingCapacity = "pending_capacity"
+	// SkipReasonAlertFiring is used for scale-downs and resize-downs held back
+	// while alerts matching the profile's holdOnAlerts are firing.
+	SkipReasonAlertFiring = "alert_firing"
+	// SkipReasonRolloutInProgress is used for targets of profiles with the
+	// Restart restartPolicy whose previous rollout has not finished.
+	SkipReasonRolloutInProgress = "rollout_in_progress"
+	// SkipReasonPolicyDenied is used for actions on targets denied by the
+	// profile's actionPolicy.
+	SkipReasonPolicyDenied = "policy_denied"
+	// SkipReasonProtectedWorkload is used for actions on targets matching the
+	// controller's protected workload selectors.
+	SkipReasonProtectedWorkload = "protected_workload"
+	// SkipReasonDuplicate is used for targets already patched for the same
+	// decision, e.g. by a reconcile whose status update failed.
+	SkipReasonDuplicate = "duplicate"
+	// SkipReasonOtherDimension is used for actions on targets whose replicas
+	// or CPU requests, the dimension the profile does not change, were changed
+	// within the profile's cooldown period.
+	SkipReasonOtherDimension = "other_dimension"
+	// SkipReasonStabilizing is used for scale actions of profiles with a
+	// behavior until they have been called for throughout its stabilization
+	// window.
+	SkipReasonStabilizing = "stabilizing"
+	// SkipReasonRateLimited is used for targets whose replicas the policies of
+	// the profile's behavior allow no further change in their period.
+	SkipReasonRateLimited = "rate_limited"
+	// SkipReasonDownwardDisabled is used for scale-downs and resize-downs while
+	// the controller runs with downward actions disabled.
+	SkipReasonDownwardDisabled = "downward_disabled"
+	// SkipReasonLowConfidence is used for actions of profiles whose confidence
+	// is below their minConfidence.
+	SkipReasonLowConfidence = "low_confidence"
+	// SkipReasonBackingOff is used for actions on targets whose last actions
+	// failed, until their backoff elapsed.
+	SkipReasonBackingOff = "backing_off"
+	// SkipReasonBusinessHours is used for scale-downs and resize-downs deferred
+	// while the business hours of the profile are open.
+	SkipReasonBusinessHours = "business_hours"
+	// SkipReasonStatefulSetScaleDownDisabled is used for scale-downs of
+	// StatefulSets by profiles that never scale them down.
+	SkipReasonStatefulSetScaleDownDisabled = "statefulset_scale_down_disabled"
+	// SkipReasonStatefulSetNotReady is used for scale-downs of StatefulSets
+	// whose replicas are not all ready, or whose last change the StatefulSet
+	// controller has not finished.
+	SkipReasonStatefulSetNotReady = "statefulset_not_ready"
+	// SkipReasonStatefulSetPartition is used for scale-downs of StatefulSets
+	// that would leave no replica at or above their rolling update partition.
+	SkipReasonStatefulSetPartition = "statefulset_partition"
+	// SkipReasonLowerPriorityFirst is used for scale-downs of targets held
+	// back while targets of a lower priority can still be scaled down.
+	SkipReasonLowerPriorityFirst = "lower_priority_first"
+	// SkipReasonPriorityProtected is used for scale-downs of targets whose
+	// PriorityClass is protected.
+	SkipReasonPriorityProtected = "priority_protected"
+	// SkipReasonTopologySpread is used for scale-downs that would break a
+	// topologySpreadConstraint of their target.
+	SkipReasonTopologySpread = "topology_spread"
+	// SkipReasonInsufficientCapacity is used for scale-ups of targets no node
+	// their pods may be scheduled on has room for.
+	SkipReasonInsufficientCapacity = "insufficient_capacity"
+	// SkipReasonSuspended is used for actions of profiles whose actions are
+	// suspended after failing repeatedly.
+	SkipReasonSuspended = "suspended"
+)
+
+// actionMetricLabels are the labels attached to every action counter.
+var actionMetricLabels = []string{"namespace", "profile", "target_kind"}
+
+var (
+	scaleUpActions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "k20s_scale_up_actions_total",
+		Help: "Total number of scale up actions taken",
+	}, actionMetricLabels)
+	scaleDownActions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "k20s_scale_down_actions_total",
+		Help: "Total number of scale down actions taken",
+	}, actionMetricLabels)
+	resizeUpActions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "k20s_resize_up_actions_total",
+		Help: "Total number of resize up actions taken",
+	}, actionMetricLabels)
+	resizeDownActions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "k20s_resize_down_actions_total",
+		Help: "Total number of resize down actions taken",
+	}, actionMetricLabels)
+	volumeExpansionActions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "k20s_volume_expansion_actions_total",
+		Help: "Total number of PersistentVolumeClaim expansions requested",
+	}, actionMetricLabels)
+
+	observedCPUUtilization = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "k20s_observed_cpu_utilization",
+		Help: "CPU utilization, as a percentage of requests, last observed for a profile",
+	}, []string{"namespace", "profile"})
+	recommendedCPUMillicores = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "k20s_recommended_cpu_millicores",
+		Help: "CPU request, in millicores, the controller recommends for a matched target",
+	}, []string{"namespace", "profile", "target_kind", "target"})
+	requestedCPUMillicores = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "k20s_requested_cpu_millicores",
+		Help: "CPU request, in millicores, a matched target currently sets for the container the controller resizes",
+	}, []string{"namespace", "profile", "target_kind", "target"})
+	cpuSavingsMillicores = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "k20s_cpu_savings_millicores",
+		Help: "CPU, in millicores, requested by all replicas of a matched target beyond the recommendation; negative when under-provisioned",
+	}, []string{"namespace", "profile", "target_kind", "target"})
+	estimatedCPUSavings = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "k20s_estimated_cpu_savings_per_hour",
+		Help: "Hourly price of the CPU requested by all replicas of a profile's targets beyond the recommendations; negative when under-provisioned",
+	}, []string{"namespace", "profile"})
+
+	annotatedRecommendations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "k20s_annotated_recommendations_total",
+		Help: "Total number of recommendations written to targets as annotations instead of being applied",
+	}, []string{"namespace", "profile", "action", "target_kind"})
+
+	skippedActions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "k20s_skipped_actions_total",
+		Help: "Total number of planned actions that were not applied, by reason",
+	}, []string{"namespace", "profile", "action", "reason"})
+
+	evictedPods = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "k20s_evicted_pods_total",
+		Help: "Total number of pods evicted to compact nodes after a resize down",
+	}, []string{"namespace", "profile"})
+
+	queryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "k20s_prometheus_query_errors_total",
+		Help: "Total number of failed Prometheus queries",
+	}, []string{"namespace", "profile"})
+	actionErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "k20s_action_errors_total",
+		Help: "Total number of actions that failed to apply",
+	}, []string{"namespace", "profile", "action"})
+	profileDegraded = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "k20s_profile_degraded",
+		Help: "Whether a profile currently reports the Degraded condition (1) or not (0)",
+	}, []string{"namespace", "profile"})
+
+	buildInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "k20s_build_info",
+		Help: "Always 1, labeled with the version of the running controller",
+	}, []string{"version", "commit", "build_date", "go_version"})
+
+	// profileMetricLabels bounds the number of profiles exported as label values.
+	profileMetricLabels = &profileLabelLimiter{seen: map[types.NamespacedName]struct{}{}}
+)
+
+func init() {
+	metrics.Registry.MustRegister(scaleUpActions, scaleDownActions, resizeUpActions, resizeDownActions, volumeExpansionActions,
+		observedCPUUtilization, recommendedCPUMillicores, requestedCPUMillicores, cpuSavingsMillicores,
+		estimatedCPUSavings, annotatedRecommendations, skippedActions, evictedPods,
+		queryErrors, actionErrors, profileDegraded, buildInfo)
+
+	info := version.Get()
+	buildInfo.WithLabelValues(info.Version, info.GitCommit, info.BuildDate, info.GoVersion).Set(1)
+}
+
+// profileScopedMetric is implemented by every metric vector that carries the
+// namespace and profile labels.
+type profileScopedMetric interface {
+	DeletePartialMatch(labels prometheus.Labels) int
+}
+
+// profileScopedMetrics lists the vectors cleaned up when a profile is deleted.
+var profileScopedMetrics = []profileScopedMetric{
+	scaleUpActions, scaleDownActions, resizeUpActions, resizeDownActions, volumeExpansionActions,
+	observedCPUUtilization, recommendedCPUMillicores, requestedCPUMillicores, cpuSavingsMillicores,
+	estimatedCPUSavings, annotatedRecommendations, skippedActions, evictedPods,
+	queryErrors, actionErrors, profileDegraded,
+}
+
+// targetRef identifies a workload managed by a profile.
+type targetRef struct {
+	Kind string
+	Name string
+}
+
+// actionCounter returns the counter that tracks the given action, or nil if the
+// action is not counted.
+func actionCounter(action string) *prometheus.CounterVec {
+	switch action {
+	case ScaleUpAction:
+		return scaleUpActions
+	case ScaleDownAction, ScaleToZeroAction:
+		return scaleDownActions
+	case ResizeUpAction:
+		return resizeUpActions
+	case ResizeDownAction:
+		return resizeDownActions
+	case ExpandVolumeAction:
+		return volumeExpansionActions
+	}
+	return nil
+}
+
+// profileLabelLimiter hands out namespace/profile label values for at most max
+// distinct profiles. Profiles beyond the limit share the overflow label value so
+// a large number of profiles cannot explode the series count.
+type profileLabelLimiter struct {
+	mu   sync.Mutex
+	seen map[types.NamespacedName]struct{}
+}
+
+// labels returns the namespace and profile label values to use for the given
+// profile. A max of zero or less disables the limit.
+func (l *profileLabelLimiter) labels(key types.NamespacedName, max int) (string, string) {
+	if !l.admit(key, max) {
+		return overflowLabelValue, overflowLabelValue
+	}
+	return key.Namespace, key.Name
+}
+
+// admit reports whether the profile owns its own label values, reserving a
+// slot for it if one is available.
+func (l *profileLabelLimiter) admit(key types.NamespacedName, max int) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.seen[key]; ok {
+		return true
+	}
+	if max > 0 && len(l.seen) >= max {
+		return false
+	}
+	l.seen[key] = struct{}{}
+	return true
+}
+
+// forget releases the label values held by the given profile and reports
+// whether the profile was being tracked.
+func (l *profileLabelLimiter) forget(key types.NamespacedName) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.seen[key]; !ok {
+		return false
+	}
+	delete(l.seen, key)
+	return true
+}
+
+// recordAction increments the counter for an action applied to a single target.
+func (r *ResourceOptimizerProfileReconciler) recordAction(profile *optimizerv1.ResourceOptimizerProfile, action, targetKind string) {
+	counter := actionCounter(action)
+	if counter == nil {
+		return
+	}
+	namespace, name := profileMetricLabels.labels(types.NamespacedName{Namespace: profile.Namespace, Name: profile.Name}, r.MaxMetricProfiles)
+	counter.WithLabelValues(namespace, name, targetKind).Inc()
+}
+
+// recordAnnotation counts a recommendation written to a target as an
+// annotation instead of being applied. It is kept apart from the action
+// counters since the target was not changed.
+func (r *ResourceOptimizerProfileReconciler) recordAnnotation(profile *optimizerv1.ResourceOptimizerProfile, action, targetKind string) {
+	namespace, name := profileMetricLabels.labels(types.NamespacedName{Namespace: profile.Namespace, Name: profile.Name}, r.MaxMetricProfiles)
+	annotatedRecommendations.WithLabelValues(namespace, name, action, targetKind).Inc()
+}
+
+// recordSkippedAction counts a planned action that was held back for the given
+// reason.
+func (r *ResourceOptimizerProfileReconciler) recordSkippedAction(profile *optimizerv1.ResourceOptimizerProfile, action, reason string) {
+	namespace, name := profileMetricLabels.labels(types.NamespacedName{Namespace: profile.Namespace, Name: profile.Name}, r.MaxMetricProfiles)
+	skippedActions.WithLabelValues(namespace, name, action, reason).Inc()
+}
+
+// recordEvictions counts the pods evicted after a resize down of a profile.
+func (r *ResourceOptimizerProfileReconciler) recordEvictions(profile *optimizerv1.ResourceOptimizerProfile, evicted int) {
+	if evicted == 0 {
+		return
+	}
+	namespace, name := profileMetricLabels.labels(types.NamespacedName{Namespace: profile.Namespace, Name: profile.Name}, r.MaxMetricProfiles)
+	evictedPods.WithLabelValues(namespace, name).Add(float64(evicted))
+}
+
+// recordQueryError counts a failed Prometheus query for a profile.
+func (r *ResourceOptimizerProfileReconciler) recordQueryError(profile *optimizerv1.ResourceOptimizerProfile) {
+	namespace, name := profileMetricLabels.labels(types.NamespacedName{Namespace: profile.Namespace, Name: profile.Name}, r.MaxMetricProfiles)
+	queryErrors.WithLabelValues(namespace, name).Inc()
+}
+
+// recordActionError counts an action that failed to apply.
+func (r *ResourceOptimizerProfileReconciler) recordActionError(profile *optimizerv1.ResourceOptimizerProfile, action string) {
+	namespace, name := profileMetricLabels.labels(types.NamespacedName{Namespace: profile.Namespace, Name: profile.Name}, r.MaxMetricProfiles)
+	actionErrors.WithLabelValues(namespace, name, action).Inc()
+}
+
+// recordDegraded exports whether the profile is currently degraded.
+func (r *ResourceOptimizerProfileReconciler) recordDegraded(profile *optimizerv1.ResourceOptimizerProfile, degraded bool) {
+	key := types.NamespacedName{Namespace: profile.Namespace, Name: profile.Name}
+	if !profileMetricLabels.admit(key, r.MaxMetricProfiles) {
+		return
+	}
+	var value float64
+	if degraded {
+		value = 1
+	}
+	profileDegraded.WithLabelValues(key.Namespace, key.Name).Set(value)
+}
+
+// recordObservedCPU exports the CPU utilization observed for a profile. Gauges
+// cannot be meaningfully aggregated, so profiles beyond the label limit are not
+// exported.
+func (r *ResourceOptimizerProfileReconciler) recordObservedCPU(profile *optimizerv1.ResourceOptimizerProfile, value float64) {
+	key := types.NamespacedName{Namespace: profile.Namespace, Name: profile.Name}
+	if !profileMetricLabels.admit(key, r.MaxMetricProfiles) {
+		return
+	}
+	observedCPUUtilization.WithLabelValues(key.Namespace, key.Name).Set(value)
+}
+
+// recordRecommendedCPU replaces the recommended, requested and saved CPU series
+// of a profile with the given per-target recommendations and current requests,
+// dropping targets that no longer match. Only the first MaxMetricTargets
+// targets, by kind and name, are exported.
+func (r *ResourceOptimizerProfileReconciler) recordRecommendedCPU(profile *optimizerv1.ResourceOptimizerProfile, recommendations map[targetRef]*resource.Quantity, current map[targetRef]TargetRecommendation) {
+	key := types.NamespacedName{Namespace: profile.Namespace, Name: profile.Name}
+	if !profileMetricLabels.admit(key, r.MaxMetricProfiles) {
+		return
+	}
+	match := prometheus.Labels{"namespace": key.Namespace, "profile": key.Name}
+	recommendedCPUMillicores.DeletePartialMatch(match)
+	requestedCPUMillicores.DeletePartialMatch(match)
+	cpuSavingsMillicores.DeletePartialMatch(match)
+	for _, target := range r.metricTargets(recommendations) {
+		request := recommendations[target]
+		recommendedCPUMillicores.WithLabelValues(key.Namespace, key.Name, target.Kind, target.Name).Set(float64(request.MilliValue()))
+		if t, ok := current[target]; ok {
+			requested := t.CurrentCPURequest.MilliValue()
+			requestedCPUMillicores.WithLabelValues(key.Namespace, key.Name, target.Kind, target.Name).Set(float64(requested))
+			cpuSavingsMillicores.WithLabelValues(key.Namespace, key.Name, target.Kind, target.Name).
+				Set(float64((requested - request.MilliValue()) * int64(t.Replicas)))
+		}
+	}
+}
+
+// metricTargets returns the targets exported by the per-target gauges: the
+// first MaxMetricTargets in kind and name order, so that a profile selecting
+// many workloads cannot explode the series count.
+func (r *ResourceOptimizerProfileReconciler) metricTargets(recommendations map[targetRef]*resource.Quantity) []targetRef {
+	targets := slices.SortedFunc(maps.Keys(recommendations), func(a, b targetRef) int {
+		return cmp.Or(cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Name, b.Name))
+	})
+	if r.MaxMetricTargets > 0 && len(targets) > r.MaxMetricTargets {
+		targets = targets[:r.MaxMetricTargets]
+	}
+	return targets
+}
+
+// recordEstimatedSavings exports the hourly price of the CPU a profile's
+// targets request beyond the recommendations.
+func (r *ResourceOptimizerProfileReconciler) recordEstimatedSavings(profile *optimizerv1.ResourceOptimizerProfile, savings float64) {
+	key := types.NamespacedName{Namespace: profile.Namespace, Name: profile.Name}
+	if !profileMetricLabels.admit(key, r.MaxMetricProfiles) {
+		return
+	}
+	estimatedCPUSavings.WithLabelValues(key.Namespace, key.Name).Set(savings)
+}
+
+// forgetProfileMetrics removes every series exported for a deleted profile.
+func forgetProfileMetrics(key types.NamespacedName) {
+	if !profileMetricLabels.forget(key) {
+		return
+	}
+	match := prometheus.Labels{"namespace": key.Namespace, "profile": key.Name}
+	for _, metric := range profileScopedMetrics {
+		metric.DeletePartialMatch(match)
+	}
+}
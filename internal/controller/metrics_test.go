@@ -0,0 +1,93 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/version"
+)
+
+var _ = Describe("Profile metric labels", func() {
+	var limiter *profileLabelLimiter
+
+	BeforeEach(func() {
+		limiter = &profileLabelLimiter{seen: map[types.NamespacedName]struct{}{}}
+	})
+
+	It("should aggregate profiles beyond the limit under the overflow label", func() {
+		first := types.NamespacedName{Namespace: "team-a", Name: "web"}
+		second := types.NamespacedName{Namespace: "team-b", Name: "api"}
+
+		ns, name := limiter.labels(first, 1)
+		Expect(ns).To(Equal("team-a"))
+		Expect(name).To(Equal("web"))
+
+		ns, name = limiter.labels(second, 1)
+		Expect(ns).To(Equal(overflowLabelValue))
+		Expect(name).To(Equal(overflowLabelValue))
+
+		// Profiles that already own a label set keep it.
+		ns, name = limiter.labels(first, 1)
+		Expect(ns).To(Equal("team-a"))
+		Expect(name).To(Equal("web"))
+	})
+
+	It("should free a slot when a profile is forgotten", func() {
+		first := types.NamespacedName{Namespace: "team-a", Name: "web"}
+		second := types.NamespacedName{Namespace: "team-b", Name: "api"}
+
+		limiter.labels(first, 1)
+		Expect(limiter.forget(first)).To(BeTrue())
+		Expect(limiter.forget(first)).To(BeFalse())
+
+		ns, name := limiter.labels(second, 1)
+		Expect(ns).To(Equal("team-b"))
+		Expect(name).To(Equal("api"))
+	})
+
+	It("should not limit label values when the limit is disabled", func() {
+		for _, name := range []string{"a", "b", "c"} {
+			_, got := limiter.labels(types.NamespacedName{Namespace: "default", Name: name}, 0)
+			Expect(got).To(Equal(name))
+		}
+	})
+})
+
+var _ = Describe("Per-target CPU gauges", func() {
+	It("should export at most MaxMetricTargets targets and drop those no longer selected", func() {
+		reconciler := &ResourceOptimizerProfileReconciler{MaxMetricTargets: 2}
+		profile := &optimizerv1.ResourceOptimizerProfile{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "many-targets"}}
+		match := prometheus.Labels{"namespace": "team-a", "profile": "many-targets"}
+		request := resource.MustParse("250m")
+		recommendations := map[targetRef]*resource.Quantity{
+			{Kind: "Deployment", Name: "c"}:  &request,
+			{Kind: "Deployment", Name: "a"}:  &request,
+			{Kind: "StatefulSet", Name: "b"}: &request,
+		}
+
+		reconciler.recordRecommendedCPU(profile, recommendations, nil)
+		Expect(testutil.ToFloat64(recommendedCPUMillicores.WithLabelValues("team-a", "many-targets", "Deployment", "a"))).To(Equal(250.0))
+		Expect(recommendedCPUMillicores.DeletePartialMatch(prometheus.Labels{"target_kind": "StatefulSet", "profile": "many-targets"})).To(BeZero())
+		Expect(recommendedCPUMillicores.DeletePartialMatch(match)).To(Equal(2))
+
+		reconciler.recordRecommendedCPU(profile, recommendations, nil)
+		delete(recommendations, targetRef{Kind: "Deployment", Name: "a"})
+		reconciler.recordRecommendedCPU(profile, recommendations, nil)
+		Expect(testutil.ToFloat64(recommendedCPUMillicores.WithLabelValues("team-a", "many-targets", "StatefulSet", "b"))).To(Equal(250.0))
+		Expect(recommendedCPUMillicores.DeletePartialMatch(match)).To(Equal(2))
+	})
+})
+
+var _ = Describe("Build info metric", func() {
+	It("should be set for the running binary", func() {
+		info := version.Get()
+		Expect(testutil.ToFloat64(buildInfo.WithLabelValues(info.Version, info.GitCommit, info.BuildDate, info.GoVersion))).To(Equal(1.0))
+		Expect(testutil.CollectAndCount(buildInfo)).To(Equal(1))
+	})
+})
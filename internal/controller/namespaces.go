@@ -0,0 +1,31 @@
+package controller
+
+import (
+	"fmt"
+	"slices"
+)
+
+// DefaultNamespaceDenylist lists the namespaces the controller never changes
+// workloads in unless told otherwise.
+var DefaultNamespaceDenylist = []string{"kube-system"}
+
+// NamespaceFilter decides which namespaces the controller may change
+// workloads in. The zero value allows every namespace.
+type NamespaceFilter struct {
+	// Allow lists the only namespaces allowed. Empty allows every namespace not
+	// denied.
+	Allow []string
+	// Deny lists namespaces never allowed, even when in Allow.
+	Deny []string
+}
+
+// Check returns an error explaining why namespace is not allowed, or nil.
+func (f NamespaceFilter) Check(namespace string) error {
+	if slices.Contains(f.Deny, namespace) {
+		return fmt.Errorf("namespace %q is on the controller's namespace denylist", namespace)
+	}
+	if len(f.Allow) > 0 && !slices.Contains(f.Allow, namespace) {
+		return fmt.Errorf("namespace %q is not on the controller's namespace allowlist", namespace)
+	}
+	return nil
+}
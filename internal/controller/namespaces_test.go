@@ -0,0 +1,19 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NamespaceFilter", func() {
+	It("should allow every namespace by default", func() {
+		Expect(NamespaceFilter{}.Check("kube-system")).To(Succeed())
+	})
+
+	It("should apply the denylist before the allowlist", func() {
+		filter := NamespaceFilter{Allow: []string{"team-a", "kube-system"}, Deny: DefaultNamespaceDenylist}
+		Expect(filter.Check("team-a")).To(Succeed())
+		Expect(filter.Check("team-b")).To(MatchError(ContainSubstring(`"team-b" is not on the controller's namespace allowlist`)))
+		Expect(filter.Check("kube-system")).To(MatchError(ContainSubstring(`"kube-system" is on the controller's namespace denylist`)))
+	})
+})
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ActionApprover decides whether planned actions may be applied.
+type ActionApprover interface {
+	// Approve returns whether the action described by input may be applied,
+	// and the reason given when it may not.
+	Approve(ctx context.Context, input map[string]any) (bool, string, error)
+}
+
+// OPAClient asks an Open Policy Agent for approval through its Data API. The
+// decision at URL must be either a bool or an object with an allow bool and an
+// optional reason string.
+type OPAClient struct {
+	// URL is the URL of the decision, e.g.
+	// http://opa:8181/v1/data/k20s/allow.
+	URL string
+	// Client is the HTTP client used to query OPA.
+	Client *http.Client
+}
+
+// NewOPAClient returns a client for the OPA decision at rawURL.
+func NewOPAClient(rawURL string) *OPAClient {
+	return &OPAClient{URL: rawURL, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Approve implements ActionApprover.
+func (o *OPAClient) Approve(ctx context.Context, input map[string]any) (bool, string, error) {
+	body, err := json.Marshal(map[string]any{"input": input})
+	if err != nil {
+		return false, "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := o.Client.Do(req)
+	if err != nil {
+		return false, "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return false, "", fmt.Errorf("OPA responded with %s", resp.Status)
+	}
+
+	var decision struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&decision); err != nil {
+		return false, "", fmt.Errorf("decoding decision: %w", err)
+	}
+	if len(decision.Result) == 0 {
+		// OPA omits the result of undefined decisions.
+		return false, "", fmt.Errorf("decision %s is undefined", o.URL)
+	}
+	var allowed bool
+	if err := json.Unmarshal(decision.Result, &allowed); err == nil {
+		return allowed, "", nil
+	}
+	var result struct {
+		Allow  bool   `json:"allow"`
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal(decision.Result, &result); err != nil {
+		return false, "", fmt.Errorf("decision is neither a bool nor an object with allow: %w", err)
+	}
+	return result.Allow, result.Reason, nil
+}
+
+// approvalDenied asks the approver about an action and returns why it is
+// denied, or "" if it is approved. Actions are denied while the approver
+// cannot be asked, since nothing may be applied without its approval.
+func approvalDenied(ctx context.Context, approver ActionApprover, input map[string]any) string {
+	allowed, reason, err := approver.Approve(ctx, input)
+	switch {
+	case err != nil:
+		log.FromContext(ctx).Error(err, "unable to get approval, denying action")
+		return fmt.Sprintf("approval could not be checked: %v", err)
+	case !allowed && reason != "":
+		return "not approved: " + reason
+	case !allowed:
+		return "not approved"
+	}
+	return ""
+}
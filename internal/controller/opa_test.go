@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/common/model"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/notify"
+)
+
+// fakeApprover answers every approval with the same decision, or error.
+type fakeApprover struct {
+	allowed bool
+	reason  string
+	err     error
+}
+
+func (f fakeApprover) Approve(context.Context, map[string]any) (bool, string, error) {
+	return f.allowed, f.reason, f.err
+}
+
+// recordingNotifier records the events it is sent.
+type recordingNotifier struct {
+	events []notify.Event
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, event notify.Event) error {
+	n.events = append(n.events, event)
+	return nil
+}
+
+var _ = Describe("OPA approval", func() {
+	decide := func(result string) (bool, string, error) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Method).To(Equal(http.MethodPost))
+			Expect(r.URL.Path).To(Equal("/v1/data/k20s/allow"))
+			var body struct {
+				Input struct {
+					Action string `json:"action"`
+					Target struct {
+						Kind string `json:"kind"`
+						Name string `json:"name"`
+					} `json:"target"`
+				} `json:"input"`
+			}
+			Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+			Expect(body.Input.Action).To(Equal(ScaleDownAction))
+			Expect(body.Input.Target.Kind).To(Equal("Deployment"))
+			Expect(body.Input.Target.Name).To(Equal("checkout"))
+			_, _ = w.Write([]byte(result))
+		}))
+		defer server.Close()
+
+		profile := &optimizerv1.ResourceOptimizerProfile{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"}}
+		target := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "checkout"}}
+		return NewOPAClient(server.URL+"/v1/data/k20s/allow").Approve(context.Background(),
+			actionInput(profile, ScaleDownAction, 10, time.Now(), "Deployment", target))
+	}
+
+	It("should accept bool and object decisions", func() {
+		allowed, _, err := decide(`{"result": true}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(allowed).To(BeTrue())
+
+		allowed, reason, err := decide(`{"result": {"allow": false, "reason": "change freeze"}}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(allowed).To(BeFalse())
+		Expect(reason).To(Equal("change freeze"))
+	})
+
+	It("should report undefined decisions", func() {
+		_, _, err := decide(`{}`)
+		Expect(err).To(MatchError(ContainSubstring("is undefined")))
+	})
+
+	It("should deny actions that are not approved or cannot be checked", func() {
+		Expect(approvalDenied(context.Background(), fakeApprover{allowed: true}, nil)).To(BeEmpty())
+		Expect(approvalDenied(context.Background(), fakeApprover{reason: "change freeze"}, nil)).To(Equal("not approved: change freeze"))
+		Expect(approvalDenied(context.Background(), fakeApprover{err: errors.New("connection refused")}, nil)).
+			To(Equal("approval could not be checked: connection refused"))
+	})
+
+	It("should skip actions while OPA cannot be reached", func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(optimizerv1.AddToScheme(scheme)).To(Succeed())
+		key := types.NamespacedName{Namespace: "team-a", Name: "web"}
+		web := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", Labels: map[string]string{"app": "web"}},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name:      "app",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")}},
+			}}}}},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&optimizerv1.ResourceOptimizerProfile{}).WithObjects(
+			&optimizerv1.ResourceOptimizerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+				Spec: optimizerv1.ResourceOptimizerProfileSpec{
+					Selector:           metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+					OptimizationPolicy: "Resize",
+					CPUThresholds:      optimizerv1.ThresholdSpec{Min: 30, Max: 70},
+				},
+			},
+			web,
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "team-a", Labels: map[string]string{"app": "web"}}},
+		).Build()
+		notifier := &recordingNotifier{}
+		reconciler := &ResourceOptimizerProfileReconciler{
+			Client:        c,
+			Scheme:        scheme,
+			PrometheusAPI: &mockPrometheusAPI{result: model.Vector{{Value: 90}}},
+			Approver:      fakeApprover{err: errors.New("connection refused")},
+			Notifier:      notifier,
+		}
+
+		_, err := reconciler.Reconcile(context.Background(), reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(context.Background(), key, web)).To(Succeed())
+		Expect(web.Spec.Template.Spec.Containers[0].Resources.Requests.Cpu().String()).To(Equal("500m"))
+		var profile optimizerv1.ResourceOptimizerProfile
+		Expect(c.Get(context.Background(), key, &profile)).To(Succeed())
+		Expect(profile.Status.LastAction).To(BeNil())
+		Expect(notifier.events).To(BeEmpty())
+	})
+})
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+	"strings"
+
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// Policies for the results of Prometheus queries that returned warnings, such
+// as the partial responses of Thanos when some of its stores did not answer.
+const (
+	// PartialResponseFail fails the query.
+	PartialResponseFail = "Fail"
+	// PartialResponseWarn logs the warnings and uses the result.
+	PartialResponseWarn = "Warn"
+	// PartialResponseSkip takes no action on a partial utilization until a
+	// later reconcile gets a complete one.
+	PartialResponseSkip = "Skip"
+)
+
+// PartialResponseError is returned for a query that returned warnings under
+// the Fail and Skip policies.
+type PartialResponseError struct {
+	Warnings prometheusv1.Warnings
+}
+
+func (e *PartialResponseError) Error() string {
+	return "prometheus returned a partial response: " + strings.Join(e.Warnings, "; ")
+}
+
+// partialResponsePolicyKey is the context key of the partial response policy.
+type partialResponsePolicyKey struct{}
+
+// withPartialResponsePolicy returns a context under which the results of
+// Prometheus queries that returned warnings are handled according to policy.
+func withPartialResponsePolicy(ctx context.Context, policy string) context.Context {
+	return context.WithValue(ctx, partialResponsePolicyKey{}, policy)
+}
+
+// partialResponsePolicy returns the profile's partialResponsePolicy, or the
+// controller's default.
+func (r *ResourceOptimizerProfileReconciler) partialResponsePolicy(profile *optimizerv1.ResourceOptimizerProfile) string {
+	if profile.Spec.PartialResponsePolicy != "" {
+		return profile.Spec.PartialResponsePolicy
+	}
+	if r.PartialResponsePolicy != "" {
+		return r.PartialResponsePolicy
+	}
+	return PartialResponseWarn
+}
+
+// checkWarnings applies the partial response policy set by
+// withPartialResponsePolicy, Warn by default, to the warnings of a query.
+func checkWarnings(ctx context.Context, warnings prometheusv1.Warnings) error {
+	if len(warnings) == 0 {
+		return nil
+	}
+	switch policy, _ := ctx.Value(partialResponsePolicyKey{}).(string); policy {
+	case PartialResponseFail, PartialResponseSkip:
+		return &PartialResponseError{Warnings: warnings}
+	}
+	log.FromContext(ctx).Info("Prometheus query returned warnings, using its result", "warnings", warnings)
+	return nil
+}
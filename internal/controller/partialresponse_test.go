@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Partial responses", func() {
+	partial := prometheusv1.Warnings{"No StoreAPIs matched for this query"}
+
+	It("should use the result of a partial response under the Warn policy", func() {
+		promAPI := &mockPrometheusAPI{result: model.Vector{{Value: 50}}, warnings: partial}
+
+		result, err := executePromQL(context.Background(), promAPI, "up")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(model.Vector{{Value: 50}}))
+
+		ctx := withPartialResponsePolicy(context.Background(), PartialResponseWarn)
+		_, err = executePromQL(ctx, promAPI, "up")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should fail partial responses under the Fail and Skip policies", func() {
+		promAPI := &mockPrometheusAPI{result: model.Vector{{Value: 50}}, warnings: partial}
+		for _, policy := range []string{PartialResponseFail, PartialResponseSkip} {
+			_, err := executePromQL(withPartialResponsePolicy(context.Background(), policy), promAPI, "up")
+			var partialErr *PartialResponseError
+			Expect(err).To(BeAssignableToTypeOf(partialErr))
+			Expect(err).To(MatchError("prometheus returned a partial response: No StoreAPIs matched for this query"))
+		}
+
+		promAPI.warnings = nil
+		_, err := executePromQL(withPartialResponsePolicy(context.Background(), PartialResponseFail), promAPI, "up")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should prefer the profile's policy over the controller's", func() {
+		reconciler := &ResourceOptimizerProfileReconciler{}
+		profile := &optimizerv1.ResourceOptimizerProfile{}
+		Expect(reconciler.partialResponsePolicy(profile)).To(Equal(PartialResponseWarn))
+
+		reconciler.PartialResponsePolicy = PartialResponseFail
+		Expect(reconciler.partialResponsePolicy(profile)).To(Equal(PartialResponseFail))
+
+		profile.Spec.PartialResponsePolicy = PartialResponseSkip
+		Expect(reconciler.partialResponsePolicy(profile)).To(Equal(PartialResponseSkip))
+	})
+
+	It("should report skipped reconciles on the Degraded condition", func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(optimizerv1.AddToScheme(scheme)).To(Succeed())
+		key := types.NamespacedName{Namespace: "team-a", Name: "web"}
+		web := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", Labels: map[string]string{"app": "web"}},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](2)},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&optimizerv1.ResourceOptimizerProfile{}).WithObjects(
+			&optimizerv1.ResourceOptimizerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+				Spec: optimizerv1.ResourceOptimizerProfileSpec{
+					Selector:              metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+					OptimizationPolicy:    "Scale",
+					CPUThresholds:         optimizerv1.ThresholdSpec{Min: 30, Max: 70},
+					PartialResponsePolicy: PartialResponseSkip,
+				},
+			},
+			web,
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "team-a", Labels: map[string]string{"app": "web"}}},
+		).Build()
+		reconciler := &ResourceOptimizerProfileReconciler{
+			Client:        c,
+			Scheme:        scheme,
+			PrometheusAPI: &mockPrometheusAPI{result: model.Vector{{Value: 90}}, warnings: partial},
+		}
+
+		result, err := reconciler.Reconcile(context.Background(), reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(ReconcileInterval))
+		Expect(c.Get(context.Background(), key, web)).To(Succeed())
+		Expect(web.Spec.Replicas).To(HaveValue(Equal(int32(2))))
+		var profile optimizerv1.ResourceOptimizerProfile
+		Expect(c.Get(context.Background(), key, &profile)).To(Succeed())
+		condition := meta.FindStatusCondition(profile.Status.Conditions, optimizerv1.ConditionDegraded)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("PartialResponse"))
+		Expect(condition.Message).To(ContainSubstring("No StoreAPIs matched for this query"))
+	})
+})
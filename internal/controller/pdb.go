@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	metav1ac "k8s.io/client-go/applyconfigurations/meta/v1"
+	policyv1ac "k8s.io/client-go/applyconfigurations/policy/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;patch;delete
+
+// pdbFieldOwner is the server-side apply field manager of the
+// PodDisruptionBudgets maintained for targets.
+const pdbFieldOwner = "k20s"
+
+// pdbName returns the name of the PodDisruptionBudget maintained for a target.
+func pdbName(target targetRef) string {
+	return fmt.Sprintf("k20s-%s-%s", strings.ToLower(target.Kind), target.Name)
+}
+
+// managedPDB reports whether a PodDisruptionBudget is maintained for a target
+// of profile.
+func managedPDB(pdb *policyv1.PodDisruptionBudget, profile *optimizerv1.ResourceOptimizerProfile) bool {
+	return pdb.Labels[optimizerv1.ManagedLabel] == "true" && pdb.Annotations[optimizerv1.ManagedByProfileAnnotation] == profile.Name
+}
+
+// coveredByOwnPDB reports whether a PodDisruptionBudget not maintained by the
+// controller selects pods with the given labels. A pod may only be covered by
+// a single PodDisruptionBudget, or it cannot be evicted at all.
+func coveredByOwnPDB(pdbs []policyv1.PodDisruptionBudget, podLabels map[string]string) bool {
+	for i := range pdbs {
+		pdb := &pdbs[i]
+		if pdb.Labels[optimizerv1.ManagedLabel] == "true" || pdb.Spec.Selector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err == nil && !selector.Empty() && selector.Matches(labels.Set(podLabels)) {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcilePodDisruptionBudgets maintains a PodDisruptionBudget keeping
+// minReplicas pods of each target of a profile with managePodDisruptionBudgets
+// available, and deletes those of targets the profile no longer selects. The
+// PodDisruptionBudgets are owned by the profile, so they go with it.
+func (r *ResourceOptimizerProfileReconciler) reconcilePodDisruptionBudgets(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile) error {
+	var pdbs policyv1.PodDisruptionBudgetList
+	if err := r.List(ctx, &pdbs, client.InNamespace(profile.Namespace)); err != nil {
+		return err
+	}
+
+	wanted := map[string]bool{}
+	if profile.Spec.ManagePodDisruptionBudgets {
+		selector := labels.Set(profile.Spec.Selector.MatchLabels).AsSelector()
+		var deployments appsv1.DeploymentList
+		if err := r.List(ctx, &deployments, &client.ListOptions{LabelSelector: selector, Namespace: profile.Namespace}); err != nil {
+			return err
+		}
+		var statefulSets appsv1.StatefulSetList
+		if err := r.List(ctx, &statefulSets, &client.ListOptions{LabelSelector: selector, Namespace: profile.Namespace}); err != nil {
+			return err
+		}
+		apply := func(target targetRef, podSelector *metav1.LabelSelector, podLabels map[string]string) error {
+			if podSelector == nil || coveredByOwnPDB(pdbs.Items, podLabels) {
+				return nil
+			}
+			wanted[pdbName(target)] = true
+			return r.applyPDB(ctx, profile, target, podSelector)
+		}
+		for _, deployment := range deployments.Items {
+			target := targetRef{Kind: "Deployment", Name: deployment.Name}
+			if err := apply(target, deployment.Spec.Selector, deployment.Spec.Template.Labels); err != nil {
+				return fmt.Errorf("applying the PodDisruptionBudget of Deployment %s: %w", deployment.Name, err)
+			}
+		}
+		for _, statefulSet := range statefulSets.Items {
+			target := targetRef{Kind: "StatefulSet", Name: statefulSet.Name}
+			if err := apply(target, statefulSet.Spec.Selector, statefulSet.Spec.Template.Labels); err != nil {
+				return fmt.Errorf("applying the PodDisruptionBudget of StatefulSet %s: %w", statefulSet.Name, err)
+			}
+		}
+	}
+
+	for i := range pdbs.Items {
+		pdb := &pdbs.Items[i]
+		if !managedPDB(pdb, profile) || wanted[pdb.Name] {
+			continue
+		}
+		if err := r.Delete(ctx, pdb); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("deleting PodDisruptionBudget %s: %w", pdb.Name, err)
+		}
+		log.FromContext(ctx).Info("Deleted PodDisruptionBudget", "podDisruptionBudget", pdb.Name)
+	}
+	return nil
+}
+
+// applyPDB applies the PodDisruptionBudget of a target.
+func (r *ResourceOptimizerProfileReconciler) applyPDB(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, target targetRef, podSelector *metav1.LabelSelector) error {
+	selector := metav1ac.LabelSelector().WithMatchLabels(podSelector.MatchLabels)
+	for _, requirement := range podSelector.MatchExpressions {
+		selector.WithMatchExpressions(metav1ac.LabelSelectorRequirement().
+			WithKey(requirement.Key).WithOperator(requirement.Operator).WithValues(requirement.Values...))
+	}
+	pdb := policyv1ac.PodDisruptionBudget(pdbName(target), profile.Namespace).
+		WithLabels(map[string]string{optimizerv1.ManagedLabel: "true"}).
+		WithAnnotations(map[string]string{optimizerv1.ManagedByProfileAnnotation: profile.Name}).
+		WithOwnerReferences(metav1ac.OwnerReference().
+			WithAPIVersion(optimizerv1.GroupVersion.String()).
+			WithKind("ResourceOptimizerProfile").
+			WithName(profile.Name).
+			WithUID(profile.UID).
+			WithController(true).
+			WithBlockOwnerDeletion(true)).
+		WithSpec(policyv1ac.PodDisruptionBudgetSpec().
+			WithMinAvailable(intstr.FromInt32(minReplicas(profile))).
+			WithSelector(selector))
+	return r.Apply(ctx, pdb, client.FieldOwner(pdbFieldOwner), client.ForceOwnership)
+}
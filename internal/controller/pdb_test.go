@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("PodDisruptionBudgets", func() {
+	var (
+		reconciler *ResourceOptimizerProfileReconciler
+		profile    *optimizerv1.ResourceOptimizerProfile
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(optimizerv1.AddToScheme(scheme)).To(Succeed())
+		deployment := func(name string) *appsv1.Deployment {
+			podLabels := map[string]string{"app": name}
+			return &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", Labels: map[string]string{"tier": "web"}},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+					Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: podLabels}},
+				},
+			}
+		}
+		reconciler = &ResourceOptimizerProfileReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				deployment("web"), deployment("api"),
+				&policyv1.PodDisruptionBudget{
+					ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "shop"},
+					Spec: policyv1.PodDisruptionBudgetSpec{
+						MinAvailable: ptr.To(intstr.FromInt32(1)),
+						Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+					},
+				},
+			).Build(),
+		}
+		profile = &optimizerv1.ResourceOptimizerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", UID: "uid"},
+			Spec: optimizerv1.ResourceOptimizerProfileSpec{
+				Selector:                   metav1.LabelSelector{MatchLabels: map[string]string{"tier": "web"}},
+				MinReplicas:                ptr.To[int32](2),
+				ManagePodDisruptionBudgets: true,
+			},
+		}
+	})
+
+	It("should maintain a PodDisruptionBudget for targets without one of their own", func() {
+		ctx := context.Background()
+		Expect(reconciler.reconcilePodDisruptionBudgets(ctx, profile)).To(Succeed())
+
+		var pdb policyv1.PodDisruptionBudget
+		Expect(reconciler.Get(ctx, types.NamespacedName{Namespace: "shop", Name: "k20s-deployment-web"}, &pdb)).To(Succeed())
+		Expect(pdb.Spec.MinAvailable).To(Equal(ptr.To(intstr.FromInt32(2))))
+		Expect(pdb.Spec.Selector.MatchLabels).To(Equal(map[string]string{"app": "web"}))
+		Expect(pdb.OwnerReferences).To(HaveLen(1))
+		Expect(pdb.OwnerReferences[0].Name).To(Equal("web"))
+
+		// The api Deployment is already covered by a PodDisruptionBudget.
+		err := reconciler.Get(ctx, types.NamespacedName{Namespace: "shop", Name: "k20s-deployment-api"}, &pdb)
+		Expect(client.IgnoreNotFound(err)).To(Succeed())
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should delete its PodDisruptionBudgets once disabled", func() {
+		ctx := context.Background()
+		Expect(reconciler.reconcilePodDisruptionBudgets(ctx, profile)).To(Succeed())
+
+		profile.Spec.ManagePodDisruptionBudgets = false
+		Expect(reconciler.reconcilePodDisruptionBudgets(ctx, profile)).To(Succeed())
+		var pdbs policyv1.PodDisruptionBudgetList
+		Expect(reconciler.List(ctx, &pdbs, client.InNamespace("shop"))).To(Succeed())
+		Expect(pdbs.Items).To(HaveLen(1))
+		Expect(pdbs.Items[0].Name).To(Equal("api"))
+	})
+})
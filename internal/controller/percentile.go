@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// Defaults of a profile's percentileRecommendation.
+const (
+	DefaultPercentileWindow   = 7 * 24 * time.Hour
+	DefaultPercentile         = 95
+	DefaultPercentileHeadroom = 15
+)
+
+// Usage is read in steps of percentileMinStep, or coarser for long windows so
+// that no series has more than percentileMaxPoints points, below the limit of
+// Prometheus.
+const (
+	percentileMinStep   = 5 * time.Minute
+	percentileMaxPoints = 10000
+)
+
+// percentileStep returns the resolution usage over a window is read at.
+func percentileStep(window time.Duration) time.Duration {
+	return max(percentileMinStep, window/percentileMaxPoints)
+}
+
+// cpuUsagePromQL calculates the CPU usage in cores, averaged over 5 minutes, of
+// a container in every pod matching podNameRegex.
+func cpuUsagePromQL(namespace, podNameRegex, container string) string {
+	return fmt.Sprintf(`sum(rate(container_cpu_usage_seconds_total{namespace="%s", pod=~"%s", container="%s"}[5m])) by (pod)`,
+		namespace, podNameRegex, container)
+}
+
+// percentileOf returns the nearest-rank percentile of values, which it sorts.
+func percentileOf(values []float64, percentile float64) float64 {
+	slices.Sort(values)
+	rank := int(math.Ceil(percentile / 100 * float64(len(values))))
+	return values[min(max(rank, 1), len(values))-1]
+}
+
+// listPercentileRecommendations sizes the CPU request of the container the
+// Resize policy operates on, in every target of the profile, to a percentile of
+// its usage over the profile's window plus headroom. Targets without usage in
+// the window get no recommendation.
+func listPercentileRecommendations(ctx context.Context, c client.Reader, promAPI PrometheusRangeClient, profile *optimizerv1.ResourceOptimizerProfile, now time.Time) (sourceRecommendations, error) {
+	window, percentile, headroom := DefaultPercentileWindow, float64(DefaultPercentile), float64(DefaultPercentileHeadroom)
+	if spec := profile.Spec.PercentileRecommendation; spec != nil {
+		if spec.Window != nil && spec.Window.Duration > 0 {
+			window = spec.Window.Duration
+		}
+		if spec.Percentile != nil {
+			percentile = float64(*spec.Percentile)
+		}
+		if spec.HeadroomPercent != nil {
+			headroom = float64(*spec.HeadroomPercent)
+		}
+	}
+	r := prometheusv1.Range{Start: now.Add(-window), End: now, Step: percentileStep(window)}
+
+	listOpts := &client.ListOptions{LabelSelector: labels.Set(profile.Spec.Selector.MatchLabels).AsSelector(), Namespace: profile.Namespace}
+	var deployments appsv1.DeploymentList
+	if err := c.List(ctx, &deployments, listOpts); err != nil {
+		return nil, err
+	}
+	var statefulSets appsv1.StatefulSetList
+	if err := c.List(ctx, &statefulSets, listOpts); err != nil {
+		return nil, err
+	}
+
+	recommendations := sourceRecommendations{}
+	recommend := func(target targetRef, container string) error {
+		queryCtx, cancel := queryContext(ctx)
+		defer cancel()
+		result, warnings, err := promAPI.QueryRange(queryCtx, cpuUsagePromQL(profile.Namespace, podNamePattern(target), container), r)
+		if err != nil {
+			return fmt.Errorf("querying the CPU usage of %s %s: %w", target.Kind, target.Name, queryError(ctx, err))
+		}
+		if err := checkWarnings(ctx, warnings); err != nil {
+			return fmt.Errorf("querying the CPU usage of %s %s: %w", target.Kind, target.Name, err)
+		}
+		matrix, ok := result.(model.Matrix)
+		if !ok {
+			return fmt.Errorf("prometheus range query returned a %s, not a matrix", result.Type())
+		}
+		var values []float64
+		for _, series := range matrix {
+			for _, point := range series.Values {
+				values = append(values, float64(point.Value))
+			}
+		}
+		if len(values) == 0 {
+			return nil
+		}
+		usage := percentileOf(values, percentile)
+		milliCores := int64(math.Ceil(usage * (1 + headroom/100) * 1000))
+		recommendations.set(target, container, *resource.NewMilliQuantity(max(milliCores, 1), resource.DecimalSI))
+		return nil
+	}
+	for _, deployment := range deployments.Items {
+		if container, _, ok := firstCPURequest(profile, deployment.Annotations, deployment.Spec.Template.Spec.Containers); ok {
+			if err := recommend(targetRef{Kind: "Deployment", Name: deployment.Name}, container); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, ss := range statefulSets.Items {
+		if container, _, ok := firstCPURequest(profile, ss.Annotations, ss.Spec.Template.Spec.Containers); ok {
+			if err := recommend(targetRef{Kind: "StatefulSet", Name: ss.Name}, container); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return recommendations, nil
+}
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// rangeRecorder answers every range query with the same result and remembers
+// the queries.
+type rangeRecorder struct {
+	result  model.Matrix
+	queries []string
+	ranges  []prometheusv1.Range
+}
+
+func (r *rangeRecorder) QueryRange(_ context.Context, query string, rng prometheusv1.Range, _ ...prometheusv1.Option) (model.Value, prometheusv1.Warnings, error) {
+	r.queries = append(r.queries, query)
+	r.ranges = append(r.ranges, rng)
+	return r.result, nil, nil
+}
+
+var _ = Describe("Percentile recommendation source", func() {
+	now := time.Date(2025, time.October, 17, 12, 0, 0, 0, time.UTC)
+	labels := map[string]string{"app": "web"}
+
+	var (
+		c       client.Client
+		profile *optimizerv1.ResourceOptimizerProfile
+		prom    *rangeRecorder
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "team-a", Labels: labels},
+			Spec: appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "sidecar"},
+				{Name: "postgres", Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}}},
+			}}}},
+		}).Build()
+		profile = &optimizerv1.ResourceOptimizerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "team-a"},
+			Spec: optimizerv1.ResourceOptimizerProfileSpec{
+				Selector:             metav1.LabelSelector{MatchLabels: labels},
+				CPUThresholds:        optimizerv1.ThresholdSpec{Min: 20, Max: 80},
+				OptimizationPolicy:   "Resize",
+				RecommendationSource: RecommendationSourcePercentile,
+			},
+		}
+		var values []model.SamplePair
+		for i := 1; i <= 100; i++ {
+			values = append(values, model.SamplePair{Value: model.SampleValue(float64(i) / 100)})
+		}
+		prom = &rangeRecorder{result: model.Matrix{
+			{Metric: model.Metric{"pod": "db-0"}, Values: values[:50]},
+			{Metric: model.Metric{"pod": "db-1"}, Values: values[50:]},
+		}}
+	})
+
+	It("should size requests to the percentile of the usage over the window plus headroom", func() {
+		recommendations, err := listPercentileRecommendations(context.Background(), c, prom, profile, now)
+		Expect(err).NotTo(HaveOccurred())
+		// 0.95 cores plus 15%.
+		request, ok := recommendations.cpuRequest(targetRef{Kind: "StatefulSet", Name: "db"}, "postgres")
+		Expect(ok).To(BeTrue())
+		Expect(request.String()).To(Equal("1093m"))
+
+		Expect(prom.queries).To(ConsistOf(ContainSubstring(`pod=~"db-[0-9]+", container="postgres"`)))
+		Expect(prom.ranges[0].Start).To(Equal(now.Add(-DefaultPercentileWindow)))
+		Expect(prom.ranges[0].Step).To(Equal(5 * time.Minute))
+	})
+
+	It("should use the profile's window, percentile and headroom", func() {
+		profile.Spec.PercentileRecommendation = &optimizerv1.PercentileRecommendation{
+			Window:          &metav1.Duration{Duration: 90 * 24 * time.Hour},
+			Percentile:      ptr.To[int32](50),
+			HeadroomPercent: ptr.To[int32](0),
+		}
+		recommendations, err := listPercentileRecommendations(context.Background(), c, prom, profile, now)
+		Expect(err).NotTo(HaveOccurred())
+		request, _ := recommendations.cpuRequest(targetRef{Kind: "StatefulSet", Name: "db"}, "postgres")
+		Expect(request.String()).To(Equal("500m"))
+		Expect(prom.ranges[0].Step).To(Equal(90 * 24 * time.Hour / percentileMaxPoints))
+	})
+
+	It("should leave targets without usage to the profile's own recommendation", func() {
+		prom.result = model.Matrix{}
+		recommendations, err := listPercentileRecommendations(context.Background(), c, prom, profile, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recommendations).To(BeEmpty())
+
+		// Without Prometheus, as in simulations, the source is not used.
+		Expect(listSourceRecommendations(context.Background(), c, nil, profile)).To(BeNil())
+	})
+})
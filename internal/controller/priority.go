@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// +kubebuilder:rbac:groups=scheduling.k8s.io,resources=priorityclasses,verbs=get;list;watch
+
+// DefaultProtectedPriority is the default of spec.priorities.protectedPriority,
+// the value of the high-priority PriorityClass of the Kubernetes
+// documentation.
+const DefaultProtectedPriority = 1000000
+
+// podPriority returns the priority the pods of a template are admitted with:
+// the value of their PriorityClass, or of the global default PriorityClass
+// when they name none.
+func podPriority(template *corev1.PodTemplateSpec, classes []schedulingv1.PriorityClass) int32 {
+	if template.Spec.Priority != nil {
+		return *template.Spec.Priority
+	}
+	for _, class := range classes {
+		if template.Spec.PriorityClassName == "" && class.GlobalDefault ||
+			template.Spec.PriorityClassName != "" && class.Name == template.Spec.PriorityClassName {
+			return class.Value
+		}
+	}
+	return 0
+}
+
+// scaleDownOrder decides which targets of a scale-down are scaled down now.
+// Targets are scaled down lowest priority first: a target waits while a
+// target of a lower priority can still be scaled down. Targets from the
+// protected priority on are never scaled down unless the profile opts in.
+type scaleDownOrder struct {
+	priorities map[targetRef]int32
+	protected  int32
+	// scaleDownProtected is set when protected targets may be scaled down.
+	scaleDownProtected bool
+	// next is the lowest priority of the targets that can still be scaled
+	// down, nil when none can.
+	next *int32
+}
+
+// newScaleDownOrder orders the targets of a scale-down by the priority of
+// their pods. It returns nil for other actions.
+func newScaleDownOrder(profile *optimizerv1.ResourceOptimizerProfile, action string, classes []schedulingv1.PriorityClass,
+	deployments []appsv1.Deployment, statefulSets []appsv1.StatefulSet) *scaleDownOrder {
+	if action != ScaleDownAction && action != ScaleToZeroAction {
+		return nil
+	}
+	order := &scaleDownOrder{priorities: map[targetRef]int32{}, protected: DefaultProtectedPriority}
+	if priorities := profile.Spec.Priorities; priorities != nil {
+		if priorities.ProtectedPriority != nil {
+			order.protected = *priorities.ProtectedPriority
+		}
+		order.scaleDownProtected = priorities.ScaleDownProtected
+	}
+	add := func(target targetRef, template *corev1.PodTemplateSpec, replicas *int32) {
+		priority := podPriority(template, classes)
+		order.priorities[target] = priority
+		current := int32(1)
+		if replicas != nil {
+			current = *replicas
+		}
+		if order.reason(target) == SkipReasonPriorityProtected ||
+			cappedReplicas(profile, current, steppedReplicas(profile, action, current)) >= current {
+			return
+		}
+		if order.next == nil || priority < *order.next {
+			order.next = &priority
+		}
+	}
+	for i := range deployments {
+		add(targetRef{Kind: "Deployment", Name: deployments[i].Name}, &deployments[i].Spec.Template, deployments[i].Spec.Replicas)
+	}
+	for i := range statefulSets {
+		add(targetRef{Kind: "StatefulSet", Name: statefulSets[i].Name}, &statefulSets[i].Spec.Template, statefulSets[i].Spec.Replicas)
+	}
+	return order
+}
+
+// reason returns why the target must not be scaled down now, or "" when it
+// may be.
+func (o *scaleDownOrder) reason(target targetRef) string {
+	priority := o.priorities[target]
+	switch {
+	case priority >= o.protected && !o.scaleDownProtected:
+		return SkipReasonPriorityProtected
+	case o.next != nil && priority > *o.next:
+		return SkipReasonLowerPriorityFirst
+	}
+	return ""
+}
+
+// priorityClasses returns the PriorityClasses needed to order the targets. As
+// long as no target names a PriorityClass, all of them run with the same
+// priority and none are listed.
+func (r *ResourceOptimizerProfileReconciler) priorityClasses(ctx context.Context, action string,
+	deployments []appsv1.Deployment, statefulSets []appsv1.StatefulSet) ([]schedulingv1.PriorityClass, error) {
+	if action != ScaleDownAction && action != ScaleToZeroAction {
+		return nil, nil
+	}
+	named := false
+	for _, deployment := range deployments {
+		named = named || deployment.Spec.Template.Spec.PriorityClassName != ""
+	}
+	for _, statefulSet := range statefulSets {
+		named = named || statefulSet.Spec.Template.Spec.PriorityClassName != ""
+	}
+	if !named {
+		return nil, nil
+	}
+	var classes schedulingv1.PriorityClassList
+	if err := r.List(ctx, &classes); err != nil {
+		return nil, err
+	}
+	return classes.Items, nil
+}
+
+// priorityAllows reports whether order lets the target be scaled down now,
+// recording the scale-down as skipped otherwise.
+func (r *ResourceOptimizerProfileReconciler) priorityAllows(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, action string, order *scaleDownOrder, target targetRef) bool {
+	if order == nil {
+		return true
+	}
+	if reason := order.reason(target); reason != "" {
+		log.FromContext(ctx).Info("Holding back scale-down by priority", "kind", target.Kind, "name", target.Name,
+			"priority", order.priorities[target], "reason", reason)
+		r.recordSkippedAction(profile, action, reason)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Scale-down priorities", func() {
+	labels := map[string]string{"app": "shop"}
+	classes := []schedulingv1.PriorityClass{
+		{ObjectMeta: metav1.ObjectMeta{Name: "batch"}, Value: -10},
+		{ObjectMeta: metav1.ObjectMeta{Name: "default"}, Value: 100, GlobalDefault: true},
+		{ObjectMeta: metav1.ObjectMeta{Name: "critical"}, Value: DefaultProtectedPriority},
+	}
+	deployment := func(name, priorityClass string, replicas int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-a", Labels: labels},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: ptr.To(replicas),
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{PriorityClassName: priorityClass}},
+			},
+		}
+	}
+
+	It("should resolve the priority of pod templates", func() {
+		Expect(podPriority(&deployment("worker", "batch", 1).Spec.Template, classes)).To(Equal(int32(-10)))
+		Expect(podPriority(&deployment("web", "", 1).Spec.Template, classes)).To(Equal(int32(100)))
+		Expect(podPriority(&deployment("web", "", 1).Spec.Template, nil)).To(BeZero())
+		Expect(podPriority(&deployment("web", "deleted", 1).Spec.Template, classes)).To(BeZero())
+	})
+
+	It("should only order scale-downs", func() {
+		profile := &optimizerv1.ResourceOptimizerProfile{}
+		Expect(newScaleDownOrder(profile, ScaleUpAction, classes, nil, nil)).To(BeNil())
+	})
+
+	It("should scale down the lowest priority that can still be scaled down first", func() {
+		profile := &optimizerv1.ResourceOptimizerProfile{}
+		deployments := []appsv1.Deployment{*deployment("worker", "batch", 1), *deployment("web", "", 3), *deployment("db", "critical", 3)}
+		order := newScaleDownOrder(profile, ScaleDownAction, classes, deployments, nil)
+		// The worker is at a single replica already.
+		Expect(order.reason(targetRef{Kind: "Deployment", Name: "web"})).To(BeEmpty())
+		Expect(order.reason(targetRef{Kind: "Deployment", Name: "db"})).To(Equal(SkipReasonPriorityProtected))
+
+		order = newScaleDownOrder(profile, ScaleToZeroAction, classes, deployments, nil)
+		Expect(order.reason(targetRef{Kind: "Deployment", Name: "worker"})).To(BeEmpty())
+		Expect(order.reason(targetRef{Kind: "Deployment", Name: "web"})).To(Equal(SkipReasonLowerPriorityFirst))
+	})
+
+	It("should scale protected targets down when the profile opts in", func() {
+		profile := &optimizerv1.ResourceOptimizerProfile{Spec: optimizerv1.ResourceOptimizerProfileSpec{
+			Priorities: &optimizerv1.PriorityScaling{ProtectedPriority: ptr.To[int32](100), ScaleDownProtected: true},
+		}}
+		deployments := []appsv1.Deployment{*deployment("web", "", 1), *deployment("db", "critical", 3)}
+		order := newScaleDownOrder(profile, ScaleDownAction, classes, deployments, nil)
+		Expect(order.reason(targetRef{Kind: "Deployment", Name: "db"})).To(BeEmpty())
+
+		profile.Spec.Priorities.ScaleDownProtected = false
+		order = newScaleDownOrder(profile, ScaleDownAction, classes, deployments, nil)
+		Expect(order.reason(targetRef{Kind: "Deployment", Name: "web"})).To(Equal(SkipReasonPriorityProtected))
+	})
+
+	It("should only patch the lowest priority targets", func() {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(schedulingv1.AddToScheme(scheme)).To(Succeed())
+		reconciler := &ResourceOptimizerProfileReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			deployment("worker", "batch", 2), deployment("web", "", 3),
+			&classes[0], &classes[1], &classes[2],
+		).Build()}
+		profile := &optimizerv1.ResourceOptimizerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "team-a"},
+			Spec: optimizerv1.ResourceOptimizerProfileSpec{
+				Selector:      metav1.LabelSelector{MatchLabels: labels},
+				CPUThresholds: optimizerv1.ThresholdSpec{Min: 20, Max: 80},
+			},
+		}
+		replicas := func(name string) int32 {
+			var d appsv1.Deployment
+			Expect(reconciler.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: name}, &d)).To(Succeed())
+			return *d.Spec.Replicas
+		}
+
+		results, err := reconciler.executeScaleAction(context.Background(), profile, ScaleDownAction, 5)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(replicas("worker")).To(Equal(int32(1)))
+		Expect(replicas("web")).To(Equal(int32(3)))
+
+		Expect(reconciler.executeScaleAction(context.Background(), profile, ScaleDownAction, 5)).Error().NotTo(HaveOccurred())
+		Expect(replicas("worker")).To(Equal(int32(1)))
+		Expect(replicas("web")).To(Equal(int32(2)))
+	})
+})
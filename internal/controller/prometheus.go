@@ -2,22 +2,111 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
 	"github.com/prometheus/client_golang/api"
 	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/config"
 	"github.com/prometheus/common/model"
+	"github.com/spf13/pflag"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
-func newPrometheusAPI(prometheusURL string) (prometheusv1.API, error) {
+// DefaultPrometheusURL is queried when PROMETHEUS_URL is not set.
+const DefaultPrometheusURL = "http://prometheus-operated.monitoring.svc.cluster.local:9090"
+
+// PrometheusURLFromEnv returns the Prometheus URL configured with the
+// PROMETHEUS_URL environment variable, or DefaultPrometheusURL. It may be a
+// comma-separated list of the replicas of a highly available Prometheus.
+func PrometheusURLFromEnv() string {
+	if prometheusURL := os.Getenv("PROMETHEUS_URL"); prometheusURL != "" {
+		return prometheusURL
+	}
+	return DefaultPrometheusURL
+}
+
+// PrometheusTLSConfig configures how the controller verifies a Prometheus
+// served over HTTPS and authenticates to it. The files are read again when
+// they change, so certificates mounted from a Secret can be rotated.
+type PrometheusTLSConfig struct {
+	// CAFile verifies the server certificate instead of the system roots.
+	CAFile string
+	// CertFile and KeyFile are a client certificate presented to Prometheus.
+	CertFile string
+	KeyFile  string
+	// ServerName overrides the name the server certificate is verified for.
+	ServerName string
+	// InsecureSkipVerify disables verification of the server certificate.
+	InsecureSkipVerify bool
+}
+
+// BindFlags adds flags for the fields of c to fs.
+func (c *PrometheusTLSConfig) BindFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&c.CAFile, "prometheus-ca-file", "",
+		"The CA bundle that verifies the certificate of an HTTPS Prometheus. Defaults to the system roots.")
+	fs.StringVar(&c.CertFile, "prometheus-cert-file", "",
+		"The client certificate presented to Prometheus. Requires --prometheus-key-file.")
+	fs.StringVar(&c.KeyFile, "prometheus-key-file", "", "The key of --prometheus-cert-file.")
+	fs.StringVar(&c.ServerName, "prometheus-server-name", "",
+		"The name the Prometheus certificate is verified for. Defaults to the host of the URL.")
+	fs.BoolVar(&c.InsecureSkipVerify, "prometheus-insecure-skip-verify", false,
+		"If set, the certificate of Prometheus is not verified. Only use this for testing.")
+}
+
+// NewPrometheusAPI returns a client for the Prometheus HTTP API at prometheusURL.
+// A comma-separated list of URLs returns a client querying all of them and
+// merging their results, which fails over to the others when one is down.
+func NewPrometheusAPI(prometheusURL string, tlsConfig PrometheusTLSConfig) (PrometheusClient, error) {
+	urls := splitPrometheusURLs(prometheusURL)
+	if len(urls) <= 1 {
+		return newPrometheusAPI(prometheusURL, tlsConfig)
+	}
+	replicated := &replicatedPrometheus{}
+	for _, url := range urls {
+		api, err := newPrometheusAPI(url, tlsConfig)
+		if err != nil {
+			return nil, err
+		}
+		replicated.replicas = append(replicated.replicas, prometheusReplica{url: url, api: api})
+	}
+	return replicated, nil
+}
+
+// newPrometheusAPI returns a client for a single Prometheus.
+func newPrometheusAPI(prometheusURL string, tlsConfig PrometheusTLSConfig) (prometheusv1.API, error) {
+	httpConfig := config.DefaultHTTPClientConfig
+	httpConfig.TLSConfig = config.TLSConfig{
+		CAFile:             tlsConfig.CAFile,
+		CertFile:           tlsConfig.CertFile,
+		KeyFile:            tlsConfig.KeyFile,
+		ServerName:         tlsConfig.ServerName,
+		InsecureSkipVerify: tlsConfig.InsecureSkipVerify,
+	}
+	if err := httpConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid Prometheus TLS configuration: %w", err)
+	}
+	roundTripper, err := config.NewRoundTripperFromConfig(httpConfig, "prometheus")
+	if err != nil {
+		return nil, fmt.Errorf("invalid Prometheus TLS configuration: %w", err)
+	}
 	client, err := api.NewClient(api.Config{
-		Address: prometheusURL,
+		Address:      prometheusURL,
+		RoundTripper: roundTripper,
 	})
 	if err != nil {
 		return nil, err
@@ -25,8 +114,23 @@ func newPrometheusAPI(prometheusURL string) (prometheusv1.API, error) {
 	return prometheusv1.NewAPI(client), nil
 }
 
-// buildPromQL constructs the Prometheus query to calculate CPU usage percentage.
-func buildPromQL(ctx context.Context, k8sClient client.Client, profile *optimizerv1.ResourceOptimizerProfile) (string, error) {
+// buildPromQL constructs the Prometheus query to calculate CPU usage
+// percentage, or the profile's metricQuery. Profiles with a recordingRule
+// select the series recorded by it instead.
+func buildPromQL(ctx context.Context, k8sClient client.Reader, profile *optimizerv1.ResourceOptimizerProfile) (string, error) {
+	podNameRegex, err := listPodNameRegex(ctx, k8sClient, profile)
+	if err != nil || podNameRegex == "" {
+		return "", err // An empty query results in 0 usage
+	}
+	if usesRecordingRule(profile) {
+		return recordedPromQL(profile, podNameRegex), nil
+	}
+	return utilizationPromQL(profile, podNameRegex)
+}
+
+// listPodNameRegex returns a regex matching the names of the pods the
+// profile selects, or an empty one if it selects none.
+func listPodNameRegex(ctx context.Context, k8sClient client.Reader, profile *optimizerv1.ResourceOptimizerProfile) (string, error) {
 	logger := log.FromContext(ctx)
 
 	// 1. Get the label selector from the profile
@@ -43,7 +147,7 @@ func buildPromQL(ctx context.Context, k8sClient client.Client, profile *optimize
 
 	if len(podList.Items) == 0 {
 		logger.Info("No pods found for selector, skipping query", "selector", selector.String())
-		return "", nil // Return an empty query, which will result in 0 usage
+		return "", nil
 	}
 
 	// 3. Construct a regex for pod names to use in the PromQL query
@@ -54,34 +158,337 @@ func buildPromQL(ctx context.Context, k8sClient client.Client, profile *optimize
 		}
 		podNameRegex += pod.Name
 	}
+	return podNameRegex, nil
+}
 
-	// 4. Build the final PromQL query
-	// This query calculates the average CPU usage over 5 minutes as a percentage of the CPU request.
-	query := fmt.Sprintf(`
+// cpuPromQL calculates the average CPU usage over 5 minutes of every pod
+// matching podNameRegex as a percentage of its CPU request.
+func cpuPromQL(namespace, podNameRegex string) string {
+	return fmt.Sprintf(`
 		(sum(rate(container_cpu_usage_seconds_total{namespace="%s", pod=~"%s", container!=""}[5m])) by (pod) / sum(kube_pod_container_resource_requests{resource="cpu", namespace="%s", pod=~"%s", container!=""}) by (pod)) * 100`,
-		profile.Namespace, podNameRegex,
-		profile.Namespace, podNameRegex,
+		namespace, podNameRegex,
+		namespace, podNameRegex,
 	)
+}
+
+// DefaultQueryTimeout is the default time a single Prometheus query of a
+// reconcile may take.
+const DefaultQueryTimeout = 30 * time.Second
+
+// queryTimeoutKey is the context key of the timeout of Prometheus queries.
+type queryTimeoutKey struct{}
+
+// withQueryTimeout returns a context under which every Prometheus query is
+// cancelled after timeout. A timeout of zero or less leaves queries unbounded.
+func withQueryTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	if timeout <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, queryTimeoutKey{}, timeout)
+}
 
-	return query, nil
+// queryContext returns the context a single Prometheus query runs under, with
+// the deadline set by withQueryTimeout, if any.
+func queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if timeout, ok := ctx.Value(queryTimeoutKey{}).(time.Duration); ok {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// queryError explains a query cancelled by the deadline of queryContext.
+func queryError(ctx context.Context, err error) error {
+	if timeout, ok := ctx.Value(queryTimeoutKey{}).(time.Duration); ok && errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("prometheus query timed out after %s: %w", timeout, err)
+	}
+	return err
 }
 
 func executePromQL(ctx context.Context, promAPI PrometheusClient, query string) (model.Value, error) {
 	if query == "" {
 		return model.Vector{}, nil // Return an empty vector if there's no query
 	}
-	result, warnings, err := promAPI.Query(ctx, query, time.Now())
+	queryCtx, cancel := queryContext(ctx)
+	defer cancel()
+	result, warnings, err := promAPI.Query(queryCtx, query, time.Now())
 	if err != nil {
-		return nil, err
+		return nil, queryError(ctx, err)
 	}
-	if len(warnings) > 0 {
-		log.FromContext(ctx).Info("Prometheus query returned warnings", "warnings", warnings)
+	if err := checkWarnings(ctx, warnings); err != nil {
+		return nil, err
 	}
 	return result, nil
 }
 
+// QueryCPU returns the CPU utilization of the profile's pods in percent of their
+// requests, averaged across pods like the reconciler does. It is 0 when the
+// profile matches no pods. Pods without requests, whose utilization is not
+// finite, are left out.
+func QueryCPU(ctx context.Context, c client.Reader, promAPI PrometheusClient, profile *optimizerv1.ResourceOptimizerProfile) (float64, error) {
+	query, err := buildPromQL(ctx, c, profile)
+	if err != nil {
+		return 0, err
+	}
+	result, err := executePromQL(ctx, promAPI, query)
+	if err == nil {
+		result, err = queryPodsDirectly(ctx, c, promAPI, profile, result)
+	}
+	if err != nil {
+		return 0, err
+	}
+	vector, ok := result.(model.Vector)
+	if !ok {
+		return 0, fmt.Errorf("prometheus query returned a %s, not a vector", result.Type())
+	}
+	vector, dropped := finiteSamples(vector)
+	if len(dropped) > 0 {
+		log.FromContext(ctx).Info("Ignoring non-finite samples", "pods", dropped)
+	}
+	return averageCPU(ctx, vector), nil
+}
+
+// CPUSample is the CPU utilization of a profile's pods at a point in time.
+type CPUSample struct {
+	Time  time.Time
+	Value float64
+}
+
+// PrometheusRangeClient is the part of the Prometheus API used to read history.
+type PrometheusRangeClient interface {
+	QueryRange(ctx context.Context, query string, r prometheusv1.Range, opts ...prometheusv1.Option) (model.Value, prometheusv1.Warnings, error)
+}
+
+// QueryCPUHistory returns the CPU utilization of the profile's pods at every
+// step of r, averaged across pods like QueryCPU. Pods are matched by the names
+// of the profile's current Deployments and StatefulSets rather than by the
+// pods running now, so pods replaced by rollouts are included.
+func QueryCPUHistory(ctx context.Context, c client.Reader, promAPI PrometheusRangeClient, profile *optimizerv1.ResourceOptimizerProfile, r prometheusv1.Range) ([]CPUSample, error) {
+	podNameRegex, err := targetPodNameRegex(ctx, c, profile)
+	if err != nil {
+		return nil, err
+	}
+	if podNameRegex == "" {
+		return nil, fmt.Errorf("profile %s/%s matches no Deployment or StatefulSet", profile.Namespace, profile.Name)
+	}
+	query, err := utilizationPromQL(profile, podNameRegex)
+	if err != nil {
+		return nil, err
+	}
+	queryCtx, cancel := queryContext(ctx)
+	defer cancel()
+	result, warnings, err := promAPI.QueryRange(queryCtx, query, r)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+	if err := checkWarnings(ctx, warnings); err != nil {
+		return nil, err
+	}
+	matrix, ok := result.(model.Matrix)
+	if !ok {
+		return nil, fmt.Errorf("prometheus range query returned a %s, not a matrix", result.Type())
+	}
+
+	// Average the pods present at each timestamp.
+	sums := map[model.Time]float64{}
+	counts := map[model.Time]int{}
+	for _, series := range matrix {
+		for _, point := range series.Values {
+			if !isFinite(float64(point.Value)) {
+				continue
+			}
+			sums[point.Timestamp] += float64(point.Value)
+			counts[point.Timestamp]++
+		}
+	}
+	samples := make([]CPUSample, 0, len(sums))
+	for ts, sum := range sums {
+		samples = append(samples, CPUSample{Time: ts.Time(), Value: sum / float64(counts[ts])})
+	}
+	slices.SortFunc(samples, func(a, b CPUSample) int { return a.Time.Compare(b.Time) })
+	return samples, nil
+}
+
+// targetPodNameRegex returns a regex matching the pods of every Deployment and
+// StatefulSet the profile selects, past and present.
+func targetPodNameRegex(ctx context.Context, c client.Reader, profile *optimizerv1.ResourceOptimizerProfile) (string, error) {
+	labelSelector := labels.Set(profile.Spec.Selector.MatchLabels).AsSelector()
+	listOpts := &client.ListOptions{LabelSelector: labelSelector, Namespace: profile.Namespace}
+
+	var deployments appsv1.DeploymentList
+	if err := c.List(ctx, &deployments, listOpts); err != nil {
+		return "", err
+	}
+	var statefulSets appsv1.StatefulSetList
+	if err := c.List(ctx, &statefulSets, listOpts); err != nil {
+		return "", err
+	}
+
+	var patterns []string
+	for _, deployment := range deployments.Items {
+		patterns = append(patterns, podNamePattern(targetRef{Kind: "Deployment", Name: deployment.Name}))
+	}
+	for _, ss := range statefulSets.Items {
+		patterns = append(patterns, podNamePattern(targetRef{Kind: "StatefulSet", Name: ss.Name}))
+	}
+	return strings.Join(patterns, "|"), nil
+}
+
+// podNamePattern returns a regex matching the names of a workload's pods.
+func podNamePattern(target targetRef) string {
+	if target.Kind == "StatefulSet" {
+		return quoteName(target.Name) + "-[0-9]+"
+	}
+	// <deployment>-<pod-template-hash>-<suffix>
+	return quoteName(target.Name) + "-[a-z0-9]+-[a-z0-9]+"
+}
+
+// quoteName escapes the dots of a Kubernetes name for a regex without
+// backslashes, which would need escaping again inside the PromQL string.
+func quoteName(name string) string {
+	return strings.ReplaceAll(name, ".", "[.]")
+}
+
+// isFinite reports whether value is neither NaN nor infinite.
+func isFinite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
+
+// finiteSamples drops the NaN and infinite samples of a utilization query,
+// which pods without CPU requests yield by dividing by zero. It returns the
+// remaining samples and the pods of the dropped ones.
+func finiteSamples(vector model.Vector) (model.Vector, []string) {
+	finite := make(model.Vector, 0, len(vector))
+	var dropped []string
+	for _, sample := range vector {
+		if isFinite(float64(sample.Value)) {
+			finite = append(finite, sample)
+			continue
+		}
+		pod := string(sample.Metric["pod"])
+		if pod == "" {
+			pod = sample.Metric.String()
+		}
+		dropped = append(dropped, pod)
+	}
+	return finite, dropped
+}
+
+// averageCPU averages the per-pod samples of a CPU query to derive a
+// representative value.
+func averageCPU(ctx context.Context, vector model.Vector) float64 {
+	if len(vector) == 0 {
+		return 0
+	}
+	var sum float64
+	// Log each sample for debugging
+	for _, sample := range vector {
+		// attempt to extract pod label, fall back to the full metric
+		pod := "unknown"
+		if m, ok := sample.Metric["pod"]; ok {
+			pod = string(m)
+		}
+		log.FromContext(ctx).Info("Prometheus sample", "pod", pod, "value", float64(sample.Value))
+		sum += float64(sample.Value)
+	}
+	value := sum / float64(len(vector))
+	log.FromContext(ctx).Info("Computed CPU percent (average)", "value", value, "seriesCount", len(vector))
+	return value
+}
+
 // PrometheusClient defines the interface for a Prometheus API client.
 // This simplifies testing by allowing us to mock only the methods we use.
 type PrometheusClient interface {
 	Query(ctx context.Context, query string, ts time.Time, opts ...prometheusv1.Option) (model.Value, prometheusv1.Warnings, error)
+	PrometheusRangeClient
+}
+
+// PrometheusConnectivity is a snapshot of the controller's connection to
+// Prometheus.
+type PrometheusConnectivity struct {
+	// URL is the Prometheus the controller queries.
+	URL string
+	// LastSuccess is when a query last succeeded. Zero if none has.
+	LastSuccess time.Time
+	// FailingSince is when the current run of failed queries started. Zero
+	// while queries succeed.
+	FailingSince time.Time
+	// LastError is the error of the last failed query in the current run.
+	LastError string
+}
+
+// Reachable reports whether the last query succeeded, or none was made yet.
+func (c PrometheusConnectivity) Reachable() bool {
+	return c.FailingSince.IsZero()
+}
+
+// PrometheusHealth tracks the outcome of the reconciler's Prometheus queries.
+// The zero value is ready to use.
+type PrometheusHealth struct {
+	mu     sync.Mutex
+	status PrometheusConnectivity
+}
+
+// Status returns the current connectivity.
+func (h *PrometheusHealth) Status() PrometheusConnectivity {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.status
+}
+
+func (h *PrometheusHealth) setURL(url string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.status.URL = url
+}
+
+// recordQuery records the outcome of a query made at now.
+func (h *PrometheusHealth) recordQuery(err error, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err == nil {
+		h.status.LastSuccess = now
+		h.status.FailingSince = time.Time{}
+		h.status.LastError = ""
+		return
+	}
+	if h.status.FailingSince.IsZero() {
+		h.status.FailingSince = now
+	}
+	h.status.LastError = err.Error()
+}
+
+// Readiness probing of Prometheus. The probe query is skipped while the
+// reconciler's own queries keep succeeding.
+const (
+	prometheusProbeQuery    = "up"
+	prometheusProbeInterval = 30 * time.Second
+	prometheusProbeTimeout  = 3 * time.Second
+)
+
+// ReadyzCheck returns a readiness check failing until Prometheus has answered
+// a query, and once it has been unreachable for longer than threshold. Unless
+// a query succeeded within the last 30 seconds the check queries "up" itself,
+// so replicas that do not reconcile, such as standby leaders, and new replicas
+// with a wrong URL are checked too.
+func (h *PrometheusHealth) ReadyzCheck(promAPI PrometheusClient, threshold time.Duration) healthz.Checker {
+	return func(req *http.Request) error {
+		if status := h.Status(); time.Since(status.LastSuccess) > prometheusProbeInterval {
+			ctx, cancel := context.WithTimeout(req.Context(), prometheusProbeTimeout)
+			defer cancel()
+			_, _, err := promAPI.Query(ctx, prometheusProbeQuery, time.Now())
+			h.recordQuery(err, time.Now())
+		}
+
+		status := h.Status()
+		switch {
+		case status.Reachable():
+			return nil
+		case status.LastSuccess.IsZero():
+			return fmt.Errorf("prometheus at %s has not answered yet: %s", status.URL, status.LastError)
+		case time.Since(status.FailingSince) <= threshold:
+			return nil
+		}
+		return fmt.Errorf("prometheus at %s unreachable since %s: %s",
+			status.URL, status.FailingSince.Format(time.RFC3339), status.LastError)
+	}
 }
@@ -0,0 +1,191 @@
+package controller
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Prometheus health", func() {
+	It("should track the current run of failed queries", func() {
+		health := &PrometheusHealth{}
+		health.setURL("http://prometheus:9090")
+		Expect(health.Status().Reachable()).To(BeTrue())
+
+		now := time.Now()
+		health.recordQuery(nil, now.Add(-20*time.Minute))
+		health.recordQuery(errors.New("connection refused"), now.Add(-10*time.Minute))
+		health.recordQuery(errors.New("i/o timeout"), now.Add(-5*time.Minute))
+
+		status := health.Status()
+		Expect(status.Reachable()).To(BeFalse())
+		Expect(status.URL).To(Equal("http://prometheus:9090"))
+		Expect(status.LastSuccess).To(Equal(now.Add(-20 * time.Minute)))
+		Expect(status.FailingSince).To(Equal(now.Add(-10 * time.Minute)))
+		Expect(status.LastError).To(Equal("i/o timeout"))
+
+		health.recordQuery(nil, now)
+		Expect(health.Status().Reachable()).To(BeTrue())
+	})
+
+	It("should probe Prometheus when no query succeeded recently", func() {
+		health := &PrometheusHealth{}
+		health.setURL("http://prometheus:9090")
+		promAPI := &mockPrometheusAPI{err: errors.New("connection refused")}
+		req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+
+		// A replica that never reached Prometheus is not ready, whatever the
+		// threshold.
+		Expect(health.ReadyzCheck(promAPI, time.Hour)(req)).To(MatchError(ContainSubstring("has not answered yet: connection refused")))
+
+		promAPI.err = nil
+		promAPI.result = model.Vector{}
+		Expect(health.ReadyzCheck(promAPI, time.Hour)(req)).To(Succeed())
+		Expect(health.Status().LastSuccess).NotTo(BeZero())
+
+		// Once Prometheus answered, outages shorter than the threshold are
+		// tolerated.
+		now := time.Now()
+		health.recordQuery(nil, now.Add(-20*time.Minute))
+		health.recordQuery(errors.New("i/o timeout"), now.Add(-10*time.Minute))
+		promAPI.err = errors.New("i/o timeout")
+		Expect(health.ReadyzCheck(promAPI, 15*time.Minute)(req)).To(Succeed())
+		Expect(health.ReadyzCheck(promAPI, time.Minute)(req)).To(MatchError(ContainSubstring("unreachable since")))
+	})
+})
+
+var _ = Describe("Prometheus TLS", func() {
+	var (
+		server *httptest.Server
+		caFile string
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+		}))
+		DeferCleanup(server.Close)
+
+		caFile = filepath.Join(GinkgoT().TempDir(), "ca.crt")
+		ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+		Expect(os.WriteFile(caFile, ca, 0o600)).To(Succeed())
+	})
+
+	query := func(tlsConfig PrometheusTLSConfig) error {
+		promAPI, err := NewPrometheusAPI(server.URL, tlsConfig)
+		Expect(err).NotTo(HaveOccurred())
+		_, _, err = promAPI.Query(context.Background(), "up", time.Now())
+		return err
+	}
+
+	It("should verify the server with the configured CA", func() {
+		Expect(query(PrometheusTLSConfig{})).To(MatchError(ContainSubstring("certificate")))
+		Expect(query(PrometheusTLSConfig{CAFile: caFile})).To(Succeed())
+		Expect(query(PrometheusTLSConfig{InsecureSkipVerify: true})).To(Succeed())
+	})
+
+	It("should reject a client certificate without a key", func() {
+		_, err := NewPrometheusAPI(server.URL, PrometheusTLSConfig{CertFile: caFile})
+		Expect(err).To(MatchError(ContainSubstring("invalid Prometheus TLS configuration")))
+	})
+})
+
+// slowPrometheusAPI answers queries only once their context is done.
+type slowPrometheusAPI struct {
+	mockPrometheusAPI
+}
+
+func (m *slowPrometheusAPI) Query(ctx context.Context, query string, ts time.Time, opts ...prometheusv1.Option) (model.Value, prometheusv1.Warnings, error) {
+	<-ctx.Done()
+	return nil, nil, ctx.Err()
+}
+
+var _ = Describe("Prometheus query timeout", func() {
+	It("should cancel every query after the timeout", func() {
+		ctx := withQueryTimeout(context.Background(), 10*time.Millisecond)
+		_, err := executePromQL(ctx, &slowPrometheusAPI{}, "up")
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		Expect(err).To(MatchError(ContainSubstring("prometheus query timed out after 10ms")))
+		// The deadline applies to each query, not to the reconcile.
+		Expect(ctx.Err()).NotTo(HaveOccurred())
+	})
+
+	It("should prefer the profile's timeout", func() {
+		reconciler := &ResourceOptimizerProfileReconciler{QueryTimeout: DefaultQueryTimeout}
+		profile := &optimizerv1.ResourceOptimizerProfile{}
+		Expect(reconciler.queryTimeout(profile)).To(Equal(DefaultQueryTimeout))
+		profile.Spec.QueryTimeout = &metav1.Duration{Duration: 2 * time.Minute}
+		Expect(reconciler.queryTimeout(profile)).To(Equal(2 * time.Minute))
+	})
+})
+
+var _ = Describe("Non-finite samples", func() {
+	It("should drop NaN and infinite samples", func() {
+		vector := model.Vector{
+			{Metric: model.Metric{"pod": "web-1"}, Value: 40},
+			{Metric: model.Metric{"pod": "web-2"}, Value: model.SampleValue(math.NaN())},
+			{Metric: model.Metric{"pod": "web-3"}, Value: model.SampleValue(math.Inf(1))},
+		}
+		finite, dropped := finiteSamples(vector)
+		Expect(finite).To(Equal(model.Vector{{Metric: model.Metric{"pod": "web-1"}, Value: 40}}))
+		Expect(dropped).To(Equal([]string{"web-2", "web-3"}))
+		Expect(averageCPU(context.Background(), finite)).To(Equal(40.0))
+	})
+
+	It("should report the pods with invalid samples in a condition", func() {
+		reconciler := &ResourceOptimizerProfileReconciler{}
+		profile := &optimizerv1.ResourceOptimizerProfile{}
+
+		Expect(reconciler.observeInvalidSamples(profile, nil)).To(BeFalse())
+		Expect(profile.Status.Conditions).To(BeEmpty())
+
+		Expect(reconciler.observeInvalidSamples(profile, []string{"web-3", "web-2"})).To(BeTrue())
+		condition := meta.FindStatusCondition(profile.Status.Conditions, optimizerv1.ConditionInvalidSamples)
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(HavePrefix("The utilization of web-2, web-3 was NaN or infinite"))
+
+		Expect(reconciler.observeInvalidSamples(profile, nil)).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(profile.Status.Conditions, optimizerv1.ConditionInvalidSamples)).To(BeTrue())
+	})
+
+	It("should leave non-finite samples out of the history", func() {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", Labels: map[string]string{"app": "web"}},
+		}).Build()
+		profile := &optimizerv1.ResourceOptimizerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+			Spec:       optimizerv1.ResourceOptimizerProfileSpec{Selector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+		}
+		promAPI := &mockPrometheusAPI{rangeResult: model.Matrix{
+			{Metric: model.Metric{"pod": "web-a-1"}, Values: []model.SamplePair{{Timestamp: 0, Value: 40}, {Timestamp: 60000, Value: 50}}},
+			{Metric: model.Metric{"pod": "web-a-2"}, Values: []model.SamplePair{{Timestamp: 0, Value: model.SampleValue(math.NaN())}}},
+		}}
+
+		samples, err := QueryCPUHistory(context.Background(), c, promAPI, profile, prometheusv1.Range{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(samples).To(HaveLen(2))
+		Expect(samples[0].Value).To(Equal(40.0))
+		Expect(samples[1].Value).To(Equal(50.0))
+	})
+})
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// splitPrometheusURLs splits a comma-separated list of Prometheus URLs.
+func splitPrometheusURLs(prometheusURLs string) []string {
+	var urls []string
+	for _, url := range strings.Split(prometheusURLs, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// prometheusReplica is one Prometheus of a highly available set.
+type prometheusReplica struct {
+	url string
+	api PrometheusClient
+}
+
+// replicatedPrometheus queries every replica of a highly available Prometheus
+// set, such as the two replicas of a Prometheus Operator Prometheus, and
+// merges their results. A query only fails when every replica fails, so a
+// replica restarting does not stall optimization.
+type replicatedPrometheus struct {
+	replicas []prometheusReplica
+}
+
+var _ PrometheusClient = &replicatedPrometheus{}
+
+// replicaResult is the answer of one replica.
+type replicaResult struct {
+	value    model.Value
+	warnings prometheusv1.Warnings
+	err      error
+}
+
+// Query implements PrometheusClient.
+func (p *replicatedPrometheus) Query(ctx context.Context, query string, ts time.Time, opts ...prometheusv1.Option) (model.Value, prometheusv1.Warnings, error) {
+	return p.queryAll(ctx, func(ctx context.Context, api PrometheusClient) (model.Value, prometheusv1.Warnings, error) {
+		return api.Query(ctx, query, ts, opts...)
+	})
+}
+
+// QueryRange implements PrometheusRangeClient.
+func (p *replicatedPrometheus) QueryRange(ctx context.Context, query string, r prometheusv1.Range, opts ...prometheusv1.Option) (model.Value, prometheusv1.Warnings, error) {
+	return p.queryAll(ctx, func(ctx context.Context, api PrometheusClient) (model.Value, prometheusv1.Warnings, error) {
+		return api.QueryRange(ctx, query, r, opts...)
+	})
+}
+
+// queryAll runs query against every replica at once and merges the results of
+// those that answered.
+func (p *replicatedPrometheus) queryAll(ctx context.Context, query func(context.Context, PrometheusClient) (model.Value, prometheusv1.Warnings, error)) (model.Value, prometheusv1.Warnings, error) {
+	results := make([]replicaResult, len(p.replicas))
+	var wg sync.WaitGroup
+	for i, replica := range p.replicas {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, warnings, err := query(ctx, replica.api)
+			results[i] = replicaResult{value: value, warnings: warnings, err: err}
+		}()
+	}
+	wg.Wait()
+
+	var (
+		values   []model.Value
+		warnings prometheusv1.Warnings
+		errs     []error
+	)
+	for i, result := range results {
+		if result.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.replicas[i].url, result.err))
+			continue
+		}
+		values = append(values, result.value)
+		warnings = append(warnings, result.warnings...)
+	}
+	if len(values) == 0 {
+		return nil, nil, errors.Join(errs...)
+	}
+	if len(errs) > 0 {
+		log.FromContext(ctx).Info("Prometheus replicas failed, using the others", "errors", errors.Join(errs...).Error())
+	}
+	slices.Sort(warnings)
+	return mergeValues(values), slices.Compact(warnings), nil
+}
+
+// mergeValues deduplicates the series the replicas returned. A series
+// returned by several replicas keeps the value of the first of them, in the
+// order the replicas were configured, and the samples of a range query one
+// replica missed, e.g. while it was restarting, are filled in from the others.
+func mergeValues(values []model.Value) model.Value {
+	switch values[0].(type) {
+	case model.Vector:
+		merged := model.Vector{}
+		seen := map[model.Fingerprint]bool{}
+		for _, value := range values {
+			vector, _ := value.(model.Vector)
+			for _, sample := range vector {
+				if fingerprint := sample.Metric.Fingerprint(); !seen[fingerprint] {
+					seen[fingerprint] = true
+					merged = append(merged, sample)
+				}
+			}
+		}
+		return merged
+	case model.Matrix:
+		merged := model.Matrix{}
+		index := map[model.Fingerprint]int{}
+		for _, value := range values {
+			matrix, _ := value.(model.Matrix)
+			for _, stream := range matrix {
+				fingerprint := stream.Metric.Fingerprint()
+				i, ok := index[fingerprint]
+				if !ok {
+					index[fingerprint] = len(merged)
+					merged = append(merged, &model.SampleStream{Metric: stream.Metric, Values: slices.Clone(stream.Values), Histograms: stream.Histograms})
+					continue
+				}
+				merged[i].Values = mergeSamplePairs(merged[i].Values, stream.Values)
+			}
+		}
+		return merged
+	default:
+		return values[0]
+	}
+}
+
+// mergeSamplePairs adds the samples of other at timestamps missing from
+// samples, keeping them ordered by time.
+func mergeSamplePairs(samples, other []model.SamplePair) []model.SamplePair {
+	seen := make(map[model.Time]bool, len(samples))
+	for _, sample := range samples {
+		seen[sample.Timestamp] = true
+	}
+	added := false
+	for _, sample := range other {
+		if !seen[sample.Timestamp] {
+			samples = append(samples, sample)
+			added = true
+		}
+	}
+	if added {
+		slices.SortFunc(samples, func(a, b model.SamplePair) int {
+			return cmp.Compare(a.Timestamp, b.Timestamp)
+		})
+	}
+	return samples
+}
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+var _ = Describe("Replicated Prometheus", func() {
+	web1 := model.Metric{"pod": "web-1"}
+	web2 := model.Metric{"pod": "web-2"}
+
+	replicated := func(apis ...PrometheusClient) *replicatedPrometheus {
+		p := &replicatedPrometheus{}
+		for i, api := range apis {
+			p.replicas = append(p.replicas, prometheusReplica{url: []string{"http://prometheus-0:9090", "http://prometheus-1:9090"}[i], api: api})
+		}
+		return p
+	}
+
+	It("should create a replicated client for a list of URLs", func() {
+		Expect(splitPrometheusURLs(" http://prometheus-0:9090, http://prometheus-1:9090,")).To(Equal([]string{"http://prometheus-0:9090", "http://prometheus-1:9090"}))
+
+		promAPI, err := NewPrometheusAPI("http://prometheus-0:9090,http://prometheus-1:9090", PrometheusTLSConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(promAPI).To(BeAssignableToTypeOf(&replicatedPrometheus{}))
+		Expect(promAPI.(*replicatedPrometheus).replicas).To(HaveLen(2))
+	})
+
+	It("should deduplicate the series of the replicas", func() {
+		promAPI := replicated(
+			&mockPrometheusAPI{result: model.Vector{{Metric: web1, Value: 40}}},
+			&mockPrometheusAPI{result: model.Vector{{Metric: web1, Value: 41}, {Metric: web2, Value: 60}}},
+		)
+
+		result, _, err := promAPI.Query(context.Background(), "up", time.Now())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(model.Vector{{Metric: web1, Value: 40}, {Metric: web2, Value: 60}}))
+	})
+
+	It("should fill in the samples a replica missed", func() {
+		promAPI := replicated(
+			&mockPrometheusAPI{rangeResult: model.Matrix{{Metric: web1, Values: []model.SamplePair{{Timestamp: 0, Value: 40}, {Timestamp: 120, Value: 42}}}}},
+			&mockPrometheusAPI{rangeResult: model.Matrix{{Metric: web1, Values: []model.SamplePair{{Timestamp: 60, Value: 41}, {Timestamp: 120, Value: 43}}}}},
+		)
+
+		result, _, err := promAPI.QueryRange(context.Background(), "up", prometheusv1.Range{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(model.Matrix{{Metric: web1, Values: []model.SamplePair{
+			{Timestamp: 0, Value: 40}, {Timestamp: 60, Value: 41}, {Timestamp: 120, Value: 42},
+		}}}))
+	})
+
+	It("should fail over to the replicas that answer", func() {
+		down := &mockPrometheusAPI{err: errors.New("connection refused")}
+		promAPI := replicated(down, &mockPrometheusAPI{result: model.Vector{{Metric: web1, Value: 40}}})
+
+		result, _, err := promAPI.Query(context.Background(), "up", time.Now())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(model.Vector{{Metric: web1, Value: 40}}))
+
+		promAPI = replicated(down, &mockPrometheusAPI{err: errors.New("i/o timeout")})
+		_, _, err = promAPI.Query(context.Background(), "up", time.Now())
+		Expect(err).To(MatchError(ContainSubstring("http://prometheus-0:9090: connection refused")))
+		Expect(err).To(MatchError(ContainSubstring("http://prometheus-1:9090: i/o timeout")))
+	})
+})
@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// ParseWorkloadSelectors parses label selectors such as
+// app.kubernetes.io/part-of=observability for ProtectedWorkloads.
+func ParseWorkloadSelectors(raw []string) ([]labels.Selector, error) {
+	selectors := make([]labels.Selector, 0, len(raw))
+	for _, s := range raw {
+		selector, err := labels.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid workload selector %q: %w", s, err)
+		}
+		if selector.Empty() {
+			return nil, fmt.Errorf("workload selector %q would protect every workload", s)
+		}
+		selectors = append(selectors, selector)
+	}
+	return selectors, nil
+}
+
+// workloadProtected reports whether a target matches one of the controller's
+// ProtectedWorkloads, whatever the profile says. Actions on protected targets
+// are logged and counted as skipped.
+func (r *ResourceOptimizerProfileReconciler) workloadProtected(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, action, kind string, target metav1.Object) bool {
+	for _, selector := range r.ProtectedWorkloads {
+		if selector.Matches(labels.Set(target.GetLabels())) {
+			log.FromContext(ctx).Info("Skipping protected workload", "action", action, "kind", kind, "name", target.GetName(), "selector", selector.String())
+			r.recordSkippedAction(profile, action, SkipReasonProtectedWorkload)
+			return true
+		}
+	}
+	return false
+}
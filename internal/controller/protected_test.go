@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Protected workloads", func() {
+	It("should reject invalid and empty selectors", func() {
+		selectors, err := ParseWorkloadSelectors([]string{"app.kubernetes.io/part-of=observability", "tier in (database,cache)"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(selectors).To(HaveLen(2))
+
+		_, err = ParseWorkloadSelectors([]string{"tier in database"})
+		Expect(err).To(MatchError(ContainSubstring(`invalid workload selector "tier in database"`)))
+		_, err = ParseWorkloadSelectors([]string{""})
+		Expect(err).To(MatchError(ContainSubstring("would protect every workload")))
+	})
+
+	It("should never scale a protected workload", func() {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		selectors, err := ParseWorkloadSelectors([]string{"app.kubernetes.io/part-of=observability"})
+		Expect(err).NotTo(HaveOccurred())
+		deployment := func(name string, labels map[string]string) *appsv1.Deployment {
+			return &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-a", Labels: labels},
+				Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](2)},
+			}
+		}
+		reconciler := &ResourceOptimizerProfileReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				deployment("web", map[string]string{"app": "web"}),
+				deployment("prometheus", map[string]string{"app": "web", "app.kubernetes.io/part-of": "observability"}),
+			).Build(),
+			ProtectedWorkloads: selectors,
+		}
+		profile := &optimizerv1.ResourceOptimizerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+			Spec: optimizerv1.ResourceOptimizerProfileSpec{
+				Selector:           metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				CPUThresholds:      optimizerv1.ThresholdSpec{Min: 20, Max: 80},
+				OptimizationPolicy: "Scale",
+			},
+		}
+
+		results, err := reconciler.executeScaleAction(context.Background(), profile, ScaleUpAction, 90)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].Name).To(Equal("web"))
+
+		var protected appsv1.Deployment
+		Expect(reconciler.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "prometheus"}, &protected)).To(Succeed())
+		Expect(*protected.Spec.Replicas).To(Equal(int32(2)))
+	})
+})
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// pruneTargets drops what the reconciler remembers about Deployments and
+// StatefulSets of a namespace that were deleted or are no longer selected by
+// any profile there: their replica changes and usage histograms. Targets
+// shared with another profile are kept until no profile selects them.
+func (r *ResourceOptimizerProfileReconciler) pruneTargets(ctx context.Context, namespace string) error {
+	var profiles optimizerv1.ResourceOptimizerProfileList
+	if err := r.List(ctx, &profiles, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+	var deployments appsv1.DeploymentList
+	if err := r.List(ctx, &deployments, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+	var statefulSets appsv1.StatefulSetList
+	if err := r.List(ctx, &statefulSets, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+
+	selected := map[targetRef]bool{}
+	for _, profile := range profiles.Items {
+		selector := labels.Set(profile.Spec.Selector.MatchLabels).AsSelector()
+		for _, deployment := range deployments.Items {
+			if selector.Matches(labels.Set(deployment.Labels)) {
+				selected[targetRef{Kind: "Deployment", Name: deployment.Name}] = true
+			}
+		}
+		for _, ss := range statefulSets.Items {
+			if selector.Matches(labels.Set(ss.Labels)) {
+				selected[targetRef{Kind: "StatefulSet", Name: ss.Name}] = true
+			}
+		}
+	}
+	r.scaleHistory.prune(namespace, selected)
+	r.usageHistory.prune(namespace, selected)
+	return nil
+}
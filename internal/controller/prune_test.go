@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Pruning targets", func() {
+	It("should forget targets no profile selects anymore", func() {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(optimizerv1.AddToScheme(scheme)).To(Succeed())
+		reconciler := &ResourceOptimizerProfileReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&optimizerv1.ResourceOptimizerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+				Spec:       optimizerv1.ResourceOptimizerProfileSpec{Selector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+			},
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", Labels: map[string]string{"app": "web"}}},
+			&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "team-a", Labels: map[string]string{"app": "db"}}},
+		).Build()}
+
+		now := time.Now()
+		web := workloadKey{"team-a", targetRef{Kind: "Deployment", Name: "web"}}
+		for _, key := range []workloadKey{
+			web,
+			{"team-a", targetRef{Kind: "StatefulSet", Name: "db"}},
+			{"team-a", targetRef{Kind: "Deployment", Name: "deleted"}},
+			{"team-b", targetRef{Kind: "Deployment", Name: "web"}},
+		} {
+			reconciler.scaleHistory.record(key, 1, now)
+			reconciler.usageHistory.observe(usageKey{key, "app"}, 0.5, time.Hour, now)
+		}
+
+		Expect(reconciler.pruneTargets(context.Background(), "team-a")).To(Succeed())
+		Expect(reconciler.scaleHistory.events).To(HaveLen(2))
+		Expect(reconciler.scaleHistory.events).To(HaveKey(web))
+		Expect(reconciler.usageHistory.histograms).To(HaveLen(2))
+		Expect(reconciler.usageHistory.histograms).To(HaveKey(usageKey{web, "app"}))
+	})
+})
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// cachedQuery is the result of an instant query and when it expires.
+type cachedQuery struct {
+	value    model.Value
+	warnings prometheusv1.Warnings
+	expires  time.Time
+}
+
+// cachingPrometheus reuses the result of an instant query for ttl, so that
+// reconciles of the same profile fired in bursts by watch events do not query
+// Prometheus again. Failed queries and range queries are not cached.
+type cachingPrometheus struct {
+	PrometheusClient
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	results map[string]cachedQuery
+}
+
+// newCachingPrometheus returns promAPI caching the results of its instant
+// queries for ttl.
+func newCachingPrometheus(promAPI PrometheusClient, ttl time.Duration) *cachingPrometheus {
+	return &cachingPrometheus{PrometheusClient: promAPI, ttl: ttl, now: time.Now, results: map[string]cachedQuery{}}
+}
+
+// Query implements PrometheusClient.
+func (c *cachingPrometheus) Query(ctx context.Context, query string, ts time.Time, opts ...prometheusv1.Option) (model.Value, prometheusv1.Warnings, error) {
+	now := c.now()
+	c.mu.Lock()
+	cached, ok := c.results[query]
+	c.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		log.FromContext(ctx).V(1).Info("Reusing cached Prometheus query result", "expires", cached.expires)
+		return cached.value, cached.warnings, nil
+	}
+
+	value, warnings, err := c.PrometheusClient.Query(ctx, query, ts, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, result := range c.results {
+		if !now.Before(result.expires) {
+			delete(c.results, key)
+		}
+	}
+	c.results[query] = cachedQuery{value: value, warnings: warnings, expires: now.Add(c.ttl)}
+	return value, warnings, nil
+}
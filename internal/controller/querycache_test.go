@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+// countingPrometheusAPI counts the queries that reach Prometheus.
+type countingPrometheusAPI struct {
+	mockPrometheusAPI
+	queries int
+}
+
+func (m *countingPrometheusAPI) Query(ctx context.Context, query string, ts time.Time, opts ...prometheusv1.Option) (model.Value, prometheusv1.Warnings, error) {
+	m.queries++
+	return m.mockPrometheusAPI.Query(ctx, query, ts, opts...)
+}
+
+var _ = Describe("Metrics cache", func() {
+	It("should reuse query results until they expire", func() {
+		promAPI := &countingPrometheusAPI{mockPrometheusAPI: mockPrometheusAPI{result: model.Vector{{Value: 40}}}}
+		cache := newCachingPrometheus(promAPI, time.Minute)
+		now := time.Now()
+		cache.now = func() time.Time { return now }
+
+		for range 3 {
+			result, _, err := cache.Query(context.Background(), "up", now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(model.Vector{{Value: 40}}))
+		}
+		Expect(promAPI.queries).To(Equal(1))
+
+		_, _, err := cache.Query(context.Background(), "down", now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(promAPI.queries).To(Equal(2))
+
+		now = now.Add(time.Minute)
+		promAPI.result = model.Vector{{Value: 60}}
+		result, _, err := cache.Query(context.Background(), "up", now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(model.Vector{{Value: 60}}))
+		Expect(promAPI.queries).To(Equal(3))
+		Expect(cache.results).To(HaveLen(1))
+	})
+
+	It("should not cache failed queries", func() {
+		promAPI := &countingPrometheusAPI{mockPrometheusAPI: mockPrometheusAPI{err: errors.New("connection refused")}}
+		cache := newCachingPrometheus(promAPI, time.Minute)
+
+		for range 2 {
+			_, _, err := cache.Query(context.Background(), "up", time.Now())
+			Expect(err).To(MatchError("connection refused"))
+		}
+		Expect(promAPI.queries).To(Equal(2))
+	})
+})
@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// Types of queue triggers.
+const (
+	QueueKafka    = "Kafka"
+	QueueRabbitMQ = "RabbitMQ"
+)
+
+// ScaleToZeroAction scales the targets of a profile whose queues stayed empty
+// to zero replicas. Like a ScaleDown, it is subject to the profile's direction,
+// cooldown and alert holds, but not to its behavior policies.
+const ScaleToZeroAction = "ScaleToZero"
+
+// queueSelector returns the series of the messages waiting in a trigger's
+// queue.
+func queueSelector(trigger optimizerv1.QueueTrigger) string {
+	if trigger.Type == QueueRabbitMQ {
+		return fmt.Sprintf(`rabbitmq_queue_messages_ready{queue="%s"}`, trigger.Queue)
+	}
+	matchers := []string{fmt.Sprintf(`consumergroup="%s"`, trigger.ConsumerGroup)}
+	if trigger.Topic != "" {
+		matchers = append(matchers, fmt.Sprintf(`topic="%s"`, trigger.Topic))
+	}
+	return "kafka_consumergroup_lag{" + strings.Join(matchers, ", ") + "}"
+}
+
+// queueDepthPromQL calculates the messages waiting in a trigger's queue.
+func queueDepthPromQL(trigger optimizerv1.QueueTrigger) string {
+	return fmt.Sprintf("sum(%s)", queueSelector(trigger))
+}
+
+// queueIdlePromQL calculates the most messages that waited in a trigger's
+// queue, per series, over a duration. It is zero when the queue stayed empty.
+func queueIdlePromQL(trigger optimizerv1.QueueTrigger, d time.Duration) string {
+	return fmt.Sprintf("sum(max_over_time(%s[%s]))", queueSelector(trigger), model.Duration(d))
+}
+
+// queueState is the backlog of a queue trigger.
+type queueState struct {
+	// depth is the number of messages waiting.
+	depth float64
+	// idle is true when the queue stayed empty for the trigger's
+	// scaleToZeroAfter.
+	idle bool
+}
+
+// queueStates queries the backlog of every queue trigger of the profile. A
+// queue without metrics is neither deep nor idle, so that a missing exporter
+// never scales targets to zero.
+func queueStates(ctx context.Context, promAPI PrometheusClient, profile *optimizerv1.ResourceOptimizerProfile) ([]queueState, error) {
+	states := make([]queueState, len(profile.Spec.QueueTriggers))
+	for i, trigger := range profile.Spec.QueueTriggers {
+		depth, ok, err := queryScalar(ctx, promAPI, queueDepthPromQL(trigger))
+		if err != nil {
+			return nil, fmt.Errorf("querying the backlog of queue trigger %d: %w", i, err)
+		}
+		states[i].depth = depth
+		if !ok || trigger.ScaleToZeroAfter == nil {
+			continue
+		}
+		peak, ok, err := queryScalar(ctx, promAPI, queueIdlePromQL(trigger, trigger.ScaleToZeroAfter.Duration))
+		if err != nil {
+			return nil, fmt.Errorf("querying the backlog of queue trigger %d: %w", i, err)
+		}
+		states[i].idle = ok && depth == 0 && peak == 0
+	}
+	return states, nil
+}
+
+// queryScalar returns the value of a query returning a single sample, or false
+// if it returned none or a non-finite one.
+func queryScalar(ctx context.Context, promAPI PrometheusClient, query string) (float64, bool, error) {
+	result, err := executePromQL(ctx, promAPI, query)
+	if err != nil {
+		return 0, false, err
+	}
+	vector, ok := result.(model.Vector)
+	if !ok {
+		return 0, false, fmt.Errorf("query returned %s, not a vector", result.Type())
+	}
+	if len(vector) == 0 || !isFinite(float64(vector[0].Value)) {
+		return 0, false, nil
+	}
+	return float64(vector[0].Value), true, nil
+}
+
+// queueAction combines the action the CPU thresholds call for with the
+// profile's queue triggers: a backlog above messagesPerReplica scales up, and
+// targets at zero replicas are scaled up as soon as messages arrive and
+// otherwise left alone. Targets are scaled to zero once every trigger sets
+// scaleToZeroAfter and stayed empty for it.
+func queueAction(profile *optimizerv1.ResourceOptimizerProfile, states []queueState, replicas int32, action string) string {
+	idle := len(states) > 0
+	for i, trigger := range profile.Spec.QueueTriggers {
+		capacity := float64(trigger.MessagesPerReplica) * float64(replicas)
+		if states[i].depth > capacity && directionAllows(profile, ScaleUpAction) {
+			return ScaleUpAction
+		}
+		idle = idle && states[i].idle
+	}
+	switch {
+	case replicas == 0:
+		return DoNothing
+	case idle && action != ScaleUpAction && directionAllows(profile, ScaleToZeroAction):
+		return ScaleToZeroAction
+	}
+	return action
+}
+
+// queueTriggersAction applies queueAction to the current backlog of the
+// profile's queue triggers, and also returns the messages waiting in all of
+// its queues.
+func queueTriggersAction(ctx context.Context, c client.Reader, promAPI PrometheusClient, profile *optimizerv1.ResourceOptimizerProfile, action string) (string, float64, error) {
+	states, err := queueStates(ctx, promAPI, profile)
+	if err != nil {
+		return "", 0, err
+	}
+	_, replicas, err := listTargetReplicas(ctx, c, profile)
+	if err != nil {
+		return "", 0, err
+	}
+	var depth float64
+	for _, state := range states {
+		depth += state.depth
+	}
+	return queueAction(profile, states, replicas, action), depth, nil
+}
+
+// targetsScaledToZero returns true when the profile has queue triggers and
+// every target it selects has zero replicas.
+func targetsScaledToZero(ctx context.Context, c client.Reader, profile *optimizerv1.ResourceOptimizerProfile) (bool, error) {
+	if profile.Spec.OptimizationPolicy == "Resize" || len(profile.Spec.QueueTriggers) == 0 {
+		return false, nil
+	}
+	names, replicas, err := listTargetReplicas(ctx, c, profile)
+	return len(names) > 0 && replicas == 0, err
+}
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/common/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Queue triggers", func() {
+	kafka := optimizerv1.QueueTrigger{Type: QueueKafka, ConsumerGroup: "billing", Topic: "invoices", MessagesPerReplica: 100,
+		ScaleToZeroAfter: &metav1.Duration{Duration: 10 * time.Minute}}
+	rabbitMQ := optimizerv1.QueueTrigger{Type: QueueRabbitMQ, Queue: "emails", MessagesPerReplica: 20}
+
+	It("should read the backlog of the consumer group or queue", func() {
+		Expect(queueDepthPromQL(kafka)).To(Equal(`sum(kafka_consumergroup_lag{consumergroup="billing", topic="invoices"})`))
+		Expect(queueIdlePromQL(kafka, 10*time.Minute)).To(Equal(`sum(max_over_time(kafka_consumergroup_lag{consumergroup="billing", topic="invoices"}[10m]))`))
+		Expect(queueDepthPromQL(rabbitMQ)).To(Equal(`sum(rabbitmq_queue_messages_ready{queue="emails"})`))
+	})
+
+	It("should only consider queues with metrics idle", func() {
+		profile := &optimizerv1.ResourceOptimizerProfile{Spec: optimizerv1.ResourceOptimizerProfileSpec{
+			QueueTriggers: []optimizerv1.QueueTrigger{kafka, rabbitMQ},
+		}}
+		states, err := queueStates(context.Background(), &mockPrometheusAPI{result: model.Vector{{Value: 0}}}, profile)
+		Expect(err).NotTo(HaveOccurred())
+		// Without scaleToZeroAfter, the RabbitMQ queue is never idle.
+		Expect(states).To(Equal([]queueState{{depth: 0, idle: true}, {depth: 0}}))
+
+		states, err = queueStates(context.Background(), &mockPrometheusAPI{result: model.Vector{}}, profile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(states).To(Equal([]queueState{{}, {}}))
+	})
+
+	It("should scale on the backlog per replica and to and from zero", func() {
+		profile := &optimizerv1.ResourceOptimizerProfile{Spec: optimizerv1.ResourceOptimizerProfileSpec{
+			QueueTriggers: []optimizerv1.QueueTrigger{kafka},
+		}}
+		Expect(queueAction(profile, []queueState{{depth: 350}}, 3, DoNothing)).To(Equal(ScaleUpAction))
+		Expect(queueAction(profile, []queueState{{depth: 250}}, 3, ScaleDownAction)).To(Equal(ScaleDownAction))
+		Expect(queueAction(profile, []queueState{{idle: true}}, 3, DoNothing)).To(Equal(ScaleToZeroAction))
+		Expect(queueAction(profile, []queueState{{idle: true}}, 3, ScaleUpAction)).To(Equal(ScaleUpAction))
+
+		// Targets at zero replicas wait for messages.
+		Expect(queueAction(profile, []queueState{{depth: 1}}, 0, ScaleDownAction)).To(Equal(ScaleUpAction))
+		Expect(queueAction(profile, []queueState{{idle: true}}, 0, ScaleDownAction)).To(Equal(DoNothing))
+
+		// Every trigger must be idle.
+		profile.Spec.QueueTriggers = append(profile.Spec.QueueTriggers, rabbitMQ)
+		Expect(queueAction(profile, []queueState{{idle: true}, {}}, 3, DoNothing)).To(Equal(DoNothing))
+
+		profile.Spec.Direction = DirectionUpOnly
+		Expect(queueAction(profile, []queueState{{idle: true}, {idle: true}}, 3, DoNothing)).To(Equal(DoNothing))
+	})
+
+	It("should scale targets to zero replicas", func() {
+		Expect(steppedReplicas(&optimizerv1.ResourceOptimizerProfile{}, ScaleToZeroAction, 5)).To(BeZero())
+		Expect(steppedReplicas(&optimizerv1.ResourceOptimizerProfile{}, ScaleUpAction, 0)).To(Equal(int32(1)))
+	})
+})
@@ -0,0 +1,25 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// expireRecommendations clears the recommendations and diff of a profile once
+// they are older than its recommendationTTL, e.g. because its metrics could
+// not be queried since. It reports whether they expired.
+func expireRecommendations(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, now time.Time) bool {
+	ttl, recommendedAt := profile.Spec.RecommendationTTL, profile.Status.RecommendedAt
+	if ttl == nil || recommendedAt == nil || now.Sub(recommendedAt.Time) <= ttl.Duration {
+		return false
+	}
+	log.FromContext(ctx).Info("Clearing expired recommendations", "recommendedAt", recommendedAt.Time, "ttl", ttl.Duration.String())
+	profile.Status.Recommendations = nil
+	profile.Status.Diff = nil
+	profile.Status.RecommendedAt = nil
+	return true
+}
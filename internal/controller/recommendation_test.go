@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Recommendation expiry", func() {
+	var profile *optimizerv1.ResourceOptimizerProfile
+	now := time.Now()
+
+	BeforeEach(func() {
+		profile = &optimizerv1.ResourceOptimizerProfile{Spec: optimizerv1.ResourceOptimizerProfileSpec{OptimizationPolicy: "Recommend"}}
+		profile.Status.Recommendations = []string{"CPU usage is 90.00%. Consider ScaleUp."}
+		profile.Status.Diff = []optimizerv1.TargetDiff{{Kind: "Deployment", Name: "web", Diff: "replicas 3→4"}}
+		profile.Status.RecommendedAt = &metav1.Time{Time: now.Add(-2 * time.Hour)}
+	})
+
+	It("should keep recommendations without a recommendationTTL", func() {
+		Expect(expireRecommendations(context.Background(), profile, now)).To(BeFalse())
+		Expect(profile.Status.Recommendations).To(HaveLen(1))
+	})
+
+	It("should clear recommendations older than the recommendationTTL", func() {
+		profile.Spec.RecommendationTTL = &metav1.Duration{Duration: 3 * time.Hour}
+		Expect(expireRecommendations(context.Background(), profile, now)).To(BeFalse())
+		Expect(profile.Status.Recommendations).To(HaveLen(1))
+
+		profile.Spec.RecommendationTTL = &metav1.Duration{Duration: time.Hour}
+		Expect(expireRecommendations(context.Background(), profile, now)).To(BeTrue())
+		Expect(profile.Status.Recommendations).To(BeNil())
+		Expect(profile.Status.Diff).To(BeNil())
+		Expect(profile.Status.RecommendedAt).To(BeNil())
+	})
+})
@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/common/model"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=prometheusrules,verbs=get;list;watch;create;update;patch;delete
+
+// RecordedCPUUtilizationMetric is the series recorded for profiles with a
+// recordingRule: the CPU utilization of each of their pods in percent of its
+// requests, labelled with the namespace and name of the profile.
+const RecordedCPUUtilizationMetric = "k20s:pod_cpu_utilization:percent"
+
+// prometheusRuleGVK is the kind of the Prometheus Operator's rule objects.
+var prometheusRuleGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PrometheusRule"}
+
+// recordingRuleName returns the name of the PrometheusRule maintained for the
+// profile.
+func recordingRuleName(profile *optimizerv1.ResourceOptimizerProfile) string {
+	return "k20s-" + profile.Name
+}
+
+// usesRecordingRule reports whether the profile's utilization is read from the
+// series recorded by its PrometheusRule.
+func usesRecordingRule(profile *optimizerv1.ResourceOptimizerProfile) bool {
+	return profile.Spec.RecordingRule && profile.Spec.MetricQuery == "" && profile.Status.RecordingRule != ""
+}
+
+// recordedPromQL selects the series recorded for the profile's pods whose
+// names match podNameRegex.
+func recordedPromQL(profile *optimizerv1.ResourceOptimizerProfile, podNameRegex string) string {
+	return fmt.Sprintf(`%s{namespace="%s", profile="%s", pod=~"%s"}`,
+		RecordedCPUUtilizationMetric, profile.Namespace, profile.Name, podNameRegex)
+}
+
+// buildRecordingRule returns the PrometheusRule recording the CPU utilization
+// of the pods whose names match podNameRegex for the profile. The pods are
+// matched by the names of the profile's workloads, so that the rule only
+// changes when its targets do.
+func buildRecordingRule(profile *optimizerv1.ResourceOptimizerProfile, podNameRegex string, ruleLabels map[string]string) *unstructured.Unstructured {
+	rule := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"groups": []interface{}{
+				map[string]interface{}{
+					"name": "k20s.profile." + profile.Name,
+					"rules": []interface{}{
+						map[string]interface{}{
+							"record": RecordedCPUUtilizationMetric,
+							"expr":   cpuPromQL(profile.Namespace, podNameRegex),
+							"labels": map[string]interface{}{
+								"namespace": profile.Namespace,
+								"profile":   profile.Name,
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+	rule.SetGroupVersionKind(prometheusRuleGVK)
+	rule.SetNamespace(profile.Namespace)
+	rule.SetName(recordingRuleName(profile))
+	objLabels := map[string]string{
+		"app.kubernetes.io/name":       "k20s",
+		"app.kubernetes.io/managed-by": "k20s",
+	}
+	for k, v := range ruleLabels {
+		objLabels[k] = v
+	}
+	rule.SetLabels(objLabels)
+	rule.SetOwnerReferences([]metav1.OwnerReference{*metav1.NewControllerRef(profile, optimizerv1.GroupVersion.WithKind("ResourceOptimizerProfile"))})
+	return rule
+}
+
+// reconcileRecordingRule applies the PrometheusRule of a profile with a
+// recordingRule, and deletes it once the profile no longer has one. The rule is
+// owned by the profile, so it is garbage collected with it. Nothing is created
+// when the Prometheus Operator CRDs are not installed.
+func (r *ResourceOptimizerProfileReconciler) reconcileRecordingRule(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile) error {
+	logger := log.FromContext(ctx)
+	if !profile.Spec.RecordingRule || profile.Spec.MetricQuery != "" {
+		if profile.Status.RecordingRule == "" {
+			return nil
+		}
+		rule := &unstructured.Unstructured{}
+		rule.SetGroupVersionKind(prometheusRuleGVK)
+		rule.SetNamespace(profile.Namespace)
+		rule.SetName(profile.Status.RecordingRule)
+		if err := r.Delete(ctx, rule); err != nil && !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+			return err
+		}
+		logger.Info("Deleted recording rule", "prometheusRule", profile.Status.RecordingRule)
+		profile.Status.RecordingRule = ""
+		return nil
+	}
+
+	podNameRegex, err := targetPodNameRegex(ctx, r.Client, profile)
+	if err != nil {
+		return err
+	}
+	if podNameRegex == "" {
+		return nil
+	}
+	rule := buildRecordingRule(profile, podNameRegex, r.RecordingRuleLabels)
+	if err := r.Apply(ctx, client.ApplyConfigurationFromUnstructured(rule), client.FieldOwner("k20s"), client.ForceOwnership); err != nil {
+		if meta.IsNoMatchError(err) {
+			logger.Info("Prometheus Operator CRD is not installed, querying the pods directly", "group", prometheusRuleGVK.Group)
+			profile.Status.RecordingRule = ""
+			return nil
+		}
+		return err
+	}
+	profile.Status.RecordingRule = rule.GetName()
+	return nil
+}
+
+// queryPodsDirectly runs the built-in CPU query when the series recorded for
+// the profile returned no samples, as it does until Prometheus loaded and
+// evaluated its rule. Other results are returned as they are.
+func queryPodsDirectly(ctx context.Context, c client.Reader, promAPI PrometheusClient, profile *optimizerv1.ResourceOptimizerProfile, result model.Value) (model.Value, error) {
+	if vector, ok := result.(model.Vector); !usesRecordingRule(profile) || !ok || len(vector) > 0 {
+		return result, nil
+	}
+	podNameRegex, err := listPodNameRegex(ctx, c, profile)
+	if err != nil || podNameRegex == "" {
+		return result, err
+	}
+	log.FromContext(ctx).Info("The recorded series has no samples yet, querying the pods directly", "metric", RecordedCPUUtilizationMetric)
+	return executePromQL(ctx, promAPI, cpuPromQL(profile.Namespace, podNameRegex))
+}
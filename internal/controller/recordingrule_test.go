@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// recordedSeriesAPI answers queries of the recorded series with recorded and
+// every other query with direct.
+type recordedSeriesAPI struct {
+	recorded, direct model.Vector
+	queries          []string
+}
+
+func (m *recordedSeriesAPI) Query(_ context.Context, query string, _ time.Time, _ ...prometheusv1.Option) (model.Value, prometheusv1.Warnings, error) {
+	m.queries = append(m.queries, query)
+	if strings.HasPrefix(query, RecordedCPUUtilizationMetric) {
+		return m.recorded, nil, nil
+	}
+	return m.direct, nil, nil
+}
+
+func (m *recordedSeriesAPI) QueryRange(context.Context, string, prometheusv1.Range, ...prometheusv1.Option) (model.Value, prometheusv1.Warnings, error) {
+	return model.Matrix{}, nil, nil
+}
+
+var _ = Describe("Recording rules", func() {
+	labels := map[string]string{"app": "web"}
+	var (
+		profile *optimizerv1.ResourceOptimizerProfile
+		scheme  *runtime.Scheme
+	)
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		profile = &optimizerv1.ResourceOptimizerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", UID: "uid"},
+			Spec: optimizerv1.ResourceOptimizerProfileSpec{
+				Selector:      metav1.LabelSelector{MatchLabels: labels},
+				CPUThresholds: optimizerv1.ThresholdSpec{Min: 20, Max: 80},
+				RecordingRule: true,
+			},
+		}
+	})
+
+	It("should record the utilization of the pods of the profile's workloads", func() {
+		rule := buildRecordingRule(profile, "web-[a-z0-9]+-[a-z0-9]+", map[string]string{"release": "prometheus"})
+		Expect(rule.GetName()).To(Equal("k20s-web"))
+		Expect(rule.GetNamespace()).To(Equal("team-a"))
+		Expect(rule.GetLabels()).To(HaveKeyWithValue("release", "prometheus"))
+		Expect(metav1.IsControlledBy(rule, profile)).To(BeTrue())
+
+		groups, _, _ := unstructured.NestedSlice(rule.Object, "spec", "groups")
+		Expect(groups).To(HaveLen(1))
+		rules := groups[0].(map[string]interface{})["rules"].([]interface{})
+		Expect(rules).To(ConsistOf(map[string]interface{}{
+			"record": RecordedCPUUtilizationMetric,
+			"expr":   cpuPromQL("team-a", "web-[a-z0-9]+-[a-z0-9]+"),
+			"labels": map[string]interface{}{"namespace": "team-a", "profile": "web"},
+		}))
+	})
+
+	It("should query the recorded series once the rule is maintained", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "team-a", Labels: labels}},
+		).Build()
+
+		query, err := buildPromQL(context.Background(), c, profile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(query).To(Equal(cpuPromQL("team-a", "web-1")))
+
+		profile.Status.RecordingRule = "k20s-web"
+		query, err = buildPromQL(context.Background(), c, profile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(query).To(Equal(`k20s:pod_cpu_utilization:percent{namespace="team-a", profile="web", pod=~"web-1"}`))
+	})
+
+	It("should query the pods directly until the recorded series has samples", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "team-a", Labels: labels}},
+		).Build()
+		profile.Status.RecordingRule = "k20s-web"
+		promAPI := &recordedSeriesAPI{recorded: model.Vector{}, direct: model.Vector{{Value: 42}}}
+
+		value, err := QueryCPU(context.Background(), c, promAPI, profile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal(42.0))
+		Expect(promAPI.queries).To(HaveLen(2))
+
+		promAPI.recorded = model.Vector{{Value: 60}}
+		promAPI.queries = nil
+		value, err = QueryCPU(context.Background(), c, promAPI, profile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal(60.0))
+		Expect(promAPI.queries).To(HaveLen(1))
+	})
+})
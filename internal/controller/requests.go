@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/common/model"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// Sources of the request metrics of a profile's requestsPerSecond.
+const (
+	RequestsSourceIstio = "Istio"
+	RequestsSourceNGINX = "NGINX"
+)
+
+// requestsPromQL calculates the requests per second, averaged over 2 minutes,
+// received by the profile's Service or, with Istio and no Service, by the named
+// workloads.
+func requestsPromQL(namespace string, rps *optimizerv1.RequestsPerSecond, workloads []string) string {
+	if rps.Source == RequestsSourceNGINX {
+		return fmt.Sprintf(`sum(rate(nginx_ingress_controller_requests{namespace="%s", service="%s"}[2m]))`,
+			namespace, rps.Service)
+	}
+	return fmt.Sprintf(`sum(rate(istio_requests_total{%s}[2m]))`, istioDestination(namespace, rps.Service, workloads))
+}
+
+// istioDestination returns the label matchers of the Istio metrics reported by
+// the Service or, when it is empty, by the named workloads.
+func istioDestination(namespace, service string, workloads []string) string {
+	if service != "" {
+		return fmt.Sprintf(`reporter="destination", destination_workload_namespace="%s", destination_service_name="%s"`, namespace, service)
+	}
+	patterns := make([]string, len(workloads))
+	for i, workload := range workloads {
+		patterns[i] = quoteName(workload)
+	}
+	return fmt.Sprintf(`reporter="destination", destination_workload_namespace="%s", destination_workload=~"%s"`,
+		namespace, strings.Join(patterns, "|"))
+}
+
+// requestsPerReplica returns the requests per second per replica of the
+// profile's targets. It returns false when the profile has no
+// requestsPerSecond, or no targets.
+func requestsPerReplica(ctx context.Context, c client.Reader, promAPI PrometheusClient, profile *optimizerv1.ResourceOptimizerProfile) (float64, bool, error) {
+	rps := profile.Spec.RequestsPerSecond
+	if rps == nil {
+		return 0, false, nil
+	}
+	workloads, replicas, err := listTargetReplicas(ctx, c, profile)
+	if err != nil || len(workloads) == 0 {
+		return 0, false, err
+	}
+
+	result, err := executePromQL(ctx, promAPI, requestsPromQL(profile.Namespace, rps, workloads))
+	if err != nil {
+		return 0, false, err
+	}
+	vector, ok := result.(model.Vector)
+	if !ok {
+		return 0, false, fmt.Errorf("requests query returned %s, not a vector", result.Type())
+	}
+	var total float64
+	for _, sample := range vector {
+		if isFinite(float64(sample.Value)) {
+			total += float64(sample.Value)
+		}
+	}
+	// Scaled to zero, a single replica would receive all the traffic.
+	return total / float64(max(replicas, 1)), true, nil
+}
+
+// listTargetReplicas returns the names of the Deployments and StatefulSets the
+// profile selects, and their total desired replicas.
+func listTargetReplicas(ctx context.Context, c client.Reader, profile *optimizerv1.ResourceOptimizerProfile) ([]string, int32, error) {
+	listOpts := &client.ListOptions{LabelSelector: labels.Set(profile.Spec.Selector.MatchLabels).AsSelector(), Namespace: profile.Namespace}
+	var deployments appsv1.DeploymentList
+	if err := c.List(ctx, &deployments, listOpts); err != nil {
+		return nil, 0, err
+	}
+	var statefulSets appsv1.StatefulSetList
+	if err := c.List(ctx, &statefulSets, listOpts); err != nil {
+		return nil, 0, err
+	}
+	var names []string
+	var replicas int32
+	for _, deployment := range deployments.Items {
+		names = append(names, deployment.Name)
+		replicas += ptrValue(deployment.Spec.Replicas, 1)
+	}
+	for _, ss := range statefulSets.Items {
+		names = append(names, ss.Name)
+		replicas += ptrValue(ss.Spec.Replicas, 1)
+	}
+	return names, replicas, nil
+}
+
+// ptrValue returns *p, or def if p is nil.
+func ptrValue(p *int32, def int32) int32 {
+	if p == nil {
+		return def
+	}
+	return *p
+}
+
+// requestsAction combines the action the CPU thresholds call for with the
+// profile's requestsPerSecond thresholds: traffic above max scales up, and
+// traffic at or above min holds back a scale-down.
+func requestsAction(profile *optimizerv1.ResourceOptimizerProfile, perReplica float64, action string) string {
+	rps := profile.Spec.RequestsPerSecond
+	switch {
+	case perReplica > float64(rps.Max) && directionAllows(profile, ScaleUpAction):
+		return ScaleUpAction
+	case action == ScaleDownAction && perReplica >= float64(rps.Min):
+		return DoNothing
+	}
+	return action
+}
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/common/model"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Requests per second", func() {
+	It("should count the requests of the workloads or the Service", func() {
+		istio := &optimizerv1.RequestsPerSecond{Source: RequestsSourceIstio}
+		Expect(requestsPromQL("shop", istio, []string{"web", "api.v2"})).To(Equal(
+			`sum(rate(istio_requests_total{reporter="destination", destination_workload_namespace="shop", destination_workload=~"web|api[.]v2"}[2m]))`))
+
+		istio.Service = "checkout"
+		Expect(requestsPromQL("shop", istio, []string{"web"})).To(Equal(
+			`sum(rate(istio_requests_total{reporter="destination", destination_workload_namespace="shop", destination_service_name="checkout"}[2m]))`))
+
+		nginx := &optimizerv1.RequestsPerSecond{Source: RequestsSourceNGINX, Service: "checkout"}
+		Expect(requestsPromQL("shop", nginx, nil)).To(Equal(
+			`sum(rate(nginx_ingress_controller_requests{namespace="shop", service="checkout"}[2m]))`))
+	})
+
+	It("should divide the requests among the replicas", func() {
+		labels := map[string]string{"app": "web"}
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", Labels: labels},
+				Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](3)},
+			},
+			&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "cache", Namespace: "shop", Labels: labels}},
+		).Build()
+		profile := &optimizerv1.ResourceOptimizerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Spec:       optimizerv1.ResourceOptimizerProfileSpec{Selector: metav1.LabelSelector{MatchLabels: labels}},
+		}
+		promAPI := &mockPrometheusAPI{result: model.Vector{{Value: 600}}}
+
+		_, ok, err := requestsPerReplica(context.Background(), c, promAPI, profile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+
+		profile.Spec.RequestsPerSecond = &optimizerv1.RequestsPerSecond{Source: RequestsSourceIstio, Min: 50, Max: 200}
+		perReplica, ok, err := requestsPerReplica(context.Background(), c, promAPI, profile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(perReplica).To(Equal(150.0))
+	})
+
+	It("should scale up on high traffic and hold scale-downs on moderate traffic", func() {
+		profile := &optimizerv1.ResourceOptimizerProfile{Spec: optimizerv1.ResourceOptimizerProfileSpec{
+			RequestsPerSecond: &optimizerv1.RequestsPerSecond{Source: RequestsSourceIstio, Min: 50, Max: 200},
+		}}
+		Expect(requestsAction(profile, 250, DoNothing)).To(Equal(ScaleUpAction))
+		Expect(requestsAction(profile, 250, ScaleDownAction)).To(Equal(ScaleUpAction))
+		Expect(requestsAction(profile, 100, ScaleDownAction)).To(Equal(DoNothing))
+		Expect(requestsAction(profile, 100, ScaleUpAction)).To(Equal(ScaleUpAction))
+		Expect(requestsAction(profile, 10, ScaleDownAction)).To(Equal(ScaleDownAction))
+
+		profile.Spec.Direction = DirectionDownOnly
+		Expect(requestsAction(profile, 250, DoNothing)).To(Equal(DoNothing))
+	})
+})
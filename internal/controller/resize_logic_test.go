@@ -24,15 +24,27 @@ import (
 
 // mockPrometheusAPI allows us to simulate responses from Prometheus.
 type mockPrometheusAPI struct {
-	result model.Value
-	err    error
+	result      model.Value
+	rangeResult model.Value
+	warnings    prometheusv1.Warnings
+	err         error
 }
 
 func (m *mockPrometheusAPI) Query(ctx context.Context, query string, ts time.Time, opts ...prometheusv1.Option) (model.Value, prometheusv1.Warnings, error) {
 	if m.err != nil {
 		return nil, nil, m.err
 	}
-	return m.result, nil, nil
+	return m.result, m.warnings, nil
+}
+
+func (m *mockPrometheusAPI) QueryRange(ctx context.Context, query string, r prometheusv1.Range, opts ...prometheusv1.Option) (model.Value, prometheusv1.Warnings, error) {
+	if m.err != nil {
+		return nil, nil, m.err
+	}
+	if m.rangeResult == nil {
+		return model.Matrix{}, nil, nil
+	}
+	return m.rangeResult, nil, nil
 }
 
 var _ = Describe("Resize Logic", func() {
@@ -133,6 +145,35 @@ var _ = Describe("Resize Logic", func() {
 		})
 	})
 
+	Context("When downward actions are disabled", func() {
+		It("should not resize the CPU request down", func() {
+			// A pod of the deployment, so that a query is built
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: appName + "-pod", Namespace: testNamespace, Labels: map[string]string{"app": appName}},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "nginx"}}},
+			}
+			Expect(k8sClient.Create(context.Background(), pod)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(context.Background(), pod)).To(Succeed())
+			}()
+
+			mockAPI := &mockPrometheusAPI{result: model.Vector{{Value: 10}}}
+			reconciler = &ResourceOptimizerProfileReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), PrometheusAPI: mockAPI, DisableDownwardActions: true}
+
+			_, err := reconciler.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: profile.Name, Namespace: profile.Namespace}})
+			Expect(err).NotTo(HaveOccurred())
+
+			updatedDeployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: appName, Namespace: testNamespace}, updatedDeployment)).To(Succeed())
+			currentRequest := updatedDeployment.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU]
+			Expect(currentRequest.String()).To(Equal("500m"))
+
+			updatedProfile := &optimizerv1.ResourceOptimizerProfile{}
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: profile.Name, Namespace: profile.Namespace}, updatedProfile)).To(Succeed())
+			Expect(updatedProfile.Status.LastAction).To(BeNil())
+		})
+	})
+
 	Context("When a scale action was recently performed", func() {
 		It("should respect the cooldown period and not perform another action", func() {
 			// 1. Set a recent LastAction status on the profile to simulate a recent action
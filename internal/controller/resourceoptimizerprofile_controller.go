@@ -18,8 +18,10 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
+	"slices"
+	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -27,15 +29,20 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
-	"github.com/prometheus/client_golang/prometheus"
+	"github.com/OpScaleHub/K20s/internal/audit"
+	"github.com/OpScaleHub/K20s/internal/notify"
+	"github.com/OpScaleHub/K20s/internal/pricing"
 	"github.com/prometheus/common/model"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 const (
@@ -46,29 +53,6 @@ const (
 	DoNothing        = "DoNothing"
 )
 
-var (
-	scaleUpActions = prometheus.NewCounter(prometheus.CounterOpts{
-		Name: "k20s_scale_up_actions_total",
-		Help: "Total number of scale up actions taken",
-	})
-	scaleDownActions = prometheus.NewCounter(prometheus.CounterOpts{
-		Name: "k20s_scale_down_actions_total",
-		Help: "Total number of scale down actions taken",
-	})
-	resizeUpActions = prometheus.NewCounter(prometheus.CounterOpts{
-		Name: "k20s_resize_up_actions_total",
-		Help: "Total number of resize up actions taken",
-	})
-	resizeDownActions = prometheus.NewCounter(prometheus.CounterOpts{
-		Name: "k20s_resize_down_actions_total",
-		Help: "Total number of resize down actions taken",
-	})
-)
-
-func init() {
-	metrics.Registry.MustRegister(scaleUpActions, scaleDownActions, resizeUpActions, resizeDownActions)
-}
-
 // ResourceOptimizerProfileReconciler reconciles a ResourceOptimizerProfile object
 type ResourceOptimizerProfileReconciler struct {
 	client.Client
@@ -76,6 +60,99 @@ type ResourceOptimizerProfileReconciler struct {
 	PrometheusAPI PrometheusClient
 	// PrometheusURL records the URL used to connect to Prometheus (for logging/debugging)
 	PrometheusURL string
+	// PrometheusTLS configures the connection to a Prometheus served over HTTPS.
+	PrometheusTLS PrometheusTLSConfig
+	// PrometheusHealth tracks whether queries succeed. Nil disables tracking.
+	PrometheusHealth *PrometheusHealth
+	// MaxMetricProfiles caps the number of profiles exported with their own metric
+	// labels. Zero or less disables the limit.
+	MaxMetricProfiles int
+	// MaxMetricTargets caps the number of targets per profile exported by the
+	// per-target gauges. Zero or less disables the limit.
+	MaxMetricTargets int
+	// Audit receives a record for every patch applied to a target. Nil disables auditing.
+	Audit audit.Recorder
+	// Notifier receives an event for every applied action and recommendation. Nil
+	// disables notifications.
+	Notifier notify.Notifier
+	// Channels resolves the NotificationChannels referenced by a profile. Nil
+	// disables per-profile notifications.
+	Channels *notify.ChannelResolver
+	// CPUHistory receives the CPU utilization observed on every reconcile. Nil
+	// disables it.
+	CPUHistory CPUObserver
+	// Namespaces limits the namespaces whose workloads are changed. Profiles
+	// elsewhere are marked Degraded and left alone.
+	Namespaces NamespaceFilter
+	// Pricing prices the CPU requested beyond the recommendations. Nil disables
+	// the savings estimate.
+	Pricing pricing.Provider
+	// PricingRegion is the region whose prices apply to the cluster.
+	PricingRegion string
+	// Autoscaler holds back scale-ups while the cluster waits for capacity. Nil
+	// disables the check.
+	Autoscaler *ClusterAutoscalerGate
+	// CheckCapacity limits scale-ups to the replicas the nodes their pods may
+	// be scheduled on have room for, by CPU requests.
+	CheckCapacity bool
+	// Compactor evicts the pods of the targets a resize-down changed from
+	// lightly requested nodes. Nil disables compaction.
+	Compactor *Compactor
+	// Alerts is consulted for profiles that hold actions while alerts are
+	// firing. Nil disables holding.
+	Alerts AlertSource
+	// Approver must approve every action on a target before it is applied. Nil
+	// approves every action.
+	Approver ActionApprover
+	// ProtectedWorkloads select Deployments and StatefulSets that are never
+	// changed, whatever the profiles selecting them say.
+	ProtectedWorkloads []labels.Selector
+	// DisableDownwardActions skips every scale-down and resize-down, whatever
+	// the profile's direction, while scale-ups and resize-ups go ahead.
+	DisableDownwardActions bool
+	// TargetRetryBudget is the number of consecutive failed actions after which
+	// a target is marked Degraded. Zero or less uses DefaultTargetRetryBudget.
+	TargetRetryBudget int
+	// ActionFailureBudget is the number of consecutive failed actions after
+	// which a profile's actions are suspended until its spec is edited or it
+	// is annotated to resume them. Zero or less uses
+	// DefaultActionFailureBudget.
+	ActionFailureBudget int
+	// QueryTimeout caps every Prometheus query of profiles without a
+	// queryTimeout. Zero or less leaves their queries unbounded.
+	QueryTimeout time.Duration
+	// PartialResponsePolicy handles the Prometheus queries that returned
+	// warnings for profiles without a partialResponsePolicy: Fail, Warn or
+	// Skip. Empty uses Warn.
+	PartialResponsePolicy string
+	// MetricsCacheTTL is how long the result of a Prometheus query is reused
+	// by later reconciles running the same query. Zero or less disables the
+	// cache.
+	MetricsCacheTTL time.Duration
+	// RequeueJitter is the fraction of ReconcileInterval added at random to
+	// each periodic requeue. Zero or less requeues every profile exactly
+	// ReconcileInterval later.
+	RequeueJitter float64
+	// BusinessHours defers the scale-downs and resize-downs of profiles without
+	// their own businessHours. Nil defers nothing.
+	BusinessHours *optimizerv1.BusinessHours
+	// RecordingRuleLabels are added to the PrometheusRules maintained for
+	// profiles with a recordingRule, e.g. to match the Prometheus Operator's
+	// rule selector.
+	RecordingRuleLabels map[string]string
+
+	// scaleHistory backs the behavior of Scale profiles.
+	scaleHistory scaleHistory
+	// usageHistory backs the usageHistory of Resize profiles.
+	usageHistory usageHistograms
+}
+
+// CPUObserver keeps the CPU utilization observed for profiles over time.
+type CPUObserver interface {
+	// ObserveCPU records the utilization observed for a profile at a time.
+	ObserveCPU(profile types.NamespacedName, at time.Time, value float64)
+	// Forget drops everything recorded for a deleted profile.
+	Forget(profile types.NamespacedName)
 }
 
 // +kubebuilder:rbac:groups=optimizer.k20s.opscale.ir,resources=resourceoptimizerprofiles,verbs=get;list;watch;create;update;patch;delete
@@ -92,10 +169,50 @@ func (r *ResourceOptimizerProfileReconciler) Reconcile(ctx context.Context, req
 	// 1. Fetch ResourceOptimizerProfile
 	var resourceOptimizerProfile optimizerv1.ResourceOptimizerProfile
 	if err := r.Get(ctx, req.NamespacedName, &resourceOptimizerProfile); err != nil {
+		if apierrors.IsNotFound(err) {
+			forgetProfileMetrics(req.NamespacedName)
+			if r.CPUHistory != nil {
+				r.CPUHistory.Forget(req.NamespacedName)
+			}
+			r.scaleHistory.forget(req.NamespacedName)
+			if err := r.pruneTargets(ctx, req.Namespace); err != nil {
+				logger.Error(err, "unable to forget the targets of the deleted profile")
+			}
+		}
 		logger.Error(err, "unable to fetch ResourceOptimizerProfile")
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	ctx = withQueryTimeout(ctx, r.queryTimeout(&resourceOptimizerProfile))
+	ctx = withPartialResponsePolicy(ctx, r.partialResponsePolicy(&resourceOptimizerProfile))
+
+	if err := r.resumeActions(ctx, &resourceOptimizerProfile); err != nil {
+		logger.Error(err, "unable to resume suspended actions")
+		return ctrl.Result{}, err
+	}
+	if expireRecommendations(ctx, &resourceOptimizerProfile, time.Now()) {
+		if err := r.Status().Update(ctx, &resourceOptimizerProfile); err != nil {
+			logger.Error(err, "unable to clear expired recommendations")
+			return ctrl.Result{}, err
+		}
+	}
+	if err := r.pruneTargets(ctx, req.Namespace); err != nil {
+		logger.Error(err, "unable to forget deleted targets")
+	}
+	if err := pruneFailingTargets(ctx, r.Client, &resourceOptimizerProfile); err != nil {
+		logger.Error(err, "unable to forget deleted failing targets")
+	}
+
+	if err := r.Namespaces.Check(req.Namespace); err != nil {
+		logger.Info("Ignoring profile outside the allowed namespaces", "reason", err.Error())
+		r.markDegraded(ctx, &resourceOptimizerProfile, "NamespaceNotAllowed", err)
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.reconcileRecordingRule(ctx, &resourceOptimizerProfile); err != nil {
+		logger.Error(err, "unable to maintain the recording rule")
+	}
+
 	// 2. Query Prometheus for metrics
 	logger.Info("Querying Prometheus for metrics...")
 	query, err := buildPromQL(ctx, r.Client, &resourceOptimizerProfile)
@@ -106,8 +223,28 @@ func (r *ResourceOptimizerProfileReconciler) Reconcile(ctx context.Context, req
 	// Log the query and Prometheus endpoint to make DNS/connectivity problems obvious
 	logger.Info("Built PromQL query", "query", query, "prometheusURL", r.PrometheusURL)
 	result, err := executePromQL(ctx, r.PrometheusAPI, query) // This function is not provided, assuming it exists
+	if err == nil {
+		result, err = queryPodsDirectly(ctx, r.Client, r.PrometheusAPI, &resourceOptimizerProfile, result)
+	}
+	var partial *PartialResponseError
+	isPartial := errors.As(err, &partial)
+	if r.PrometheusHealth != nil {
+		// Prometheus answered a partial response, so it is reachable.
+		if isPartial {
+			r.PrometheusHealth.recordQuery(nil, time.Now())
+		} else {
+			r.PrometheusHealth.recordQuery(err, time.Now())
+		}
+	}
+	if isPartial && r.partialResponsePolicy(&resourceOptimizerProfile) == PartialResponseSkip {
+		logger.Info("Skipping the reconcile on a partial response", "warnings", partial.Warnings)
+		r.markPartialResponse(ctx, &resourceOptimizerProfile, partial)
+		return ctrl.Result{RequeueAfter: r.requeueInterval()}, nil
+	}
 	if err != nil {
 		logger.Error(err, "error querying Prometheus")
+		r.recordQueryError(&resourceOptimizerProfile)
+		r.markDegraded(ctx, &resourceOptimizerProfile, "QueryFailed", err)
 		return ctrl.Result{}, err
 	}
 	logger.Info("Prometheus query result", "result", result)
@@ -115,136 +252,293 @@ func (r *ResourceOptimizerProfileReconciler) Reconcile(ctx context.Context, req
 	// 3. Compare against thresholds
 	logger.Info("Comparing metrics against thresholds...")
 	var value float64
+	scaledToZero := false
 	switch result.Type() {
 	case model.ValVector:
-		vector := result.(model.Vector)
-		if len(vector) > 0 {
-			// Average across all returned pod series to derive a representative value
-			var sum float64
-			// Log each sample for debugging
-			for _, sample := range vector {
-				// attempt to extract pod label, fall back to the full metric
-				pod := "unknown"
-				if m, ok := sample.Metric["pod"]; ok {
-					pod = string(m)
-				}
-				log.FromContext(ctx).Info("Prometheus sample", "pod", pod, "value", float64(sample.Value))
-				sum += float64(sample.Value)
+		vector, dropped := finiteSamples(result.(model.Vector))
+		if r.observeInvalidSamples(&resourceOptimizerProfile, dropped) {
+			if err := r.Status().Update(ctx, &resourceOptimizerProfile); err != nil {
+				logger.Error(err, "unable to record the InvalidSamples condition")
+				return ctrl.Result{}, err
+			}
+		}
+		if len(vector) == 0 && len(dropped) > 0 {
+			logger.Info("Every sample is non-finite, taking no action", "pods", dropped)
+			return ctrl.Result{RequeueAfter: r.requeueInterval()}, nil
+		}
+		if len(vector) == 0 {
+			// Consumers scaled to zero by queue triggers have no metrics, but
+			// must be scaled up again when messages arrive.
+			scaledToZero, err = targetsScaledToZero(ctx, r.Client, &resourceOptimizerProfile)
+			if err != nil {
+				logger.Error(err, "error listing targets")
+				return ctrl.Result{}, err
+			}
+			if !scaledToZero {
+				return r.reconcileWithoutHistory(ctx, &resourceOptimizerProfile)
 			}
-			value = sum / float64(len(vector))
-			log.FromContext(ctx).Info("Computed CPU percent (average)", "value", value, "seriesCount", len(vector))
 		}
+		// Average across all returned pod series to derive a representative value
+		value = averageCPU(ctx, vector)
 	default:
 		logger.Info("Prometheus query did not return a vector")
-		return ctrl.Result{RequeueAfter: time.Minute * 5}, nil
+		return ctrl.Result{RequeueAfter: r.requeueInterval()}, nil
+	}
+
+	r.recordObservedCPU(&resourceOptimizerProfile, value)
+	if r.CPUHistory != nil {
+		r.CPUHistory.ObserveCPU(req.NamespacedName, time.Now(), value)
+	}
+	if err := r.observeUsage(ctx, &resourceOptimizerProfile, value); err != nil {
+		logger.Error(err, "error observing CPU usage")
+	}
+	if err := r.publishRecommendedCPU(ctx, &resourceOptimizerProfile, value); err != nil {
+		logger.Error(err, "error computing recommended CPU requests")
 	}
 
-	cpuThresholds := resourceOptimizerProfile.Spec.CPUThresholds
-	var action string
+	r.updateConfidence(ctx, &resourceOptimizerProfile)
 
-	if value < float64(cpuThresholds.Min) {
-		if resourceOptimizerProfile.Spec.OptimizationPolicy == "Resize" {
-			action = ResizeDownAction
-		} else {
-			action = ScaleDownAction
+	action := decideAction(&resourceOptimizerProfile, value)
+
+	observedMetrics := map[string]string{"cpu_usage": fmt.Sprintf("%.2f", value)}
+	if resourceOptimizerProfile.Spec.OptimizationPolicy != "Resize" {
+		perReplica, ok, err := requestsPerReplica(ctx, r.Client, r.PrometheusAPI, &resourceOptimizerProfile)
+		if err != nil {
+			logger.Error(err, "error querying requests per second")
+		} else if ok {
+			observedMetrics["requests_per_second"] = fmt.Sprintf("%.2f", perReplica)
+			action = requestsAction(&resourceOptimizerProfile, perReplica, action)
+		}
+		latency, ok, err := observedLatency(ctx, r.Client, r.PrometheusAPI, &resourceOptimizerProfile)
+		if err != nil {
+			logger.Error(err, "error querying request latency")
+		} else if ok {
+			observedMetrics["latency_ms"] = fmt.Sprintf("%.2f", latency)
+			action = latencyAction(&resourceOptimizerProfile, latency, action)
 		}
-	} else if value > float64(cpuThresholds.Max) {
-		if resourceOptimizerProfile.Spec.OptimizationPolicy == "Resize" {
-			action = ResizeUpAction
+	}
+
+	// Queue triggers, like alert triggers, do not depend on the observed
+	// utilization.
+	queueTriggered := false
+	var backlog float64
+	if resourceOptimizerProfile.Spec.OptimizationPolicy != "Resize" && len(resourceOptimizerProfile.Spec.QueueTriggers) > 0 {
+		queued, depth, err := queueTriggersAction(ctx, r.Client, r.PrometheusAPI, &resourceOptimizerProfile, action)
+		if err != nil {
+			logger.Error(err, "error evaluating queue triggers")
+			// Only messages wake up targets scaled to zero.
+			if scaledToZero {
+				action = DoNothing
+			}
 		} else {
+			backlog = depth
+			observedMetrics["queue_depth"] = fmt.Sprintf("%.0f", backlog)
+			queueTriggered = queued != action
+			action = queued
+		}
+	}
+
+	// Firing alert triggers override the CPU thresholds with a ScaleUp.
+	var firingAlerts []string
+	if resourceOptimizerProfile.Spec.OptimizationPolicy != "Resize" && directionAllows(&resourceOptimizerProfile, ScaleUpAction) {
+		firingAlerts, err = firingAlertTriggers(ctx, r.PrometheusAPI, &resourceOptimizerProfile)
+		if err != nil {
+			logger.Error(err, "error querying alert triggers")
+		}
+		if len(firingAlerts) > 0 {
+			logger.Info("Alert triggers are firing", "alerts", firingAlerts)
 			action = ScaleUpAction
 		}
-	} else {
-		action = DoNothing
 	}
 
 	logger.Info("Comparison result", "action", action)
 
+	if suspension := resourceOptimizerProfile.Status.ActionsSuspended; suspension != nil &&
+		resourceOptimizerProfile.Spec.OptimizationPolicy != "Recommend" && action != DoNothing {
+		logger.Info("Actions are suspended after repeated failures, skipping execution", "action", action, "since", suspension.Since.Time)
+		r.recordSkippedAction(&resourceOptimizerProfile, action, SkipReasonSuspended)
+		action = DoNothing
+	}
+
+	if r.DisableDownwardActions && resourceOptimizerProfile.Spec.OptimizationPolicy != "Recommend" &&
+		(action == ScaleDownAction || action == ResizeDownAction || action == ScaleToZeroAction) {
+		logger.Info("Downward actions are disabled, skipping execution", "action", action)
+		r.recordSkippedAction(&resourceOptimizerProfile, action, SkipReasonDownwardDisabled)
+		action = DoNothing
+	}
+
+	if resourceOptimizerProfile.Spec.OptimizationPolicy != "Recommend" {
+		until, err := r.deferredUntil(&resourceOptimizerProfile, action, time.Now())
+		if err != nil {
+			logger.Error(err, "unable to check business hours")
+		} else if !until.IsZero() {
+			logger.Info("Deferring action until business hours close", "action", action, "until", until)
+			r.recordSkippedAction(&resourceOptimizerProfile, action, SkipReasonBusinessHours)
+			action = DoNothing
+		}
+	}
+
+	// Alert triggers do not depend on the observed utilization.
+	if action != DoNothing && len(firingAlerts) == 0 && !queueTriggered && resourceOptimizerProfile.Spec.OptimizationPolicy != "Recommend" &&
+		!confidentEnough(&resourceOptimizerProfile) {
+		logger.Info("Confidence is below minConfidence, skipping execution", "action", action,
+			"confidence", resourceOptimizerProfile.Status.Confidence, "minConfidence", *resourceOptimizerProfile.Spec.MinConfidence)
+		r.recordSkippedAction(&resourceOptimizerProfile, action, SkipReasonLowConfidence)
+		action = DoNothing
+	}
+
+	if r.holdForAlerts(ctx, &resourceOptimizerProfile, action) {
+		logger.Info("Holding action while alerts are firing", "action", action)
+		r.recordSkippedAction(&resourceOptimizerProfile, action, SkipReasonAlertFiring)
+		resourceOptimizerProfile.Status.ObservedMetrics = observedMetrics
+		if err := r.Status().Update(ctx, &resourceOptimizerProfile); err != nil {
+			logger.Error(err, "unable to update ResourceOptimizerProfile status")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: AlertHoldRecheck}, nil
+	}
+
+	if err := r.reconcileVolumes(ctx, &resourceOptimizerProfile); err != nil {
+		logger.Error(err, "error checking volume capacity")
+	}
+	if err := r.reconcilePodDisruptionBudgets(ctx, &resourceOptimizerProfile); err != nil {
+		logger.Error(err, "error maintaining PodDisruptionBudgets")
+	}
+
 	// 4. Handle actions based on the optimization policy
 	switch resourceOptimizerProfile.Spec.OptimizationPolicy {
 	case "Scale":
 		// Policy is "Scale", so we proceed with action execution
-		cooldownPeriod := 5 * time.Minute // Default cooldown
-		if resourceOptimizerProfile.Spec.CooldownPeriod != nil {
-			cooldownPeriod = resourceOptimizerProfile.Spec.CooldownPeriod.Duration
+		if remaining := r.scaleHistory.stabilizing(&resourceOptimizerProfile, action, time.Now()); remaining > 0 {
+			logger.Info("Action is within its stabilization window, skipping execution", "action", action, "remaining", remaining.String())
+			r.recordSkippedAction(&resourceOptimizerProfile, action, SkipReasonStabilizing)
+			return ctrl.Result{RequeueAfter: remaining}, nil
 		}
-		logger.Info("Using cooldown period", "cooldown", cooldownPeriod.String())
 
-		lastAction := resourceOptimizerProfile.Status.LastAction
+		logger.Info("Using cooldown period", "cooldown", cooldownPeriod(&resourceOptimizerProfile).String())
+		if remaining := cooldownRemaining(&resourceOptimizerProfile, action, time.Now()); remaining > 0 {
+			logger.Info("Action is in cooldown period, skipping execution", "action", action, "lastActionTimestamp", resourceOptimizerProfile.Status.LastAction.Timestamp)
+			r.recordSkippedAction(&resourceOptimizerProfile, action, SkipReasonCooldown)
+			// Requeue after the cooldown period expires
+			return ctrl.Result{RequeueAfter: remaining}, nil
+		}
 
-		if action != DoNothing && lastAction != nil && lastAction.Type != DoNothing {
-			if time.Since(lastAction.Timestamp.Time) < cooldownPeriod {
-				logger.Info("Action is in cooldown period, skipping execution", "action", action, "lastActionTimestamp", lastAction.Timestamp)
-				// Requeue after the cooldown period expires
-				requeueAfter := cooldownPeriod - time.Since(lastAction.Timestamp.Time)
-				return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		if action == ScaleUpAction && r.Autoscaler != nil {
+			reason, err := r.Autoscaler.Check(ctx, r.Client, &resourceOptimizerProfile)
+			if err != nil {
+				logger.Error(err, "unable to check for pending capacity, scaling up anyway")
+			} else if reason != "" {
+				logger.Info("Deferring scale up while the cluster waits for capacity", "reason", reason)
+				r.recordSkippedAction(&resourceOptimizerProfile, action, SkipReasonPendingCapacity)
+				return ctrl.Result{RequeueAfter: ClusterAutoscalerDeferral}, nil
 			}
 		}
 
 		logger.Info("Executing policy action...")
-		if err := r.executeScaleAction(ctx, &resourceOptimizerProfile, action); err != nil {
+		results, err := r.executeScaleAction(ctx, &resourceOptimizerProfile, action, value)
+		r.recordTargetFailures(&resourceOptimizerProfile, results, time.Now())
+		if err != nil {
 			logger.Error(err, "error executing scale action")
+			r.recordActionError(&resourceOptimizerProfile, action)
+			r.notify(ctx, &resourceOptimizerProfile, notify.EventActionFailed, action, value, err.Error())
+			recordPartialAction(&resourceOptimizerProfile, action, results, err)
+			reason := "ActionFailed"
+			if r.recordActionFailure(ctx, &resourceOptimizerProfile, err, time.Now()) {
+				reason = "ActionsSuspended"
+			}
+			r.markDegraded(ctx, &resourceOptimizerProfile, reason, err)
 			return ctrl.Result{}, err
 		}
 
-		switch action {
-		case ScaleUpAction:
-			scaleUpActions.Inc()
-		case ScaleDownAction:
-			scaleDownActions.Inc()
-		}
-
-		if action != DoNothing {
+		// An action every target skipped, for example because the action
+		// policy or the Approver denied it, was not applied: it neither
+		// becomes the last action nor starts the cooldown.
+		if action != DoNothing && len(results) == 0 {
+			logger.Info("Action was not applied to any target", "action", action)
+		} else if action != DoNothing {
+			resourceOptimizerProfile.Status.ActionFailures = 0
 			resourceOptimizerProfile.Status.LastAction = &optimizerv1.ActionDetail{
 				Type:      action,
 				Timestamp: metav1.Now(),
 				Details:   fmt.Sprintf("CPU usage was %.2f, triggered %s", value, action),
+				Targets:   results,
+			}
+			if len(firingAlerts) > 0 {
+				resourceOptimizerProfile.Status.LastAction.Details = fmt.Sprintf("Alerts %s were firing, triggered %s", strings.Join(firingAlerts, ", "), action)
+			} else if queueTriggered {
+				resourceOptimizerProfile.Status.LastAction.Details = fmt.Sprintf("Queue backlog was %.0f messages, triggered %s", backlog, action)
 			}
+			r.notify(ctx, &resourceOptimizerProfile, notify.EventAction, action, value, resourceOptimizerProfile.Status.LastAction.Details)
 		}
 	case "Resize":
-		cooldownPeriod := 5 * time.Minute // Default cooldown
-		if resourceOptimizerProfile.Spec.CooldownPeriod != nil {
-			cooldownPeriod = resourceOptimizerProfile.Spec.CooldownPeriod.Duration
-		}
-		logger.Info("Using cooldown period for Resize", "cooldown", cooldownPeriod.String())
-
-		lastAction := resourceOptimizerProfile.Status.LastAction
-		if action != DoNothing && lastAction != nil && lastAction.Type != DoNothing {
-			if time.Since(lastAction.Timestamp.Time) < cooldownPeriod {
-				logger.Info("Action is in cooldown period, skipping execution", "action", action, "lastActionTimestamp", lastAction.Timestamp)
-				requeueAfter := cooldownPeriod - time.Since(lastAction.Timestamp.Time)
-				return ctrl.Result{RequeueAfter: requeueAfter}, nil
-			}
+		logger.Info("Using cooldown period for Resize", "cooldown", cooldownPeriod(&resourceOptimizerProfile).String())
+		if remaining := cooldownRemaining(&resourceOptimizerProfile, action, time.Now()); remaining > 0 {
+			logger.Info("Action is in cooldown period, skipping execution", "action", action, "lastActionTimestamp", resourceOptimizerProfile.Status.LastAction.Timestamp)
+			r.recordSkippedAction(&resourceOptimizerProfile, action, SkipReasonCooldown)
+			return ctrl.Result{RequeueAfter: remaining}, nil
 		}
 
 		logger.Info("Executing resize action...")
-		if err := r.executeResizeAction(ctx, &resourceOptimizerProfile, action, value); err != nil {
+		results, err := r.executeResizeAction(ctx, &resourceOptimizerProfile, action, value)
+		r.recordTargetFailures(&resourceOptimizerProfile, results, time.Now())
+		if err != nil {
 			logger.Error(err, "error executing resize action")
+			r.recordActionError(&resourceOptimizerProfile, action)
+			r.notify(ctx, &resourceOptimizerProfile, notify.EventActionFailed, action, value, err.Error())
+			recordPartialAction(&resourceOptimizerProfile, action, results, err)
+			reason := "ActionFailed"
+			if r.recordActionFailure(ctx, &resourceOptimizerProfile, err, time.Now()) {
+				reason = "ActionsSuspended"
+			}
+			r.markDegraded(ctx, &resourceOptimizerProfile, reason, err)
 			return ctrl.Result{}, err
 		}
 
-		switch action {
-		case ResizeUpAction:
-			resizeUpActions.Inc()
-		case ResizeDownAction:
-			resizeDownActions.Inc()
-		}
-
-		if action != DoNothing {
+		if action != DoNothing && len(results) == 0 {
+			logger.Info("Action was not applied to any target", "action", action)
+		} else if action != DoNothing {
+			resourceOptimizerProfile.Status.ActionFailures = 0
 			resourceOptimizerProfile.Status.LastAction = &optimizerv1.ActionDetail{
 				Type:      action,
 				Timestamp: metav1.Now(),
 				Details:   fmt.Sprintf("CPU usage was %.2f%%, triggered %s", value, action),
+				Targets:   results,
+			}
+			r.notify(ctx, &resourceOptimizerProfile, notify.EventAction, action, value, resourceOptimizerProfile.Status.LastAction.Details)
+		}
+		// Only the pods of the targets that were resized are moved: targets
+		// skipped as protected, denied or already applied keep their pods.
+		if patched := patchedTargets(results); action == ResizeDownAction && r.Compactor != nil && !annotates(&resourceOptimizerProfile) && len(patched) > 0 {
+			evicted, err := r.Compactor.Compact(ctx, r.Client, resourceOptimizerProfile.Namespace, patched)
+			r.recordEvictions(&resourceOptimizerProfile, evicted)
+			if err != nil {
+				logger.Error(err, "error compacting pods after resize down")
 			}
 		}
 
 	case "Recommend":
 		if action != DoNothing {
+			r.recordSkippedAction(&resourceOptimizerProfile, action, SkipReasonDryRun)
 			recommendation := fmt.Sprintf("CPU usage is %.2f%%. Consider %s.", value, action)
+			if len(firingAlerts) > 0 {
+				recommendation = fmt.Sprintf("Alerts %s are firing. Consider %s.", strings.Join(firingAlerts, ", "), action)
+			} else if queueTriggered {
+				recommendation = fmt.Sprintf("Queue backlog is %.0f messages. Consider %s.", backlog, action)
+			}
 			resourceOptimizerProfile.Status.Recommendations = []string{recommendation}
+			recommendedAt := metav1.Now()
+			resourceOptimizerProfile.Status.RecommendedAt = &recommendedAt
+			diff, err := r.dryRunDiff(ctx, &resourceOptimizerProfile, action, value)
+			if err != nil {
+				logger.Error(err, "error computing the changes of the recommendation")
+			}
+			resourceOptimizerProfile.Status.Diff = diff
+			r.notify(ctx, &resourceOptimizerProfile, notify.EventRecommendation, action, value, recommendation)
 		} else {
 			// For recommend policy, we clear previous recommendations if no action is needed now
 			resourceOptimizerProfile.Status.Recommendations = nil
+			resourceOptimizerProfile.Status.Diff = nil
+			resourceOptimizerProfile.Status.RecommendedAt = nil
 		}
 	default:
 		logger.Info("OptimizationPolicy is not 'Scale' or 'Recommend', no action will be taken.", "policy", resourceOptimizerProfile.Spec.OptimizationPolicy)
@@ -252,140 +546,365 @@ func (r *ResourceOptimizerProfileReconciler) Reconcile(ctx context.Context, req
 
 	// 5. Update status for all policies
 	logger.Info("Updating status...")
-	resourceOptimizerProfile.Status.ObservedMetrics = map[string]string{"cpu_usage": fmt.Sprintf("%.2f", value)}
+	resourceOptimizerProfile.Status.ObservedMetrics = observedMetrics
+	resourceOptimizerProfile.Status.InitialEstimates = nil
+	if resourceOptimizerProfile.Spec.OptimizationPolicy != "Recommend" {
+		resourceOptimizerProfile.Status.Diff = nil
+	}
+	condition := metav1.Condition{
+		Type:               optimizerv1.ConditionDegraded,
+		Status:             metav1.ConditionFalse,
+		Reason:             "Reconciled",
+		Message:            "Metrics were queried and the policy was evaluated successfully",
+		ObservedGeneration: resourceOptimizerProfile.Generation,
+	}
+	if degraded := degradedTargets(&resourceOptimizerProfile); len(degraded) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "TargetsDegraded"
+		condition.Message = fmt.Sprintf("Actions kept failing on %s; the other targets are still managed", strings.Join(degraded, ", "))
+	}
+	if suspension := resourceOptimizerProfile.Status.ActionsSuspended; suspension != nil {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "ActionsSuspended"
+		condition.Message = fmt.Sprintf("Actions were suspended after %d failures in a row, the last with: %s; edit the spec or annotate the profile with %s=true to resume them",
+			resourceOptimizerProfile.Status.ActionFailures, suspension.Message, optimizerv1.ResumeActionsAnnotation)
+	}
+	meta.SetStatusCondition(&resourceOptimizerProfile.Status.Conditions, condition)
+	r.recordDegraded(&resourceOptimizerProfile, condition.Status == metav1.ConditionTrue)
 	if err := r.Status().Update(ctx, &resourceOptimizerProfile); err != nil {
 		logger.Error(err, "unable to update ResourceOptimizerProfile status")
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{RequeueAfter: time.Minute * 5}, nil
+	return ctrl.Result{RequeueAfter: r.requeueInterval()}, nil
 }
 
-func (r *ResourceOptimizerProfileReconciler) executeScaleAction(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, action string) error {
+// reconcileWithoutHistory handles a profile whose targets have no CPU metrics
+// yet, typically because they were just created. The thresholds cannot be
+// evaluated, so no action is taken. The initial CPU requests of the targets
+// are estimated and recorded in the status instead.
+func (r *ResourceOptimizerProfileReconciler) reconcileWithoutHistory(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	logger.Info("No CPU metrics found for the targets yet, estimating initial CPU requests")
+
+	estimates, err := estimateInitialCPURequests(ctx, r.Client, profile)
+	if err != nil {
+		logger.Error(err, "error estimating initial CPU requests")
+		return ctrl.Result{}, err
+	}
+	profile.Status.InitialEstimates = estimates
+	profile.Status.ObservedMetrics = nil
+	meta.SetStatusCondition(&profile.Status.Conditions, metav1.Condition{
+		Type:               optimizerv1.ConditionDegraded,
+		Status:             metav1.ConditionFalse,
+		Reason:             "NoMetricHistory",
+		Message:            "No CPU metrics were found for the targets yet; initial CPU requests were estimated instead",
+		ObservedGeneration: profile.Generation,
+	})
+	r.recordDegraded(profile, false)
+	if err := r.Status().Update(ctx, profile); err != nil {
+		logger.Error(err, "unable to update ResourceOptimizerProfile status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: r.requeueInterval()}, nil
+}
+
+// observeInvalidSamples sets the InvalidSamples condition for the pods whose
+// utilization samples were dropped for not being finite, and reports whether
+// the condition changed. Profiles that never had invalid samples do not get
+// the condition.
+func (r *ResourceOptimizerProfileReconciler) observeInvalidSamples(profile *optimizerv1.ResourceOptimizerProfile, dropped []string) bool {
+	if len(dropped) == 0 && meta.FindStatusCondition(profile.Status.Conditions, optimizerv1.ConditionInvalidSamples) == nil {
+		return false
+	}
+	condition := metav1.Condition{
+		Type:               optimizerv1.ConditionInvalidSamples,
+		Status:             metav1.ConditionFalse,
+		Reason:             "SamplesValid",
+		Message:            "Every sample of the utilization query was finite",
+		ObservedGeneration: profile.Generation,
+	}
+	if len(dropped) > 0 {
+		slices.Sort(dropped)
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "NonFiniteSamples"
+		condition.Message = fmt.Sprintf("The utilization of %s was NaN or infinite and is left out, as for pods without CPU requests",
+			strings.Join(dropped, ", "))
+	}
+	return meta.SetStatusCondition(&profile.Status.Conditions, condition)
+}
+
+// markPartialResponse records on the Degraded condition that no action was
+// taken because Prometheus answered a partial response under the Skip policy.
+// Prometheus is reachable, so the profile is not reported Degraded.
+func (r *ResourceOptimizerProfileReconciler) markPartialResponse(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, partial *PartialResponseError) {
+	meta.SetStatusCondition(&profile.Status.Conditions, metav1.Condition{
+		Type:               optimizerv1.ConditionDegraded,
+		Status:             metav1.ConditionFalse,
+		Reason:             "PartialResponse",
+		Message:            fmt.Sprintf("No action was taken: %v", partial),
+		ObservedGeneration: profile.Generation,
+	})
+	r.recordDegraded(profile, false)
+	if err := r.Status().Update(ctx, profile); err != nil {
+		log.FromContext(ctx).Error(err, "unable to record the PartialResponse reason")
+	}
+}
+
+// markDegraded sets the Degraded condition after a failed query or action. The
+// status update is best effort: the original error is what gets returned to the
+// caller and retried.
+func (r *ResourceOptimizerProfileReconciler) markDegraded(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, reason string, cause error) {
+	meta.SetStatusCondition(&profile.Status.Conditions, metav1.Condition{
+		Type:               optimizerv1.ConditionDegraded,
+		Status:             metav1.ConditionTrue,
+		Reason:             reason,
+		Message:            cause.Error(),
+		ObservedGeneration: profile.Generation,
+	})
+	r.recordDegraded(profile, true)
+	if err := r.Status().Update(ctx, profile); err != nil {
+		log.FromContext(ctx).Error(err, "unable to record Degraded condition")
+	}
+}
+
+func (r *ResourceOptimizerProfileReconciler) executeScaleAction(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, action string, observedValue float64) ([]optimizerv1.TargetResult, error) {
 	logger := log.FromContext(ctx)
 
 	if action == DoNothing {
-		return nil
+		return nil, nil
 	}
 
+	key := decisionKey(profile, action)
 	labelSelector := labels.Set(profile.Spec.Selector.MatchLabels).AsSelector()
+	var results []optimizerv1.TargetResult
+	var errs []error
 
-	// List Deployments
 	var deployments appsv1.DeploymentList
 	if err := r.List(ctx, &deployments, &client.ListOptions{LabelSelector: labelSelector, Namespace: profile.Namespace}); err != nil {
-		return err
+		return nil, err
 	}
+	var statefulSets appsv1.StatefulSetList
+	if err := r.List(ctx, &statefulSets, &client.ListOptions{LabelSelector: labelSelector, Namespace: profile.Namespace}); err != nil {
+		return nil, err
+	}
+	classes, err := r.priorityClasses(ctx, action, deployments.Items, statefulSets.Items)
+	if err != nil {
+		return nil, err
+	}
+	order := newScaleDownOrder(profile, action, classes, deployments.Items, statefulSets.Items)
 
 	for _, deployment := range deployments.Items {
+		if r.workloadProtected(ctx, profile, action, "Deployment", &deployment) {
+			continue
+		}
+		if r.alreadyApplied(ctx, profile, action, "Deployment", &deployment, key) {
+			continue
+		}
+		if r.otherDimensionChanged(ctx, profile, action, "Deployment", &deployment, time.Now()) {
+			continue
+		}
+		if !r.actionAllowed(ctx, profile, action, observedValue, "Deployment", &deployment) {
+			continue
+		}
+		if !r.retryDue(ctx, profile, action, targetRef{Kind: "Deployment", Name: deployment.Name}, time.Now()) {
+			continue
+		}
+		if !r.priorityAllows(ctx, profile, action, order, targetRef{Kind: "Deployment", Name: deployment.Name}) {
+			continue
+		}
 		patch := client.MergeFrom(deployment.DeepCopy())
 		var currentReplicas int32 = 1
 		if deployment.Spec.Replicas != nil {
 			currentReplicas = *deployment.Spec.Replicas
 		}
-		var newReplicas int32
-		if action == ScaleUpAction {
-			newReplicas = currentReplicas + 1
-		} else {
-			newReplicas = currentReplicas - 1
+		newReplicas := cappedReplicas(profile, currentReplicas, steppedReplicas(profile, action, currentReplicas))
+		target := targetRef{Kind: "Deployment", Name: deployment.Name}
+		newReplicas, ok := r.behaviorReplicas(ctx, profile, action, target, currentReplicas, newReplicas)
+		if !ok {
+			continue
 		}
-
-		if newReplicas < 1 {
-			newReplicas = 1
+		if newReplicas, ok = r.capacityReplicas(ctx, profile, action, target, &deployment.Spec.Template.Spec, currentReplicas, newReplicas); !ok {
+			continue
+		}
+		if ok, err := r.balanceScaleDown(ctx, profile, action, target, deployment.Spec.Selector, &deployment.Spec.Template, currentReplicas, newReplicas); !ok {
+			if err != nil {
+				errs = append(errs, err)
+			}
+			continue
 		}
 
-		deployment.Spec.Replicas = &newReplicas
-		if err := r.Patch(ctx, &deployment, patch); err != nil {
-			logger.Error(err, "error patching deployment")
-			return err
+		field, before, after := "spec.replicas", fmt.Sprint(currentReplicas), fmt.Sprint(newReplicas)
+		if annotates(profile) {
+			field, before = annotateRecommendation(&deployment.ObjectMeta, profile, optimizerv1.RecommendedReplicasAnnotation, after, time.Now())
+		} else {
+			deployment.Spec.Replicas = &newReplicas
+			markManaged(&deployment.ObjectMeta, profile, time.Now())
+		}
+		stampDecision(ctx, &deployment.ObjectMeta, profile, key)
+		err := r.Patch(ctx, &deployment, patch)
+		r.recordAudit(ctx, profile, action, target, field, before, after, observedValue, err)
+		results = append(results, targetResult(target, field, before, after, err))
+		if err != nil {
+			logger.Error(err, "error patching deployment", "deployment", deployment.Name)
+			errs = append(errs, fmt.Errorf("patching Deployment %s: %w", deployment.Name, err))
+			continue
+		}
+		if annotates(profile) {
+			r.recordAnnotation(profile, action, "Deployment")
+		} else {
+			r.recordScale(profile, target, currentReplicas, newReplicas)
+			r.recordAction(profile, action, "Deployment")
 		}
 		logger.Info("Patched deployment", "deployment", deployment.Name, "replicas", newReplicas)
 	}
 
-	// List StatefulSets
-	var statefulSets appsv1.StatefulSetList
-	if err := r.List(ctx, &statefulSets, &client.ListOptions{LabelSelector: labelSelector, Namespace: profile.Namespace}); err != nil {
-		return err
-	}
-
 	for _, statefulSet := range statefulSets.Items {
+		if r.workloadProtected(ctx, profile, action, "StatefulSet", &statefulSet) {
+			continue
+		}
+		if r.alreadyApplied(ctx, profile, action, "StatefulSet", &statefulSet, key) {
+			continue
+		}
+		if r.otherDimensionChanged(ctx, profile, action, "StatefulSet", &statefulSet, time.Now()) {
+			continue
+		}
+		if !r.actionAllowed(ctx, profile, action, observedValue, "StatefulSet", &statefulSet) {
+			continue
+		}
+		if !r.retryDue(ctx, profile, action, targetRef{Kind: "StatefulSet", Name: statefulSet.Name}, time.Now()) {
+			continue
+		}
+		if !r.priorityAllows(ctx, profile, action, order, targetRef{Kind: "StatefulSet", Name: statefulSet.Name}) {
+			continue
+		}
 		patch := client.MergeFrom(statefulSet.DeepCopy())
 		var currentReplicas int32 = 1
 		if statefulSet.Spec.Replicas != nil {
 			currentReplicas = *statefulSet.Spec.Replicas
 		}
-		var newReplicas int32
-		if action == ScaleUpAction {
-			newReplicas = currentReplicas + 1
-		} else {
-			newReplicas = currentReplicas - 1
+		newReplicas := cappedReplicas(profile, currentReplicas, steppedReplicas(profile, action, currentReplicas))
+		target := targetRef{Kind: "StatefulSet", Name: statefulSet.Name}
+		newReplicas, ok := r.behaviorReplicas(ctx, profile, action, target, currentReplicas, newReplicas)
+		if !ok {
+			continue
 		}
-
-		if newReplicas < 1 {
-			newReplicas = 1
+		if newReplicas, ok = r.limitStatefulSetReplicas(ctx, profile, action, &statefulSet, currentReplicas, newReplicas); !ok {
+			continue
+		}
+		if newReplicas, ok = r.capacityReplicas(ctx, profile, action, target, &statefulSet.Spec.Template.Spec, currentReplicas, newReplicas); !ok {
+			continue
+		}
+		if ok, err := r.balanceScaleDown(ctx, profile, action, target, statefulSet.Spec.Selector, &statefulSet.Spec.Template, currentReplicas, newReplicas); !ok {
+			if err != nil {
+				errs = append(errs, err)
+			}
+			continue
 		}
 
-		statefulSet.Spec.Replicas = &newReplicas
-		if err := r.Patch(ctx, &statefulSet, patch); err != nil {
-			logger.Error(err, "error patching statefulset")
-			return err
+		field, before, after := "spec.replicas", fmt.Sprint(currentReplicas), fmt.Sprint(newReplicas)
+		if annotates(profile) {
+			field, before = annotateRecommendation(&statefulSet.ObjectMeta, profile, optimizerv1.RecommendedReplicasAnnotation, after, time.Now())
+		} else {
+			statefulSet.Spec.Replicas = &newReplicas
+			markManaged(&statefulSet.ObjectMeta, profile, time.Now())
+		}
+		stampDecision(ctx, &statefulSet.ObjectMeta, profile, key)
+		err := r.Patch(ctx, &statefulSet, patch)
+		r.recordAudit(ctx, profile, action, target, field, before, after, observedValue, err)
+		results = append(results, targetResult(target, field, before, after, err))
+		if err != nil {
+			logger.Error(err, "error patching statefulset", "statefulset", statefulSet.Name)
+			errs = append(errs, fmt.Errorf("patching StatefulSet %s: %w", statefulSet.Name, err))
+			continue
+		}
+		if annotates(profile) {
+			r.recordAnnotation(profile, action, "StatefulSet")
+		} else {
+			r.recordScale(profile, target, currentReplicas, newReplicas)
+			r.recordAction(profile, action, "StatefulSet")
 		}
 		logger.Info("Patched statefulset", "statefulset", statefulSet.Name, "replicas", newReplicas)
 	}
 
-	return nil
+	return results, errors.Join(errs...)
 }
 
-func (r *ResourceOptimizerProfileReconciler) executeResizeAction(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, action string, observedValue float64) error {
+func (r *ResourceOptimizerProfileReconciler) executeResizeAction(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, action string, observedValue float64) ([]optimizerv1.TargetResult, error) {
 	logger := log.FromContext(ctx)
 
 	if action == DoNothing {
-		return nil
+		return nil, nil
 	}
 
+	key := decisionKey(profile, action)
 	labelSelector := labels.Set(profile.Spec.Selector.MatchLabels).AsSelector()
+	var results []optimizerv1.TargetResult
+	var errs []error
+	sources, err := listSourceRecommendations(ctx, r.Client, r.PrometheusAPI, profile)
+	if err != nil {
+		return nil, err
+	}
 
 	// --- Handle Deployments ---
 	var deployments appsv1.DeploymentList
 	if err := r.List(ctx, &deployments, &client.ListOptions{LabelSelector: labelSelector, Namespace: profile.Namespace}); err != nil {
-		return err
+		return nil, err
 	}
 
 	for _, deployment := range deployments.Items {
+		if r.workloadProtected(ctx, profile, action, "Deployment", &deployment) {
+			continue
+		}
+		if r.alreadyApplied(ctx, profile, action, "Deployment", &deployment, key) {
+			continue
+		}
+		if r.otherDimensionChanged(ctx, profile, action, "Deployment", &deployment, time.Now()) {
+			continue
+		}
+		if !r.actionAllowed(ctx, profile, action, observedValue, "Deployment", &deployment) {
+			continue
+		}
+		if !r.retryDue(ctx, profile, action, targetRef{Kind: "Deployment", Name: deployment.Name}, time.Now()) {
+			continue
+		}
+		if restartsPods(profile) && deploymentRollingOut(&deployment) {
+			logger.Info("Deferring resize until the previous rollout finishes", "deployment", deployment.Name)
+			r.recordSkippedAction(profile, action, SkipReasonRolloutInProgress)
+			continue
+		}
 		patch := client.MergeFrom(deployment.DeepCopy())
 
 		// Iterate over containers and update the first one with a CPU request
 		for i, container := range deployment.Spec.Template.Spec.Containers {
 			if _, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
-				// Simple resize logic: target usage is the middle of the threshold range
-				targetUsagePercent := (float64(profile.Spec.CPUThresholds.Min+profile.Spec.CPUThresholds.Max) / 2)
-				// Calculate new request based on observed usage to meet the target percentage
-				// newRequest = (currentUsage / targetPercent)
-				newCPUValue := (observedValue / targetUsagePercent) * container.Resources.Requests.Cpu().AsApproximateFloat64()
-
-				// Add a 25% buffer for safety
-				newCPUValue *= 1.25
-
-				milliVal := int64(newCPUValue * 1000)
-				if milliVal < 1 {
-					milliVal = 1
+				newCPURequest := recommendTargetCPURequest(ctx, profile, sources, &r.usageHistory, targetRef{Kind: "Deployment", Name: deployment.Name},
+					deployment.Annotations[optimizerv1.RuntimeAnnotation], container.Name, currentCPURequest(profile, deployment.Annotations, container), observedValue)
+				field, before, after := cpuRequestField(container.Name), container.Resources.Requests.Cpu().String(), newCPURequest.String()
+				if annotates(profile) {
+					after = container.Name + "=" + after
+					field, before = annotateRecommendation(&deployment.ObjectMeta, profile, optimizerv1.RecommendedCPURequestAnnotation, after, time.Now())
+				} else {
+					deployment.Spec.Template.Spec.Containers[i].Resources.Requests[corev1.ResourceCPU] = *newCPURequest
+					if restartsPods(profile) {
+						stampRestart(&deployment.Spec.Template, time.Now())
+					}
+					markManaged(&deployment.ObjectMeta, profile, time.Now())
 				}
-				newCPURequest := resource.NewMilliQuantity(milliVal, resource.DecimalSI)
 
-				// Enforce min/max boundaries if they are defined in the spec
-				if profile.Spec.MinCPU != nil && newCPURequest.Cmp(*profile.Spec.MinCPU) < 0 {
-					newCPURequest = resource.NewMilliQuantity(profile.Spec.MinCPU.MilliValue(), resource.DecimalSI)
-					logger.Info("Clamping CPU request to configured minCPU", "deployment", deployment.Name, "minCPU", profile.Spec.MinCPU.String())
-				}
-				if profile.Spec.MaxCPU != nil && newCPURequest.Cmp(*profile.Spec.MaxCPU) > 0 {
-					newCPURequest = resource.NewMilliQuantity(profile.Spec.MaxCPU.MilliValue(), resource.DecimalSI)
-					logger.Info("Clamping CPU request to configured maxCPU", "deployment", deployment.Name, "maxCPU", profile.Spec.MaxCPU.String())
+				stampDecision(ctx, &deployment.ObjectMeta, profile, key)
+				err := r.Patch(ctx, &deployment, patch)
+				r.recordAudit(ctx, profile, action, targetRef{Kind: "Deployment", Name: deployment.Name}, field, before, after, observedValue, err)
+				results = append(results, targetResult(targetRef{Kind: "Deployment", Name: deployment.Name}, field, before, after, err))
+				if err != nil {
+					logger.Error(err, "error patching deployment for resize", "deployment", deployment.Name)
+					errs = append(errs, fmt.Errorf("patching Deployment %s: %w", deployment.Name, err))
+					break
 				}
-
-				deployment.Spec.Template.Spec.Containers[i].Resources.Requests[corev1.ResourceCPU] = *newCPURequest
-
-				if err := r.Patch(ctx, &deployment, patch); err != nil {
-					logger.Error(err, "error patching deployment for resize")
-					return err
+				if annotates(profile) {
+					r.recordAnnotation(profile, action, "Deployment")
+				} else {
+					r.recordAction(profile, action, "Deployment")
 				}
 				logger.Info("Patched deployment for resize", "deployment", deployment.Name, "newCPURequest", newCPURequest.String())
 				break // Only patch the first container with CPU requests for now
@@ -396,39 +915,61 @@ func (r *ResourceOptimizerProfileReconciler) executeResizeAction(ctx context.Con
 	// --- Handle StatefulSets (similar logic) ---
 	var statefulSets appsv1.StatefulSetList
 	if err := r.List(ctx, &statefulSets, &client.ListOptions{LabelSelector: labelSelector, Namespace: profile.Namespace}); err != nil {
-		return err
+		return results, errors.Join(append(errs, err)...)
 	}
 
 	for _, ss := range statefulSets.Items {
+		if r.workloadProtected(ctx, profile, action, "StatefulSet", &ss) {
+			continue
+		}
+		if r.alreadyApplied(ctx, profile, action, "StatefulSet", &ss, key) {
+			continue
+		}
+		if r.otherDimensionChanged(ctx, profile, action, "StatefulSet", &ss, time.Now()) {
+			continue
+		}
+		if !r.actionAllowed(ctx, profile, action, observedValue, "StatefulSet", &ss) {
+			continue
+		}
+		if !r.retryDue(ctx, profile, action, targetRef{Kind: "StatefulSet", Name: ss.Name}, time.Now()) {
+			continue
+		}
+		if restartsPods(profile) && statefulSetRollingOut(&ss) {
+			logger.Info("Deferring resize until the previous rollout finishes", "statefulset", ss.Name)
+			r.recordSkippedAction(profile, action, SkipReasonRolloutInProgress)
+			continue
+		}
 		patch := client.MergeFrom(ss.DeepCopy())
 
 		for i, container := range ss.Spec.Template.Spec.Containers {
 			if _, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
-				targetUsagePercent := (float64(profile.Spec.CPUThresholds.Min+profile.Spec.CPUThresholds.Max) / 2)
-				newCPUValue := (observedValue / targetUsagePercent) * container.Resources.Requests.Cpu().AsApproximateFloat64()
-				newCPUValue *= 1.25 // Add 25% buffer
-
-				milliVal := int64(newCPUValue * 1000)
-				if milliVal < 1 {
-					milliVal = 1
+				newCPURequest := recommendTargetCPURequest(ctx, profile, sources, &r.usageHistory, targetRef{Kind: "StatefulSet", Name: ss.Name},
+					ss.Annotations[optimizerv1.RuntimeAnnotation], container.Name, currentCPURequest(profile, ss.Annotations, container), observedValue)
+				field, before, after := cpuRequestField(container.Name), container.Resources.Requests.Cpu().String(), newCPURequest.String()
+				if annotates(profile) {
+					after = container.Name + "=" + after
+					field, before = annotateRecommendation(&ss.ObjectMeta, profile, optimizerv1.RecommendedCPURequestAnnotation, after, time.Now())
+				} else {
+					ss.Spec.Template.Spec.Containers[i].Resources.Requests[corev1.ResourceCPU] = *newCPURequest
+					if restartsPods(profile) {
+						stampRestart(&ss.Spec.Template, time.Now())
+					}
+					markManaged(&ss.ObjectMeta, profile, time.Now())
 				}
-				newCPURequest := resource.NewMilliQuantity(milliVal, resource.DecimalSI)
 
-				// Enforce min/max boundaries if they are defined in the spec
-				if profile.Spec.MinCPU != nil && newCPURequest.Cmp(*profile.Spec.MinCPU) < 0 {
-					newCPURequest = resource.NewMilliQuantity(profile.Spec.MinCPU.MilliValue(), resource.DecimalSI)
-					logger.Info("Clamping CPU request to configured minCPU", "statefulset", ss.Name, "minCPU", profile.Spec.MinCPU.String())
+				stampDecision(ctx, &ss.ObjectMeta, profile, key)
+				err := r.Patch(ctx, &ss, patch)
+				r.recordAudit(ctx, profile, action, targetRef{Kind: "StatefulSet", Name: ss.Name}, field, before, after, observedValue, err)
+				results = append(results, targetResult(targetRef{Kind: "StatefulSet", Name: ss.Name}, field, before, after, err))
+				if err != nil {
+					logger.Error(err, "error patching statefulset for resize", "statefulset", ss.Name)
+					errs = append(errs, fmt.Errorf("patching StatefulSet %s: %w", ss.Name, err))
+					break
 				}
-				if profile.Spec.MaxCPU != nil && newCPURequest.Cmp(*profile.Spec.MaxCPU) > 0 {
-					newCPURequest = resource.NewMilliQuantity(profile.Spec.MaxCPU.MilliValue(), resource.DecimalSI)
-					logger.Info("Clamping CPU request to configured maxCPU", "statefulset", ss.Name, "maxCPU", profile.Spec.MaxCPU.String())
-				}
-
-				ss.Spec.Template.Spec.Containers[i].Resources.Requests[corev1.ResourceCPU] = *newCPURequest
-
-				if err := r.Patch(ctx, &ss, patch); err != nil {
-					logger.Error(err, "error patching statefulset for resize")
-					return err
+				if annotates(profile) {
+					r.recordAnnotation(profile, action, "StatefulSet")
+				} else {
+					r.recordAction(profile, action, "StatefulSet")
 				}
 				logger.Info("Patched statefulset for resize", "statefulset", ss.Name, "newCPURequest", newCPURequest.String())
 				break // Only patch the first container with CPU requests
@@ -436,23 +977,234 @@ func (r *ResourceOptimizerProfileReconciler) executeResizeAction(ctx context.Con
 		}
 	}
 
+	return results, errors.Join(errs...)
+}
+
+// recordPartialAction records an action that failed after it was applied to
+// some targets as the profile's last action, so that the targets it changed
+// are visible and its cooldown applies.
+func recordPartialAction(profile *optimizerv1.ResourceOptimizerProfile, action string, results []optimizerv1.TargetResult, err error) {
+	if !slices.ContainsFunc(results, func(result optimizerv1.TargetResult) bool {
+		return result.Result == optimizerv1.TargetResultSucceeded
+	}) {
+		return
+	}
+	profile.Status.LastAction = &optimizerv1.ActionDetail{
+		Type:      action,
+		Timestamp: metav1.Now(),
+		Details:   fmt.Sprintf("%s was only partially applied: %v", action, err),
+		Targets:   results,
+	}
+}
+
+// patchedTargets returns the targets an action was applied to.
+func patchedTargets(results []optimizerv1.TargetResult) []targetRef {
+	var targets []targetRef
+	for _, result := range results {
+		if result.Result == optimizerv1.TargetResultSucceeded {
+			targets = append(targets, targetRef{Kind: result.Kind, Name: result.Name})
+		}
+	}
+	return targets
+}
+
+// targetResult describes the result of patching a target for the action's
+// status.
+func targetResult(target targetRef, field, before, after string, patchErr error) optimizerv1.TargetResult {
+	result := optimizerv1.TargetResult{Kind: target.Kind, Name: target.Name, Field: field, From: before, To: after,
+		Result: optimizerv1.TargetResultSucceeded}
+	if patchErr != nil {
+		result.Result = optimizerv1.TargetResultFailed
+		result.Message = patchErr.Error()
+	}
+	return result
+}
+
+// recordAudit appends a patch to the audit trail. Failing to audit is logged but
+// never fails the reconcile, since the patch has already been sent.
+func (r *ResourceOptimizerProfileReconciler) recordAudit(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, action string, target targetRef, field, before, after string, observedValue float64, patchErr error) {
+	if r.Audit == nil {
+		return
+	}
+	record := audit.Record{
+		Time:              time.Now(),
+		ProfileNamespace:  profile.Namespace,
+		ProfileName:       profile.Name,
+		ProfileGeneration: profile.Generation,
+		Action:            action,
+		TargetKind:        target.Kind,
+		TargetName:        target.Name,
+		Field:             field,
+		Before:            before,
+		After:             after,
+		MetricValue:       observedValue,
+		Result:            audit.ResultSucceeded,
+	}
+	if patchErr != nil {
+		record.Result = audit.ResultFailed
+		record.Error = patchErr.Error()
+	}
+	if err := r.Audit.Record(ctx, record); err != nil {
+		log.FromContext(ctx).Error(err, "unable to write audit record", "target", target.Name)
+	}
+}
+
+// notify publishes an optimization decision to the controller-wide notifier and
+// to the profile's notification channels. Delivery failures are logged but never
+// fail the reconcile.
+func (r *ResourceOptimizerProfileReconciler) notify(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, kind, action string, observedValue float64, details string) {
+	logger := log.FromContext(ctx)
+
+	var notifiers notify.Multi
+	if r.Notifier != nil {
+		notifiers = append(notifiers, r.Notifier)
+	}
+	if r.Channels != nil && len(profile.Spec.Notifications) > 0 {
+		channels, err := r.Channels.Notifier(ctx, profile)
+		if err != nil {
+			logger.Error(err, "unable to resolve notification channels")
+		}
+		notifiers = append(notifiers, channels)
+	}
+	if len(notifiers) == 0 {
+		return
+	}
+
+	event := notify.Event{
+		Kind:              kind,
+		Time:              time.Now(),
+		ProfileNamespace:  profile.Namespace,
+		ProfileName:       profile.Name,
+		ProfileGeneration: profile.Generation,
+		Policy:            profile.Spec.OptimizationPolicy,
+		Action:            action,
+		MetricValue:       observedValue,
+		Details:           details,
+	}
+	if err := notifiers.Notify(ctx, event); err != nil {
+		logger.Error(err, "unable to publish notification", "kind", kind, "action", action)
+	}
+}
+
+// cpuRequestField is the audited field path of a container's CPU request.
+func cpuRequestField(container string) string {
+	return fmt.Sprintf("spec.template.spec.containers[%s].resources.requests.cpu", container)
+}
+
+// recommendCPURequest computes the CPU request that would bring the observed
+// utilization back to the middle of the configured thresholds, multiplied by a
+// safety buffer and clamped to the profile's minCPU/maxCPU boundaries.
+func recommendCPURequest(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, currentRequest resource.Quantity, observedValue, buffer float64) *resource.Quantity {
+	logger := log.FromContext(ctx)
+
+	// Simple resize logic: target usage is the middle of the threshold range
+	targetUsagePercent := (float64(profile.Spec.CPUThresholds.Min+profile.Spec.CPUThresholds.Max) / 2)
+	// Calculate new request based on observed usage to meet the target percentage
+	// newRequest = (currentUsage / targetPercent)
+	newCPUValue := (observedValue / targetUsagePercent) * currentRequest.AsApproximateFloat64()
+
+	// Add a buffer for safety
+	newCPUValue *= buffer
+
+	milliVal := int64(newCPUValue * 1000)
+	if milliVal < 1 {
+		milliVal = 1
+	}
+	newCPURequest := resource.NewMilliQuantity(milliVal, resource.DecimalSI)
+
+	// Enforce min/max boundaries if they are defined in the spec
+	if profile.Spec.MinCPU != nil && newCPURequest.Cmp(*profile.Spec.MinCPU) < 0 {
+		newCPURequest = resource.NewMilliQuantity(profile.Spec.MinCPU.MilliValue(), resource.DecimalSI)
+		logger.Info("Clamping CPU request to configured minCPU", "minCPU", profile.Spec.MinCPU.String())
+	}
+	if profile.Spec.MaxCPU != nil && newCPURequest.Cmp(*profile.Spec.MaxCPU) > 0 {
+		newCPURequest = resource.NewMilliQuantity(profile.Spec.MaxCPU.MilliValue(), resource.DecimalSI)
+		logger.Info("Clamping CPU request to configured maxCPU", "maxCPU", profile.Spec.MaxCPU.String())
+	}
+
+	return newCPURequest
+}
+
+// publishRecommendedCPU exports the CPU request the controller would propose for
+// every matched target so the recommendation can be graphed over time, regardless
+// of whether the profile's policy applies it. With Pricing set, the hourly price
+// of the CPU requested beyond the recommendations is exported as well.
+func (r *ResourceOptimizerProfileReconciler) publishRecommendedCPU(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, observedValue float64) error {
+	recommendations, err := recommendCPURequests(ctx, r.Client, r.PrometheusAPI, profile, &r.usageHistory, observedValue)
+	if err != nil {
+		return err
+	}
+	current, err := currentTargets(ctx, r.Client, profile)
+	if err != nil {
+		return err
+	}
+	r.recordRecommendedCPU(profile, recommendations, current)
+	if r.Pricing == nil {
+		return nil
+	}
+	price, err := r.Pricing.CPUCorePrice(ctx, r.PricingRegion)
+	if err != nil {
+		return err
+	}
+	r.recordEstimatedSavings(profile, savedCPUCores(recommendations, current)*price)
 	return nil
 }
 
+// firstCPURequest returns the name and current CPU request of the first
+// container that has one, which is the container the Resize policy operates on.
+func firstCPURequest(profile *optimizerv1.ResourceOptimizerProfile, annotations map[string]string, containers []corev1.Container) (string, resource.Quantity, bool) {
+	for _, container := range containers {
+		if _, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
+			return container.Name, currentCPURequest(profile, annotations, container), true
+		}
+	}
+	return "", resource.Quantity{}, false
+}
+
+// ReconcileInterval is how often a profile is reconciled when nothing else
+// requeues it sooner.
+const ReconcileInterval = 5 * time.Minute
+
+// DefaultRequeueJitter is the default fraction of ReconcileInterval added at
+// random to each requeue.
+const DefaultRequeueJitter = 0.1
+
+// requeueInterval returns ReconcileInterval plus a random jitter of up to
+// RequeueJitter of it, so that profiles created at the same moment, e.g. by a
+// GitOps sync, spread their reconciles out instead of querying Prometheus
+// and the API server all at once.
+func (r *ResourceOptimizerProfileReconciler) requeueInterval() time.Duration {
+	if r.RequeueJitter <= 0 {
+		return ReconcileInterval
+	}
+	return wait.Jitter(ReconcileInterval, r.RequeueJitter)
+}
+
+// queryTimeout returns how long each Prometheus query of the profile may take.
+func (r *ResourceOptimizerProfileReconciler) queryTimeout(profile *optimizerv1.ResourceOptimizerProfile) time.Duration {
+	if profile.Spec.QueryTimeout != nil {
+		return profile.Spec.QueryTimeout.Duration
+	}
+	return r.QueryTimeout
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ResourceOptimizerProfileReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	prometheusURL := os.Getenv("PROMETHEUS_URL")
-	if prometheusURL == "" {
-		prometheusURL = "http://prometheus-operated.monitoring.svc.cluster.local:9090"
-	}
+	prometheusURL := PrometheusURLFromEnv()
 
-	promAPI, err := newPrometheusAPI(prometheusURL)
+	promAPI, err := NewPrometheusAPI(prometheusURL, r.PrometheusTLS)
 	if err != nil {
 		return err
 	}
 
 	r.PrometheusAPI = promAPI
+	if r.MetricsCacheTTL > 0 {
+		r.PrometheusAPI = newCachingPrometheus(promAPI, r.MetricsCacheTTL)
+	}
 	r.PrometheusURL = prometheusURL
+	if r.PrometheusHealth != nil {
+		r.PrometheusHealth.setURL(prometheusURL)
+	}
 
 	// Log chosen Prometheus URL on setup so local runs show connectivity target
 	ctrl.Log.WithName("setup").Info("Prometheus URL configured", "url", prometheusURL)
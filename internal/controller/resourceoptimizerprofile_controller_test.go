@@ -18,18 +18,24 @@ package controller
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/prometheus/common/model"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/audit"
 )
 
 var _ = Describe("ResourceOptimizerProfile Controller", func() {
@@ -95,5 +101,136 @@ var _ = Describe("ResourceOptimizerProfile Controller", func() {
 			// TODO(user): Add more specific assertions depending on your controller's reconciliation logic.
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
+
+		It("should report the Degraded condition when Prometheus cannot be queried", func() {
+			By("creating a pod so that a query is built")
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-app-pod",
+					Namespace: "default",
+					Labels:    map[string]string{"app": "test-app"},
+				},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "nginx"}}},
+			}
+			Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, pod)).To(Succeed())
+			}()
+
+			controllerReconciler := &ResourceOptimizerProfileReconciler{
+				Client:        k8sClient,
+				Scheme:        k8sClient.Scheme(),
+				PrometheusAPI: &mockPrometheusAPI{err: fmt.Errorf("connection refused")},
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).To(HaveOccurred())
+
+			updated := &optimizerv1.ResourceOptimizerProfile{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, updated)).To(Succeed())
+			condition := meta.FindStatusCondition(updated.Status.Conditions, optimizerv1.ConditionDegraded)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal("QueryFailed"))
+		})
+
+		It("should leave profiles in denied namespaces alone", func() {
+			controllerReconciler := &ResourceOptimizerProfileReconciler{
+				Client:        k8sClient,
+				Scheme:        k8sClient.Scheme(),
+				PrometheusAPI: &mockPrometheusAPI{err: fmt.Errorf("must not be queried")},
+				Namespaces:    NamespaceFilter{Deny: []string{"default"}},
+			}
+
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(reconcile.Result{}))
+
+			updated := &optimizerv1.ResourceOptimizerProfile{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, updated)).To(Succeed())
+			condition := meta.FindStatusCondition(updated.Status.Conditions, optimizerv1.ConditionDegraded)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Reason).To(Equal("NamespaceNotAllowed"))
+			Expect(condition.Message).To(ContainSubstring("denylist"))
+		})
+	})
+
+	Context("When auditing actions", func() {
+		ctx := context.Background()
+
+		It("should record the replica change of every patched target", func() {
+			replicas := int32(2)
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "audited-app", Namespace: "default", Labels: map[string]string{"app": "audited-app"}},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "audited-app"}},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "audited-app"}},
+						Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "nginx"}}},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, deployment)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, deployment)).To(Succeed())
+			}()
+
+			profile := &optimizerv1.ResourceOptimizerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "audited-profile", Namespace: "default", Generation: 3},
+				Spec: optimizerv1.ResourceOptimizerProfileSpec{
+					Selector:           metav1.LabelSelector{MatchLabels: map[string]string{"app": "audited-app"}},
+					CPUThresholds:      optimizerv1.ThresholdSpec{Min: 30, Max: 70},
+					OptimizationPolicy: "Scale",
+				},
+			}
+			recorder := &fakeAuditRecorder{}
+			controllerReconciler := &ResourceOptimizerProfileReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), Audit: recorder}
+
+			Expect(controllerReconciler.executeScaleAction(ctx, profile, ScaleUpAction, 91.5)).Error().NotTo(HaveOccurred())
+
+			Expect(recorder.records).To(HaveLen(1))
+			record := recorder.records[0]
+			Expect(record.ProfileName).To(Equal("audited-profile"))
+			Expect(record.ProfileGeneration).To(Equal(int64(3)))
+			Expect(record.TargetKind).To(Equal("Deployment"))
+			Expect(record.TargetName).To(Equal("audited-app"))
+			Expect(record.Field).To(Equal("spec.replicas"))
+			Expect(record.Before).To(Equal("2"))
+			Expect(record.After).To(Equal("3"))
+			Expect(record.MetricValue).To(Equal(91.5))
+			Expect(record.Result).To(Equal(audit.ResultSucceeded))
+		})
+	})
+})
+
+var _ = Describe("Requeue jitter", func() {
+	It("should spread requeues over the jitter", func() {
+		reconciler := &ResourceOptimizerProfileReconciler{}
+		Expect(reconciler.requeueInterval()).To(Equal(ReconcileInterval))
+
+		reconciler.RequeueJitter = 0.2
+		intervals := map[time.Duration]bool{}
+		for range 20 {
+			interval := reconciler.requeueInterval()
+			Expect(interval).To(BeNumerically(">=", ReconcileInterval))
+			Expect(interval).To(BeNumerically("<", ReconcileInterval+time.Minute))
+			intervals[interval] = true
+		}
+		Expect(len(intervals)).To(BeNumerically(">", 1))
 	})
 })
+
+// fakeAuditRecorder keeps audit records in memory for assertions.
+type fakeAuditRecorder struct {
+	records []audit.Record
+}
+
+func (f *fakeAuditRecorder) Record(_ context.Context, record audit.Record) error {
+	f.records = append(f.records, record)
+	return nil
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// Values of spec.restartPolicy.
+const (
+	// RestartPolicyRollout relies on the rollout started for a changed pod
+	// template.
+	RestartPolicyRollout = "Rollout"
+	// RestartPolicyRestart restarts the pods like kubectl rollout restart.
+	RestartPolicyRestart = "Restart"
+)
+
+// RestartedAtAnnotation is the pod template annotation set by kubectl rollout
+// restart.
+const RestartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// restartsPods reports whether the profile restarts the pods of the workloads
+// it resizes. Profiles that only annotate their targets never do.
+func restartsPods(profile *optimizerv1.ResourceOptimizerProfile) bool {
+	return profile.Spec.RestartPolicy == RestartPolicyRestart && !annotates(profile)
+}
+
+// stampRestart annotates a pod template the way kubectl rollout restart does.
+func stampRestart(template *corev1.PodTemplateSpec, now time.Time) {
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[RestartedAtAnnotation] = now.Format(time.RFC3339)
+}
+
+// deploymentRollingOut reports whether a Deployment has not finished rolling
+// out its current template. Restarting it then would replace pods that are
+// still being surged in, on top of those its maxUnavailable already allows to
+// be down.
+func deploymentRollingOut(deployment *appsv1.Deployment) bool {
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+	return status.ObservedGeneration < deployment.Generation ||
+		status.UpdatedReplicas < replicas ||
+		status.Replicas > status.UpdatedReplicas ||
+		status.UnavailableReplicas > 0
+}
+
+// statefulSetRollingOut reports whether a StatefulSet has not finished rolling
+// out its current template.
+func statefulSetRollingOut(statefulSet *appsv1.StatefulSet) bool {
+	replicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+	status := statefulSet.Status
+	return status.ObservedGeneration < statefulSet.Generation ||
+		status.CurrentRevision != status.UpdateRevision ||
+		status.UpdatedReplicas < replicas ||
+		status.ReadyReplicas < replicas
+}
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+var _ = Describe("Restart policy", func() {
+	It("should annotate the pod template like kubectl rollout restart", func() {
+		template := corev1.PodTemplateSpec{}
+		stampRestart(&template, time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+		Expect(template.Annotations).To(HaveKeyWithValue(RestartedAtAnnotation, "2025-01-01T12:00:00Z"))
+	})
+
+	It("should tell when a Deployment is still rolling out", func() {
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Generation: 2},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](3)},
+			Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3},
+		}
+		Expect(deploymentRollingOut(deployment)).To(BeFalse())
+
+		// Surged pods of the old template are still around.
+		deployment.Status.Replicas = 4
+		Expect(deploymentRollingOut(deployment)).To(BeTrue())
+
+		deployment.Status.Replicas = 3
+		deployment.Status.UnavailableReplicas = 1
+		Expect(deploymentRollingOut(deployment)).To(BeTrue())
+
+		deployment.Status.UnavailableReplicas = 0
+		deployment.Generation = 3
+		Expect(deploymentRollingOut(deployment)).To(BeTrue())
+	})
+
+	It("should tell when a StatefulSet is still rolling out", func() {
+		statefulSet := &appsv1.StatefulSet{
+			Spec: appsv1.StatefulSetSpec{Replicas: ptr.To[int32](2)},
+			Status: appsv1.StatefulSetStatus{
+				ReadyReplicas: 2, UpdatedReplicas: 2, CurrentRevision: "web-1", UpdateRevision: "web-1",
+			},
+		}
+		Expect(statefulSetRollingOut(statefulSet)).To(BeFalse())
+
+		statefulSet.Status.UpdateRevision = "web-2"
+		Expect(statefulSetRollingOut(statefulSet)).To(BeTrue())
+	})
+})
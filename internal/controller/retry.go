@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// DefaultTargetRetryBudget is the default number of consecutive failed actions
+// after which a target is marked Degraded.
+const DefaultTargetRetryBudget = 5
+
+const (
+	// targetRetryBaseDelay is how long a target is left alone after its
+	// first failed action. The delay doubles with every further failure.
+	targetRetryBaseDelay = time.Minute
+	// targetRetryMaxDelay caps the delay between actions on a failing target,
+	// including Degraded ones, which keep being retried so they recover on
+	// their own.
+	targetRetryMaxDelay = time.Hour
+)
+
+// targetRetryDelay returns how long to wait before the next action on a target
+// whose last actions failed.
+func targetRetryDelay(failures int32) time.Duration {
+	delay := targetRetryBaseDelay
+	for i := int32(1); i < failures && delay < targetRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, targetRetryMaxDelay)
+}
+
+// retryBudget returns the number of consecutive failed actions after which a
+// target is marked Degraded.
+func (r *ResourceOptimizerProfileReconciler) retryBudget() int32 {
+	if r.TargetRetryBudget <= 0 {
+		return DefaultTargetRetryBudget
+	}
+	return int32(r.TargetRetryBudget)
+}
+
+// targetFailureIndex returns the index of a target in the profile's failing
+// targets, or -1.
+func targetFailureIndex(profile *optimizerv1.ResourceOptimizerProfile, target targetRef) int {
+	return slices.IndexFunc(profile.Status.FailingTargets, func(failure optimizerv1.TargetFailure) bool {
+		return failure.Kind == target.Kind && failure.Name == target.Name
+	})
+}
+
+// retryDue reports whether an action on a target is due. Targets whose last
+// actions failed are skipped until their backoff elapsed, while the profile's
+// other targets are still acted on.
+func (r *ResourceOptimizerProfileReconciler) retryDue(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, action string, target targetRef, now time.Time) bool {
+	i := targetFailureIndex(profile, target)
+	if i < 0 || !now.Before(profile.Status.FailingTargets[i].RetryAfter.Time) {
+		return true
+	}
+	failure := profile.Status.FailingTargets[i]
+	log.FromContext(ctx).Info("Backing off from a failing target", "action", action, "kind", target.Kind, "name", target.Name,
+		"failures", failure.Failures, "retryAfter", failure.RetryAfter.Time)
+	r.recordSkippedAction(profile, action, SkipReasonBackingOff)
+	return false
+}
+
+// recordTargetFailures updates the profile's failing targets with the results
+// of an action: targets it failed on back off for longer, and are marked
+// Degraded once they exhausted the retry budget, and targets it succeeded on
+// are no longer failing.
+func (r *ResourceOptimizerProfileReconciler) recordTargetFailures(profile *optimizerv1.ResourceOptimizerProfile, results []optimizerv1.TargetResult, now time.Time) {
+	for _, result := range results {
+		target := targetRef{Kind: result.Kind, Name: result.Name}
+		i := targetFailureIndex(profile, target)
+		if result.Result == optimizerv1.TargetResultSucceeded {
+			if i >= 0 {
+				profile.Status.FailingTargets = slices.Delete(profile.Status.FailingTargets, i, i+1)
+			}
+			continue
+		}
+		if i < 0 {
+			profile.Status.FailingTargets = append(profile.Status.FailingTargets, optimizerv1.TargetFailure{Kind: target.Kind, Name: target.Name})
+			i = len(profile.Status.FailingTargets) - 1
+		}
+		failure := &profile.Status.FailingTargets[i]
+		failure.Failures++
+		failure.LastFailureTime = metav1.NewTime(now)
+		failure.RetryAfter = metav1.NewTime(now.Add(targetRetryDelay(failure.Failures)))
+		failure.Degraded = failure.Failures >= r.retryBudget()
+		failure.Message = result.Message
+	}
+}
+
+// degradedTargets returns the Kind/Name of the profile's targets that
+// exhausted the retry budget.
+func degradedTargets(profile *optimizerv1.ResourceOptimizerProfile) []string {
+	var degraded []string
+	for _, failure := range profile.Status.FailingTargets {
+		if failure.Degraded {
+			degraded = append(degraded, fmt.Sprintf("%s/%s", failure.Kind, failure.Name))
+		}
+	}
+	return degraded
+}
+
+// pruneFailingTargets drops the failing targets the profile no longer selects,
+// e.g. because they were deleted, so they cannot keep it Degraded.
+func pruneFailingTargets(ctx context.Context, c client.Reader, profile *optimizerv1.ResourceOptimizerProfile) error {
+	if len(profile.Status.FailingTargets) == 0 {
+		return nil
+	}
+	listOpts := &client.ListOptions{LabelSelector: labels.Set(profile.Spec.Selector.MatchLabels).AsSelector(), Namespace: profile.Namespace}
+	var deployments appsv1.DeploymentList
+	if err := c.List(ctx, &deployments, listOpts); err != nil {
+		return err
+	}
+	var statefulSets appsv1.StatefulSetList
+	if err := c.List(ctx, &statefulSets, listOpts); err != nil {
+		return err
+	}
+	selected := map[targetRef]bool{}
+	for _, deployment := range deployments.Items {
+		selected[targetRef{Kind: "Deployment", Name: deployment.Name}] = true
+	}
+	for _, ss := range statefulSets.Items {
+		selected[targetRef{Kind: "StatefulSet", Name: ss.Name}] = true
+	}
+	profile.Status.FailingTargets = slices.DeleteFunc(profile.Status.FailingTargets, func(failure optimizerv1.TargetFailure) bool {
+		return !selected[targetRef{Kind: failure.Kind, Name: failure.Name}]
+	})
+	return nil
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Failing targets", func() {
+	labels := map[string]string{"app": "web"}
+	failed := optimizerv1.TargetResult{Kind: "Deployment", Name: "api", Result: optimizerv1.TargetResultFailed, Message: "denied"}
+	succeeded := optimizerv1.TargetResult{Kind: "Deployment", Name: "api", Result: optimizerv1.TargetResultSucceeded}
+
+	It("should back off exponentially up to an hour", func() {
+		Expect(targetRetryDelay(1)).To(Equal(time.Minute))
+		Expect(targetRetryDelay(2)).To(Equal(2 * time.Minute))
+		Expect(targetRetryDelay(4)).To(Equal(8 * time.Minute))
+		Expect(targetRetryDelay(10)).To(Equal(time.Hour))
+	})
+
+	It("should mark targets Degraded once the retry budget is exhausted", func() {
+		reconciler := &ResourceOptimizerProfileReconciler{TargetRetryBudget: 2}
+		profile := &optimizerv1.ResourceOptimizerProfile{}
+		now := time.Now()
+
+		reconciler.recordTargetFailures(profile, []optimizerv1.TargetResult{failed}, now)
+		Expect(profile.Status.FailingTargets).To(HaveLen(1))
+		Expect(profile.Status.FailingTargets[0].Failures).To(Equal(int32(1)))
+		Expect(profile.Status.FailingTargets[0].RetryAfter.Time).To(BeTemporally("~", now.Add(time.Minute), time.Second))
+		Expect(degradedTargets(profile)).To(BeEmpty())
+
+		reconciler.recordTargetFailures(profile, []optimizerv1.TargetResult{failed}, now)
+		Expect(profile.Status.FailingTargets[0].Degraded).To(BeTrue())
+		Expect(profile.Status.FailingTargets[0].Message).To(Equal("denied"))
+		Expect(degradedTargets(profile)).To(Equal([]string{"Deployment/api"}))
+
+		reconciler.recordTargetFailures(profile, []optimizerv1.TargetResult{succeeded}, now)
+		Expect(profile.Status.FailingTargets).To(BeEmpty())
+	})
+
+	It("should skip failing targets until their backoff elapsed", func() {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		patched := map[string]int{}
+		reconciler := &ResourceOptimizerProfileReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "team-a", Labels: labels},
+				Spec: appsv1.DeploymentSpec{Replicas: ptr.To[int32](2)}},
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", Labels: labels},
+				Spec: appsv1.DeploymentSpec{Replicas: ptr.To[int32](2)}},
+		).WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				patched[obj.GetName()]++
+				if obj.GetName() == "api" {
+					return errors.New("denied")
+				}
+				return c.Patch(ctx, obj, patch, opts...)
+			},
+		}).Build()}
+		profile := &optimizerv1.ResourceOptimizerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+			Spec: optimizerv1.ResourceOptimizerProfileSpec{
+				Selector:      metav1.LabelSelector{MatchLabels: labels},
+				CPUThresholds: optimizerv1.ThresholdSpec{Min: 20, Max: 80},
+			},
+		}
+
+		results, err := reconciler.executeScaleAction(context.Background(), profile, ScaleUpAction, 95)
+		Expect(err).To(HaveOccurred())
+		reconciler.recordTargetFailures(profile, results, time.Now())
+		recordPartialAction(profile, ScaleUpAction, results, err)
+		Expect(profile.Status.FailingTargets).To(HaveLen(1))
+
+		results, err = reconciler.executeScaleAction(context.Background(), profile, ScaleUpAction, 95)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(patched).To(Equal(map[string]int{"api": 1, "web": 2}))
+		profile.Status.LastAction = &optimizerv1.ActionDetail{Type: ScaleUpAction, Timestamp: metav1.Now(), Targets: results}
+
+		profile.Status.FailingTargets[0].RetryAfter = metav1.NewTime(time.Now().Add(-time.Second))
+		_, err = reconciler.executeScaleAction(context.Background(), profile, ScaleUpAction, 95)
+		Expect(err).To(HaveOccurred())
+		Expect(patched).To(Equal(map[string]int{"api": 2, "web": 3}))
+	})
+
+	It("should forget failing targets the profile no longer selects", func() {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", Labels: labels}},
+		).Build()
+		profile := &optimizerv1.ResourceOptimizerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+			Spec:       optimizerv1.ResourceOptimizerProfileSpec{Selector: metav1.LabelSelector{MatchLabels: labels}},
+			Status: optimizerv1.ResourceOptimizerProfileStatus{FailingTargets: []optimizerv1.TargetFailure{
+				{Kind: "Deployment", Name: "web", Failures: 1},
+				{Kind: "Deployment", Name: "deleted", Failures: 5, Degraded: true},
+			}},
+		}
+		Expect(pruneFailingTargets(context.Background(), c, profile)).To(Succeed())
+		Expect(profile.Status.FailingTargets).To(Equal([]optimizerv1.TargetFailure{{Kind: "Deployment", Name: "web", Failures: 1}}))
+	})
+})
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// Runtimes with a built-in sizing preset, selected with the runtime annotation
+// on a target workload.
+const (
+	RuntimeGo     = "go"
+	RuntimeJVM    = "jvm"
+	RuntimeNodeJS = "nodejs"
+)
+
+// DefaultCPUBuffer is the safety margin the Resize policy adds to the request
+// that would bring the observed utilization to the middle of the thresholds.
+const DefaultCPUBuffer = 1.25
+
+// runtimePreset tunes the sizing of CPU requests to a language runtime.
+type runtimePreset struct {
+	// cpuBuffer replaces DefaultCPUBuffer.
+	cpuBuffer float64
+	// minCPU and maxCPU bound recommended requests when the profile has no
+	// minCPU or maxCPU of its own. Zero leaves the request unbounded.
+	minCPU, maxCPU resource.Quantity
+}
+
+var runtimePresets = map[string]runtimePreset{
+	// Go services use little CPU beyond their steady state, and their garbage
+	// collector runs concurrently with a bounded share of the CPU.
+	RuntimeGo: {cpuBuffer: 1.15, minCPU: resource.MustParse("50m")},
+	// JIT compilation and garbage collection threads burst well above the
+	// steady state, and JVMs starved of CPU start slowly and fail their probes.
+	RuntimeJVM: {cpuBuffer: 1.5, minCPU: resource.MustParse("500m")},
+	// A Node.js process runs JavaScript on a single event loop, so requests
+	// beyond one CPU are rarely used.
+	RuntimeNodeJS: {cpuBuffer: 1.2, minCPU: resource.MustParse("100m"), maxCPU: resource.MustParse("1")},
+}
+
+// runtimePresetFor returns the preset of the runtime named by a workload's
+// runtime annotation. Workloads without one, or with an unknown runtime, are
+// sized with the defaults.
+func runtimePresetFor(ctx context.Context, target targetRef, runtime string) runtimePreset {
+	if runtime == "" {
+		return runtimePreset{cpuBuffer: DefaultCPUBuffer}
+	}
+	preset, ok := runtimePresets[runtime]
+	if !ok {
+		log.FromContext(ctx).Info("Ignoring unknown runtime", "kind", target.Kind, "name", target.Name,
+			"annotation", optimizerv1.RuntimeAnnotation, "runtime", runtime)
+		return runtimePreset{cpuBuffer: DefaultCPUBuffer}
+	}
+	return preset
+}
+
+// bound applies the preset's bounds to a request, unless the profile sets
+// minCPU or maxCPU, which take precedence.
+func (p runtimePreset) bound(profile *optimizerv1.ResourceOptimizerProfile, request *resource.Quantity) *resource.Quantity {
+	if profile.Spec.MinCPU == nil && !p.minCPU.IsZero() && request.Cmp(p.minCPU) < 0 {
+		bounded := p.minCPU.DeepCopy()
+		return &bounded
+	}
+	if profile.Spec.MaxCPU == nil && !p.maxCPU.IsZero() && request.Cmp(p.maxCPU) > 0 {
+		bounded := p.maxCPU.DeepCopy()
+		return &bounded
+	}
+	return request
+}
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Runtime presets", func() {
+	web := targetRef{Kind: "Deployment", Name: "web"}
+
+	var profile *optimizerv1.ResourceOptimizerProfile
+
+	BeforeEach(func() {
+		profile = &optimizerv1.ResourceOptimizerProfile{Spec: optimizerv1.ResourceOptimizerProfileSpec{
+			OptimizationPolicy: "Resize",
+			CPUThresholds:      optimizerv1.ThresholdSpec{Min: 20, Max: 80},
+		}}
+	})
+	recommend := func(runtime, current string, observed float64) string {
+		return recommendTargetCPURequest(context.Background(), profile, nil, nil, web, runtime, "app", resource.MustParse(current), observed).String()
+	}
+
+	It("should tune the buffer to the runtime", func() {
+		Expect(recommend("", "1", 50)).To(Equal("1250m"))
+		Expect(recommend(RuntimeGo, "1", 50)).To(Equal("1150m"))
+		Expect(recommend(RuntimeJVM, "1", 50)).To(Equal("1500m"))
+		Expect(recommend("cobol", "1", 50)).To(Equal("1250m"))
+	})
+
+	It("should bound requests unless the profile does", func() {
+		Expect(recommend(RuntimeJVM, "100m", 50)).To(Equal("500m"))
+		Expect(recommend(RuntimeNodeJS, "1", 100)).To(Equal("1"))
+
+		profile.Spec.MinCPU = ptr.To(resource.MustParse("200m"))
+		profile.Spec.MaxCPU = ptr.To(resource.MustParse("2"))
+		Expect(recommend(RuntimeJVM, "100m", 50)).To(Equal("200m"))
+		Expect(recommend(RuntimeNodeJS, "1", 100)).To(Equal("2"))
+	})
+
+	It("should cap the change from the current request to maxResizePercent", func() {
+		profile.Spec.MaxResizePercent = ptr.To[int32](50)
+		Expect(recommend("", "500m", 500)).To(Equal("750m"))
+		Expect(recommend("", "1", 1)).To(Equal("500m"))
+		Expect(recommend("", "1", 50)).To(Equal("1250m"))
+		Expect(recommend(RuntimeJVM, "100m", 50)).To(Equal("150m"))
+	})
+})
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"context"
+	"math"
+	"slices"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// schedulableOn reports whether pods with spec may be scheduled on node: the
+// node accepts pods, matches their nodeSelector and required node affinity,
+// and they tolerate its NoSchedule and NoExecute taints.
+func schedulableOn(spec *corev1.PodSpec, node *corev1.Node) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	for key, value := range spec.NodeSelector {
+		if node.Labels[key] != value {
+			return false
+		}
+	}
+	if affinity := spec.Affinity; affinity != nil && affinity.NodeAffinity != nil {
+		if required := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution; required != nil &&
+			!slices.ContainsFunc(required.NodeSelectorTerms, func(term corev1.NodeSelectorTerm) bool { return termMatches(term, node) }) {
+			return false
+		}
+	}
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !slices.ContainsFunc(spec.Tolerations, func(toleration corev1.Toleration) bool { return toleration.ToleratesTaint(taint) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// termMatches reports whether node matches every requirement of a node
+// selector term. Empty terms match no node.
+func termMatches(term corev1.NodeSelectorTerm, node *corev1.Node) bool {
+	if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+		return false
+	}
+	for _, requirement := range term.MatchExpressions {
+		value, ok := node.Labels[requirement.Key]
+		if !requirementMatches(requirement, value, ok) {
+			return false
+		}
+	}
+	for _, requirement := range term.MatchFields {
+		// metadata.name is the only field node selectors support.
+		if requirement.Key != "metadata.name" || !requirementMatches(requirement, node.Name, true) {
+			return false
+		}
+	}
+	return true
+}
+
+// requirementMatches reports whether a label value, set when ok, satisfies a
+// node selector requirement.
+func requirementMatches(requirement corev1.NodeSelectorRequirement, value string, ok bool) bool {
+	switch requirement.Operator {
+	case corev1.NodeSelectorOpIn:
+		return ok && slices.Contains(requirement.Values, value)
+	case corev1.NodeSelectorOpNotIn:
+		return !ok || !slices.Contains(requirement.Values, value)
+	case corev1.NodeSelectorOpExists:
+		return ok
+	case corev1.NodeSelectorOpDoesNotExist:
+		return !ok
+	case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+		if !ok || len(requirement.Values) != 1 {
+			return false
+		}
+		have, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false
+		}
+		want, err := strconv.ParseInt(requirement.Values[0], 10, 64)
+		if err != nil {
+			return false
+		}
+		if requirement.Operator == corev1.NodeSelectorOpGt {
+			return have > want
+		}
+		return have < want
+	}
+	return false
+}
+
+// nodeRequests returns the CPU, in millicores, requested by the pods running
+// on each node.
+func nodeRequests(pods []corev1.Pod) map[string]int64 {
+	requested := map[string]int64{}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Spec.NodeName != "" && !podTerminated(pod) {
+			requested[pod.Spec.NodeName] += podCPURequest(pod)
+		}
+	}
+	return requested
+}
+
+// fittingReplicas returns how many more pods with spec fit, by CPU requests,
+// on the nodes they may be scheduled on.
+func fittingReplicas(spec *corev1.PodSpec, nodes []corev1.Node, pods []corev1.Pod) int64 {
+	request := podCPURequest(&corev1.Pod{Spec: *spec})
+	if request == 0 {
+		return math.MaxInt32
+	}
+	requested := nodeRequests(pods)
+	var fitting int64
+	for i := range nodes {
+		node := &nodes[i]
+		if !schedulableOn(spec, node) {
+			continue
+		}
+		if free := node.Status.Allocatable.Cpu().MilliValue() - requested[node.Name]; free > 0 {
+			fitting += free / request
+		}
+	}
+	return fitting
+}
+
+// capacityReplicas limits a scale-up of a target from current to desired
+// replicas to the replicas that fit on the nodes its pods may be scheduled on,
+// as long as the reconciler checks capacity. It returns false, recording the
+// scale-up as skipped, when not a single replica fits. Should the capacity not
+// be readable, the scale-up goes ahead.
+func (r *ResourceOptimizerProfileReconciler) capacityReplicas(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, action string,
+	target targetRef, spec *corev1.PodSpec, current, desired int32) (int32, bool) {
+	if !r.CheckCapacity || desired <= current {
+		return desired, true
+	}
+	logger := log.FromContext(ctx)
+	var nodes corev1.NodeList
+	if err := r.List(ctx, &nodes); err != nil {
+		logger.Error(err, "unable to check node capacity, scaling up anyway")
+		return desired, true
+	}
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods); err != nil {
+		logger.Error(err, "unable to check node capacity, scaling up anyway")
+		return desired, true
+	}
+	fitting := fittingReplicas(spec, nodes.Items, pods.Items)
+	if fitting == 0 {
+		logger.Info("Holding back scale-up that no compatible node has room for", "kind", target.Kind, "name", target.Name)
+		r.recordSkippedAction(profile, action, SkipReasonInsufficientCapacity)
+		return current, false
+	}
+	if fitting < int64(desired-current) {
+		logger.Info("Limiting scale-up to the room on compatible nodes", "kind", target.Kind, "name", target.Name,
+			"desired", desired, "allowed", current+int32(fitting))
+		return current + int32(fitting), true
+	}
+	return desired, true
+}
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Scheduling constraints", func() {
+	node := func(name, cpu string, labels map[string]string, taints ...corev1.Taint) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Spec:       corev1.NodeSpec{Taints: taints},
+			Status:     corev1.NodeStatus{Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}},
+		}
+	}
+	gpuTaint := corev1.Taint{Key: "gpu", Value: "true", Effect: corev1.TaintEffectNoSchedule}
+	spec := func() *corev1.PodSpec {
+		return &corev1.PodSpec{Containers: []corev1.Container{{
+			Name:      "app",
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}},
+		}}}
+	}
+
+	It("should honor nodeSelectors, node affinity and taints", func() {
+		general := node("general", "4", map[string]string{"pool": "general", "generation": "5"})
+		gpu := node("gpu", "4", map[string]string{"pool": "gpu"}, gpuTaint)
+
+		pod := spec()
+		Expect(schedulableOn(pod, general)).To(BeTrue())
+		Expect(schedulableOn(pod, gpu)).To(BeFalse())
+		pod.Tolerations = []corev1.Toleration{{Key: "gpu", Operator: corev1.TolerationOpExists}}
+		Expect(schedulableOn(pod, gpu)).To(BeTrue())
+
+		pod.NodeSelector = map[string]string{"pool": "gpu"}
+		Expect(schedulableOn(pod, general)).To(BeFalse())
+
+		pod.NodeSelector = nil
+		pod.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "generation", Operator: corev1.NodeSelectorOpGt, Values: []string{"4"}}},
+			}}},
+		}}
+		Expect(schedulableOn(pod, general)).To(BeTrue())
+		Expect(schedulableOn(pod, gpu)).To(BeFalse())
+
+		general.Spec.Unschedulable = true
+		Expect(schedulableOn(pod, general)).To(BeFalse())
+	})
+
+	It("should only count the room on compatible nodes", func() {
+		nodes := []corev1.Node{
+			*node("general", "4", nil),
+			*node("gpu", "16", nil, gpuTaint),
+		}
+		pods := []corev1.Pod{{
+			Spec:   corev1.PodSpec{NodeName: "general", Containers: spec().Containers},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}}
+		Expect(fittingReplicas(spec(), nodes, pods)).To(Equal(int64(3)))
+	})
+
+	It("should limit scale-ups to the replicas that fit", func() {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		reconciler := &ResourceOptimizerProfileReconciler{
+			Client:        fake.NewClientBuilder().WithScheme(scheme).WithObjects(node("general", "2", nil), node("gpu", "16", nil, gpuTaint)).Build(),
+			CheckCapacity: true,
+		}
+		profile := &optimizerv1.ResourceOptimizerProfile{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"}}
+		target := targetRef{Kind: "Deployment", Name: "web"}
+
+		replicas, ok := reconciler.capacityReplicas(context.Background(), profile, ScaleUpAction, target, spec(), 2, 6)
+		Expect(ok).To(BeTrue())
+		Expect(replicas).To(Equal(int32(4)))
+		replicas, ok = reconciler.capacityReplicas(context.Background(), profile, ScaleDownAction, target, spec(), 2, 1)
+		Expect(ok).To(BeTrue())
+		Expect(replicas).To(Equal(int32(1)))
+
+		full := spec()
+		full.Containers[0].Resources.Requests[corev1.ResourceCPU] = resource.MustParse("3")
+		replicas, ok = reconciler.capacityReplicas(context.Background(), profile, ScaleUpAction, target, full, 2, 3)
+		Expect(ok).To(BeFalse())
+		Expect(replicas).To(Equal(int32(2)))
+	})
+})
@@ -0,0 +1,266 @@
+package controller
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// DefaultCooldownPeriod is the cooldown applied when a profile does not set one.
+const DefaultCooldownPeriod = 5 * time.Minute
+
+// Simulation is what the controller would do for a profile at a given CPU
+// utilization.
+type Simulation struct {
+	// Action is the action selected by the profile's thresholds.
+	Action string
+	// Executed reports whether the action would be applied now.
+	Executed bool
+	// SkipReason is set when an action other than DoNothing would not be
+	// executed, using the reasons of k20s_skipped_actions_total.
+	SkipReason string
+	// CooldownRemaining is the time until the cooldown of the last action expires.
+	CooldownRemaining time.Duration
+	// Targets holds the CPU request recommended for every matched target.
+	Targets []TargetRecommendation
+}
+
+// TargetRecommendation is the CPU request recommended for a single target.
+type TargetRecommendation struct {
+	Kind       string
+	Name       string
+	CPURequest resource.Quantity
+	// CurrentCPURequest is the request the recommendation would replace.
+	CurrentCPURequest resource.Quantity
+	// Replicas is the number of replicas the target currently asks for.
+	Replicas int32
+}
+
+// Simulate evaluates profile as if cpuUtilization had been observed, without
+// changing the profile or its targets.
+func Simulate(ctx context.Context, c client.Reader, profile *optimizerv1.ResourceOptimizerProfile, cpuUtilization float64) (*Simulation, error) {
+	sim := &Simulation{Action: decideAction(profile, cpuUtilization)}
+	switch profile.Spec.OptimizationPolicy {
+	case "Scale", "Resize":
+		sim.CooldownRemaining = cooldownRemaining(profile, sim.Action, time.Now())
+		switch {
+		case profile.Status.ActionsSuspended != nil && sim.Action != DoNothing:
+			sim.SkipReason = SkipReasonSuspended
+		case sim.CooldownRemaining > 0:
+			sim.SkipReason = SkipReasonCooldown
+		default:
+			sim.Executed = sim.Action != DoNothing
+		}
+	case "Recommend":
+		if sim.Action != DoNothing {
+			sim.SkipReason = SkipReasonDryRun
+		}
+	}
+
+	recommendations, err := recommendCPURequests(ctx, c, nil, profile, nil, cpuUtilization)
+	if err != nil {
+		return nil, err
+	}
+	current, err := currentTargets(ctx, c, profile)
+	if err != nil {
+		return nil, err
+	}
+	for target, request := range recommendations {
+		recommendation := current[target]
+		recommendation.Kind, recommendation.Name, recommendation.CPURequest = target.Kind, target.Name, *request
+		sim.Targets = append(sim.Targets, recommendation)
+	}
+	slices.SortFunc(sim.Targets, func(a, b TargetRecommendation) int {
+		return cmp.Or(cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Name, b.Name))
+	})
+	return sim, nil
+}
+
+// Directions a profile may restrict its actions to.
+const (
+	DirectionBoth     = "Both"
+	DirectionDownOnly = "DownOnly"
+	DirectionUpOnly   = "UpOnly"
+)
+
+// decideAction selects the action the profile's thresholds call for at the
+// observed CPU utilization, within the profile's direction.
+func decideAction(profile *optimizerv1.ResourceOptimizerProfile, value float64) string {
+	cpuThresholds := profile.Spec.CPUThresholds
+	resize := profile.Spec.OptimizationPolicy == "Resize"
+	switch {
+	case value < float64(cpuThresholds.Min) && directionAllows(profile, ScaleDownAction):
+		if resize {
+			return ResizeDownAction
+		}
+		return ScaleDownAction
+	case value > float64(cpuThresholds.Max) && directionAllows(profile, ScaleUpAction):
+		if resize {
+			return ResizeUpAction
+		}
+		return ScaleUpAction
+	}
+	return DoNothing
+}
+
+// directionAllows reports whether the profile's direction allows an action.
+func directionAllows(profile *optimizerv1.ResourceOptimizerProfile, action string) bool {
+	switch action {
+	case ScaleUpAction, ResizeUpAction:
+		return profile.Spec.Direction != DirectionDownOnly
+	case ScaleDownAction, ResizeDownAction, ScaleToZeroAction:
+		return profile.Spec.Direction != DirectionUpOnly
+	}
+	return true
+}
+
+// cooldownPeriod returns the profile's cooldown, or DefaultCooldownPeriod.
+func cooldownPeriod(profile *optimizerv1.ResourceOptimizerProfile) time.Duration {
+	if profile.Spec.CooldownPeriod != nil {
+		return profile.Spec.CooldownPeriod.Duration
+	}
+	return DefaultCooldownPeriod
+}
+
+// cooldownRemaining returns how long action has to wait for the cooldown of the
+// profile's last action to expire. It is zero when the action may run now.
+func cooldownRemaining(profile *optimizerv1.ResourceOptimizerProfile, action string, now time.Time) time.Duration {
+	lastAction := profile.Status.LastAction
+	if action == DoNothing || lastAction == nil || lastAction.Type == DoNothing {
+		return 0
+	}
+	return max(cooldownPeriod(profile)-now.Sub(lastAction.Timestamp.Time), 0)
+}
+
+// recommendCPURequests computes the CPU request the controller would propose
+// for every target matched by the profile. promAPI and usage may be nil, which
+// leaves out the Percentile source and the usage history.
+func recommendCPURequests(ctx context.Context, c client.Reader, promAPI PrometheusRangeClient, profile *optimizerv1.ResourceOptimizerProfile, usage *usageHistograms, observedValue float64) (map[targetRef]*resource.Quantity, error) {
+	labelSelector := labels.Set(profile.Spec.Selector.MatchLabels).AsSelector()
+	listOpts := &client.ListOptions{LabelSelector: labelSelector, Namespace: profile.Namespace}
+
+	var deployments appsv1.DeploymentList
+	if err := c.List(ctx, &deployments, listOpts); err != nil {
+		return nil, err
+	}
+	var statefulSets appsv1.StatefulSetList
+	if err := c.List(ctx, &statefulSets, listOpts); err != nil {
+		return nil, err
+	}
+
+	sources, err := listSourceRecommendations(ctx, c, promAPI, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	recommendations := map[targetRef]*resource.Quantity{}
+	for _, deployment := range deployments.Items {
+		if container, request, ok := firstCPURequest(profile, deployment.Annotations, deployment.Spec.Template.Spec.Containers); ok {
+			target := targetRef{Kind: "Deployment", Name: deployment.Name}
+			recommendations[target] = recommendTargetCPURequest(ctx, profile, sources, usage, target,
+				deployment.Annotations[optimizerv1.RuntimeAnnotation], container, request, observedValue)
+		}
+	}
+	for _, ss := range statefulSets.Items {
+		if container, request, ok := firstCPURequest(profile, ss.Annotations, ss.Spec.Template.Spec.Containers); ok {
+			target := targetRef{Kind: "StatefulSet", Name: ss.Name}
+			recommendations[target] = recommendTargetCPURequest(ctx, profile, sources, usage, target,
+				ss.Annotations[optimizerv1.RuntimeAnnotation], container, request, observedValue)
+		}
+	}
+	return recommendations, nil
+}
+
+// currentTargets returns the current CPU request and replicas of the profile's
+// targets that have a CPU request.
+func currentTargets(ctx context.Context, c client.Reader, profile *optimizerv1.ResourceOptimizerProfile) (map[targetRef]TargetRecommendation, error) {
+	listOpts := &client.ListOptions{LabelSelector: labels.Set(profile.Spec.Selector.MatchLabels).AsSelector(), Namespace: profile.Namespace}
+	var deployments appsv1.DeploymentList
+	if err := c.List(ctx, &deployments, listOpts); err != nil {
+		return nil, err
+	}
+	var statefulSets appsv1.StatefulSetList
+	if err := c.List(ctx, &statefulSets, listOpts); err != nil {
+		return nil, err
+	}
+
+	current := map[targetRef]TargetRecommendation{}
+	for _, deployment := range deployments.Items {
+		if _, request, ok := firstCPURequest(profile, deployment.Annotations, deployment.Spec.Template.Spec.Containers); ok {
+			current[targetRef{Kind: "Deployment", Name: deployment.Name}] = TargetRecommendation{
+				CurrentCPURequest: request, Replicas: ptr.Deref(deployment.Spec.Replicas, 1)}
+		}
+	}
+	for _, ss := range statefulSets.Items {
+		if _, request, ok := firstCPURequest(profile, ss.Annotations, ss.Spec.Template.Spec.Containers); ok {
+			current[targetRef{Kind: "StatefulSet", Name: ss.Name}] = TargetRecommendation{
+				CurrentCPURequest: request, Replicas: ptr.Deref(ss.Spec.Replicas, 1)}
+		}
+	}
+	return current, nil
+}
+
+// savedCPUCores returns the CPU cores requested by all replicas of the targets
+// beyond their recommendations. It is negative when the targets request less
+// than recommended.
+func savedCPUCores(recommendations map[targetRef]*resource.Quantity, current map[targetRef]TargetRecommendation) float64 {
+	var millicores int64
+	for target, request := range recommendations {
+		if t, ok := current[target]; ok {
+			millicores += (t.CurrentCPURequest.MilliValue() - request.MilliValue()) * int64(t.Replicas)
+		}
+	}
+	return float64(millicores) / 1000
+}
+
+// ReplayStep is the decision the controller would have made at one sample of a
+// replayed history.
+type ReplayStep struct {
+	Time           time.Time
+	CPUUtilization float64
+	Action         string
+	// Executed reports whether the action would have been applied.
+	Executed bool
+	// SkipReason is set when an action other than DoNothing would not have been
+	// executed.
+	SkipReason string
+}
+
+// Replay evaluates profile at every sample, oldest first, as if each had been
+// observed by a reconcile, and returns the steps that called for an action.
+// Cooldowns are tracked across the replayed steps only. The samples are replayed as recorded: the effect the actions would have had
+// on the utilization is not modeled.
+func Replay(profile *optimizerv1.ResourceOptimizerProfile, samples []CPUSample) []ReplayStep {
+	replayed := profile.DeepCopy()
+	replayed.Status.LastAction = nil
+	var steps []ReplayStep
+	for _, sample := range samples {
+		step := ReplayStep{Time: sample.Time, CPUUtilization: sample.Value, Action: decideAction(replayed, sample.Value)}
+		if step.Action == DoNothing {
+			continue
+		}
+		switch replayed.Spec.OptimizationPolicy {
+		case "Scale", "Resize":
+			if cooldownRemaining(replayed, step.Action, sample.Time) > 0 {
+				step.SkipReason = SkipReasonCooldown
+			} else {
+				step.Executed = true
+				replayed.Status.LastAction = &optimizerv1.ActionDetail{Type: step.Action, Timestamp: metav1.NewTime(sample.Time)}
+			}
+		case "Recommend":
+			step.SkipReason = SkipReasonDryRun
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
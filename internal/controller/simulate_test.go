@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Action decisions", func() {
+	profile := func(policy string) *optimizerv1.ResourceOptimizerProfile {
+		return &optimizerv1.ResourceOptimizerProfile{
+			Spec: optimizerv1.ResourceOptimizerProfileSpec{
+				OptimizationPolicy: policy,
+				CPUThresholds:      optimizerv1.ThresholdSpec{Min: 20, Max: 80},
+			},
+		}
+	}
+
+	It("should pick the action from the thresholds and the policy", func() {
+		Expect(decideAction(profile("Scale"), 90)).To(Equal(ScaleUpAction))
+		Expect(decideAction(profile("Scale"), 10)).To(Equal(ScaleDownAction))
+		Expect(decideAction(profile("Resize"), 90)).To(Equal(ResizeUpAction))
+		Expect(decideAction(profile("Resize"), 10)).To(Equal(ResizeDownAction))
+		Expect(decideAction(profile("Scale"), 50)).To(Equal(DoNothing))
+	})
+
+	It("should only act in the profile's direction", func() {
+		p := profile("Resize")
+		p.Spec.Direction = DirectionDownOnly
+		Expect(decideAction(p, 90)).To(Equal(DoNothing))
+		Expect(decideAction(p, 10)).To(Equal(ResizeDownAction))
+
+		p.Spec.Direction = DirectionUpOnly
+		Expect(decideAction(p, 90)).To(Equal(ResizeUpAction))
+		Expect(decideAction(p, 10)).To(Equal(DoNothing))
+	})
+
+	It("should report the remaining cooldown of the last action", func() {
+		now := time.Now()
+		p := profile("Scale")
+		p.Spec.CooldownPeriod = &metav1.Duration{Duration: 10 * time.Minute}
+		Expect(cooldownRemaining(p, ScaleUpAction, now)).To(BeZero())
+
+		p.Status.LastAction = &optimizerv1.ActionDetail{Type: ScaleUpAction, Timestamp: metav1.NewTime(now.Add(-4 * time.Minute))}
+		Expect(cooldownRemaining(p, ScaleUpAction, now)).To(Equal(6 * time.Minute))
+		Expect(cooldownRemaining(p, DoNothing, now)).To(BeZero())
+
+		p.Status.LastAction.Timestamp = metav1.NewTime(now.Add(-time.Hour))
+		Expect(cooldownRemaining(p, ScaleUpAction, now)).To(BeZero())
+	})
+
+	It("should count the CPU requested by every replica beyond the recommendation", func() {
+		web := targetRef{Kind: "Deployment", Name: "web"}
+		db := targetRef{Kind: "StatefulSet", Name: "db"}
+		recommendations := map[targetRef]*resource.Quantity{
+			web:                               resource.NewMilliQuantity(250, resource.DecimalSI),
+			db:                                resource.NewMilliQuantity(1500, resource.DecimalSI),
+			{Kind: "Deployment", Name: "new"}: resource.NewMilliQuantity(100, resource.DecimalSI),
+		}
+		current := map[targetRef]TargetRecommendation{
+			web: {CurrentCPURequest: resource.MustParse("500m"), Replicas: 4},
+			db:  {CurrentCPURequest: resource.MustParse("1"), Replicas: 1},
+		}
+		// web frees 4 x 250m, db needs another 500m.
+		Expect(savedCPUCores(recommendations, current)).To(BeNumerically("~", 0.5))
+	})
+
+	It("should replay history with cooldowns between the replayed actions", func() {
+		start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		var samples []CPUSample
+		for i, value := range []float64{50, 90, 95, 90, 50, 10} {
+			samples = append(samples, CPUSample{Time: start.Add(time.Duration(i) * 5 * time.Minute), Value: value})
+		}
+		p := profile("Scale")
+		p.Spec.CooldownPeriod = &metav1.Duration{Duration: 10 * time.Minute}
+		// The live cooldown must not leak into the replayed past.
+		p.Status.LastAction = &optimizerv1.ActionDetail{Type: ScaleUpAction, Timestamp: metav1.Now()}
+
+		Expect(Replay(p, samples)).To(Equal([]ReplayStep{
+			{Time: start.Add(5 * time.Minute), CPUUtilization: 90, Action: ScaleUpAction, Executed: true},
+			{Time: start.Add(10 * time.Minute), CPUUtilization: 95, Action: ScaleUpAction, SkipReason: SkipReasonCooldown},
+			{Time: start.Add(15 * time.Minute), CPUUtilization: 90, Action: ScaleUpAction, Executed: true},
+			{Time: start.Add(25 * time.Minute), CPUUtilization: 10, Action: ScaleDownAction, Executed: true},
+		}))
+
+		steps := Replay(profile("Recommend"), samples)
+		Expect(steps).To(HaveLen(4))
+		Expect(steps[0].SkipReason).To(Equal(SkipReasonDryRun))
+	})
+})
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// Values of spec.statefulSets.scaleDown.
+const (
+	// StatefulSetScaleDownOrdered scales StatefulSets down one ready replica
+	// at a time.
+	StatefulSetScaleDownOrdered = "Ordered"
+	// StatefulSetScaleDownDisabled never scales StatefulSets down.
+	StatefulSetScaleDownDisabled = "Disabled"
+)
+
+// statefulSetReplicas limits a scale-down of a StatefulSet from current to
+// desired replicas to what is safe for it, and reports the reason when it
+// must be held back. Scale-ups are left alone.
+//
+// A StatefulSet is only scaled down once its controller caught up with its
+// spec and all replicas are ready, so that pods removed by the previous action
+// are gone and no unready pod is left serving alone. With the default
+// OrderedReady podManagementPolicy, replicas are removed one per action, the
+// way the StatefulSet controller would remove them anyway. A staged rolling
+// update keeps at least one replica at or above its partition, since those
+// are the ones running the new revision.
+func statefulSetReplicas(profile *optimizerv1.ResourceOptimizerProfile, statefulSet *appsv1.StatefulSet, current, desired int32) (int32, string) {
+	if desired >= current {
+		return desired, ""
+	}
+	if profile.Spec.StatefulSets != nil && profile.Spec.StatefulSets.ScaleDown == StatefulSetScaleDownDisabled {
+		return current, SkipReasonStatefulSetScaleDownDisabled
+	}
+	status := statefulSet.Status
+	if status.ObservedGeneration < statefulSet.Generation || status.Replicas != current || status.ReadyReplicas < current {
+		return current, SkipReasonStatefulSetNotReady
+	}
+	if statefulSet.Spec.PodManagementPolicy != appsv1.ParallelPodManagement {
+		desired = max(desired, current-1)
+	}
+	if update := statefulSet.Spec.UpdateStrategy.RollingUpdate; update != nil && update.Partition != nil &&
+		*update.Partition > 0 && *update.Partition < current {
+		desired = max(desired, *update.Partition+1)
+		if desired >= current {
+			return current, SkipReasonStatefulSetPartition
+		}
+	}
+	return desired, ""
+}
+
+// limitStatefulSetReplicas applies statefulSetReplicas, recording scale-downs
+// it holds back. It returns false when the StatefulSet must be left alone.
+func (r *ResourceOptimizerProfileReconciler) limitStatefulSetReplicas(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, action string, statefulSet *appsv1.StatefulSet, current, desired int32) (int32, bool) {
+	replicas, reason := statefulSetReplicas(profile, statefulSet, current, desired)
+	if reason != "" {
+		log.FromContext(ctx).Info("Holding back StatefulSet scale-down", "statefulset", statefulSet.Name, "reason", reason)
+		r.recordSkippedAction(profile, action, reason)
+		return current, false
+	}
+	if replicas != desired {
+		log.FromContext(ctx).Info("Scaling StatefulSet down gradually", "statefulset", statefulSet.Name,
+			"replicas", current, "desired", desired, "allowed", replicas)
+	}
+	return replicas, true
+}
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("StatefulSet scaling", func() {
+	var (
+		profile     *optimizerv1.ResourceOptimizerProfile
+		statefulSet *appsv1.StatefulSet
+	)
+
+	BeforeEach(func() {
+		profile = &optimizerv1.ResourceOptimizerProfile{}
+		statefulSet = &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Generation: 2},
+			Spec:       appsv1.StatefulSetSpec{Replicas: ptr.To[int32](5)},
+			Status:     appsv1.StatefulSetStatus{ObservedGeneration: 2, Replicas: 5, ReadyReplicas: 5},
+		}
+	})
+
+	It("should leave scale-ups alone", func() {
+		statefulSet.Status.ReadyReplicas = 0
+		Expect(statefulSetReplicas(profile, statefulSet, 5, 8)).To(Equal(int32(8)))
+	})
+
+	It("should remove one replica at a time unless pods are managed in parallel", func() {
+		replicas, reason := statefulSetReplicas(profile, statefulSet, 5, 2)
+		Expect(reason).To(BeEmpty())
+		Expect(replicas).To(Equal(int32(4)))
+
+		statefulSet.Spec.PodManagementPolicy = appsv1.ParallelPodManagement
+		Expect(statefulSetReplicas(profile, statefulSet, 5, 2)).To(Equal(int32(2)))
+	})
+
+	It("should wait for every replica to be ready", func() {
+		statefulSet.Status.ReadyReplicas = 4
+		_, reason := statefulSetReplicas(profile, statefulSet, 5, 4)
+		Expect(reason).To(Equal(SkipReasonStatefulSetNotReady))
+
+		statefulSet.Status.ReadyReplicas, statefulSet.Status.Replicas = 5, 6
+		_, reason = statefulSetReplicas(profile, statefulSet, 5, 4)
+		Expect(reason).To(Equal(SkipReasonStatefulSetNotReady))
+
+		statefulSet.Status.Replicas, statefulSet.Generation = 5, 3
+		_, reason = statefulSetReplicas(profile, statefulSet, 5, 4)
+		Expect(reason).To(Equal(SkipReasonStatefulSetNotReady))
+	})
+
+	It("should keep a replica at or above the partition of a staged rolling update", func() {
+		statefulSet.Spec.PodManagementPolicy = appsv1.ParallelPodManagement
+		statefulSet.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{Partition: ptr.To[int32](3)}
+		Expect(statefulSetReplicas(profile, statefulSet, 5, 1)).To(Equal(int32(4)))
+
+		statefulSet.Spec.UpdateStrategy.RollingUpdate.Partition = ptr.To[int32](4)
+		_, reason := statefulSetReplicas(profile, statefulSet, 5, 1)
+		Expect(reason).To(Equal(SkipReasonStatefulSetPartition))
+	})
+
+	It("should never scale down when disabled", func() {
+		profile.Spec.StatefulSets = &optimizerv1.StatefulSetScaling{ScaleDown: StatefulSetScaleDownDisabled}
+		replicas, reason := statefulSetReplicas(profile, statefulSet, 5, 4)
+		Expect(reason).To(Equal(SkipReasonStatefulSetScaleDownDisabled))
+		Expect(replicas).To(Equal(int32(5)))
+	})
+})
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// DefaultActionFailureBudget is the default number of consecutive failed
+// actions after which a profile's actions are suspended.
+const DefaultActionFailureBudget = 10
+
+// actionFailureBudget returns the number of consecutive failed actions after
+// which a profile's actions are suspended.
+func (r *ResourceOptimizerProfileReconciler) actionFailureBudget() int32 {
+	if r.ActionFailureBudget <= 0 {
+		return DefaultActionFailureBudget
+	}
+	return int32(r.ActionFailureBudget)
+}
+
+// recordActionFailure counts a failed action of the profile and suspends its
+// actions once the failures exhausted the action failure budget. It reports
+// whether the actions are suspended.
+func (r *ResourceOptimizerProfileReconciler) recordActionFailure(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, cause error, now time.Time) bool {
+	profile.Status.ActionFailures++
+	if profile.Status.ActionsSuspended == nil && profile.Status.ActionFailures >= r.actionFailureBudget() {
+		log.FromContext(ctx).Info("Suspending actions after repeated failures", "failures", profile.Status.ActionFailures)
+		profile.Status.ActionsSuspended = &optimizerv1.ActionSuspension{
+			Since:              metav1.NewTime(now),
+			ObservedGeneration: profile.Generation,
+		}
+	}
+	if profile.Status.ActionsSuspended != nil {
+		profile.Status.ActionsSuspended.Message = cause.Error()
+	}
+	return profile.Status.ActionsSuspended != nil
+}
+
+// resumeActions resumes the actions of a profile suspended for failing
+// repeatedly once its spec was edited or it was annotated with
+// optimizer.k20s.opscale.ir/resume-actions=true. The annotation is removed,
+// which also resets the count of failures of a profile that is not suspended.
+func (r *ResourceOptimizerProfileReconciler) resumeActions(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile) error {
+	annotated := profile.Annotations[optimizerv1.ResumeActionsAnnotation] == "true"
+	if annotated {
+		base := profile.DeepCopy()
+		delete(profile.Annotations, optimizerv1.ResumeActionsAnnotation)
+		if err := r.Patch(ctx, profile, client.MergeFrom(base)); err != nil {
+			return err
+		}
+	}
+	suspension := profile.Status.ActionsSuspended
+	if !annotated && (suspension == nil || suspension.ObservedGeneration == profile.Generation) {
+		return nil
+	}
+	if suspension != nil {
+		log.FromContext(ctx).Info("Resuming suspended actions", "since", suspension.Since.Time, "annotated", annotated)
+	}
+	profile.Status.ActionFailures = 0
+	profile.Status.ActionsSuspended = nil
+	return nil
+}
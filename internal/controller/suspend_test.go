@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Suspended actions", func() {
+	denied := errors.New("denied")
+
+	It("should suspend actions once the action failure budget is exhausted", func() {
+		reconciler := &ResourceOptimizerProfileReconciler{ActionFailureBudget: 2}
+		profile := &optimizerv1.ResourceOptimizerProfile{ObjectMeta: metav1.ObjectMeta{Generation: 3}}
+		now := time.Now()
+
+		Expect(reconciler.recordActionFailure(context.Background(), profile, denied, now)).To(BeFalse())
+		Expect(profile.Status.ActionFailures).To(Equal(int32(1)))
+		Expect(profile.Status.ActionsSuspended).To(BeNil())
+
+		Expect(reconciler.recordActionFailure(context.Background(), profile, denied, now)).To(BeTrue())
+		Expect(profile.Status.ActionsSuspended).To(Equal(&optimizerv1.ActionSuspension{
+			Since: metav1.NewTime(now), ObservedGeneration: 3, Message: "denied",
+		}))
+
+		later := now.Add(time.Hour)
+		Expect(reconciler.recordActionFailure(context.Background(), profile, errors.New("forbidden"), later)).To(BeTrue())
+		Expect(profile.Status.ActionsSuspended.Since.Time).To(Equal(now))
+		Expect(profile.Status.ActionsSuspended.Message).To(Equal("forbidden"))
+	})
+
+	It("should report suspended actions in simulations", func() {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		profile := &optimizerv1.ResourceOptimizerProfile{Spec: optimizerv1.ResourceOptimizerProfileSpec{
+			OptimizationPolicy: "Scale",
+			CPUThresholds:      optimizerv1.ThresholdSpec{Min: 20, Max: 80},
+		}}
+		profile.Status.ActionsSuspended = &optimizerv1.ActionSuspension{Since: metav1.Now()}
+
+		sim, err := Simulate(context.Background(), fake.NewClientBuilder().WithScheme(scheme).Build(), profile, 95)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sim.Executed).To(BeFalse())
+		Expect(sim.SkipReason).To(Equal(SkipReasonSuspended))
+	})
+
+	Context("when resuming", func() {
+		var (
+			reconciler *ResourceOptimizerProfileReconciler
+			profile    *optimizerv1.ResourceOptimizerProfile
+		)
+
+		BeforeEach(func() {
+			scheme := runtime.NewScheme()
+			Expect(optimizerv1.AddToScheme(scheme)).To(Succeed())
+			profile = &optimizerv1.ResourceOptimizerProfile{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", Generation: 3}}
+			reconciler = &ResourceOptimizerProfileReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(profile).Build()}
+			profile.Status.ActionFailures = 10
+			profile.Status.ActionsSuspended = &optimizerv1.ActionSuspension{Since: metav1.Now(), ObservedGeneration: 3}
+		})
+
+		It("should keep actions suspended until the spec is edited", func() {
+			Expect(reconciler.resumeActions(context.Background(), profile)).To(Succeed())
+			Expect(profile.Status.ActionsSuspended).NotTo(BeNil())
+
+			profile.Generation = 4
+			Expect(reconciler.resumeActions(context.Background(), profile)).To(Succeed())
+			Expect(profile.Status.ActionsSuspended).To(BeNil())
+			Expect(profile.Status.ActionFailures).To(BeZero())
+		})
+
+		It("should resume actions of annotated profiles and remove the annotation", func() {
+			var annotated optimizerv1.ResourceOptimizerProfile
+			key := types.NamespacedName{Namespace: "team-a", Name: "web"}
+			Expect(reconciler.Get(context.Background(), key, &annotated)).To(Succeed())
+			annotated.Annotations = map[string]string{optimizerv1.ResumeActionsAnnotation: "true"}
+			Expect(reconciler.Update(context.Background(), &annotated)).To(Succeed())
+			annotated.Status = profile.Status
+
+			Expect(reconciler.resumeActions(context.Background(), &annotated)).To(Succeed())
+			Expect(annotated.Status.ActionsSuspended).To(BeNil())
+			Expect(annotated.Status.ActionFailures).To(BeZero())
+
+			Expect(reconciler.Get(context.Background(), key, &annotated)).To(Succeed())
+			Expect(annotated.Annotations).NotTo(HaveKey(optimizerv1.ResumeActionsAnnotation))
+		})
+	})
+})
@@ -0,0 +1,223 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// +kubebuilder:rbac:groups="",resources=pods,verbs=patch
+
+// removalDeletionCost is the pod-deletion-cost given to the pods a Deployment
+// scale-down should remove, so that its ReplicaSet removes them first.
+const removalDeletionCost = "-1000"
+
+// placement is where the running pods of a target are scheduled.
+type placement struct {
+	pods []corev1.Pod
+	// nodeLabels holds the labels of every node by name.
+	nodeLabels map[string]map[string]string
+}
+
+// targetPlacement returns the placement of the scheduled, running pods of a
+// target.
+func (r *ResourceOptimizerProfileReconciler) targetPlacement(ctx context.Context, namespace string, selector *metav1.LabelSelector) (*placement, error) {
+	podSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pod selector: %w", err)
+	}
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods, &client.ListOptions{Namespace: namespace, LabelSelector: podSelector}); err != nil {
+		return nil, err
+	}
+	var nodes corev1.NodeList
+	if err := r.List(ctx, &nodes); err != nil {
+		return nil, err
+	}
+	p := &placement{nodeLabels: map[string]map[string]string{}}
+	for _, node := range nodes.Items {
+		p.nodeLabels[node.Name] = node.Labels
+	}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != "" && pod.DeletionTimestamp == nil && !podTerminated(&pod) {
+			p.pods = append(p.pods, pod)
+		}
+	}
+	slices.SortFunc(p.pods, func(a, b corev1.Pod) int { return strings.Compare(a.Name, b.Name) })
+	return p, nil
+}
+
+// domain returns the value of key on the node of pod, and whether it has one.
+func (p *placement) domain(pod *corev1.Pod, key string) (string, bool) {
+	value, ok := p.nodeLabels[pod.Spec.NodeName][key]
+	return value, ok
+}
+
+// domainCounts counts the pods matched by selector in each domain of key,
+// leaving out the removed ones. Domains all of whose pods are removed are
+// counted as empty. Pods on nodes without key are ignored, as the scheduler
+// does.
+func (p *placement) domainCounts(key string, selector labels.Selector, removed map[string]bool) map[string]int {
+	counts := map[string]int{}
+	for i := range p.pods {
+		pod := &p.pods[i]
+		domain, ok := p.domain(pod, key)
+		if !ok || !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		if !removed[pod.Name] {
+			counts[domain]++
+		} else if _, ok := counts[domain]; !ok {
+			counts[domain] = 0
+		}
+	}
+	return counts
+}
+
+// skew is the difference between the most and the least populated domains.
+func skew(counts map[string]int) int {
+	if len(counts) == 0 {
+		return 0
+	}
+	most, least := 0, -1
+	for _, count := range counts {
+		most = max(most, count)
+		if least < 0 || count < least {
+			least = count
+		}
+	}
+	return most - least
+}
+
+// balancedRemovals picks count pods to remove, each from the domain of key
+// that has the most pods left. Pods on nodes without key are picked first.
+func (p *placement) balancedRemovals(key string, count int) map[string]bool {
+	removed := map[string]bool{}
+	for len(removed) < count && len(removed) < len(p.pods) {
+		counts := p.domainCounts(key, labels.Everything(), removed)
+		pick, pickCount := -1, -1
+		for i := range p.pods {
+			pod := &p.pods[i]
+			if removed[pod.Name] {
+				continue
+			}
+			domain, ok := p.domain(pod, key)
+			if !ok {
+				pick = i
+				break
+			}
+			if counts[domain] > pickCount {
+				pick, pickCount = i, counts[domain]
+			}
+		}
+		removed[p.pods[pick].Name] = true
+	}
+	return removed
+}
+
+// ordinalRemovals returns the pods a StatefulSet scaled down to desired
+// replicas removes: those with an ordinal from desired on.
+func (p *placement) ordinalRemovals(statefulSet string, desired int32) map[string]bool {
+	removed := map[string]bool{}
+	for _, pod := range p.pods {
+		suffix, ok := strings.CutPrefix(pod.Name, statefulSet+"-")
+		if !ok {
+			continue
+		}
+		if ordinal, err := strconv.Atoi(suffix); err == nil && ordinal >= int(desired) {
+			removed[pod.Name] = true
+		}
+	}
+	return removed
+}
+
+// spreadViolation returns the first DoNotSchedule topology spread constraint
+// that removing the pods would break: one whose skew would exceed its maxSkew,
+// and grow. Constraints already broken are not held against a removal that
+// does not make them worse.
+func (p *placement) spreadViolation(constraints []corev1.TopologySpreadConstraint, removed map[string]bool) *corev1.TopologySpreadConstraint {
+	for i := range constraints {
+		constraint := &constraints[i]
+		if constraint.WhenUnsatisfiable != corev1.DoNotSchedule || constraint.LabelSelector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(constraint.LabelSelector)
+		if err != nil {
+			continue
+		}
+		before := skew(p.domainCounts(constraint.TopologyKey, selector, nil))
+		after := skew(p.domainCounts(constraint.TopologyKey, selector, removed))
+		if after > int(constraint.MaxSkew) && after > before {
+			return constraint
+		}
+	}
+	return nil
+}
+
+// balanceScaleDown checks a scale-down of a target from current to desired
+// replicas against the topology spread constraints of its pods, and returns
+// false, recording it as skipped, when it would break one. The replicas a
+// Deployment scale-down removes are picked from the most populated domains
+// and given a low pod-deletion-cost, so that its ReplicaSet removes them
+// first; a StatefulSet always removes its highest ordinals.
+func (r *ResourceOptimizerProfileReconciler) balanceScaleDown(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, action string,
+	target targetRef, selector *metav1.LabelSelector, template *corev1.PodTemplateSpec, current, desired int32) (bool, error) {
+	spread := profile.Spec.TopologySpread
+	if spread == nil || desired >= current {
+		return true, nil
+	}
+	logger := log.FromContext(ctx)
+	p, err := r.targetPlacement(ctx, profile.Namespace, selector)
+	if err != nil {
+		return false, fmt.Errorf("reading the placement of %s %s: %w", target.Kind, target.Name, err)
+	}
+
+	var removed map[string]bool
+	if target.Kind == "StatefulSet" {
+		removed = p.ordinalRemovals(target.Name, desired)
+	} else {
+		key := spread.TopologyKey
+		if key == "" {
+			key = corev1.LabelTopologyZone
+		}
+		removed = p.balancedRemovals(key, int(current-desired))
+	}
+	if constraint := p.spreadViolation(template.Spec.TopologySpreadConstraints, removed); constraint != nil {
+		logger.Info("Holding back scale-down that would break a topology spread constraint", "kind", target.Kind, "name", target.Name,
+			"topologyKey", constraint.TopologyKey, "maxSkew", constraint.MaxSkew)
+		r.recordSkippedAction(profile, action, SkipReasonTopologySpread)
+		return false, nil
+	}
+	if target.Kind == "StatefulSet" || annotates(profile) {
+		return true, nil
+	}
+
+	for i := range p.pods {
+		pod := &p.pods[i]
+		marked := pod.Annotations[corev1.PodDeletionCost] == removalDeletionCost
+		if removed[pod.Name] == marked {
+			continue
+		}
+		patch := client.MergeFrom(pod.DeepCopy())
+		if removed[pod.Name] {
+			metav1.SetMetaDataAnnotation(&pod.ObjectMeta, corev1.PodDeletionCost, removalDeletionCost)
+		} else {
+			delete(pod.Annotations, corev1.PodDeletionCost)
+		}
+		if err := r.Patch(ctx, pod, patch); err != nil {
+			// The ReplicaSet then picks the pod to remove on its own.
+			logger.Error(err, "failed to set the deletion cost of pod", "pod", pod.Name)
+		}
+	}
+	return true, nil
+}
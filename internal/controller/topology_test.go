@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Topology spread", func() {
+	labels := map[string]string{"app": "web"}
+	node := func(name, zone string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{corev1.LabelTopologyZone: zone}}}
+	}
+	pod := func(name, nodeName string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-a", Labels: labels},
+			Spec:       corev1.PodSpec{NodeName: nodeName},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	// Zone a runs three replicas, zone b two.
+	objects := func() []runtime.Object {
+		return []runtime.Object{
+			node("node-a", "a"), node("node-b", "b"),
+			pod("web-0", "node-a"), pod("web-1", "node-a"), pod("web-2", "node-a"), pod("web-3", "node-b"), pod("web-4", "node-b"),
+		}
+	}
+	constraint := corev1.TopologySpreadConstraint{
+		MaxSkew:           1,
+		TopologyKey:       corev1.LabelTopologyZone,
+		WhenUnsatisfiable: corev1.DoNotSchedule,
+		LabelSelector:     &metav1.LabelSelector{MatchLabels: labels},
+	}
+
+	var (
+		reconciler *ResourceOptimizerProfileReconciler
+		profile    *optimizerv1.ResourceOptimizerProfile
+		p          *placement
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		reconciler = &ResourceOptimizerProfileReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objects()...).Build()}
+		profile = &optimizerv1.ResourceOptimizerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+			Spec:       optimizerv1.ResourceOptimizerProfileSpec{TopologySpread: &optimizerv1.TopologySpread{}},
+		}
+		var err error
+		p, err = reconciler.targetPlacement(context.Background(), "team-a", &metav1.LabelSelector{MatchLabels: labels})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should remove replicas from the most populated zone first", func() {
+		Expect(p.balancedRemovals(corev1.LabelTopologyZone, 1)).To(Equal(map[string]bool{"web-0": true}))
+		Expect(p.balancedRemovals(corev1.LabelTopologyZone, 3)).To(Equal(map[string]bool{"web-0": true, "web-1": true, "web-3": true}))
+	})
+
+	It("should refuse removals that break a DoNotSchedule constraint", func() {
+		Expect(p.spreadViolation([]corev1.TopologySpreadConstraint{constraint}, map[string]bool{"web-0": true})).To(BeNil())
+		Expect(p.spreadViolation([]corev1.TopologySpreadConstraint{constraint}, map[string]bool{"web-3": true})).To(Equal(&constraint))
+
+		soft := constraint
+		soft.WhenUnsatisfiable = corev1.ScheduleAnyway
+		Expect(p.spreadViolation([]corev1.TopologySpreadConstraint{soft}, map[string]bool{"web-3": true})).To(BeNil())
+	})
+
+	It("should remove the highest ordinals of StatefulSets", func() {
+		Expect(p.ordinalRemovals("web", 3)).To(Equal(map[string]bool{"web-3": true, "web-4": true}))
+	})
+
+	It("should mark the pods a Deployment scale-down removes", func() {
+		template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{TopologySpreadConstraints: []corev1.TopologySpreadConstraint{constraint}}}
+		target := targetRef{Kind: "Deployment", Name: "web"}
+		Expect(reconciler.balanceScaleDown(context.Background(), profile, ScaleDownAction, target,
+			&metav1.LabelSelector{MatchLabels: labels}, template, 5, 4)).To(BeTrue())
+
+		var marked corev1.Pod
+		Expect(reconciler.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "web-0"}, &marked)).To(Succeed())
+		Expect(marked.Annotations).To(HaveKeyWithValue(corev1.PodDeletionCost, removalDeletionCost))
+		Expect(reconciler.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "web-3"}, &marked)).To(Succeed())
+		Expect(marked.Annotations).NotTo(HaveKey(corev1.PodDeletionCost))
+	})
+
+	It("should hold back StatefulSet scale-downs that break a constraint", func() {
+		template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{TopologySpreadConstraints: []corev1.TopologySpreadConstraint{constraint}}}
+		target := targetRef{Kind: "StatefulSet", Name: "web"}
+		// Removing web-4 leaves zone a with two replicas more than zone b.
+		Expect(reconciler.balanceScaleDown(context.Background(), profile, ScaleDownAction, target,
+			&metav1.LabelSelector{MatchLabels: labels}, template, 5, 4)).To(BeFalse())
+		Expect(reconciler.balanceScaleDown(context.Background(), profile, ScaleDownAction, target,
+			&metav1.LabelSelector{MatchLabels: labels}, &corev1.PodTemplateSpec{}, 5, 4)).To(BeTrue())
+	})
+})
@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// Defaults of a profile's usageHistory.
+const (
+	DefaultUsageHalfLife   = 24 * time.Hour
+	DefaultUsagePercentile = 90
+)
+
+// The usage histograms have exponentially growing buckets, as in the
+// VerticalPodAutoscaler: the first holds usage below 10m CPU and each following
+// bucket is 5% wider than the previous one, up to 1000 CPUs.
+const (
+	usageFirstBucket = 0.01
+	usageBucketRatio = 1.05
+	usageMaxCores    = 1000
+)
+
+// usageBuckets is the number of buckets of a usage histogram.
+var usageBuckets = usageBucket(usageMaxCores) + 1
+
+// usageBucket returns the bucket holding a usage in cores.
+func usageBucket(cores float64) int {
+	if cores < usageFirstBucket {
+		return 0
+	}
+	return int(math.Log(cores/usageFirstBucket)/math.Log(usageBucketRatio)) + 1
+}
+
+// usageBucketEnd returns the usage in cores at which a bucket ends.
+func usageBucketEnd(bucket int) float64 {
+	return usageFirstBucket * math.Pow(usageBucketRatio, float64(bucket))
+}
+
+// usageHistogram is the CPU usage distribution of a container, with the
+// weight of every sample decaying over time.
+type usageHistogram struct {
+	weights []float64
+	total   float64
+	last    time.Time
+}
+
+// add decays the weights of the samples added before now by the half-life and
+// adds a sample of usage in cores with a weight of one.
+func (h *usageHistogram) add(cores float64, halfLife time.Duration, now time.Time) {
+	if h.weights == nil {
+		h.weights = make([]float64, usageBuckets)
+	} else if elapsed := now.Sub(h.last); elapsed > 0 {
+		decay := math.Exp2(-float64(elapsed) / float64(halfLife))
+		for i := range h.weights {
+			h.weights[i] *= decay
+		}
+		h.total *= decay
+	}
+	h.weights[min(usageBucket(cores), usageBuckets-1)]++
+	h.total++
+	h.last = now
+}
+
+// percentile returns the usage in cores below which the given percentage of
+// the weight lies, rounded up to the end of its bucket.
+func (h *usageHistogram) percentile(percentile float64) float64 {
+	threshold := h.total * percentile / 100
+	var sum float64
+	last := 0
+	for i, weight := range h.weights {
+		if weight == 0 {
+			continue
+		}
+		sum += weight
+		last = i
+		if sum >= threshold {
+			return usageBucketEnd(i)
+		}
+	}
+	// Rounding errors can leave the sum just below the total.
+	return usageBucketEnd(last)
+}
+
+// usageKey identifies a container of a target.
+type usageKey struct {
+	workloadKey
+	container string
+}
+
+// usageHistograms keeps the usage histogram of every container the Resize
+// policy sizes. It is kept in memory only, so a restarted controller starts
+// the histograms afresh. The zero value is ready to use.
+type usageHistograms struct {
+	mu         sync.Mutex
+	histograms map[usageKey]*usageHistogram
+}
+
+// observe adds a usage sample to the histogram of a container.
+func (u *usageHistograms) observe(key usageKey, cores float64, halfLife time.Duration, now time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.histograms == nil {
+		u.histograms = map[usageKey]*usageHistogram{}
+	}
+	histogram, ok := u.histograms[key]
+	if !ok {
+		histogram = &usageHistogram{}
+		u.histograms[key] = histogram
+	}
+	histogram.add(cores, halfLife, now)
+}
+
+// prune drops the histograms of the targets in a namespace that keep does not
+// contain.
+func (u *usageHistograms) prune(namespace string, keep map[targetRef]bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for key := range u.histograms {
+		if key.namespace == namespace && !keep[key.targetRef] {
+			delete(u.histograms, key)
+		}
+	}
+}
+
+// cpuRequest returns the CPU request sized from the histogram of a container
+// for a profile with a usageHistory: the usage at the profile's percentile
+// divided by the middle of its CPU thresholds, bounded by minCPU and maxCPU. It
+// returns false while the container has no histogram. u may be nil.
+func (u *usageHistograms) cpuRequest(profile *optimizerv1.ResourceOptimizerProfile, key usageKey) (*resource.Quantity, bool) {
+	if u == nil || profile.Spec.UsageHistory == nil {
+		return nil, false
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	histogram, ok := u.histograms[key]
+	if !ok {
+		return nil, false
+	}
+	percentile := float64(DefaultUsagePercentile)
+	if p := profile.Spec.UsageHistory.Percentile; p != nil {
+		percentile = float64(*p)
+	}
+	targetUsagePercent := float64(profile.Spec.CPUThresholds.Min+profile.Spec.CPUThresholds.Max) / 2
+	milliCores := int64(math.Ceil(histogram.percentile(percentile) / targetUsagePercent * 100 * 1000))
+	return clampCPURequest(profile, *resource.NewMilliQuantity(max(milliCores, 1), resource.DecimalSI)), true
+}
+
+// usageHalfLife returns the half-life of the profile's usage samples.
+func usageHalfLife(profile *optimizerv1.ResourceOptimizerProfile) time.Duration {
+	if history := profile.Spec.UsageHistory; history != nil && history.HalfLife != nil && history.HalfLife.Duration > 0 {
+		return history.HalfLife.Duration
+	}
+	return DefaultUsageHalfLife
+}
+
+// observeUsage adds the CPU usage of every target of a profile with a
+// usageHistory to the histogram of the container the Resize policy sizes. The
+// usage is the observed utilization, in percent, of the container's current
+// request.
+func (r *ResourceOptimizerProfileReconciler) observeUsage(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, observedValue float64) error {
+	if profile.Spec.UsageHistory == nil {
+		return nil
+	}
+	listOpts := &client.ListOptions{LabelSelector: labels.Set(profile.Spec.Selector.MatchLabels).AsSelector(), Namespace: profile.Namespace}
+	var deployments appsv1.DeploymentList
+	if err := r.List(ctx, &deployments, listOpts); err != nil {
+		return err
+	}
+	var statefulSets appsv1.StatefulSetList
+	if err := r.List(ctx, &statefulSets, listOpts); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	halfLife := usageHalfLife(profile)
+	observe := func(target targetRef, container string, request resource.Quantity) {
+		cores := observedValue / 100 * request.AsApproximateFloat64()
+		r.usageHistory.observe(usageKey{workloadKey{profile.Namespace, target}, container}, cores, halfLife, now)
+		log.FromContext(ctx).V(1).Info("Observed CPU usage", "kind", target.Kind, "name", target.Name, "container", container, "cores", cores)
+	}
+	for _, deployment := range deployments.Items {
+		if container, request, ok := firstCPURequest(profile, deployment.Annotations, deployment.Spec.Template.Spec.Containers); ok {
+			observe(targetRef{Kind: "Deployment", Name: deployment.Name}, container, request)
+		}
+	}
+	for _, ss := range statefulSets.Items {
+		if container, request, ok := firstCPURequest(profile, ss.Annotations, ss.Spec.Template.Spec.Containers); ok {
+			observe(targetRef{Kind: "StatefulSet", Name: ss.Name}, container, request)
+		}
+	}
+	return nil
+}
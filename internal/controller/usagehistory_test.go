@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Usage history", func() {
+	now := time.Date(2025, time.October, 17, 12, 0, 0, 0, time.UTC)
+	web := usageKey{workloadKey{"team-a", targetRef{Kind: "Deployment", Name: "web"}}, "app"}
+
+	var (
+		profile *optimizerv1.ResourceOptimizerProfile
+		usage   *usageHistograms
+	)
+
+	BeforeEach(func() {
+		profile = &optimizerv1.ResourceOptimizerProfile{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"},
+			Spec: optimizerv1.ResourceOptimizerProfileSpec{
+				OptimizationPolicy: "Resize",
+				CPUThresholds:      optimizerv1.ThresholdSpec{Min: 30, Max: 70},
+				UsageHistory:       &optimizerv1.UsageHistory{},
+			},
+		}
+		usage = &usageHistograms{}
+	})
+
+	It("should size requests to a percentile of the usage", func() {
+		_, ok := usage.cpuRequest(profile, web)
+		Expect(ok).To(BeFalse())
+
+		for i := range 100 {
+			usage.observe(web, 0.1+float64(i)/100, DefaultUsageHalfLife, now.Add(time.Duration(i)*time.Second))
+		}
+		// The 90th percentile, 0.99 cores, rounded up to its bucket, at 50%.
+		request, ok := usage.cpuRequest(profile, web)
+		Expect(ok).To(BeTrue())
+		Expect(request.MilliValue()).To(BeNumerically("~", 2000, 100))
+
+		profile.Spec.UsageHistory.Percentile = ptr.To[int32](50)
+		request, _ = usage.cpuRequest(profile, web)
+		Expect(request.MilliValue()).To(BeNumerically("~", 1200, 60))
+
+		profile.Spec.MaxCPU = ptr.To(resource.MustParse("1"))
+		request, _ = usage.cpuRequest(profile, web)
+		Expect(request.String()).To(Equal("1"))
+	})
+
+	It("should weigh recent usage more", func() {
+		profile.Spec.UsageHistory.Percentile = ptr.To[int32](50)
+		for i := range 10 {
+			usage.observe(web, 1, time.Hour, now.Add(time.Duration(i)*time.Minute))
+		}
+		// Four half-lives later, a sample outweighs the ten older ones.
+		usage.observe(web, 0.2, time.Hour, now.Add(4*time.Hour))
+		usage.observe(web, 0.2, time.Hour, now.Add(4*time.Hour))
+		request, _ := usage.cpuRequest(profile, web)
+		Expect(request.MilliValue()).To(BeNumerically("~", 400, 20))
+
+		// Without a usageHistory, requests come from the latest utilization.
+		profile.Spec.UsageHistory = nil
+		_, ok := usage.cpuRequest(profile, web)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should cover usage from idle to the largest nodes", func() {
+		Expect(usageBucket(0)).To(Equal(0))
+		Expect(usageBucketEnd(usageBucket(0.25))).To(BeNumerically(">=", 0.25))
+		Expect(usageBucketEnd(usageBucket(0.25))).To(BeNumerically("<", 0.25*usageBucketRatio))
+		Expect(usageBucketEnd(usageBuckets - 1)).To(BeNumerically(">=", usageMaxCores))
+	})
+})
@@ -0,0 +1,205 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/common/model"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/notify"
+)
+
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+
+// ExpandVolumeAction grows a PersistentVolumeClaim whose volume is nearing
+// capacity. It is taken alongside the CPU driven actions, and is not subject
+// to their cooldown.
+const ExpandVolumeAction = "ExpandVolume"
+
+// Defaults of spec.volumeExpansion.
+const (
+	DefaultVolumeThresholdPercent = 80
+	DefaultVolumeIncreasePercent  = 50
+)
+
+// volumeUsagePromQL returns the used share of the volumes of the given claims
+// from the kubelet volume stats, by claim.
+func volumeUsagePromQL(namespace string, claims []string) string {
+	selector := fmt.Sprintf(`namespace="%s", persistentvolumeclaim=~"%s"`, namespace, strings.Join(claims, "|"))
+	return fmt.Sprintf(`max by (persistentvolumeclaim) (kubelet_volume_stats_used_bytes{%s}) / max by (persistentvolumeclaim) (kubelet_volume_stats_capacity_bytes{%s})`,
+		selector, selector)
+}
+
+// targetClaims returns the names of the claims mounted by the pods of the
+// profile, sorted.
+func targetClaims(ctx context.Context, c client.Reader, profile *optimizerv1.ResourceOptimizerProfile) ([]string, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&profile.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector: %w", err)
+	}
+	var pods corev1.PodList
+	if err := c.List(ctx, &pods, &client.ListOptions{Namespace: profile.Namespace, LabelSelector: selector}); err != nil {
+		return nil, err
+	}
+	var claims []string
+	for _, pod := range pods.Items {
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim != nil && !slices.Contains(claims, volume.PersistentVolumeClaim.ClaimName) {
+				claims = append(claims, volume.PersistentVolumeClaim.ClaimName)
+			}
+		}
+	}
+	slices.Sort(claims)
+	return claims, nil
+}
+
+// volumeUsage returns the used share, from 0 to 1, of the volumes of the
+// profile's claims that report stats.
+func volumeUsage(ctx context.Context, promAPI PrometheusClient, namespace string, claims []string) (map[string]float64, error) {
+	result, err := executePromQL(ctx, promAPI, volumeUsagePromQL(namespace, claims))
+	if err != nil {
+		return nil, err
+	}
+	vector, ok := result.(model.Vector)
+	if !ok {
+		return nil, fmt.Errorf("volume usage query returned %s, not a vector", result.Type())
+	}
+	usage := map[string]float64{}
+	for _, sample := range vector {
+		usage[string(sample.Metric["persistentvolumeclaim"])] = float64(sample.Value)
+	}
+	return usage, nil
+}
+
+// expandedSize returns the size a claim of the given capacity is expanded to,
+// rounded up to whole gibibytes and capped at maxSize.
+func expandedSize(expansion *optimizerv1.VolumeExpansion, capacity resource.Quantity) resource.Quantity {
+	increase := int64(DefaultVolumeIncreasePercent)
+	if expansion.IncreasePercent != nil {
+		increase = int64(*expansion.IncreasePercent)
+	}
+	const gibibyte = 1 << 30
+	bytes := capacity.Value() * (100 + increase) / 100
+	bytes = (bytes + gibibyte - 1) / gibibyte * gibibyte
+	if expansion.MaxSize != nil && bytes > expansion.MaxSize.Value() {
+		return expansion.MaxSize.DeepCopy()
+	}
+	return *resource.NewQuantity(bytes, resource.BinarySI)
+}
+
+// reconcileVolumes recommends the expansion of the claims of the profile's
+// targets whose volumes are nearing capacity, and expands them when the
+// profile allows it, recording the recommendations in the status.
+func (r *ResourceOptimizerProfileReconciler) reconcileVolumes(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile) error {
+	expansion := profile.Spec.VolumeExpansion
+	if expansion == nil {
+		profile.Status.VolumeRecommendations = nil
+		return nil
+	}
+	claims, err := targetClaims(ctx, r.Client, profile)
+	if err != nil || len(claims) == 0 {
+		profile.Status.VolumeRecommendations = nil
+		return err
+	}
+	usage, err := volumeUsage(ctx, r.PrometheusAPI, profile.Namespace, claims)
+	if err != nil {
+		r.recordQueryError(profile)
+		return err
+	}
+	threshold := float64(DefaultVolumeThresholdPercent)
+	if expansion.ThresholdPercent != nil {
+		threshold = float64(*expansion.ThresholdPercent)
+	}
+
+	var recommendations []optimizerv1.VolumeRecommendation
+	for _, name := range claims {
+		used, ok := usage[name]
+		if !ok || used*100 < threshold {
+			continue
+		}
+		var claim corev1.PersistentVolumeClaim
+		if err := r.Get(ctx, types.NamespacedName{Namespace: profile.Namespace, Name: name}, &claim); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		capacity := claim.Status.Capacity[corev1.ResourceStorage]
+		recommendation := optimizerv1.VolumeRecommendation{
+			PersistentVolumeClaim: name,
+			UsedPercent:           int32(used * 100),
+			Capacity:              capacity,
+		}
+		if requested := claim.Spec.Resources.Requests[corev1.ResourceStorage]; requested.Cmp(capacity) > 0 {
+			recommendation.Message = fmt.Sprintf("Expansion to %s is in progress", requested.String())
+			recommendations = append(recommendations, recommendation)
+			continue
+		}
+		size := expandedSize(expansion, capacity)
+		if size.Cmp(capacity) <= 0 {
+			recommendation.Message = "The claim is at the maxSize"
+			recommendations = append(recommendations, recommendation)
+			continue
+		}
+		recommendation.RecommendedSize = &size
+		recommendation.Expanded, recommendation.Message, err = r.expandVolume(ctx, profile, &claim, size, used*100)
+		if err != nil {
+			recommendation.Message = err.Error()
+		}
+		recommendations = append(recommendations, recommendation)
+	}
+	profile.Status.VolumeRecommendations = recommendations
+	return nil
+}
+
+// expandVolume requests a claim be expanded to size, when the profile and the
+// claim's StorageClass allow it. Otherwise it returns why the claim was left
+// alone.
+func (r *ResourceOptimizerProfileReconciler) expandVolume(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, claim *corev1.PersistentVolumeClaim, size resource.Quantity, usedPercent float64) (bool, string, error) {
+	if !profile.Spec.VolumeExpansion.Expand || profile.Spec.OptimizationPolicy == "Recommend" {
+		r.recordSkippedAction(profile, ExpandVolumeAction, SkipReasonDryRun)
+		return false, "", nil
+	}
+	if claim.Spec.StorageClassName == nil || *claim.Spec.StorageClassName == "" {
+		return false, "The claim has no StorageClass", nil
+	}
+	var storageClass storagev1.StorageClass
+	if err := r.Get(ctx, types.NamespacedName{Name: *claim.Spec.StorageClassName}, &storageClass); err != nil {
+		return false, "", fmt.Errorf("getting StorageClass %s: %w", *claim.Spec.StorageClassName, err)
+	}
+	if storageClass.AllowVolumeExpansion == nil || !*storageClass.AllowVolumeExpansion {
+		return false, fmt.Sprintf("StorageClass %s does not allow volume expansion", storageClass.Name), nil
+	}
+
+	patch := client.MergeFrom(claim.DeepCopy())
+	before := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+	if claim.Spec.Resources.Requests == nil {
+		claim.Spec.Resources.Requests = corev1.ResourceList{}
+	}
+	claim.Spec.Resources.Requests[corev1.ResourceStorage] = size
+	err := r.Patch(ctx, claim, patch)
+	target := targetRef{Kind: "PersistentVolumeClaim", Name: claim.Name}
+	r.recordAudit(ctx, profile, ExpandVolumeAction, target, "spec.resources.requests.storage", before.String(), size.String(), usedPercent, err)
+	details := fmt.Sprintf("Volume of %s was %.0f%% full, expanding it from %s to %s", claim.Name, usedPercent, before.String(), size.String())
+	if err != nil {
+		r.recordActionError(profile, ExpandVolumeAction)
+		r.notify(ctx, profile, notify.EventActionFailed, ExpandVolumeAction, usedPercent, err.Error())
+		return false, "", fmt.Errorf("patching PersistentVolumeClaim %s: %w", claim.Name, err)
+	}
+	r.recordAction(profile, ExpandVolumeAction, "PersistentVolumeClaim")
+	r.notify(ctx, profile, notify.EventAction, ExpandVolumeAction, usedPercent, details)
+	log.FromContext(ctx).Info("Expanded PersistentVolumeClaim", "claim", claim.Name, "size", size.String())
+	return true, "", nil
+}
@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/common/model"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Volume expansion", func() {
+	It("should query the used share of the claims' volumes", func() {
+		Expect(volumeUsagePromQL("shop", []string{"data-db-0", "data-db-1"})).To(Equal(
+			`max by (persistentvolumeclaim) (kubelet_volume_stats_used_bytes{namespace="shop", persistentvolumeclaim=~"data-db-0|data-db-1"}) / ` +
+				`max by (persistentvolumeclaim) (kubelet_volume_stats_capacity_bytes{namespace="shop", persistentvolumeclaim=~"data-db-0|data-db-1"})`))
+	})
+
+	It("should round the expanded size up to whole gibibytes and cap it at maxSize", func() {
+		expansion := &optimizerv1.VolumeExpansion{}
+		size := expandedSize(expansion, resource.MustParse("10Gi"))
+		Expect(size.Cmp(resource.MustParse("15Gi"))).To(BeZero())
+		size = expandedSize(expansion, resource.MustParse("1Gi"))
+		Expect(size.Cmp(resource.MustParse("2Gi"))).To(BeZero())
+
+		expansion.MaxSize = ptr.To(resource.MustParse("12Gi"))
+		size = expandedSize(expansion, resource.MustParse("10Gi"))
+		Expect(size.Cmp(resource.MustParse("12Gi"))).To(BeZero())
+	})
+
+	Context("when reconciling", func() {
+		var (
+			reconciler *ResourceOptimizerProfileReconciler
+			profile    *optimizerv1.ResourceOptimizerProfile
+		)
+
+		BeforeEach(func() {
+			scheme := runtime.NewScheme()
+			Expect(corev1.AddToScheme(scheme)).To(Succeed())
+			Expect(storagev1.AddToScheme(scheme)).To(Succeed())
+			Expect(optimizerv1.AddToScheme(scheme)).To(Succeed())
+			claim := func(name, storageClass string) *corev1.PersistentVolumeClaim {
+				return &corev1.PersistentVolumeClaim{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop"},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						StorageClassName: ptr.To(storageClass),
+						Resources: corev1.VolumeResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+						},
+					},
+					Status: corev1.PersistentVolumeClaimStatus{
+						Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+					},
+				}
+			}
+			pod := func(name, claimName string) *corev1.Pod {
+				return &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", Labels: map[string]string{"app": "db"}},
+					Spec: corev1.PodSpec{Volumes: []corev1.Volume{{
+						Name: "data",
+						VolumeSource: corev1.VolumeSource{
+							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+						},
+					}}},
+				}
+			}
+			reconciler = &ResourceOptimizerProfileReconciler{
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+					pod("db-0", "data-db-0"), pod("db-1", "data-db-1"), pod("db-2", "data-db-2"),
+					claim("data-db-0", "expandable"), claim("data-db-1", "fixed"), claim("data-db-2", "expandable"),
+					&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "expandable"}, AllowVolumeExpansion: ptr.To(true)},
+					&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "fixed"}},
+				).Build(),
+				PrometheusAPI: &mockPrometheusAPI{result: model.Vector{
+					{Metric: model.Metric{"persistentvolumeclaim": "data-db-0"}, Value: 0.9},
+					{Metric: model.Metric{"persistentvolumeclaim": "data-db-1"}, Value: 0.85},
+					{Metric: model.Metric{"persistentvolumeclaim": "data-db-2"}, Value: 0.5},
+				}},
+			}
+			profile = &optimizerv1.ResourceOptimizerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "shop"},
+				Spec: optimizerv1.ResourceOptimizerProfileSpec{
+					Selector:           metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+					OptimizationPolicy: "Scale",
+					VolumeExpansion:    &optimizerv1.VolumeExpansion{},
+				},
+			}
+		})
+
+		storage := func(name string) resource.Quantity {
+			var claim corev1.PersistentVolumeClaim
+			Expect(reconciler.Get(context.Background(), types.NamespacedName{Namespace: "shop", Name: name}, &claim)).To(Succeed())
+			return claim.Spec.Resources.Requests[corev1.ResourceStorage]
+		}
+
+		It("should only recommend the expansion of claims above the threshold by default", func() {
+			Expect(reconciler.reconcileVolumes(context.Background(), profile)).To(Succeed())
+			recommendations := profile.Status.VolumeRecommendations
+			Expect(recommendations).To(HaveLen(2))
+			Expect(recommendations[0].PersistentVolumeClaim).To(Equal("data-db-0"))
+			Expect(recommendations[0].UsedPercent).To(Equal(int32(90)))
+			Expect(recommendations[0].RecommendedSize.Cmp(resource.MustParse("15Gi"))).To(BeZero())
+			Expect(recommendations[0].Expanded).To(BeFalse())
+			Expect(recommendations[1].PersistentVolumeClaim).To(Equal("data-db-1"))
+
+			size := storage("data-db-0")
+			Expect(size.Cmp(resource.MustParse("10Gi"))).To(BeZero())
+		})
+
+		It("should expand claims whose StorageClass allows it", func() {
+			profile.Spec.VolumeExpansion.Expand = true
+			Expect(reconciler.reconcileVolumes(context.Background(), profile)).To(Succeed())
+			recommendations := profile.Status.VolumeRecommendations
+			Expect(recommendations).To(HaveLen(2))
+			Expect(recommendations[0].Expanded).To(BeTrue())
+			size := storage("data-db-0")
+			Expect(size.Cmp(resource.MustParse("15Gi"))).To(BeZero())
+
+			Expect(recommendations[1].Expanded).To(BeFalse())
+			Expect(recommendations[1].Message).To(Equal("StorageClass fixed does not allow volume expansion"))
+			size = storage("data-db-1")
+			Expect(size.Cmp(resource.MustParse("10Gi"))).To(BeZero())
+
+			// The resize is pending until the volume is expanded.
+			Expect(reconciler.reconcileVolumes(context.Background(), profile)).To(Succeed())
+			Expect(profile.Status.VolumeRecommendations[0].Message).To(Equal("Expansion to 15Gi is in progress"))
+		})
+
+		It("should not expand claims of profiles that only recommend", func() {
+			profile.Spec.VolumeExpansion.Expand = true
+			profile.Spec.OptimizationPolicy = "Recommend"
+			Expect(reconciler.reconcileVolumes(context.Background(), profile)).To(Succeed())
+			Expect(profile.Status.VolumeRecommendations[0].Expanded).To(BeFalse())
+			size := storage("data-db-0")
+			Expect(size.Cmp(resource.MustParse("10Gi"))).To(BeZero())
+		})
+	})
+})
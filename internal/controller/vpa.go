@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// +kubebuilder:rbac:groups=autoscaling.k8s.io,resources=verticalpodautoscalers,verbs=get;list
+
+// Recommendation sources of the Resize policy.
+const (
+	// RecommendationSourceK20s derives new CPU requests from the observed
+	// utilization and the profile's thresholds.
+	RecommendationSourceK20s = "K20s"
+	// RecommendationSourceVPA takes new CPU requests from the target of a
+	// VerticalPodAutoscaler in Off mode.
+	RecommendationSourceVPA = "VPA"
+	// RecommendationSourcePercentile sizes new CPU requests to a percentile of
+	// the usage over a window, plus headroom.
+	RecommendationSourcePercentile = "Percentile"
+)
+
+var verticalPodAutoscalerGVK = schema.GroupVersionKind{Group: "autoscaling.k8s.io", Version: "v1", Kind: "VerticalPodAutoscalerList"}
+
+// sourceRecommendations holds the CPU requests recommended by a profile's
+// recommendation source, by target workload and container name.
+type sourceRecommendations map[targetRef]map[string]resource.Quantity
+
+// cpuRequest returns the CPU request recommended for a container of a workload.
+func (s sourceRecommendations) cpuRequest(target targetRef, container string) (resource.Quantity, bool) {
+	request, ok := s[target][container]
+	return request, ok
+}
+
+// set records the CPU request recommended for a container of a workload.
+func (s sourceRecommendations) set(target targetRef, container string, request resource.Quantity) {
+	if s[target] == nil {
+		s[target] = map[string]resource.Quantity{}
+	}
+	s[target][container] = request
+}
+
+// listSourceRecommendations returns the recommendations of the profile's
+// recommendation source, or nil for the K20s source. promAPI may be nil, which
+// disables the Percentile source.
+func listSourceRecommendations(ctx context.Context, c client.Reader, promAPI PrometheusRangeClient, profile *optimizerv1.ResourceOptimizerProfile) (sourceRecommendations, error) {
+	switch profile.Spec.RecommendationSource {
+	case RecommendationSourceVPA:
+		return listVPARecommendations(ctx, c, profile)
+	case RecommendationSourcePercentile:
+		if promAPI == nil {
+			return nil, nil
+		}
+		return listPercentileRecommendations(ctx, c, promAPI, profile, time.Now())
+	}
+	return nil, nil
+}
+
+// listVPARecommendations reads the recommendations of the VerticalPodAutoscalers
+// in Off mode in the profile's namespace. VPAs in the other modes are ignored
+// since they apply their recommendations themselves. It returns nil when the
+// VPA CRDs are not installed.
+func listVPARecommendations(ctx context.Context, c client.Reader, profile *optimizerv1.ResourceOptimizerProfile) (sourceRecommendations, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(verticalPodAutoscalerGVK)
+	if err := c.List(ctx, list, client.InNamespace(profile.Namespace)); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("listing VerticalPodAutoscalers: %w", err)
+	}
+
+	recommendations := sourceRecommendations{}
+	for _, vpa := range list.Items {
+		if mode, _, _ := unstructured.NestedString(vpa.Object, "spec", "updatePolicy", "updateMode"); mode != "Off" {
+			continue
+		}
+		kind, _, _ := unstructured.NestedString(vpa.Object, "spec", "targetRef", "kind")
+		name, _, _ := unstructured.NestedString(vpa.Object, "spec", "targetRef", "name")
+		containers, _, _ := unstructured.NestedSlice(vpa.Object, "status", "recommendation", "containerRecommendations")
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			containerName, _, _ := unstructured.NestedString(container, "containerName")
+			cpu, _, _ := unstructured.NestedString(container, "target", "cpu")
+			request, err := resource.ParseQuantity(cpu)
+			if err != nil {
+				continue
+			}
+			recommendations.set(targetRef{Kind: kind, Name: name}, containerName, request)
+		}
+	}
+	return recommendations, nil
+}
+
+// recommendTargetCPURequest returns the CPU request recommended for a target's
+// container. With the VPA and Percentile sources it is the recommendation of the
+// source, bounded by minCPU and maxCPU, and the profile's own recommendation is
+// logged next to it for comparison. Containers the source has no recommendation
+// for yet fall back to the profile's own recommendation. That is sized from the
+// container's usage histogram for profiles with a usageHistory, once it has
+// one, and from the observed utilization otherwise. Either way the preset of
+// the target's runtime, if it names one, is applied, and the change from the
+// current request is capped by the profile's maxResizePercent.
+func recommendTargetCPURequest(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile, sources sourceRecommendations, usage *usageHistograms, target targetRef, runtime, container string, currentRequest resource.Quantity, observedValue float64) *resource.Quantity {
+	preset := runtimePresetFor(ctx, target, runtime)
+	recommended := recommendCPURequest(ctx, profile, currentRequest, observedValue, preset.cpuBuffer)
+	if fromHistory, ok := usage.cpuRequest(profile, usageKey{workloadKey{profile.Namespace, target}, container}); ok {
+		log.FromContext(ctx).Info("Using the usage history", "kind", target.Kind, "name", target.Name,
+			"container", container, "history", fromHistory.String(), "latest", recommended.String())
+		recommended = fromHistory
+	}
+	if request, ok := sources.cpuRequest(target, container); ok {
+		log.FromContext(ctx).Info("Using the recommendation of the source", "source", profile.Spec.RecommendationSource,
+			"kind", target.Kind, "name", target.Name, "container", container, "recommended", request.String(), "k20s", recommended.String())
+		recommended = clampCPURequest(profile, request)
+	}
+	bounded := preset.bound(profile, recommended)
+	if capped := cappedCPURequest(profile, currentRequest, *bounded); capped.Cmp(*bounded) != 0 {
+		log.FromContext(ctx).Info("Capping the resize to maxResizePercent", "kind", target.Kind, "name", target.Name,
+			"container", container, "current", currentRequest.String(), "recommended", bounded.String(), "capped", capped.String())
+		return capped
+	}
+	return bounded
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("VPA recommendation source", func() {
+	labels := map[string]string{"app": "web"}
+
+	deployment := func(name string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-a", Labels: labels},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name:      "app",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}},
+			}}}}},
+		}
+	}
+	vpa := func(name, target, mode, cpu string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"targetRef":    map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "name": target},
+				"updatePolicy": map[string]interface{}{"updateMode": mode},
+			},
+			"status": map[string]interface{}{"recommendation": map[string]interface{}{"containerRecommendations": []interface{}{
+				map[string]interface{}{"containerName": "app", "target": map[string]interface{}{"cpu": cpu, "memory": "256Mi"}},
+			}}},
+		}}
+		u.SetAPIVersion("autoscaling.k8s.io/v1")
+		u.SetKind("VerticalPodAutoscaler")
+		u.SetNamespace("team-a")
+		u.SetName(name)
+		return u
+	}
+
+	var (
+		reconciler *ResourceOptimizerProfileReconciler
+		profile    *optimizerv1.ResourceOptimizerProfile
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		reconciler = &ResourceOptimizerProfileReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			deployment("web"), deployment("web-canary"), deployment("web-auto"),
+			vpa("web", "web", "Off", "400m"),
+			vpa("web-auto", "web-auto", "Auto", "300m"),
+		).Build()}
+		profile = &optimizerv1.ResourceOptimizerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+			Spec: optimizerv1.ResourceOptimizerProfileSpec{
+				Selector:             metav1.LabelSelector{MatchLabels: labels},
+				CPUThresholds:        optimizerv1.ThresholdSpec{Min: 20, Max: 80},
+				OptimizationPolicy:   "Resize",
+				RecommendationSource: RecommendationSourceVPA,
+			},
+		}
+	})
+
+	cpuRequest := func(name string) string {
+		var d appsv1.Deployment
+		Expect(reconciler.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: name}, &d)).To(Succeed())
+		return d.Spec.Template.Spec.Containers[0].Resources.Requests.Cpu().String()
+	}
+
+	It("should resize to the target of VPAs in Off mode", func() {
+		Expect(reconciler.executeResizeAction(context.Background(), profile, ResizeUpAction, 100)).Error().NotTo(HaveOccurred())
+		Expect(cpuRequest("web")).To(Equal("400m"))
+		// Without a VPA in Off mode the profile's own recommendation applies.
+		Expect(cpuRequest("web-canary")).To(Equal("250m"))
+		Expect(cpuRequest("web-auto")).To(Equal("250m"))
+	})
+
+	It("should bound VPA targets by maxCPU", func() {
+		profile.Spec.MaxCPU = ptr.To(resource.MustParse("300m"))
+		recommendations, err := recommendCPURequests(context.Background(), reconciler.Client, nil, profile, nil, 100)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recommendations[targetRef{Kind: "Deployment", Name: "web"}].String()).To(Equal("300m"))
+	})
+
+	It("should ignore VPAs with the K20s source", func() {
+		profile.Spec.RecommendationSource = ""
+		recommendations, err := recommendCPURequests(context.Background(), reconciler.Client, nil, profile, nil, 100)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recommendations[targetRef{Kind: "Deployment", Name: "web"}].String()).To(Equal("250m"))
+	})
+})
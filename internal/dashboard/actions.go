@@ -0,0 +1,114 @@
+package dashboard
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"slices"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/OpScaleHub/K20s/internal/audit"
+)
+
+// ActionsPath is where the HTML action log is served.
+const ActionsPath = "/actions"
+
+const actionsPageTemplate = `
+<!DOCTYPE html>
+<html>
+<head>
+    <title>Actions - K20s Controller Status</title>
+    <style>
+        body { font-family: sans-serif; margin: 2em; }
+        table { border-collapse: collapse; width: 100%; }
+        th, td { border: 1px solid #ddd; padding: 8px; text-align: left; }
+        th { background-color: #f2f2f2; }
+        h1 { color: #333; }
+        form { margin-bottom: 1em; }
+        .Failed { color: #c62828; }
+    </style>
+</head>
+<body>
+    <p><a href="{{.StatusPath}}">← All profiles</a></p>
+    <h1>Actions</h1>
+    <form method="get">
+        <label>Namespace <input name="namespace" value="{{.Filter.Namespace}}"></label>
+        <label>Type
+            <select name="action">
+                <option value="">All</option>
+                {{range .Types}}<option{{if eq . $.Filter.Action}} selected{{end}}>{{.}}</option>{{end}}
+            </select>
+        </label>
+        <button type="submit">Filter</button>
+    </form>
+    <table>
+        <thead>
+        <tr>
+            <th>Time</th>
+            <th>Profile</th>
+            <th>Target</th>
+            <th>Type</th>
+            <th>Change</th>
+            <th>Result</th>
+        </tr>
+        </thead>
+        <tbody>
+        {{range .Actions}}
+        <tr>
+            <td>{{.Time.Format "2006-01-02 15:04:05"}}</td>
+            <td><a href="{{$.StatusPath}}/{{.ProfileNamespace}}/{{.ProfileName}}">{{.ProfileNamespace}}/{{.ProfileName}}</a></td>
+            <td>{{.TargetKind}}/{{.TargetName}}</td>
+            <td>{{.Action}}</td>
+            <td>{{.Field}}: {{.Before}} → {{.After}}</td>
+            <td class="{{.Result}}">{{.Result}}{{with .Error}}: {{.}}{{end}}</td>
+        </tr>
+        {{else}}
+        <tr><td colspan="6">No actions recorded{{if or .Filter.Namespace .Filter.Action}} matching the filter{{end}}.</td></tr>
+        {{end}}
+        </tbody>
+    </table>
+</body>
+</html>
+`
+
+var actionsPage = template.Must(template.New("actions").Parse(actionsPageTemplate))
+
+// ActionsPage serves an HTML log of the recent actions across all profiles,
+// filtered by the namespace and action query parameters.
+type ActionsPage struct {
+	// Actions holds the actions shown on the page. When nil the log is empty.
+	Actions *audit.History
+}
+
+func (h *ActionsPage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	filter := actionFilterFrom(r.URL.Query())
+
+	var records []audit.Record
+	if h.Actions != nil {
+		records = h.Actions.Records()
+	}
+	// Offer every type still in the history, not only the filtered ones.
+	var types []string
+	for _, record := range records {
+		if !slices.Contains(types, record.Action) {
+			types = append(types, record.Action)
+		}
+	}
+	slices.Sort(types)
+
+	var buf bytes.Buffer
+	if err := actionsPage.Execute(&buf, struct {
+		Filter     actionFilter
+		Types      []string
+		Actions    []audit.Record
+		StatusPath string
+	}{filter, types, filter.apply(records, 0), StatusPath}); err != nil {
+		ctrl.Log.WithName("actions-page-handler").Error(err, "failed to execute HTML template")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	_, _ = w.Write(buf.Bytes())
+}
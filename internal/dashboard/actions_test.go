@@ -0,0 +1,59 @@
+package dashboard
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/OpScaleHub/K20s/internal/audit"
+)
+
+var _ = Describe("ActionsPage", func() {
+	var page *ActionsPage
+
+	BeforeEach(func() {
+		history := audit.NewHistory(10)
+		for _, record := range []audit.Record{
+			{ProfileNamespace: "team-a", ProfileName: "web", Action: "ScaleUp", TargetKind: "Deployment", TargetName: "web",
+				Field: "spec.replicas", Before: "2", After: "3", Result: audit.ResultSucceeded},
+			{ProfileNamespace: "team-b", ProfileName: "db", Action: "ResizeDown", TargetKind: "StatefulSet", TargetName: "db",
+				Field: "cpu", Before: "500m", After: "400m", Result: audit.ResultFailed, Error: "conflict"},
+		} {
+			record.Time = time.Unix(0, 0)
+			Expect(history.Record(context.Background(), record)).To(Succeed())
+		}
+		page = &ActionsPage{Actions: history}
+	})
+
+	serve := func(path string) string {
+		rec := httptest.NewRecorder()
+		page.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		return rec.Body.String()
+	}
+
+	It("should list the actions of all profiles", func() {
+		body := serve(ActionsPath)
+		Expect(body).To(ContainSubstring("team-a/web"))
+		Expect(body).To(ContainSubstring("spec.replicas: 2 → 3"))
+		Expect(body).To(ContainSubstring("Failed: conflict"))
+		Expect(body).To(ContainSubstring("<option>ResizeDown</option><option>ScaleUp</option>"))
+	})
+
+	It("should filter by namespace and type", func() {
+		body := serve(ActionsPath + "?namespace=team-b")
+		Expect(body).To(ContainSubstring("team-b/db"))
+		Expect(body).NotTo(ContainSubstring("team-a/web"))
+
+		body = serve(ActionsPath + "?action=ScaleUp")
+		Expect(body).To(ContainSubstring("team-a/web"))
+		Expect(body).NotTo(ContainSubstring("team-b/db"))
+		Expect(body).To(ContainSubstring("<option selected>ScaleUp</option>"))
+
+		Expect(serve(ActionsPath + "?action=ScaleDown")).To(ContainSubstring("No actions recorded matching the filter."))
+	})
+})
@@ -0,0 +1,315 @@
+// Package dashboard serves the controller's state over HTTP for people and
+// tools that should not need access to the Kubernetes API server.
+package dashboard
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/audit"
+)
+
+// APIPrefix is the path under which the JSON API is served.
+const APIPrefix = "/api/v1/"
+
+// Profile is the JSON representation of a ResourceOptimizerProfile.
+type Profile struct {
+	Namespace  string                                     `json:"namespace"`
+	Name       string                                     `json:"name"`
+	Generation int64                                      `json:"generation"`
+	Spec       optimizerv1.ResourceOptimizerProfileSpec   `json:"spec"`
+	Status     optimizerv1.ResourceOptimizerProfileStatus `json:"status"`
+}
+
+// ProfileList is the response of GET /api/v1/profiles.
+type ProfileList struct {
+	Items []Profile `json:"items"`
+	// Continue is set when more profiles match than limit. Passed as the
+	// continue parameter, it returns the next page.
+	Continue string `json:"continue,omitempty"`
+}
+
+// selectedList is a list response whose items only have the requested fields.
+type selectedList struct {
+	Items    []map[string]any `json:"items"`
+	Continue string           `json:"continue,omitempty"`
+}
+
+// ActionList is the response of GET /api/v1/actions.
+type ActionList struct {
+	Items []audit.Record `json:"items"`
+}
+
+// apiError is the body of every non-2xx response.
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// APIHandler serves read-only JSON views of the controller's state:
+//
+//	GET /api/v1/profiles                    all profiles, optionally
+//	                                        ?namespace=&limit=&continue=&fields=
+//	GET /api/v1/profiles/{namespace}/{name} a single profile, optionally ?fields=
+//	GET /api/v1/actions                     recent mutations, newest first,
+//	                                        optionally ?namespace=&profile=&action=&limit=
+//	GET /api/v1/showback                    requested, used and recommended CPU
+//	                                        per namespace, optionally ?namespace=
+//	GET /api/v1/services/{service}          the workloads of a service, what is
+//	                                        recommended for them and their recent
+//	                                        actions, optionally ?namespace=&limit=
+//	POST /api/v1/simulate                   the decision the controller would make
+//	                                        for a hypothetical profile, ?namespace=
+//	GET /api/v1/events                      live profile changes and actions as
+//	                                        server-sent events, optionally ?namespace=
+//	GET /api/v1/openapi.{json,yaml}         the OpenAPI document of this API
+//	GET /api/docs                           a Swagger UI for the OpenAPI document
+//
+// Responses are compressed with gzip for clients accepting it, except for the
+// event stream.
+type APIHandler struct {
+	// Client reads profiles, typically from the manager's cache.
+	Client client.Reader
+	// Actions holds the recent mutations served by /api/v1/actions. When nil the
+	// endpoint returns an empty list.
+	Actions *audit.History
+	// Events streams live changes to /api/v1/events. When nil the endpoint
+	// returns 404.
+	Events *Hub
+	// ServiceKey is the label, or annotation, naming the service of a workload
+	// for /api/v1/services. Defaults to DefaultServiceKey.
+	ServiceKey string
+
+	mux *http.ServeMux
+}
+
+// NewAPIHandler returns a handler serving the API under APIPrefix and its
+// documentation at DocsPath.
+func NewAPIHandler(c client.Reader, actions *audit.History, events *Hub) *APIHandler {
+	h := &APIHandler{Client: c, Actions: actions, Events: events, mux: http.NewServeMux()}
+	h.mux.HandleFunc("GET "+APIPrefix+"profiles", h.listProfiles)
+	h.mux.HandleFunc("GET "+APIPrefix+"profiles/{namespace}/{name}", h.getProfile)
+	h.mux.HandleFunc("GET "+APIPrefix+"actions", h.listActions)
+	h.mux.HandleFunc("GET "+APIPrefix+"showback", h.listShowback)
+	h.mux.HandleFunc("GET "+APIPrefix+"services/{service}", h.getService)
+	h.mux.HandleFunc("POST "+APIPrefix+"simulate", h.simulate)
+	h.mux.HandleFunc("GET "+APIPrefix+"events", h.streamEvents)
+	h.mux.HandleFunc("GET "+APIPrefix+"openapi.json", h.openAPIJSON)
+	h.mux.HandleFunc("GET "+APIPrefix+"openapi.yaml", h.openAPIYAML)
+	h.mux.HandleFunc("GET "+DocsPath, h.docs)
+	h.mux.HandleFunc(APIPrefix, func(w http.ResponseWriter, _ *http.Request) {
+		writeError(w, http.StatusNotFound, "not found")
+	})
+	return h
+}
+
+func (h *APIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if acceptsGzip(r) && r.URL.Path != APIPrefix+"events" {
+		gz := &gzipResponseWriter{ResponseWriter: w}
+		defer func() { _ = gz.Close() }()
+		w = gz
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *APIHandler) listProfiles(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, err := parseLimit(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	after, err := decodeContinue(query.Get("continue"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid continue token")
+		return
+	}
+
+	var opts []client.ListOption
+	if ns := query.Get("namespace"); ns != "" {
+		opts = append(opts, client.InNamespace(ns))
+	}
+	var profiles optimizerv1.ResourceOptimizerProfileList
+	if err := h.Client.List(r.Context(), &profiles, opts...); err != nil {
+		ctrl.Log.WithName("api").Error(err, "failed to list ResourceOptimizerProfiles")
+		writeError(w, http.StatusInternalServerError, "failed to list profiles")
+		return
+	}
+	// Pages are cut in namespace/name order, which the cache does not keep.
+	slices.SortFunc(profiles.Items, func(a, b optimizerv1.ResourceOptimizerProfile) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+	})
+
+	list := ProfileList{Items: []Profile{}}
+	for i := range profiles.Items {
+		profile := &profiles.Items[i]
+		if after != nil && cmp.Or(cmp.Compare(profile.Namespace, after.Namespace), cmp.Compare(profile.Name, after.Name)) <= 0 {
+			continue
+		}
+		if limit > 0 && len(list.Items) == limit {
+			last := list.Items[len(list.Items)-1]
+			list.Continue = encodeContinue(types.NamespacedName{Namespace: last.Namespace, Name: last.Name})
+			break
+		}
+		list.Items = append(list.Items, profileView(profile))
+	}
+
+	fields := parseFields(query.Get("fields"))
+	if fields == nil {
+		writeJSON(w, http.StatusOK, list)
+		return
+	}
+	selected := selectedList{Items: make([]map[string]any, 0, len(list.Items)), Continue: list.Continue}
+	for _, item := range list.Items {
+		object, err := selectFields(item, fields)
+		if err != nil {
+			ctrl.Log.WithName("api").Error(err, "failed to select fields", "fields", fields)
+			writeError(w, http.StatusInternalServerError, "failed to select fields")
+			return
+		}
+		selected.Items = append(selected.Items, object)
+	}
+	writeJSON(w, http.StatusOK, selected)
+}
+
+// parseLimit returns the limit query parameter, or 0 without one.
+func parseLimit(query url.Values) (int, error) {
+	raw := query.Get("limit")
+	if raw == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, errors.New("limit must be a non-negative integer")
+	}
+	return n, nil
+}
+
+// encodeContinue returns the continue token of a page ending with key.
+func encodeContinue(key types.NamespacedName) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key.String()))
+}
+
+// decodeContinue returns the last profile of the previous page, or nil
+// without a token.
+func decodeContinue(token string) (*types.NamespacedName, error) {
+	if token == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+	namespace, name, ok := strings.Cut(string(raw), "/")
+	if !ok {
+		return nil, errors.New("continue token is not namespace/name")
+	}
+	return &types.NamespacedName{Namespace: namespace, Name: name}, nil
+}
+
+func (h *APIHandler) getProfile(w http.ResponseWriter, r *http.Request) {
+	key := types.NamespacedName{Namespace: r.PathValue("namespace"), Name: r.PathValue("name")}
+	var profile optimizerv1.ResourceOptimizerProfile
+	if err := h.Client.Get(r.Context(), key, &profile); err != nil {
+		if apierrors.IsNotFound(err) {
+			writeError(w, http.StatusNotFound, "profile "+key.String()+" not found")
+			return
+		}
+		ctrl.Log.WithName("api").Error(err, "failed to get ResourceOptimizerProfile", "profile", key.String())
+		writeError(w, http.StatusInternalServerError, "failed to get profile")
+		return
+	}
+	fields := parseFields(r.URL.Query().Get("fields"))
+	if fields == nil {
+		writeJSON(w, http.StatusOK, profileView(&profile))
+		return
+	}
+	selected, err := selectFields(profileView(&profile), fields)
+	if err != nil {
+		ctrl.Log.WithName("api").Error(err, "failed to select fields", "fields", fields)
+		writeError(w, http.StatusInternalServerError, "failed to select fields")
+		return
+	}
+	writeJSON(w, http.StatusOK, selected)
+}
+
+func (h *APIHandler) listActions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, err := parseLimit(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	list := ActionList{Items: []audit.Record{}}
+	if h.Actions != nil {
+		list.Items = append(list.Items, actionFilterFrom(query).apply(h.Actions.Records(), limit)...)
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// actionFilter selects actions by profile and type. Empty fields match
+// everything.
+type actionFilter struct {
+	Namespace string
+	Profile   string
+	Action    string
+}
+
+func actionFilterFrom(query url.Values) actionFilter {
+	return actionFilter{Namespace: query.Get("namespace"), Profile: query.Get("profile"), Action: query.Get("action")}
+}
+
+// apply returns the records matching the filter, at most limit of them unless
+// limit is 0.
+func (f actionFilter) apply(records []audit.Record, limit int) []audit.Record {
+	var out []audit.Record
+	for _, record := range records {
+		if f.Namespace != "" && record.ProfileNamespace != f.Namespace {
+			continue
+		}
+		if f.Profile != "" && record.ProfileName != f.Profile {
+			continue
+		}
+		if f.Action != "" && record.Action != f.Action {
+			continue
+		}
+		if limit > 0 && len(out) == limit {
+			break
+		}
+		out = append(out, record)
+	}
+	return out
+}
+
+func profileView(profile *optimizerv1.ResourceOptimizerProfile) Profile {
+	return Profile{
+		Namespace:  profile.Namespace,
+		Name:       profile.Name,
+		Generation: profile.Generation,
+		Spec:       profile.Spec,
+		Status:     profile.Status,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, apiError{Error: message})
+}
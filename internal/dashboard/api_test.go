@@ -0,0 +1,239 @@
+package dashboard
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/audit"
+)
+
+var _ = Describe("APIHandler", func() {
+	var (
+		handler *APIHandler
+		history *audit.History
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(optimizerv1.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a",
+				Labels: map[string]string{DefaultServiceKey: "web"}}},
+			&optimizerv1.ResourceOptimizerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+				Spec:       optimizerv1.ResourceOptimizerProfileSpec{OptimizationPolicy: "Balanced"},
+			},
+			&optimizerv1.ResourceOptimizerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "team-b"},
+				Spec:       optimizerv1.ResourceOptimizerProfileSpec{OptimizationPolicy: "Recommend"},
+			},
+		).Build()
+
+		history = audit.NewHistory(10)
+		handler = NewAPIHandler(c, history, NewHub())
+	})
+
+	get := func(path string, into any) int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		Expect(rec.Header().Get("Content-Type")).To(Equal("application/json"))
+		if into != nil {
+			Expect(json.Unmarshal(rec.Body.Bytes(), into)).To(Succeed())
+		}
+		return rec.Code
+	}
+
+	It("should list profiles, optionally by namespace", func() {
+		var list ProfileList
+		Expect(get("/api/v1/profiles", &list)).To(Equal(http.StatusOK))
+		Expect(list.Items).To(HaveLen(2))
+
+		Expect(get("/api/v1/profiles?namespace=team-b", &list)).To(Equal(http.StatusOK))
+		Expect(list.Items).To(HaveLen(1))
+		Expect(list.Items[0].Name).To(Equal("worker"))
+		Expect(list.Items[0].Spec.OptimizationPolicy).To(Equal("Recommend"))
+	})
+
+	It("should return a single profile or 404", func() {
+		var profile Profile
+		Expect(get("/api/v1/profiles/team-a/web", &profile)).To(Equal(http.StatusOK))
+		Expect(profile.Namespace).To(Equal("team-a"))
+		Expect(profile.Name).To(Equal("web"))
+
+		var apiErr apiError
+		Expect(get("/api/v1/profiles/team-a/missing", &apiErr)).To(Equal(http.StatusNotFound))
+		Expect(apiErr.Error).To(ContainSubstring("team-a/missing"))
+	})
+
+	It("should page through profiles with limit and continue", func() {
+		var list ProfileList
+		Expect(get("/api/v1/profiles?limit=1", &list)).To(Equal(http.StatusOK))
+		Expect(list.Items).To(HaveLen(1))
+		Expect(list.Items[0].Name).To(Equal("web"))
+		Expect(list.Continue).NotTo(BeEmpty())
+
+		var next ProfileList
+		Expect(get("/api/v1/profiles?limit=1&continue="+list.Continue, &next)).To(Equal(http.StatusOK))
+		Expect(next.Items).To(HaveLen(1))
+		Expect(next.Items[0].Name).To(Equal("worker"))
+		Expect(next.Continue).To(BeEmpty())
+
+		Expect(get("/api/v1/profiles?limit=x", nil)).To(Equal(http.StatusBadRequest))
+		Expect(get("/api/v1/profiles?continue=!!", nil)).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should only return the requested fields", func() {
+		var list selectedList
+		Expect(get("/api/v1/profiles?fields=name,spec.optimizationPolicy,missing", &list)).To(Equal(http.StatusOK))
+		Expect(list.Items).To(HaveLen(2))
+		Expect(list.Items[0]).To(Equal(map[string]any{
+			"name": "web",
+			"spec": map[string]any{"optimizationPolicy": "Balanced"},
+		}))
+
+		var profile map[string]any
+		Expect(get("/api/v1/profiles/team-b/worker?fields=namespace", &profile)).To(Equal(http.StatusOK))
+		Expect(profile).To(Equal(map[string]any{"namespace": "team-b"}))
+	})
+
+	It("should compress responses for clients accepting gzip", func() {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/profiles", nil)
+		req.Header.Set("Accept-Encoding", "br, gzip")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Content-Encoding")).To(Equal("gzip"))
+
+		body, err := gzip.NewReader(rec.Body)
+		Expect(err).NotTo(HaveOccurred())
+		var list ProfileList
+		Expect(json.NewDecoder(body).Decode(&list)).To(Succeed())
+		Expect(list.Items).To(HaveLen(2))
+
+		req.Header.Set("Accept-Encoding", "gzip;q=0")
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		Expect(rec.Header().Get("Content-Encoding")).To(BeEmpty())
+	})
+
+	It("should list recent actions newest first with filters", func() {
+		for i, name := range []string{"web", "worker", "web"} {
+			Expect(history.Record(context.Background(), audit.Record{
+				Time:             time.Unix(int64(i), 0),
+				ProfileNamespace: "team-a",
+				ProfileName:      name,
+				Action:           "ScaleUp",
+			})).To(Succeed())
+		}
+
+		var list ActionList
+		Expect(get("/api/v1/actions", &list)).To(Equal(http.StatusOK))
+		Expect(list.Items).To(HaveLen(3))
+		Expect(list.Items[0].Time.Unix()).To(Equal(int64(2)))
+
+		Expect(get("/api/v1/actions?profile=web&limit=1", &list)).To(Equal(http.StatusOK))
+		Expect(list.Items).To(HaveLen(1))
+		Expect(list.Items[0].ProfileName).To(Equal("web"))
+		Expect(list.Items[0].Time.Unix()).To(Equal(int64(2)))
+
+		Expect(get("/api/v1/actions?action=ScaleDown", &list)).To(Equal(http.StatusOK))
+		Expect(list.Items).To(BeEmpty())
+
+		Expect(get("/api/v1/actions?limit=-1", nil)).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should answer unknown API paths with a JSON 404", func() {
+		Expect(get("/api/v1/unknown", nil)).To(Equal(http.StatusNotFound))
+	})
+
+	It("should document every API route in the OpenAPI document", func() {
+		var spec struct {
+			Paths map[string]map[string]any `json:"paths"`
+		}
+		Expect(get("/api/v1/openapi.json", &spec)).To(Equal(http.StatusOK))
+		Expect(spec.Paths).To(HaveLen(7))
+		// A cancelled request ends the event stream right after its headers.
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		for path, operations := range spec.Paths {
+			if _, ok := operations["get"]; !ok {
+				// POST routes are covered by their own specs.
+				continue
+			}
+			// Substitute path parameters and expect the route to exist.
+			concrete := strings.NewReplacer("{namespace}", "team-a", "{name}", "web", "{service}", "web").Replace(path)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, concrete, nil).WithContext(ctx))
+			Expect(rec.Code).To(Equal(http.StatusOK), path)
+		}
+	})
+
+	Context("simulate", func() {
+		post := func(path, body string, into any) int {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+			Expect(rec.Header().Get("Content-Type")).To(Equal("application/json"))
+			if into != nil {
+				Expect(json.Unmarshal(rec.Body.Bytes(), into)).To(Succeed())
+			}
+			return rec.Code
+		}
+		spec := `{"selector":{"matchLabels":{"backstage.io/kubernetes-id":"web"}},"cpuThresholds":{"min":20,"max":80},"optimizationPolicy":"Scale"}`
+
+		It("should return the decision for a hypothetical spec at a synthetic utilization", func() {
+			var result SimulationResult
+			Expect(post("/api/v1/simulate?namespace=team-a", `{"spec":`+spec+`,"cpuUtilization":95}`, &result)).To(Equal(http.StatusOK))
+			Expect(result.Action).To(Equal("ScaleUp"))
+			Expect(result.Executed).To(BeTrue())
+			Expect(result.CPUUtilization).To(Equal(95.0))
+			Expect(result.Targets).To(BeEmpty())
+
+			Expect(post("/api/v1/simulate?namespace=team-a", `{"spec":`+spec+`,"cpuUtilization":50}`, &result)).To(Equal(http.StatusOK))
+			Expect(result.Action).To(Equal("DoNothing"))
+			Expect(result.Executed).To(BeFalse())
+		})
+
+		It("should start from an existing profile", func() {
+			var apiErr apiError
+			Expect(post("/api/v1/simulate?namespace=team-a", `{"name":"web","spec":`+spec+`}`, &apiErr)).To(Equal(http.StatusBadRequest))
+			Expect(apiErr.Error).To(ContainSubstring("has not observed one yet"))
+
+			Expect(post("/api/v1/simulate?namespace=team-a", `{"name":"missing","cpuUtilization":10}`, nil)).To(Equal(http.StatusNotFound))
+		})
+
+		It("should reject invalid requests and specs", func() {
+			Expect(post("/api/v1/simulate", `{"spec":`+spec+`,"cpuUtilization":95}`, nil)).To(Equal(http.StatusBadRequest))
+			Expect(post("/api/v1/simulate?namespace=team-a", `{"cpuUtilization":95}`, nil)).To(Equal(http.StatusBadRequest))
+			Expect(post("/api/v1/simulate?namespace=team-a", `{"spec":`+spec+`}`, nil)).To(Equal(http.StatusBadRequest))
+			Expect(post("/api/v1/simulate?namespace=team-a", `{"spec":`+spec+`,"cpuUtilization":-1}`, nil)).To(Equal(http.StatusBadRequest))
+			Expect(post("/api/v1/simulate?namespace=team-a", `{"spec":`+spec+`,"cpu":95}`, nil)).To(Equal(http.StatusBadRequest))
+
+			var apiErr apiError
+			invalid := `{"selector":{"matchLabels":{"app":"web"}},"cpuThresholds":{"min":80,"max":20},"optimizationPolicy":"Scale"}`
+			Expect(post("/api/v1/simulate?namespace=team-a", `{"spec":`+invalid+`,"cpuUtilization":95}`, &apiErr)).To(Equal(http.StatusUnprocessableEntity))
+			Expect(apiErr.Error).To(ContainSubstring("cpuThresholds"))
+		})
+	})
+
+	It("should serve a Swagger UI for the OpenAPI document", func() {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DocsPath, nil))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Content-Type")).To(Equal("text/html"))
+		Expect(rec.Body.String()).To(ContainSubstring("/api/v1/openapi.json"))
+	})
+})
@@ -0,0 +1,261 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apiserver/pkg/apis/apiserver"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/authenticatorfactory"
+	"k8s.io/apiserver/pkg/authentication/request/bearertoken"
+	"k8s.io/apiserver/pkg/authentication/request/union"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/apiserver/pkg/authorization/authorizerfactory"
+	"k8s.io/apiserver/plugin/pkg/authenticator/token/oidc"
+	authenticationv1 "k8s.io/client-go/kubernetes/typed/authentication/v1"
+	authorizationv1 "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// StatusPath is where the HTML status page is served.
+const StatusPath = "/status"
+
+// AuthOptions configures who may read the status page and the API.
+type AuthOptions struct {
+	// Authorize additionally requires every user to be allowed, through a
+	// SubjectAccessReview, to get or list ResourceOptimizerProfiles in the
+	// namespace they are reading.
+	Authorize bool
+
+	// OIDCIssuerURL accepts ID tokens issued by this OpenID Connect provider in
+	// addition to the tokens the Kubernetes API server accepts, e.g. tokens
+	// forwarded by an oauth2-proxy in front of the dashboard.
+	OIDCIssuerURL string
+	// OIDCClientID is the audience the ID tokens must be issued for.
+	OIDCClientID string
+	// OIDCUsernameClaim is the claim used as the user name. Defaults to "sub".
+	OIDCUsernameClaim string
+	// OIDCGroupsClaim is the claim holding the user's groups, if any.
+	OIDCGroupsClaim string
+}
+
+// Auth authenticates requests with bearer tokens, which are verified with
+// TokenReviews or against an OIDC issuer, and optionally authorizes them with
+// SubjectAccessReviews.
+type Auth struct {
+	authenticator authenticator.Request
+	// authorizer is nil when authorization is disabled.
+	authorizer authorizer.Authorizer
+}
+
+// NewAuth returns an Auth delegating to the API server described by config.
+// ctx bounds the background refresh of the OIDC issuer's keys.
+func NewAuth(ctx context.Context, config *rest.Config, httpClient *http.Client, opts AuthOptions) (*Auth, error) {
+	// Retry transient API server errors like the metrics endpoint's filter does.
+	backoff := &wait.Backoff{Duration: 500 * time.Millisecond, Factor: 1.5, Jitter: 0.2, Steps: 5}
+
+	authenticationClient, err := authenticationv1.NewForConfigAndClient(config, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	tokenReview, _, err := authenticatorfactory.DelegatingAuthenticatorConfig{
+		Anonymous:                &apiserver.AnonymousAuthConfig{Enabled: false},
+		CacheTTL:                 time.Minute,
+		TokenAccessReviewClient:  authenticationClient,
+		TokenAccessReviewTimeout: 10 * time.Second,
+		WebhookRetryBackoff:      backoff,
+	}.New()
+	if err != nil {
+		return nil, fmt.Errorf("creating token review authenticator: %w", err)
+	}
+
+	auth := &Auth{authenticator: tokenReview}
+	if opts.OIDCIssuerURL != "" {
+		idTokens, err := newOIDCAuthenticator(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		auth.authenticator = union.New(bearertoken.New(idTokens), tokenReview)
+	}
+
+	if opts.Authorize {
+		authorizationClient, err := authorizationv1.NewForConfigAndClient(config, httpClient)
+		if err != nil {
+			return nil, err
+		}
+		auth.authorizer, err = authorizerfactory.DelegatingAuthorizerConfig{
+			SubjectAccessReviewClient: authorizationClient,
+			AllowCacheTTL:             5 * time.Minute,
+			DenyCacheTTL:              30 * time.Second,
+			WebhookRetryBackoff:       backoff,
+		}.New()
+		if err != nil {
+			return nil, fmt.Errorf("creating subject access review authorizer: %w", err)
+		}
+	}
+	return auth, nil
+}
+
+func newOIDCAuthenticator(ctx context.Context, opts AuthOptions) (authenticator.Token, error) {
+	if opts.OIDCClientID == "" {
+		return nil, fmt.Errorf("an OIDC client ID is required with issuer %s", opts.OIDCIssuerURL)
+	}
+	usernameClaim := opts.OIDCUsernameClaim
+	if usernameClaim == "" {
+		usernameClaim = "sub"
+	}
+	noPrefix := ""
+	jwt := apiserver.JWTAuthenticator{
+		Issuer: apiserver.Issuer{
+			URL:       opts.OIDCIssuerURL,
+			Audiences: []string{opts.OIDCClientID},
+		},
+		ClaimMappings: apiserver.ClaimMappings{
+			Username: apiserver.PrefixedClaimOrExpression{Claim: usernameClaim, Prefix: &noPrefix},
+		},
+	}
+	if opts.OIDCGroupsClaim != "" {
+		jwt.ClaimMappings.Groups = apiserver.PrefixedClaimOrExpression{Claim: opts.OIDCGroupsClaim, Prefix: &noPrefix}
+	}
+	idTokens, err := oidc.New(ctx, oidc.Options{JWTAuthenticator: jwt})
+	if err != nil {
+		return nil, fmt.Errorf("creating OIDC authenticator: %w", err)
+	}
+	return idTokens, nil
+}
+
+var (
+	// ErrUnauthenticated is returned by Check for a missing or rejected token.
+	ErrUnauthenticated = errors.New("a valid bearer token is required")
+	// ErrForbidden is returned by Check when the user may not read the profiles.
+	ErrForbidden = errors.New("forbidden")
+)
+
+// Check authenticates a bearer token and, when authorization is enabled,
+// checks that its user may verb ResourceOptimizerProfiles in namespace, or in
+// all namespaces when it is empty. It applies the access control of Wrap to
+// callers that are not HTTP handlers, such as the gRPC API.
+func (a *Auth) Check(ctx context.Context, token, verb, namespace, name string) error {
+	if token == "" {
+		return ErrUnauthenticated
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	res, ok, err := a.authenticator.AuthenticateRequest(req)
+	if err != nil || !ok {
+		if err != nil {
+			ctrl.Log.WithName("dashboard-auth").V(1).Info("authentication failed", "error", err.Error())
+		}
+		return ErrUnauthenticated
+	}
+	if a.authorizer == nil {
+		return nil
+	}
+
+	attributes := profileAttributes(verb, namespace, name)
+	attributes.User = res.User
+	decision, _, err := a.authorizer.Authorize(ctx, attributes)
+	if err != nil {
+		return fmt.Errorf("authorizing user %q: %w", res.User.GetName(), err)
+	}
+	if decision != authorizer.DecisionAllow {
+		return fmt.Errorf("%w: user %q cannot %s resourceoptimizerprofiles%s", ErrForbidden, res.User.GetName(),
+			verb, namespaceSuffix(namespace))
+	}
+	return nil
+}
+
+// Wrap returns a handler that only passes authorized requests to next. The API
+// documentation describes no profile data and is served to everyone.
+func (a *Auth) Wrap(next http.Handler) http.Handler {
+	log := ctrl.Log.WithName("dashboard-auth")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isDocumentation(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		res, ok, err := a.authenticator.AuthenticateRequest(r)
+		if err != nil || !ok {
+			if err != nil {
+				log.V(1).Info("authentication failed", "error", err.Error())
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer realm="k20s"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		if a.authorizer != nil {
+			attributes := requestAttributes(r)
+			attributes.User = res.User
+			decision, reason, err := a.authorizer.Authorize(r.Context(), attributes)
+			if err != nil {
+				log.Error(err, "authorization failed", "user", res.User.GetName())
+				http.Error(w, "Authorization failed", http.StatusInternalServerError)
+				return
+			}
+			if decision != authorizer.DecisionAllow {
+				log.V(1).Info("authorization denied", "user", res.User.GetName(), "verb", attributes.Verb,
+					"namespace", attributes.Namespace, "reason", reason)
+				http.Error(w, fmt.Sprintf("User %q cannot %s resourceoptimizerprofiles%s", res.User.GetName(),
+					attributes.Verb, namespaceSuffix(attributes.Namespace)), http.StatusForbidden)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestAttributes maps a request to the ResourceOptimizerProfile access it
+// needs: get for a single profile, through the API or its status page, list for
+// everything else. Requests without
+// a namespace need access in all namespaces.
+func requestAttributes(r *http.Request) authorizer.AttributesRecord {
+	rest, ok := strings.CutPrefix(r.URL.Path, APIPrefix+"profiles/")
+	if !ok {
+		rest, ok = strings.CutPrefix(r.URL.Path, StatusPath+"/")
+	}
+	if ok {
+		namespace, name, _ := strings.Cut(rest, "/")
+		return profileAttributes("get", namespace, name)
+	}
+	return profileAttributes("list", r.URL.Query().Get("namespace"), "")
+}
+
+// profileAttributes describes an access to ResourceOptimizerProfiles.
+func profileAttributes(verb, namespace, name string) authorizer.AttributesRecord {
+	return authorizer.AttributesRecord{
+		Verb:            verb,
+		Namespace:       namespace,
+		Name:            name,
+		APIGroup:        optimizerv1.GroupVersion.Group,
+		APIVersion:      optimizerv1.GroupVersion.Version,
+		Resource:        "resourceoptimizerprofiles",
+		ResourceRequest: true,
+	}
+}
+
+func isDocumentation(path string) bool {
+	return path == DocsPath || path == APIPrefix+"openapi.json" || path == APIPrefix+"openapi.yaml"
+}
+
+func namespaceSuffix(namespace string) string {
+	if namespace == "" {
+		return " in all namespaces"
+	}
+	return " in namespace " + namespace
+}
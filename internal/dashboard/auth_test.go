@@ -0,0 +1,121 @@
+package dashboard
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+)
+
+// fakeAuthenticator accepts the bearer token "valid".
+type fakeAuthenticator struct{}
+
+func (fakeAuthenticator) AuthenticateRequest(r *http.Request) (*authenticator.Response, bool, error) {
+	if r.Header.Get("Authorization") != "Bearer valid" {
+		return nil, false, nil
+	}
+	return &authenticator.Response{User: &user.DefaultInfo{Name: "alice"}}, true, nil
+}
+
+// fakeAuthorizer allows access to the team-a namespace only and records the
+// attributes it was asked about.
+type fakeAuthorizer struct {
+	last authorizer.Attributes
+}
+
+func (f *fakeAuthorizer) Authorize(_ context.Context, a authorizer.Attributes) (authorizer.Decision, string, error) {
+	f.last = a
+	if a.GetNamespace() == "team-a" {
+		return authorizer.DecisionAllow, "", nil
+	}
+	return authorizer.DecisionNoOpinion, "", nil
+}
+
+var _ = Describe("Auth", func() {
+	var (
+		authz   *fakeAuthorizer
+		handler http.Handler
+	)
+
+	BeforeEach(func() {
+		authz = &fakeAuthorizer{}
+		auth := &Auth{authenticator: fakeAuthenticator{}, authorizer: authz}
+		handler = auth.Wrap(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+	})
+
+	serve := func(path, token string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	It("should reject requests without a valid token", func() {
+		Expect(serve("/api/v1/profiles?namespace=team-a", "")).To(Equal(http.StatusUnauthorized))
+		Expect(serve("/api/v1/profiles?namespace=team-a", "forged")).To(Equal(http.StatusUnauthorized))
+		Expect(serve(StatusPath, "")).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should authorize access per namespace", func() {
+		Expect(serve("/api/v1/profiles/team-a/web", "valid")).To(Equal(http.StatusOK))
+		Expect(authz.last.GetVerb()).To(Equal("get"))
+		Expect(authz.last.GetName()).To(Equal("web"))
+		Expect(authz.last.GetResource()).To(Equal("resourceoptimizerprofiles"))
+		Expect(authz.last.GetUser().GetName()).To(Equal("alice"))
+
+		Expect(serve(StatusPath+"/team-a/web", "valid")).To(Equal(http.StatusOK))
+		Expect(authz.last.GetVerb()).To(Equal("get"))
+		Expect(authz.last.GetNamespace()).To(Equal("team-a"))
+
+		Expect(serve("/api/v1/actions?namespace=team-a", "valid")).To(Equal(http.StatusOK))
+		Expect(authz.last.GetVerb()).To(Equal("list"))
+
+		Expect(serve("/api/v1/profiles/team-b/web", "valid")).To(Equal(http.StatusForbidden))
+		// Listing everything requires access in all namespaces.
+		Expect(serve("/api/v1/profiles", "valid")).To(Equal(http.StatusForbidden))
+		Expect(serve(StatusPath, "valid")).To(Equal(http.StatusForbidden))
+	})
+
+	It("should check tokens for callers other than HTTP handlers", func() {
+		auth := &Auth{authenticator: fakeAuthenticator{}, authorizer: authz}
+		ctx := context.Background()
+		Expect(auth.Check(ctx, "", "list", "team-a", "")).To(MatchError(ErrUnauthenticated))
+		Expect(auth.Check(ctx, "forged", "list", "team-a", "")).To(MatchError(ErrUnauthenticated))
+		Expect(auth.Check(ctx, "valid", "get", "team-a", "web")).To(Succeed())
+		Expect(authz.last.GetName()).To(Equal("web"))
+		Expect(auth.Check(ctx, "valid", "list", "", "")).To(MatchError(ErrForbidden))
+
+		auth.authorizer = nil
+		Expect(auth.Check(ctx, "valid", "list", "", "")).To(Succeed())
+	})
+
+	It("should serve the API documentation to everyone", func() {
+		Expect(serve(DocsPath, "")).To(Equal(http.StatusOK))
+		Expect(serve("/api/v1/openapi.json", "")).To(Equal(http.StatusOK))
+	})
+
+	It("should accept a valid OIDC configuration", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		_, err := newOIDCAuthenticator(ctx, AuthOptions{
+			OIDCIssuerURL:     "https://issuer.example.com",
+			OIDCClientID:      "k20s",
+			OIDCUsernameClaim: "email",
+			OIDCGroupsClaim:   "groups",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = newOIDCAuthenticator(ctx, AuthOptions{OIDCIssuerURL: "https://issuer.example.com"})
+		Expect(err).To(MatchError(ContainSubstring("client ID")))
+	})
+})
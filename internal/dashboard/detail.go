@@ -0,0 +1,212 @@
+package dashboard
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"net/http"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/audit"
+)
+
+const profilePageTemplate = `
+<!DOCTYPE html>
+<html>
+<head>
+    <title>{{.Profile.Namespace}}/{{.Profile.Name}} - K20s Controller Status</title>
+    <style>
+        body { font-family: sans-serif; margin: 2em; }
+        table { border-collapse: collapse; width: 100%; }
+        th, td { border: 1px solid #ddd; padding: 8px; text-align: left; vertical-align: top; }
+        th { background-color: #f2f2f2; }
+        h1 { color: #333; }
+        .error { color: #c62828; }
+    </style>
+</head>
+<body>
+    <p><a href="{{.StatusPath}}">← All profiles</a></p>
+    <h1>{{.Profile.Namespace}}/{{.Profile.Name}}</h1>
+    <p>
+        Policy: {{.Profile.Spec.OptimizationPolicy}},
+        CPU thresholds: {{.Profile.Spec.CPUThresholds.Min}}%–{{.Profile.Spec.CPUThresholds.Max}}%,
+        observed CPU: {{if .Profile.Status.ObservedMetrics}}{{.Profile.Status.ObservedMetrics.cpu_usage}}%{{else}}N/A{{end}}
+    </p>
+    {{with .Degraded}}<p class="error">Degraded ({{.Reason}}): {{.Message}}</p>{{end}}
+    <h2>Targets</h2>
+    <table>
+        <thead>
+        <tr>
+            <th>Kind</th>
+            <th>Name</th>
+            <th>Replicas</th>
+            <th>Requests</th>
+            <th>Last Action</th>
+            <th>Error</th>
+        </tr>
+        </thead>
+        <tbody>
+        {{range .Targets}}
+        <tr>
+            <td>{{.Kind}}</td>
+            <td>{{.Name}}</td>
+            <td>{{.ReadyReplicas}}/{{.Replicas}} ready</td>
+            <td>{{range .Containers}}{{.Name}}: cpu {{or .CPU "-"}}, memory {{or .Memory "-"}}<br>{{end}}</td>
+            <td>{{with .LastAction}}{{.Action}} @ {{.Time.Format "2006-01-02 15:04:05"}}: {{.Field}} {{.Before}} → {{.After}}{{else}}None{{end}}</td>
+            <td class="error">{{with .LastAction}}{{.Error}}{{end}}</td>
+        </tr>
+        {{else}}
+        <tr><td colspan="6">No Deployment or StatefulSet matches the profile's selector.</td></tr>
+        {{end}}
+        </tbody>
+    </table>
+</body>
+</html>
+`
+
+var profilePage = template.Must(template.New("profile").Parse(profilePageTemplate))
+
+// targetView is a Deployment or StatefulSet matched by a profile.
+type targetView struct {
+	Kind          string
+	Name          string
+	Replicas      int32
+	ReadyReplicas int32
+	Containers    []containerRequests
+	// LastAction is the most recent action applied to the target, if any is
+	// still in the history.
+	LastAction *audit.Record
+}
+
+type containerRequests struct {
+	Name   string
+	CPU    string
+	Memory string
+}
+
+// serveProfile renders the detail page of a single profile.
+func (h *StatusPage) serveProfile(w http.ResponseWriter, r *http.Request, key types.NamespacedName) {
+	ctx := r.Context()
+	logger := ctrl.Log.WithName("status-page-handler")
+
+	var profile optimizerv1.ResourceOptimizerProfile
+	if err := h.Client.Get(ctx, key, &profile); err != nil {
+		if apierrors.IsNotFound(err) {
+			http.Error(w, "Profile "+key.String()+" not found", http.StatusNotFound)
+			return
+		}
+		logger.Error(err, "failed to get ResourceOptimizerProfile", "profile", key.String())
+		http.Error(w, "Failed to get resource", http.StatusInternalServerError)
+		return
+	}
+
+	targets, err := h.targets(ctx, &profile)
+	if err != nil {
+		logger.Error(err, "failed to list targets", "profile", key.String())
+		http.Error(w, "Failed to list targets", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Profile    *optimizerv1.ResourceOptimizerProfile
+		Targets    []targetView
+		Degraded   *metav1.Condition
+		StatusPath string
+	}{Profile: &profile, Targets: targets, StatusPath: StatusPath}
+	if meta.IsStatusConditionTrue(profile.Status.Conditions, optimizerv1.ConditionDegraded) {
+		data.Degraded = meta.FindStatusCondition(profile.Status.Conditions, optimizerv1.ConditionDegraded)
+	}
+
+	var buf bytes.Buffer
+	if err := profilePage.Execute(&buf, data); err != nil {
+		logger.Error(err, "failed to execute HTML template")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// targets returns the workloads matched by the profile's selector, like the
+// controller matches them, with the last action applied to each.
+func (h *StatusPage) targets(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile) ([]targetView, error) {
+	labelSelector := labels.Set(profile.Spec.Selector.MatchLabels).AsSelector()
+	listOpts := &client.ListOptions{LabelSelector: labelSelector, Namespace: profile.Namespace}
+
+	var deployments appsv1.DeploymentList
+	if err := h.Client.List(ctx, &deployments, listOpts); err != nil {
+		return nil, err
+	}
+	var statefulSets appsv1.StatefulSetList
+	if err := h.Client.List(ctx, &statefulSets, listOpts); err != nil {
+		return nil, err
+	}
+
+	var targets []targetView
+	for _, deployment := range deployments.Items {
+		targets = append(targets, targetView{
+			Kind:          "Deployment",
+			Name:          deployment.Name,
+			Replicas:      replicas(deployment.Spec.Replicas),
+			ReadyReplicas: deployment.Status.ReadyReplicas,
+			Containers:    requests(deployment.Spec.Template.Spec.Containers),
+		})
+	}
+	for _, ss := range statefulSets.Items {
+		targets = append(targets, targetView{
+			Kind:          "StatefulSet",
+			Name:          ss.Name,
+			Replicas:      replicas(ss.Spec.Replicas),
+			ReadyReplicas: ss.Status.ReadyReplicas,
+			Containers:    requests(ss.Spec.Template.Spec.Containers),
+		})
+	}
+
+	if h.Actions != nil {
+		records := h.Actions.Records()
+		for i := range targets {
+			for _, record := range records {
+				if record.ProfileNamespace == profile.Namespace && record.ProfileName == profile.Name &&
+					record.TargetKind == targets[i].Kind && record.TargetName == targets[i].Name {
+					targets[i].LastAction = &record
+					break
+				}
+			}
+		}
+	}
+	return targets, nil
+}
+
+// replicas returns the desired replicas, which default to 1 when unset.
+func replicas(n *int32) int32 {
+	if n == nil {
+		return 1
+	}
+	return *n
+}
+
+func requests(containers []corev1.Container) []containerRequests {
+	out := make([]containerRequests, 0, len(containers))
+	for _, c := range containers {
+		view := containerRequests{Name: c.Name}
+		if cpu, ok := c.Resources.Requests[corev1.ResourceCPU]; ok {
+			view.CPU = cpu.String()
+		}
+		if memory, ok := c.Resources.Requests[corev1.ResourceMemory]; ok {
+			view.Memory = memory.String()
+		}
+		out = append(out, view)
+	}
+	return out
+}
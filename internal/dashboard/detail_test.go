@@ -0,0 +1,97 @@
+package dashboard
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/audit"
+)
+
+var _ = Describe("Profile detail page", func() {
+	var page *StatusPage
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(optimizerv1.AddToScheme(scheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&optimizerv1.ResourceOptimizerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+				Spec: optimizerv1.ResourceOptimizerProfileSpec{
+					Selector:           metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+					OptimizationPolicy: "Resize",
+				},
+				Status: optimizerv1.ResourceOptimizerProfileStatus{
+					Conditions: []metav1.Condition{{
+						Type: optimizerv1.ConditionDegraded, Status: metav1.ConditionTrue,
+						Reason: "QueryFailed", Message: "prometheus unreachable",
+					}},
+				},
+			},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", Labels: map[string]string{"app": "web"}},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: ptr.To[int32](3),
+					Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+						Name: "app",
+						Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("250m"),
+						}},
+					}}}},
+				},
+				Status: appsv1.DeploymentStatus{ReadyReplicas: 2},
+			},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "team-a", Labels: map[string]string{"app": "other"}},
+			},
+		).Build()
+
+		actions := audit.NewHistory(5)
+		Expect(actions.Record(context.Background(), audit.Record{
+			ProfileNamespace: "team-a", ProfileName: "web", Action: "ResizeUp",
+			TargetKind: "Deployment", TargetName: "web", Field: "spec.template.spec.containers[app].resources.requests.cpu",
+			Before: "200m", After: "250m", Result: audit.ResultFailed, Error: "conflict",
+		})).To(Succeed())
+		page = &StatusPage{Client: c, Actions: actions}
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		page.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	It("should show the matched targets with their last action", func() {
+		rec := serve(StatusPath + "/team-a/web")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		body := rec.Body.String()
+		Expect(body).To(ContainSubstring("<td>web</td>"))
+		Expect(body).NotTo(ContainSubstring("<td>other</td>"))
+		Expect(body).To(ContainSubstring("2/3 ready"))
+		Expect(body).To(ContainSubstring("app: cpu 250m, memory -"))
+		Expect(body).To(ContainSubstring("ResizeUp @"))
+		Expect(body).To(ContainSubstring("conflict"))
+		Expect(body).To(ContainSubstring("Degraded (QueryFailed): prometheus unreachable"))
+	})
+
+	It("should link the overview to the detail page", func() {
+		Expect(serve(StatusPath).Body.String()).To(ContainSubstring(`<a href="/status/team-a/web">web</a>`))
+	})
+
+	It("should return 404 for unknown profiles", func() {
+		Expect(serve(StatusPath + "/team-a/missing").Code).To(Equal(http.StatusNotFound))
+		Expect(serve(StatusPath + "/team-a").Code).To(Equal(http.StatusNotFound))
+	})
+})
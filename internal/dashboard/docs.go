@@ -0,0 +1,65 @@
+package dashboard
+
+import (
+	_ "embed"
+	"html/template"
+	"net/http"
+
+	"sigs.k8s.io/yaml"
+)
+
+// DocsPath is where the Swagger UI for the API is served.
+const DocsPath = "/api/docs"
+
+// swaggerUIVersion is the swagger-ui-dist release loaded by the docs page.
+const swaggerUIVersion = "5.17.14"
+
+// openAPISpec documents the API served by APIHandler. Keep it in sync with the
+// routes registered in NewAPIHandler.
+//
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// openAPISpecJSON is openAPISpec converted to JSON.
+var openAPISpecJSON = func() []byte {
+	out, err := yaml.YAMLToJSON(openAPISpec)
+	if err != nil {
+		panic("invalid embedded OpenAPI document: " + err.Error())
+	}
+	return out
+}()
+
+var docsPage = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <title>K20s API</title>
+    <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui.css">
+</head>
+<body>
+    <div id="swagger-ui"></div>
+    <script src="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui-bundle.js" crossorigin></script>
+    <script>
+        window.onload = () => {
+            window.ui = SwaggerUIBundle({ url: {{.SpecURL}}, dom_id: "#swagger-ui" });
+        };
+    </script>
+</body>
+</html>
+`))
+
+func (h *APIHandler) openAPIYAML(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	_, _ = w.Write(openAPISpec)
+}
+
+func (h *APIHandler) openAPIJSON(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(openAPISpecJSON)
+}
+
+// docs serves a Swagger UI for the API. The UI assets are loaded from the
+// swagger-ui-dist package on unpkg, so the browser needs internet access.
+func (h *APIHandler) docs(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+	_ = docsPage.Execute(w, struct{ Version, SpecURL string }{swaggerUIVersion, APIPrefix + "openapi.json"})
+}
@@ -0,0 +1,176 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	toolscache "k8s.io/client-go/tools/cache"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/audit"
+)
+
+// Types of live events.
+const (
+	// EventProfile carries a Profile after it was created or updated, e.g. by a
+	// reconcile writing its status.
+	EventProfile = "profile"
+	// EventProfileDeleted carries a ProfileRef of a deleted profile.
+	EventProfileDeleted = "profile-deleted"
+	// EventAction carries an audit.Record of a patch applied to a workload.
+	EventAction = "action"
+)
+
+// heartbeatInterval keeps idle event streams open through proxies.
+const heartbeatInterval = 30 * time.Second
+
+// subscriberBuffer is the number of events buffered per subscriber. Events for
+// subscribers that fall further behind are dropped.
+const subscriberBuffer = 64
+
+// Event is a change pushed to live subscribers.
+type Event struct {
+	Type string
+	// Namespace is the namespace of the profile the event belongs to.
+	Namespace string
+	Data      any
+}
+
+// ProfileRef identifies a profile.
+type ProfileRef struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// Hub fans profile changes and applied actions out to live subscribers such as
+// the status page. It implements audit.Recorder to receive actions.
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+}
+
+var _ audit.Recorder = &Hub{}
+
+// NewHub returns a Hub without subscribers.
+func NewHub() *Hub {
+	return &Hub{subscribers: map[chan Event]struct{}{}}
+}
+
+// Record implements audit.Recorder by publishing the record as an action event.
+func (h *Hub) Record(_ context.Context, record audit.Record) error {
+	h.Publish(Event{Type: EventAction, Namespace: record.ProfileNamespace, Data: record})
+	return nil
+}
+
+// Publish delivers event to every subscriber without blocking.
+func (h *Hub) Publish(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving every published event and a function
+// that cancels the subscription.
+func (h *Hub) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.subscribers, ch)
+		h.mu.Unlock()
+	}
+}
+
+// WatchProfiles publishes an event whenever a profile in the informer cache is
+// added, updated or deleted.
+func (h *Hub) WatchProfiles(ctx context.Context, informers cache.Informers) error {
+	informer, err := informers.GetInformer(ctx, &optimizerv1.ResourceOptimizerProfile{})
+	if err != nil {
+		return err
+	}
+	publish := func(obj any) {
+		if profile, ok := obj.(*optimizerv1.ResourceOptimizerProfile); ok {
+			h.Publish(Event{Type: EventProfile, Namespace: profile.Namespace, Data: profileView(profile)})
+		}
+	}
+	_, err = informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    publish,
+		UpdateFunc: func(_, obj any) { publish(obj) },
+		DeleteFunc: func(obj any) {
+			if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if profile, ok := obj.(*optimizerv1.ResourceOptimizerProfile); ok {
+				h.Publish(Event{
+					Type:      EventProfileDeleted,
+					Namespace: profile.Namespace,
+					Data:      ProfileRef{Namespace: profile.Namespace, Name: profile.Name},
+				})
+			}
+		},
+	})
+	return err
+}
+
+// streamEvents serves live events as server-sent events, optionally only those
+// of ?namespace=.
+func (h *APIHandler) streamEvents(w http.ResponseWriter, r *http.Request) {
+	if h.Events == nil {
+		writeError(w, http.StatusNotFound, "live events are not enabled")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	events, cancel := h.Events.Subscribe()
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	namespace := r.URL.Query().Get("namespace")
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case event := <-events:
+			if namespace != "" && event.Namespace != namespace {
+				continue
+			}
+			data, err := json.Marshal(event.Data)
+			if err != nil {
+				ctrl.Log.WithName("api").Error(err, "failed to encode live event", "type", event.Type)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,99 @@
+package dashboard
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/audit"
+)
+
+var _ = Describe("Live events", func() {
+	var (
+		hub    *Hub
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(optimizerv1.AddToScheme(scheme)).To(Succeed())
+		hub = NewHub()
+		server = httptest.NewServer(NewAPIHandler(fake.NewClientBuilder().WithScheme(scheme).Build(), nil, hub))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	// subscribe opens an event stream and returns a reader positioned after the
+	// response headers, once the hub has registered the subscriber.
+	subscribe := func(ctx context.Context, query string) *bufio.Reader {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v1/events"+query, nil)
+		Expect(err).NotTo(HaveOccurred())
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() { _ = resp.Body.Close() })
+		Expect(resp.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+		Eventually(func() int {
+			hub.mu.Lock()
+			defer hub.mu.Unlock()
+			return len(hub.subscribers)
+		}).Should(BeNumerically(">", 0))
+		return bufio.NewReader(resp.Body)
+	}
+
+	readEvent := func(r *bufio.Reader) (string, string) {
+		var event, data string
+		for {
+			line, err := r.ReadString('\n')
+			Expect(err).NotTo(HaveOccurred())
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case line == "":
+				return event, data
+			case strings.HasPrefix(line, "event: "):
+				event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}
+
+	It("should stream actions recorded by the hub", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream := subscribe(ctx, "")
+
+		Expect(hub.Record(ctx, audit.Record{ProfileNamespace: "team-a", ProfileName: "web", Action: "ScaleUp"})).To(Succeed())
+		event, data := readEvent(stream)
+		Expect(event).To(Equal(EventAction))
+		Expect(data).To(ContainSubstring(`"action":"ScaleUp"`))
+	})
+
+	It("should only stream events of the requested namespace", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream := subscribe(ctx, "?namespace=team-b")
+
+		hub.Publish(Event{Type: EventProfile, Namespace: "team-a", Data: ProfileRef{Namespace: "team-a", Name: "web"}})
+		hub.Publish(Event{Type: EventProfileDeleted, Namespace: "team-b", Data: ProfileRef{Namespace: "team-b", Name: "api"}})
+		event, data := readEvent(stream)
+		Expect(event).To(Equal(EventProfileDeleted))
+		Expect(data).To(MatchJSON(`{"namespace":"team-b","name":"api"}`))
+	})
+
+	It("should stop delivering after unsubscribing", func() {
+		events, cancel := hub.Subscribe()
+		cancel()
+		hub.Publish(Event{Type: EventAction})
+		Consistently(events).ShouldNot(Receive())
+	})
+})
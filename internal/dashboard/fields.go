@@ -0,0 +1,61 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// parseFields splits the fields query parameter, a comma-separated list of
+// dotted JSON paths such as name,status.observedMetrics. It returns nil when
+// every field is requested.
+func parseFields(raw string) []string {
+	var fields []string
+	for _, field := range strings.Split(raw, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// selectFields returns the JSON object of v with only the given dotted paths.
+// Paths that do not exist in v are left out.
+func selectFields(v any, fields []string) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]any
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	selected := map[string]any{}
+	for _, field := range fields {
+		copyPath(selected, object, strings.Split(field, "."))
+	}
+	return selected, nil
+}
+
+// copyPath copies the value at path in src to the same path in dst.
+func copyPath(dst, src map[string]any, path []string) {
+	value, ok := src[path[0]]
+	if !ok {
+		return
+	}
+	if len(path) == 1 {
+		dst[path[0]] = value
+		return
+	}
+	child, ok := value.(map[string]any)
+	if !ok {
+		return
+	}
+	next, ok := dst[path[0]].(map[string]any)
+	if !ok {
+		next = map[string]any{}
+	}
+	copyPath(next, child, path[1:])
+	if len(next) > 0 {
+		dst[path[0]] = next
+	}
+}
@@ -0,0 +1,159 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// GrafanaPath is where a Grafana dashboard for the controller's metrics is
+// served.
+const GrafanaPath = "/dashboards/grafana"
+
+// GrafanaHandler serves a Grafana dashboard of the controller's metrics, ready
+// to import. Panels query the Prometheus data source picked with the
+// dashboard's datasource variable, which ?datasource= presets to a data source
+// uid or name. Like VersionHandler it describes no profile data and needs no
+// authentication.
+type GrafanaHandler struct{}
+
+func (GrafanaHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(grafanaDashboard(r.URL.Query().Get("datasource")))
+}
+
+type grafanaPanel struct {
+	ID          int                `json:"id"`
+	Type        string             `json:"type"`
+	Title       string             `json:"title"`
+	Description string             `json:"description,omitempty"`
+	Datasource  grafanaDatasource  `json:"datasource"`
+	GridPos     grafanaGridPos     `json:"gridPos"`
+	FieldConfig grafanaFieldConfig `json:"fieldConfig"`
+	Targets     []grafanaTarget    `json:"targets"`
+}
+
+type grafanaDatasource struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+type grafanaGridPos struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type grafanaFieldConfig struct {
+	Defaults struct {
+		Unit string `json:"unit,omitempty"`
+	} `json:"defaults"`
+	Overrides []any `json:"overrides"`
+}
+
+type grafanaTarget struct {
+	RefID        string            `json:"refId"`
+	Datasource   grafanaDatasource `json:"datasource"`
+	Expr         string            `json:"expr"`
+	LegendFormat string            `json:"legendFormat"`
+}
+
+type grafanaVariable struct {
+	Name       string             `json:"name"`
+	Label      string             `json:"label"`
+	Type       string             `json:"type"`
+	Query      any                `json:"query"`
+	Datasource *grafanaDatasource `json:"datasource,omitempty"`
+	Current    *grafanaOption     `json:"current,omitempty"`
+	Refresh    int                `json:"refresh,omitempty"`
+	Multi      bool               `json:"multi,omitempty"`
+	IncludeAll bool               `json:"includeAll,omitempty"`
+}
+
+type grafanaOption struct {
+	Text  any `json:"text"`
+	Value any `json:"value"`
+}
+
+// profileFilter selects the profiles picked with the dashboard's variables.
+const profileFilter = `namespace=~"$namespace", profile=~"$profile"`
+
+// grafanaDashboard returns the dashboard with its datasource variable preset
+// to datasource, if set.
+func grafanaDashboard(datasource string) map[string]any {
+	ds := grafanaDatasource{Type: "prometheus", UID: "${datasource}"}
+	datasourceVariable := grafanaVariable{Name: "datasource", Label: "Data source", Type: "datasource", Query: "prometheus"}
+	if datasource != "" {
+		datasourceVariable.Current = &grafanaOption{Text: datasource, Value: datasource}
+	}
+	labelVariable := func(name, label, query string) grafanaVariable {
+		return grafanaVariable{
+			Name: name, Label: label, Type: "query", Datasource: &ds, Refresh: 2, Multi: true, IncludeAll: true,
+			Query:   map[string]string{"query": query, "refId": name},
+			Current: &grafanaOption{Text: []string{"All"}, Value: []string{"$__all"}},
+		}
+	}
+
+	var panels []grafanaPanel
+	panel := func(kind, title, description, unit string, pos grafanaGridPos, exprs ...[2]string) {
+		p := grafanaPanel{
+			ID: len(panels) + 1, Type: kind, Title: title, Description: description, Datasource: ds, GridPos: pos,
+			FieldConfig: grafanaFieldConfig{Overrides: []any{}},
+		}
+		p.FieldConfig.Defaults.Unit = unit
+		for i, expr := range exprs {
+			p.Targets = append(p.Targets, grafanaTarget{
+				RefID: string(rune('A' + i)), Datasource: ds, Expr: expr[0], LegendFormat: expr[1],
+			})
+		}
+		panels = append(panels, p)
+	}
+	actionRate := func(action string) [2]string {
+		return [2]string{
+			`sum by (namespace, profile) (rate(k20s_` + action + `_actions_total{` + profileFilter + `}[$__rate_interval]))`,
+			action + ` {{namespace}}/{{profile}}`,
+		}
+	}
+
+	panel("stat", "CPU savings", "CPU requested by all replicas of the matched targets beyond the recommendations.", "none",
+		grafanaGridPos{X: 0, Y: 0, W: 6, H: 8},
+		[2]string{`sum(k20s_cpu_savings_millicores{` + profileFilter + `}) / 1000`, "cores"})
+	panel("timeseries", "Observed CPU utilization", "CPU utilization, in percent of requests, last observed for each profile.",
+		"percent", grafanaGridPos{X: 6, Y: 0, W: 18, H: 8},
+		[2]string{`k20s_observed_cpu_utilization{` + profileFilter + `}`, "{{namespace}}/{{profile}}"})
+	panel("timeseries", "Actions applied", "Rate of actions applied to individual targets.", "ops",
+		grafanaGridPos{X: 0, Y: 8, W: 12, H: 8},
+		actionRate("scale_up"), actionRate("scale_down"), actionRate("resize_up"), actionRate("resize_down"))
+	panel("timeseries", "Requested vs recommended CPU", "CPU request of each matched target and the one the controller recommends.",
+		"none", grafanaGridPos{X: 12, Y: 8, W: 12, H: 8},
+		[2]string{`k20s_requested_cpu_millicores{` + profileFilter + `}`, "requested {{target_kind}}/{{target}}"},
+		[2]string{`k20s_recommended_cpu_millicores{` + profileFilter + `}`, "recommended {{target_kind}}/{{target}}"})
+	panel("timeseries", "CPU savings by target", "Millicores requested by all replicas of a target beyond the recommendation. Negative when under-provisioned.",
+		"none", grafanaGridPos{X: 0, Y: 16, W: 12, H: 8},
+		[2]string{`k20s_cpu_savings_millicores{` + profileFilter + `}`, "{{namespace}}/{{target_kind}}/{{target}}"})
+	panel("timeseries", "Skipped actions", "Rate of planned actions held back, by reason.", "ops",
+		grafanaGridPos{X: 12, Y: 16, W: 12, H: 8},
+		[2]string{`sum by (action, reason) (rate(k20s_skipped_actions_total{` + profileFilter + `}[$__rate_interval]))`, "{{action}} {{reason}}"})
+	panel("timeseries", "Errors", "Rate of actions that failed to apply and of failed Prometheus queries.", "ops",
+		grafanaGridPos{X: 0, Y: 24, W: 24, H: 8},
+		[2]string{`sum by (namespace, profile, action) (rate(k20s_action_errors_total{` + profileFilter + `}[$__rate_interval]))`, "{{action}} {{namespace}}/{{profile}}"},
+		[2]string{`sum by (namespace, profile) (rate(k20s_prometheus_query_errors_total{` + profileFilter + `}[$__rate_interval]))`, "query {{namespace}}/{{profile}}"})
+
+	return map[string]any{
+		"uid":           "k20s",
+		"title":         "K20s",
+		"tags":          []string{"k20s"},
+		"editable":      true,
+		"schemaVersion": 39,
+		"time":          map[string]string{"from": "now-6h", "to": "now"},
+		"refresh":       "1m",
+		"templating": map[string]any{"list": []grafanaVariable{
+			datasourceVariable,
+			labelVariable("namespace", "Namespace", "label_values(k20s_observed_cpu_utilization, namespace)"),
+			labelVariable("profile", "Profile", `label_values(k20s_observed_cpu_utilization{namespace=~"$namespace"}, profile)`),
+		}},
+		"panels": panels,
+	}
+}
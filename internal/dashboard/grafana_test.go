@@ -0,0 +1,70 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GrafanaHandler", func() {
+	type dashboard struct {
+		Templating struct {
+			List []struct {
+				Name    string `json:"name"`
+				Current *struct {
+					Value any `json:"value"`
+				} `json:"current"`
+			} `json:"list"`
+		} `json:"templating"`
+		Panels []struct {
+			Title      string `json:"title"`
+			Datasource struct {
+				UID string `json:"uid"`
+			} `json:"datasource"`
+			Targets []struct {
+				Expr string `json:"expr"`
+			} `json:"targets"`
+		} `json:"panels"`
+	}
+
+	serve := func(path string) dashboard {
+		rec := httptest.NewRecorder()
+		GrafanaHandler{}.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Content-Type")).To(Equal("application/json"))
+		var d dashboard
+		Expect(json.Unmarshal(rec.Body.Bytes(), &d)).To(Succeed())
+		return d
+	}
+
+	It("should query the controller's metrics through the datasource variable", func() {
+		d := serve(GrafanaPath)
+		Expect(d.Templating.List).NotTo(BeEmpty())
+		Expect(d.Templating.List[0].Name).To(Equal("datasource"))
+		Expect(d.Templating.List[0].Current).To(BeNil())
+
+		var exprs []string
+		for _, panel := range d.Panels {
+			Expect(panel.Datasource.UID).To(Equal("${datasource}"), panel.Title)
+			for _, target := range panel.Targets {
+				exprs = append(exprs, target.Expr)
+			}
+		}
+		Expect(exprs).To(ContainElements(
+			ContainSubstring("k20s_scale_up_actions_total"),
+			ContainSubstring("k20s_observed_cpu_utilization"),
+			ContainSubstring("k20s_recommended_cpu_millicores"),
+			ContainSubstring("k20s_requested_cpu_millicores"),
+			ContainSubstring("k20s_cpu_savings_millicores"),
+		))
+	})
+
+	It("should preset the datasource variable", func() {
+		d := serve(GrafanaPath + "?datasource=prom-eu")
+		Expect(d.Templating.List[0].Current).NotTo(BeNil())
+		Expect(d.Templating.List[0].Current.Value).To(Equal("prom-eu"))
+	})
+})
@@ -0,0 +1,78 @@
+package dashboard
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DefaultCPUHistorySize is the default number of CPU samples kept per profile.
+const DefaultCPUHistorySize = 60
+
+// EventCPU carries a CPUSample observed for a profile.
+const EventCPU = "cpu"
+
+// Sample is the CPU utilization observed at a point in time.
+type Sample struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// CPUSample is a Sample of a profile, as published on the event stream.
+type CPUSample struct {
+	ProfileRef
+	Sample
+}
+
+// CPUHistory keeps the most recent CPU utilization samples of every profile in
+// memory for the status page's sparklines. Only the leader reconciles, so other
+// replicas have no history.
+type CPUHistory struct {
+	size int
+	// hub, when set, receives every sample as an EventCPU.
+	hub *Hub
+
+	mu      sync.Mutex
+	samples map[types.NamespacedName][]Sample
+}
+
+// NewCPUHistory returns a history keeping size samples per profile and
+// publishing new samples to hub, which may be nil.
+func NewCPUHistory(size int, hub *Hub) *CPUHistory {
+	return &CPUHistory{size: max(size, 1), hub: hub, samples: map[types.NamespacedName][]Sample{}}
+}
+
+// ObserveCPU implements controller.CPUObserver.
+func (h *CPUHistory) ObserveCPU(profile types.NamespacedName, at time.Time, value float64) {
+	sample := Sample{Time: at, Value: value}
+	h.mu.Lock()
+	samples := append(h.samples[profile], sample)
+	if len(samples) > h.size {
+		samples = samples[len(samples)-h.size:]
+	}
+	h.samples[profile] = samples
+	h.mu.Unlock()
+
+	if h.hub != nil {
+		h.hub.Publish(Event{
+			Type:      EventCPU,
+			Namespace: profile.Namespace,
+			Data:      CPUSample{ProfileRef: ProfileRef{Namespace: profile.Namespace, Name: profile.Name}, Sample: sample},
+		})
+	}
+}
+
+// Forget implements controller.CPUObserver.
+func (h *CPUHistory) Forget(profile types.NamespacedName) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.samples, profile)
+}
+
+// Samples returns the samples of a profile, oldest first.
+func (h *CPUHistory) Samples(profile types.NamespacedName) []Sample {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]Sample(nil), h.samples[profile]...)
+}
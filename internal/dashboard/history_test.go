@@ -0,0 +1,51 @@
+package dashboard
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("CPUHistory", func() {
+	profile := types.NamespacedName{Namespace: "default", Name: "web"}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	It("keeps the most recent samples of each profile", func() {
+		history := NewCPUHistory(3, nil)
+		for i := range 5 {
+			history.ObserveCPU(profile, start.Add(time.Duration(i)*time.Minute), float64(i*10))
+		}
+		history.ObserveCPU(types.NamespacedName{Namespace: "default", Name: "api"}, start, 99)
+
+		samples := history.Samples(profile)
+		Expect(samples).To(HaveLen(3))
+		Expect(samples[0]).To(Equal(Sample{Time: start.Add(2 * time.Minute), Value: 20}))
+		Expect(samples[2]).To(Equal(Sample{Time: start.Add(4 * time.Minute), Value: 40}))
+	})
+
+	It("forgets deleted profiles", func() {
+		history := NewCPUHistory(3, nil)
+		history.ObserveCPU(profile, start, 10)
+		history.Forget(profile)
+		Expect(history.Samples(profile)).To(BeEmpty())
+	})
+
+	It("publishes samples to the hub", func() {
+		hub := NewHub()
+		events, cancel := hub.Subscribe()
+		defer cancel()
+
+		NewCPUHistory(3, hub).ObserveCPU(profile, start, 42.5)
+
+		var event Event
+		Eventually(events).Should(Receive(&event))
+		Expect(event.Type).To(Equal(EventCPU))
+		Expect(event.Namespace).To(Equal("default"))
+		Expect(event.Data).To(Equal(CPUSample{
+			ProfileRef: ProfileRef{Namespace: "default", Name: "web"},
+			Sample:     Sample{Time: start, Value: 42.5},
+		}))
+	})
+})
@@ -0,0 +1,80 @@
+package dashboard
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// Server serves the status pages and the API on their own address, independent
+// of the metrics server's TLS and authentication settings.
+type Server struct {
+	// Addr is the address to listen on, e.g. ":8082".
+	Addr string
+	// TLSConfig enables TLS. The server is plaintext when nil.
+	TLSConfig *tls.Config
+	// Handlers maps the paths to serve, as passed to http.ServeMux.Handle, to
+	// their handlers.
+	Handlers map[string]http.Handler
+}
+
+var _ manager.LeaderElectionRunnable = &Server{}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Start listens on Addr and serves until ctx is cancelled.
+func (s *Server) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	log.FromContext(ctx).Info("serving dashboard", "addr", listener.Addr().String(), "tls", s.TLSConfig != nil)
+	return s.Serve(ctx, listener)
+}
+
+// Serve serves on listener until ctx is cancelled and then shuts down
+// gracefully.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	mux := http.NewServeMux()
+	for path, handler := range s.Handlers {
+		mux.Handle(path, handler)
+	}
+	srv := &http.Server{
+		Handler:           mux,
+		TLSConfig:         s.TLSConfig,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if s.TLSConfig != nil {
+		listener = tls.NewListener(listener, s.TLSConfig)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Serve(listener)
+	}()
+	select {
+	case <-ctx.Done():
+		// The event stream only ends when its clients disconnect, so do not wait
+		// for it forever.
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			return srv.Close()
+		}
+		return nil
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	}
+}
@@ -0,0 +1,46 @@
+package dashboard
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Server", func() {
+	It("should serve the handlers until the context is cancelled", func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+
+		server := &Server{Handlers: map[string]http.Handler{
+			StatusPath: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = io.WriteString(w, "ok")
+			}),
+		}}
+		Expect(server.NeedLeaderElection()).To(BeFalse())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			done <- server.Serve(ctx, listener)
+		}()
+
+		resp, err := http.Get("http://" + listener.Addr().String() + StatusPath)
+		Expect(err).NotTo(HaveOccurred())
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal("ok"))
+
+		resp, err = http.Get("http://" + listener.Addr().String() + "/metrics")
+		Expect(err).NotTo(HaveOccurred())
+		_ = resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+	})
+})
@@ -0,0 +1,175 @@
+package dashboard
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/audit"
+	"github.com/OpScaleHub/K20s/internal/controller"
+)
+
+const (
+	// DefaultServiceKey is the label, or annotation, naming the service a
+	// workload belongs to. It is the one the Backstage Kubernetes plugin
+	// matches workloads with.
+	DefaultServiceKey = "backstage.io/kubernetes-id"
+	// DefaultServiceActions is the number of recent actions returned for a
+	// service.
+	DefaultServiceActions = 20
+)
+
+// Service is the response of GET /api/v1/services/{service}.
+type Service struct {
+	Service   string            `json:"service"`
+	Workloads []ServiceWorkload `json:"workloads"`
+	// Actions are the recent actions on the service's workloads, newest first.
+	Actions []audit.Record `json:"actions"`
+}
+
+// ServiceWorkload is a workload of a service and what the controller
+// recommends for it.
+type ServiceWorkload struct {
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Replicas  int32  `json:"replicas"`
+	// Profile is the profile selecting the workload. The remaining fields are
+	// only set when there is one.
+	Profile string `json:"profile,omitempty"`
+	Policy  string `json:"policy,omitempty"`
+	// CPUUtilization is the utilization the profile last observed.
+	CPUUtilization *float64 `json:"cpuUtilization,omitempty"`
+	// Action is the action the profile's thresholds call for at that
+	// utilization.
+	Action                string                  `json:"action,omitempty"`
+	CPURequest            string                  `json:"cpuRequest,omitempty"`
+	RecommendedCPURequest string                  `json:"recommendedCPURequest,omitempty"`
+	Recommendations       []string                `json:"recommendations,omitempty"`
+	Confidence            *optimizerv1.Confidence `json:"confidence,omitempty"`
+}
+
+// serviceKey returns the label or annotation naming services.
+func (h *APIHandler) serviceKey() string {
+	return cmp.Or(h.ServiceKey, DefaultServiceKey)
+}
+
+func (h *APIHandler) getService(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	service := r.PathValue("service")
+	query := r.URL.Query()
+	limit := DefaultServiceActions
+	if raw := query.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "limit must be a non-negative integer")
+			return
+		}
+		limit = n
+	}
+	var opts []client.ListOption
+	if ns := query.Get("namespace"); ns != "" {
+		opts = append(opts, client.InNamespace(ns))
+	}
+
+	var deployments appsv1.DeploymentList
+	var statefulSets appsv1.StatefulSetList
+	var profiles optimizerv1.ResourceOptimizerProfileList
+	for _, list := range []client.ObjectList{&deployments, &statefulSets, &profiles} {
+		if err := h.Client.List(ctx, list, opts...); err != nil {
+			ctrl.Log.WithName("api").Error(err, "failed to list the workloads of a service", "service", service)
+			writeError(w, http.StatusInternalServerError, "failed to list workloads")
+			return
+		}
+	}
+
+	result := Service{Service: service, Workloads: []ServiceWorkload{}, Actions: []audit.Record{}}
+	add := func(kind string, meta metav1.ObjectMeta, replicas *int32) {
+		if meta.Labels[h.serviceKey()] != service && meta.Annotations[h.serviceKey()] != service {
+			return
+		}
+		workload := ServiceWorkload{Namespace: meta.Namespace, Kind: kind, Name: meta.Name, Replicas: 1}
+		if replicas != nil {
+			workload.Replicas = *replicas
+		}
+		h.recommendForWorkload(r, &workload, meta.Labels, profiles.Items)
+		result.Workloads = append(result.Workloads, workload)
+	}
+	for _, deployment := range deployments.Items {
+		add("Deployment", deployment.ObjectMeta, deployment.Spec.Replicas)
+	}
+	for _, ss := range statefulSets.Items {
+		add("StatefulSet", ss.ObjectMeta, ss.Spec.Replicas)
+	}
+	if len(result.Workloads) == 0 {
+		writeError(w, http.StatusNotFound, "no workloads with "+h.serviceKey()+"="+service)
+		return
+	}
+	slices.SortFunc(result.Workloads, func(a, b ServiceWorkload) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Name, b.Name))
+	})
+
+	if h.Actions != nil {
+		for _, record := range h.Actions.Records() {
+			if limit > 0 && len(result.Actions) == limit {
+				break
+			}
+			if slices.ContainsFunc(result.Workloads, func(workload ServiceWorkload) bool {
+				return record.ProfileNamespace == workload.Namespace && record.TargetKind == workload.Kind && record.TargetName == workload.Name
+			}) {
+				result.Actions = append(result.Actions, record)
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// recommendForWorkload fills in what the first profile, by name, selecting the
+// workload recommends for it.
+func (h *APIHandler) recommendForWorkload(r *http.Request, workload *ServiceWorkload, workloadLabels map[string]string, profiles []optimizerv1.ResourceOptimizerProfile) {
+	var profile *optimizerv1.ResourceOptimizerProfile
+	for i := range profiles {
+		candidate := &profiles[i]
+		if candidate.Namespace != workload.Namespace ||
+			!labels.SelectorFromSet(candidate.Spec.Selector.MatchLabels).Matches(labels.Set(workloadLabels)) {
+			continue
+		}
+		if profile == nil || candidate.Name < profile.Name {
+			profile = candidate
+		}
+	}
+	if profile == nil {
+		return
+	}
+	workload.Profile = profile.Name
+	workload.Policy = profile.Spec.OptimizationPolicy
+	workload.Recommendations = profile.Status.Recommendations
+	workload.Confidence = profile.Status.Confidence
+
+	value, err := strconv.ParseFloat(profile.Status.ObservedMetrics["cpu_usage"], 64)
+	if err != nil {
+		// Not evaluated yet.
+		return
+	}
+	workload.CPUUtilization = &value
+	sim, err := controller.Simulate(r.Context(), h.Client, profile, value)
+	if err != nil {
+		ctrl.Log.WithName("api").Error(err, "failed to recommend target requests", "profile", client.ObjectKeyFromObject(profile))
+		return
+	}
+	workload.Action = sim.Action
+	for _, target := range sim.Targets {
+		if target.Kind == workload.Kind && target.Name == workload.Name {
+			workload.CPURequest = target.CurrentCPURequest.String()
+			workload.RecommendedCPURequest = target.CPURequest.String()
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/audit"
+)
+
+var _ = Describe("Services API", func() {
+	var (
+		handler *APIHandler
+		history *audit.History
+	)
+
+	podSpec := corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+		Name:      "app",
+		Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}},
+	}}}}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(optimizerv1.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&optimizerv1.ResourceOptimizerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "checkout", Namespace: "shop"},
+				Spec: optimizerv1.ResourceOptimizerProfileSpec{
+					Selector:           metav1.LabelSelector{MatchLabels: map[string]string{"app": "checkout"}},
+					CPUThresholds:      optimizerv1.ThresholdSpec{Min: 20, Max: 80},
+					OptimizationPolicy: "Recommend",
+				},
+				Status: optimizerv1.ResourceOptimizerProfileStatus{
+					ObservedMetrics: map[string]string{"cpu_usage": "10.00"},
+					Recommendations: []string{"CPU usage is 10.00%. Consider ScaleDown."},
+				},
+			},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "checkout-api", Namespace: "shop",
+					Labels: map[string]string{"app": "checkout", DefaultServiceKey: "checkout"}},
+				Spec: appsv1.DeploymentSpec{Replicas: ptr.To[int32](3), Template: podSpec},
+			},
+			// Identified by annotation and not selected by any profile.
+			&appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "checkout-db", Namespace: "shop",
+					Annotations: map[string]string{DefaultServiceKey: "checkout"}},
+				Spec: appsv1.StatefulSetSpec{Template: podSpec},
+			},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "cart", Namespace: "shop",
+					Labels: map[string]string{"app": "checkout", DefaultServiceKey: "cart"}},
+			},
+		).Build()
+
+		history = audit.NewHistory(10)
+		handler = NewAPIHandler(c, history, NewHub())
+	})
+
+	get := func(path string, into any) int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if into != nil {
+			Expect(json.Unmarshal(rec.Body.Bytes(), into)).To(Succeed())
+		}
+		return rec.Code
+	}
+
+	It("should return the recommendations and recent actions of a service", func() {
+		for i, target := range []string{"checkout-api", "cart", "checkout-api"} {
+			Expect(history.Record(context.Background(), audit.Record{Time: time.Unix(int64(i), 0), ProfileNamespace: "shop",
+				ProfileName: "checkout", Action: "ScaleDown", TargetKind: "Deployment", TargetName: target})).To(Succeed())
+		}
+
+		var service Service
+		Expect(get("/api/v1/services/checkout", &service)).To(Equal(http.StatusOK))
+		Expect(service.Workloads).To(HaveLen(2))
+		api := service.Workloads[0]
+		Expect(api.Name).To(Equal("checkout-api"))
+		Expect(api.Replicas).To(Equal(int32(3)))
+		Expect(api.Profile).To(Equal("checkout"))
+		Expect(api.Action).To(Equal("ScaleDown"))
+		Expect(api.CPURequest).To(Equal("1"))
+		Expect(api.RecommendedCPURequest).NotTo(BeEmpty())
+		Expect(api.Recommendations).To(ConsistOf("CPU usage is 10.00%. Consider ScaleDown."))
+		Expect(service.Workloads[1]).To(Equal(ServiceWorkload{Namespace: "shop", Kind: "StatefulSet", Name: "checkout-db", Replicas: 1}))
+		Expect(service.Actions).To(HaveLen(2))
+		Expect(service.Actions[0].Time.Unix()).To(Equal(int64(2)))
+
+		Expect(get("/api/v1/services/checkout?limit=1", &service)).To(Equal(http.StatusOK))
+		Expect(service.Actions).To(HaveLen(1))
+	})
+
+	It("should use the configured service key", func() {
+		handler.ServiceKey = "app"
+		var service Service
+		Expect(get("/api/v1/services/checkout", &service)).To(Equal(http.StatusOK))
+		Expect(service.Workloads).To(HaveLen(2))
+		Expect(service.Workloads[0].Name).To(Equal("cart"))
+	})
+
+	It("should answer unknown services with 404", func() {
+		var apiErr apiError
+		Expect(get("/api/v1/services/checkout?namespace=other", &apiErr)).To(Equal(http.StatusNotFound))
+		Expect(apiErr.Error).To(Equal("no workloads with backstage.io/kubernetes-id=checkout"))
+		Expect(get("/api/v1/services/checkout?limit=x", nil)).To(Equal(http.StatusBadRequest))
+	})
+})
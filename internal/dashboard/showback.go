@@ -0,0 +1,113 @@
+package dashboard
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/controller"
+)
+
+// ShowbackList is the response of GET /api/v1/showback.
+type ShowbackList struct {
+	Items []NamespaceShowback `json:"items"`
+}
+
+// NamespaceShowback sums up, for the targets of the evaluated profiles of a
+// namespace, the CPU they request across all replicas, the CPU they use at
+// the utilization their profile last observed, and the CPU they would request
+// with the recommended requests.
+type NamespaceShowback struct {
+	Namespace string `json:"namespace"`
+	Profiles  int    `json:"profiles"`
+	// EvaluatedProfiles is the number of profiles that observed a utilization
+	// and so count towards the totals.
+	EvaluatedProfiles int   `json:"evaluatedProfiles"`
+	Workloads         int   `json:"workloads"`
+	Replicas          int32 `json:"replicas"`
+	// RequestedCPUMillicores, UsedCPUMillicores and RecommendedCPUMillicores
+	// are totals across replicas.
+	RequestedCPUMillicores   int64 `json:"requestedCPUMillicores"`
+	UsedCPUMillicores        int64 `json:"usedCPUMillicores"`
+	RecommendedCPUMillicores int64 `json:"recommendedCPUMillicores"`
+	// OverProvisionedCPUMillicores is the requested CPU beyond the
+	// recommendations, negative when under-provisioned.
+	OverProvisionedCPUMillicores int64 `json:"overProvisionedCPUMillicores"`
+	// CPUUtilization is the used CPU in percent of the requested CPU.
+	CPUUtilization *float64 `json:"cpuUtilization,omitempty"`
+}
+
+func (h *APIHandler) listShowback(w http.ResponseWriter, r *http.Request) {
+	var opts []client.ListOption
+	if ns := r.URL.Query().Get("namespace"); ns != "" {
+		opts = append(opts, client.InNamespace(ns))
+	}
+	var profiles optimizerv1.ResourceOptimizerProfileList
+	if err := h.Client.List(r.Context(), &profiles, opts...); err != nil {
+		ctrl.Log.WithName("api").Error(err, "failed to list ResourceOptimizerProfiles")
+		writeError(w, http.StatusInternalServerError, "failed to list profiles")
+		return
+	}
+	writeJSON(w, http.StatusOK, ShowbackList{Items: showback(r.Context(), h.Client, profiles.Items)})
+}
+
+// showback aggregates the profiles by namespace. A workload selected by
+// several profiles is counted once, for the first of them by name.
+func showback(ctx context.Context, c client.Reader, profiles []optimizerv1.ResourceOptimizerProfile) []NamespaceShowback {
+	profiles = slices.Clone(profiles)
+	slices.SortFunc(profiles, func(a, b optimizerv1.ResourceOptimizerProfile) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+	})
+
+	items := []NamespaceShowback{}
+	counted := map[string]bool{}
+	for i := range profiles {
+		profile := &profiles[i]
+		if len(items) == 0 || items[len(items)-1].Namespace != profile.Namespace {
+			items = append(items, NamespaceShowback{Namespace: profile.Namespace})
+		}
+		ns := &items[len(items)-1]
+		ns.Profiles++
+
+		value, err := strconv.ParseFloat(profile.Status.ObservedMetrics["cpu_usage"], 64)
+		if err != nil {
+			// Not evaluated yet.
+			continue
+		}
+		sim, err := controller.Simulate(ctx, c, profile, value)
+		if err != nil {
+			ctrl.Log.WithName("api").Error(err, "failed to recommend target requests", "profile", client.ObjectKeyFromObject(profile))
+			continue
+		}
+		ns.EvaluatedProfiles++
+		for _, target := range sim.Targets {
+			key := profile.Namespace + "/" + target.Kind + "/" + target.Name
+			if counted[key] {
+				continue
+			}
+			counted[key] = true
+			replicas := int64(target.Replicas)
+			requested := target.CurrentCPURequest.MilliValue() * replicas
+			ns.Workloads++
+			ns.Replicas += target.Replicas
+			ns.RequestedCPUMillicores += requested
+			ns.UsedCPUMillicores += int64(float64(requested) * value / 100)
+			ns.RecommendedCPUMillicores += target.CPURequest.MilliValue() * replicas
+		}
+	}
+	for i := range items {
+		ns := &items[i]
+		ns.OverProvisionedCPUMillicores = ns.RequestedCPUMillicores - ns.RecommendedCPUMillicores
+		if ns.RequestedCPUMillicores > 0 {
+			utilization := float64(ns.UsedCPUMillicores) / float64(ns.RequestedCPUMillicores) * 100
+			ns.CPUUtilization = &utilization
+		}
+	}
+	return items
+}
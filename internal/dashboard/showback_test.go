@@ -0,0 +1,100 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Showback API", func() {
+	var handler *APIHandler
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(optimizerv1.AddToScheme(scheme)).To(Succeed())
+		profile := func(namespace, name, cpuUsage string) *optimizerv1.ResourceOptimizerProfile {
+			p := &optimizerv1.ResourceOptimizerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				Spec: optimizerv1.ResourceOptimizerProfileSpec{
+					Selector:           metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+					CPUThresholds:      optimizerv1.ThresholdSpec{Min: 20, Max: 80},
+					OptimizationPolicy: "Recommend",
+				},
+			}
+			if cpuUsage != "" {
+				p.Status.ObservedMetrics = map[string]string{"cpu_usage": cpuUsage}
+			}
+			return p
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			profile("shop", "web", "25.00"),
+			// Selects the same Deployment, which is only counted once.
+			profile("shop", "web-too", "50.00"),
+			profile("shop", "new", ""),
+			profile("blog", "web", ""),
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", Labels: map[string]string{"app": "web"}},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: ptr.To[int32](2),
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+						Spec: corev1.PodSpec{Containers: []corev1.Container{{
+							Name: "app",
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+							},
+						}}},
+					},
+				},
+			},
+		).Build()
+		handler = NewAPIHandler(c, nil, nil)
+	})
+
+	get := func(path string) ShowbackList {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		var list ShowbackList
+		Expect(json.Unmarshal(rec.Body.Bytes(), &list)).To(Succeed())
+		return list
+	}
+
+	It("should sum up the targets of the evaluated profiles of each namespace", func() {
+		list := get("/api/v1/showback")
+		Expect(list.Items).To(HaveLen(2))
+		Expect(list.Items[0]).To(Equal(NamespaceShowback{Namespace: "blog", Profiles: 1}))
+
+		shop := list.Items[1]
+		Expect(shop.Namespace).To(Equal("shop"))
+		Expect(shop.Profiles).To(Equal(3))
+		Expect(shop.EvaluatedProfiles).To(Equal(2))
+		Expect(shop.Workloads).To(Equal(1))
+		Expect(shop.Replicas).To(Equal(int32(2)))
+		Expect(shop.RequestedCPUMillicores).To(Equal(int64(2000)))
+		Expect(shop.UsedCPUMillicores).To(Equal(int64(500)))
+		Expect(shop.RecommendedCPUMillicores).To(BeNumerically("<", 2000))
+		Expect(shop.OverProvisionedCPUMillicores).To(Equal(shop.RequestedCPUMillicores - shop.RecommendedCPUMillicores))
+		Expect(shop.CPUUtilization).To(HaveValue(BeNumerically("~", 25, 0.01)))
+	})
+
+	It("should filter by namespace", func() {
+		list := get("/api/v1/showback?namespace=blog")
+		Expect(list.Items).To(HaveLen(1))
+		Expect(list.Items[0].Namespace).To(Equal("blog"))
+	})
+})
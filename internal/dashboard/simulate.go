@@ -0,0 +1,150 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/controller"
+	webhookv1 "github.com/OpScaleHub/K20s/internal/webhook/v1"
+)
+
+// maxSimulationRequestBytes bounds the body of POST /api/v1/simulate.
+const maxSimulationRequestBytes = 1 << 20
+
+// SimulationRequest is the body of POST /api/v1/simulate.
+type SimulationRequest struct {
+	// Name is the name of an existing profile in the namespace. The simulation
+	// then starts from its status, so its cooldown applies, and uses its spec
+	// and last observed CPU utilization unless Spec and CPUUtilization are set.
+	Name string `json:"name,omitempty"`
+	// Spec is the hypothetical profile's spec. It is required without Name.
+	Spec *optimizerv1.ResourceOptimizerProfileSpec `json:"spec,omitempty"`
+	// CPUUtilization is a synthetic CPU utilization in percent of the requests.
+	// It is required unless Name refers to a profile that observed one.
+	CPUUtilization *float64 `json:"cpuUtilization,omitempty"`
+}
+
+// SimulationResult is the response of POST /api/v1/simulate.
+type SimulationResult struct {
+	Namespace string `json:"namespace"`
+	// CPUUtilization is the utilization the decision was made at.
+	CPUUtilization float64 `json:"cpuUtilization"`
+	// Action is the action the thresholds call for.
+	Action string `json:"action"`
+	// Executed reports whether the controller would apply the action now.
+	Executed bool `json:"executed"`
+	// SkipReason is why an action other than DoNothing would be held back.
+	SkipReason        string `json:"skipReason,omitempty"`
+	CooldownRemaining string `json:"cooldownRemaining,omitempty"`
+	// Warnings are the admission webhook's warnings about the spec.
+	Warnings []string          `json:"warnings,omitempty"`
+	Targets  []SimulatedTarget `json:"targets"`
+}
+
+// SimulatedTarget is a workload the profile selects and the CPU request
+// recommended for it.
+type SimulatedTarget struct {
+	Kind                  string `json:"kind"`
+	Name                  string `json:"name"`
+	Replicas              int32  `json:"replicas"`
+	CPURequest            string `json:"cpuRequest"`
+	RecommendedCPURequest string `json:"recommendedCPURequest"`
+}
+
+func (h *APIHandler) simulate(w http.ResponseWriter, r *http.Request) {
+	namespace := r.URL.Query().Get("namespace")
+	if namespace == "" {
+		writeError(w, http.StatusBadRequest, "namespace is required")
+		return
+	}
+	var req SimulationRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSimulationRequestBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	profile := &optimizerv1.ResourceOptimizerProfile{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "simulation"}}
+	if req.Name != "" {
+		key := types.NamespacedName{Namespace: namespace, Name: req.Name}
+		if err := h.Client.Get(r.Context(), key, profile); err != nil {
+			if apierrors.IsNotFound(err) {
+				writeError(w, http.StatusNotFound, "profile "+key.String()+" not found")
+				return
+			}
+			ctrl.Log.WithName("api").Error(err, "failed to get ResourceOptimizerProfile", "profile", key.String())
+			writeError(w, http.StatusInternalServerError, "failed to get profile")
+			return
+		}
+	} else if req.Spec == nil {
+		writeError(w, http.StatusBadRequest, "spec is required without name")
+		return
+	}
+	if req.Spec != nil {
+		profile.Spec = *req.Spec
+	}
+
+	var value float64
+	switch {
+	case req.CPUUtilization != nil:
+		value = *req.CPUUtilization
+	case req.Name != "":
+		observed, err := strconv.ParseFloat(profile.Status.ObservedMetrics["cpu_usage"], 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("cpuUtilization is required: profile %s has not observed one yet", req.Name))
+			return
+		}
+		value = observed
+	default:
+		writeError(w, http.StatusBadRequest, "cpuUtilization is required without name")
+		return
+	}
+	if value < 0 {
+		writeError(w, http.StatusBadRequest, "cpuUtilization must not be negative")
+		return
+	}
+
+	warnings, err := webhookv1.ValidateProfile(profile)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	sim, err := controller.Simulate(r.Context(), h.Client, profile, value)
+	if err != nil {
+		ctrl.Log.WithName("api").Error(err, "failed to simulate profile", "namespace", namespace, "name", req.Name)
+		writeError(w, http.StatusInternalServerError, "failed to simulate profile")
+		return
+	}
+
+	result := SimulationResult{
+		Namespace:      namespace,
+		CPUUtilization: value,
+		Action:         sim.Action,
+		Executed:       sim.Executed,
+		SkipReason:     sim.SkipReason,
+		Warnings:       warnings,
+		Targets:        make([]SimulatedTarget, 0, len(sim.Targets)),
+	}
+	if sim.CooldownRemaining > 0 {
+		result.CooldownRemaining = sim.CooldownRemaining.String()
+	}
+	for _, target := range sim.Targets {
+		result.Targets = append(result.Targets, SimulatedTarget{
+			Kind:                  target.Kind,
+			Name:                  target.Name,
+			Replicas:              target.Replicas,
+			CPURequest:            target.CurrentCPURequest.String(),
+			RecommendedCPURequest: target.CPURequest.String(),
+		})
+	}
+	writeJSON(w, http.StatusOK, result)
+}
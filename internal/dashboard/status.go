@@ -0,0 +1,368 @@
+package dashboard
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/audit"
+	"github.com/OpScaleHub/K20s/internal/controller"
+)
+
+// recentActionsShown is the number of actions rendered on the status page.
+const recentActionsShown = 20
+
+const statusPageTemplate = `
+<!DOCTYPE html>
+<html>
+<head>
+    <title>K20s Controller Status</title>
+    <style>
+        body { font-family: sans-serif; margin: 2em; }
+        table { border-collapse: collapse; width: 100%; }
+        th, td { border: 1px solid #ddd; padding: 8px; text-align: left; }
+        th { background-color: #f2f2f2; }
+        h1 { color: #333; }
+        #live { color: #888; font-size: 0.9em; }
+        .updated { animation: flash 1.5s; }
+        @keyframes flash { from { background-color: #fff3b0; } to { background-color: transparent; } }
+        .ok { color: #2e7d32; }
+        .error { color: #c62828; }
+        svg.sparkline { vertical-align: middle; margin-left: 0.5em; }
+        svg.sparkline .band { fill: #e8f5e9; }
+        svg.sparkline polyline { fill: none; stroke: #1565c0; stroke-width: 1.5; }
+    </style>
+</head>
+<body>
+    <h1>K20s Controller Status</h1>
+    <p id="live">Connecting to live updates…</p>
+    {{with .Prometheus}}
+    <p id="prometheus">
+        Prometheus <code>{{.URL}}</code>:
+        {{if .Reachable}}<span class="ok">reachable</span>{{else}}<span class="error">unreachable since {{.FailingSince.Format "2006-01-02 15:04:05"}}: {{.LastError}}</span>{{end}},
+        last successful query {{if .LastSuccess.IsZero}}never{{else}}{{.LastSuccess.Format "2006-01-02 15:04:05"}}{{end}}
+    </p>
+    {{end}}
+    <h2>Resource Optimizer Profiles</h2>
+    <table>
+        <thead>
+        <tr>
+            <th>Namespace</th>
+            <th>Name</th>
+            <th>Policy</th>
+            <th>Last Action</th>
+            <th>Observed CPU</th>
+            <th>Recommendation</th>
+        </tr>
+        </thead>
+        <tbody id="profiles">
+        {{range .Profiles}}
+        <tr id="profile-{{.Namespace}}/{{.Name}}">
+            <td>{{.Namespace}}</td>
+            <td><a href="{{$.StatusPath}}/{{.Namespace}}/{{.Name}}">{{.Name}}</a></td>
+            <td>{{.Spec.OptimizationPolicy}}</td>
+            <td>{{if .Status.LastAction}}{{.Status.LastAction.Type}} @ {{.Status.LastAction.Timestamp.Format "2006-01-02 15:04:05"}}{{else}}None{{end}}</td>
+            <td class="cpu" data-min="{{.Spec.CPUThresholds.Min}}" data-max="{{.Spec.CPUThresholds.Max}}" data-samples="{{$.SamplesJSON .Namespace .Name}}">{{if .Status.ObservedMetrics}}{{.Status.ObservedMetrics.cpu_usage}}%{{else}}N/A{{end}}</td>
+            <td>{{if .Status.Recommendations}}{{range .Status.Recommendations}}{{.}}{{end}}{{else}}None{{end}}</td>
+        </tr>
+        {{end}}
+        </tbody>
+    </table>
+    <h2>Showback by Namespace</h2>
+    <p>CPU of the targets of evaluated profiles, across all replicas. Used CPU is estimated from the utilization each profile last observed.</p>
+    <table>
+        <thead>
+        <tr>
+            <th>Namespace</th>
+            <th>Profiles</th>
+            <th>Workloads</th>
+            <th>Replicas</th>
+            <th>Requested CPU</th>
+            <th>Used CPU</th>
+            <th>Recommended CPU</th>
+            <th>Over-provisioned</th>
+            <th>Utilization</th>
+        </tr>
+        </thead>
+        <tbody id="showback">
+        {{range .Showback}}
+        <tr>
+            <td>{{.Namespace}}</td>
+            <td>{{.EvaluatedProfiles}}/{{.Profiles}}</td>
+            <td>{{.Workloads}}</td>
+            <td>{{.Replicas}}</td>
+            <td>{{.RequestedCPUMillicores}}m</td>
+            <td>{{.UsedCPUMillicores}}m</td>
+            <td>{{.RecommendedCPUMillicores}}m</td>
+            <td>{{.OverProvisionedCPUMillicores}}m</td>
+            <td>{{with .CPUUtilization}}{{printf "%.1f" .}}%{{else}}N/A{{end}}</td>
+        </tr>
+        {{end}}
+        </tbody>
+    </table>
+    <h2>Recent Actions</h2>
+    <p><a href="{{.ActionsPath}}">All actions</a></p>
+    <table>
+        <thead>
+        <tr>
+            <th>Time</th>
+            <th>Profile</th>
+            <th>Action</th>
+            <th>Target</th>
+            <th>Change</th>
+            <th>Result</th>
+        </tr>
+        </thead>
+        <tbody id="actions">
+        {{range .Actions}}
+        <tr>
+            <td>{{.Time.Format "2006-01-02 15:04:05"}}</td>
+            <td>{{.ProfileNamespace}}/{{.ProfileName}}</td>
+            <td>{{.Action}}</td>
+            <td>{{.TargetKind}}/{{.TargetName}}</td>
+            <td>{{.Field}}: {{.Before}} → {{.After}}</td>
+            <td>{{.Result}}</td>
+        </tr>
+        {{end}}
+        </tbody>
+    </table>
+    <script>
+    (() => {
+        const maxActions = {{.MaxActions}};
+        const maxSamples = {{.MaxSamples}};
+        const statusPath = {{.StatusPath}};
+        const pad = (n) => String(n).padStart(2, "0");
+        const formatTime = (s) => {
+            const d = new Date(s);
+            return d.getFullYear() + "-" + pad(d.getMonth() + 1) + "-" + pad(d.getDate()) + " " +
+                pad(d.getHours()) + ":" + pad(d.getMinutes()) + ":" + pad(d.getSeconds());
+        };
+        const row = (cells) => {
+            const tr = document.createElement("tr");
+            for (const text of cells) {
+                const td = document.createElement("td");
+                td.textContent = text;
+                tr.appendChild(td);
+            }
+            return tr;
+        };
+        // sparkline draws the CPU samples of a profile, with its threshold band,
+        // into the given cell.
+        const sparkline = (td) => {
+            const samples = JSON.parse(td.dataset.samples || "[]");
+            const old = td.querySelector("svg");
+            if (old) {
+                old.remove();
+            }
+            if (samples.length < 2) {
+                return;
+            }
+            const w = 120, h = 24, ns = "http://www.w3.org/2000/svg";
+            const top = Math.max(100, ...samples.map((s) => s.value));
+            const y = (v) => h - (v / top) * h;
+            const svg = document.createElementNS(ns, "svg");
+            svg.setAttribute("class", "sparkline");
+            svg.setAttribute("width", w);
+            svg.setAttribute("height", h);
+            const band = document.createElementNS(ns, "rect");
+            band.setAttribute("class", "band");
+            band.setAttribute("x", 0);
+            band.setAttribute("width", w);
+            band.setAttribute("y", y(Number(td.dataset.max)));
+            band.setAttribute("height", Math.max(0, y(Number(td.dataset.min)) - y(Number(td.dataset.max))));
+            svg.appendChild(band);
+            const line = document.createElementNS(ns, "polyline");
+            line.setAttribute("points", samples.map((s, i) => (i * w / (samples.length - 1)) + "," + y(s.value)).join(" "));
+            svg.appendChild(line);
+            const title = document.createElementNS(ns, "title");
+            title.textContent = samples.length + " samples since " + formatTime(samples[0].time);
+            svg.appendChild(title);
+            td.appendChild(svg);
+        };
+        const flash = (tr) => {
+            tr.classList.add("updated");
+            setTimeout(() => tr.classList.remove("updated"), 1500);
+        };
+
+        const renderProfile = (p) => {
+            const status = p.status || {};
+            const last = status.lastAction ? status.lastAction.type + " @ " + formatTime(status.lastAction.timestamp) : "None";
+            const cpu = status.observedMetrics && status.observedMetrics.cpu_usage ? status.observedMetrics.cpu_usage + "%" : "N/A";
+            const recs = status.recommendations && status.recommendations.length ? status.recommendations.join("") : "None";
+            const tr = row([p.namespace, p.name, p.spec.optimizationPolicy, last, cpu, recs]);
+            tr.id = "profile-" + p.namespace + "/" + p.name;
+            const link = document.createElement("a");
+            link.href = statusPath + "/" + encodeURIComponent(p.namespace) + "/" + encodeURIComponent(p.name);
+            link.textContent = p.name;
+            tr.cells[1].replaceChildren(link);
+            const td = tr.cells[4];
+            td.className = "cpu";
+            td.dataset.min = p.spec.cpuThresholds.min;
+            td.dataset.max = p.spec.cpuThresholds.max;
+            return tr;
+        };
+
+        const live = document.getElementById("live");
+        const profiles = document.getElementById("profiles");
+        const actions = document.getElementById("actions");
+        document.querySelectorAll("td.cpu").forEach(sparkline);
+
+        const source = new EventSource({{.EventsURL}});
+        source.onopen = () => { live.textContent = "Live: updates appear as reconciles complete."; };
+        source.onerror = () => { live.textContent = "Live updates disconnected, retrying…"; };
+        source.addEventListener("profile", (e) => {
+            const tr = renderProfile(JSON.parse(e.data));
+            const existing = document.getElementById(tr.id);
+            if (existing) {
+                // Keep the samples collected so far.
+                tr.cells[4].dataset.samples = existing.cells[4].dataset.samples || "[]";
+                sparkline(tr.cells[4]);
+                existing.replaceWith(tr);
+            } else {
+                profiles.appendChild(tr);
+            }
+            flash(tr);
+        });
+        source.addEventListener("cpu", (e) => {
+            const s = JSON.parse(e.data);
+            const tr = document.getElementById("profile-" + s.namespace + "/" + s.name);
+            if (!tr) {
+                return;
+            }
+            const td = tr.cells[4];
+            const samples = JSON.parse(td.dataset.samples || "[]");
+            samples.push({ time: s.time, value: s.value });
+            td.dataset.samples = JSON.stringify(samples.slice(-maxSamples));
+            sparkline(td);
+        });
+        source.addEventListener("profile-deleted", (e) => {
+            const ref = JSON.parse(e.data);
+            const existing = document.getElementById("profile-" + ref.namespace + "/" + ref.name);
+            if (existing) {
+                existing.remove();
+            }
+        });
+        source.addEventListener("action", (e) => {
+            const a = JSON.parse(e.data);
+            const tr = row([formatTime(a.time), a.profileNamespace + "/" + a.profileName, a.action,
+                a.targetKind + "/" + a.targetName, a.field + ": " + a.before + " → " + a.after, a.result]);
+            actions.prepend(tr);
+            flash(tr);
+            while (actions.rows.length > maxActions) {
+                actions.deleteRow(-1);
+            }
+        });
+    })();
+    </script>
+</body>
+</html>
+`
+
+var statusPage = template.Must(template.New("status").Parse(statusPageTemplate))
+
+// StatusPage serves a simple HTML page with the status of all
+// ResourceOptimizerProfiles, their showback by namespace and the most recent
+// actions at StatusPath, and a
+// page with the targets of each profile at StatusPath/{namespace}/{name}. The
+// overview subscribes to the API's event stream to update itself without
+// reloads.
+type StatusPage struct {
+	// Client reads profiles, typically from the manager's cache.
+	Client client.Reader
+	// Actions holds the recent actions shown on the page. Optional.
+	Actions *audit.History
+	// History holds the CPU samples drawn as sparklines. Optional.
+	History *CPUHistory
+	// Prometheus reports the connectivity shown at the top of the page. Optional.
+	Prometheus *controller.PrometheusHealth
+}
+
+// statusPageData is rendered by statusPageTemplate.
+type statusPageData struct {
+	Profiles    []optimizerv1.ResourceOptimizerProfile
+	Showback    []NamespaceShowback
+	Actions     []audit.Record
+	Prometheus  *controller.PrometheusConnectivity
+	MaxActions  int
+	MaxSamples  int
+	EventsURL   string
+	StatusPath  string
+	ActionsPath string
+	history     *CPUHistory
+}
+
+// SamplesJSON returns the CPU samples of a profile as a JSON array.
+func (d statusPageData) SamplesJSON(namespace, name string) (string, error) {
+	samples := []Sample{}
+	if d.history != nil {
+		samples = d.history.Samples(types.NamespacedName{Namespace: namespace, Name: name})
+	}
+	out, err := json.Marshal(samples)
+	return string(out), err
+}
+
+func (h *StatusPage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if rest, ok := strings.CutPrefix(r.URL.Path, StatusPath+"/"); ok {
+		namespace, name, _ := strings.Cut(rest, "/")
+		if namespace == "" || name == "" || strings.Contains(name, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		h.serveProfile(w, r, types.NamespacedName{Namespace: namespace, Name: name})
+		return
+	}
+
+	ctx := r.Context()
+	logger := ctrl.Log.WithName("status-page-handler")
+
+	var profiles optimizerv1.ResourceOptimizerProfileList
+	if err := h.Client.List(ctx, &profiles); err != nil {
+		logger.Error(err, "failed to list ResourceOptimizerProfiles")
+		http.Error(w, "Failed to list resources", http.StatusInternalServerError)
+		return
+	}
+
+	var actions []audit.Record
+	if h.Actions != nil {
+		actions = h.Actions.Records()
+		if len(actions) > recentActionsShown {
+			actions = actions[:recentActionsShown]
+		}
+	}
+
+	data := statusPageData{
+		Profiles:    profiles.Items,
+		Showback:    showback(ctx, h.Client, profiles.Items),
+		Actions:     actions,
+		MaxActions:  recentActionsShown,
+		MaxSamples:  DefaultCPUHistorySize,
+		EventsURL:   APIPrefix + "events",
+		StatusPath:  StatusPath,
+		ActionsPath: ActionsPath,
+		history:     h.History,
+	}
+	if h.History != nil {
+		data.MaxSamples = h.History.size
+	}
+	if h.Prometheus != nil {
+		status := h.Prometheus.Status()
+		data.Prometheus = &status
+	}
+
+	var buf bytes.Buffer
+	if err := statusPage.Execute(&buf, data); err != nil {
+		logger.Error(err, "failed to execute HTML template")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	_, _ = w.Write(buf.Bytes())
+}
@@ -0,0 +1,54 @@
+package dashboard
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/audit"
+	"github.com/OpScaleHub/K20s/internal/controller"
+)
+
+var _ = Describe("StatusPage", func() {
+	It("should render profiles and recent actions and subscribe to live events", func() {
+		scheme := runtime.NewScheme()
+		Expect(optimizerv1.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&optimizerv1.ResourceOptimizerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+			Spec:       optimizerv1.ResourceOptimizerProfileSpec{OptimizationPolicy: "Scale"},
+			Status: optimizerv1.ResourceOptimizerProfileStatus{
+				ObservedMetrics: map[string]string{"cpu_usage": "91.00"},
+			},
+		}).Build()
+		history := audit.NewHistory(5)
+		Expect(history.Record(context.Background(), audit.Record{
+			ProfileNamespace: "team-a", ProfileName: "web", Action: "ScaleUp",
+			TargetKind: "Deployment", TargetName: "web", Field: "spec.replicas", Before: "2", After: "3",
+			Result: audit.ResultSucceeded,
+		})).To(Succeed())
+
+		cpu := NewCPUHistory(10, nil)
+		cpu.ObserveCPU(types.NamespacedName{Namespace: "team-a", Name: "web"}, time.Unix(0, 0).UTC(), 91)
+
+		rec := httptest.NewRecorder()
+		(&StatusPage{Client: c, Actions: history, History: cpu, Prometheus: &controller.PrometheusHealth{}}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, StatusPath, nil))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		body := rec.Body.String()
+		Expect(body).To(ContainSubstring(`id="profile-team-a/web"`))
+		Expect(body).To(ContainSubstring("91.00%"))
+		Expect(body).To(ContainSubstring(`id="showback"`))
+		Expect(body).To(ContainSubstring(`<span class="ok">reachable</span>`))
+		Expect(body).To(ContainSubstring("spec.replicas: 2 → 3"))
+		Expect(body).To(ContainSubstring(`data-samples="[{&#34;time&#34;:&#34;1970-01-01T00:00:00Z&#34;,&#34;value&#34;:91}]"`))
+		Expect(body).To(ContainSubstring(`new EventSource("/api/v1/events")`))
+	})
+})
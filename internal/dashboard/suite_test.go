@@ -0,0 +1,14 @@
+package dashboard
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestDashboard(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Dashboard Suite")
+}
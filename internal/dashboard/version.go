@@ -0,0 +1,21 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/OpScaleHub/K20s/internal/version"
+)
+
+// VersionPath is where the build information of the controller is served.
+const VersionPath = "/version"
+
+// VersionHandler serves the build information of the controller as JSON. Like
+// the API server's /version it describes no profile data and needs no
+// authentication.
+type VersionHandler struct{}
+
+func (VersionHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(version.Get())
+}
@@ -0,0 +1,25 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/OpScaleHub/K20s/internal/version"
+)
+
+var _ = Describe("VersionHandler", func() {
+	It("should serve the build information", func() {
+		rec := httptest.NewRecorder()
+		VersionHandler{}.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, VersionPath, nil))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Content-Type")).To(Equal("application/json"))
+
+		var info version.Info
+		Expect(json.Unmarshal(rec.Body.Bytes(), &info)).To(Succeed())
+		Expect(info).To(Equal(version.Get()))
+	})
+})
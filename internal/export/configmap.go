@@ -0,0 +1,199 @@
+// Package export publishes the controller's recommendations outside of the
+// ResourceOptimizerProfile API, for consumers that cannot read the CRD.
+package export
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/yaml"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/controller"
+)
+
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;create;patch;delete
+
+const (
+	// ConfigMapName is the name of the ConfigMap holding the recommendations of
+	// the profiles in its namespace.
+	ConfigMapName = "k20s-recommendations"
+	// YAMLKey and JSONKey hold the same Recommendations document in both
+	// formats.
+	YAMLKey = "recommendations.yaml"
+	JSONKey = "recommendations.json"
+
+	// DefaultInterval is how often the ConfigMaps are refreshed.
+	DefaultInterval = time.Minute
+
+	// fieldOwner is the server-side apply field manager of the ConfigMaps.
+	fieldOwner = "k20s"
+)
+
+// managedLabels mark the ConfigMaps maintained by the exporter.
+var managedLabels = map[string]string{
+	"app.kubernetes.io/name":       "k20s",
+	"app.kubernetes.io/managed-by": "k20s",
+	"app.kubernetes.io/component":  "recommendations",
+}
+
+// Recommendations is the document published for a namespace.
+type Recommendations struct {
+	Namespace   string                  `json:"namespace"`
+	GeneratedAt time.Time               `json:"generatedAt"`
+	Profiles    []ProfileRecommendation `json:"profiles"`
+}
+
+// ProfileRecommendation is what the controller currently recommends for a profile.
+type ProfileRecommendation struct {
+	Name   string `json:"name"`
+	Policy string `json:"policy"`
+	// CPUUtilization is the utilization last observed, in percent of requests.
+	CPUUtilization *float64 `json:"cpuUtilization,omitempty"`
+	// Action is the action the thresholds call for at that utilization.
+	Action string `json:"action,omitempty"`
+	// Confidence is how far that utilization can be trusted.
+	Confidence      *optimizerv1.Confidence `json:"confidence,omitempty"`
+	Recommendations []string                `json:"recommendations,omitempty"`
+	Targets         []TargetRecommendation  `json:"targets,omitempty"`
+}
+
+// TargetRecommendation is the CPU request recommended for a workload.
+type TargetRecommendation struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	CPURequest string `json:"cpuRequest"`
+}
+
+// ConfigMapExporter maintains a ConfigMap with the current recommendations in
+// every namespace that has profiles, and deletes it from namespaces that no
+// longer do.
+type ConfigMapExporter struct {
+	// Client reads profiles and their targets and writes the ConfigMaps.
+	Client client.Client
+	// Reader lists existing ConfigMaps. Use an uncached reader so the
+	// controller doesn't watch every ConfigMap in the cluster.
+	Reader client.Reader
+	// Interval is how often the ConfigMaps are refreshed.
+	Interval time.Duration
+}
+
+var _ manager.LeaderElectionRunnable = &ConfigMapExporter{}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (e *ConfigMapExporter) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable.
+func (e *ConfigMapExporter) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := e.Export(ctx); err != nil {
+			log.FromContext(ctx).Error(err, "unable to export recommendations")
+		}
+	}, cmp.Or(e.Interval, DefaultInterval))
+	return nil
+}
+
+// Export refreshes every ConfigMap once.
+func (e *ConfigMapExporter) Export(ctx context.Context) error {
+	var profiles optimizerv1.ResourceOptimizerProfileList
+	if err := e.Client.List(ctx, &profiles); err != nil {
+		return fmt.Errorf("listing profiles: %w", err)
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	documents := map[string]*Recommendations{}
+	for i := range profiles.Items {
+		profile := &profiles.Items[i]
+		doc, ok := documents[profile.Namespace]
+		if !ok {
+			doc = &Recommendations{Namespace: profile.Namespace, GeneratedAt: now}
+			documents[profile.Namespace] = doc
+		}
+		recommendation, err := e.recommend(ctx, profile)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "unable to recommend target requests", "profile", client.ObjectKeyFromObject(profile))
+		}
+		doc.Profiles = append(doc.Profiles, recommendation)
+	}
+
+	for _, doc := range documents {
+		slices.SortFunc(doc.Profiles, func(a, b ProfileRecommendation) int { return cmp.Compare(a.Name, b.Name) })
+		if err := e.apply(ctx, doc); err != nil {
+			return fmt.Errorf("publishing recommendations in namespace %s: %w", doc.Namespace, err)
+		}
+	}
+
+	var existing corev1.ConfigMapList
+	if err := e.Reader.List(ctx, &existing, client.MatchingLabelsSelector{Selector: labels.SelectorFromSet(managedLabels)}); err != nil {
+		return fmt.Errorf("listing recommendation ConfigMaps: %w", err)
+	}
+	for i := range existing.Items {
+		cm := &existing.Items[i]
+		if _, ok := documents[cm.Namespace]; ok || cm.Name != ConfigMapName {
+			continue
+		}
+		if err := e.Client.Delete(ctx, cm); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("deleting stale recommendations in namespace %s: %w", cm.Namespace, err)
+		}
+	}
+	return nil
+}
+
+// recommend builds the recommendation of a profile from its status, using the
+// last observed utilization for the target requests.
+func (e *ConfigMapExporter) recommend(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile) (ProfileRecommendation, error) {
+	recommendation := ProfileRecommendation{
+		Name:            profile.Name,
+		Policy:          profile.Spec.OptimizationPolicy,
+		Confidence:      profile.Status.Confidence,
+		Recommendations: profile.Status.Recommendations,
+	}
+	value, err := strconv.ParseFloat(profile.Status.ObservedMetrics["cpu_usage"], 64)
+	if err != nil {
+		// Not evaluated yet.
+		return recommendation, nil
+	}
+	recommendation.CPUUtilization = &value
+
+	sim, err := controller.Simulate(ctx, e.Client, profile, value)
+	if err != nil {
+		return recommendation, err
+	}
+	recommendation.Action = sim.Action
+	for _, target := range sim.Targets {
+		recommendation.Targets = append(recommendation.Targets, TargetRecommendation{
+			Kind:       target.Kind,
+			Name:       target.Name,
+			CPURequest: target.CPURequest.String(),
+		})
+	}
+	return recommendation, nil
+}
+
+func (e *ConfigMapExporter) apply(ctx context.Context, doc *Recommendations) error {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	yamlData, err := yaml.JSONToYAML(data)
+	if err != nil {
+		return err
+	}
+	cm := corev1ac.ConfigMap(ConfigMapName, doc.Namespace).
+		WithLabels(managedLabels).
+		WithData(map[string]string{YAMLKey: string(yamlData), JSONKey: string(data) + "\n"})
+	return e.Client.Apply(ctx, cm, client.FieldOwner(fieldOwner), client.ForceOwnership)
+}
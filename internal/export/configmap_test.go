@@ -0,0 +1,82 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("ConfigMapExporter", func() {
+	labels := map[string]string{"app": "web"}
+
+	newClient := func(objects ...client.Object) client.Client {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(optimizerv1.AddToScheme(scheme)).To(Succeed())
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	}
+
+	It("should publish the recommendations of every namespace", func() {
+		c := newClient(
+			&optimizerv1.ResourceOptimizerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+				Spec: optimizerv1.ResourceOptimizerProfileSpec{
+					Selector:           metav1.LabelSelector{MatchLabels: labels},
+					CPUThresholds:      optimizerv1.ThresholdSpec{Min: 20, Max: 80},
+					OptimizationPolicy: "Recommend",
+				},
+				Status: optimizerv1.ResourceOptimizerProfileStatus{
+					ObservedMetrics: map[string]string{"cpu_usage": "100.00"},
+					Recommendations: []string{"CPU usage is 100.00%. Consider ScaleUp."},
+					Confidence:      &optimizerv1.Confidence{Score: 82, Level: "High", Samples: 289},
+				},
+			},
+			&optimizerv1.ResourceOptimizerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: "team-a"},
+				Spec:       optimizerv1.ResourceOptimizerProfileSpec{Selector: metav1.LabelSelector{MatchLabels: labels}},
+			},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", Labels: labels},
+				Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Name:      "app",
+					Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}},
+				}}}}},
+			},
+			// Left over from a namespace whose profiles were deleted.
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName, Namespace: "team-b", Labels: managedLabels}},
+		)
+		exporter := &ConfigMapExporter{Client: c, Reader: c}
+		Expect(exporter.Export(context.Background())).To(Succeed())
+
+		var cm corev1.ConfigMap
+		Expect(c.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: ConfigMapName}, &cm)).To(Succeed())
+		Expect(cm.Labels).To(Equal(managedLabels))
+
+		var doc Recommendations
+		Expect(json.Unmarshal([]byte(cm.Data[JSONKey]), &doc)).To(Succeed())
+		Expect(doc.Namespace).To(Equal("team-a"))
+		Expect(doc.Profiles).To(HaveLen(2))
+		Expect(doc.Profiles[0].Name).To(Equal("new"))
+		Expect(doc.Profiles[0].CPUUtilization).To(BeNil())
+		Expect(doc.Profiles[1].Action).To(Equal("ScaleUp"))
+		Expect(doc.Profiles[1].Confidence.Score).To(Equal(int32(82)))
+		Expect(doc.Profiles[1].Targets).To(Equal([]TargetRecommendation{{Kind: "Deployment", Name: "web", CPURequest: "250m"}}))
+		Expect(cm.Data[YAMLKey]).To(ContainSubstring("- CPU usage is 100.00%. Consider ScaleUp.\n"))
+
+		err := c.Get(context.Background(), types.NamespacedName{Namespace: "team-b", Name: ConfigMapName}, &cm)
+		Expect(err).To(MatchError(ContainSubstring("not found")))
+	})
+})
@@ -0,0 +1,93 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	grpcv1 "github.com/OpScaleHub/K20s/api/grpc/v1"
+	"github.com/OpScaleHub/K20s/internal/dashboard"
+)
+
+// Authorizer checks that the caller presenting a bearer token may verb
+// ResourceOptimizerProfiles in namespace, or in all namespaces when it is
+// empty. *dashboard.Auth implements it, so the gRPC API and the status page
+// share their access control.
+type Authorizer interface {
+	Check(ctx context.Context, token, verb, namespace, name string) error
+}
+
+var _ Authorizer = &dashboard.Auth{}
+
+// unaryAuth authorizes every OptimizerService call with the access its request
+// needs. The health service stays open for probes.
+func (s *Server) unaryAuth(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if !optimizerMethod(info.FullMethod) {
+		return handler(ctx, req)
+	}
+	verb, namespace, name := requestAccess(req)
+	if err := s.authorize(ctx, verb, namespace, name); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// streamAuth authorizes streaming OptimizerService calls, which need access to
+// the profiles of all namespaces.
+func (s *Server) streamAuth(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if !optimizerMethod(info.FullMethod) {
+		return handler(srv, stream)
+	}
+	if err := s.authorize(stream.Context(), "list", "", ""); err != nil {
+		return err
+	}
+	return handler(srv, stream)
+}
+
+// authorize checks the bearer token in the call's authorization metadata.
+func (s *Server) authorize(ctx context.Context, verb, namespace, name string) error {
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, value := range md.Get("authorization") {
+			if bearer, ok := strings.CutPrefix(value, "Bearer "); ok {
+				token = bearer
+				break
+			}
+		}
+	}
+	err := s.Auth.Check(ctx, token, verb, namespace, name)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, dashboard.ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, dashboard.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	default:
+		log.FromContext(ctx).Error(err, "authorization failed")
+		return status.Error(codes.Internal, "authorization failed")
+	}
+}
+
+// requestAccess maps a request to the ResourceOptimizerProfile access it
+// needs: get for a single profile, list in the requested namespace for
+// everything else.
+func requestAccess(req any) (verb, namespace, name string) {
+	if r, ok := req.(interface{ GetProfile() *grpcv1.ProfileRef }); ok {
+		return "get", r.GetProfile().GetNamespace(), r.GetProfile().GetName()
+	}
+	if r, ok := req.(interface{ GetNamespace() string }); ok {
+		return "list", r.GetNamespace(), ""
+	}
+	return "list", "", ""
+}
+
+func optimizerMethod(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/"+grpcv1.OptimizerService_ServiceDesc.ServiceName+"/")
+}
@@ -0,0 +1,230 @@
+// Package grpcapi serves the OptimizerService defined in api/grpc/v1.
+package grpcapi
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	grpcv1 "github.com/OpScaleHub/K20s/api/grpc/v1"
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/controller"
+)
+
+// Service implements grpcv1.OptimizerServiceServer on top of a Kubernetes client.
+type Service struct {
+	grpcv1.UnimplementedOptimizerServiceServer
+
+	// Client reads profiles and their targets, typically from the manager's cache.
+	Client client.Reader
+}
+
+var _ grpcv1.OptimizerServiceServer = &Service{}
+
+// ListRecommendations implements grpcv1.OptimizerServiceServer.
+func (s *Service) ListRecommendations(ctx context.Context, req *grpcv1.ListRecommendationsRequest) (*grpcv1.ListRecommendationsResponse, error) {
+	var opts []client.ListOption
+	if req.GetNamespace() != "" {
+		opts = append(opts, client.InNamespace(req.GetNamespace()))
+	}
+	var profiles optimizerv1.ResourceOptimizerProfileList
+	if err := s.Client.List(ctx, &profiles, opts...); err != nil {
+		return nil, status.Errorf(codes.Internal, "listing profiles: %v", err)
+	}
+
+	resp := &grpcv1.ListRecommendationsResponse{}
+	for i := range profiles.Items {
+		profile := &profiles.Items[i]
+		item := &grpcv1.ProfileRecommendations{
+			Profile:            profileRef(profile),
+			OptimizationPolicy: profile.Spec.OptimizationPolicy,
+			Recommendations:    profile.Status.Recommendations,
+		}
+		if cpu, ok := observedCPU(profile); ok {
+			item.ObservedCpuUtilization = &cpu
+		}
+		resp.Profiles = append(resp.Profiles, item)
+	}
+	return resp, nil
+}
+
+// GetProfileStatus implements grpcv1.OptimizerServiceServer.
+func (s *Service) GetProfileStatus(ctx context.Context, req *grpcv1.GetProfileStatusRequest) (*grpcv1.ProfileStatus, error) {
+	profile, err := s.getProfile(ctx, req.GetProfile())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &grpcv1.ProfileStatus{
+		Profile:            profileRef(profile),
+		OptimizationPolicy: profile.Spec.OptimizationPolicy,
+		ObservedMetrics:    profile.Status.ObservedMetrics,
+		Recommendations:    profile.Status.Recommendations,
+	}
+	if last := profile.Status.LastAction; last != nil {
+		resp.LastAction = &grpcv1.LastAction{
+			Type:      last.Type,
+			Timestamp: timestamppb.New(last.Timestamp.Time),
+			Details:   last.Details,
+		}
+	}
+	for _, cond := range profile.Status.Conditions {
+		resp.Conditions = append(resp.Conditions, &grpcv1.Condition{
+			Type:               cond.Type,
+			Status:             string(cond.Status),
+			Reason:             cond.Reason,
+			Message:            cond.Message,
+			LastTransitionTime: timestamppb.New(cond.LastTransitionTime.Time),
+		})
+	}
+	return resp, nil
+}
+
+// SimulateAction implements grpcv1.OptimizerServiceServer.
+func (s *Service) SimulateAction(ctx context.Context, req *grpcv1.SimulateActionRequest) (*grpcv1.SimulateActionResponse, error) {
+	if req.GetCpuUtilization() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "cpu_utilization must not be negative")
+	}
+	profile, err := s.getProfile(ctx, req.GetProfile())
+	if err != nil {
+		return nil, err
+	}
+
+	sim, err := controller.Simulate(ctx, s.Client, profile, req.GetCpuUtilization())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "simulating profile: %v", err)
+	}
+	resp := &grpcv1.SimulateActionResponse{
+		Action:            sim.Action,
+		Executed:          sim.Executed,
+		SkipReason:        sim.SkipReason,
+		CooldownRemaining: durationpb.New(sim.CooldownRemaining),
+	}
+	for _, target := range sim.Targets {
+		resp.Targets = append(resp.Targets, &grpcv1.TargetRecommendation{
+			Kind:       target.Kind,
+			Name:       target.Name,
+			CpuRequest: target.CPURequest.String(),
+		})
+	}
+	return resp, nil
+}
+
+func (s *Service) getProfile(ctx context.Context, ref *grpcv1.ProfileRef) (*optimizerv1.ResourceOptimizerProfile, error) {
+	if ref.GetNamespace() == "" || ref.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "profile namespace and name are required")
+	}
+	key := types.NamespacedName{Namespace: ref.GetNamespace(), Name: ref.GetName()}
+	var profile optimizerv1.ResourceOptimizerProfile
+	if err := s.Client.Get(ctx, key, &profile); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, status.Errorf(codes.NotFound, "profile %s not found", key)
+		}
+		return nil, status.Errorf(codes.Internal, "getting profile %s: %v", key, err)
+	}
+	return &profile, nil
+}
+
+func profileRef(profile *optimizerv1.ResourceOptimizerProfile) *grpcv1.ProfileRef {
+	return &grpcv1.ProfileRef{Namespace: profile.Namespace, Name: profile.Name}
+}
+
+// observedCPU parses the CPU utilization the controller last stored in status.
+func observedCPU(profile *optimizerv1.ResourceOptimizerProfile) (float64, bool) {
+	raw, ok := profile.Status.ObservedMetrics["cpu_usage"]
+	if !ok {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	return value, err == nil
+}
+
+// Server serves the OptimizerService and the standard gRPC health service on
+// its own port. It reads from the manager's cache and therefore runs on every
+// replica, not only the leader.
+type Server struct {
+	// Addr is the address to listen on, e.g. ":9090".
+	Addr string
+	// TLSConfig enables TLS. The server is plaintext when nil.
+	TLSConfig *tls.Config
+	// ClientCAs, when set, requires every client to present a certificate
+	// signed by one of these CAs. It needs TLSConfig.
+	ClientCAs *x509.CertPool
+	// Auth, when set, requires every OptimizerService call to carry a bearer
+	// token Auth accepts in its authorization metadata.
+	Auth Authorizer
+	// Service handles the OptimizerService calls.
+	Service *Service
+}
+
+var _ manager.LeaderElectionRunnable = &Server{}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Start listens on Addr and serves until ctx is cancelled.
+func (s *Server) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	log.FromContext(ctx).Info("serving gRPC API", "addr", listener.Addr().String(), "tls", s.TLSConfig != nil,
+		"clientCertificates", s.ClientCAs != nil, "bearerTokens", s.Auth != nil)
+	return s.Serve(ctx, listener)
+}
+
+// Serve serves on listener until ctx is cancelled and then stops gracefully.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	var opts []grpc.ServerOption
+	if s.TLSConfig != nil {
+		config := s.TLSConfig
+		if s.ClientCAs != nil {
+			config = config.Clone()
+			config.ClientCAs = s.ClientCAs
+			config.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(config)))
+	} else if s.ClientCAs != nil {
+		return errors.New("client certificates require TLS")
+	}
+	if s.Auth != nil {
+		opts = append(opts, grpc.ChainUnaryInterceptor(s.unaryAuth), grpc.ChainStreamInterceptor(s.streamAuth))
+	}
+	srv := grpc.NewServer(opts...)
+	grpcv1.RegisterOptimizerServiceServer(srv, s.Service)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Serve(listener)
+	}()
+	select {
+	case <-ctx.Done():
+		srv.GracefulStop()
+		return nil
+	case err := <-errCh:
+		if errors.Is(err, grpc.ErrServerStopped) {
+			return nil
+		}
+		return err
+	}
+}
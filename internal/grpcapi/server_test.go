@@ -0,0 +1,271 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	grpcv1 "github.com/OpScaleHub/K20s/api/grpc/v1"
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/controller"
+	"github.com/OpScaleHub/K20s/internal/dashboard"
+)
+
+var _ = Describe("OptimizerService", func() {
+	var service *Service
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(optimizerv1.AddToScheme(scheme)).To(Succeed())
+
+		labels := map[string]string{"app": "web"}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&optimizerv1.ResourceOptimizerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec: optimizerv1.ResourceOptimizerProfileSpec{
+					Selector:           metav1.LabelSelector{MatchLabels: labels},
+					OptimizationPolicy: "Resize",
+					CPUThresholds:      optimizerv1.ThresholdSpec{Min: 20, Max: 80},
+				},
+				Status: optimizerv1.ResourceOptimizerProfileStatus{
+					ObservedMetrics: map[string]string{"cpu_usage": "42.50"},
+					LastAction: &optimizerv1.ActionDetail{
+						Type:      controller.ResizeUpAction,
+						Timestamp: metav1.NewTime(time.Now().Add(-time.Minute)),
+					},
+				},
+			},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Labels: labels},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: labels},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: labels},
+						Spec: corev1.PodSpec{Containers: []corev1.Container{{
+							Name:  "web",
+							Image: "nginx",
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+							},
+						}}},
+					},
+				},
+			},
+		).Build()
+		service = &Service{Client: c}
+	})
+
+	It("should list recommendations with the observed utilization", func() {
+		resp, err := service.ListRecommendations(context.Background(), &grpcv1.ListRecommendationsRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Profiles).To(HaveLen(1))
+		Expect(resp.Profiles[0].GetProfile().GetName()).To(Equal("web"))
+		Expect(resp.Profiles[0].GetObservedCpuUtilization()).To(Equal(42.5))
+
+		resp, err = service.ListRecommendations(context.Background(), &grpcv1.ListRecommendationsRequest{Namespace: "other"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Profiles).To(BeEmpty())
+	})
+
+	It("should return NotFound for unknown profiles", func() {
+		_, err := service.GetProfileStatus(context.Background(), &grpcv1.GetProfileStatusRequest{
+			Profile: &grpcv1.ProfileRef{Namespace: "default", Name: "missing"},
+		})
+		Expect(status.Code(err)).To(Equal(codes.NotFound))
+
+		_, err = service.GetProfileStatus(context.Background(), &grpcv1.GetProfileStatusRequest{})
+		Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+	})
+
+	It("should simulate an action without executing it during cooldown", func() {
+		resp, err := service.SimulateAction(context.Background(), &grpcv1.SimulateActionRequest{
+			Profile:        &grpcv1.ProfileRef{Namespace: "default", Name: "web"},
+			CpuUtilization: 100,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.GetAction()).To(Equal(controller.ResizeUpAction))
+		Expect(resp.GetExecuted()).To(BeFalse())
+		Expect(resp.GetSkipReason()).To(Equal(controller.SkipReasonCooldown))
+		Expect(resp.GetCooldownRemaining().AsDuration()).To(BeNumerically(">", 3*time.Minute))
+		Expect(resp.GetTargets()).To(HaveLen(1))
+		// 100% / 50% target * 100m * 1.25 buffer
+		Expect(resp.GetTargets()[0].GetCpuRequest()).To(Equal("250m"))
+	})
+
+	It("should serve the service over gRPC", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		listener := bufconn.Listen(1 << 20)
+		server := &Server{Service: service}
+		go func() {
+			defer GinkgoRecover()
+			Expect(server.Serve(ctx, listener)).To(Succeed())
+		}()
+
+		conn, err := grpc.NewClient("passthrough:///bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return listener.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { _ = conn.Close() }()
+
+		resp, err := grpcv1.NewOptimizerServiceClient(conn).GetProfileStatus(ctx, &grpcv1.GetProfileStatusRequest{
+			Profile: &grpcv1.ProfileRef{Namespace: "default", Name: "web"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.GetLastAction().GetType()).To(Equal(controller.ResizeUpAction))
+		Expect(resp.GetObservedMetrics()).To(HaveKeyWithValue("cpu_usage", "42.50"))
+	})
+
+	It("should require a bearer token allowed to read the requested profiles", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		listener := bufconn.Listen(1 << 20)
+		auth := &fakeAuthorizer{}
+		server := &Server{Service: service, Auth: auth}
+		go func() {
+			defer GinkgoRecover()
+			Expect(server.Serve(ctx, listener)).To(Succeed())
+		}()
+		conn, err := grpc.NewClient("passthrough:///bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return listener.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { _ = conn.Close() }()
+		optimizer := grpcv1.NewOptimizerServiceClient(conn)
+		withToken := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer valid")
+		getStatus := &grpcv1.GetProfileStatusRequest{Profile: &grpcv1.ProfileRef{Namespace: "default", Name: "web"}}
+
+		_, err = optimizer.GetProfileStatus(ctx, getStatus)
+		Expect(status.Code(err)).To(Equal(codes.Unauthenticated))
+
+		_, err = optimizer.GetProfileStatus(withToken, getStatus)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(auth.checked).To(HaveExactElements("get default/web", "get default/web"))
+
+		_, err = optimizer.ListRecommendations(withToken, &grpcv1.ListRecommendationsRequest{})
+		Expect(status.Code(err)).To(Equal(codes.PermissionDenied))
+		Expect(auth.checked[len(auth.checked)-1]).To(Equal("list /"))
+
+		health, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health.GetStatus()).To(Equal(healthpb.HealthCheckResponse_SERVING))
+	})
+
+	It("should only answer clients with a certificate signed by the client CA", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ca, caKey := newCertificate(&x509.Certificate{
+			Subject: pkix.Name{CommonName: "k20s-ca"}, IsCA: true, BasicConstraintsValid: true,
+			KeyUsage: x509.KeyUsageCertSign,
+		}, nil, nil)
+		serverCert, serverKey := newCertificate(&x509.Certificate{
+			Subject: pkix.Name{CommonName: "k20s"}, DNSNames: []string{"k20s"},
+			ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		}, ca, caKey)
+		clientCert, clientKey := newCertificate(&x509.Certificate{
+			Subject:     pkix.Name{CommonName: "portal"},
+			ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}, ca, caKey)
+		pool := x509.NewCertPool()
+		pool.AddCert(ca)
+
+		listener := bufconn.Listen(1 << 20)
+		server := &Server{
+			Service: service,
+			TLSConfig: &tls.Config{MinVersion: tls.VersionTLS12, Certificates: []tls.Certificate{
+				{Certificate: [][]byte{serverCert.Raw}, PrivateKey: serverKey},
+			}},
+			ClientCAs: pool,
+		}
+		go func() {
+			defer GinkgoRecover()
+			Expect(server.Serve(ctx, listener)).To(Succeed())
+		}()
+		getStatus := func(certificates ...tls.Certificate) error {
+			conn, err := grpc.NewClient("passthrough:///bufnet",
+				grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+					return listener.DialContext(ctx)
+				}),
+				grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+					ServerName: "k20s", RootCAs: pool, Certificates: certificates,
+				})),
+			)
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = conn.Close() }()
+			_, err = grpcv1.NewOptimizerServiceClient(conn).GetProfileStatus(ctx, &grpcv1.GetProfileStatusRequest{
+				Profile: &grpcv1.ProfileRef{Namespace: "default", Name: "web"},
+			})
+			return err
+		}
+
+		Expect(getStatus()).To(HaveOccurred())
+		Expect(getStatus(tls.Certificate{Certificate: [][]byte{clientCert.Raw}, PrivateKey: clientKey})).To(Succeed())
+	})
+})
+
+// fakeAuthorizer accepts the bearer token "valid" for the default namespace
+// and records the access it was asked about.
+type fakeAuthorizer struct {
+	checked []string
+}
+
+func (f *fakeAuthorizer) Check(_ context.Context, token, verb, namespace, name string) error {
+	f.checked = append(f.checked, verb+" "+namespace+"/"+name)
+	if token != "valid" {
+		return dashboard.ErrUnauthenticated
+	}
+	if namespace != "default" {
+		return dashboard.ErrForbidden
+	}
+	return nil
+}
+
+// newCertificate issues a certificate from template signed by parent, or a
+// self-signed one when parent is nil.
+func newCertificate(template, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Minute)
+	template.NotAfter = time.Now().Add(time.Hour)
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	Expect(err).NotTo(HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	Expect(err).NotTo(HaveOccurred())
+	return cert, key
+}
@@ -0,0 +1,109 @@
+// Package monitoring maintains the Prometheus Operator objects that ship with
+// the controller, such as alerting rules and the ServiceMonitor scraping it.
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// fieldOwner is the server-side apply field manager used for every object
+	// maintained by this package.
+	fieldOwner = "k20s"
+
+	// resyncInterval is how often installed objects are re-applied so manual
+	// edits or deletions are reverted.
+	resyncInterval = 10 * time.Minute
+)
+
+// monitoringGroupVersion is the Prometheus Operator API group version.
+var monitoringGroupVersion = schema.GroupVersion{Group: "monitoring.coreos.com", Version: "v1"}
+
+// Options configures where installed objects are created.
+type Options struct {
+	// Namespace is the namespace the controller runs in. Objects are created here.
+	Namespace string
+	// PodName is the name of the controller pod. When set, installed objects are
+	// owned by the Deployment running the controller so they are garbage
+	// collected with it.
+	PodName string
+	// Labels are added to every installed object, e.g. to match the
+	// Prometheus Operator's rule or monitor selectors.
+	Labels map[string]string
+}
+
+// installer applies a single object built by build whenever the manager holds
+// leadership, and keeps it applied until the manager stops.
+type installer struct {
+	client client.Client
+	reader client.Reader
+	opts   Options
+	gvk    schema.GroupVersionKind
+	build  func(ctx context.Context) (*unstructured.Unstructured, error)
+}
+
+var _ manager.LeaderElectionRunnable = &installer{}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (i *installer) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable.
+func (i *installer) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithValues("kind", i.gvk.Kind)
+
+	if _, err := i.client.RESTMapper().RESTMapping(i.gvk.GroupKind(), i.gvk.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			logger.Info("Prometheus Operator CRD is not installed, skipping", "group", i.gvk.Group)
+			return nil
+		}
+		return err
+	}
+
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := i.apply(ctx); err != nil {
+			logger.Error(err, "unable to apply monitoring object")
+		}
+	}, resyncInterval)
+	return nil
+}
+
+func (i *installer) apply(ctx context.Context) error {
+	obj, err := i.build(ctx)
+	if err != nil {
+		return err
+	}
+	obj.SetGroupVersionKind(i.gvk)
+	obj.SetNamespace(i.opts.Namespace)
+
+	objLabels := map[string]string{
+		"app.kubernetes.io/name":       "k20s",
+		"app.kubernetes.io/managed-by": "k20s",
+	}
+	for k, v := range i.opts.Labels {
+		objLabels[k] = v
+	}
+	obj.SetLabels(objLabels)
+
+	if i.opts.PodName != "" {
+		owner, err := controllerOwner(ctx, i.reader, i.opts.Namespace, i.opts.PodName)
+		if err != nil {
+			return fmt.Errorf("resolving controller owner: %w", err)
+		}
+		obj.SetOwnerReferences([]metav1.OwnerReference{*owner})
+	}
+
+	return i.client.Apply(ctx, client.ApplyConfigurationFromUnstructured(obj), client.FieldOwner(fieldOwner), client.ForceOwnership)
+}
@@ -0,0 +1,51 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get
+
+// controllerOwner walks from the controller pod to the Deployment that manages it
+// and returns an owner reference to that Deployment. If the pod is not managed by
+// a Deployment, the reference points at the pod's closest controller instead.
+func controllerOwner(ctx context.Context, reader client.Reader, namespace, podName string) (*metav1.OwnerReference, error) {
+	var pod corev1.Pod
+	if err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: podName}, &pod); err != nil {
+		return nil, err
+	}
+	owner := metav1.GetControllerOf(&pod)
+	if owner == nil {
+		return nil, fmt.Errorf("pod %s/%s has no controller", namespace, podName)
+	}
+	if owner.Kind != "ReplicaSet" {
+		return ownerReference(owner), nil
+	}
+
+	var rs appsv1.ReplicaSet
+	if err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: owner.Name}, &rs); err != nil {
+		return nil, err
+	}
+	if deployment := metav1.GetControllerOf(&rs); deployment != nil {
+		return ownerReference(deployment), nil
+	}
+	return ownerReference(owner), nil
+}
+
+// ownerReference copies a controller reference into a plain, non-blocking owner
+// reference suitable for objects the controller creates.
+func ownerReference(ref *metav1.OwnerReference) *metav1.OwnerReference {
+	return &metav1.OwnerReference{
+		APIVersion: ref.APIVersion,
+		Kind:       ref.Kind,
+		Name:       ref.Name,
+		UID:        ref.UID,
+	}
+}
@@ -0,0 +1,78 @@
+package monitoring
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=prometheusrules,verbs=get;list;watch;create;update;patch
+
+// PrometheusRuleName is the name of the PrometheusRule holding the controller's
+// health alerts.
+const PrometheusRuleName = "k20s-controller-alerts"
+
+// NewPrometheusRuleInstaller returns a runnable that maintains a PrometheusRule
+// with alerts on the controller's own health metrics. Nothing is created when the
+// Prometheus Operator CRDs are not installed.
+func NewPrometheusRuleInstaller(c client.Client, reader client.Reader, opts Options) manager.Runnable {
+	return &installer{
+		client: c,
+		reader: reader,
+		opts:   opts,
+		gvk:    monitoringGroupVersion.WithKind("PrometheusRule"),
+		build:  buildPrometheusRule,
+	}
+}
+
+func buildPrometheusRule(context.Context) (*unstructured.Unstructured, error) {
+	rule := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"groups": []interface{}{
+				map[string]interface{}{
+					"name":  "k20s.controller",
+					"rules": alertRules(),
+				},
+			},
+		},
+	}}
+	rule.SetName(PrometheusRuleName)
+	return rule, nil
+}
+
+func alertRules() []interface{} {
+	return []interface{}{
+		alert("K20sPrometheusQueryFailing",
+			`sum by (namespace, profile) (rate(k20s_prometheus_query_errors_total[10m])) > 0`,
+			"15m", "warning",
+			"K20s cannot query Prometheus for profile {{ $labels.namespace }}/{{ $labels.profile }}",
+			"Metric queries for the profile have been failing for 15 minutes, so no optimization decisions are being made."),
+		alert("K20sActionsFailing",
+			`sum by (namespace, profile, action) (increase(k20s_action_errors_total[30m])) >= 3`,
+			"5m", "warning",
+			"K20s repeatedly fails to apply {{ $labels.action }} for {{ $labels.namespace }}/{{ $labels.profile }}",
+			"At least 3 {{ $labels.action }} actions failed in the last 30 minutes. Check the controller logs and its RBAC permissions."),
+		alert("K20sProfileDegraded",
+			`max by (namespace, profile) (k20s_profile_degraded) == 1`,
+			"30m", "warning",
+			"Profile {{ $labels.namespace }}/{{ $labels.profile }} has been Degraded for 30 minutes",
+			"The profile's Degraded condition explains why the controller cannot manage its targets."),
+	}
+}
+
+func alert(name, expr, forDuration, severity, summary, description string) map[string]interface{} {
+	return map[string]interface{}{
+		"alert": name,
+		"expr":  expr,
+		"for":   forDuration,
+		"labels": map[string]interface{}{
+			"severity": severity,
+		},
+		"annotations": map[string]interface{}{
+			"summary":     summary,
+			"description": description,
+		},
+	}
+}
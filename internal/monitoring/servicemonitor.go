@@ -0,0 +1,85 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// +kubebuilder:rbac:groups="",resources=services,verbs=get
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update;patch
+
+// ServiceMonitorName is the name of the ServiceMonitor scraping the controller.
+const ServiceMonitorName = "k20s-controller-manager-metrics"
+
+// MetricsEndpoint describes the Service exposing the controller's metrics.
+type MetricsEndpoint struct {
+	// ServiceName is the name of the metrics Service in the controller namespace.
+	ServiceName string
+	// PortName is the name of the Service port serving metrics.
+	PortName string
+	// Secure is set when metrics are served over HTTPS with authentication.
+	Secure bool
+}
+
+// NewServiceMonitorInstaller returns a runnable that maintains a ServiceMonitor
+// for the controller's metrics Service. Nothing is created when the Prometheus
+// Operator CRDs are not installed.
+func NewServiceMonitorInstaller(c client.Client, reader client.Reader, opts Options, endpoint MetricsEndpoint) manager.Runnable {
+	return &installer{
+		client: c,
+		reader: reader,
+		opts:   opts,
+		gvk:    monitoringGroupVersion.WithKind("ServiceMonitor"),
+		build: func(ctx context.Context) (*unstructured.Unstructured, error) {
+			return buildServiceMonitor(ctx, reader, opts.Namespace, endpoint)
+		},
+	}
+}
+
+func buildServiceMonitor(ctx context.Context, reader client.Reader, namespace string, endpoint MetricsEndpoint) (*unstructured.Unstructured, error) {
+	// Select the Service by its own labels so the monitor keeps working with
+	// whatever name prefix and labels the install applied.
+	var svc corev1.Service
+	if err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: endpoint.ServiceName}, &svc); err != nil {
+		return nil, fmt.Errorf("getting metrics service: %w", err)
+	}
+	if len(svc.Labels) == 0 {
+		return nil, fmt.Errorf("metrics service %s/%s has no labels to select it by", namespace, endpoint.ServiceName)
+	}
+	matchLabels := map[string]interface{}{}
+	for k, v := range svc.Labels {
+		matchLabels[k] = v
+	}
+
+	scrape := map[string]interface{}{
+		"path": "/metrics",
+		"port": endpoint.PortName,
+		// Keep the namespace/profile labels exported by the controller instead of
+		// renaming them to exported_namespace.
+		"honorLabels": true,
+	}
+	if endpoint.Secure {
+		scrape["scheme"] = "https"
+		scrape["bearerTokenFile"] = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+		scrape["tlsConfig"] = map[string]interface{}{
+			"insecureSkipVerify": true,
+		}
+	}
+
+	monitor := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"endpoints": []interface{}{scrape},
+			"selector": map[string]interface{}{
+				"matchLabels": matchLabels,
+			},
+		},
+	}}
+	monitor.SetName(ServiceMonitorName)
+	return monitor, nil
+}
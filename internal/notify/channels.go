@@ -0,0 +1,335 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// +kubebuilder:rbac:groups=optimizer.k20s.opscale.ir,resources=notificationchannels,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
+
+// secretRecheckInterval is how often the Secrets of an unchanged channel are
+// read again to pick up rotated credentials.
+const secretRecheckInterval = time.Minute
+
+// ChannelResolver turns the NotificationChannels referenced by a profile into
+// notifiers. Notifiers are cached per channel and rebuilt whenever the channel
+// changes, or one of its Secrets does as seen at most secretRecheckInterval
+// later, so stateful notifiers such as SMTP digests and PagerDuty failure
+// counts survive across reconciles. Channels are dropped once deleted when the
+// resolver watches them with WatchChannels.
+type ChannelResolver struct {
+	// Client reads NotificationChannels, typically from the manager's cache.
+	Client client.Reader
+	// SecretReader reads credentials. An uncached reader avoids watching every
+	// Secret in the cluster.
+	SecretReader client.Reader
+
+	mu       sync.Mutex
+	channels map[types.UID]*resolvedChannel
+	ctx      context.Context
+	cancel   context.CancelFunc
+	running  sync.WaitGroup
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+// resolvedChannel is a notifier built from a channel at a given version.
+type resolvedChannel struct {
+	version string
+	// channelVersion is the channel's resourceVersion and checked when its
+	// Secrets were last read.
+	channelVersion string
+	checked        time.Time
+	notifier       Notifier
+	// stop ends the channel's background work, e.g. flushing SMTP digests.
+	stop context.CancelFunc
+}
+
+var _ manager.LeaderElectionRunnable = &ChannelResolver{}
+
+// NewChannelResolver returns a resolver reading channels with c and their
+// credentials with secretReader.
+func NewChannelResolver(c, secretReader client.Reader) *ChannelResolver {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ChannelResolver{
+		Client:       c,
+		SecretReader: secretReader,
+		channels:     map[types.UID]*resolvedChannel{},
+		ctx:          ctx,
+		cancel:       cancel,
+		now:          time.Now,
+	}
+}
+
+// WatchChannels stops and forgets the notifier of every NotificationChannel
+// deleted from the informer cache, ending e.g. its SMTP digests.
+func (r *ChannelResolver) WatchChannels(ctx context.Context, informers cache.Informers) error {
+	informer, err := informers.GetInformer(ctx, &optimizerv1.NotificationChannel{})
+	if err != nil {
+		return err
+	}
+	_, err = informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		DeleteFunc: func(obj any) {
+			if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if channel, ok := obj.(*optimizerv1.NotificationChannel); ok {
+				r.forget(channel.UID)
+			}
+		},
+	})
+	return err
+}
+
+// forget stops and drops the notifier of a channel.
+func (r *ChannelResolver) forget(uid types.UID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cached, ok := r.channels[uid]; ok {
+		cached.stop()
+		delete(r.channels, uid)
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (r *ChannelResolver) NeedLeaderElection() bool {
+	return true
+}
+
+// Start waits for the manager to stop and then stops every channel, giving
+// digest notifiers the chance to send what they hold.
+func (r *ChannelResolver) Start(ctx context.Context) error {
+	<-ctx.Done()
+	r.cancel()
+	r.running.Wait()
+	return nil
+}
+
+// Notifier returns a notifier delivering to every channel referenced by the
+// profile. Channels that cannot be resolved are reported in the returned error
+// while the remaining channels are still returned.
+func (r *ChannelResolver) Notifier(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile) (Notifier, error) {
+	var notifiers Multi
+	var errs []error
+	for _, target := range profile.Spec.Notifications {
+		notifier, err := r.resolve(ctx, types.NamespacedName{Namespace: profile.Namespace, Name: target.ChannelRef.Name})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("notification channel %q: %w", target.ChannelRef.Name, err))
+			continue
+		}
+		notifiers = append(notifiers, notifier)
+	}
+	return notifiers, errors.Join(errs...)
+}
+
+// ReportNotifier returns a notifier delivering to every channel in the
+// namespace that lists report events. Unlike other events, reports are not
+// tied to a profile, so channels opt in through their events rather than
+// being referenced.
+func (r *ChannelResolver) ReportNotifier(ctx context.Context, namespace string) (Notifier, error) {
+	var channels optimizerv1.NotificationChannelList
+	if err := r.Client.List(ctx, &channels, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	var notifiers Multi
+	var errs []error
+	for _, channel := range channels.Items {
+		if !slices.Contains(channel.Spec.Events, optimizerv1.NotificationEventKind(EventReport)) {
+			continue
+		}
+		notifier, err := r.resolve(ctx, types.NamespacedName{Namespace: namespace, Name: channel.Name})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("notification channel %q: %w", channel.Name, err))
+			continue
+		}
+		notifiers = append(notifiers, notifier)
+	}
+	return notifiers, errors.Join(errs...)
+}
+
+func (r *ChannelResolver) resolve(ctx context.Context, key types.NamespacedName) (Notifier, error) {
+	var channel optimizerv1.NotificationChannel
+	if err := r.Client.Get(ctx, key, &channel); err != nil {
+		return nil, err
+	}
+
+	now := r.now()
+	r.mu.Lock()
+	if cached, ok := r.channels[channel.UID]; ok && cached.channelVersion == channel.ResourceVersion &&
+		now.Sub(cached.checked) < secretRecheckInterval {
+		r.mu.Unlock()
+		return cached.notifier, nil
+	}
+	r.mu.Unlock()
+
+	secrets, err := r.secrets(ctx, &channel)
+	if err != nil {
+		return nil, err
+	}
+	version := channel.ResourceVersion
+	for _, name := range slices.Sorted(maps.Keys(secrets)) {
+		version += "/" + secrets[name].ResourceVersion
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if cached, ok := r.channels[channel.UID]; ok {
+		if cached.version == version {
+			cached.checked = now
+			return cached.notifier, nil
+		}
+		cached.stop()
+		delete(r.channels, channel.UID)
+	}
+
+	notifier, err := buildChannelNotifier(&channel, secrets)
+	if err != nil {
+		return nil, err
+	}
+	resolved := &resolvedChannel{
+		version:        version,
+		channelVersion: channel.ResourceVersion,
+		checked:        now,
+		notifier:       notifier,
+		stop:           func() {},
+	}
+	if runnable, ok := notifier.(interface{ Start(context.Context) error }); ok {
+		channelCtx, stop := context.WithCancel(r.ctx)
+		resolved.stop = stop
+		r.running.Add(1)
+		go func() {
+			defer r.running.Done()
+			if err := runnable.Start(channelCtx); err != nil {
+				log.FromContext(ctx).Error(err, "notification channel stopped", "channel", key.String())
+			}
+		}()
+	}
+	if len(channel.Spec.Events) > 0 {
+		resolved.notifier = filtered{kinds: channel.Spec.Events, next: notifier}
+	}
+	r.channels[channel.UID] = resolved
+	return resolved.notifier, nil
+}
+
+// secrets returns the Secrets referenced by the channel, keyed by name.
+func (r *ChannelResolver) secrets(ctx context.Context, channel *optimizerv1.NotificationChannel) (map[string]*corev1.Secret, error) {
+	var refs []*corev1.SecretKeySelector
+	if channel.Spec.SMTP != nil && channel.Spec.SMTP.PasswordSecretRef != nil {
+		refs = append(refs, channel.Spec.SMTP.PasswordSecretRef)
+	}
+	if channel.Spec.PagerDuty != nil {
+		refs = append(refs, &channel.Spec.PagerDuty.RoutingKeySecretRef)
+	}
+	if channel.Spec.Grafana != nil {
+		refs = append(refs, &channel.Spec.Grafana.APITokenSecretRef)
+	}
+
+	secrets := map[string]*corev1.Secret{}
+	for _, ref := range refs {
+		var secret corev1.Secret
+		if err := r.SecretReader.Get(ctx, types.NamespacedName{Namespace: channel.Namespace, Name: ref.Name}, &secret); err != nil {
+			return nil, err
+		}
+		secrets[ref.Name] = &secret
+	}
+	return secrets, nil
+}
+
+// buildChannelNotifier creates the notifier described by a channel.
+func buildChannelNotifier(channel *optimizerv1.NotificationChannel, secrets map[string]*corev1.Secret) (Notifier, error) {
+	spec := channel.Spec
+	switch spec.Type {
+	case optimizerv1.ChannelTypeCloudEvents:
+		if spec.CloudEvents == nil {
+			return nil, errors.New("cloudEvents must be set")
+		}
+		return NewCloudEventsNotifier(spec.CloudEvents.SinkURL), nil
+	case optimizerv1.ChannelTypeSMTP:
+		if spec.SMTP == nil {
+			return nil, errors.New("smtp must be set")
+		}
+		config := SMTPConfig{
+			Addr:     spec.SMTP.Addr,
+			From:     spec.SMTP.From,
+			To:       spec.SMTP.To,
+			Username: spec.SMTP.Username,
+			Digest:   spec.SMTP.Digest,
+		}
+		if ref := spec.SMTP.PasswordSecretRef; ref != nil {
+			password, err := secretValue(secrets, ref)
+			if err != nil {
+				return nil, err
+			}
+			config.Password = password
+		}
+		return NewSMTPNotifier(config), nil
+	case optimizerv1.ChannelTypePagerDuty:
+		if spec.PagerDuty == nil {
+			return nil, errors.New("pagerDuty must be set")
+		}
+		routingKey, err := secretValue(secrets, &spec.PagerDuty.RoutingKeySecretRef)
+		if err != nil {
+			return nil, err
+		}
+		return NewPagerDutyNotifier(routingKey, int(spec.PagerDuty.FailureThreshold)), nil
+	case optimizerv1.ChannelTypeGrafana:
+		if spec.Grafana == nil {
+			return nil, errors.New("grafana must be set")
+		}
+		apiToken, err := secretValue(secrets, &spec.Grafana.APITokenSecretRef)
+		if err != nil {
+			return nil, err
+		}
+		return NewGrafanaNotifier(GrafanaConfig{
+			URL:          spec.Grafana.URL,
+			APIToken:     apiToken,
+			DashboardUID: spec.Grafana.DashboardUID,
+			Tags:         spec.Grafana.Tags,
+		}), nil
+	}
+	return nil, fmt.Errorf("unsupported channel type %q", spec.Type)
+}
+
+func secretValue(secrets map[string]*corev1.Secret, ref *corev1.SecretKeySelector) (string, error) {
+	secret, ok := secrets[ref.Name]
+	if !ok {
+		return "", fmt.Errorf("secret %q not found", ref.Name)
+	}
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("secret %q has no key %q", ref.Name, ref.Key)
+	}
+	return strings.TrimSpace(string(value)), nil
+}
+
+// filtered only forwards events of the given kinds.
+type filtered struct {
+	kinds []optimizerv1.NotificationEventKind
+	next  Notifier
+}
+
+// Notify implements Notifier.
+func (f filtered) Notify(ctx context.Context, event Event) error {
+	if !slices.Contains(f.kinds, optimizerv1.NotificationEventKind(event.Kind)) {
+		return nil
+	}
+	return f.next.Notify(ctx, event)
+}
@@ -0,0 +1,179 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("ChannelResolver", func() {
+	var (
+		k8sClient  client.Client
+		resolver   *ChannelResolver
+		secretGets int
+		now        time.Time
+		received   []Event
+		sink       *httptest.Server
+		profile    *optimizerv1.ResourceOptimizerProfile
+	)
+
+	BeforeEach(func() {
+		received = nil
+		sink = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var event Event
+			Expect(json.NewDecoder(r.Body).Decode(&event)).To(Succeed())
+			received = append(received, event)
+		}))
+
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(optimizerv1.AddToScheme(scheme)).To(Succeed())
+		k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&optimizerv1.NotificationChannel{
+				ObjectMeta: metav1.ObjectMeta{Name: "events", Namespace: "default", UID: "events-uid"},
+				Spec: optimizerv1.NotificationChannelSpec{
+					Type:        optimizerv1.ChannelTypeCloudEvents,
+					Events:      []optimizerv1.NotificationEventKind{"action"},
+					CloudEvents: &optimizerv1.CloudEventsChannel{SinkURL: sink.URL},
+				},
+			},
+			&optimizerv1.NotificationChannel{
+				ObjectMeta: metav1.ObjectMeta{Name: "pager", Namespace: "default", UID: "pager-uid"},
+				Spec: optimizerv1.NotificationChannelSpec{
+					Type: optimizerv1.ChannelTypePagerDuty,
+					PagerDuty: &optimizerv1.PagerDutyChannel{
+						RoutingKeySecretRef: corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "pagerduty"},
+							Key:                  "routingKey",
+						},
+					},
+				},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "pagerduty", Namespace: "default"},
+				Data:       map[string][]byte{"routingKey": []byte("secret-key\n")},
+			},
+		).Build()
+		secretGets = 0
+		secretReader := interceptor.NewClient(k8sClient.(client.WithWatch), interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				secretGets++
+				return c.Get(ctx, key, obj, opts...)
+			},
+		})
+		resolver = NewChannelResolver(k8sClient, secretReader)
+		now = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		resolver.now = func() time.Time { return now }
+
+		profile = &optimizerv1.ResourceOptimizerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		}
+	})
+
+	AfterEach(func() {
+		sink.Close()
+	})
+
+	ref := func(name string) optimizerv1.NotificationTarget {
+		return optimizerv1.NotificationTarget{ChannelRef: corev1.LocalObjectReference{Name: name}}
+	}
+
+	It("should deliver only the event kinds selected by the channel", func() {
+		profile.Spec.Notifications = []optimizerv1.NotificationTarget{ref("events")}
+		notifier, err := resolver.Notifier(context.Background(), profile)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(notifier.Notify(context.Background(), Event{Kind: EventRecommendation, ProfileName: "web"})).To(Succeed())
+		Expect(notifier.Notify(context.Background(), Event{Kind: EventAction, ProfileName: "web"})).To(Succeed())
+
+		Expect(received).To(HaveLen(1))
+		Expect(received[0].Kind).To(Equal(EventAction))
+	})
+
+	It("should reuse the notifier while the channel and its secrets are unchanged", func() {
+		profile.Spec.Notifications = []optimizerv1.NotificationTarget{ref("pager")}
+		_, err := resolver.Notifier(context.Background(), profile)
+		Expect(err).NotTo(HaveOccurred())
+		first := resolver.channels["pager-uid"].notifier.(*PagerDutyNotifier)
+		Expect(first.RoutingKey).To(Equal("secret-key"))
+
+		_, err = resolver.Notifier(context.Background(), profile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolver.channels["pager-uid"].notifier).To(BeIdenticalTo(first))
+		Expect(secretGets).To(Equal(1))
+
+		By("rotating the routing key")
+		var secret corev1.Secret
+		Expect(k8sClient.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "pagerduty"}, &secret)).To(Succeed())
+		secret.Data["routingKey"] = []byte("rotated-key")
+		Expect(k8sClient.Update(context.Background(), &secret)).To(Succeed())
+
+		_, err = resolver.Notifier(context.Background(), profile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolver.channels["pager-uid"].notifier).To(BeIdenticalTo(first))
+
+		now = now.Add(secretRecheckInterval)
+		_, err = resolver.Notifier(context.Background(), profile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolver.channels["pager-uid"].notifier.(*PagerDutyNotifier).RoutingKey).To(Equal("rotated-key"))
+		Expect(secretGets).To(Equal(2))
+	})
+
+	It("should stop the notifiers of deleted channels", func() {
+		informers := &informertest.FakeInformers{Scheme: k8sClient.Scheme()}
+		Expect(resolver.WatchChannels(context.Background(), informers)).To(Succeed())
+		profile.Spec.Notifications = []optimizerv1.NotificationTarget{ref("pager")}
+		_, err := resolver.Notifier(context.Background(), profile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolver.channels).To(HaveKey(types.UID("pager-uid")))
+
+		var channel optimizerv1.NotificationChannel
+		Expect(k8sClient.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "pager"}, &channel)).To(Succeed())
+		informer, err := informers.FakeInformerFor(context.Background(), &channel)
+		Expect(err).NotTo(HaveOccurred())
+		informer.Delete(&channel)
+		Expect(resolver.channels).NotTo(HaveKey(types.UID("pager-uid")))
+	})
+
+	It("should deliver reports only to channels listing them", func() {
+		notifier, err := resolver.ReportNotifier(context.Background(), "default")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(notifier).To(BeEmpty())
+
+		var channel optimizerv1.NotificationChannel
+		Expect(k8sClient.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "events"}, &channel)).To(Succeed())
+		channel.Spec.Events = append(channel.Spec.Events, EventReport)
+		Expect(k8sClient.Update(context.Background(), &channel)).To(Succeed())
+
+		notifier, err = resolver.ReportNotifier(context.Background(), "default")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(notifier.Notify(context.Background(), Event{Kind: EventReport, ProfileNamespace: "default", Report: "weekly-2025-w02"})).To(Succeed())
+		Expect(received).To(HaveLen(1))
+		Expect(received[0].Report).To(Equal("weekly-2025-w02"))
+	})
+
+	It("should report missing channels but keep the resolvable ones", func() {
+		profile.Spec.Notifications = []optimizerv1.NotificationTarget{ref("missing"), ref("events")}
+		notifier, err := resolver.Notifier(context.Background(), profile)
+		Expect(err).To(MatchError(ContainSubstring(`notification channel "missing"`)))
+
+		Expect(notifier.Notify(context.Background(), Event{Kind: EventAction, ProfileName: "web"})).To(Succeed())
+		Expect(received).To(HaveLen(1))
+	})
+})
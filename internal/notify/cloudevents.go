@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// cloudEventsSpecVersion is the CloudEvents specification version emitted.
+	cloudEventsSpecVersion = "1.0"
+	// cloudEventTypePrefix is prepended to the event kind to form the CloudEvent type.
+	cloudEventTypePrefix = "ir.opscale.k20s."
+	// defaultHTTPTimeout bounds every request made by the HTTP based notifiers.
+	defaultHTTPTimeout = 10 * time.Second
+)
+
+// CloudEventsNotifier posts events to an HTTP sink as CloudEvents in binary
+// content mode, which is accepted by Knative Eventing brokers and Argo Events
+// webhook sources.
+type CloudEventsNotifier struct {
+	// SinkURL is the HTTP endpoint receiving the events.
+	SinkURL string
+	// Client is the HTTP client used to deliver events. Defaults to a client with
+	// a short timeout.
+	Client *http.Client
+}
+
+// NewCloudEventsNotifier returns a notifier posting CloudEvents to sinkURL.
+func NewCloudEventsNotifier(sinkURL string) *CloudEventsNotifier {
+	return &CloudEventsNotifier{
+		SinkURL: sinkURL,
+		Client:  &http.Client{Timeout: defaultHTTPTimeout},
+	}
+}
+
+// Notify implements Notifier.
+func (n *CloudEventsNotifier) Notify(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.SinkURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Ce-Specversion", cloudEventsSpecVersion)
+	req.Header.Set("Ce-Id", uuid.NewString())
+	req.Header.Set("Ce-Type", cloudEventTypePrefix+event.Kind)
+	if event.Kind == EventReport {
+		req.Header.Set("Ce-Source", fmt.Sprintf("/apis/optimizer.k20s.opscale.ir/v1/namespaces/%s/optimizationreports/%s",
+			event.ProfileNamespace, event.Report))
+	} else {
+		req.Header.Set("Ce-Source", fmt.Sprintf("/apis/optimizer.k20s.opscale.ir/v1/namespaces/%s/resourceoptimizerprofiles/%s",
+			event.ProfileNamespace, event.ProfileName))
+		req.Header.Set("Ce-Subject", event.Action)
+	}
+	req.Header.Set("Ce-Time", event.Time.UTC().Format(time.RFC3339Nano))
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("cloudevents sink %s responded with %s", n.SinkURL, resp.Status)
+	}
+	return nil
+}
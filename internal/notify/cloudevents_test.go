@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CloudEventsNotifier", func() {
+	event := Event{
+		Kind:             EventAction,
+		Time:             time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+		ProfileNamespace: "default",
+		ProfileName:      "web",
+		Policy:           "Scale",
+		Action:           "ScaleUp",
+		MetricValue:      91.5,
+	}
+
+	It("should post the event in binary content mode", func() {
+		var headers http.Header
+		var body Event
+		sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			headers = r.Header.Clone()
+			Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer sink.Close()
+
+		Expect(NewCloudEventsNotifier(sink.URL).Notify(context.Background(), event)).To(Succeed())
+
+		Expect(headers.Get("Ce-Specversion")).To(Equal("1.0"))
+		Expect(headers.Get("Ce-Type")).To(Equal("ir.opscale.k20s.action"))
+		Expect(headers.Get("Ce-Source")).To(Equal("/apis/optimizer.k20s.opscale.ir/v1/namespaces/default/resourceoptimizerprofiles/web"))
+		Expect(headers.Get("Ce-Subject")).To(Equal("ScaleUp"))
+		Expect(headers.Get("Ce-Time")).To(Equal("2025-01-01T12:00:00Z"))
+		Expect(headers.Get("Ce-Id")).NotTo(BeEmpty())
+		Expect(body).To(Equal(event))
+	})
+
+	It("should fail when the sink rejects the event", func() {
+		sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer sink.Close()
+
+		Expect(NewCloudEventsNotifier(sink.URL).Notify(context.Background(), event)).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GrafanaConfig configures a GrafanaNotifier.
+type GrafanaConfig struct {
+	// URL is the base URL of Grafana, e.g. https://grafana.example.com.
+	URL string
+	// APIToken is a service account token allowed to create annotations.
+	APIToken string
+	// DashboardUID restricts annotations to one dashboard. They are
+	// organization wide when empty, and shown by dashboards querying their
+	// tags.
+	DashboardUID string
+	// Tags are added to the tags of every annotation.
+	Tags []string
+}
+
+// GrafanaNotifier creates a Grafana annotation for every applied or failed
+// action, so actions show up as markers on existing dashboards. Other events
+// are ignored.
+type GrafanaNotifier struct {
+	config GrafanaConfig
+	// Client is the HTTP client used to create annotations.
+	Client *http.Client
+}
+
+// NewGrafanaNotifier returns a notifier annotating the Grafana at config.URL.
+func NewGrafanaNotifier(config GrafanaConfig) *GrafanaNotifier {
+	return &GrafanaNotifier{
+		config: config,
+		Client: &http.Client{Timeout: defaultHTTPTimeout},
+	}
+}
+
+// grafanaAnnotation is the body of a request to Grafana's annotations API.
+type grafanaAnnotation struct {
+	DashboardUID string   `json:"dashboardUID,omitempty"`
+	Time         int64    `json:"time"`
+	Tags         []string `json:"tags"`
+	Text         string   `json:"text"`
+}
+
+// Notify implements Notifier.
+func (n *GrafanaNotifier) Notify(ctx context.Context, event Event) error {
+	if event.Kind != EventAction && event.Kind != EventActionFailed {
+		return nil
+	}
+	annotation := grafanaAnnotation{
+		DashboardUID: n.config.DashboardUID,
+		Time:         event.Time.UnixMilli(),
+		Tags: append([]string{"k20s", event.Action, "namespace:" + event.ProfileNamespace, "profile:" + event.ProfileName},
+			n.config.Tags...),
+		Text: fmt.Sprintf("K20s %s of %s/%s at %.2f%% CPU", event.Action, event.ProfileNamespace, event.ProfileName, event.MetricValue),
+	}
+	if event.Kind == EventActionFailed {
+		annotation.Tags = append(annotation.Tags, "failed")
+		annotation.Text += " failed"
+	}
+	if event.Details != "" {
+		annotation.Text += ": " + event.Details
+	}
+	data, err := json.Marshal(annotation)
+	if err != nil {
+		return err
+	}
+
+	url := strings.TrimSuffix(n.config.URL, "/") + "/api/annotations"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+n.config.APIToken)
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("grafana %s responded with %s", url, resp.Status)
+	}
+	return nil
+}
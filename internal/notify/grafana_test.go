@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GrafanaNotifier", func() {
+	var (
+		requests    int
+		auth        string
+		annotation  grafanaAnnotation
+		grafana     *httptest.Server
+		status      int
+		annotations *GrafanaNotifier
+	)
+	event := Event{
+		Kind:             EventAction,
+		Time:             time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+		ProfileNamespace: "default",
+		ProfileName:      "web",
+		Policy:           "Scale",
+		Action:           "ScaleUp",
+		MetricValue:      91.5,
+		Details:          "Deployment/web: 2 -> 3 replicas",
+	}
+
+	BeforeEach(func() {
+		requests, status = 0, http.StatusOK
+		grafana = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			Expect(r.URL.Path).To(Equal("/api/annotations"))
+			auth = r.Header.Get("Authorization")
+			Expect(json.NewDecoder(r.Body).Decode(&annotation)).To(Succeed())
+			w.WriteHeader(status)
+		}))
+		annotations = NewGrafanaNotifier(GrafanaConfig{
+			URL: grafana.URL + "/", APIToken: "token", DashboardUID: "web-dashboard", Tags: []string{"team:web"},
+		})
+	})
+
+	AfterEach(func() {
+		grafana.Close()
+	})
+
+	It("should annotate applied actions", func() {
+		Expect(annotations.Notify(context.Background(), event)).To(Succeed())
+		Expect(auth).To(Equal("Bearer token"))
+		Expect(annotation).To(Equal(grafanaAnnotation{
+			DashboardUID: "web-dashboard",
+			Time:         event.Time.UnixMilli(),
+			Tags:         []string{"k20s", "ScaleUp", "namespace:default", "profile:web", "team:web"},
+			Text:         "K20s ScaleUp of default/web at 91.50% CPU: Deployment/web: 2 -> 3 replicas",
+		}))
+	})
+
+	It("should tag failed actions", func() {
+		failed := event
+		failed.Kind, failed.Details = EventActionFailed, "conflict"
+		Expect(annotations.Notify(context.Background(), failed)).To(Succeed())
+		Expect(annotation.Tags).To(ContainElement("failed"))
+		Expect(annotation.Text).To(Equal("K20s ScaleUp of default/web at 91.50% CPU failed: conflict"))
+	})
+
+	It("should ignore recommendations", func() {
+		recommendation := event
+		recommendation.Kind = EventRecommendation
+		Expect(annotations.Notify(context.Background(), recommendation)).To(Succeed())
+		Expect(requests).To(BeZero())
+	})
+
+	It("should fail when Grafana rejects the annotation", func() {
+		status = http.StatusUnauthorized
+		Expect(annotations.Notify(context.Background(), event)).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,61 @@
+// Package notify publishes optimization decisions to external systems.
+package notify
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Kinds of events published by the controller.
+const (
+	// EventAction is published after an action was applied to the profile's targets.
+	EventAction = "action"
+	// EventRecommendation is published when a Recommend profile proposes an action.
+	EventRecommendation = "recommendation"
+	// EventActionFailed is published when an action could not be applied.
+	EventActionFailed = "action_failed"
+	// EventReport is published when the weekly OptimizationReport of a
+	// namespace is generated. It is only delivered to channels listing it in
+	// their events.
+	EventReport = "report"
+)
+
+// Event describes a single optimization decision, or a report summarizing
+// many.
+type Event struct {
+	Kind              string    `json:"kind"`
+	Time              time.Time `json:"time"`
+	ProfileNamespace  string    `json:"profileNamespace"`
+	ProfileName       string    `json:"profileName"`
+	ProfileGeneration int64     `json:"profileGeneration"`
+	Policy            string    `json:"policy"`
+	Action            string    `json:"action"`
+	// MetricValue is the observed metric value the decision was based on.
+	MetricValue float64 `json:"metricValue"`
+	Details     string  `json:"details,omitempty"`
+	// Report is the name of the OptimizationReport of a report event, whose
+	// Details hold the report as Markdown.
+	Report string `json:"report,omitempty"`
+}
+
+// Notifier delivers events to a destination. Implementations must be safe for
+// concurrent use.
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// Multi fans an event out to several notifiers. Every notifier is attempted and
+// their errors are joined.
+type Multi []Notifier
+
+// Notify implements Notifier.
+func (m Multi) Notify(ctx context.Context, event Event) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
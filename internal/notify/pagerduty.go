@@ -0,0 +1,151 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// PagerDutyEventsURL is the PagerDuty Events API v2 endpoint.
+	PagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+	// DefaultPagerDutyFailureThreshold is the number of consecutive failed
+	// actions of a profile that triggers an incident.
+	DefaultPagerDutyFailureThreshold = 3
+)
+
+// PagerDutyNotifier triggers a PagerDuty incident once a profile's actions have
+// failed a number of times in a row, and resolves it when an action succeeds
+// again. A trigger that cannot be delivered is retried with the next failure.
+// Other events are ignored.
+type PagerDutyNotifier struct {
+	// RoutingKey is the integration key of the PagerDuty service.
+	RoutingKey string
+	// FailureThreshold is the number of consecutive failures that triggers an
+	// incident.
+	FailureThreshold int
+	// URL defaults to PagerDutyEventsURL.
+	URL string
+	// Client is the HTTP client used to deliver events.
+	Client *http.Client
+
+	mu       sync.Mutex
+	failures map[types.NamespacedName]int
+	// triggered holds the profiles with an incident PagerDuty accepted.
+	triggered map[types.NamespacedName]bool
+}
+
+// NewPagerDutyNotifier returns a notifier sending events to the PagerDuty
+// service identified by routingKey.
+func NewPagerDutyNotifier(routingKey string, failureThreshold int) *PagerDutyNotifier {
+	if failureThreshold < 1 {
+		failureThreshold = DefaultPagerDutyFailureThreshold
+	}
+	return &PagerDutyNotifier{
+		RoutingKey:       routingKey,
+		FailureThreshold: failureThreshold,
+		URL:              PagerDutyEventsURL,
+		Client:           &http.Client{Timeout: defaultHTTPTimeout},
+		failures:         map[types.NamespacedName]int{},
+		triggered:        map[types.NamespacedName]bool{},
+	}
+}
+
+// pagerDutyEvent is the body of a PagerDuty Events API v2 request.
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string `json:"summary"`
+	Source        string `json:"source"`
+	Severity      string `json:"severity"`
+	Component     string `json:"component"`
+	Group         string `json:"group"`
+	Class         string `json:"class"`
+	CustomDetails Event  `json:"custom_details"`
+}
+
+// Notify implements Notifier.
+func (n *PagerDutyNotifier) Notify(ctx context.Context, event Event) error {
+	key := types.NamespacedName{Namespace: event.ProfileNamespace, Name: event.ProfileName}
+	dedupKey := "k20s/" + key.String()
+
+	n.mu.Lock()
+	var request *pagerDutyEvent
+	switch event.Kind {
+	case EventActionFailed:
+		n.failures[key]++
+		if n.failures[key] >= n.FailureThreshold && !n.triggered[key] {
+			request = &pagerDutyEvent{
+				EventAction: "trigger",
+				Payload: &pagerDutyPayload{
+					Summary:       fmt.Sprintf("K20s failed to apply %s for profile %s %d times in a row", event.Action, key, n.failures[key]),
+					Source:        key.String(),
+					Severity:      "error",
+					Component:     "k20s",
+					Group:         event.ProfileNamespace,
+					Class:         event.Action,
+					CustomDetails: event,
+				},
+			}
+		}
+	case EventAction:
+		if n.triggered[key] {
+			request = &pagerDutyEvent{EventAction: "resolve"}
+		}
+		delete(n.failures, key)
+	}
+	n.mu.Unlock()
+
+	if request == nil {
+		return nil
+	}
+	request.RoutingKey = n.RoutingKey
+	request.DedupKey = dedupKey
+	if err := n.send(ctx, request); err != nil {
+		return err
+	}
+
+	// Only delivered events change whether the profile has an incident, so
+	// that a failed trigger is sent again and a failed resolve is retried with
+	// the next successful action.
+	n.mu.Lock()
+	n.triggered[key] = request.EventAction == "trigger"
+	if !n.triggered[key] {
+		delete(n.triggered, key)
+	}
+	n.mu.Unlock()
+	return nil
+}
+
+func (n *PagerDutyNotifier) send(ctx context.Context, event *pagerDutyEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("pagerduty responded with %s", resp.Status)
+	}
+	return nil
+}
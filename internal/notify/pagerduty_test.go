@@ -0,0 +1,94 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PagerDutyNotifier", func() {
+	var (
+		received []pagerDutyEvent
+		server   *httptest.Server
+		notifier *PagerDutyNotifier
+	)
+
+	BeforeEach(func() {
+		received = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var event pagerDutyEvent
+			Expect(json.NewDecoder(r.Body).Decode(&event)).To(Succeed())
+			received = append(received, event)
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		notifier = NewPagerDutyNotifier("routing-key", 2)
+		notifier.URL = server.URL
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	event := func(kind string) Event {
+		return Event{Kind: kind, ProfileNamespace: "default", ProfileName: "web", Action: "ScaleUp"}
+	}
+
+	It("should trigger an incident once the failure threshold is reached", func() {
+		Expect(notifier.Notify(context.Background(), event(EventActionFailed))).To(Succeed())
+		Expect(received).To(BeEmpty())
+
+		Expect(notifier.Notify(context.Background(), event(EventActionFailed))).To(Succeed())
+		Expect(received).To(HaveLen(1))
+		Expect(received[0].EventAction).To(Equal("trigger"))
+		Expect(received[0].RoutingKey).To(Equal("routing-key"))
+		Expect(received[0].DedupKey).To(Equal("k20s/default/web"))
+
+		// Further failures do not page again.
+		Expect(notifier.Notify(context.Background(), event(EventActionFailed))).To(Succeed())
+		Expect(received).To(HaveLen(1))
+	})
+
+	It("should resolve the incident when an action succeeds again", func() {
+		for range 2 {
+			Expect(notifier.Notify(context.Background(), event(EventActionFailed))).To(Succeed())
+		}
+		Expect(notifier.Notify(context.Background(), event(EventAction))).To(Succeed())
+
+		Expect(received).To(HaveLen(2))
+		Expect(received[1].EventAction).To(Equal("resolve"))
+		Expect(received[1].DedupKey).To(Equal("k20s/default/web"))
+	})
+
+	It("should retry a trigger that could not be delivered", func() {
+		status := http.StatusTooManyRequests
+		server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var event pagerDutyEvent
+			Expect(json.NewDecoder(r.Body).Decode(&event)).To(Succeed())
+			received = append(received, event)
+			w.WriteHeader(status)
+		})
+		Expect(notifier.Notify(context.Background(), event(EventActionFailed))).To(Succeed())
+		Expect(notifier.Notify(context.Background(), event(EventActionFailed))).To(MatchError(ContainSubstring("429")))
+
+		status = http.StatusAccepted
+		Expect(notifier.Notify(context.Background(), event(EventActionFailed))).To(Succeed())
+		Expect(notifier.Notify(context.Background(), event(EventActionFailed))).To(Succeed())
+		Expect(received).To(HaveLen(2))
+		Expect(received[1].EventAction).To(Equal("trigger"))
+
+		Expect(notifier.Notify(context.Background(), event(EventAction))).To(Succeed())
+		Expect(received).To(HaveLen(3))
+		Expect(received[2].EventAction).To(Equal("resolve"))
+	})
+
+	It("should not resolve anything when no incident was triggered", func() {
+		Expect(notifier.Notify(context.Background(), event(EventActionFailed))).To(Succeed())
+		Expect(notifier.Notify(context.Background(), event(EventAction))).To(Succeed())
+		Expect(notifier.Notify(context.Background(), event(EventRecommendation))).To(Succeed())
+		Expect(received).To(BeEmpty())
+	})
+})
@@ -0,0 +1,238 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// DefaultDigestInterval is how often digest mails are sent.
+	DefaultDigestInterval = 24 * time.Hour
+
+	// defaultSMTPTimeout bounds the delivery of a single mail, from dialing the
+	// server to the end of the SMTP session.
+	defaultSMTPTimeout = 30 * time.Second
+)
+
+// SMTPConfig configures the SMTP notifier.
+type SMTPConfig struct {
+	// Addr is the host:port of the SMTP server.
+	Addr string
+	// From is the sender address.
+	From string
+	// To lists the recipient addresses.
+	To []string
+	// Username and Password enable PLAIN authentication when Username is set.
+	Username string
+	Password string
+	// Digest batches events into one mail per profile per DigestInterval instead
+	// of sending a mail per event.
+	Digest bool
+	// DigestInterval defaults to DefaultDigestInterval.
+	DigestInterval time.Duration
+}
+
+// SMTPNotifier mails events to a fixed list of recipients, either one mail per
+// event or, in digest mode, one summary mail per profile per interval. In digest
+// mode it must be added to the manager so pending digests are sent.
+type SMTPNotifier struct {
+	config SMTPConfig
+	// send delivers a message; replaced in tests.
+	send func(ctx context.Context, addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+	mu      sync.Mutex
+	pending map[types.NamespacedName][]Event
+}
+
+var _ manager.LeaderElectionRunnable = &SMTPNotifier{}
+
+// NewSMTPNotifier returns a notifier delivering events by mail.
+func NewSMTPNotifier(config SMTPConfig) *SMTPNotifier {
+	if config.DigestInterval <= 0 {
+		config.DigestInterval = DefaultDigestInterval
+	}
+	return &SMTPNotifier{
+		config:  config,
+		send:    sendMail,
+		pending: map[types.NamespacedName][]Event{},
+	}
+}
+
+// Notify implements Notifier.
+func (n *SMTPNotifier) Notify(ctx context.Context, event Event) error {
+	if event.Kind == EventReport {
+		// Reports are digests already.
+		subject := fmt.Sprintf("[K20s] Weekly optimization report for %s", event.ProfileNamespace)
+		return n.mail(ctx, subject, []byte(strings.ReplaceAll(event.Details, "\n", "\r\n")))
+	}
+	if n.config.Digest {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		key := types.NamespacedName{Namespace: event.ProfileNamespace, Name: event.ProfileName}
+		n.pending[key] = append(n.pending[key], event)
+		return nil
+	}
+
+	subject := fmt.Sprintf("[K20s] %s %s for %s/%s", event.Action, event.Kind, event.ProfileNamespace, event.ProfileName)
+	var body bytes.Buffer
+	writeEventLine(&body, event)
+	return n.mail(ctx, subject, body.Bytes())
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Events are only
+// produced by the leader, so only the leader holds pending digests.
+func (n *SMTPNotifier) NeedLeaderElection() bool {
+	return true
+}
+
+// Start sends pending digests every DigestInterval and once more on shutdown.
+// It returns immediately when digest mode is disabled.
+func (n *SMTPNotifier) Start(ctx context.Context) error {
+	if !n.config.Digest {
+		return nil
+	}
+	ticker := time.NewTicker(n.config.DigestInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			n.flush(context.Background())
+			return nil
+		case <-ticker.C:
+			n.flush(ctx)
+		}
+	}
+}
+
+// flush mails one digest per profile with pending events.
+func (n *SMTPNotifier) flush(ctx context.Context) {
+	n.mu.Lock()
+	pending := n.pending
+	n.pending = map[types.NamespacedName][]Event{}
+	n.mu.Unlock()
+
+	keys := make([]types.NamespacedName, 0, len(pending))
+	for key := range pending {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	for _, key := range keys {
+		events := pending[key]
+		subject := fmt.Sprintf("[K20s] Digest for %s: %d events", key, len(events))
+		var body bytes.Buffer
+		fmt.Fprintf(&body, "Optimization activity for profile %s since the last digest:\r\n\r\n", key)
+		for _, event := range events {
+			writeEventLine(&body, event)
+		}
+		if err := n.mail(ctx, subject, body.Bytes()); err != nil {
+			log.FromContext(ctx).Error(err, "unable to send digest mail", "profile", key.String())
+		}
+	}
+}
+
+func (n *SMTPNotifier) mail(ctx context.Context, subject string, body []byte) error {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.config.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.config.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.Write(body)
+
+	var auth smtp.Auth
+	if n.config.Username != "" {
+		host, _, err := net.SplitHostPort(n.config.Addr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", n.config.Username, n.config.Password, host)
+	}
+	return n.send(ctx, n.config.Addr, auth, n.config.From, n.config.To, msg.Bytes())
+}
+
+// sendMail is smtp.SendMail bounded by ctx and defaultSMTPTimeout, so that a
+// stalled server cannot block the reconcile sending the notification.
+func sendMail(ctx context.Context, addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, defaultSMTPTimeout)
+	defer cancel()
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		return err
+	}
+	// Unblock the session as soon as ctx is canceled, not only at its deadline.
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer func() { _ = c.Close() }()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if a != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err := c.Auth(a); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		if err := c.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+func writeEventLine(buf *bytes.Buffer, event Event) {
+	fmt.Fprintf(buf, "%s  %-14s %-10s metric=%.2f",
+		event.Time.UTC().Format(time.RFC3339), event.Kind, event.Action, event.MetricValue)
+	if event.Details != "" {
+		fmt.Fprintf(buf, "  %s", event.Details)
+	}
+	buf.WriteString("\r\n")
+}
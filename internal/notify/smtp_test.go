@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/smtp"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// sentMail captures a message handed to the SMTP transport.
+type sentMail struct {
+	addr string
+	from string
+	to   []string
+	msg  string
+}
+
+var _ = Describe("SMTPNotifier", func() {
+	var (
+		sent     []sentMail
+		notifier *SMTPNotifier
+	)
+
+	newNotifier := func(digest bool) *SMTPNotifier {
+		n := NewSMTPNotifier(SMTPConfig{
+			Addr:   "smtp.example.com:587",
+			From:   "k20s@example.com",
+			To:     []string{"team@example.com"},
+			Digest: digest,
+		})
+		n.send = func(_ context.Context, addr string, _ smtp.Auth, from string, to []string, msg []byte) error {
+			sent = append(sent, sentMail{addr: addr, from: from, to: to, msg: string(msg)})
+			return nil
+		}
+		return n
+	}
+
+	event := func(profile, action string) Event {
+		return Event{
+			Kind:             EventAction,
+			Time:             time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+			ProfileNamespace: "default",
+			ProfileName:      profile,
+			Action:           action,
+			MetricValue:      85,
+		}
+	}
+
+	BeforeEach(func() {
+		sent = nil
+	})
+
+	It("should send one mail per event outside digest mode", func() {
+		notifier = newNotifier(false)
+		Expect(notifier.Notify(context.Background(), event("web", "ScaleUp"))).To(Succeed())
+
+		Expect(sent).To(HaveLen(1))
+		Expect(sent[0].addr).To(Equal("smtp.example.com:587"))
+		Expect(sent[0].to).To(ConsistOf("team@example.com"))
+		Expect(sent[0].msg).To(ContainSubstring("Subject: [K20s] ScaleUp action for default/web"))
+	})
+
+	It("should batch events into one mail per profile in digest mode", func() {
+		notifier = newNotifier(true)
+		Expect(notifier.Notify(context.Background(), event("web", "ScaleUp"))).To(Succeed())
+		Expect(notifier.Notify(context.Background(), event("web", "ScaleDown"))).To(Succeed())
+		Expect(notifier.Notify(context.Background(), event("api", "ResizeUp"))).To(Succeed())
+		Expect(sent).To(BeEmpty())
+
+		notifier.flush(context.Background())
+
+		Expect(sent).To(HaveLen(2))
+		Expect(sent[0].msg).To(ContainSubstring("Subject: [K20s] Digest for default/api: 1 events"))
+		Expect(sent[1].msg).To(ContainSubstring("Subject: [K20s] Digest for default/web: 2 events"))
+		Expect(sent[1].msg).To(ContainSubstring("ScaleUp"))
+		Expect(sent[1].msg).To(ContainSubstring("ScaleDown"))
+
+		notifier.flush(context.Background())
+		Expect(sent).To(HaveLen(2))
+	})
+	It("should mail reports right away in digest mode", func() {
+		notifier = newNotifier(true)
+		Expect(notifier.Notify(context.Background(), Event{Kind: EventReport, ProfileNamespace: "default",
+			Details: "# Weekly report\n\n- 3 actions\n"})).To(Succeed())
+
+		Expect(sent).To(HaveLen(1))
+		Expect(sent[0].msg).To(ContainSubstring("Subject: [K20s] Weekly optimization report for default"))
+		Expect(sent[0].msg).To(HaveSuffix("# Weekly report\r\n\r\n- 3 actions\r\n"))
+	})
+
+	It("should give up on a server that stalls", func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { _ = listener.Close() }()
+		go func() {
+			// Accept the connection but never greet, until the client hangs up.
+			if conn, err := listener.Accept(); err == nil {
+				_, _ = io.Copy(io.Discard, conn)
+				_ = conn.Close()
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		Expect(sendMail(ctx, listener.Addr().String(), nil, "k20s@example.com", []string{"team@example.com"}, []byte("hi"))).
+			NotTo(Succeed())
+		Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+	})
+})
@@ -0,0 +1,96 @@
+// Package preflight verifies at startup that the cluster is set up for the
+// controller, so a missing CRD or RBAC rule stops it with one clear error
+// instead of a stream of failing reconciles.
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// profileResource is the plural name of the ResourceOptimizerProfile CRD.
+const profileResource = "resourceoptimizerprofiles"
+
+// permission is an action the controller needs in every namespace.
+type permission struct {
+	group, resource, subresource, verb string
+}
+
+// requiredPermissions are the cluster-wide permissions reconciles fail
+// without. The manager-role ClusterRole in config/rbac grants them.
+var requiredPermissions = []permission{
+	{group: optimizerv1.GroupVersion.Group, resource: profileResource, verb: "list"},
+	{group: optimizerv1.GroupVersion.Group, resource: profileResource, verb: "watch"},
+	{group: optimizerv1.GroupVersion.Group, resource: profileResource, subresource: "status", verb: "update"},
+	{group: "apps", resource: "deployments", verb: "list"},
+	{group: "apps", resource: "deployments", verb: "patch"},
+	{group: "apps", resource: "statefulsets", verb: "list"},
+	{group: "apps", resource: "statefulsets", verb: "patch"},
+	{group: "", resource: "pods", verb: "list"},
+}
+
+// Check verifies that the ResourceOptimizerProfile CRD is served and that the
+// controller's identity holds the permissions reconciles need. The error lists
+// every problem found and how to fix it.
+func Check(ctx context.Context, disc discovery.DiscoveryInterface, reviews authorizationv1client.SelfSubjectAccessReviewInterface) error {
+	var errs []error
+	if err := checkCRD(disc); err != nil {
+		errs = append(errs, err)
+	}
+	for _, p := range requiredPermissions {
+		review, err := reviews.Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Group:       p.group,
+					Resource:    p.resource,
+					Subresource: p.subresource,
+					Verb:        p.verb,
+				},
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("checking permissions: %w", err)
+		}
+		if !review.Status.Allowed {
+			errs = append(errs, fmt.Errorf("not allowed to %s %s in all namespaces: "+
+				"apply config/rbac or bind the manager-role ClusterRole to the controller's service account", p.verb, p.String()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func checkCRD(disc discovery.DiscoveryInterface) error {
+	groupVersion := optimizerv1.GroupVersion.String()
+	resources, err := disc.ServerResourcesForGroupVersion(groupVersion)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("looking up %s: %w", groupVersion, err)
+	}
+	if resources != nil {
+		for _, resource := range resources.APIResources {
+			if resource.Name == profileResource {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("the ResourceOptimizerProfile CRD (%s) is not installed: run \"make install\" or apply config/crd", groupVersion)
+}
+
+func (p permission) String() string {
+	name := p.resource
+	if p.group != "" {
+		name += "." + p.group
+	}
+	if p.subresource != "" {
+		name += "/" + p.subresource
+	}
+	return name
+}
@@ -0,0 +1,52 @@
+package preflight
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+var _ = Describe("Check", func() {
+	var clientset *fake.Clientset
+
+	// allow answers access reviews, denying the given verb on resource.
+	allow := func(deniedVerb, deniedResource string) {
+		clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+			attrs := review.Spec.ResourceAttributes
+			review.Status.Allowed = attrs.Verb != deniedVerb || attrs.Resource != deniedResource
+			return true, review, nil
+		})
+	}
+
+	BeforeEach(func() {
+		clientset = fake.NewClientset()
+		clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{{
+			GroupVersion: optimizerv1.GroupVersion.String(),
+			APIResources: []metav1.APIResource{{Name: "resourceoptimizerprofiles"}},
+		}}
+	})
+
+	It("should pass when the CRD is installed and every permission is granted", func() {
+		allow("", "")
+		Expect(Check(context.Background(), clientset.Discovery(), clientset.AuthorizationV1().SelfSubjectAccessReviews())).To(Succeed())
+	})
+
+	It("should report a missing CRD and missing permissions together", func() {
+		clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = nil
+		allow("patch", "statefulsets")
+		err := Check(context.Background(), clientset.Discovery(), clientset.AuthorizationV1().SelfSubjectAccessReviews())
+		Expect(err).To(MatchError(ContainSubstring("the ResourceOptimizerProfile CRD (optimizer.k20s.opscale.ir/v1) is not installed")))
+		Expect(err).To(MatchError(ContainSubstring("not allowed to patch statefulsets.apps in all namespaces")))
+		Expect(err).NotTo(MatchError(ContainSubstring("deployments")))
+	})
+})
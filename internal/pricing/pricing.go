@@ -0,0 +1,114 @@
+// Package pricing provides the prices used to turn the CPU requested beyond
+// the controller's recommendations into a cost.
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get
+
+// DefaultRegion is the key of the price applied to regions a table does not list.
+const DefaultRegion = "default"
+
+// refreshInterval is how long a table read from a ConfigMap is reused before
+// the ConfigMap is read again.
+const refreshInterval = time.Minute
+
+// Provider returns the hourly price of one requested CPU core.
+type Provider interface {
+	CPUCorePrice(ctx context.Context, region string) (float64, error)
+}
+
+// Table holds the hourly price of one CPU core per region.
+type Table map[string]float64
+
+// CPUCorePrice returns the price listed for region, or the DefaultRegion price
+// when the region is not listed.
+func (t Table) CPUCorePrice(_ context.Context, region string) (float64, error) {
+	if price, ok := t[region]; ok {
+		return price, nil
+	}
+	if price, ok := t[DefaultRegion]; ok {
+		return price, nil
+	}
+	return 0, fmt.Errorf("no CPU price for region %q and no %q price", region, DefaultRegion)
+}
+
+// ParseTable parses ConfigMap data mapping regions to the hourly price of one
+// CPU core, e.g. us-east-1: "0.0316".
+func ParseTable(data map[string]string) (Table, error) {
+	table := Table{}
+	for region, value := range data {
+		price, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || price < 0 {
+			return nil, fmt.Errorf("invalid CPU price %q for region %q", value, region)
+		}
+		table[region] = price
+	}
+	return table, nil
+}
+
+// ConfigMapProvider prices CPU from a Table kept in the data of a ConfigMap.
+// The ConfigMap is read at most once a minute, so prices can be edited without
+// restarting the controller.
+type ConfigMapProvider struct {
+	// Reader reads the ConfigMap. An uncached reader avoids watching every
+	// ConfigMap in the cluster.
+	Reader client.Reader
+	// ConfigMap is the namespace and name of the ConfigMap.
+	ConfigMap types.NamespacedName
+
+	mu    sync.Mutex
+	table Table
+	read  time.Time
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+var _ Provider = &ConfigMapProvider{}
+
+// NewConfigMapProvider returns a provider reading prices from configMap with
+// reader.
+func NewConfigMapProvider(reader client.Reader, configMap types.NamespacedName) *ConfigMapProvider {
+	return &ConfigMapProvider{Reader: reader, ConfigMap: configMap, now: time.Now}
+}
+
+// CPUCorePrice implements Provider.
+func (p *ConfigMapProvider) CPUCorePrice(ctx context.Context, region string) (float64, error) {
+	table, err := p.load(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return table.CPUCorePrice(ctx, region)
+}
+
+// load returns the table of the ConfigMap, reading it again once the last read
+// is older than refreshInterval.
+func (p *ConfigMapProvider) load(ctx context.Context) (Table, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	if p.table != nil && now.Sub(p.read) < refreshInterval {
+		return p.table, nil
+	}
+	var configMap corev1.ConfigMap
+	if err := p.Reader.Get(ctx, p.ConfigMap, &configMap); err != nil {
+		return nil, fmt.Errorf("reading prices from ConfigMap %s: %w", p.ConfigMap, err)
+	}
+	table, err := ParseTable(configMap.Data)
+	if err != nil {
+		return nil, fmt.Errorf("ConfigMap %s: %w", p.ConfigMap, err)
+	}
+	p.table, p.read = table, now
+	return table, nil
+}
@@ -0,0 +1,86 @@
+package pricing
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("Table", func() {
+	It("should fall back to the default price for unlisted regions", func() {
+		table, err := ParseTable(map[string]string{"us-east-1": "0.0316", DefaultRegion: " 0.04 "})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(table.CPUCorePrice(context.Background(), "us-east-1")).To(Equal(0.0316))
+		Expect(table.CPUCorePrice(context.Background(), "eu-west-1")).To(Equal(0.04))
+		delete(table, DefaultRegion)
+		_, err = table.CPUCorePrice(context.Background(), "eu-west-1")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject prices that are not non-negative numbers", func() {
+		_, err := ParseTable(map[string]string{"us-east-1": "$0.03"})
+		Expect(err).To(HaveOccurred())
+		_, err = ParseTable(map[string]string{"us-east-1": "-1"})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("ConfigMapProvider", func() {
+	var (
+		k8sClient client.WithWatch
+		provider  *ConfigMapProvider
+		configMap *corev1.ConfigMap
+		now       time.Time
+		gets      int
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "k20s-pricing", Namespace: "k20s-system"},
+			Data:       map[string]string{"us-east-1": "0.03"},
+		}
+		k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap).Build()
+		gets = 0
+		reader := interceptor.NewClient(k8sClient, interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				gets++
+				return c.Get(ctx, key, obj, opts...)
+			},
+		})
+		provider = NewConfigMapProvider(reader, types.NamespacedName{Namespace: "k20s-system", Name: "k20s-pricing"})
+		now = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		provider.now = func() time.Time { return now }
+	})
+
+	It("should re-read edited prices at most once a minute", func() {
+		Expect(provider.CPUCorePrice(context.Background(), "us-east-1")).To(Equal(0.03))
+
+		configMap.Data["us-east-1"] = "0.05"
+		Expect(k8sClient.Update(context.Background(), configMap)).To(Succeed())
+		Expect(provider.CPUCorePrice(context.Background(), "us-east-1")).To(Equal(0.03))
+		Expect(gets).To(Equal(1))
+
+		now = now.Add(refreshInterval)
+		Expect(provider.CPUCorePrice(context.Background(), "us-east-1")).To(Equal(0.05))
+		Expect(gets).To(Equal(2))
+	})
+
+	It("should report a missing ConfigMap", func() {
+		Expect(k8sClient.Delete(context.Background(), configMap)).To(Succeed())
+		_, err := provider.CPUCorePrice(context.Background(), "us-east-1")
+		Expect(err).To(MatchError(ContainSubstring("k20s-system/k20s-pricing")))
+	})
+})
@@ -0,0 +1,119 @@
+// Package report generates the weekly OptimizationReports summarizing what the
+// controller did, and could still do, in each namespace.
+package report
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/OpScaleHub/K20s/internal/audit"
+)
+
+// WeekStart returns the start of the week holding t, Monday at 00:00 UTC.
+func WeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+}
+
+// Name returns the name of the report of the week starting at start, e.g.
+// weekly-2025-w02 for its ISO week.
+func Name(start time.Time) string {
+	year, week := start.ISOWeek()
+	return fmt.Sprintf("weekly-%d-w%02d", year, week)
+}
+
+// Totals are the actions applied in a namespace over a week.
+type Totals struct {
+	// Actions counts the succeeded patches by action.
+	Actions map[string]int32
+	// Failed counts the failed patches.
+	Failed int32
+	// ReplicasRemoved is the net number of replicas removed by scaling.
+	ReplicasRemoved int32
+	// CPURequestsReleased is the net CPU removed from pod templates by resizing,
+	// per replica.
+	CPURequestsReleased resource.Quantity
+}
+
+type weekKey struct {
+	namespace string
+	start     time.Time
+}
+
+// Collector is an audit.Recorder adding up the patches applied by the
+// controller per namespace and week. It keeps the totals in memory only, so
+// patches applied before the controller started, or became the leader, are
+// not counted.
+type Collector struct {
+	mu    sync.Mutex
+	weeks map[weekKey]*Totals
+}
+
+var _ audit.Recorder = &Collector{}
+
+// NewCollector returns an empty Collector.
+func NewCollector() *Collector {
+	return &Collector{weeks: map[weekKey]*Totals{}}
+}
+
+// Record implements audit.Recorder.
+func (c *Collector) Record(_ context.Context, record audit.Record) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := weekKey{namespace: record.ProfileNamespace, start: WeekStart(record.Time)}
+	totals, ok := c.weeks[key]
+	if !ok {
+		totals = &Totals{Actions: map[string]int32{}}
+		c.weeks[key] = totals
+	}
+	if record.Result != audit.ResultSucceeded {
+		totals.Failed++
+		return nil
+	}
+	totals.Actions[record.Action]++
+	switch {
+	case record.Field == "spec.replicas":
+		before, errBefore := strconv.Atoi(record.Before)
+		after, errAfter := strconv.Atoi(record.After)
+		if errBefore == nil && errAfter == nil {
+			totals.ReplicasRemoved += int32(before - after)
+		}
+	case strings.HasSuffix(record.Field, ".resources.requests.cpu"):
+		before, errBefore := resource.ParseQuantity(record.Before)
+		after, errAfter := resource.ParseQuantity(record.After)
+		if errBefore == nil && errAfter == nil {
+			totals.CPURequestsReleased.Add(before)
+			totals.CPURequestsReleased.Sub(after)
+		}
+	}
+	return nil
+}
+
+// Totals returns the totals of a namespace for the week starting at start.
+func (c *Collector) Totals(namespace string, start time.Time) Totals {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	totals, ok := c.weeks[weekKey{namespace: namespace, start: start}]
+	if !ok {
+		return Totals{Actions: map[string]int32{}}
+	}
+	copied := *totals
+	copied.Actions = maps.Clone(totals.Actions)
+	copied.CPURequestsReleased = totals.CPURequestsReleased.DeepCopy()
+	return copied
+}
+
+// Forget drops the totals of the weeks starting before t.
+func (c *Collector) Forget(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	maps.DeleteFunc(c.weeks, func(key weekKey, _ *Totals) bool { return key.start.Before(t) })
+}
@@ -0,0 +1,297 @@
+package report
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"maps"
+	"slices"
+	"strconv"
+	texttemplate "text/template"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/controller"
+	"github.com/OpScaleHub/K20s/internal/notify"
+)
+
+// +kubebuilder:rbac:groups=optimizer.k20s.opscale.ir,resources=optimizationreports,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=optimizer.k20s.opscale.ir,resources=optimizationreports/status,verbs=get;update;patch
+
+const (
+	// DefaultInterval is how often the generator checks whether a week ended.
+	DefaultInterval = time.Hour
+	// DefaultTopWorkloads is the number of over-provisioned workloads listed
+	// in a report.
+	DefaultTopWorkloads = 5
+)
+
+// Generator generates an OptimizationReport for the past week in every
+// namespace that has profiles, once the week ended, and delivers it to the
+// namespace's notification channels.
+type Generator struct {
+	// Client reads profiles and their targets and writes the reports.
+	Client client.Client
+	// Collector holds the actions applied over the week.
+	Collector *Collector
+	// Notifier returns the notifier delivering the reports of a namespace.
+	// Reports are not delivered when it is nil.
+	Notifier func(ctx context.Context, namespace string) (notify.Notifier, error)
+	// Interval is how often the generator checks whether a week ended.
+	Interval time.Duration
+	// TopWorkloads is the number of over-provisioned workloads listed.
+	TopWorkloads int
+
+	// since is when the generator started, and so the collector with it.
+	since time.Time
+}
+
+var _ manager.LeaderElectionRunnable = &Generator{}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Only the
+// leader applies actions, so only its collector knows about them.
+func (g *Generator) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable.
+func (g *Generator) Start(ctx context.Context) error {
+	g.since = time.Now()
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := g.Report(ctx, time.Now()); err != nil {
+			log.FromContext(ctx).Error(err, "unable to generate weekly reports")
+		}
+	}, cmp.Or(g.Interval, DefaultInterval))
+	return nil
+}
+
+// Report generates the missing reports of the week before the one holding
+// now. Weeks that ended before the generator started are not reported, since
+// none of their actions were collected.
+func (g *Generator) Report(ctx context.Context, now time.Time) error {
+	end := WeekStart(now)
+	start := end.AddDate(0, 0, -7)
+	if !g.since.Before(end) {
+		return nil
+	}
+
+	var profiles optimizerv1.ResourceOptimizerProfileList
+	if err := g.Client.List(ctx, &profiles); err != nil {
+		return fmt.Errorf("listing profiles: %w", err)
+	}
+	namespaces := map[string][]*optimizerv1.ResourceOptimizerProfile{}
+	for i := range profiles.Items {
+		profile := &profiles.Items[i]
+		namespaces[profile.Namespace] = append(namespaces[profile.Namespace], profile)
+	}
+
+	for _, namespace := range slices.Sorted(maps.Keys(namespaces)) {
+		key := client.ObjectKey{Namespace: namespace, Name: Name(start)}
+		if err := g.Client.Get(ctx, key, &optimizerv1.OptimizationReport{}); err == nil {
+			continue
+		} else if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("getting report %s: %w", key, err)
+		}
+		report := g.build(ctx, namespace, namespaces[namespace], start, end, now)
+		status := report.Status
+		if err := g.Client.Create(ctx, report); err != nil {
+			return fmt.Errorf("creating report %s: %w", key, err)
+		}
+		report.Status = status
+		if err := g.Client.Status().Update(ctx, report); err != nil {
+			return fmt.Errorf("updating report %s: %w", key, err)
+		}
+		g.deliver(ctx, report)
+	}
+	g.Collector.Forget(end)
+	return nil
+}
+
+// build generates the report of a namespace.
+func (g *Generator) build(ctx context.Context, namespace string, profiles []*optimizerv1.ResourceOptimizerProfile, start, end, now time.Time) *optimizerv1.OptimizationReport {
+	totals := g.Collector.Totals(namespace, start)
+	report := &optimizerv1.OptimizationReport{
+		ObjectMeta: metav1.ObjectMeta{Name: Name(start), Namespace: namespace},
+		Spec:       optimizerv1.OptimizationReportSpec{PeriodStart: metav1.NewTime(start), PeriodEnd: metav1.NewTime(end)},
+		Status: optimizerv1.OptimizationReportStatus{
+			GeneratedAt:         metav1.NewTime(now),
+			FailedActions:       totals.Failed,
+			ReplicasRemoved:     totals.ReplicasRemoved,
+			CPURequestsReleased: totals.CPURequestsReleased,
+			TopOverProvisioned:  g.overProvisioned(ctx, profiles),
+		},
+	}
+	for _, action := range slices.Sorted(maps.Keys(totals.Actions)) {
+		report.Status.Actions = append(report.Status.Actions, optimizerv1.ActionCount{Action: action, Count: totals.Actions[action]})
+	}
+	var countedSince time.Time
+	if g.since.After(start) {
+		countedSince = g.since
+	}
+	report.Status.Markdown, report.Status.HTML = render(report, countedSince)
+	return report
+}
+
+// overProvisioned returns the workloads requesting the most CPU beyond what
+// their profile recommends at the utilization it last observed.
+func (g *Generator) overProvisioned(ctx context.Context, profiles []*optimizerv1.ResourceOptimizerProfile) []optimizerv1.OverProvisionedWorkload {
+	var workloads []optimizerv1.OverProvisionedWorkload
+	for _, profile := range profiles {
+		value, err := strconv.ParseFloat(profile.Status.ObservedMetrics["cpu_usage"], 64)
+		if err != nil {
+			// Not evaluated yet.
+			continue
+		}
+		sim, err := controller.Simulate(ctx, g.Client, profile, value)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "unable to recommend target requests", "profile", client.ObjectKeyFromObject(profile))
+			continue
+		}
+		for _, target := range sim.Targets {
+			excess := target.CurrentCPURequest.MilliValue() - target.CPURequest.MilliValue()
+			if excess <= 0 {
+				continue
+			}
+			workloads = append(workloads, optimizerv1.OverProvisionedWorkload{
+				Kind:                  target.Kind,
+				Name:                  target.Name,
+				Profile:               profile.Name,
+				CPURequest:            target.CurrentCPURequest,
+				RecommendedCPURequest: target.CPURequest,
+				Excess:                *resource.NewMilliQuantity(excess*int64(target.Replicas), resource.DecimalSI),
+			})
+		}
+	}
+	slices.SortFunc(workloads, func(a, b optimizerv1.OverProvisionedWorkload) int {
+		return cmp.Or(b.Excess.Cmp(a.Excess), cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Name, b.Name))
+	})
+	return workloads[:min(len(workloads), cmp.Or(g.TopWorkloads, DefaultTopWorkloads))]
+}
+
+// deliver sends a report to the notification channels of its namespace.
+// Failing to deliver is logged, since the report itself was generated.
+func (g *Generator) deliver(ctx context.Context, report *optimizerv1.OptimizationReport) {
+	if g.Notifier == nil {
+		return
+	}
+	logger := log.FromContext(ctx).WithValues("report", client.ObjectKeyFromObject(report))
+	notifier, err := g.Notifier(ctx, report.Namespace)
+	if err != nil {
+		logger.Error(err, "unable to resolve notification channels")
+	}
+	if notifier == nil {
+		return
+	}
+	event := notify.Event{
+		Kind:             notify.EventReport,
+		Time:             report.Status.GeneratedAt.Time,
+		ProfileNamespace: report.Namespace,
+		Details:          report.Status.Markdown,
+		Report:           report.Name,
+	}
+	if err := notifier.Notify(ctx, event); err != nil {
+		logger.Error(err, "unable to deliver report")
+	}
+}
+
+// view is the data the report templates render.
+type view struct {
+	*optimizerv1.OptimizationReport
+	// CountedSince is set when actions were only collected for part of the
+	// week.
+	CountedSince time.Time
+}
+
+const markdownTemplate = `# Weekly optimization report for {{.Namespace}}
+
+Week starting Monday {{.Spec.PeriodStart.Format "2006-01-02"}} (UTC).
+{{- if not .CountedSince.IsZero}} Actions are counted since {{.CountedSince.UTC.Format "2006-01-02 15:04"}}, when the controller started reporting.{{end}}
+
+## Actions taken
+{{if .Status.Actions}}
+| Action | Targets |
+|---|---|
+{{- range .Status.Actions}}
+| {{.Action}} | {{.Count}} |
+{{- end}}
+{{else}}
+No actions were applied.
+{{end}}
+{{- if .Status.FailedActions}}
+Actions that could not be applied: {{.Status.FailedActions}}.
+{{end}}
+## Savings
+
+- Replicas removed: {{.Status.ReplicasRemoved}}
+- CPU requests released per replica: {{.Status.CPURequestsReleased.String}}
+
+## Top over-provisioned workloads
+{{if .Status.TopOverProvisioned}}
+| Workload | Profile | CPU request | Recommended | Excess |
+|---|---|---|---|---|
+{{- range .Status.TopOverProvisioned}}
+| {{.Kind}}/{{.Name}} | {{.Profile}} | {{.CPURequest.String}} | {{.RecommendedCPURequest.String}} | {{.Excess.String}} |
+{{- end}}
+{{else}}
+No workload requests more CPU than recommended.
+{{end}}`
+
+const htmlTemplate = `<h1>Weekly optimization report for {{.Namespace}}</h1>
+<p>Week starting Monday {{.Spec.PeriodStart.Format "2006-01-02"}} (UTC).
+{{- if not .CountedSince.IsZero}} Actions are counted since {{.CountedSince.UTC.Format "2006-01-02 15:04"}}, when the controller started reporting.{{end}}</p>
+<h2>Actions taken</h2>
+{{- if .Status.Actions}}
+<table>
+<tr><th>Action</th><th>Targets</th></tr>
+{{- range .Status.Actions}}
+<tr><td>{{.Action}}</td><td>{{.Count}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No actions were applied.</p>
+{{- end}}
+{{- if .Status.FailedActions}}
+<p>Actions that could not be applied: {{.Status.FailedActions}}.</p>
+{{- end}}
+<h2>Savings</h2>
+<ul>
+<li>Replicas removed: {{.Status.ReplicasRemoved}}</li>
+<li>CPU requests released per replica: {{.Status.CPURequestsReleased.String}}</li>
+</ul>
+<h2>Top over-provisioned workloads</h2>
+{{- if .Status.TopOverProvisioned}}
+<table>
+<tr><th>Workload</th><th>Profile</th><th>CPU request</th><th>Recommended</th><th>Excess</th></tr>
+{{- range .Status.TopOverProvisioned}}
+<tr><td>{{.Kind}}/{{.Name}}</td><td>{{.Profile}}</td><td>{{.CPURequest.String}}</td><td>{{.RecommendedCPURequest.String}}</td><td>{{.Excess.String}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No workload requests more CPU than recommended.</p>
+{{- end}}
+`
+
+var (
+	markdownReport = texttemplate.Must(texttemplate.New("markdown").Parse(markdownTemplate))
+	htmlReport     = htmltemplate.Must(htmltemplate.New("html").Parse(htmlTemplate))
+)
+
+// render renders a report as Markdown and as an HTML fragment.
+func render(report *optimizerv1.OptimizationReport, countedSince time.Time) (string, string) {
+	data := view{OptimizationReport: report, CountedSince: countedSince}
+	var markdown, html bytes.Buffer
+	// The templates only fail on bugs in themselves, which the tests catch.
+	_ = markdownReport.Execute(&markdown, data)
+	_ = htmlReport.Execute(&html, data)
+	return markdown.String(), html.String()
+}
@@ -0,0 +1,145 @@
+package report
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/audit"
+	"github.com/OpScaleHub/K20s/internal/notify"
+)
+
+// recordingNotifier keeps the events it is asked to deliver.
+type recordingNotifier struct {
+	events []notify.Event
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, event notify.Event) error {
+	n.events = append(n.events, event)
+	return nil
+}
+
+var _ = Describe("Weekly reports", func() {
+	labels := map[string]string{"app": "web"}
+	// Wednesday of ISO week 2 of 2025.
+	wednesday := time.Date(2025, time.January, 8, 15, 0, 0, 0, time.UTC)
+	monday := time.Date(2025, time.January, 6, 0, 0, 0, 0, time.UTC)
+
+	record := func(at time.Time, namespace, action, field, before, after, result string) audit.Record {
+		return audit.Record{Time: at, ProfileNamespace: namespace, ProfileName: "web", Action: action,
+			TargetKind: "Deployment", TargetName: "web", Field: field, Before: before, After: after, Result: result}
+	}
+
+	It("should start weeks on Monday in UTC", func() {
+		Expect(WeekStart(wednesday)).To(Equal(monday))
+		Expect(WeekStart(monday)).To(Equal(monday))
+		Expect(WeekStart(monday.Add(-time.Nanosecond))).To(Equal(monday.AddDate(0, 0, -7)))
+		Expect(Name(monday)).To(Equal("weekly-2025-w02"))
+	})
+
+	It("should add up the actions of each namespace and week", func() {
+		collector := NewCollector()
+		cpuField := "spec.template.spec.containers[app].resources.requests.cpu"
+		for _, r := range []audit.Record{
+			record(wednesday, "team-a", "ScaleDown", "spec.replicas", "5", "3", audit.ResultSucceeded),
+			record(wednesday, "team-a", "ScaleUp", "spec.replicas", "3", "4", audit.ResultSucceeded),
+			record(wednesday, "team-a", "ResizeDown", cpuField, "1", "600m", audit.ResultSucceeded),
+			record(wednesday, "team-a", "ResizeDown", cpuField, "600m", "500m", audit.ResultFailed),
+			record(wednesday, "team-b", "ScaleDown", "spec.replicas", "2", "1", audit.ResultSucceeded),
+			record(wednesday.AddDate(0, 0, 7), "team-a", "ScaleDown", "spec.replicas", "4", "3", audit.ResultSucceeded),
+		} {
+			Expect(collector.Record(context.Background(), r)).To(Succeed())
+		}
+
+		totals := collector.Totals("team-a", monday)
+		Expect(totals.Actions).To(Equal(map[string]int32{"ScaleDown": 1, "ScaleUp": 1, "ResizeDown": 1}))
+		Expect(totals.Failed).To(Equal(int32(1)))
+		Expect(totals.ReplicasRemoved).To(Equal(int32(1)))
+		Expect(totals.CPURequestsReleased.String()).To(Equal("400m"))
+
+		collector.Forget(monday.AddDate(0, 0, 7))
+		Expect(collector.Totals("team-a", monday).Actions).To(BeEmpty())
+		Expect(collector.Totals("team-a", monday.AddDate(0, 0, 7)).Actions).To(HaveLen(1))
+	})
+
+	It("should generate and deliver the report of the past week once", func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(optimizerv1.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&optimizerv1.OptimizationReport{}).WithObjects(
+			&optimizerv1.ResourceOptimizerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+				Spec: optimizerv1.ResourceOptimizerProfileSpec{
+					Selector:           metav1.LabelSelector{MatchLabels: labels},
+					CPUThresholds:      optimizerv1.ThresholdSpec{Min: 20, Max: 80},
+					OptimizationPolicy: "Recommend",
+				},
+				Status: optimizerv1.ResourceOptimizerProfileStatus{ObservedMetrics: map[string]string{"cpu_usage": "10.00"}},
+			},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", Labels: labels},
+				Spec: appsv1.DeploymentSpec{Replicas: ptr.To[int32](3), Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Name:      "app",
+					Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}},
+				}}}}},
+			},
+		).Build()
+		collector := NewCollector()
+		Expect(collector.Record(context.Background(), record(wednesday, "team-a", "ScaleDown", "spec.replicas", "5", "3", audit.ResultSucceeded))).To(Succeed())
+		notifier := &recordingNotifier{}
+		generator := &Generator{
+			Client:    c,
+			Collector: collector,
+			Notifier: func(context.Context, string) (notify.Notifier, error) {
+				return notifier, nil
+			},
+			since: wednesday.Add(-time.Hour),
+		}
+
+		By("waiting for the week to end")
+		Expect(generator.Report(context.Background(), wednesday.Add(time.Hour))).To(Succeed())
+		Expect(notifier.events).To(BeEmpty())
+
+		nextMonday := monday.AddDate(0, 0, 7).Add(time.Hour)
+		Expect(generator.Report(context.Background(), nextMonday)).To(Succeed())
+		var report optimizerv1.OptimizationReport
+		Expect(c.Get(context.Background(), client.ObjectKey{Namespace: "team-a", Name: "weekly-2025-w02"}, &report)).To(Succeed())
+		Expect(report.Spec.PeriodStart.Time).To(BeTemporally("==", monday))
+		Expect(report.Status.Actions).To(Equal([]optimizerv1.ActionCount{{Action: "ScaleDown", Count: 1}}))
+		Expect(report.Status.ReplicasRemoved).To(Equal(int32(2)))
+		Expect(report.Status.TopOverProvisioned).To(HaveLen(1))
+		workload := report.Status.TopOverProvisioned[0]
+		Expect(workload.Name).To(Equal("web"))
+		Expect(workload.RecommendedCPURequest.Cmp(workload.CPURequest)).To(Equal(-1))
+		Expect(workload.Excess.MilliValue()).To(Equal(3 * (1000 - workload.RecommendedCPURequest.MilliValue())))
+		Expect(report.Status.Markdown).To(ContainSubstring("| ScaleDown | 1 |"))
+		Expect(report.Status.Markdown).To(ContainSubstring("Actions are counted since 2025-01-08 14:00"))
+		Expect(report.Status.HTML).To(ContainSubstring("<td>Deployment/web</td>"))
+
+		Expect(notifier.events).To(HaveLen(1))
+		Expect(notifier.events[0].Kind).To(Equal(notify.EventReport))
+		Expect(notifier.events[0].Report).To(Equal("weekly-2025-w02"))
+		Expect(notifier.events[0].Details).To(Equal(report.Status.Markdown))
+
+		By("not generating it again")
+		Expect(generator.Report(context.Background(), nextMonday.Add(time.Hour))).To(Succeed())
+		Expect(notifier.events).To(HaveLen(1))
+	})
+
+	It("should not report weeks that ended before it started", func() {
+		generator := &Generator{Collector: NewCollector(), since: monday.AddDate(0, 0, 7)}
+		Expect(generator.Report(context.Background(), monday.AddDate(0, 0, 7).Add(time.Hour))).To(Succeed())
+	})
+})
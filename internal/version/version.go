@@ -0,0 +1,69 @@
+// Package version describes the build of the controller binary.
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+)
+
+// These are set at build time, e.g.
+//
+//	go build -ldflags "-X github.com/OpScaleHub/K20s/internal/version.Version=v1.2.3"
+//
+// GitCommit and BuildDate fall back to the VCS information Go embeds when
+// building from a checkout.
+var (
+	Version   = "dev"
+	GitCommit = ""
+	BuildDate = ""
+)
+
+// Info describes the running binary.
+type Info struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"gitCommit"`
+	BuildDate string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
+	// APIVersions are the versions of the optimizer API the binary serves.
+	APIVersions []string `json:"apiVersions"`
+}
+
+// Get returns the build information of the running binary.
+func Get() Info {
+	info := Info{
+		Version:     Version,
+		GitCommit:   GitCommit,
+		BuildDate:   BuildDate,
+		GoVersion:   runtime.Version(),
+		APIVersions: []string{optimizerv1.GroupVersion.String()},
+	}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		var modified bool
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.GitCommit == "" {
+					info.GitCommit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = setting.Value
+				}
+			case "vcs.modified":
+				modified = setting.Value == "true"
+			}
+		}
+		if modified && GitCommit == "" && info.GitCommit != "" {
+			info.GitCommit += "-dirty"
+		}
+	}
+	if info.GitCommit == "" {
+		info.GitCommit = "unknown"
+	}
+	if info.BuildDate == "" {
+		info.BuildDate = "unknown"
+	}
+	return info
+}
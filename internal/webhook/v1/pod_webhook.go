@@ -0,0 +1,191 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/controller"
+)
+
+var podlog = logf.Log.WithName("pod-resource")
+
+// SetupPodWebhookWithManager registers the webhook that injects the CPU requests
+// recommended by Admission profiles into new pods. Profiles are read from the
+// manager's cache, but the owners of pods through the API reader so the manager
+// does not cache every ReplicaSet in the cluster. Pods in the protected
+// namespaces are never changed.
+func SetupPodWebhookWithManager(mgr ctrl.Manager, namespaces controller.NamespaceFilter, protected []string) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&corev1.Pod{}).
+		WithDefaulter(&PodCustomDefaulter{Client: mgr.GetClient(), Reader: mgr.GetAPIReader(), Namespaces: namespaces, Protected: protected}).
+		Complete()
+}
+
+// Every pod created in the cluster passes through this webhook, so it times out
+// quickly: a slow controller must not delay pod starts. config/webhook also
+// excludes the protected namespaces with a namespaceSelector.
+// +kubebuilder:webhook:path=/mutate--v1-pod,mutating=true,failurePolicy=ignore,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=mpod-v1.kb.io,admissionReviewVersions=v1,timeoutSeconds=3
+// +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get
+
+// PodCustomDefaulter sets the CPU request recommended for a pod's Deployment
+// or StatefulSet by a profile with the Admission action mode. Pods of other
+// workloads are admitted unchanged, and so are pods whose workload cannot be
+// read: a missing recommendation must never keep pods from starting.
+type PodCustomDefaulter struct {
+	// Client reads the profiles, typically from the manager's cache.
+	Client client.Reader
+	// Reader reads the owners of pods. It is only used in namespaces with an
+	// Admission profile.
+	Reader client.Reader
+	// Namespaces are the namespaces pods may be changed in.
+	Namespaces controller.NamespaceFilter
+	// Protected are namespaces pods are never changed in.
+	Protected []string
+}
+
+var _ webhook.CustomDefaulter = &PodCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the type Pod.
+func (d *PodCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return fmt.Errorf("expected a Pod object but got %T", obj)
+	}
+	// Pods created by controllers usually have no namespace set yet.
+	namespace := pod.Namespace
+	if req, err := admission.RequestFromContext(ctx); err == nil && req.Namespace != "" {
+		namespace = req.Namespace
+	}
+	if d.Namespaces.Check(namespace) != nil || slices.Contains(d.Protected, namespace) {
+		return nil
+	}
+	profiles, err := d.admissionProfiles(ctx, namespace)
+	if err != nil || len(profiles) == 0 {
+		if err != nil {
+			podlog.Error(err, "unable to list profiles", "namespace", namespace)
+		}
+		return nil
+	}
+
+	workload, err := d.workload(ctx, namespace, pod)
+	if err != nil || workload == nil {
+		if err != nil {
+			podlog.Error(err, "unable to find the workload of pod", "namespace", namespace, "generateName", pod.GenerateName)
+		}
+		return nil
+	}
+	value, ok := workload.Annotations[optimizerv1.RecommendedCPURequestAnnotation]
+	if !ok {
+		return nil
+	}
+	container, request, err := controller.ParseRecommendedCPURequest(value)
+	if err != nil {
+		podlog.Error(err, "ignoring recommended CPU request", "namespace", namespace, "name", workload.Name)
+		return nil
+	}
+
+	profileName := workload.Annotations[optimizerv1.RecommendedByAnnotation]
+	if !slices.Contains(profiles, profileName) {
+		return nil
+	}
+
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		if c.Name != container {
+			continue
+		}
+		// A request above the limit would get the pod rejected.
+		if limit, ok := c.Resources.Limits[corev1.ResourceCPU]; ok && request.Cmp(limit) > 0 {
+			podlog.Info("recommended CPU request exceeds the limit, leaving pod unchanged",
+				"namespace", namespace, "workload", workload.Name, "container", container, "request", request.String(), "limit", limit.String())
+			return nil
+		}
+		if c.Resources.Requests == nil {
+			c.Resources.Requests = corev1.ResourceList{}
+		}
+		c.Resources.Requests[corev1.ResourceCPU] = request
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[optimizerv1.RecommendedCPURequestAnnotation] = value
+		pod.Annotations[optimizerv1.RecommendedByAnnotation] = profileName
+		return nil
+	}
+	return nil
+}
+
+// admissionProfiles returns the names of the profiles with the Admission
+// action mode in namespace. Pods in namespaces without one are admitted
+// without reading their owners.
+func (d *PodCustomDefaulter) admissionProfiles(ctx context.Context, namespace string) ([]string, error) {
+	var profiles optimizerv1.ResourceOptimizerProfileList
+	if err := d.Client.List(ctx, &profiles, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, profile := range profiles.Items {
+		if profile.Spec.ActionMode == controller.ActionModeAdmission {
+			names = append(names, profile.Name)
+		}
+	}
+	return names, nil
+}
+
+// workload returns the metadata of the Deployment or StatefulSet controlling
+// the pod, or nil if it has neither.
+func (d *PodCustomDefaulter) workload(ctx context.Context, namespace string, pod *corev1.Pod) (*metav1.ObjectMeta, error) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return nil, nil
+	}
+	switch owner.Kind {
+	case "StatefulSet":
+		var ss appsv1.StatefulSet
+		if err := d.Reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: owner.Name}, &ss); err != nil {
+			return nil, err
+		}
+		return &ss.ObjectMeta, nil
+	case "ReplicaSet":
+		var rs appsv1.ReplicaSet
+		if err := d.Reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: owner.Name}, &rs); err != nil {
+			return nil, err
+		}
+		owner = metav1.GetControllerOf(&rs)
+		if owner == nil || owner.Kind != "Deployment" {
+			return nil, nil
+		}
+		var deployment appsv1.Deployment
+		if err := d.Reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: owner.Name}, &deployment); err != nil {
+			return nil, err
+		}
+		return &deployment.ObjectMeta, nil
+	}
+	return nil, nil
+}
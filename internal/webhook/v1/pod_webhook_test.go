@@ -0,0 +1,128 @@
+package v1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/controller"
+)
+
+var _ = Describe("Pod Webhook", func() {
+	var (
+		defaulter *PodCustomDefaulter
+		profile   *optimizerv1.ResourceOptimizerProfile
+		pod       *corev1.Pod
+		ownerGets int
+	)
+	ctx := admission.NewContextWithRequest(context.Background(),
+		admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Namespace: "team-a"}})
+	controllerRef := func(kind, name string) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: kind, Name: name, UID: "uid", Controller: ptr.To(true)}}
+	}
+
+	BeforeEach(func() {
+		profile = &optimizerv1.ResourceOptimizerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-profile", Namespace: "team-a"},
+			Spec: optimizerv1.ResourceOptimizerProfileSpec{
+				OptimizationPolicy: "Resize",
+				ActionMode:         controller.ActionModeAdmission,
+			},
+		}
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{GenerateName: "web-7d9f-", OwnerReferences: controllerRef("ReplicaSet", "web-7d9f")},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "sidecar"},
+				{Name: "app", Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+					Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+				}},
+			}},
+		}
+	})
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(optimizerv1.AddToScheme(scheme)).To(Succeed())
+		objects := []client.Object{
+			profile,
+			&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+				Name: "web-7d9f", Namespace: "team-a", OwnerReferences: controllerRef("Deployment", "web"),
+			}},
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", Annotations: map[string]string{
+				optimizerv1.RecommendedCPURequestAnnotation: "app=250m",
+				optimizerv1.RecommendedByAnnotation:         "web-profile",
+			}}},
+		}
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+		ownerGets = 0
+		reader := interceptor.NewClient(cl, interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				ownerGets++
+				return c.Get(ctx, key, obj, opts...)
+			},
+		})
+		defaulter = &PodCustomDefaulter{Client: cl, Reader: reader}
+	})
+
+	cpuRequest := func() string {
+		return pod.Spec.Containers[1].Resources.Requests.Cpu().String()
+	}
+
+	It("should inject the request recommended for the pod's Deployment", func() {
+		Expect(defaulter.Default(ctx, pod)).To(Succeed())
+		Expect(cpuRequest()).To(Equal("250m"))
+		Expect(pod.Spec.Containers[0].Resources.Requests).To(BeEmpty())
+		Expect(pod.Annotations).To(HaveKeyWithValue(optimizerv1.RecommendedByAnnotation, "web-profile"))
+	})
+
+	Context("when the profile only annotates", func() {
+		BeforeEach(func() {
+			profile.Spec.ActionMode = controller.ActionModeAnnotate
+		})
+
+		It("should leave pods alone without reading their owners", func() {
+			Expect(defaulter.Default(ctx, pod)).To(Succeed())
+			Expect(cpuRequest()).To(Equal("100m"))
+			Expect(ownerGets).To(BeZero())
+		})
+	})
+
+	It("should not raise the request above the limit", func() {
+		pod.Spec.Containers[1].Resources.Limits[corev1.ResourceCPU] = resource.MustParse("200m")
+		Expect(defaulter.Default(ctx, pod)).To(Succeed())
+		Expect(cpuRequest()).To(Equal("100m"))
+	})
+
+	It("should admit pods whose workload cannot be found or is not allowed", func() {
+		pod.OwnerReferences = controllerRef("ReplicaSet", "gone")
+		Expect(defaulter.Default(ctx, pod)).To(Succeed())
+		Expect(cpuRequest()).To(Equal("100m"))
+
+		pod.OwnerReferences = controllerRef("ReplicaSet", "web-7d9f")
+		defaulter.Namespaces = controller.NamespaceFilter{Deny: []string{"team-a"}}
+		Expect(defaulter.Default(ctx, pod)).To(Succeed())
+		Expect(cpuRequest()).To(Equal("100m"))
+	})
+
+	It("should leave pods in protected namespaces alone", func() {
+		defaulter.Protected = []string{"kube-system", "team-a"}
+		Expect(defaulter.Default(ctx, pod)).To(Succeed())
+		Expect(cpuRequest()).To(Equal("100m"))
+		Expect(ownerGets).To(BeZero())
+	})
+})
@@ -0,0 +1,501 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/controller"
+)
+
+// nolint:unused
+// log is for logging in this package.
+var resourceoptimizerprofilelog = logf.Log.WithName("resourceoptimizerprofile-resource")
+
+// minThresholdGap is the smallest distance between the CPU thresholds that does
+// not trigger an oscillation warning.
+const minThresholdGap = 10
+
+// minCooldownPeriod is the shortest cooldown that does not trigger a warning.
+const minCooldownPeriod = time.Minute
+
+// DefaultProtectedNamespaces lists the namespaces profiles are only admitted
+// in with AllowProtectedNamespaceAnnotation unless told otherwise.
+var DefaultProtectedNamespaces = []string{"kube-system", "kube-public", "kube-node-lease"}
+
+// SetupResourceOptimizerProfileWebhookWithManager registers the webhook for ResourceOptimizerProfile in the manager.
+// Profiles are rejected in namespaces the controller is not allowed to change,
+// in protected namespaces unless they opt in, and, with denyOverlaps, when
+// they overlap another profile.
+func SetupResourceOptimizerProfileWebhookWithManager(mgr ctrl.Manager, namespaces controller.NamespaceFilter, protected []string, denyOverlaps bool) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&optimizerv1.ResourceOptimizerProfile{}).
+		WithValidator(&ResourceOptimizerProfileCustomValidator{
+			Reader:              mgr.GetAPIReader(),
+			Namespaces:          namespaces,
+			ProtectedNamespaces: protected,
+			DenyOverlaps:        denyOverlaps,
+		}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-optimizer-k20s-opscale-ir-v1-resourceoptimizerprofile,mutating=false,failurePolicy=fail,sideEffects=None,groups=optimizer.k20s.opscale.ir,resources=resourceoptimizerprofiles,verbs=create;update,versions=v1,name=vresourceoptimizerprofile-v1.kb.io,admissionReviewVersions=v1
+
+// ResourceOptimizerProfileCustomValidator struct is responsible for validating the ResourceOptimizerProfile resource
+// when it is created, updated, or deleted.
+type ResourceOptimizerProfileCustomValidator struct {
+	// Reader lists the other profiles of a namespace. Overlaps are not checked
+	// when it is nil.
+	Reader client.Reader
+	// Namespaces are the namespaces profiles may be created in.
+	Namespaces controller.NamespaceFilter
+	// ProtectedNamespaces are namespaces profiles are only admitted in when
+	// they carry AllowProtectedNamespaceAnnotation.
+	ProtectedNamespaces []string
+	// DenyOverlaps rejects profiles whose selector overlaps another profile's,
+	// instead of warning about them.
+	DenyOverlaps bool
+}
+
+var _ webhook.CustomValidator = &ResourceOptimizerProfileCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type ResourceOptimizerProfile.
+func (v *ResourceOptimizerProfileCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	profile, ok := obj.(*optimizerv1.ResourceOptimizerProfile)
+	if !ok {
+		return nil, fmt.Errorf("expected a ResourceOptimizerProfile object but got %T", obj)
+	}
+	resourceoptimizerprofilelog.Info("Validation for ResourceOptimizerProfile upon creation", "name", profile.GetName())
+
+	if err := v.checkNamespace(profile); err != nil {
+		return nil, err
+	}
+	return v.validate(ctx, profile)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type ResourceOptimizerProfile.
+func (v *ResourceOptimizerProfileCustomValidator) ValidateUpdate(ctx context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	profile, ok := newObj.(*optimizerv1.ResourceOptimizerProfile)
+	if !ok {
+		return nil, fmt.Errorf("expected a ResourceOptimizerProfile object for the newObj but got %T", newObj)
+	}
+	resourceoptimizerprofilelog.Info("Validation for ResourceOptimizerProfile upon update", "name", profile.GetName())
+
+	if err := v.checkNamespace(profile); err != nil {
+		return nil, err
+	}
+	return v.validate(ctx, profile)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type ResourceOptimizerProfile.
+func (v *ResourceOptimizerProfileCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate runs ValidateProfile and checks the profile for overlaps with the
+// other profiles of its namespace.
+func (v *ResourceOptimizerProfileCustomValidator) validate(ctx context.Context, profile *optimizerv1.ResourceOptimizerProfile) (admission.Warnings, error) {
+	warnings, err := ValidateProfile(profile)
+	if err != nil || v.Reader == nil {
+		return warnings, err
+	}
+	overlapping, err := overlappingProfiles(ctx, v.Reader, profile)
+	if err != nil {
+		// Failing to list profiles must not keep profiles from being applied.
+		resourceoptimizerprofilelog.Error(err, "unable to check for overlapping profiles", "name", profile.GetName())
+		return warnings, nil
+	}
+	if len(overlapping) == 0 {
+		return warnings, nil
+	}
+	message := fmt.Sprintf("the selector overlaps profiles %s, which may manage the same workloads", strings.Join(overlapping, ", "))
+	if v.DenyOverlaps {
+		return warnings, apierrors.NewInvalid(optimizerv1.GroupVersion.WithKind("ResourceOptimizerProfile").GroupKind(), profile.Name,
+			field.ErrorList{field.Forbidden(field.NewPath("spec", "selector", "matchLabels"), message)})
+	}
+	return append(warnings, "spec.selector.matchLabels: "+message), nil
+}
+
+// overlappingProfiles returns the names of the other profiles in the
+// profile's namespace that can select the same Deployments and StatefulSets.
+// Two profiles can unless their matchLabels require different values for a
+// label, since a workload carrying the labels of both is matched by both.
+func overlappingProfiles(ctx context.Context, c client.Reader, profile *optimizerv1.ResourceOptimizerProfile) ([]string, error) {
+	var profiles optimizerv1.ResourceOptimizerProfileList
+	if err := c.List(ctx, &profiles, client.InNamespace(profile.Namespace)); err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, other := range profiles.Items {
+		if other.Name == profile.Name {
+			continue
+		}
+		if matchLabelsOverlap(profile.Spec.Selector.MatchLabels, other.Spec.Selector.MatchLabels) {
+			names = append(names, other.Name)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// matchLabelsOverlap returns true if a set of labels can match both a and b.
+func matchLabelsOverlap(a, b map[string]string) bool {
+	for key, value := range a {
+		if other, ok := b[key]; ok && other != value {
+			return false
+		}
+	}
+	return true
+}
+
+// checkNamespace forbids profiles in namespaces the controller must not
+// change, and in protected namespaces unless the profile opts in. Deleting
+// them stays possible.
+func (v *ResourceOptimizerProfileCustomValidator) checkNamespace(profile *optimizerv1.ResourceOptimizerProfile) error {
+	resource := optimizerv1.GroupVersion.WithResource("resourceoptimizerprofiles").GroupResource()
+	if err := v.Namespaces.Check(profile.Namespace); err != nil {
+		return apierrors.NewForbidden(resource, profile.Name, err)
+	}
+	if slices.Contains(v.ProtectedNamespaces, profile.Namespace) &&
+		profile.Annotations[optimizerv1.AllowProtectedNamespaceAnnotation] != "true" {
+		return apierrors.NewForbidden(resource, profile.Name, fmt.Errorf("namespace %q is protected; annotate the profile with %s=true to manage its workloads",
+			profile.Namespace, optimizerv1.AllowProtectedNamespaceAnnotation))
+	}
+	return nil
+}
+
+// ValidateProfile runs the checks of the validating webhook. It is also used
+// by the lint command to check profiles before they are applied. The warnings
+// flag settings that are accepted but probably not what the author meant.
+func ValidateProfile(profile *optimizerv1.ResourceOptimizerProfile) (admission.Warnings, error) {
+	var allErrs field.ErrorList
+	var warnings admission.Warnings
+	spec := &profile.Spec
+	specPath := field.NewPath("spec")
+
+	// The schema enforces these too, but lint runs without the API server.
+	selectorPath := specPath.Child("selector")
+	if _, err := metav1.LabelSelectorAsSelector(&spec.Selector); err != nil {
+		allErrs = append(allErrs, field.Invalid(selectorPath, spec.Selector, err.Error()))
+	}
+	if len(spec.Selector.MatchLabels) == 0 {
+		// The controller selects targets by matchLabels alone, and an empty set
+		// would select every workload in the namespace.
+		allErrs = append(allErrs, field.Required(selectorPath.Child("matchLabels"), "targets are selected by matchLabels"))
+	}
+	if len(spec.Selector.MatchExpressions) > 0 {
+		warnings = append(warnings, "spec.selector.matchExpressions only narrow the pods whose CPU is measured; "+
+			"Deployments and StatefulSets are selected by matchLabels alone")
+	}
+
+	thresholdsPath := specPath.Child("cpuThresholds")
+	for _, t := range []struct {
+		name  string
+		value int32
+	}{{"min", spec.CPUThresholds.Min}, {"max", spec.CPUThresholds.Max}} {
+		if t.value < 1 || t.value > 100 {
+			allErrs = append(allErrs, field.Invalid(thresholdsPath.Child(t.name), t.value, "must be between 1 and 100"))
+		}
+	}
+	if spec.CPUThresholds.Min >= spec.CPUThresholds.Max {
+		allErrs = append(allErrs, field.Invalid(thresholdsPath, spec.CPUThresholds, "min must be less than max"))
+	} else if spec.CPUThresholds.Max-spec.CPUThresholds.Min < minThresholdGap {
+		warnings = append(warnings, fmt.Sprintf("spec.cpuThresholds are less than %d points apart, which may cause "+
+			"the controller to oscillate between scaling up and down", minThresholdGap))
+	}
+
+	policyPath := specPath.Child("optimizationPolicy")
+	switch spec.OptimizationPolicy {
+	case "Scale", "Resize", "Recommend":
+	default:
+		allErrs = append(allErrs, field.NotSupported(policyPath, spec.OptimizationPolicy, []string{"Scale", "Resize", "Recommend"}))
+	}
+
+	if spec.CooldownPeriod != nil {
+		cooldownPath := specPath.Child("cooldownPeriod")
+		if spec.CooldownPeriod.Duration < 0 {
+			allErrs = append(allErrs, field.Invalid(cooldownPath, spec.CooldownPeriod.Duration.String(), "must not be negative"))
+		} else if spec.CooldownPeriod.Duration < minCooldownPeriod {
+			warnings = append(warnings, fmt.Sprintf("spec.cooldownPeriod is shorter than %s; "+
+				"actions may be taken before their effect shows in the metrics", minCooldownPeriod))
+		}
+	}
+
+	if spec.MinCPU != nil && spec.MinCPU.Sign() <= 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("minCPU"), spec.MinCPU.String(), "must be positive"))
+	}
+	if spec.MaxCPU != nil && spec.MaxCPU.Sign() <= 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("maxCPU"), spec.MaxCPU.String(), "must be positive"))
+	}
+	if spec.InitialCPURequest != nil && spec.InitialCPURequest.Sign() <= 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("initialCPURequest"), spec.InitialCPURequest.String(), "must be positive"))
+	}
+	if spec.MinCPU != nil && spec.MaxCPU != nil && spec.MinCPU.Cmp(*spec.MaxCPU) > 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("minCPU"), spec.MinCPU.String(), "must not be greater than maxCPU"))
+	}
+	if (spec.MinCPU != nil || spec.MaxCPU != nil) && spec.OptimizationPolicy != "Resize" {
+		warnings = append(warnings, "spec.minCPU and spec.maxCPU only apply to the Resize policy")
+	}
+	if spec.ActionMode != "" && spec.OptimizationPolicy == "Recommend" {
+		warnings = append(warnings, "spec.actionMode only applies to the Scale and Resize policies")
+	} else if spec.ActionMode == controller.ActionModeAdmission && spec.OptimizationPolicy != "Resize" {
+		warnings = append(warnings, "spec.actionMode Admission only changes how the Resize policy applies its actions")
+	}
+	if spec.RestartPolicy != "" && spec.OptimizationPolicy != "Resize" {
+		warnings = append(warnings, "spec.restartPolicy only applies to the Resize policy")
+	}
+	if spec.RecommendationSource != "" && spec.OptimizationPolicy != "Resize" {
+		warnings = append(warnings, "spec.recommendationSource only applies to the Resize policy")
+	}
+	if spec.PercentileRecommendation != nil {
+		if window := spec.PercentileRecommendation.Window; window != nil && window.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("percentileRecommendation", "window"), window.Duration.String(), "must be positive"))
+		}
+		if spec.RecommendationSource != controller.RecommendationSourcePercentile {
+			warnings = append(warnings, "spec.percentileRecommendation only applies to the Percentile recommendation source")
+		}
+	}
+	if spec.UsageHistory != nil {
+		if halfLife := spec.UsageHistory.HalfLife; halfLife != nil && halfLife.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("usageHistory", "halfLife"), halfLife.Duration.String(), "must be positive"))
+		}
+		if spec.OptimizationPolicy != "Resize" {
+			warnings = append(warnings, "spec.usageHistory only applies to the Resize policy")
+		}
+	}
+
+	for i, target := range spec.Notifications {
+		if target.ChannelRef.Name == "" {
+			allErrs = append(allErrs, field.Required(specPath.Child("notifications").Index(i).Child("channelRef", "name"), ""))
+		}
+	}
+
+	for i, trigger := range spec.AlertTriggers {
+		if trigger.AlertName == "" {
+			allErrs = append(allErrs, field.Required(specPath.Child("alertTriggers").Index(i).Child("alertName"), ""))
+		}
+	}
+	if len(spec.AlertTriggers) > 0 && spec.OptimizationPolicy == "Resize" {
+		warnings = append(warnings, "spec.alertTriggers only apply to the Scale and Recommend policies")
+	}
+
+	if rps := spec.RequestsPerSecond; rps != nil {
+		rpsPath := specPath.Child("requestsPerSecond")
+		if rps.Min >= rps.Max {
+			allErrs = append(allErrs, field.Invalid(rpsPath.Child("min"), rps.Min, "must be less than max"))
+		}
+		if rps.Source == controller.RequestsSourceNGINX && rps.Service == "" {
+			allErrs = append(allErrs, field.Required(rpsPath.Child("service"), "the NGINX source counts the requests of a Service"))
+		}
+		if spec.OptimizationPolicy == "Resize" {
+			warnings = append(warnings, "spec.requestsPerSecond only applies to the Scale and Recommend policies")
+		}
+	}
+	scaleToZero := 0
+	for i, trigger := range spec.QueueTriggers {
+		triggerPath := specPath.Child("queueTriggers").Index(i)
+		if trigger.Type == controller.QueueKafka && trigger.ConsumerGroup == "" {
+			allErrs = append(allErrs, field.Required(triggerPath.Child("consumerGroup"), "Kafka triggers read the lag of a consumer group"))
+		}
+		if trigger.Type == controller.QueueRabbitMQ && trigger.Queue == "" {
+			allErrs = append(allErrs, field.Required(triggerPath.Child("queue"), "RabbitMQ triggers read the messages of a queue"))
+		}
+		if trigger.ScaleToZeroAfter != nil {
+			scaleToZero++
+			if trigger.ScaleToZeroAfter.Duration <= 0 {
+				allErrs = append(allErrs, field.Invalid(triggerPath.Child("scaleToZeroAfter"), trigger.ScaleToZeroAfter.Duration.String(), "must be positive"))
+			}
+		}
+	}
+	if scaleToZero > 0 && scaleToZero < len(spec.QueueTriggers) {
+		warnings = append(warnings, "targets are only scaled to zero when every entry of spec.queueTriggers sets scaleToZeroAfter")
+	}
+	if len(spec.QueueTriggers) > 0 && spec.OptimizationPolicy == "Resize" {
+		warnings = append(warnings, "spec.queueTriggers only apply to the Scale and Recommend policies")
+	}
+	if objective := spec.LatencyObjective; objective != nil {
+		if objective.Threshold.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("latencyObjective", "threshold"), objective.Threshold.Duration.String(), "must be positive"))
+		}
+		if objective.Source == controller.LatencySourceLinkerd && objective.Service != "" {
+			warnings = append(warnings, "spec.latencyObjective.service is ignored by the Linkerd source, which measures the profile's targets")
+		}
+		if spec.OptimizationPolicy == "Resize" {
+			warnings = append(warnings, "spec.latencyObjective only applies to the Scale and Recommend policies")
+		}
+	}
+
+	if spec.ScaleStep != nil {
+		stepPath := specPath.Child("scaleStep")
+		for name, step := range map[string]*intstr.IntOrString{"up": spec.ScaleStep.Up, "down": spec.ScaleStep.Down} {
+			if step == nil {
+				continue
+			}
+			if scaled, err := intstr.GetScaledValueFromIntOrPercent(step, 100, true); err != nil || scaled <= 0 {
+				allErrs = append(allErrs, field.Invalid(stepPath.Child(name), step.String(), "must be a positive number of replicas or a positive percentage"))
+			}
+		}
+		if spec.OptimizationPolicy != "Scale" {
+			warnings = append(warnings, "spec.scaleStep only applies to the Scale policy")
+		}
+	}
+
+	if spec.MaxReplicaChange != nil {
+		if *spec.MaxReplicaChange < 1 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("maxReplicaChange"), *spec.MaxReplicaChange, "must be at least 1"))
+		}
+		if spec.OptimizationPolicy != "Scale" {
+			warnings = append(warnings, "spec.maxReplicaChange only applies to the Scale policy")
+		}
+	}
+
+	if spec.MaxResizePercent != nil {
+		if *spec.MaxResizePercent < 1 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("maxResizePercent"), *spec.MaxResizePercent, "must be at least 1"))
+		}
+		if spec.OptimizationPolicy != "Resize" {
+			warnings = append(warnings, "spec.maxResizePercent only applies to the Resize policy")
+		}
+	}
+
+	if spec.Behavior != nil {
+		behaviorPath := specPath.Child("behavior")
+		allErrs = append(allErrs, validateScalingRules(behaviorPath.Child("scaleUp"), spec.Behavior.ScaleUp)...)
+		allErrs = append(allErrs, validateScalingRules(behaviorPath.Child("scaleDown"), spec.Behavior.ScaleDown)...)
+		if spec.OptimizationPolicy != "Scale" {
+			warnings = append(warnings, "spec.behavior only applies to the Scale policy")
+		}
+		for _, rules := range []*autoscalingv2.HPAScalingRules{spec.Behavior.ScaleUp, spec.Behavior.ScaleDown} {
+			if rules != nil && rules.Tolerance != nil {
+				warnings = append(warnings, "spec.behavior tolerances are not used; the CPU thresholds decide when to scale")
+				break
+			}
+		}
+	}
+
+	for i, rule := range spec.ActionPolicy {
+		rulePath := specPath.Child("actionPolicy").Index(i)
+		if rule.Name == "" {
+			allErrs = append(allErrs, field.Required(rulePath.Child("name"), ""))
+		}
+		if _, err := controller.CompileActionRule(rule.Expression); err != nil {
+			allErrs = append(allErrs, field.Invalid(rulePath.Child("expression"), rule.Expression, err.Error()))
+		}
+	}
+	if len(spec.ActionPolicy) > 0 && spec.OptimizationPolicy == "Recommend" {
+		warnings = append(warnings, "spec.actionPolicy only applies to the Scale and Resize policies")
+	}
+
+	if spec.MinConfidence != nil {
+		if *spec.MinConfidence < 0 || *spec.MinConfidence > 100 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("minConfidence"), *spec.MinConfidence, "must be between 0 and 100"))
+		}
+		if spec.OptimizationPolicy == "Recommend" {
+			warnings = append(warnings, "spec.minConfidence only applies to the Scale and Resize policies")
+		}
+	}
+
+	if hours := spec.BusinessHours; hours != nil && !hours.Disabled {
+		if err := controller.CheckBusinessHours(hours); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("businessHours"), fmt.Sprintf("%s-%s", hours.Start, hours.End), err.Error()))
+		}
+		if spec.OptimizationPolicy == "Recommend" {
+			warnings = append(warnings, "spec.businessHours only applies to the Scale and Resize policies")
+		}
+	}
+
+	if spec.QueryTimeout != nil && spec.QueryTimeout.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("queryTimeout"), spec.QueryTimeout.Duration.String(), "must be positive"))
+	}
+
+	if p := spec.PartialResponsePolicy; p != "" && p != controller.PartialResponseFail && p != controller.PartialResponseWarn && p != controller.PartialResponseSkip {
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("partialResponsePolicy"), p,
+			[]string{controller.PartialResponseFail, controller.PartialResponseWarn, controller.PartialResponseSkip}))
+	}
+
+	if spec.RecommendationTTL != nil {
+		if spec.RecommendationTTL.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("recommendationTTL"), spec.RecommendationTTL.Duration.String(), "must be positive"))
+		}
+		if spec.OptimizationPolicy != "Recommend" {
+			warnings = append(warnings, "spec.recommendationTTL only applies to the Recommend policy")
+		}
+	}
+
+	if spec.MetricQuery != "" {
+		if _, err := controller.ParseMetricQuery(spec.MetricQuery); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("metricQuery"), spec.MetricQuery, err.Error()))
+		}
+		if spec.RecordingRule {
+			warnings = append(warnings, "spec.recordingRule does not apply to a spec.metricQuery, which is queried as it is")
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return warnings, nil
+	}
+	return warnings, apierrors.NewInvalid(optimizerv1.GroupVersion.WithKind("ResourceOptimizerProfile").GroupKind(), profile.Name, allErrs)
+}
+
+// validateScalingRules applies the limits the HorizontalPodAutoscaler places on
+// its behavior, which the schema does not carry over.
+func validateScalingRules(path *field.Path, rules *autoscalingv2.HPAScalingRules) field.ErrorList {
+	var allErrs field.ErrorList
+	if rules == nil {
+		return nil
+	}
+	if w := rules.StabilizationWindowSeconds; w != nil && (*w < 0 || *w > 3600) {
+		allErrs = append(allErrs, field.Invalid(path.Child("stabilizationWindowSeconds"), *w, "must be between 0 and 3600"))
+	}
+	if p := rules.SelectPolicy; p != nil {
+		switch *p {
+		case autoscalingv2.MaxChangePolicySelect, autoscalingv2.MinChangePolicySelect, autoscalingv2.DisabledPolicySelect:
+		default:
+			allErrs = append(allErrs, field.NotSupported(path.Child("selectPolicy"), *p, []string{"Max", "Min", "Disabled"}))
+		}
+	}
+	for i, policy := range rules.Policies {
+		policyPath := path.Child("policies").Index(i)
+		switch policy.Type {
+		case autoscalingv2.PodsScalingPolicy, autoscalingv2.PercentScalingPolicy:
+		default:
+			allErrs = append(allErrs, field.NotSupported(policyPath.Child("type"), policy.Type, []string{"Pods", "Percent"}))
+		}
+		if policy.Value <= 0 {
+			allErrs = append(allErrs, field.Invalid(policyPath.Child("value"), policy.Value, "must be positive"))
+		}
+		if policy.PeriodSeconds <= 0 || policy.PeriodSeconds > 1800 {
+			allErrs = append(allErrs, field.Invalid(policyPath.Child("periodSeconds"), policy.PeriodSeconds, "must be between 1 and 1800"))
+		}
+	}
+	return allErrs
+}
@@ -0,0 +1,377 @@
+package v1
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	optimizerv1 "github.com/OpScaleHub/K20s/api/v1"
+	"github.com/OpScaleHub/K20s/internal/controller"
+)
+
+var _ = Describe("ResourceOptimizerProfile Webhook", func() {
+	var (
+		obj       *optimizerv1.ResourceOptimizerProfile
+		validator ResourceOptimizerProfileCustomValidator
+	)
+
+	BeforeEach(func() {
+		obj = &optimizerv1.ResourceOptimizerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: optimizerv1.ResourceOptimizerProfileSpec{
+				Selector:           metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				CPUThresholds:      optimizerv1.ThresholdSpec{Min: 20, Max: 80},
+				OptimizationPolicy: "Scale",
+			},
+		}
+	})
+
+	It("should admit a valid profile without warnings", func() {
+		warnings, err := validator.ValidateCreate(context.Background(), obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(BeEmpty())
+	})
+
+	It("should deny inverted thresholds on update", func() {
+		updated := obj.DeepCopy()
+		updated.Spec.CPUThresholds = optimizerv1.ThresholdSpec{Min: 80, Max: 20}
+		_, err := validator.ValidateUpdate(context.Background(), obj, updated)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.cpuThresholds: Invalid value")))
+	})
+
+	It("should forbid profiles in namespaces the controller must not change", func() {
+		validator.Namespaces = controller.NamespaceFilter{Deny: []string{"default"}}
+		_, err := validator.ValidateCreate(context.Background(), obj)
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("denylist")))
+
+		_, err = validator.ValidateUpdate(context.Background(), obj, obj)
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+
+		validator.Namespaces = controller.NamespaceFilter{Allow: []string{"default"}}
+		Expect(validator.ValidateCreate(context.Background(), obj)).Error().NotTo(HaveOccurred())
+	})
+
+	It("should forbid profiles in protected namespaces unless they opt in", func() {
+		validator.ProtectedNamespaces = []string{"kube-system", "default"}
+		_, err := validator.ValidateCreate(context.Background(), obj)
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring(`namespace "default" is protected`)))
+
+		obj.Annotations = map[string]string{optimizerv1.AllowProtectedNamespaceAnnotation: "true"}
+		Expect(validator.ValidateUpdate(context.Background(), obj, obj)).Error().NotTo(HaveOccurred())
+
+		validator.Namespaces = controller.NamespaceFilter{Deny: []string{"default"}}
+		_, err = validator.ValidateCreate(context.Background(), obj)
+		Expect(err).To(MatchError(ContainSubstring("denylist")))
+	})
+
+	It("should warn about or deny profiles overlapping others", func() {
+		scheme := runtime.NewScheme()
+		Expect(optimizerv1.AddToScheme(scheme)).To(Succeed())
+		profile := func(name, namespace string, matchLabels map[string]string) client.Object {
+			return &optimizerv1.ResourceOptimizerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				Spec:       optimizerv1.ResourceOptimizerProfileSpec{Selector: metav1.LabelSelector{MatchLabels: matchLabels}},
+			}
+		}
+		overlaps := ResourceOptimizerProfileCustomValidator{Reader: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			profile("web", "default", map[string]string{"app": "web"}),
+			profile("frontend", "default", map[string]string{"tier": "frontend"}),
+			profile("api", "default", map[string]string{"app": "api"}),
+			profile("other-team", "team-b", map[string]string{"app": "web"}),
+		).Build()}
+
+		warnings, err := overlaps.ValidateCreate(context.Background(), obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(ConsistOf("spec.selector.matchLabels: the selector overlaps profiles frontend, which may manage the same workloads"))
+
+		overlaps.DenyOverlaps = true
+		_, err = overlaps.ValidateUpdate(context.Background(), obj, obj)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.selector.matchLabels: Forbidden: the selector overlaps profiles frontend")))
+
+		obj.Spec.Selector.MatchLabels["tier"] = "backend"
+		Expect(overlaps.ValidateCreate(context.Background(), obj)).To(BeEmpty())
+	})
+
+	It("should deny profiles that select every workload", func() {
+		obj.Spec.Selector = metav1.LabelSelector{}
+		_, err := ValidateProfile(obj)
+		Expect(err).To(MatchError(ContainSubstring("spec.selector.matchLabels: Required value")))
+	})
+
+	It("should deny invalid policies and CPU bounds", func() {
+		obj.Spec.OptimizationPolicy = "Shrink"
+		obj.Spec.MinCPU = ptrTo(resource.MustParse("2"))
+		obj.Spec.MaxCPU = ptrTo(resource.MustParse("1"))
+		obj.Spec.InitialCPURequest = ptrTo(resource.MustParse("0"))
+		_, err := ValidateProfile(obj)
+		Expect(err).To(MatchError(ContainSubstring(`spec.optimizationPolicy: Unsupported value: "Shrink"`)))
+		Expect(err).To(MatchError(ContainSubstring("must not be greater than maxCPU")))
+		Expect(err).To(MatchError(ContainSubstring(`spec.initialCPURequest: Invalid value: "0"`)))
+	})
+
+	It("should warn about settings that are probably mistakes", func() {
+		obj.Spec.CPUThresholds = optimizerv1.ThresholdSpec{Min: 50, Max: 55}
+		obj.Spec.CooldownPeriod = &metav1.Duration{Duration: 10 * time.Second}
+		obj.Spec.MaxCPU = ptrTo(resource.MustParse("1"))
+		obj.Spec.RestartPolicy = "Restart"
+		obj.Spec.RecommendationSource = "VPA"
+		obj.Spec.Selector.MatchExpressions = []metav1.LabelSelectorRequirement{
+			{Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"web"}},
+		}
+		warnings, err := ValidateProfile(obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(HaveLen(6))
+	})
+
+	It("should warn about an action mode on Recommend profiles", func() {
+		obj.Spec.OptimizationPolicy = "Recommend"
+		obj.Spec.ActionMode = "Annotate"
+		warnings, err := ValidateProfile(obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(ConsistOf("spec.actionMode only applies to the Scale and Resize policies"))
+	})
+
+	It("should warn about the Admission mode outside the Resize policy", func() {
+		obj.Spec.ActionMode = controller.ActionModeAdmission
+		warnings, err := ValidateProfile(obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(ConsistOf("spec.actionMode Admission only changes how the Resize policy applies its actions"))
+
+		obj.Spec.OptimizationPolicy = "Resize"
+		Expect(ValidateProfile(obj)).To(BeEmpty())
+	})
+
+	It("should check action policy expressions", func() {
+		obj.Spec.ActionPolicy = []optimizerv1.ActionRule{
+			{Name: "weekdays", Expression: `now.getDayOfWeek() != 0 && now.getDayOfWeek() != 6`},
+			{Name: "typo", Expression: `acton == "ScaleDown"`},
+			{Name: "not-bool", Expression: `value * 2.0`},
+		}
+		_, err := ValidateProfile(obj)
+		Expect(err).To(MatchError(ContainSubstring("spec.actionPolicy[1].expression")))
+		Expect(err).To(MatchError(ContainSubstring("undeclared reference to 'acton'")))
+		Expect(err).To(MatchError(ContainSubstring("spec.actionPolicy[2].expression")))
+		Expect(err).NotTo(MatchError(ContainSubstring("spec.actionPolicy[0]")))
+	})
+
+	It("should check scale steps", func() {
+		up, down := intstr.FromString("50%"), intstr.FromInt32(0)
+		obj.Spec.ScaleStep = &optimizerv1.ScaleStep{Up: &up, Down: &down}
+		_, err := ValidateProfile(obj)
+		Expect(err).To(MatchError(ContainSubstring(`spec.scaleStep.down: Invalid value: "0"`)))
+		Expect(err).NotTo(MatchError(ContainSubstring("spec.scaleStep.up")))
+
+		down = intstr.FromString("half")
+		_, err = ValidateProfile(obj)
+		Expect(err).To(MatchError(ContainSubstring(`spec.scaleStep.down: Invalid value: "half"`)))
+	})
+
+	It("should check the maximum replica change", func() {
+		obj.Spec.MaxReplicaChange = ptr.To[int32](0)
+		_, err := ValidateProfile(obj)
+		Expect(err).To(MatchError(ContainSubstring("spec.maxReplicaChange: Invalid value: 0")))
+
+		obj.Spec.MaxReplicaChange = ptr.To[int32](5)
+		obj.Spec.OptimizationPolicy = "Resize"
+		warnings, err := ValidateProfile(obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(ContainElement("spec.maxReplicaChange only applies to the Scale policy"))
+	})
+
+	It("should check the maximum resize percent", func() {
+		obj.Spec.MaxResizePercent = ptr.To[int32](0)
+		_, err := ValidateProfile(obj)
+		Expect(err).To(MatchError(ContainSubstring("spec.maxResizePercent: Invalid value: 0")))
+
+		obj.Spec.MaxResizePercent = ptr.To[int32](50)
+		warnings, err := ValidateProfile(obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(ContainElement("spec.maxResizePercent only applies to the Resize policy"))
+	})
+
+	It("should check the scaling behavior", func() {
+		obj.Spec.Behavior = &autoscalingv2.HorizontalPodAutoscalerBehavior{
+			ScaleUp: &autoscalingv2.HPAScalingRules{Policies: []autoscalingv2.HPAScalingPolicy{
+				{Type: autoscalingv2.PercentScalingPolicy, Value: 100, PeriodSeconds: 60},
+			}},
+			ScaleDown: &autoscalingv2.HPAScalingRules{
+				StabilizationWindowSeconds: ptr.To[int32](7200),
+				Policies:                   []autoscalingv2.HPAScalingPolicy{{Type: "Replicas", Value: 0, PeriodSeconds: 60}},
+			},
+		}
+		_, err := ValidateProfile(obj)
+		Expect(err).To(MatchError(ContainSubstring("spec.behavior.scaleDown.stabilizationWindowSeconds: Invalid value: 7200")))
+		Expect(err).To(MatchError(ContainSubstring(`spec.behavior.scaleDown.policies[0].type: Unsupported value: "Replicas"`)))
+		Expect(err).To(MatchError(ContainSubstring("spec.behavior.scaleDown.policies[0].value: Invalid value: 0")))
+		Expect(err).NotTo(MatchError(ContainSubstring("scaleUp")))
+	})
+
+	It("should check alert triggers", func() {
+		obj.Spec.OptimizationPolicy = "Resize"
+		obj.Spec.AlertTriggers = []optimizerv1.AlertTrigger{{AlertName: "HighLatency"}, {}}
+		warnings, err := ValidateProfile(obj)
+		Expect(err).To(MatchError(ContainSubstring("spec.alertTriggers[1].alertName: Required value")))
+		Expect(warnings).To(ContainElement("spec.alertTriggers only apply to the Scale and Recommend policies"))
+	})
+
+	It("should check requests per second", func() {
+		obj.Spec.OptimizationPolicy = "Resize"
+		obj.Spec.RequestsPerSecond = &optimizerv1.RequestsPerSecond{Source: controller.RequestsSourceNGINX, Min: 100, Max: 100}
+		warnings, err := ValidateProfile(obj)
+		Expect(err).To(MatchError(ContainSubstring("spec.requestsPerSecond.min: Invalid value: 100: must be less than max")))
+		Expect(err).To(MatchError(ContainSubstring("spec.requestsPerSecond.service: Required value")))
+		Expect(warnings).To(ConsistOf("spec.requestsPerSecond only applies to the Scale and Recommend policies"))
+
+		obj.Spec.OptimizationPolicy = "Scale"
+		obj.Spec.RequestsPerSecond = &optimizerv1.RequestsPerSecond{Source: controller.RequestsSourceIstio, Min: 20, Max: 100}
+		Expect(ValidateProfile(obj)).To(BeEmpty())
+	})
+
+	It("should check queue triggers", func() {
+		obj.Spec.OptimizationPolicy = "Resize"
+		obj.Spec.QueueTriggers = []optimizerv1.QueueTrigger{
+			{Type: controller.QueueKafka, MessagesPerReplica: 100, ScaleToZeroAfter: &metav1.Duration{}},
+			{Type: controller.QueueRabbitMQ, MessagesPerReplica: 20},
+		}
+		warnings, err := ValidateProfile(obj)
+		Expect(err).To(MatchError(ContainSubstring("spec.queueTriggers[0].consumerGroup: Required value")))
+		Expect(err).To(MatchError(ContainSubstring(`spec.queueTriggers[0].scaleToZeroAfter: Invalid value: "0s": must be positive`)))
+		Expect(err).To(MatchError(ContainSubstring("spec.queueTriggers[1].queue: Required value")))
+		Expect(warnings).To(ConsistOf(
+			"targets are only scaled to zero when every entry of spec.queueTriggers sets scaleToZeroAfter",
+			"spec.queueTriggers only apply to the Scale and Recommend policies",
+		))
+
+		obj.Spec.OptimizationPolicy = "Scale"
+		obj.Spec.QueueTriggers = []optimizerv1.QueueTrigger{{Type: controller.QueueKafka, ConsumerGroup: "billing", MessagesPerReplica: 100,
+			ScaleToZeroAfter: &metav1.Duration{Duration: 10 * time.Minute}}}
+		Expect(ValidateProfile(obj)).To(BeEmpty())
+	})
+
+	It("should check the latency objective", func() {
+		obj.Spec.OptimizationPolicy = "Resize"
+		obj.Spec.LatencyObjective = &optimizerv1.LatencyObjective{Source: controller.LatencySourceLinkerd, Service: "checkout"}
+		warnings, err := ValidateProfile(obj)
+		Expect(err).To(MatchError(ContainSubstring(`spec.latencyObjective.threshold: Invalid value: "0s": must be positive`)))
+		Expect(warnings).To(ConsistOf(
+			"spec.latencyObjective.service is ignored by the Linkerd source, which measures the profile's targets",
+			"spec.latencyObjective only applies to the Scale and Recommend policies",
+		))
+
+		obj.Spec.OptimizationPolicy = "Scale"
+		obj.Spec.LatencyObjective = &optimizerv1.LatencyObjective{Source: controller.LatencySourceIstio, Service: "checkout",
+			Threshold: metav1.Duration{Duration: 300 * time.Millisecond}}
+		Expect(ValidateProfile(obj)).To(BeEmpty())
+	})
+
+	It("should check the percentile recommendation", func() {
+		obj.Spec.OptimizationPolicy = "Resize"
+		obj.Spec.PercentileRecommendation = &optimizerv1.PercentileRecommendation{Window: &metav1.Duration{Duration: -time.Hour}}
+		warnings, err := ValidateProfile(obj)
+		Expect(err).To(MatchError(ContainSubstring(`spec.percentileRecommendation.window: Invalid value: "-1h0m0s": must be positive`)))
+		Expect(warnings).To(ConsistOf("spec.percentileRecommendation only applies to the Percentile recommendation source"))
+
+		obj.Spec.RecommendationSource = controller.RecommendationSourcePercentile
+		obj.Spec.PercentileRecommendation.Window.Duration = 14 * 24 * time.Hour
+		Expect(ValidateProfile(obj)).To(BeEmpty())
+	})
+
+	It("should check the usage history", func() {
+		obj.Spec.UsageHistory = &optimizerv1.UsageHistory{HalfLife: &metav1.Duration{}}
+		warnings, err := ValidateProfile(obj)
+		Expect(err).To(MatchError(ContainSubstring(`spec.usageHistory.halfLife: Invalid value: "0s": must be positive`)))
+		Expect(warnings).To(ConsistOf("spec.usageHistory only applies to the Resize policy"))
+	})
+
+	It("should check the minimum confidence", func() {
+		obj.Spec.MinConfidence = ptr.To[int32](120)
+		_, err := ValidateProfile(obj)
+		Expect(err).To(MatchError(ContainSubstring("spec.minConfidence: Invalid value: 120")))
+
+		obj.Spec.MinConfidence = ptr.To[int32](60)
+		obj.Spec.OptimizationPolicy = "Recommend"
+		warnings, err := ValidateProfile(obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(ConsistOf("spec.minConfidence only applies to the Scale and Resize policies"))
+	})
+
+	It("should check business hours", func() {
+		obj.Spec.BusinessHours = &optimizerv1.BusinessHours{Start: "08:00", End: "08:00"}
+		_, err := ValidateProfile(obj)
+		Expect(err).To(MatchError(ContainSubstring("spec.businessHours: Invalid value")))
+
+		obj.Spec.BusinessHours = &optimizerv1.BusinessHours{Days: []string{"Monday"}, TimeZone: "Mars/Olympus"}
+		_, err = ValidateProfile(obj)
+		Expect(err).To(MatchError(ContainSubstring("invalid time zone")))
+
+		obj.Spec.BusinessHours.TimeZone = "Europe/Berlin"
+		obj.Spec.OptimizationPolicy = "Recommend"
+		warnings, err := ValidateProfile(obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(ContainElement("spec.businessHours only applies to the Scale and Resize policies"))
+	})
+
+	It("should check the query timeout", func() {
+		obj.Spec.QueryTimeout = &metav1.Duration{Duration: -time.Second}
+		_, err := ValidateProfile(obj)
+		Expect(err).To(MatchError(ContainSubstring(`spec.queryTimeout: Invalid value: "-1s": must be positive`)))
+	})
+
+	It("should check the partial response policy", func() {
+		obj.Spec.PartialResponsePolicy = "Retry"
+		_, err := ValidateProfile(obj)
+		Expect(err).To(MatchError(ContainSubstring(`spec.partialResponsePolicy: Unsupported value: "Retry"`)))
+
+		obj.Spec.PartialResponsePolicy = "Skip"
+		_, err = ValidateProfile(obj)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should check the metric query template", func() {
+		obj.Spec.MetricQuery = `up{namespace="{{.Namespace}}", pod=~"{{.PodRegex}}"}`
+		_, err := ValidateProfile(obj)
+		Expect(err).NotTo(HaveOccurred())
+
+		obj.Spec.MetricQuery = `up{namespace="{{.Namespaces}}"}`
+		_, err = ValidateProfile(obj)
+		Expect(err).To(MatchError(ContainSubstring("spec.metricQuery: Invalid value")))
+	})
+
+	It("should warn about recording rules of metric queries", func() {
+		obj.Spec.MetricQuery = `up{namespace="{{.Namespace}}", pod=~"{{.PodRegex}}"}`
+		obj.Spec.RecordingRule = true
+		warnings, err := ValidateProfile(obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(ContainElement("spec.recordingRule does not apply to a spec.metricQuery, which is queried as it is"))
+	})
+
+	It("should check the recommendation TTL", func() {
+		obj.Spec.RecommendationTTL = &metav1.Duration{}
+		_, err := ValidateProfile(obj)
+		Expect(err).To(MatchError(ContainSubstring(`spec.recommendationTTL: Invalid value: "0s": must be positive`)))
+
+		obj.Spec.RecommendationTTL = &metav1.Duration{Duration: time.Hour}
+		warnings, err := ValidateProfile(obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(ContainElement("spec.recommendationTTL only applies to the Recommend policy"))
+	})
+})
+
+func ptrTo(q resource.Quantity) *resource.Quantity {
+	return &q
+}